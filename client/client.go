@@ -0,0 +1,354 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client is the API of nvdtools for services embedding it rather than shelling out to nvdsync and cpe2cve:
+// it syncs the NVD feeds to a local directory, loads them and matches inventories of CPE names against them,
+// reporting the matched CVEs along with their scores.
+//
+//	c, err := client.New(client.Config{Dir: "/var/lib/nvd"})
+//	...
+//	if err := c.Update(ctx); err != nil { // sync and load the feeds
+//		...
+//	}
+//	matches, err := c.Match(ctx, []string{"cpe:/a:gnu:glibc:2.28"}, client.MatchOptions{Limit: 10})
+//
+// The packages it ties together (cvefeed, wfn, cvss and the datafeed package of nvdsync) provide what it doesn't.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cmd/nvdsync/datafeed"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ErrNotLoaded is returned by the queries of the client whose feeds aren't loaded yet, see Client.Load
+var ErrNotLoaded = errors.New("client: feeds not loaded")
+
+// Config configures the client; either Dir or Feeds must be set
+type Config struct {
+	// Dir is the directory the feeds are synced to and, unless Feeds are set, loaded from
+	Dir string
+	// Sync are the feeds Sync downloads, the mirror of NVD CVE API 2.0 if empty (see datafeed.SupportedAPI)
+	Sync []datafeed.Syncer
+	// Source configures the remote end of the feeds; nil takes it from the environment, see datafeed.NewSourceConfig
+	Source *datafeed.SourceConfig
+	// Journal is the change journal the changes of the synced feeds are appended to, see datafeed.Sync; optional
+	Journal string
+	// Feeds are the paths of the feeds to load; if empty, the NVD feeds found in Dir (nvdcve-*.json, optionally
+	// gzipped) are loaded in lexical order
+	Feeds []string
+	// Overrides are the paths of the feeds overriding them, see cvefeed.Dictionary.Override
+	Overrides []string
+	// Load configures loading of the feeds, see cvefeed.LoadOptions; IndexDir is disregarded
+	Load cvefeed.LoadOptions
+	// SkipRejected drops rejected CVEs from the feeds, see cvefeed.Dictionary.DropRejected
+	SkipRejected bool
+	// Indexed builds an index of the feeds, so CPE names are matched against the CVEs of their products only
+	Indexed bool
+	// RequireVersion ignores matching specifications without version, see cvefeed.Cache.SetRequireVersion
+	RequireVersion bool
+	// Matching is the semantics of matching CPE names, see wfn.MatchOptions
+	Matching wfn.MatchOptions
+	// Setup configures the caches built of the feeds beyond the options above, e.g. cache.SetWildcardVersions(true);
+	// optional
+	Setup func(cache *cvefeed.Cache)
+}
+
+// MatchOptions tunes a single match query, the zero value reports all the matches, the most severe first
+type MatchOptions struct {
+	// Soft treats NA attributes of the CPE names (except part, vendor and product) as ANY, see cvefeed.Cache.GetSoft
+	Soft bool
+	// Filter reports only the matches which pass the filter
+	Filter cvefeed.ScoreFilter
+	// KEV is the catalog of known exploited vulnerabilities to flag the matches with, optional
+	KEV cvefeed.KEV
+	// Order is the order of the matches
+	Order cvefeed.ResultOrder
+	// Limit is the number of matches reported, kept as per Order; 0 reports all of them
+	Limit int
+}
+
+// Match is a CVE matched by the CPE names of the inventory
+type Match struct {
+	// MatchRecord describes the match and the CVE: the matched CPE names, scores, references etc.,
+	// see cvefeed.NewMatchRecord
+	cvefeed.MatchRecord
+	// FixedIn maps the URI bindings of the matched vulnerable CPE names to the versions they were fixed in,
+	// if the feed tells them
+	FixedIn map[string]string `json:"fixed_in,omitempty"`
+	// Vectors are the CVSS vectors of the CVE keyed by the major version, see cvefeed.CVSSVectors
+	Vectors map[string]string `json:"vectors,omitempty"`
+	// Result is the match result the match was made of, for the granular APIs of cvefeed
+	Result cvefeed.MatchResult `json:"-"`
+}
+
+// Scores are the scores of a CVE
+type Scores struct {
+	// Score is the representative score of the CVE, see cvefeed.RepresentativeScore
+	cvefeed.Score
+	// CVSS2 and CVSS3 are the base scores of CVSS v2 and v3 assessments, 0 if the CVE wasn't assessed
+	CVSS2, CVSS3 float64
+	// Vectors are the CVSS vectors of the CVE keyed by the major version, see cvefeed.CVSSVectors
+	Vectors map[string]string
+}
+
+// ScoresOf returns the scores of the CVE
+func ScoresOf(cve cvefeed.CVEItem) Scores {
+	return Scores{
+		Score:   cvefeed.RepresentativeScore(cve),
+		CVSS2:   cve.CVSS20base(),
+		CVSS3:   cve.CVSS30base(),
+		Vectors: cvefeed.CVSSVectors(cve),
+	}
+}
+
+// Status describes the feeds the client answers from
+type Status struct {
+	Feeds    []string
+	CVEs     int
+	LoadedAt time.Time // zero if the feeds aren't loaded
+}
+
+// Client syncs, loads and matches the feeds as per its config. It's safe for concurrent use: the queries are answered
+// from the feeds loaded last, while the new ones load.
+type Client struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	cache  *cvefeed.Cache
+	status Status
+}
+
+// New returns the client of the config; the feeds aren't synced nor loaded until Sync, Load or Update
+func New(cfg Config) (*Client, error) {
+	if cfg.Dir == "" && len(cfg.Feeds) == 0 {
+		return nil, fmt.Errorf("client: neither feeds directory nor feeds are set")
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// Sync downloads the feeds of the config to its directory, only the ones changed since the last sync,
+// see datafeed.Sync; it doesn't load them
+func (c *Client) Sync(ctx context.Context) error {
+	if c.cfg.Dir == "" {
+		return fmt.Errorf("client: feeds directory isn't set")
+	}
+	feeds := c.cfg.Sync
+	if len(feeds) == 0 {
+		feeds = []datafeed.Syncer{datafeed.SupportedAPI["cve-api-2.0"]}
+	}
+	s := datafeed.Sync{
+		Feeds:    feeds,
+		Source:   c.cfg.Source,
+		LocalDir: c.cfg.Dir,
+		Journal:  c.cfg.Journal,
+	}
+	if err := s.Do(ctx); err != nil {
+		return fmt.Errorf("client: couldn't sync feeds: %v", err)
+	}
+	return nil
+}
+
+// Update syncs the feeds and loads them
+func (c *Client) Update(ctx context.Context) error {
+	if err := c.Sync(ctx); err != nil {
+		return err
+	}
+	return c.Load(ctx)
+}
+
+// nvdFeedRe matches the names of the NVD feed files nvdsync downloads,
+// e.g. nvdcve-1.1-2002.json.gz or nvdcve-api-2.0.json.gz
+var nvdFeedRe = regexp.MustCompile(`^nvdcve-.+\.json(\.gz)?$`)
+
+// feeds returns the paths of the feeds to load
+func (c *Client) feeds() ([]string, error) {
+	if len(c.cfg.Feeds) != 0 {
+		return c.cfg.Feeds, nil
+	}
+	files, err := ioutil.ReadDir(c.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range files {
+		if !f.IsDir() && nvdFeedRe.MatchString(f.Name()) {
+			paths = append(paths, filepath.Join(c.cfg.Dir, f.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no feeds in %q", c.cfg.Dir)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Load loads the feeds and replaces the ones queries are answered from; they keep being answered from the former
+// feeds while loading, and if it fails or ctx is done before the feeds are loaded
+func (c *Client) Load(ctx context.Context) error {
+	paths, err := c.feeds()
+	if err != nil {
+		return fmt.Errorf("client: couldn't load feeds: %v", err)
+	}
+	dict, err := cvefeed.LoadFeedContext(ctx, c.cfg.Load, paths...)
+	if err != nil {
+		return fmt.Errorf("client: couldn't load feeds: %v", err)
+	}
+	if len(c.cfg.Overrides) != 0 {
+		overrides, err := cvefeed.LoadFeedContext(ctx, c.cfg.Load, c.cfg.Overrides...)
+		if err != nil {
+			return fmt.Errorf("client: couldn't load overrides: %v", err)
+		}
+		dict.Override(overrides)
+	}
+	if c.cfg.SkipRejected {
+		dict.DropRejected()
+	}
+	cache := cvefeed.NewCache(dict).SetRequireVersion(c.cfg.RequireVersion).SetMatchOptions(c.cfg.Matching)
+	if c.cfg.Setup != nil {
+		c.cfg.Setup(cache)
+	}
+	if c.cfg.Indexed {
+		cache.Idx = cvefeed.NewIndex(dict)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = cache
+	c.status = Status{Feeds: paths, CVEs: len(dict), LoadedAt: time.Now().UTC()}
+	return nil
+}
+
+// Status returns the status of the client
+func (c *Client) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// loaded returns the cache of the feeds loaded last
+func (c *Client) loaded() (*cvefeed.Cache, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.cache == nil {
+		return nil, ErrNotLoaded
+	}
+	return c.cache, nil
+}
+
+// CVE returns the CVE of the feeds by its ID, nil if there's no such CVE
+func (c *Client) CVE(id string) (cvefeed.CVEItem, error) {
+	cache, err := c.loaded()
+	if err != nil {
+		return nil, err
+	}
+	return cache.Dict[id], nil
+}
+
+// Scores returns the scores of the CVE of the feeds by its ID, see ScoresOf
+func (c *Client) Scores(id string) (Scores, error) {
+	cve, err := c.CVE(id)
+	if err != nil {
+		return Scores{}, err
+	}
+	if cve == nil {
+		return Scores{}, fmt.Errorf("client: CVE %q not found", id)
+	}
+	return ScoresOf(cve), nil
+}
+
+// Match returns the CVEs matching the CPE names of the inventory (URI or formatted string bindings) as per opts,
+// one per CVE; matching stops once ctx is done, returning ctx.Err(). The CPE names are matched together,
+// so the CVEs of applications running on platforms match if the inventory has both.
+func (c *Client) Match(ctx context.Context, cpes []string, opts MatchOptions) ([]Match, error) {
+	cache, err := c.loaded()
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([]*wfn.Attributes, len(cpes))
+	orig := make(map[wfn.Attributes]*wfn.Attributes, len(cpes)) // softened names to the parsed ones
+	for i, name := range cpes {
+		attr, err := wfn.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("client: couldn't parse CPE name %q: %v", name, err)
+		}
+		if opts.Soft {
+			soft := attr.Soften()
+			if _, ok := orig[*soft]; !ok {
+				orig[*soft] = attr
+			}
+			attr = soft
+		}
+		attrs[i] = attr
+	}
+
+	var results []cvefeed.MatchResult
+	out := make(chan cvefeed.MatchResult)
+	errc := make(chan error, 1)
+	go func() { errc <- cache.MatchStream(ctx, attrs, out) }()
+	for r := range out {
+		results = append(results, r)
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+
+	results = opts.Filter.Apply(results)
+	if opts.KEV != nil {
+		results = opts.KEV.Apply(results)
+	}
+	results, _ = cvefeed.LimitResults(results, opts.Limit, opts.Order)
+	matches := make([]Match, len(results))
+	for i, r := range results {
+		if opts.Soft {
+			// the results are shared with the cache, so the CPE names are copied rather than amended
+			soft := r.CPEs
+			r.CPEs = make([]*wfn.Attributes, len(soft))
+			for j, attr := range soft {
+				if attr != nil && orig[*attr] != nil {
+					attr = orig[*attr]
+				}
+				r.CPEs[j] = attr
+			}
+		}
+		matches[i] = newMatch(r)
+	}
+	return matches, nil
+}
+
+// newMatch returns the match of the result
+func newMatch(r cvefeed.MatchResult) Match {
+	m := Match{MatchRecord: cvefeed.NewMatchRecord(r), Result: r}
+	for i, fixed := range r.FixedIn {
+		if fixed != "" && i < len(r.CPEs) && r.CPEs[i] != nil && (r.Platform == nil || !r.Platform[i]) {
+			if m.FixedIn == nil {
+				m.FixedIn = make(map[string]string)
+			}
+			m.FixedIn[r.CPEs[i].BindToURI()] = fixed
+		}
+	}
+	if vectors := cvefeed.CVSSVectors(r.CVE); len(vectors) != 0 {
+		m.Vectors = vectors
+	}
+	return m
+}
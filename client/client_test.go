@@ -0,0 +1,126 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cmd/nvdsync/datafeed"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testFeed = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2020-0001","vulnStatus":"Analyzed",
+		"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1",
+			"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}}]},
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,
+			"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.2"}]}]}]}},
+	{"cve":{"id":"CVE-2020-0002","vulnStatus":"Analyzed",
+		"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1",
+			"vectorString":"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N","baseScore":3.3,"baseSeverity":"LOW"}}]},
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,
+			"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+
+// feedSyncer writes the test feed
+type feedSyncer string
+
+func (name feedSyncer) Sync(ctx context.Context, src datafeed.SourceConfig, localdir string) error {
+	return ioutil.WriteFile(filepath.Join(localdir, string(name)), []byte(testFeed), 0644)
+}
+
+func TestClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "client-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = New(Config{}); err == nil {
+		t.Fatal("client of no feeds was created")
+	}
+	c, err := New(Config{Dir: dir, Sync: []datafeed.Syncer{feedSyncer("nvdcve-test.json")}, Indexed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err = c.Match(ctx, []string{"cpe:/a:acme:widget:1.0"}, MatchOptions{}); err != ErrNotLoaded {
+		t.Fatalf("expected ErrNotLoaded before the feeds are loaded, got %v", err)
+	}
+	if err = c.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st := c.Status(); st.CVEs != 2 || len(st.Feeds) != 1 || st.LoadedAt.IsZero() {
+		t.Errorf("unexpected status %+v", st)
+	}
+
+	matches, err := c.Match(ctx, []string{"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}, MatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].CVE != "CVE-2020-0001" || matches[1].CVE != "CVE-2020-0002" {
+		t.Fatalf("expected both CVEs, the most severe first, got %+v", matches)
+	}
+	m := matches[0]
+	if m.Severity != "Critical" || m.Score != 9.8 || m.Matches[0] != "cpe:/a:acme:widget:1.0" || m.Vectors["3"] == "" {
+		t.Errorf("unexpected match %+v", m)
+	}
+
+	matches, err = c.Match(ctx, []string{"cpe:/a:acme:widget:1.0"}, MatchOptions{Limit: 1, Order: cvefeed.ByCVEID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].CVE != "CVE-2020-0001" {
+		t.Errorf("expected the first CVE by ID, got %+v", matches)
+	}
+
+	// soft matches report the CPE names as they were passed, not the softened ones
+	matches, err = c.Match(ctx, []string{"cpe:2.3:a:acme:widget:1.0:-:*:*:*:*:*:*"}, MatchOptions{Soft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Matches[0] != "cpe:/a:acme:widget:1.0:-" {
+		t.Errorf("soft match: unexpected matches %+v", matches)
+	}
+
+	if _, err = c.Match(ctx, []string{"cpe:/x"}, MatchOptions{}); err == nil {
+		t.Error("bad CPE name was matched")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = c.Match(canceled, []string{"cpe:/a:acme:widget:1.0"}, MatchOptions{}); err != context.Canceled {
+		t.Errorf("expected canceled match, got %v", err)
+	}
+	if err = c.Load(canceled); err == nil {
+		t.Error("canceled load succeeded")
+	}
+	if c.Status().CVEs != 2 {
+		t.Error("feeds were replaced by the canceled load")
+	}
+
+	scores, err := c.Scores("CVE-2020-0002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores.Score.Score != 3.3 || scores.CVSS3 != 3.3 || scores.Vectors["3"] != "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N" {
+		t.Errorf("unexpected scores %+v", scores)
+	}
+	if _, err = c.Scores("CVE-2020-9999"); err == nil {
+		t.Error("scores of unknown CVE")
+	}
+}
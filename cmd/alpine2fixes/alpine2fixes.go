@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/providers/alpine"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+
+	"github.com/golang/glog"
+)
+
+func main() {
+	release := flag.String("release", "", "download secdb of the repositories of this Alpine release, e.g. v3.18, rather than reading the arguments")
+	repos := flag.String("repos", "main,community", "comma separated repositories of -release")
+	timeout := flag.Duration("timeout", time.Minute, "download timeout, per URL")
+	httpConfig := client.DefaultConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Println("Usage: alpine2fixes [flags] [secdb.json|URL...] > alpine.fixes.csv")
+		fmt.Println("Converts Alpine security database (secdb) to distribution fixes CSV (CVE,package,release,status)")
+		fmt.Println("for -distro_fixes flag of cpe2cve")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	srcs := flag.Args()
+	if *release != "" {
+		for _, repo := range strings.Split(*repos, ",") {
+			srcs = append(srcs, alpine.SecDBURL(*release, strings.TrimSpace(repo)))
+		}
+	}
+	if len(srcs) == 0 {
+		flag.Usage()
+	}
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	fixes := make(cvefeed.DistroFixes)
+	for _, src := range srcs {
+		var fs cvefeed.DistroFixes
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			fs, err = alpine.Fetch(ctx, httpClient, src)
+			cancel()
+		} else {
+			fs, err = alpine.Load(src)
+		}
+		if err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("%s: fixes of %d CVEs", src, len(fs))
+		for _, cveFixes := range fs {
+			for _, f := range cveFixes {
+				fixes.Add(f)
+			}
+		}
+	}
+
+	if err := fixes.Write(os.Stdout); err != nil {
+		glog.Fatal(err)
+	}
+}
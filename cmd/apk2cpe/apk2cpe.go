@@ -0,0 +1,238 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+var progname = path.Base(os.Args[0])
+
+// custom type to be recognized by flag.Parse()
+type fieldsToSkip map[int]struct{}
+
+// remove elements from  fields slice as per config
+// NB!: this modifies the underlying array of fields slice
+func (fs fieldsToSkip) skipFields(fields []string) []string {
+	j := 0
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fs[i]; ok {
+			continue
+		}
+		fields[j] = fields[i]
+		j++
+	}
+	return fields[:j]
+}
+
+// part of flag.Value interface implementation
+func (fs fieldsToSkip) String() string {
+	fss := make([]string, 0, len(fs))
+	for i := range fs {
+		fss = append(fss, fmt.Sprintf("%d", i+1))
+	}
+	return strings.Join(fss, ",")
+}
+
+// part of flag.Value interface implementation
+func (fs *fieldsToSkip) Set(val string) error {
+	if *fs == nil {
+		*fs = fieldsToSkip{}
+	}
+	for _, v := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return fmt.Errorf("illegal field index %d", n)
+		}
+		(*fs)[n-1] = struct{}{}
+	}
+	return nil
+}
+
+type config struct {
+	nameField    int
+	versionField int
+	archField    int
+	cpeField     int
+	installed    bool
+	inFieldSep   string
+	outFieldSep  string
+	skip         fieldsToSkip
+}
+
+func (c *config) addFlags() {
+	flag.IntVar(&c.nameField, "name", 0, "position of the field in DSV input that contains the package name, "+
+		"or name-version-rrelease (as listed by apk info -v, or the .apk file name) if -version is omitted (starts at 1)")
+	flag.IntVar(&c.versionField, "version", 0, "position of the field in DSV input that contains the package version, version-rrelease (starts at 1)")
+	flag.IntVar(&c.archField, "arch", 0, "optional position of the field in DSV input that contains the package architecture (starts at 1)")
+	flag.IntVar(&c.cpeField, "cpe", 0, "position of the field in the output to put generated CPE at (starts at 1)")
+	flag.BoolVar(&c.installed, "installed", false, "read apk installed packages database (e.g. /lib/apk/db/installed) instead of DSV input; "+
+		"the input fields are package name, version and architecture, -name, -version and -arch are implied")
+	flag.StringVar(&c.inFieldSep, "d", "\t", "input column delimiter")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+	flag.Var(&c.skip, "e", "optional comma-separated list of input fields that should be dropped from output (starts with 1) "+
+		"package fields are extracted before dropping fields, CPE is added after that")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s takes a delimiter-separated input with the fields containing Alpine package name, version\n" +
+			"%[2]s and architecture (e.g. the output of apk info -v) or apk installed packages database and produces\n" +
+			"%[2]s delimiter-separated output consisting of the same fields plus CPE name parsed from the package.\n" +
+			"usage: %[1]s [flags]\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func field(fields []string, n int) (string, error) {
+	if n == 0 {
+		return "", nil
+	}
+	if n > len(fields) {
+		return "", fmt.Errorf("not enough fields (%d)", len(fields))
+	}
+	return fields[n-1], nil
+}
+
+// NB!: modifies underlying array of fields slice
+func processRecord(fields []string, cfg config) ([]string, error) {
+	name, err := field(fields, cfg.nameField)
+	if err != nil {
+		return nil, err
+	}
+	version, err := field(fields, cfg.versionField)
+	if err != nil {
+		return nil, err
+	}
+	arch, err := field(fields, cfg.archField)
+	if err != nil {
+		return nil, err
+	}
+	var attr *wfn.Attributes
+	if cfg.versionField == 0 {
+		attr, err = cpeparse.FromAPKName(name)
+	} else {
+		attr, err = cpeparse.FromAPKPackage(name, version, arch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse Alpine package from fields %q: %v", fields, err)
+	}
+	cpe := attr.BindToURI()
+	fields = cfg.skip.skipFields(fields)
+	if cfg.cpeField > len(fields) {
+		// if cfg.cpeField > len(fields)+1 we ignore that silently and just add CPE as the last field
+		return append(fields, cpe), nil
+	}
+	outFields := make([]string, 0, len(fields)+1)
+	outFields = append(outFields, fields[:cfg.cpeField-1]...)
+	outFields = append(outFields, cpe)
+	outFields = append(outFields, fields[cfg.cpeField-1:]...)
+	return outFields, nil
+}
+
+// readRecords calls fn for every record of the input: DSV records, or name, version and architecture of the
+// packages of apk installed packages database
+func readRecords(in io.Reader, cfg config, fn func([]string) error) error {
+	if cfg.installed {
+		return cpeparse.ParseAPKInstalled(in, func(p cpeparse.APKPackage) error {
+			return fn([]string{p.Package, p.Version, p.Architecture})
+		})
+	}
+	r := csv.NewReader(in)
+	r.Comma = rune(cfg.inFieldSep[0])
+	r.FieldsPerRecord = -1
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err = fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func apk2cpe(in io.Reader, out io.Writer, cfg config) {
+	if cfg.installed {
+		cfg.nameField, cfg.versionField, cfg.archField = 1, 2, 3
+	}
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	err := readRecords(in, cfg, func(inRec []string) error {
+		outRec, err := processRecord(inRec, cfg)
+		if err != nil {
+			sayErr(0, "couldn't process record %v: %v", inRec, err)
+			return nil
+		}
+		if err = w.Write(outRec); err != nil {
+			sayErr(-1, "write error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		sayErr(-1, "read error: %v", err)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		sayErr(-1, "write error: %v", err)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if (cfg.nameField == 0 && !cfg.installed) || cfg.cpeField == 0 {
+		flag.Usage()
+	}
+	apk2cpe(os.Stdin, os.Stdout, cfg)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProcessRecord(t *testing.T) {
+	cases := []struct {
+		in   string
+		out  string
+		fail bool
+	}{
+		{"", "", true},
+		{"musl", "", true},
+		{"musl\t1.2.2-r7\tx86_64", "musl;1.2.2-r7;cpe:/a::musl:1.2.2:r7:~~~~x86_64~", false},
+		{"ca-certificates-bundle\t20220614-r0\tnoarch", "ca-certificates-bundle;20220614-r0;cpe:/a::ca-certificates-bundle:20220614:r0", false},
+	}
+	cfg := config{
+		nameField:    1,
+		versionField: 2,
+		archField:    3,
+		cpeField:     3,
+		inFieldSep:   "\t",
+		outFieldSep:  ";",
+		skip:         fieldsToSkip(map[int]struct{}{2: {}}),
+	}
+	for _, c := range cases {
+		fields := strings.Split(c.in, cfg.inFieldSep)
+		record, err := processRecord(fields, cfg)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("line %q was expected to succeed, but failed: %v", c.in, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("line %q was expected to fail, but succeeded", c.in)
+			continue
+		}
+		if out := strings.Join(record, cfg.outFieldSep); out != c.out {
+			t.Errorf("line %q: expected %q, got %q", c.in, c.out, out)
+		}
+	}
+}
+
+func TestAPKName(t *testing.T) {
+	record, err := processRecord([]string{"busybox-1.35.0-r17"}, config{nameField: 1, cpeField: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "cpe:/a::busybox:1.35.0:r17"; record[1] != want {
+		t.Errorf("expected %q, got %q", want, record[1])
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	installed := "C:Q1abc=\nP:musl\nV:1.2.2-r7\nA:x86_64\nT:the musl c library\n\n" +
+		"P:busybox\nV:1.35.0-r17\nA:aarch64\n"
+	var out bytes.Buffer
+	apk2cpe(strings.NewReader(installed), &out, config{installed: true, cpeField: 4, outFieldSep: "\t"})
+	want := "musl\t1.2.2-r7\tx86_64\tcpe:/a::musl:1.2.2:r7:~~~~x86_64~\n" +
+		"busybox\t1.35.0-r17\taarch64\tcpe:/a::busybox:1.35.0:r17:~~~~aarch64~\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
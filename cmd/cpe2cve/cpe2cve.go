@@ -15,7 +15,9 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -23,12 +25,19 @@ import (
 	"path"
 	"runtime"
 	"runtime/pprof"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/cpedict"
 	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/cyclonedx"
+	"github.com/facebookincubator/nvdtools/cvefeed/sarif"
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/facebookincubator/nvdtools/wfn"
 	"github.com/golang/glog"
 )
@@ -36,7 +45,18 @@ import (
 type config struct {
 	nProcessors                      int
 	cpesAt, cvesAt, matchesAt        int
+	platformsAt                      int
+	limit                            int
 	cwesAt, cvss2at, cvss3at, cvssAt int
+	epssAt, epssPercentileAt         int
+	cvssNotesAt                      int
+	cvssAs                           string
+	kevAt                            int
+	statusAt, vendorCommentsAt       int
+	cweNamesAt, capecAt              int
+	cweCatalogPath                   string
+	cweCatalog                       cvefeed.CWECatalog
+	skipRejected                     bool
 	feedFormat                       string
 	inFieldSep, inRecSep             string
 	outFieldSep, outRecSep           string
@@ -44,54 +64,225 @@ type config struct {
 	skip                             fieldsToSkip
 	indexedDict                      bool
 	requireVersion                   bool
+	softMatch                        bool
+	collapseEscapes                  bool
+	wildcardVersions                 bool
+	matchOpts                        wfn.MatchOptions
+	versionCmp                       string
+	recoverPanics                    bool
+	validate                         bool
 	cacheSize                        int64
 	overrides                        multiString
+	matchCriteria                    multiString
+	sources                          sourceFeeds
+	sourcePriority                   string
+	fieldPriority                    multiString
+	mergePolicy                      cvefeed.MergePolicy
+	sourcesAt, provenanceAt          int
+	asOf                             string
+	journal                          multiString
+	history                          *cvefeed.History
+	firstSeenAt                      int
+	metrics                          metrics.Config
+	exceptionsPath                   string
+	exceptions                       cvefeed.Exceptions
+	suppressionsPath                 string
+	suppressionsAudit                string
+	suppressions                     *cvefeed.Suppressions
+	annotationsAt                    int
+	distroFixesPath                  string
+	distroFixes                      cvefeed.DistroFixes
+	minSeverity                      string
+	publishedAfter                   string
+	includeUndated                   bool
+	filter                           cvefeed.ScoreFilter
+	filterSource                     string
+	filterExpr                       *cvefeed.FilterExpr
+	epssSource                       string
+	epss                             cvefeed.EPSS
+	kevSource                        string
+	kevOnly                          bool
+	kevDueBefore                     string
+	kev                              cvefeed.KEV
+	kevDue                           time.Time
+	sbomPath, sbomOutput             string
+	sarifArtifact                    string
+	cpeDictPath                      string
+	cpeDict                          *cpedict.Index
+	indexDir                         string
 }
 
 func (c *config) addFlags() {
-	flag.IntVar(&c.nProcessors, "nproc", 1, "number of concurrent goroutines that perform CVE lookup")
+	flag.IntVar(&c.nProcessors, "workers", runtime.NumCPU(), "number of concurrent goroutines that perform CVE lookup; the output keeps the order of the input regardless")
+	flag.IntVar(&c.nProcessors, "nproc", runtime.NumCPU(), "same as -workers, kept for compatibility")
 	flag.IntVar(&c.cpesAt, "cpe", 0, "look for CPE names in input at this position (starts with 1)")
 	flag.IntVar(&c.cvesAt, "cve", 0, "output CVEs at this position (starts with 1)")
 	flag.IntVar(&c.cwesAt, "cwe", 0, "output problem types (CWEs) at this position (starts with 1)")
+	flag.IntVar(&c.cweNamesAt, "cwe_name", 0, "output the names of problem types (CWEs) at this position (starts with 1), in the order of -cwe, empty for the ones not in the catalog; 0 disables the output")
+	flag.IntVar(&c.capecAt, "capec", 0, "output CAPEC attack patterns related to problem types (CWEs) at this position (starts with 1), requires -cwe_catalog; 0 disables the output")
+	flag.StringVar(&c.cweCatalogPath, "cwe_catalog", "", "path to CWE catalog CSV as published by MITRE (plain or gzip'ed) for -cwe_name and -capec; the names of the most common CWEs are built in")
 	flag.IntVar(&c.cvssAt, "cvss", 0, "output CVSS base score (v3 if available, v2 otherwise) at this position (starts with 1)")
+	flag.StringVar(&c.cvssAs, "cvss_as", "", "output the base score of -cvss in this CVSS version (2.0, 3.0, 3.1 or 4.0), converting the vectors of other versions approximately when the CVE has none of it, to score mixed-version data on a single scale")
+	flag.IntVar(&c.cvssNotesAt, "cvss_notes", 0, "with -cvss_as, output the notes of lossy conversions of the score at this position (starts with 1), empty for the scores of the version or converted losslessly")
 	flag.IntVar(&c.cvss2at, "cvss2", 0, "output CVSS 2.0 base score at this position (starts with 1)")
 	flag.IntVar(&c.cvss3at, "cvss3", 0, "output CVSS 3.0 base score at this position (starts with 1)")
+	flag.IntVar(&c.epssAt, "epss", 0, "output EPSS probability of exploitation at this position (starts with 1); 0 disables the output")
+	flag.IntVar(&c.epssPercentileAt, "epss_percentile", 0, "output EPSS percentile at this position (starts with 1); 0 disables the output")
+	flag.StringVar(&c.epssSource, "epss_scores", cvefeed.EPSSURL, "path or URL of EPSS scores CSV (plain or gzip'ed) for -epss, -epss_percentile and epss and epss_percentile of -filter")
+	flag.IntVar(&c.kevAt, "kev", 0, "output the date CVEs listed in CISA Known Exploited Vulnerabilities (KEV) catalog are due to be remediated by at this position (starts with 1), empty for the others; 0 disables the output")
+	flag.BoolVar(&c.kevOnly, "kev_only", false, "output only CVEs listed in KEV catalog")
+	flag.StringVar(&c.kevDueBefore, "kev_due_before", "", "output only CVEs listed in KEV catalog due to be remediated before this date (YYYY-MM-DD)")
+	flag.StringVar(&c.kevSource, "kev_catalog", cvefeed.KEVURL, "path or URL of KEV catalog JSON for -kev, -kev_only, -kev_due_before and kev of -filter")
+	flag.IntVar(&c.statusAt, "status", 0, "output the status of CVEs in the feed (e.g. Analyzed, Awaiting Analysis or Rejected; NVD JSON 1.x feeds only tell the rejected ones) at this position (starts with 1); 0 disables the output")
+	flag.IntVar(&c.vendorCommentsAt, "vendor_comments", 0, "output vendor comments on CVEs (organization: comment) at this position (starts with 1); 0 disables the output")
+	flag.BoolVar(&c.skipRejected, "skip_rejected", false, "skip rejected CVEs: the ones of vulnStatus Rejected or, in NVD JSON 1.x feeds, described as ** REJECT **")
+	flag.StringVar(&c.sbomPath, "sbom", "", "match components of CycloneDX SBOM (JSON or XML) read from this file (- for standard input) rather than CPE names in the delimited input; components are matched by their CPE names or, failing that, by CPE names derived from their purls")
+	flag.StringVar(&c.sbomOutput, "sbom_output", "vex", "with -sbom, output the vulnerabilities found as CycloneDX VEX document (vex) or add them to the SBOM (augmented, always JSON)")
 	flag.IntVar(&c.matchesAt, "matches", 0, "output CPEs that matches CVE at this position; 0 disables the output")
+	flag.IntVar(&c.platformsAt, "platforms", 0, "output CPEs the vulnerable CPEs had to run on (e.g. the OS of \"app running on OS\" configurations) at this position; 0 disables the output")
 	flag.Int64Var(&c.cacheSize, "cache_size", 0, "limit the cache size to this amount in bytes; 0 removes the limit, -1 disables caching")
 	flag.StringVar(&c.feedFormat, "feed", "json", "vulnerability feed format (currently only json is supported)")
 	flag.StringVar(&c.inFieldSep, "d", "\t", "input columns delimiter")
 	flag.StringVar(&c.inRecSep, "d2", ",", "inner input columns delimiter: separates elements of list passed into a CSV columns")
-	flag.StringVar(&c.outFieldSep, "o", "\t", "output columns delimiter")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output columns delimiter, or the structured output format: json (an array of records, one per input row), ndjson (a record per line), see cvefeed.ScanRecord, or sarif (SARIF 2.1.0 log, e.g. for GitHub code scanning)")
+	flag.StringVar(&c.sarifArtifact, "sarif_artifact", "", "with -o sarif, the URI of the scanned artifact (e.g. the path of the lock file or the name of the image) the results are located in")
 	flag.StringVar(&c.outRecSep, "o2", ",", "inner output columns delimiter: separates elements of lists in output CSV columns")
 	flag.StringVar(&c.cpuProfile, "cpuprofile", "", "file to store CPU profile data to; empty value disables CPU profiling")
 	flag.StringVar(&c.memProfile, "memprofile", "", "file to store memory profile data to; empty value disables memory profiling")
 	flag.Var(&c.skip, "e", "comma separated list of fields to erase from output; starts at 1, supports ranges (e.g. 1-3); processed before the vulnerablitie field added")
 	flag.BoolVar(&c.indexedDict, "idxd", false, "build and use an index for CVE dictionary: increases the processing speed, but might miss some matches")
 	flag.BoolVar(&c.requireVersion, "require_version", false, "ignore matches of CPEs with version ANY")
+	flag.BoolVar(&c.softMatch, "soft", false, "treat NA attributes of input CPEs (except part, vendor and product) as ANY, for inventories which report NA for unknown attributes")
+	flag.BoolVar(&c.wildcardVersions, "wildcard_versions", false, "match input CPEs with versions like 2.4.* as ranges of versions, e.g. [2.4.0, 2.5.0)")
+	flag.BoolVar(&c.matchOpts.IgnoreTargetSW, "ignore_target_sw", false, "don't compare target_sw attributes, e.g. CVEs of products on android match the products on ios")
+	flag.BoolVar(&c.matchOpts.IgnoreTargetHW, "ignore_target_hw", false, "don't compare target_hw attributes, e.g. CVEs of products on x86 match the products on arm64")
+	flag.BoolVar(&c.matchOpts.AnyNotNA, "any_not_na", false, "ANY attributes of input CPEs don't match NA attributes of CVEs, e.g. the product of unknown update doesn't match CVEs of the product without updates")
+	flag.BoolVar(&c.matchOpts.ExactVersion, "exact_version", false, "only match the exact versions of CVEs: version ranges, versions ANY and wildcard versions don't match")
+	flag.StringVar(&c.versionCmp, "version_cmp", "", "compare versions of CVE version ranges by this versioning scheme: rpm, deb, apk, semver or dotted; comma separated [vendor:]product=scheme entries set the scheme of products, e.g. rpm,mysql:mysql=dotted; empty keeps the heuristic comparison")
+	flag.BoolVar(&c.collapseEscapes, "collapse_escapes", false, "collapse runs of backslashes in input CPEs into one, for CPEs double escaped by JSON or shell layers; heuristic, literal backslashes collapse too")
+	flag.BoolVar(&c.recoverPanics, "recover", false, "skip CVEs whose configuration can't be evaluated (e.g. malformed feed records) rather than aborting; the skipped CVEs are logged")
+	flag.BoolVar(&c.validate, "validate", false, "validate structure of the feeds against NVD schema before loading them; slows down loading")
+	flag.StringVar(&c.indexDir, "index_dir", "", "keep CVEs on disk, indexed in this directory, rather than in memory: the index is built on the first run and reused while the feeds don't change, so later runs start instantly; can't be combined with -r, -idxd, -validate, -skip_rejected, -match_criteria, -source, -as_of and -journal")
 	flag.Var(&c.overrides, "r", "overRide: path to override feed, can be specified multiple times")
+	flag.StringVar(&c.exceptionsPath, "exceptions", "", "path to CSV file with accepted risk exceptions (CVE,CPE,suppress|severity) applied to the matches; the severity of rescored matches counts for -min_severity, -filter and the order of the CVEs, and is the severity of JSON output, but -cvss outputs the score of the CVE regardless")
+	flag.StringVar(&c.suppressionsPath, "suppressions", "", "path to JSON file of suppression rules (cve, cpe glob, justification, expires YYYY-MM-DD, action suppress or annotate) applied to the matches after -exceptions; expired rules don't apply")
+	flag.StringVar(&c.suppressionsAudit, "suppressions_audit", "", "with -suppressions, write the audit of the rules (CSV: rule, action, cve, cpe, expires, status, fired, cves) to this file once the input is processed, - for stderr")
+	flag.IntVar(&c.annotationsAt, "annotations", 0, "with -suppressions, output the rules annotating the matches (rule: justification) at this position (starts with 1); 0 disables the output")
+	flag.StringVar(&c.distroFixesPath, "distro_fixes", "", "path to CSV file with Linux distribution fixes (CVE,package,release,status, see redhat2fixes and alpine2fixes) amending the matches of rpm and apk packages: packages not affected or built with the backported fix are dropped, the others get the distribution's fixed version")
+	flag.StringVar(&c.minSeverity, "min_severity", "", "output only CVEs of this severity (low, medium, high or critical) or higher")
+	flag.Float64Var(&c.filter.MinCVSSScore, "min_cvss", 0, "output only CVEs with CVSS base score (v3 if available, v2 otherwise) of this value or higher")
+	flag.StringVar(&c.filterSource, "filter", "", "output only CVEs the expression is true of, e.g. 'cvss3 >= 7 && published_after(\"2022-01-01\") && !rejected'; "+
+		"variables: "+filterVarNames()+"; functions: published_after, published_before, modified_after, modified_before (YYYY-MM-DD), "+
+		"contains(text, substring), matches(text, regexp), has_cwe(cwe)")
+	flag.StringVar(&c.publishedAfter, "published_after", "", "match only CVEs published on this date (YYYY-MM-DD) or later")
+	flag.BoolVar(&c.includeUndated, "include_undated", false, "with -published_after, also match CVEs whose publication date is unknown")
+	flag.IntVar(&c.limit, "limit", 0, "output at most this many CVEs per input line, the most severe first; 0 removes the limit")
+	flag.StringVar(&c.cpeDictPath, "cpe_dict", "", "path to CPE dictionary (XML or NVD CPE API 2.0 response, plain or gzip'ed) to warn about deprecated input CPEs and their replacements")
+	flag.Var(&c.sources, "source", "name=path: feed of another source of CVEs (e.g. a vendor's or distribution's feed in NVD JSON format) merged with the NVD feeds passed as arguments, which are the source nvd; can be specified multiple times")
+	flag.StringVar(&c.sourcePriority, "source_priority", "", "with -source, comma separated list of sources in the order of priority: each field of a CVE comes from the first source defining it, sources not listed never contribute; defaults to nvd followed by the sources in the order of -source flags")
+	flag.Var(&c.fieldPriority, "field_priority", "with -source, field=src1,src2: the priority of sources for the field (config, cwe, cvss2, cvss3, description or dates) overriding -source_priority, e.g. config=vendor,nvd; can be specified multiple times")
+	flag.IntVar(&c.sourcesAt, "sources", 0, "with -source, output the sources CVEs were merged from at this position (starts with 1); 0 disables the output")
+	flag.IntVar(&c.provenanceAt, "provenance", 0, "with -source, output the sources of the fields of CVEs (field:source) at this position (starts with 1); 0 disables the output")
+	flag.StringVar(&c.asOf, "as_of", "", "match only CVEs known on this date (YYYY-MM-DD): published by then or, as per -journal, synced by then and not withdrawn; CVEs are matched with their current data")
+	flag.Var(&c.journal, "journal", "path to change journal recorded by nvdsync -journal for -as_of and -first_seen, can be specified multiple times")
+	flag.IntVar(&c.firstSeenAt, "first_seen", 0, "output the time CVEs were first synced as per -journal at this position (starts with 1), empty if the journal doesn't tell; 0 disables the output")
+	flag.Var(&c.matchCriteria, "match_criteria", "path to NVD 2.0 CPE match criteria feed, can be specified multiple times")
+	c.metrics.AddFlags(flag.CommandLine)
 }
 
 func (c *config) mustBeValid() {
+	if c.nProcessors < 1 {
+		glog.Errorf("-workers value is invalid %d, at least one worker is needed", c.nProcessors)
+		flag.Usage()
+	}
+	if c.filterSource != "" {
+		var err error
+		if c.filterExpr, err = cvefeed.ParseFilter(c.filterSource); err != nil {
+			glog.Errorf("-filter value is invalid: %v", err)
+			flag.Usage()
+		}
+	}
 	if flag.NArg() < 1 {
 		glog.Error("feed file wasn't provided")
 		flag.Usage()
 	}
-	if c.cpesAt <= 0 {
-		glog.Error("-cpe flag wasn't provided")
+	if c.sbomPath != "" {
+		if c.sbomOutput != "vex" && c.sbomOutput != "augmented" {
+			glog.Errorf("-sbom_output value is invalid %q", c.sbomOutput)
+			flag.Usage()
+		}
+	} else {
+		if c.cpesAt <= 0 {
+			glog.Error("-cpe flag wasn't provided")
+			flag.Usage()
+		}
+		if c.cvesAt <= 0 && !c.structuredOutput() {
+			glog.Error("-cve flag wasn't provided")
+			flag.Usage()
+		}
+	}
+	if c.indexDir != "" && (len(c.overrides) != 0 || c.indexedDict || c.validate || c.skipRejected || len(c.matchCriteria) != 0 || len(c.sources.names) != 0 || c.asOf != "" || len(c.journal) != 0) {
+		glog.Error("-index_dir can't be combined with -r, -idxd, -validate, -skip_rejected, -match_criteria, -source, -as_of and -journal")
+		flag.Usage()
+	}
+	if len(c.sources.names) != 0 {
+		var err error
+		if c.mergePolicy, err = c.sources.mergePolicy(c.sourcePriority, c.fieldPriority); err != nil {
+			glog.Errorf("-source_priority or -field_priority value is invalid: %v", err)
+			flag.Usage()
+		}
+	} else if c.sourcePriority != "" || len(c.fieldPriority) != 0 {
+		glog.Error("-source_priority and -field_priority require -source")
+		flag.Usage()
+	}
+	if c.sourcesAt < 0 {
+		glog.Errorf("-sources value is invalid %d", c.sourcesAt)
+		flag.Usage()
+	}
+	if c.provenanceAt < 0 {
+		glog.Errorf("-provenance value is invalid %d", c.provenanceAt)
+		flag.Usage()
+	}
+	if c.firstSeenAt < 0 || c.firstSeenAt > 0 && len(c.journal) == 0 {
+		glog.Errorf("-first_seen value is invalid %d, it requires -journal", c.firstSeenAt)
 		flag.Usage()
 	}
-	if c.cvesAt <= 0 {
-		glog.Error("-cve flag wasn't provided")
+	if c.annotationsAt < 0 || c.annotationsAt > 0 && c.suppressionsPath == "" {
+		glog.Errorf("-annotations value is invalid %d, it requires -suppressions", c.annotationsAt)
+		flag.Usage()
+	}
+	if c.suppressionsAudit != "" && c.suppressionsPath == "" {
+		glog.Error("-suppressions_audit requires -suppressions")
 		flag.Usage()
 	}
 	if c.matchesAt < 0 {
 		glog.Errorf("-matches value is invalid %d", c.matchesAt)
 		flag.Usage()
 	}
+	if c.platformsAt < 0 {
+		glog.Errorf("-platforms value is invalid %d", c.platformsAt)
+		flag.Usage()
+	}
+	if c.limit < 0 {
+		glog.Errorf("-limit value is invalid %d", c.limit)
+		flag.Usage()
+	}
 	if c.cwesAt < 0 {
 		glog.Errorf("-cwe value is invalid %d", c.cwesAt)
 		flag.Usage()
 	}
+	if c.cweNamesAt < 0 {
+		glog.Errorf("-cwe_name value is invalid %d", c.cweNamesAt)
+		flag.Usage()
+	}
+	if c.capecAt < 0 {
+		glog.Errorf("-capec value is invalid %d", c.capecAt)
+		flag.Usage()
+	}
+	if c.capecAt > 0 && c.cweCatalogPath == "" {
+		glog.Error("-capec requires -cwe_catalog, the built in catalog doesn't relate CWEs to CAPEC")
+		flag.Usage()
+	}
 	if c.cvss2at < 0 {
 		glog.Errorf("-cvss2 value is invalid %d", c.cvss2at)
 		flag.Usage()
@@ -104,52 +295,127 @@ func (c *config) mustBeValid() {
 		glog.Errorf("-cvss value is invalid %d", c.cvssAt)
 		flag.Usage()
 	}
+	if c.cvssAs != "" {
+		if _, err := cvss.NewVector(c.cvssAs); err != nil {
+			glog.Errorf("-cvss_as value is invalid: %v", err)
+			flag.Usage()
+		}
+	}
+	if c.cvssNotesAt < 0 || c.cvssNotesAt > 0 && c.cvssAs == "" {
+		glog.Errorf("-cvss_notes value is invalid %d, it requires -cvss_as", c.cvssNotesAt)
+		flag.Usage()
+	}
+	if c.kevAt < 0 {
+		glog.Errorf("-kev value is invalid %d", c.kevAt)
+		flag.Usage()
+	}
+	if c.statusAt < 0 {
+		glog.Errorf("-status value is invalid %d", c.statusAt)
+		flag.Usage()
+	}
+	if c.vendorCommentsAt < 0 {
+		glog.Errorf("-vendor_comments value is invalid %d", c.vendorCommentsAt)
+		flag.Usage()
+	}
+	if c.epssAt < 0 {
+		glog.Errorf("-epss value is invalid %d", c.epssAt)
+		flag.Usage()
+	}
+	if c.epssPercentileAt < 0 {
+		glog.Errorf("-epss_percentile value is invalid %d", c.epssPercentileAt)
+		flag.Usage()
+	}
 }
 
-func process(in <-chan []string, out chan<- []string, cache *cvefeed.Cache, cfg config, nlines *uint64) {
-	cpesAt := cfg.cpesAt - 1
-	for rec := range in {
-		if cpesAt >= len(rec) {
-			glog.Errorf("not enough fields in input (%d)", len(rec))
-			continue
-		}
-		cpeList := strings.Split(rec[cpesAt], cfg.inRecSep)
-		cpes := make([]*wfn.Attributes, len(cpeList))
-		for i, uri := range cpeList {
-			attr, err := wfn.Parse(uri)
-			if err != nil {
-				glog.Errorf("couldn't parse uri %q: %v", uri, err)
-				continue
-			}
-			cpes[i] = attr
-		}
-		rec[cpesAt] = strings.Join(cpeList, cfg.outRecSep)
-		for _, matches := range cache.Get(cpes) {
-			matchingCPEs := make([]string, len(matches.CPEs))
-			for i, attr := range matches.CPEs {
-				if attr == nil {
-					glog.Errorf("%s matches nil CPE", matches.CVE.CVEID())
-					continue
-				}
-				matchingCPEs[i] = (*wfn.Attributes)(attr).BindToURI()
-			}
-			rec2 := make([]string, len(rec))
-			copy(rec2, rec)
-			cvss := matches.CVE.CVSS30base()
-			if cvss == 0 {
-				cvss = matches.CVE.CVSS20base()
-			}
-			rec2 = cfg.skip.appendAt(
-				rec2,
-				cfg.cvesAt-1, matches.CVE.CVEID(),
-				cfg.matchesAt-1, strings.Join(matchingCPEs, cfg.outRecSep),
-				cfg.cwesAt-1, strings.Join(matches.CVE.ProblemTypes(), cfg.outRecSep),
-				cfg.cvss2at-1, fmt.Sprintf("%.1f", matches.CVE.CVSS20base()),
-				cfg.cvss3at-1, fmt.Sprintf("%.1f", matches.CVE.CVSS30base()),
-				cfg.cvssAt-1, fmt.Sprintf("%.1f", cvss),
-			)
-			out <- rec2
+// match matches the inventory against the dictionary, applying distribution fixes, exceptions, suppressions, KEV catalog, filters and limit as
+// configured; returns true if the results were truncated to the limit
+func (cfg config) match(cache *cvefeed.Cache, cpes []*wfn.Attributes) ([]cvefeed.MatchResult, bool) {
+	start := time.Now()
+	defer matchDuration.Since(start)
+	inventoriesMatched.Inc()
+	var results []cvefeed.MatchResult
+	if cfg.softMatch {
+		results = cache.GetSoft(cpes)
+	} else {
+		results = cache.Get(cpes)
+	}
+	if cfg.distroFixes != nil {
+		results = cfg.distroFixes.Apply(results)
+	}
+	if cfg.exceptions != nil {
+		results = cfg.exceptions.Apply(results)
+	}
+	if cfg.suppressions != nil {
+		results = cfg.suppressions.Apply(results)
+	}
+	if cfg.kevOnly {
+		results = cfg.kev.Filter(results, cfg.kevDue)
+	} else if cfg.kev != nil {
+		results = cfg.kev.Apply(results)
+	}
+	results = cfg.filter.Apply(results)
+	if cfg.filterExpr != nil {
+		results = cfg.filterExpr.Apply(results)
+	}
+	var truncated bool
+	if cfg.limit > 0 {
+		results, truncated = cvefeed.LimitResults(results, cfg.limit, cvefeed.BySeverity)
+	}
+	cvesMatched.Add(float64(len(results)))
+	return results, truncated
+}
+
+// processSBOM matches the components of CycloneDX SBOM read from in and writes the vulnerabilities found
+// to out, as VEX document or the SBOM augmented with them, as per cfg.sbomOutput
+func processSBOM(in io.Reader, out io.Writer, cache *cvefeed.Cache, cfg config) error {
+	sbom, err := cyclonedx.ParseSBOM(in)
+	if err != nil {
+		return err
+	}
+	findings, errs := sbom.Match(func(cpes []*wfn.Attributes) []cvefeed.MatchResult {
+		results, truncated := cfg.match(cache, cpes)
+		if truncated {
+			glog.V(1).Infof("output of %q truncated to %d CVEs", cpes[0].BindToFmtString(), cfg.limit)
 		}
+		return results
+	})
+	for _, err := range errs {
+		glog.Errorf("skipped component: %v", err)
+	}
+	glog.V(1).Infof("found vulnerabilities in %d components", len(findings))
+	if cfg.sbomOutput == "augmented" {
+		return sbom.WriteAugmented(out, findings)
+	}
+	return sbom.VEX(findings).Write(out)
+}
+
+// rowOutput is the output of an input row: delimited records, one per CVE, the structured record in JSON and
+// NDJSON output modes or the match results in SARIF output mode
+type rowOutput struct {
+	records    [][]string
+	structured *cvefeed.ScanRecord
+	results    []cvefeed.MatchResult
+}
+
+// structuredOutput tells the output is JSON, NDJSON or SARIF rather than delimited
+func (c *config) structuredOutput() bool {
+	return c.outFieldSep == "json" || c.outFieldSep == "ndjson" || c.outFieldSep == "sarif"
+}
+
+// empty tells there's nothing to write of the row
+func (row rowOutput) empty() bool {
+	return row.structured == nil && len(row.records) == 0 && len(row.results) == 0
+}
+
+// job is an input row along with the channel its output is sent to, see processInput
+type job struct {
+	rec []string
+	out chan<- rowOutput
+}
+
+func process(in <-chan job, cache *cvefeed.Cache, cfg config, nlines *uint64) {
+	for j := range in {
+		j.out <- processRecord(j.rec, cache, cfg)
 		n := atomic.AddUint64(nlines, 1)
 		if n > 0 {
 			if n%10000 == 0 {
@@ -163,10 +429,143 @@ func process(in <-chan []string, out chan<- []string, cache *cvefeed.Cache, cfg
 	}
 }
 
+// processRecord returns the output of the input row, empty if the row has no matches to write or is malformed
+func processRecord(rec []string, cache *cvefeed.Cache, cfg config) rowOutput {
+	var row rowOutput
+	cpesAt := cfg.cpesAt - 1
+	if cpesAt >= len(rec) {
+		glog.Errorf("not enough fields in input (%d)", len(rec))
+		return row
+	}
+	cpeList := strings.Split(rec[cpesAt], cfg.inRecSep)
+	cpes := make([]*wfn.Attributes, len(cpeList))
+	for i, uri := range cpeList {
+		if cfg.collapseEscapes {
+			uri, _ = wfn.CollapseDoubleEscaping(uri)
+		}
+		attr, err := wfn.Parse(uri)
+		if err != nil {
+			glog.Errorf("couldn't parse uri %q: %v", uri, err)
+			continue
+		}
+		cpes[i] = attr
+		if warning := deprecationWarning(cfg.cpeDict, attr); warning != "" {
+			glog.Warningf("%s: %s", uri, warning)
+		}
+	}
+	rec[cpesAt] = strings.Join(cpeList, cfg.outRecSep)
+	results, truncated := cfg.match(cache, cpes)
+	if truncated {
+		glog.V(1).Infof("output of %q truncated to %d CVEs", rec[cpesAt], cfg.limit)
+	}
+	if cfg.outFieldSep == "sarif" {
+		// the log is made of the results of all rows at once, see writeSARIF
+		row.results, results = results, nil
+	} else if cfg.structuredOutput() {
+		input := cfg.skip.skipFields(append([]string(nil), rec...))
+		row.structured = &cvefeed.ScanRecord{Input: input, Vulnerabilities: []cvefeed.MatchRecord{}}
+	}
+	for _, matches := range results {
+		epss := cfg.epss[matches.CVE.CVEID()]
+		var kevDue string
+		if e := cfg.kev[matches.CVE.CVEID()]; e != nil {
+			kevDue = e.DueDate
+		}
+		if row.structured != nil {
+			mr := cvefeed.NewMatchRecord(matches)
+			if epss != nil {
+				mr.EPSS, mr.EPSSPercentile = &epss.Probability, &epss.Percentile
+			}
+			mr.KEVDueDate = kevDue
+			row.structured.Vulnerabilities = append(row.structured.Vulnerabilities, mr)
+			continue
+		}
+		matchingCPEs := make([]string, len(matches.CPEs))
+		for i, attr := range matches.CPEs {
+			if attr == nil {
+				glog.Errorf("%s matches nil CPE", matches.CVE.CVEID())
+				continue
+			}
+			matchingCPEs[i] = (*wfn.Attributes)(attr).BindToURI()
+		}
+		var platformCPEs []string
+		if cfg.platformsAt > 0 {
+			for _, attr := range matches.PlatformCPEs() {
+				if attr != nil {
+					platformCPEs = append(platformCPEs, attr.BindToURI())
+				}
+			}
+		}
+		var epssProbability, epssPercentile string
+		if epss != nil {
+			epssProbability = strconv.FormatFloat(epss.Probability, 'f', -1, 64)
+			epssPercentile = strconv.FormatFloat(epss.Percentile, 'f', -1, 64)
+		}
+		var cweNames, capec []string
+		if cfg.cweCatalog != nil {
+			cweNames = cfg.cweCatalog.Names(matches.CVE.ProblemTypes())
+			capec = cfg.cweCatalog.CAPEC(matches.CVE.ProblemTypes())
+		}
+		score := cvefeed.RepresentativeScore(matches.CVE).Score
+		var cvssNotes []string
+		if cfg.cvssAs != "" {
+			var err error
+			if score, cvssNotes, err = cvefeed.ConvertedScore(matches.CVE, cfg.cvssAs); err != nil {
+				glog.Errorf("couldn't convert CVSS score: %v", err)
+			}
+		}
+		var firstSeen string
+		if cfg.history != nil {
+			if tl, _ := cfg.history.Timeline(matches.CVE.CVEID()); !tl.FirstSeen.IsZero() {
+				firstSeen = tl.FirstSeen.UTC().Format(time.RFC3339)
+			}
+		}
+		var vendorComments []string
+		if cfg.vendorCommentsAt > 0 {
+			for _, c := range cvefeed.VendorComments(matches.CVE) {
+				vendorComments = append(vendorComments, c.Organization+": "+c.Comment)
+			}
+		}
+		var annotations []string
+		for _, a := range matches.Annotations {
+			annotations = append(annotations, a.String())
+		}
+		rec2 := make([]string, len(rec))
+		copy(rec2, rec)
+		rec2 = cfg.skip.appendAt(
+			rec2,
+			cfg.cvesAt-1, matches.CVE.CVEID(),
+			cfg.matchesAt-1, strings.Join(matchingCPEs, cfg.outRecSep),
+			cfg.platformsAt-1, strings.Join(platformCPEs, cfg.outRecSep),
+			cfg.cwesAt-1, strings.Join(matches.CVE.ProblemTypes(), cfg.outRecSep),
+			cfg.cweNamesAt-1, strings.Join(cweNames, cfg.outRecSep),
+			cfg.capecAt-1, strings.Join(capec, cfg.outRecSep),
+			cfg.cvss2at-1, fmt.Sprintf("%.1f", matches.CVE.CVSS20base()),
+			cfg.cvss3at-1, fmt.Sprintf("%.1f", matches.CVE.CVSS30base()),
+			cfg.cvssAt-1, fmt.Sprintf("%.1f", score),
+			cfg.cvssNotesAt-1, strings.Join(cvssNotes, cfg.outRecSep),
+			cfg.epssAt-1, epssProbability,
+			cfg.kevAt-1, kevDue,
+			cfg.epssPercentileAt-1, epssPercentile,
+			cfg.statusAt-1, cvefeed.CVEStatus(matches.CVE),
+			cfg.vendorCommentsAt-1, strings.Join(vendorComments, cfg.outRecSep),
+			cfg.sourcesAt-1, strings.Join(cvefeed.CVESources(matches.CVE), cfg.outRecSep),
+			cfg.provenanceAt-1, strings.Join(provenanceList(matches.CVE), cfg.outRecSep),
+			cfg.firstSeenAt-1, firstSeen,
+			cfg.annotationsAt-1, strings.Join(annotations, cfg.outRecSep),
+		)
+		row.records = append(row.records, rec2)
+	}
+	return row
+}
+
 func processInput(in io.Reader, out io.Writer, cache *cvefeed.Cache, cfg config) chan struct{} {
 	done := make(chan struct{})
-	procIn := make(chan []string)
-	procOut := make(chan []string)
+	procIn := make(chan job)
+	// the output channels of the rows in the order of the input; the rows a slow one holds back are bounded
+	// by its capacity, so the memory doesn't grow with the input
+	pending := make(chan chan rowOutput, 4*cfg.nProcessors)
+	procOut := make(chan rowOutput)
 
 	r := csv.NewReader(in)
 	r.Comma = rune(cfg.inFieldSep[0])
@@ -180,16 +579,38 @@ func processInput(in io.Reader, out io.Writer, cache *cvefeed.Cache, cfg config)
 	procWG.Add(cfg.nProcessors)
 	for i := 0; i < cfg.nProcessors; i++ {
 		go func() {
-			process(procIn, procOut, cache, cfg, &linesProcessed)
+			process(procIn, cache, cfg, &linesProcessed)
 			procWG.Done()
 		}()
 	}
 
+	// pass the output of the rows on in the order of the input, as they're done
+	go func() {
+		for res := range pending {
+			if row := <-res; !row.empty() {
+				procOut <- row
+			}
+		}
+		close(procOut)
+	}()
+
 	// write processed results in background
 	go func() {
-		for rec := range procOut {
-			if err := w.Write(rec); err != nil {
-				glog.Errorf("write error: %v", err)
+		if cfg.outFieldSep == "sarif" {
+			writeSARIF(out, procOut, cfg.sarifArtifact)
+			close(done)
+			return
+		}
+		if cfg.structuredOutput() {
+			writeStructured(out, procOut, cfg.outFieldSep == "json")
+			close(done)
+			return
+		}
+		for row := range procOut {
+			for _, rec := range row.records {
+				if err := w.Write(rec); err != nil {
+					glog.Errorf("write error: %v", err)
+				}
 			}
 			w.Flush()
 		}
@@ -209,16 +630,133 @@ func processInput(in io.Reader, out io.Writer, cache *cvefeed.Cache, cfg config)
 			}
 			glog.Errorf("read error at line %d: %v", line, err)
 		}
-		procIn <- rec
+		res := make(chan rowOutput, 1)
+		pending <- res
+		procIn <- job{rec: rec, out: res}
 	}
 
 	close(procIn)
+	close(pending)
 	procWG.Wait()
-	close(procOut)
 	glog.V(1).Infof("processed %d lines in %v", linesProcessed, time.Since(start))
 	return done
 }
 
+// writeStructured writes the structured records of the rows as NDJSON, one record per line, or as JSON array
+func writeStructured(out io.Writer, rows <-chan rowOutput, array bool) {
+	next := "[\n"
+	if !array {
+		next = ""
+	}
+	var failed bool
+	write := func(b []byte) {
+		if _, err := out.Write(b); err != nil && !failed {
+			glog.Errorf("write error: %v", err)
+			failed = true
+		}
+	}
+	for row := range rows {
+		b, err := json.Marshal(row.structured)
+		if err != nil {
+			glog.Errorf("couldn't encode the record of %q: %v", row.structured.Input, err)
+			continue
+		}
+		write(append([]byte(next), b...))
+		if array {
+			next = ",\n"
+		} else {
+			write([]byte("\n"))
+		}
+	}
+	if array {
+		if next == "[\n" {
+			write([]byte("[]\n"))
+		} else {
+			write([]byte("\n]\n"))
+		}
+	}
+}
+
+// writeSARIF writes the match results of all rows as SARIF log located in the artifact, once the rows are over
+func writeSARIF(out io.Writer, rows <-chan rowOutput, artifact string) {
+	var results []cvefeed.MatchResult
+	for row := range rows {
+		results = append(results, row.results...)
+	}
+	if err := sarif.FromMatchResults(results, artifact).Write(out); err != nil {
+		glog.Errorf("write error: %v", err)
+	}
+}
+
+// filterVarNames returns the variables of -filter expressions in lexical order
+func filterVarNames() string {
+	names := make([]string, 0, len(cvefeed.FilterVars))
+	for name := range cvefeed.FilterVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// reportSuppressions warns about the expired suppression rules which still match and writes the audit of the rules
+// to the file, if any
+func reportSuppressions(s *cvefeed.Suppressions, auditPath string) {
+	for _, a := range s.Audit() {
+		if a.Expired && a.Fired != 0 {
+			glog.Warningf("suppression rule %s expired on %s, but still matches %d findings (%s)",
+				a.Rule.ID, a.Rule.Expires, a.Fired, strings.Join(a.CVEs, ", "))
+		}
+	}
+	if auditPath == "" {
+		return
+	}
+	out := os.Stderr
+	if auditPath != "-" {
+		f, err := os.Create(auditPath)
+		if err != nil {
+			glog.Errorf("couldn't write suppressions audit: %v", err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := s.WriteAudit(out); err != nil {
+		glog.Errorf("couldn't write suppressions audit: %v", err)
+	}
+}
+
+// deprecationWarning returns the warning about the name deprecated in the CPE dictionary, suggesting its replacements;
+// it's empty if the name isn't deprecated or there's no dictionary
+func deprecationWarning(dict *cpedict.Index, name *wfn.Attributes) string {
+	if dict == nil {
+		return ""
+	}
+	replacements, deprecated := dict.Replacements(cpedict.NamePattern(*name))
+	if !deprecated {
+		return ""
+	}
+	if len(replacements) == 0 {
+		return "deprecated in CPE dictionary"
+	}
+	names := make([]string, len(replacements))
+	for i, item := range replacements {
+		replacement := item.CPE23.Name
+		if replacement == (cpedict.NamePattern{}) {
+			replacement = item.Name
+		}
+		names[i] = wfn.Attributes(replacement).BindToFmtString()
+		if title := item.TitleIn("en"); title != "" {
+			names[i] += fmt.Sprintf(" (%s)", title)
+		}
+	}
+	return "deprecated in CPE dictionary, replaced by " + strings.Join(names, ", ")
+}
+
+// isURL tells the source of enrichment data (e.g. EPSS scores) is to be downloaded rather than read from file
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
 func init() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.xml.gz...\n", path.Base(os.Args[0]))
@@ -234,25 +772,60 @@ func main() {
 	cfg.addFlags()
 	flag.Parse()
 	cfg.mustBeValid()
+	cvefeed.SetLogger(cvefeed.GlogLogger{})
+
+	stopMetrics, err := cfg.metrics.Start()
+	if err != nil {
+		glog.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			glog.Errorf("couldn't write metrics: %v", err)
+		}
+	}()
 
-	glog.V(1).Info("loading NVD feeds...")
-	start := time.Now()
 	if cfg.feedFormat != "json" {
 		glog.Fatalf("unknown vulnerability feed format %q", cfg.feedFormat)
 	}
-	var overrides cvefeed.Dictionary
-	dict, err := cvefeed.LoadJSONDictionary(flag.Args()...)
-	if err == nil {
-		overrides, err = cvefeed.LoadJSONDictionary(cfg.overrides...)
-	}
-	if err != nil {
-		glog.Error(err)
-		if len(dict) == 0 {
-			glog.Error("dictionary is empty")
-			os.Exit(-1)
+	var dict, overrides cvefeed.Dictionary
+	var diskIndex *cvefeed.DiskIndex
+	start := time.Now()
+	if cfg.indexDir != "" {
+		glog.V(1).Infof("opening the index of NVD feeds in %q...", cfg.indexDir)
+		var built bool
+		diskIndex, built, err = cvefeed.OpenOrBuildDiskIndex(cvefeed.LoadOptions{IndexDir: cfg.indexDir}, flag.Args()...)
+		if err != nil {
+			glog.Error(err)
+			if diskIndex == nil || diskIndex.Len() == 0 {
+				glog.Error("dictionary is empty")
+				os.Exit(-1)
+			}
+		}
+		defer diskIndex.Close()
+		glog.V(1).Infof("...%d CVEs in the index (built %v) in %v", diskIndex.Len(), built, time.Since(start))
+	} else {
+		glog.V(1).Info("loading NVD feeds...")
+		loadDictionary := cvefeed.LoadJSONDictionary
+		if cfg.validate {
+			loadDictionary = cvefeed.LoadValidatedJSONDictionary
+		}
+		dict, err = loadDictionary(flag.Args()...)
+		if err == nil {
+			overrides, err = loadDictionary(cfg.overrides...)
 		}
+		if err != nil {
+			glog.Error(err)
+			if len(dict) == 0 {
+				glog.Error("dictionary is empty")
+				os.Exit(-1)
+			}
+		}
+		glog.V(1).Infof("...done in %v", time.Since(start))
+	}
+
+	if cfg.skipRejected {
+		glog.V(1).Infof("skipped %d rejected CVEs", dict.DropRejected())
 	}
-	glog.V(1).Infof("...done in %v", time.Since(start))
 
 	if len(overrides) != 0 {
 		start = time.Now()
@@ -261,7 +834,149 @@ func main() {
 		glog.V(1).Infof("...done in %v", time.Since(start))
 	}
 
-	cache := cvefeed.NewCache(dict).SetRequireVersion(cfg.requireVersion).SetMaxSize(cfg.cacheSize)
+	if cfg.minSeverity != "" {
+		if cfg.filter.MinSeverity, err = cvss.ParseSeverity(cfg.minSeverity); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if cfg.distroFixesPath != "" {
+		if cfg.distroFixes, err = cvefeed.LoadDistroFixes(cfg.distroFixesPath); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if cfg.exceptionsPath != "" {
+		if cfg.exceptions, err = cvefeed.LoadExceptions(cfg.exceptionsPath); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if cfg.suppressionsPath != "" {
+		if cfg.suppressions, err = cvefeed.LoadSuppressions(cfg.suppressionsPath); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if cfg.kevDueBefore != "" {
+		if cfg.kevDue, err = time.Parse("2006-01-02", cfg.kevDueBefore); err != nil {
+			glog.Fatalf("-kev_due_before value is invalid: %v", err)
+		}
+		cfg.kevOnly = true
+	}
+
+	if cfg.kevAt > 0 || cfg.kevOnly || cfg.filterExpr != nil && cfg.filterExpr.Uses("kev") {
+		start = time.Now()
+		glog.V(1).Infof("loading KEV catalog from %q...", cfg.kevSource)
+		if isURL(cfg.kevSource) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			cfg.kev, err = cvefeed.FetchKEV(ctx, cfg.kevSource)
+			cancel()
+		} else {
+			cfg.kev, err = cvefeed.LoadKEV(cfg.kevSource)
+		}
+		if err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("...%d CVEs loaded in %v", len(cfg.kev), time.Since(start))
+	}
+
+	if cfg.cweCatalogPath != "" {
+		start = time.Now()
+		glog.V(1).Infof("loading CWE catalog from %q...", cfg.cweCatalogPath)
+		if cfg.cweCatalog, err = cvefeed.LoadCWECatalog(cfg.cweCatalogPath); err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("...%d CWEs loaded in %v", len(cfg.cweCatalog), time.Since(start))
+	} else if cfg.cweNamesAt > 0 {
+		cfg.cweCatalog = cvefeed.DefaultCWECatalog()
+	}
+
+	if cfg.epssAt > 0 || cfg.epssPercentileAt > 0 || cfg.filterExpr != nil && cfg.filterExpr.Uses("epss", "epss_percentile") {
+		start = time.Now()
+		glog.V(1).Infof("loading EPSS scores from %q...", cfg.epssSource)
+		if isURL(cfg.epssSource) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			cfg.epss, err = cvefeed.FetchEPSS(ctx, cfg.epssSource)
+			cancel()
+		} else {
+			cfg.epss, err = cvefeed.LoadEPSS(cfg.epssSource)
+		}
+		if err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("...%d scores loaded in %v", len(cfg.epss), time.Since(start))
+		if cfg.filterExpr != nil {
+			cfg.filterExpr.SetEPSS(cfg.epss)
+		}
+	}
+
+	if len(cfg.matchCriteria) != 0 {
+		start = time.Now()
+		glog.V(1).Info("applying match criteria...")
+		mc, err := cvefeed.LoadMatchCriteria(cfg.matchCriteria...)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		dict.ApplyMatchCriteria(mc)
+		glog.V(1).Infof("...done in %v", time.Since(start))
+	}
+
+	if len(cfg.sources.names) != 0 {
+		start = time.Now()
+		glog.V(1).Info("merging sources...")
+		sources := map[string]cvefeed.Dictionary{nvdSource: dict}
+		for _, name := range cfg.sources.names {
+			if sources[name], err = cvefeed.LoadJSONDictionary(cfg.sources.feeds[name]...); err != nil {
+				glog.Fatalf("source %s: %v", name, err)
+			}
+		}
+		dict = cvefeed.MergeDictionaries(sources, cfg.mergePolicy)
+		glog.V(1).Infof("...%d CVEs merged in %v", len(dict), time.Since(start))
+	}
+
+	if cfg.asOf != "" || len(cfg.journal) != 0 {
+		var asOf time.Time
+		if cfg.asOf != "" {
+			if asOf, err = time.Parse("2006-01-02", cfg.asOf); err != nil {
+				glog.Fatalf("-as_of value is invalid: %v", err)
+			}
+			asOf = asOf.Add(24*time.Hour - time.Nanosecond) // known on the date means known by the end of it
+		}
+		journal, err := cvefeed.LoadJournal(cfg.journal...)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		cfg.history = cvefeed.NewHistory(dict, journal)
+		if !asOf.IsZero() {
+			dict = cfg.history.AsOf(asOf)
+			glog.V(1).Infof("%d CVEs known on %s, %d of them modified since", len(dict), cfg.asOf, len(cfg.history.ModifiedAfter(asOf)))
+		}
+	}
+
+	cache := cvefeed.NewCache(dict).SetRequireVersion(cfg.requireVersion).SetWildcardVersions(cfg.wildcardVersions).SetMatchOptions(cfg.matchOpts).SetMaxSize(cfg.cacheSize).SetRecoverPanics(cfg.recoverPanics)
+	if diskIndex != nil {
+		cache.SetSource(diskIndex)
+		cvesLoaded.Set(float64(diskIndex.Len()))
+	} else {
+		cvesLoaded.Set(float64(len(dict)))
+	}
+
+	if cfg.versionCmp != "" {
+		vc, err := cvefeed.ParseVersionComparators(cfg.versionCmp)
+		if err != nil {
+			glog.Fatalf("-version_cmp value is invalid: %v", err)
+		}
+		cache.SetVersionComparators(vc)
+	}
+
+	if cfg.publishedAfter != "" {
+		cutoff, err := time.Parse("2006-01-02", cfg.publishedAfter)
+		if err != nil {
+			glog.Fatalf("-published_after value is invalid: %v", err)
+		}
+		cache.SetPublishedAfter(cutoff, cfg.includeUndated)
+	}
 
 	if cfg.indexedDict {
 		start = time.Now()
@@ -280,6 +995,21 @@ func main() {
 		}
 	}
 
+	if cfg.cpeDictPath != "" {
+		start = time.Now()
+		glog.V(1).Infof("loading CPE dictionary from %q...", cfg.cpeDictPath)
+		f, err := os.Open(cfg.cpeDictPath)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		cfg.cpeDict, err = cpedict.LoadIndex(f, nil)
+		f.Close()
+		if err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("...%d CPEs loaded in %v", cfg.cpeDict.Len(), time.Since(start))
+	}
+
 	if cfg.cpuProfile != "" {
 		f, err := os.Create(cfg.cpuProfile)
 		if err != nil {
@@ -289,7 +1019,21 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	done := processInput(os.Stdin, os.Stdout, cache, cfg)
+	var done chan struct{}
+	if cfg.sbomPath != "" {
+		in := os.Stdin
+		if cfg.sbomPath != "-" {
+			if in, err = os.Open(cfg.sbomPath); err != nil {
+				glog.Fatal(err)
+			}
+			defer in.Close()
+		}
+		if err = processSBOM(in, os.Stdout, cache, cfg); err != nil {
+			glog.Fatal(err)
+		}
+	} else {
+		done = processInput(os.Stdin, os.Stdout, cache, cfg)
+	}
 
 	if cfg.memProfile != "" {
 		f, err := os.Create(cfg.memProfile)
@@ -303,5 +1047,19 @@ func main() {
 		f.Close()
 	}
 
-	<-done
+	if done != nil {
+		<-done
+	}
+
+	if cfg.suppressions != nil {
+		reportSuppressions(cfg.suppressions, cfg.suppressionsAudit)
+	}
+
+	if skipped := cache.Skipped(); len(skipped) != 0 {
+		ids := make([]string, len(skipped))
+		for i, err := range skipped {
+			ids[i] = err.CVEID
+		}
+		glog.Errorf("skipped %d CVEs which couldn't be evaluated: %s", len(ids), strings.Join(ids, ", "))
+	}
 }
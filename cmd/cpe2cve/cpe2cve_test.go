@@ -16,11 +16,19 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/facebookincubator/nvdtools/cpedict"
 	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/sarif"
+	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 func TestAppendAt(t *testing.T) {
@@ -60,11 +68,11 @@ func TestProcessInput(t *testing.T) {
 			in: "1,2,3,cpe:/o:microsoft:windows_10:-::~~~~x64~+cpe:/a:adobe:flash_player:24.0.0.194,5,6,7,8,9,10",
 			out: [][]string{
 				{
-					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2016-0165|cpe:/o:microsoft:windows_10:::~~~~x64~|8|9|10",
+					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2016-0165|cpe:/o:microsoft:windows_10:-::~~~~x64~|8|9|10",
 				},
 				{
-					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2666-1337|cpe:/o:microsoft:windows_10:::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|8|9|10",
-					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2666-1337|cpe:/a:adobe:flash_player:24.0.0.194&cpe:/o:microsoft:windows_10:::~~~~x64~|8|9|10",
+					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2666-1337|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|8|9|10",
+					"2|cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|5|6|7|CVE-2666-1337|cpe:/a:adobe:flash_player:24.0.0.194&cpe:/o:microsoft:windows_10:-::~~~~x64~|8|9|10",
 				},
 			},
 		},
@@ -178,6 +186,425 @@ func TestProcessInputRequireVersion(t *testing.T) {
 	}
 }
 
+func TestProcessInputEPSS(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~;cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	epss, err := cvefeed.ParseEPSS(strings.NewReader("cve,epss,percentile\nCVE-2016-0165,0.0137,0.8566\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		nProcessors:      1,
+		cpesAt:           1,
+		cvesAt:           2,
+		epssAt:           3,
+		epssPercentileAt: 4,
+		inFieldSep:       ",",
+		inRecSep:         ";",
+		outFieldSep:      ",",
+		outRecSep:        ";",
+		epss:             epss,
+	}
+	var w bytes.Buffer
+	done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+	<-done
+	expected := map[string]string{
+		"CVE-2016-0165": "0.0137,0.8566",
+		"CVE-2666-1337": ",", // not scored
+	}
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(expected), len(got), w.String())
+	}
+	for _, line := range got {
+		fields := strings.SplitN(line, ",", 3)
+		if len(fields) != 3 || expected[fields[1]] != fields[2] {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
+
+func TestProcessInputKEV(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~;cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	kev, err := cvefeed.ParseKEV(strings.NewReader(`{"vulnerabilities":[{"cveID":"CVE-2016-0165","dueDate":"2022-05-03"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kevOnly := range []bool{false, true} {
+		cfg := config{
+			nProcessors: 1,
+			cpesAt:      1,
+			cvesAt:      2,
+			kevAt:       3,
+			inFieldSep:  ",",
+			inRecSep:    ";",
+			outFieldSep: ",",
+			outRecSep:   ";",
+			kev:         kev,
+			kevOnly:     kevOnly,
+		}
+		var w bytes.Buffer
+		done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+		<-done
+		expected := map[string]string{"CVE-2016-0165": "2022-05-03"}
+		if !kevOnly {
+			expected["CVE-2666-1337"] = "" // not listed
+		}
+		got := strings.Split(strings.TrimSpace(w.String()), "\n")
+		if len(got) != len(expected) {
+			t.Fatalf("kev only %v: expected %d lines, got %d:\n%s", kevOnly, len(expected), len(got), w.String())
+		}
+		for _, line := range got {
+			fields := strings.SplitN(line, ",", 3)
+			if due, ok := expected[fields[1]]; len(fields) != 3 || !ok || due != fields[2] {
+				t.Errorf("kev only %v: unexpected line %q", kevOnly, line)
+			}
+		}
+	}
+}
+
+func TestProcessInputSuppressions(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~;cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	suppressions, err := cvefeed.ParseSuppressions(strings.NewReader(`[
+  {"id": "win", "cve": "CVE-2016-0165", "justification": "not deployed"},
+  {"id": "flash", "cve": "CVE-2666-*", "justification": "sandboxed", "action": "annotate"}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		nProcessors:   1,
+		cpesAt:        1,
+		cvesAt:        2,
+		annotationsAt: 3,
+		inFieldSep:    ",",
+		inRecSep:      ";",
+		outFieldSep:   ",",
+		outRecSep:     ";",
+		suppressions:  suppressions,
+	}
+	var w bytes.Buffer
+	done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(got) != 1 || !strings.HasSuffix(got[0], ",CVE-2666-1337,flash: sandboxed") {
+		t.Fatalf("unexpected output:\n%s", w.String())
+	}
+	var audit bytes.Buffer
+	if err := suppressions.WriteAudit(&audit); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(audit.String(), "win,suppress,CVE-2016-0165,,,active,1,CVE-2016-0165") {
+		t.Errorf("unexpected audit:\n%s", audit.String())
+	}
+}
+
+func TestProcessInputFilter(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~;cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	for expr, expected := range map[string]string{
+		`cve == "CVE-2016-0165"`:    "CVE-2016-0165",
+		`!(cve == "CVE-2016-0165")`: "CVE-2666-1337",
+		`false`:                     "",
+	} {
+		filter, err := cvefeed.ParseFilter(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := config{
+			nProcessors: 1,
+			cpesAt:      1,
+			cvesAt:      2,
+			inFieldSep:  ",",
+			inRecSep:    ";",
+			outFieldSep: ",",
+			outRecSep:   ";",
+			filterExpr:  filter,
+		}
+		var w bytes.Buffer
+		done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+		<-done
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+			if fields := strings.Split(line, ","); len(fields) > 1 {
+				got = append(got, fields[1])
+			}
+		}
+		if strings.Join(got, " ") != expected {
+			t.Errorf("%s: expected %q, got:\n%s", expr, expected, w.String())
+		}
+	}
+}
+
+const testDictJSON20Status = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2020-0001","vulnStatus":"Analyzed",
+		"vendorComments":[{"organization":"Acme","comment":"Fixed in 1.1."},{"organization":"Distro","comment":"Backported."}],
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}},
+	{"cve":{"id":"CVE-2020-0002","vulnStatus":"Rejected",
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+
+func TestProcessInputStatus(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSON20Status))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		nProcessors:      1,
+		cpesAt:           1,
+		cvesAt:           2,
+		statusAt:         3,
+		vendorCommentsAt: 4,
+		inFieldSep:       ",",
+		inRecSep:         ";",
+		outFieldSep:      ",",
+		outRecSep:        ";",
+	}
+	for _, skipRejected := range []bool{false, true} {
+		expected := map[string]string{"CVE-2020-0001": "Analyzed,Acme: Fixed in 1.1.;Distro: Backported."}
+		if skipRejected {
+			dict.DropRejected()
+		} else {
+			expected["CVE-2020-0002"] = "Rejected,"
+		}
+		var w bytes.Buffer
+		done := processInput(strings.NewReader("cpe:/a:acme:widget:1.0"), &w, cvefeed.NewCache(dict), cfg)
+		<-done
+		got := strings.Split(strings.TrimSpace(w.String()), "\n")
+		if len(got) != len(expected) {
+			t.Fatalf("skip rejected %v: expected %d lines, got %d:\n%s", skipRejected, len(expected), len(got), w.String())
+		}
+		for _, line := range got {
+			fields := strings.SplitN(line, ",", 3)
+			if status, ok := expected[fields[1]]; len(fields) != 3 || !ok || status != fields[2] {
+				t.Errorf("skip rejected %v: unexpected line %q", skipRejected, line)
+			}
+		}
+	}
+}
+
+func TestProcessInputCWE(t *testing.T) {
+	feed := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001",
+		"weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-79"}]},
+			{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-1004"}]}],
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(feed))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	catalog, err := cvefeed.ParseCWECatalog(strings.NewReader("CWE-ID,Name,Related Attack Patterns\n79,Cross-site Scripting,::63::588::\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		catalog  cvefeed.CWECatalog
+		expected string
+	}{
+		{cvefeed.DefaultCWECatalog(), "cpe:/a:acme:widget:1.0,CVE-2020-0001,CWE-79;CWE-1004," +
+			"Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting');,\n"},
+		{catalog, "cpe:/a:acme:widget:1.0,CVE-2020-0001,CWE-79;CWE-1004,Cross-site Scripting;,CAPEC-63;CAPEC-588\n"},
+	}
+	for _, c := range cases {
+		cfg := config{
+			nProcessors: 1,
+			cpesAt:      1,
+			cvesAt:      2,
+			cwesAt:      3,
+			cweNamesAt:  4,
+			capecAt:     5,
+			inFieldSep:  ",",
+			inRecSep:    ";",
+			outFieldSep: ",",
+			outRecSep:   ";",
+			cweCatalog:  c.catalog,
+		}
+		var w bytes.Buffer
+		done := processInput(strings.NewReader("cpe:/a:acme:widget:1.0"), &w, cvefeed.NewCache(dict), cfg)
+		<-done
+		if w.String() != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, w.String())
+		}
+	}
+}
+
+func TestProcessInputStructured(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSON20Status))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := "host1\tcpe:/a:acme:widget:1.0\nhost2\tcpe:/a:acme:gadget:1.0\n"
+	for _, format := range []string{"json", "ndjson"} {
+		cfg := config{
+			nProcessors: 1,
+			cpesAt:      2,
+			inFieldSep:  "\t",
+			inRecSep:    ",",
+			outFieldSep: format,
+			outRecSep:   ",",
+		}
+		var w bytes.Buffer
+		done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+		<-done
+		var records []cvefeed.ScanRecord
+		if format == "json" {
+			if err := json.Unmarshal(w.Bytes(), &records); err != nil {
+				t.Fatalf("%s: couldn't decode output: %v\n%s", format, err, w.String())
+			}
+		} else {
+			for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+				var rec cvefeed.ScanRecord
+				if err := json.Unmarshal([]byte(line), &rec); err != nil {
+					t.Fatalf("%s: couldn't decode line %q: %v", format, line, err)
+				}
+				records = append(records, rec)
+			}
+		}
+		if len(records) != 2 {
+			t.Fatalf("%s: expected 2 records, got %d:\n%s", format, len(records), w.String())
+		}
+		sort.Slice(records, func(i, j int) bool { return records[i].Input[0] < records[j].Input[0] })
+		if vulns := records[1].Vulnerabilities; records[1].Input[0] != "host2" || vulns == nil || len(vulns) != 0 {
+			t.Errorf("%s: expected no vulnerabilities of host2, got %+v", format, records[1])
+		}
+		vulns := records[0].Vulnerabilities
+		sort.Slice(vulns, func(i, j int) bool { return vulns[i].CVE < vulns[j].CVE })
+		if len(vulns) != 2 || vulns[0].CVE != "CVE-2020-0001" || vulns[1].Status != "Rejected" ||
+			len(vulns[0].Matches) != 1 || vulns[0].Matches[0] != "cpe:/a:acme:widget:1.0" || len(vulns[0].VendorComments) != 2 {
+			t.Errorf("%s: unexpected vulnerabilities of host1 %+v", format, vulns)
+		}
+	}
+}
+
+func TestProcessInputSARIF(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSON20Status))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := "host1\tcpe:/a:acme:widget:1.0\nhost2\tcpe:/a:acme:gadget:1.0\n"
+	cfg := config{
+		nProcessors:   2,
+		cpesAt:        2,
+		inFieldSep:    "\t",
+		inRecSep:      ",",
+		outFieldSep:   "sarif",
+		outRecSep:     ",",
+		sarifArtifact: "inventory.tsv",
+	}
+	var w bytes.Buffer
+	done := processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+	<-done
+	var log sarif.Log
+	if err := json.Unmarshal(w.Bytes(), &log); err != nil {
+		t.Fatalf("couldn't decode output: %v\n%s", err, w.String())
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 2 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("expected 2 rules and 2 results, got:\n%s", w.String())
+	}
+	for _, r := range log.Runs[0].Results {
+		loc := r.Locations[0]
+		if loc.PhysicalLocation == nil || loc.PhysicalLocation.ArtifactLocation.URI != "inventory.tsv" {
+			t.Errorf("result %s isn't located in the artifact: %+v", r.RuleID, loc.PhysicalLocation)
+		}
+		if len(loc.LogicalLocations) != 1 || loc.LogicalLocations[0].Name != "cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*" {
+			t.Errorf("unexpected logical locations of %s: %+v", r.RuleID, loc.LogicalLocations)
+		}
+	}
+}
+
+func TestProcessSBOM(t *testing.T) {
+	in := `{"bomFormat": "CycloneDX", "specVersion": "1.4", "version": 1, "components": [
+  {"type": "operating-system", "bom-ref": "os", "name": "windows_10", "cpe": "cpe:/o:microsoft:windows_10:-::~~~~x64~"},
+  {"type": "library", "bom-ref": "none", "name": "unknown"}
+]}`
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	for _, output := range []string{"vex", "augmented"} {
+		var w bytes.Buffer
+		cfg := config{sbomOutput: output}
+		if err := processSBOM(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg); err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Components      []json.RawMessage `json:"components"`
+			Vulnerabilities []struct {
+				ID      string `json:"id"`
+				Affects []struct {
+					Ref string `json:"ref"`
+				} `json:"affects"`
+			} `json:"vulnerabilities"`
+		}
+		if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v", output, err)
+		}
+		if len(doc.Vulnerabilities) != 1 || doc.Vulnerabilities[0].ID != "CVE-2016-0165" || doc.Vulnerabilities[0].Affects[0].Ref != "os" {
+			t.Errorf("%s: unexpected output:\n%s", output, w.String())
+		}
+		if hasComponents := len(doc.Components) != 0; hasComponents != (output == "augmented") {
+			t.Errorf("%s: unexpected components in output:\n%s", output, w.String())
+		}
+	}
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	dict, err := cpedict.LoadIndex(strings.NewReader(`{"format":"NVD_CPE","version":"2.0","products":[
+		{"cpe":{"deprecated":true,"cpeName":"cpe:2.3:a:vendor:old:1.0:*:*:*:*:*:*:*","deprecatedBy":[{"cpeName":"cpe:2.3:a:vendor:new:1.0:*:*:*:*:*:*:*"}]}},
+		{"cpe":{"deprecated":false,"cpeName":"cpe:2.3:a:vendor:new:1.0:*:*:*:*:*:*:*","titles":[{"title":"Vendor New 1.0","lang":"en"}]}}
+	]}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"cpe:/a:vendor:old:1.0":     "deprecated in CPE dictionary, replaced by cpe:2.3:a:vendor:new:1.0:*:*:*:*:*:*:* (Vendor New 1.0)",
+		"cpe:/a:vendor:new:1.0":     "",
+		"cpe:/a:vendor:unknown:1.0": "",
+	}
+	for uri, want := range cases {
+		attr, err := wfn.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if warning := deprecationWarning(dict, attr); warning != want {
+			t.Errorf("%s: expected %q, got %q", uri, want, warning)
+		}
+		if warning := deprecationWarning(nil, attr); warning != "" {
+			t.Errorf("%s: expected no warning without dictionary, got %q", uri, warning)
+		}
+	}
+}
+
 func BenchmarkProcessInputJSON(t *testing.B) {
 	in := `1;2;3;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194
 1;2;3;cpe:/o::centos_linux:7.5.1804,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8
@@ -211,6 +638,101 @@ func BenchmarkProcessInputJSON(t *testing.B) {
 	}
 }
 
+// benchmarkInput returns n rows of distinct CPE names, so they miss the cache, every other one matching CVEs
+func benchmarkInput(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "%d;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.%d.194\n", i, i)
+		} else {
+			fmt.Fprintf(&b, "%d;cpe:/o::centos_linux:7.5.1804,cpe:/a::chardet:2.2.%d,cpe:/a::kitchen:1.1.1\n", i, i)
+		}
+	}
+	return b.String()
+}
+
+func TestProcessInputOrder(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse dictionary: %v", err)
+	}
+	in := benchmarkInput(500)
+	cfg := config{
+		cpesAt:      2,
+		cvesAt:      3,
+		inFieldSep:  ";",
+		outFieldSep: "|",
+		inRecSep:    ",",
+		outRecSep:   "&",
+	}
+	row := func(line string) int { // the input row of the output line
+		n, err := strconv.Atoi(strings.SplitN(line, "|", 2)[0])
+		if err != nil {
+			t.Fatalf("bad output line %q: %v", line, err)
+		}
+		return n
+	}
+	// the CVEs of a row are in no particular order, so the lines are compared once sorted
+	var want string
+	for _, workers := range []int{1, 2, 8, 32} {
+		cfg.nProcessors = workers
+		var w bytes.Buffer
+		<-processInput(strings.NewReader(in), &w, cvefeed.NewCache(dict), cfg)
+		lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+		for i, prev := range lines[:len(lines)-1] {
+			if row(prev) > row(lines[i+1]) {
+				t.Fatalf("%d workers: output isn't in the order of the input: %q after %q", workers, lines[i+1], prev)
+			}
+		}
+		sort.Strings(lines)
+		got := strings.Join(lines, "\n")
+		if workers == 1 {
+			if len(lines) < 250 {
+				t.Fatalf("expected at least 250 output lines, got %d", len(lines))
+			}
+			want = got
+			continue
+		}
+		if got != want {
+			t.Errorf("%d workers: output differs from the output of one worker", workers)
+		}
+	}
+}
+
+// BenchmarkProcessInputWorkers measures the scaling of matching across the workers; every iteration starts
+// with an empty cache, so the rows are matched rather than looked up
+func BenchmarkProcessInputWorkers(b *testing.B) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		b.Fatalf("couldn't parse dictionary: %v", err)
+	}
+	in := benchmarkInput(2000)
+	workers := []int{1, 2, 4, 8}
+	if n := runtime.NumCPU(); n > 8 {
+		workers = append(workers, n)
+	}
+	for _, n := range workers {
+		cfg := config{
+			nProcessors: n,
+			cpesAt:      2,
+			cvesAt:      3,
+			inFieldSep:  ";",
+			outFieldSep: "|",
+			inRecSep:    ",",
+			outRecSep:   "&",
+		}
+		b.Run(fmt.Sprintf("workers=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				<-processInput(strings.NewReader(in), ioutil.Discard, cvefeed.NewCache(dict), cfg)
+			}
+		})
+	}
+}
+
 func getSkip(ff []int) fieldsToSkip {
 	set := make(map[int]struct{})
 	for _, f := range ff {
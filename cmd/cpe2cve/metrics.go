@@ -0,0 +1,31 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+)
+
+// metrics of matching, exposed as per -metrics_addr and -metrics_file
+var (
+	cvesLoaded = metrics.NewGauge("cpe2cve_cves_loaded",
+		"CVEs in the dictionary inventories are matched against.")
+	inventoriesMatched = metrics.NewCounter("cpe2cve_inventories_matched_total",
+		"Input lines (or SBOM components) matched.")
+	cvesMatched = metrics.NewCounter("cpe2cve_cves_matched_total",
+		"CVEs matched by the inventories, after exceptions and filters.")
+	matchDuration = metrics.NewHistogram("cpe2cve_match_duration_seconds",
+		"Latency of matching an inventory.", nil)
+)
@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// nvdSource is the name of the source of the feeds passed as arguments
+const nvdSource = "nvd"
+
+// sourceFeeds is a custom type to be recognized by flag.Parse().
+// It maps multiple name=path occurrences of the flag into feeds of named sources, in the order of the flags;
+// a source might have several feeds.
+type sourceFeeds struct {
+	names []string
+	feeds map[string][]string
+}
+
+// part of flag.Value interface implementation
+func (sf *sourceFeeds) String() string {
+	var ss []string
+	for _, name := range sf.names {
+		for _, path := range sf.feeds[name] {
+			ss = append(ss, name+"="+path)
+		}
+	}
+	return fmt.Sprintf("%v", ss)
+}
+
+// part of flag.Value interface implementation
+func (sf *sourceFeeds) Set(val string) error {
+	i := strings.IndexByte(val, '=')
+	if i <= 0 || i == len(val)-1 {
+		return fmt.Errorf("expected name=path, got %q", val)
+	}
+	name, path := val[:i], val[i+1:]
+	if name == nvdSource {
+		return fmt.Errorf("source name %q is reserved for the feeds passed as arguments", nvdSource)
+	}
+	if strings.ContainsAny(name, ",=") {
+		return fmt.Errorf("source name %q can't contain ',' or '='", name)
+	}
+	if sf.feeds == nil {
+		sf.feeds = map[string][]string{}
+	}
+	if _, ok := sf.feeds[name]; !ok {
+		sf.names = append(sf.names, name)
+	}
+	sf.feeds[name] = append(sf.feeds[name], path)
+	return nil
+}
+
+// mergePolicy returns the policy of merging the sources: the default priority list is the one given or,
+// if it's empty, NVD followed by the sources in the order of the flags; see cvefeed.ParseMergePolicy
+func (sf *sourceFeeds) mergePolicy(priority string, fields []string) (cvefeed.MergePolicy, error) {
+	if priority == "" {
+		priority = strings.Join(append([]string{nvdSource}, sf.names...), ",")
+	}
+	policy, err := cvefeed.ParseMergePolicy(priority, fields)
+	if err != nil {
+		return cvefeed.MergePolicy{}, err
+	}
+	known := map[string]bool{nvdSource: true}
+	for _, name := range sf.names {
+		known[name] = true
+	}
+	lists := [][]string{policy.Default}
+	for _, order := range policy.Fields {
+		lists = append(lists, order)
+	}
+	for _, order := range lists {
+		for _, src := range order {
+			if !known[src] {
+				return cvefeed.MergePolicy{}, fmt.Errorf("unknown source %q, expected %s or one of -source names", src, nvdSource)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// provenanceList returns the sources of the fields of merged CVE as field:source pairs, in the order fields are merged
+func provenanceList(cve cvefeed.CVEItem) []string {
+	provenance := cvefeed.CVEProvenance(cve)
+	var pairs []string
+	for _, f := range nvdcommon.Fields {
+		if src, ok := provenance[f.String()]; ok {
+			pairs = append(pairs, f.String()+":"+src)
+		}
+	}
+	return pairs
+}
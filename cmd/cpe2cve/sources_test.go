@@ -0,0 +1,56 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestSourceFeeds(t *testing.T) {
+	var sf sourceFeeds
+	for _, val := range []string{"vendor=a.json", "distro=b.json", "vendor=c.json"} {
+		if err := sf.Set(val); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if out := sf.String(); out != "[vendor=a.json vendor=c.json distro=b.json]" {
+		t.Fatalf("unexpected sources %s", out)
+	}
+	for _, val := range []string{"", "vendor", "=a.json", "vendor=", "nvd=a.json", "a,b=c.json"} {
+		if err := sf.Set(val); err == nil {
+			t.Errorf("%q expected to be an error", val)
+		}
+	}
+
+	policy, err := sf.mergePolicy("", []string{"config=vendor,nvd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"nvd", "vendor", "distro"}; !reflect.DeepEqual(policy.Default, want) {
+		t.Errorf("expected default priority %v, got %v", want, policy.Default)
+	}
+	if want := []string{"vendor", "nvd"}; !reflect.DeepEqual(policy.Fields[nvdcommon.FieldConfig], want) {
+		t.Errorf("expected config priority %v, got %v", want, policy.Fields[nvdcommon.FieldConfig])
+	}
+	if _, err := sf.mergePolicy("nvd,other", nil); err == nil {
+		t.Error("unknown source in priority expected to be an error")
+	}
+	if _, err := sf.mergePolicy("", []string{"cvss3=other"}); err == nil {
+		t.Error("unknown source in field priority expected to be an error")
+	}
+}
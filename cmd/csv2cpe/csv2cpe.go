@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/facebookincubator/nvdtools/cpedict"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -37,6 +38,8 @@ func main() {
 	erase := flag.String("e", "", "comma separated list of columns to erase, optional")
 	unmap := flag.Bool("x", false, "erase all columns mapped from -cpe_{field}, optional")
 	lower := flag.Bool("lower", false, "force cpe output to be lower case, optional")
+	dict := flag.String("cpe_dict", "", "CPE dictionary (XML or NVD CPE API 2.0 response, plain or gzip'ed) to rate the CPEs guessed with -cpe_guess by, optional")
+	minConfidence := flag.Float64("guess_min_confidence", 0, "output empty cpe for guesses of lower confidence, in [0, 1], optional")
 
 	flag.Parse()
 
@@ -66,11 +69,27 @@ func main() {
 	}
 
 	p := &Processor{
-		InputComma:        rune((*idelim)[0]),
-		OutputComma:       rune((*odelim)[0]),
-		CPEToLower:        *lower,
-		CPEOutputColumn:   *idx,
-		EraseInputColumns: eraseCols,
+		InputComma:         rune((*idelim)[0]),
+		OutputComma:        rune((*odelim)[0]),
+		CPEToLower:         *lower,
+		CPEOutputColumn:    *idx,
+		EraseInputColumns:  eraseCols,
+		GuessMinConfidence: *minConfidence,
+	}
+
+	if *dict != "" {
+		f, err := os.Open(*dict)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		idx, err := cpedict.LoadIndex(f, nil)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't load CPE dictionary: %v\n", err)
+			os.Exit(1)
+		}
+		p.GuessVendors = idx.ProductVendors()
 	}
 
 	err = p.Process(acm, os.Stdin, os.Stdout)
@@ -87,6 +106,9 @@ type Processor struct {
 	CPEToLower        bool   // whether the output cpe should be forced lower case
 	CPEOutputColumn   int    // index to add cpe column in the output, after erases
 	EraseInputColumns IntSet // input columns to erase before output
+
+	GuessVendors       wfn.ProductVendors // rates CPEs guessed from free-form strings, optional, see wfn.GuessCPE
+	GuessMinConfidence float64            // CPEs guessed with lower confidence are left empty
 }
 
 // Process reads CSV from r and writes CSV + CPE to w.
@@ -112,7 +134,12 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 			return fmt.Errorf("error parsing line %d: %v", line, err)
 		}
 
-		cpe, err := acm.CPE(cols, p.CPEToLower)
+		var cpe string
+		if acm.Guess != 0 {
+			cpe = p.guessCPE(cols, acm.Guess)
+		} else {
+			cpe, err = acm.CPE(cols, p.CPEToLower)
+		}
 		if err != nil {
 			return fmt.Errorf("error parsing columns in line %d: %v", line, err)
 		}
@@ -126,6 +153,19 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 	return nil
 }
 
+// guessCPE returns the most likely CPE guessed from free-form product string in the column, empty if there's none
+// of enough confidence.
+func (p *Processor) guessCPE(cols []string, col int) string {
+	if col > len(cols) {
+		return ""
+	}
+	guesses := wfn.GuessCPE(cols[col-1], p.GuessVendors)
+	if len(guesses) == 0 || guesses[0].Confidence < p.GuessMinConfidence {
+		return ""
+	}
+	return guesses[0].Attributes.BindToURI()
+}
+
 // AttributeColumnMap maps CSV columns to WFN Attribute fields.
 type AttributeColumnMap struct {
 	Part      int
@@ -139,6 +179,7 @@ type AttributeColumnMap struct {
 	TargetHW  int
 	Other     int
 	Language  int
+	Guess     int // free-form product string to guess the CPE from, instead of the other columns
 }
 
 // AddFlags adds configuration flags to the given FlagSet.
@@ -154,6 +195,8 @@ func (acm *AttributeColumnMap) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&acm.TargetHW, "cpe_targethw", 0, "targethw cpe column index")
 	fs.IntVar(&acm.Other, "cpe_other", 0, "other cpe column index")
 	fs.IntVar(&acm.Language, "cpe_language", 0, "language cpe column index")
+	fs.IntVar(&acm.Guess, "cpe_guess", 0, "column index of free-form product string (e.g. \"Apache Tomcat 9.0.31\" or \"openssl-1.1.1k-5.el8\") "+
+		"to guess the cpe from, instead of the other cpe columns")
 }
 
 // CPE returns a CPE by mapping cols to the configured column indices.
@@ -219,6 +262,7 @@ func (acm *AttributeColumnMap) Columns() []int {
 		acm.TargetHW,
 		acm.Other,
 		acm.Language,
+		acm.Guess,
 	).ReverseSortedSet()
 
 	for i, v := range s {
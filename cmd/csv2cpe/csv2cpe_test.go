@@ -93,6 +93,12 @@ func TestProcessor(t *testing.T) {
 			"a\tb\tc\n",
 			"cpe:/a\n",
 		},
+		{
+			[]string{"-cpe_guess=2"},
+			NewIntSet(2),
+			"a\tApache Tomcat 9.0.31\nb\topenssl-1.1.1k-5.el8\n",
+			"a,cpe:/a:apache:tomcat:9.0.31\nb,cpe:/a:openssl:openssl:1.1.1k\n",
+		},
 	}
 
 	for n, c := range cases {
@@ -0,0 +1,188 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/server"
+	"github.com/golang/glog"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	addr             string
+	grpcAddr         string
+	watch            time.Duration
+	overrides        multiString
+	indexedDict      bool
+	requireVersion   bool
+	wildcardVersions bool
+	versionCmp       string
+	cacheSize        int64
+	recoverPanics    bool
+	validate         bool
+	skipRejected     bool
+}
+
+func (c *config) addFlags() {
+	flag.StringVar(&c.addr, "addr", "localhost:8080", "address to serve HTTP queries on")
+	flag.StringVar(&c.grpcAddr, "grpc_addr", "", "address to serve gRPC queries on, over unencrypted HTTP/2 (see cvefeed/server/cveserver.proto); empty disables gRPC")
+	flag.DurationVar(&c.watch, "watch", time.Minute, "check the feed files for changes this often and reload them once they change; 0 disables reloading")
+	flag.Var(&c.overrides, "r", "overRide: path to override feed, can be specified multiple times")
+	flag.BoolVar(&c.indexedDict, "idxd", false, "build and use an index for CVE dictionary: increases the processing speed, but might miss some matches")
+	flag.BoolVar(&c.requireVersion, "require_version", false, "ignore matches of CPEs with version ANY")
+	flag.BoolVar(&c.wildcardVersions, "wildcard_versions", false, "match CPEs with versions like 2.4.* as ranges of versions, e.g. [2.4.0, 2.5.0)")
+	flag.StringVar(&c.versionCmp, "version_cmp", "", "compare versions of CVE version ranges by this versioning scheme: rpm, deb, apk, semver or dotted; comma separated [vendor:]product=scheme entries set the scheme of products, see cpe2cve")
+	flag.Int64Var(&c.cacheSize, "cache_size", 0, "limit the cache size to this amount in bytes; 0 removes the limit, -1 disables caching")
+	flag.BoolVar(&c.recoverPanics, "recover", false, "skip CVEs whose configuration can't be evaluated (e.g. malformed feed records) rather than aborting; the skipped CVEs are logged")
+	flag.BoolVar(&c.validate, "validate", false, "validate structure of the feeds against NVD schema before loading them; slows down loading")
+	flag.BoolVar(&c.skipRejected, "skip_rejected", false, "skip rejected CVEs")
+}
+
+func (c *config) mustBeValid() {
+	if flag.NArg() < 1 {
+		glog.Error("feed file wasn't provided")
+		flag.Usage()
+	}
+	if c.watch < 0 {
+		glog.Errorf("-watch value is invalid %v", c.watch)
+		flag.Usage()
+	}
+}
+
+// serverConfig returns the config of the server of the feeds
+func (c *config) serverConfig(feeds []string) (server.Config, error) {
+	sc := server.Config{
+		Feeds:        feeds,
+		Overrides:    c.overrides,
+		Load:         cvefeed.LoadJSONDictionary,
+		SkipRejected: c.skipRejected,
+		Indexed:      c.indexedDict,
+		Logger:       cvefeed.GlogLogger{},
+	}
+	if c.validate {
+		sc.Load = cvefeed.LoadValidatedJSONDictionary
+	}
+	var vc *cvefeed.VersionComparators
+	if c.versionCmp != "" {
+		var err error
+		if vc, err = cvefeed.ParseVersionComparators(c.versionCmp); err != nil {
+			return sc, fmt.Errorf("-version_cmp value is invalid: %v", err)
+		}
+	}
+	sc.Setup = func(cache *cvefeed.Cache) {
+		cache.SetRequireVersion(c.requireVersion).SetWildcardVersions(c.wildcardVersions).SetMaxSize(c.cacheSize).SetRecoverPanics(c.recoverPanics)
+		if vc != nil {
+			cache.SetVersionComparators(vc)
+		}
+	}
+	return sc, nil
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s loads vulnerability feeds once, holds them in memory and answers CPE to CVE match\n" +
+			"%[2]s queries over HTTP: GET /match?cpe=...&cpe=... or POST /match with {\"cpes\": [...]} JSON\n" +
+			"%[2]s are answered with the CVEs matching the CPE names, the most severe first, GET /status with\n" +
+			"%[2]s the feeds loaded; with -grpc_addr, the same queries over gRPC. The feeds are reloaded as\n" +
+			"%[2]s their files change.\n" +
+			"usage: %[1]s [flags] nvd_feed.json.gz...\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Set("logtostderr", "true")
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	cfg.mustBeValid()
+	cvefeed.SetLogger(cvefeed.GlogLogger{})
+
+	sc, err := cfg.serverConfig(flag.Args())
+	if err != nil {
+		glog.Fatal(err)
+	}
+	glog.V(1).Info("loading NVD feeds...")
+	start := time.Now()
+	srv, err := server.New(sc)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	glog.V(1).Infof("...%d CVEs loaded in %v", srv.Status().CVEs, time.Since(start))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cfg.watch > 0 {
+		go srv.Watch(ctx, cfg.watch)
+	}
+
+	hs := &http.Server{Addr: cfg.addr, Handler: srv.Handler()}
+	var gs *http.Server
+	if cfg.grpcAddr != "" {
+		gs = &http.Server{Addr: cfg.grpcAddr, Handler: srv.GRPCHandler(), Protocols: new(http.Protocols)}
+		gs.Protocols.SetUnencryptedHTTP2(true)
+	}
+	idle := make(chan struct{})
+	go func() {
+		defer close(idle)
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		glog.V(1).Info("shutting down...")
+		shutdownCtx, cancelShutdown := context.WithTimeout(ctx, 30*time.Second)
+		defer cancelShutdown()
+		if gs != nil {
+			if err := gs.Shutdown(shutdownCtx); err != nil {
+				glog.Errorf("shutdown of gRPC: %v", err)
+			}
+		}
+		if err := hs.Shutdown(shutdownCtx); err != nil {
+			glog.Errorf("shutdown: %v", err)
+		}
+	}()
+	if gs != nil {
+		go func() {
+			glog.V(1).Infof("serving gRPC on %s", cfg.grpcAddr)
+			if err := gs.ListenAndServe(); err != http.ErrServerClosed {
+				glog.Fatal(err)
+			}
+		}()
+	}
+	glog.V(1).Infof("serving on %s", cfg.addr)
+	if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+		glog.Fatal(err)
+	}
+	<-idle // queries in flight are answered
+}
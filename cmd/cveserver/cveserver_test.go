@@ -0,0 +1,64 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/server"
+)
+
+func TestServerConfig(t *testing.T) {
+	cfg := config{versionCmp: "bogus"}
+	if _, err := cfg.serverConfig([]string{"feed.json"}); err == nil {
+		t.Error("expected invalid -version_cmp to fail")
+	}
+
+	dir, err := ioutil.TempDir("", "cveserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	data := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001",
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.10"}]}]}]}}]}`
+	if err := ioutil.WriteFile(feed, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg = config{versionCmp: "dotted"}
+	sc, err := cfg.serverConfig([]string{feed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := server.New(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cpe, n := range map[string]int{
+		"cpe:/a:acme:widget:1.9":  1,
+		"cpe:/a:acme:widget:1.10": 0,
+	} {
+		rec, err := srv.Match(&server.MatchRequest{CPEs: []string{cpe}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rec.Vulnerabilities) != n {
+			t.Errorf("%s: expected %d CVEs, got %d", cpe, n, len(rec.Vulnerabilities))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+// multiString is a custom type to be recognized by flag.Parse().
+// It maps multiple occurances of the flag into slice of strings.
+type multiString []string
+
+// part of flag.Value interface implementation
+func (ms *multiString) String() string {
+	return fmt.Sprintf("%v", *ms)
+}
+
+// part of flag.Value interface implementation
+func (ms *multiString) Set(val string) error {
+	*ms = append(*ms, val)
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	vectorField int
+	cveField    int
+	assetField  int
+	overrides   string
+	inFieldSep  string
+	outFieldSep string
+}
+
+func (c *config) addFlags() {
+	flag.IntVar(&c.vectorField, "vector", 0, "position of the field in DSV input that contains the CVSS vector (starts at 1)")
+	flag.IntVar(&c.cveField, "cve", 0, "optional position of the field in DSV input that contains the CVE ID (starts at 1)")
+	flag.IntVar(&c.assetField, "asset", 0, "optional position of the field in DSV input that contains the asset name (starts at 1)")
+	flag.StringVar(&c.overrides, "config", "", "JSON file with temporal and environmental metric overrides, see usage")
+	flag.StringVar(&c.inFieldSep, "d", "\t", "input column delimiter")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+}
+
+// overrides are temporal and environmental metric values, e.g. "CR": "H", replacing the ones of the vectors;
+// the metrics of the asset override the default ones and the metrics of the CVE override both
+type overrides struct {
+	Default map[string]string            `json:"default"`
+	Assets  map[string]map[string]string `json:"assets"`
+	CVEs    map[string]map[string]string `json:"cves"`
+}
+
+func loadOverrides(filename string) (*overrides, error) {
+	var ovr overrides
+	if filename == "" {
+		return &ovr, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&ovr); err != nil {
+		return nil, fmt.Errorf("couldn't decode config %q: %v", filename, err)
+	}
+	return &ovr, nil
+}
+
+// metrics returns the metric overrides for the CVE of the asset, both may be empty
+func (o *overrides) metrics(cve, asset string) map[string]string {
+	metrics := make(map[string]string)
+	for _, m := range []map[string]string{o.Default, o.Assets[asset], o.CVEs[cve]} {
+		for metric, value := range m {
+			metrics[metric] = value
+		}
+	}
+	return metrics
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s takes a delimiter-separated input with one of the fields containing CVSS v2, v3 or v4 vector\n" +
+			"%[2]s and produces delimiter-separated output consisting of the same fields plus the score, severity and\n" +
+			"%[2]s vector adjusted by temporal and environmental metric overrides of the config file:\n" +
+			"\n" +
+			"  {\n" +
+			"    \"default\": {\"E\": \"F\"},\n" +
+			"    \"assets\": {\"webserver\": {\"CR\": \"H\", \"AR\": \"L\"}},\n" +
+			"    \"cves\": {\"CVE-2018-0001\": {\"RL\": \"OF\"}}\n" +
+			"  }\n" +
+			"\n" +
+			"%[2]s the metrics of the asset and CVE of the row override the default ones, in this order;\n" +
+			"%[2]s the metrics the CVSS version of the vector doesn't define are skipped.\n" +
+			"usage: %[1]s [flags]\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func field(fields []string, n int) (string, error) {
+	if n == 0 {
+		return "", nil
+	}
+	if n > len(fields) {
+		return "", fmt.Errorf("not enough fields (%d)", len(fields))
+	}
+	return fields[n-1], nil
+}
+
+func processRecord(fields []string, cfg config, ovr *overrides) ([]string, error) {
+	var vector, cve, asset string
+	var err error
+	for _, f := range []struct {
+		value *string
+		n     int
+	}{{&vector, cfg.vectorField}, {&cve, cfg.cveField}, {&asset, cfg.assetField}} {
+		if *f.value, err = field(fields, f.n); err != nil {
+			return nil, err
+		}
+	}
+	v, err := cvss.Parse(vector, cvss.Lenient)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse CVSS vector from field %q: %v", vector, err)
+	}
+	if err = cvss.Adjust(v, ovr.metrics(cve, asset)); err != nil {
+		return nil, fmt.Errorf("couldn't adjust CVSS vector %q: %v", vector, err)
+	}
+	details, err := cvss.Details(v)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't score CVSS vector %q: %v", vector, err)
+	}
+	adjusted, err := cvss.Canonical(v)
+	if err != nil {
+		return nil, err
+	}
+	score := strconv.FormatFloat(details.Score, 'f', 1, 64)
+	return append(fields, score, details.Severity, adjusted), nil
+}
+
+func score(in io.Reader, out io.Writer, cfg config, ovr *overrides) {
+	r := csv.NewReader(in)
+	r.Comma = rune(cfg.inFieldSep[0])
+	r.FieldsPerRecord = -1
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	for {
+		inRec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			sayErr(-1, "read error: %v", err)
+		}
+		outRec, err := processRecord(inRec, cfg, ovr)
+		if err != nil {
+			sayErr(0, "couldn't process record %v: %v", inRec, err)
+			continue
+		}
+		if err = w.Write(outRec); err != nil {
+			sayErr(-1, "write error: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		sayErr(-1, "write error: %v", err)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if cfg.vectorField == 0 {
+		flag.Usage()
+	}
+	ovr, err := loadOverrides(cfg.overrides)
+	if err != nil {
+		sayErr(-1, "couldn't load overrides: %v", err)
+	}
+	score(os.Stdin, os.Stdout, cfg, ovr)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testOverrides = overrides{
+	Default: map[string]string{"E": "U"},
+	Assets:  map[string]map[string]string{"db": {"CR": "H"}},
+	CVEs:    map[string]map[string]string{"CVE-2018-0002": {"E": "H"}},
+}
+
+func TestProcessRecord(t *testing.T) {
+	cases := []struct {
+		in   string
+		out  string
+		fail bool
+	}{
+		{"", "", true},
+		{"CVE-2018-0001,web", "", true},
+		{"CVE-2018-0001,web,AV:N/AC:L/PR:N", "", true},
+		{
+			"CVE-2018-0001,web,CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+			"CVE-2018-0001;web;CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N;6.9;MEDIUM;CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N/E:U",
+			false,
+		},
+		{
+			"CVE-2018-0001,db,CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+			"CVE-2018-0001;db;CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N;8.5;HIGH;CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N/E:U/CR:H",
+			false,
+		},
+		{
+			"CVE-2018-0002,web,(AV:N/AC:L/Au:N/C:P/I:N/A:N)",
+			"CVE-2018-0002;web;(AV:N/AC:L/Au:N/C:P/I:N/A:N);5.0;MEDIUM;AV:N/AC:L/Au:N/C:P/I:N/A:N/E:H",
+			false,
+		},
+	}
+	cfg := config{
+		vectorField: 3,
+		cveField:    1,
+		assetField:  2,
+		inFieldSep:  ",",
+		outFieldSep: ";",
+	}
+	for _, c := range cases {
+		record, err := processRecord(strings.Split(c.in, cfg.inFieldSep), cfg, &testOverrides)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("line %q was expected to succeed, but failed: %v", c.in, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("line %q was expected to fail, but succeeded", c.in)
+			continue
+		}
+		if out := strings.Join(record, cfg.outFieldSep); out != c.out {
+			t.Errorf("line %q: expected %q, got %q", c.in, c.out, out)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	in := "CVE-2018-0001\tCVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H\nCVE-2018-0002\tbogus\n"
+	var out bytes.Buffer
+	score(strings.NewReader(in), &out, config{vectorField: 2, cveField: 1, inFieldSep: "\t", outFieldSep: "\t"}, &overrides{})
+	want := "CVE-2018-0001\tCVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H\t9.8\tCRITICAL\tCVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
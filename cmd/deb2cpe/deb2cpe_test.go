@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProcessRecord(t *testing.T) {
+	cases := []struct {
+		in   string
+		out  string
+		fail bool
+	}{
+		{"", "", true},
+		{"bash", "", true},
+		{"bash\t5.0-6ubuntu1.2\tamd64", "bash;5.0-6ubuntu1.2;cpe:/a::bash:5.0:6ubuntu1.2:~~~~amd64~", false},
+		{"libc6:amd64\t2.31-0ubuntu9.9", "libc6:amd64;2.31-0ubuntu9.9;cpe:/a::libc6:2.31:0ubuntu9.9:~~~~amd64~", false},
+	}
+	cfg := config{
+		nameField:    1,
+		versionField: 2,
+		archField:    3,
+		cpeField:     3,
+		inFieldSep:   "\t",
+		outFieldSep:  ";",
+		skip:         fieldsToSkip(map[int]struct{}{2: {}}),
+	}
+	for _, c := range cases {
+		fields := strings.Split(c.in, cfg.inFieldSep)
+		if len(fields) == 2 {
+			cfg.archField = 0
+		} else {
+			cfg.archField = 3
+		}
+		record, err := processRecord(fields, cfg)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("line %q was expected to succeed, but failed: %v", c.in, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("line %q was expected to fail, but succeeded", c.in)
+			continue
+		}
+		if out := strings.Join(record, cfg.outFieldSep); out != c.out {
+			t.Errorf("line %q: expected %q, got %q", c.in, c.out, out)
+		}
+	}
+}
+
+func TestDebName(t *testing.T) {
+	record, err := processRecord([]string{"openssl_1.1.1f-1ubuntu2_amd64.deb"}, config{nameField: 1, cpeField: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "cpe:/a::openssl:1.1.1f:1ubuntu2:~~~~amd64~"; record[1] != want {
+		t.Errorf("expected %q, got %q", want, record[1])
+	}
+}
+
+func TestStatus(t *testing.T) {
+	status := "Package: bash\nStatus: install ok installed\nArchitecture: amd64\nVersion: 5.0-6ubuntu1.2\n\n" +
+		"Package: removed\nStatus: deinstall ok config-files\nArchitecture: all\nVersion: 1.0-1\n"
+	var out bytes.Buffer
+	deb2cpe(strings.NewReader(status), &out, config{status: true, cpeField: 4, outFieldSep: "\t"})
+	if want := "bash\t5.0-6ubuntu1.2\tamd64\tcpe:/a::bash:5.0:6ubuntu1.2:~~~~amd64~\n"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
@@ -18,12 +18,14 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/api"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/converter"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 )
 
 const (
@@ -37,14 +39,13 @@ var (
 )
 
 func init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	publicKey = os.Getenv("FIREEYE_PUBLIC")
 	if publicKey == "" {
-		log.Fatalln("Please set FIREEYE_PUBLIC in environment")
+		logging.Fatal("Please set FIREEYE_PUBLIC in environment")
 	}
 	privateKey = os.Getenv("FIREEYE_PRIVATE")
 	if privateKey == "" {
-		log.Fatalln("Please set FIREEYE_PRIVATE in environment")
+		logging.Fatal("Please set FIREEYE_PRIVATE in environment")
 	}
 }
 
@@ -54,6 +55,11 @@ func main() {
 	sinceUnix := flag.Int64("since_unix", 0, "Unix timestamp since when should we download. If not set, downloads all available data")
 	dontConvert := flag.Bool("dont_convert", false, "Should the feed be converted to NVD format or not")
 	userAgent := flag.String("user_agent", userAgent, "User agent to be used when sending requests")
+	httpConfig := client.DefaultConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		flag.PrintDefaults()
@@ -61,25 +67,41 @@ func main() {
 	}
 	flag.Parse()
 
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
 	since := *sinceUnix
 	if *sinceDuration != "" {
 		dur, err := time.ParseDuration("-" + *sinceDuration)
 		if err != nil {
-			log.Fatalln(err)
+			logging.Fatal(err)
 		}
 		since = time.Now().Add(dur).Unix()
 	}
 
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
 	// create the API
 	client, err := api.NewClient(*baseURL, *userAgent, publicKey, privateKey)
 	if err != nil {
-		log.Fatalln(err)
+		logging.Fatal(err)
 	}
+	client.HTTP = httpClient
 
-	log.Printf("Downloading since %s\n", time.Unix(since, 0).Format(time.RFC1123))
+	logging.Infof("Downloading since %s", time.Unix(since, 0).Format(time.RFC1123))
 	vulns, err := client.FetchAllVulnerabilitiesSince(since)
 	if err != nil {
-		log.Fatalln(err)
+		logging.Fatal(err)
 	}
 
 	if *dontConvert {
@@ -91,6 +113,6 @@ func main() {
 
 func writeOutput(output interface{}) {
 	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
-		log.Fatalln(err)
+		logging.Fatal(err)
 	}
 }
@@ -18,13 +18,15 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/providers/flexera/api"
 	"github.com/facebookincubator/nvdtools/providers/flexera/converter"
 	"github.com/facebookincubator/nvdtools/providers/flexera/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 )
 
 const (
@@ -36,10 +38,9 @@ var (
 )
 
 func init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	apiKey = os.Getenv("FLEXERA_TOKEN")
 	if apiKey == "" {
-		log.Fatal("Please set FLEXERA_TOKEN in environment")
+		logging.Fatal("Please set FLEXERA_TOKEN in environment")
 	}
 }
 
@@ -48,6 +49,11 @@ func main() {
 	sinceDuration := flag.String("since", "", "Golang duration string, overrides -since_unix flag")
 	sinceUnix := flag.Int64("since_unix", 0, "Unix timestamp since when should we download. If not set, downloads all available data")
 	only := flag.String("only", "", "If present, it will only download this advisory")
+	httpConfig := api.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		flag.PrintDefaults()
@@ -55,13 +61,29 @@ func main() {
 	}
 	flag.Parse()
 
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
 	// create the API
 	client := api.NewClient(*baseURL, apiKey)
+	client.HTTP = httpClient
 	var fetch func() (<-chan *schema.FlexeraAdvisory, error)
 
 	if *only != "" {
 		fetch = func() (<-chan *schema.FlexeraAdvisory, error) {
-			log.Printf("Downloading only: %s\n", *only)
+			logging.Infof("Downloading only: %s", *only)
 			adv, err := client.Fetch(*only)
 			if err != nil {
 				return nil, err
@@ -76,7 +98,7 @@ func main() {
 		if *sinceDuration != "" {
 			dur, err := time.ParseDuration("-" + *sinceDuration)
 			if err != nil {
-				log.Fatal(err)
+				logging.Fatal(err)
 			}
 			since = time.Now().Add(dur).Unix()
 		}
@@ -84,7 +106,7 @@ func main() {
 		from, to := since, time.Now().Unix()
 
 		fetch = func() (<-chan *schema.FlexeraAdvisory, error) {
-			log.Printf(
+			logging.Infof(
 				"Download window: %s - %s\n",
 				time.Unix(from, 0).Format(time.RFC1123),
 				time.Unix(to, 0).Format(time.RFC1123),
@@ -95,11 +117,11 @@ func main() {
 
 	advCh, err := fetch()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(err)
 	}
 
 	converted := converter.Convert(advCh)
 	if err := json.NewEncoder(os.Stdout).Encode(converted); err != nil {
-		log.Fatal(err)
+		logging.Fatal(err)
 	}
 }
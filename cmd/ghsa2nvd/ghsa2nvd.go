@@ -0,0 +1,147 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/ghsa/api"
+	"github.com/facebookincubator/nvdtools/providers/ghsa/converter"
+	"github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+)
+
+var token string
+
+func init() {
+	token = os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		logging.Fatal("Please set GITHUB_TOKEN in environment")
+	}
+}
+
+func main() {
+	url := flag.String("url", api.URL, "GitHub GraphQL API endpoint")
+	sinceDuration := flag.String("since", "", "Golang duration string, download advisories updated since then. If not set, downloads all available data")
+	statePath := flag.String("state", "", "path to the file keeping the position of incremental pulls, overrides -since once written")
+	timeout := flag.Duration("timeout", time.Hour, "timeout of the download")
+	dontConvert := flag.Bool("dont_convert", false, "Should the feed be converted to NVD format or not")
+	httpConfig := api.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
+	var cursor api.Cursor
+	if *sinceDuration != "" {
+		dur, err := time.ParseDuration("-" + *sinceDuration)
+		if err != nil {
+			logging.Fatal(err)
+		}
+		cursor.UpdatedSince = time.Now().Add(dur)
+	}
+	if *statePath != "" {
+		if err := readState(*statePath, &cursor); err != nil {
+			logging.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	client := api.NewClient(*url, token)
+	client.HTTP = httpClient
+	var advisories []*schema.SecurityAdvisory
+	next, err := client.Fetch(ctx, cursor, func(page []*schema.SecurityAdvisory, _ api.Cursor) error {
+		advisories = append(advisories, page...)
+		return nil
+	})
+	if err != nil {
+		logging.Fatal(err)
+	}
+	logging.Infof("downloaded %d advisories", len(advisories))
+
+	if *dontConvert {
+		writeOutput(advisories)
+	} else {
+		writeOutput(converter.Convert(advisories))
+	}
+
+	// the state moves on only once the advisories are written, so failed runs are pulled again
+	if *statePath != "" {
+		if err := writeState(*statePath, next); err != nil {
+			logging.Fatal(err)
+		}
+	}
+}
+
+func writeOutput(output interface{}) {
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		logging.Fatal(err)
+	}
+}
+
+// readState reads the cursor from the state file, if it exists
+func readState(path string, cursor *api.Cursor) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cursor)
+}
+
+func writeState(path string, cursor api.Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
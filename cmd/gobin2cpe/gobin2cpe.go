@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	stdlib      bool
+	main        bool
+	outFieldSep string
+}
+
+func (c *config) addFlags() {
+	flag.BoolVar(&c.stdlib, "stdlib", true, "output the standard library of the Go version the binaries are built with, as golang:go")
+	flag.BoolVar(&c.main, "main", true, "output the main modules of the binaries, unless they're built from working copies, (devel)")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s reads the build information of Go binaries (ELF, Mach-O and PE executables built with\n" +
+			"%[2]s module support) of the files and the directories, walked recursively, and produces delimiter-separated\n" +
+			"%[2]s output consisting of CPE name, binary, module path and version of every module the binaries are built of.\n" +
+			"%[2]s The CPE names match the ones of osv2nvd and govulndb2nvd feeds, so the output can be processed by cpe2cve.\n" +
+			"usage: %[1]s [flags] binary|directory...\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// processBinary returns the output records of the modules of the binary; modules which CPE names couldn't be
+// parsed of are reported and skipped
+func processBinary(name string, bin *cpeparse.GoBinary, cfg config) [][]string {
+	var records [][]string
+	for _, mod := range bin.Modules() {
+		if !cfg.stdlib && mod.Path == cpeparse.GoStdlib || !cfg.main && mod == bin.Main {
+			continue
+		}
+		attr, err := cpeparse.FromGoModule(mod.Path, mod.Version)
+		if err != nil {
+			sayErr(0, "%s: %v", name, err)
+			continue
+		}
+		records = append(records, []string{attr.BindToURI(), name, mod.Path, mod.Version})
+	}
+	return records
+}
+
+func gobin2cpe(paths []string, out io.Writer, cfg config) error {
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	err := cpeparse.WalkGoBinaries(paths, func(name string, bin *cpeparse.GoBinary) error {
+		return w.WriteAll(processBinary(name, bin, cfg))
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	if err := gobin2cpe(flag.Args(), os.Stdout, cfg); err != nil {
+		sayErr(-1, "%v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+)
+
+func TestProcessBinary(t *testing.T) {
+	bin := &cpeparse.GoBinary{
+		GoVersion: "go1.21.3",
+		Main:      cpeparse.GoModule{Path: "github.com/example/app", Version: "v1.2.0"},
+		Deps: []cpeparse.GoModule{
+			{Path: "golang.org/x/net", Version: "v0.17.0"},
+			{Path: "example", Version: "v1.0.0"}, // not a module path CPE names can be parsed of
+		},
+	}
+	cases := []struct {
+		cfg  config
+		want [][]string
+	}{
+		{config{stdlib: true, main: true}, [][]string{
+			{"cpe:/a:golang:go:1.21.3", "app", "stdlib", "v1.21.3"},
+			{"cpe:/a:example:app:1.2.0::~~~go~~", "app", "github.com/example/app", "v1.2.0"},
+			{"cpe:/a:golang:net:0.17.0::~~~go~~", "app", "golang.org/x/net", "v0.17.0"},
+		}},
+		{config{}, [][]string{
+			{"cpe:/a:golang:net:0.17.0::~~~go~~", "app", "golang.org/x/net", "v0.17.0"},
+		}},
+	}
+	for _, c := range cases {
+		if got := processBinary("app", bin, c.cfg); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: expected %q, got %q", c.cfg, c.want, got)
+		}
+	}
+}
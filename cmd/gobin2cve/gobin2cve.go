@@ -0,0 +1,167 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	"github.com/facebookincubator/nvdtools/providers/govulndb/api"
+	"github.com/facebookincubator/nvdtools/providers/govulndb/check"
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	vulns       string
+	moduleOnly  bool
+	outFieldSep string
+}
+
+func (c *config) addFlags() {
+	flag.StringVar(&c.vulns, "vulns", "", "comma separated list of files of OSV records of the Go vulnerability database, "+
+		"as written by govulndb2nvd -dont_convert, and of directories of local copies of the database")
+	flag.BoolVar(&c.moduleOnly, "module_only", false, "report the vulnerabilities of the module versions without checking "+
+		"whether the binaries have the vulnerable functions")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s finds the vulnerabilities of the Go vulnerability database in Go binaries of the files and\n" +
+			"%[2]s the directories, walked recursively, the way govulncheck does: the modules the binaries are built of are\n" +
+			"%[2]s matched against the affected versions and the functions of the binaries against the vulnerable ones.\n" +
+			"%[2]s The output is delimiter-separated, binary, module path, version, ID, aliases, fixed version and\n" +
+			"%[2]s vulnerable functions found of every vulnerability; the lists are comma separated.\n" +
+			"usage: %[1]s -vulns records.json [flags] binary|directory...\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// loadVulns reads the records of the files and the directories of the comma separated list
+func loadVulns(list string) ([]*schema.Vulnerability, error) {
+	var vulns []*schema.Vulnerability
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			records, err := api.ReadDir(name)
+			if err != nil {
+				return nil, err
+			}
+			for vuln := range records {
+				vulns = append(vulns, vuln)
+			}
+			continue
+		}
+		records, err := readRecords(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		vulns = append(vulns, records...)
+	}
+	return vulns, nil
+}
+
+// readRecords reads the file of a JSON array of records or of a single record
+func readRecords(name string) ([]*schema.Vulnerability, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var vulns []*schema.Vulnerability
+	if data = bytes.TrimSpace(data); len(data) != 0 && data[0] == '{' {
+		var vuln schema.Vulnerability
+		err = json.Unmarshal(data, &vuln)
+		vulns = append(vulns, &vuln)
+	} else {
+		err = json.Unmarshal(data, &vulns)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode OSV records: %v", err)
+	}
+	return vulns, nil
+}
+
+// processBinary returns the output records of the vulnerabilities of the binary
+func processBinary(name string, bin *cpeparse.GoBinary, vulns []*schema.Vulnerability, cfg config) [][]string {
+	var records [][]string
+	for _, f := range check.Binary(bin, vulns, check.Options{ModuleOnly: cfg.moduleOnly}) {
+		records = append(records, []string{
+			name, f.Module, f.Version, f.ID, strings.Join(f.Aliases, ","), f.Fixed, strings.Join(f.Symbols, ","),
+		})
+	}
+	return records
+}
+
+func gobin2cve(paths []string, vulns []*schema.Vulnerability, out io.Writer, cfg config) error {
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	err := cpeparse.WalkGoBinaries(paths, func(name string, bin *cpeparse.GoBinary) error {
+		return w.WriteAll(processBinary(name, bin, vulns, cfg))
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if cfg.vulns == "" || flag.NArg() == 0 {
+		flag.Usage()
+	}
+	vulns, err := loadVulns(cfg.vulns)
+	if err != nil {
+		sayErr(-1, "%v", err)
+	}
+	if err := gobin2cve(flag.Args(), vulns, os.Stdout, cfg); err != nil {
+		sayErr(-1, "%v", err)
+	}
+}
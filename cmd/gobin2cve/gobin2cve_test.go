@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+)
+
+const testRecord = `{
+  "id": "GO-2022-0969",
+  "modified": "2023-06-12T18:45:41Z",
+  "aliases": ["CVE-2022-27664", "GHSA-69cg-p879-7622"],
+  "affected": [{
+    "package": {"name": "stdlib", "ecosystem": "Go"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.18.6"}, {"introduced": "1.19.0"}, {"fixed": "1.19.1"}]}],
+    "ecosystem_specific": {"imports": [{"path": "net/http", "symbols": ["ListenAndServe", "Server.Serve"]}]}
+  }]
+}`
+
+func TestGobin2cve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobin2cve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"record.json":  testRecord,
+		"records.json": "[" + testRecord + "]",
+		"broken.json":  "[{",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, list := range []string{filepath.Join(dir, "record.json"), filepath.Join(dir, "records.json") + ","} {
+		vulns, err := loadVulns(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(vulns) != 1 || vulns[0].ID != "GO-2022-0969" {
+			t.Errorf("%s: unexpected records %+v", list, vulns)
+		}
+	}
+	if _, err := loadVulns(filepath.Join(dir, "broken.json")); err == nil {
+		t.Error("expected failure loading broken records")
+	}
+
+	vulns, err := loadVulns(filepath.Join(dir, "record.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := &cpeparse.GoBinary{GoVersion: "go1.18.2", Symbols: []string{"main.main", "net/http.(*Server).Serve"}}
+	want := [][]string{
+		{"app", "stdlib", "1.18.2", "GO-2022-0969", "CVE-2022-27664,GHSA-69cg-p879-7622", "1.18.6", "net/http.Server.Serve"},
+	}
+	if got := processBinary("app", bin, vulns, config{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	bin.Symbols = []string{"main.main", "net/http.Get"}
+	if got := processBinary("app", bin, vulns, config{}); len(got) != 0 {
+		t.Errorf("expected no vulnerabilities of the binary without the vulnerable functions, got %q", got)
+	}
+	if got := processBinary("app", bin, vulns, config{moduleOnly: true}); len(got) != 1 || got[0][6] != "" {
+		t.Errorf("expected the vulnerability of the module, got %q", got)
+	}
+}
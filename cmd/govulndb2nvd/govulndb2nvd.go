@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/govulndb/api"
+	"github.com/facebookincubator/nvdtools/providers/govulndb/converter"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+func main() {
+	modules := flag.String("modules", "", "comma separated list of module paths to download the vulnerabilities of, e.g. stdlib,golang.org/x/net; all by default")
+	since := flag.String("since", "", "only download the records modified since the date, YYYY-MM-DD")
+	dir := flag.String("dir", "", "path to a local copy of the database to read the records of instead of downloading")
+	baseURL := flag.String("base_url", api.BaseURL, "where the Go vulnerability database is published")
+	timeout := flag.Duration("timeout", time.Hour, "timeout of the download")
+	reviewedOnly := flag.Bool("reviewed_only", false, "skip the records imported from other databases unreviewed by the Go security team")
+	dontConvert := flag.Bool("dont_convert", false, "Should the feed be converted to NVD format or not")
+	httpConfig := api.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
+	var sinceTime time.Time
+	if *since != "" {
+		if sinceTime, err = time.Parse("2006-01-02", *since); err != nil {
+			logging.Errorf("-since: expected YYYY-MM-DD, got %q", *since)
+			flag.Usage()
+		}
+	}
+	if *dir != "" && (*modules != "" || *since != "") {
+		logging.Errorf("-modules and -since select records to download, they can't be used with -dir")
+		flag.Usage()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var vulns <-chan *schema.Vulnerability
+	if *dir != "" {
+		vulns, err = api.ReadDir(*dir)
+	} else {
+		var httpClient *client.Client
+		if httpClient, err = client.New(httpConfig); err != nil {
+			logging.Fatal(err)
+		}
+		client := api.NewClient()
+		client.BaseURL, client.HTTP = *baseURL, httpClient
+		vulns, err = client.FetchAll(ctx, sinceTime, split(*modules))
+	}
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	if *dontConvert {
+		var output []*schema.Vulnerability
+		for vuln := range vulns {
+			if !*reviewedOnly || converter.Reviewed(vuln) {
+				output = append(output, vuln)
+			}
+		}
+		writeOutput(output)
+	} else {
+		writeOutput(converter.Convert(vulns, *reviewedOnly))
+	}
+}
+
+func split(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func writeOutput(output interface{}) {
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		logging.Fatal(err)
+	}
+}
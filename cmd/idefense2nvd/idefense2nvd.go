@@ -18,13 +18,15 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/providers/idefense/api"
 	"github.com/facebookincubator/nvdtools/providers/idefense/converter"
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 )
 
 const (
@@ -38,10 +40,9 @@ var (
 )
 
 func init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	apiKey = os.Getenv("IDEFENSE_TOKEN")
 	if apiKey == "" {
-		log.Fatal("Please set IDEFENSE_TOKEN in environment")
+		logging.Fatal("Please set IDEFENSE_TOKEN in environment")
 	}
 }
 
@@ -51,6 +52,11 @@ func main() {
 	sinceDuration := flag.String("since", "", "Golang duration string, overrides -since_unix flag")
 	sinceUnix := flag.Int64("since_unix", 0, "Unix timestamp since when should we download. If not set, downloads all available data")
 	dontConvert := flag.Bool("dont_convert", false, "Should the feed be converted to NVD format or not")
+	httpConfig := api.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		flag.PrintDefaults()
@@ -58,11 +64,21 @@ func main() {
 	}
 	flag.Parse()
 
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
 	since := *sinceUnix
 	if *sinceDuration != "" {
 		dur, err := time.ParseDuration("-" + *sinceDuration)
 		if err != nil {
-			log.Fatal(err)
+			logging.Fatal(err)
 		}
 		since = time.Now().Add(dur).Unix()
 	}
@@ -70,17 +86,23 @@ func main() {
 	parameters = make(map[string]interface{})
 	if *parametersPath != "" {
 		if err := readParameters(*parametersPath); err != nil {
-			log.Fatal(err)
+			logging.Fatal(err)
 		}
 	}
 	parameters["last_published.from"] = time.Unix(since, 0).Format("2006-01-02T15:04:05.000Z")
 
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
 	// create the API
 	client := api.NewClient(*url, apiKey, parameters)
+	client.HTTP = httpClient
 
 	vulns, err := client.FetchAll()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal(err)
 	}
 
 	if *dontConvert {
@@ -96,7 +118,7 @@ func main() {
 
 func writeOutput(output interface{}) {
 	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
-		log.Fatal(err)
+		logging.Fatal(err)
 	}
 }
 
@@ -0,0 +1,335 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxMetadataSize bounds the size of the archive entries kept as image metadata (manifests, configs)
+const maxMetadataSize = 4 << 20
+
+// layer is the content of image layer relevant to package databases
+type layer struct {
+	files     map[string][]byte // package database files by path
+	whiteouts []string          // paths removed from the lower layers
+	opaque    []string          // directories whose content of the lower layers is removed
+}
+
+func newLayer() *layer {
+	return &layer{files: map[string][]byte{}}
+}
+
+func (l *layer) empty() bool {
+	return len(l.files) == 0 && len(l.whiteouts) == 0 && len(l.opaque) == 0
+}
+
+// add records the file of the layer if it's a package database or a whiteout
+func (l *layer) add(hdr *tar.Header, r io.Reader) error {
+	name := cleanPath(hdr.Name)
+	dir, base := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	switch {
+	case base == ".wh..wh..opq":
+		l.opaque = append(l.opaque, dir)
+	case strings.HasPrefix(base, ".wh."):
+		l.whiteouts = append(l.whiteouts, path.Join(dir, strings.TrimPrefix(base, ".wh.")))
+	case hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA:
+		if databaseOf(name) == "" {
+			return nil
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("couldn't read %q: %v", hdr.Name, err)
+		}
+		l.files[name] = data
+	}
+	return nil
+}
+
+// cleanPath returns the path of archive entry relative to the root, e.g. var/lib/dpkg/status for ./var/lib/dpkg/status
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// archive is the content of image tarball relevant to package databases
+type archive struct {
+	layers   map[string]*layer // layers by entry name
+	metadata map[string][]byte // small entries which aren't layers by entry name, e.g. manifests and configs
+	root     *layer            // the archive itself as a layer, for the tarballs of root filesystem
+}
+
+// readArchive reads image tarball (optionally gzip-compressed) in a single pass: every entry is scanned as a possible
+// layer, small entries are kept as metadata, so the order of entries doesn't matter
+func readArchive(r io.Reader) (*archive, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	a := &archive{layers: map[string]*layer{}, metadata: map[string][]byte{}, root: newLayer()}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read image tarball: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		name := cleanPath(hdr.Name)
+		if databaseOf(name) != "" {
+			if err = a.root.add(hdr, tr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		br := bufio.NewReaderSize(tr, 64<<10)
+		l, err := scanLayer(br)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read layer %q: %v", hdr.Name, err)
+		}
+		if l != nil {
+			a.layers[name] = l
+			continue
+		}
+		if hdr.Size <= maxMetadataSize {
+			data, err := ioutil.ReadAll(br)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't read %q: %v", hdr.Name, err)
+			}
+			a.metadata[name] = data
+		}
+	}
+}
+
+// decompress returns the reader of gzip-compressed or uncompressed stream
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// scanLayer returns nil if r isn't a layer, i.e. a tarball, gzip-compressed or not
+func scanLayer(br *bufio.Reader) (*layer, error) {
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		zbr := bufio.NewReader(zr)
+		if !isTar(zbr) {
+			return nil, nil
+		}
+		r = zbr
+	} else if !isTar(br) {
+		return nil, nil
+	}
+	l := newLayer()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return l, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = l.add(hdr, tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isTar checks for the magic of POSIX and GNU tar header
+func isTar(br *bufio.Reader) bool {
+	hdr, _ := br.Peek(512)
+	return len(hdr) == 512 && bytes.HasPrefix(hdr[257:], []byte("ustar"))
+}
+
+// images returns the layers of every image of the archive, from the bottom to the top: the images of docker save
+// manifest (manifest.json) or OCI image layout (index.json); the archive without either is a root filesystem
+func (a *archive) images() ([][]*layer, error) {
+	if data, ok := a.metadata["manifest.json"]; ok {
+		var manifest []struct {
+			Layers []string
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("couldn't parse manifest.json: %v", err)
+		}
+		var images [][]*layer
+		for _, m := range manifest {
+			layers, err := a.layersOf(m.Layers)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, layers)
+		}
+		return images, nil
+	}
+	if data, ok := a.metadata["index.json"]; ok {
+		var images [][]*layer
+		if err := a.ociImages(data, &images); err != nil {
+			return nil, fmt.Errorf("couldn't parse OCI image layout: %v", err)
+		}
+		return images, nil
+	}
+	if a.root.empty() && len(a.layers) != 0 {
+		return nil, fmt.Errorf("image tarball has neither manifest.json nor index.json")
+	}
+	return [][]*layer{{a.root}}, nil
+}
+
+// ociImages appends the images of OCI image index or manifest
+func (a *archive) ociImages(data []byte, images *[][]*layer) error {
+	type descriptor struct {
+		Digest string
+	}
+	var m struct {
+		Manifests []descriptor
+		Layers    []descriptor
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if m.Layers != nil {
+		var names []string
+		for _, d := range m.Layers {
+			names = append(names, blobPath(d.Digest))
+		}
+		layers, err := a.layersOf(names)
+		if err != nil {
+			return err
+		}
+		*images = append(*images, layers)
+		return nil
+	}
+	for _, d := range m.Manifests {
+		blob, ok := a.metadata[blobPath(d.Digest)]
+		if !ok {
+			return fmt.Errorf("no blob of manifest %q", d.Digest)
+		}
+		if err := a.ociImages(blob, images); err != nil {
+			return fmt.Errorf("manifest %q: %v", d.Digest, err)
+		}
+	}
+	return nil
+}
+
+// blobPath returns the path of the blob of the digest in OCI image layout, e.g. blobs/sha256/abc for sha256:abc
+func blobPath(digest string) string {
+	return path.Join("blobs", strings.Replace(digest, ":", "/", 1))
+}
+
+func (a *archive) layersOf(names []string) ([]*layer, error) {
+	layers := make([]*layer, 0, len(names))
+	for _, name := range names {
+		l, ok := a.layers[cleanPath(name)]
+		if !ok {
+			return nil, fmt.Errorf("no layer %q in image tarball (only gzip-compressed and uncompressed layers are supported)", name)
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+// mergeLayers stacks the layers from the bottom to the top and returns the package database files of the result
+func mergeLayers(layers []*layer) map[string][]byte {
+	files := map[string][]byte{}
+	remove := func(p string, self bool) {
+		for name := range files {
+			if (self && name == p) || strings.HasPrefix(name, p+"/") || p == "" {
+				delete(files, name)
+			}
+		}
+	}
+	for _, l := range layers {
+		// whiteouts only hide the content of the lower layers
+		for _, dir := range l.opaque {
+			remove(dir, false)
+		}
+		for _, p := range l.whiteouts {
+			remove(p, true)
+		}
+		for name, data := range l.files {
+			files[name] = data
+		}
+	}
+	return files
+}
+
+// package databases, by the name of package manager
+const (
+	dpkgDB = "dpkg"
+	apkDB  = "apk"
+	rpmDB  = "rpm"
+)
+
+// unsupportedRPMDBs are rpm databases of formats other than Berkeley DB
+var unsupportedRPMDBs = map[string]bool{
+	"var/lib/rpm/rpmdb.sqlite":          true,
+	"usr/lib/sysimage/rpm/rpmdb.sqlite": true,
+	"var/lib/rpm/Packages.db":           true,
+	"usr/lib/sysimage/rpm/Packages.db":  true,
+}
+
+// databaseOf returns the package manager of the database at the path, or empty string if it isn't one;
+// status.d holds a status file per package on distroless images
+func databaseOf(name string) string {
+	switch name {
+	case "var/lib/dpkg/status":
+		return dpkgDB
+	case "lib/apk/db/installed":
+		return apkDB
+	case "var/lib/rpm/Packages", "usr/lib/sysimage/rpm/Packages":
+		return rpmDB
+	}
+	if unsupportedRPMDBs[name] {
+		return rpmDB
+	}
+	if dir, base := path.Split(name); dir == "var/lib/dpkg/status.d/" && !strings.HasSuffix(base, ".md5sums") {
+		return dpkgDB
+	}
+	return ""
+}
+
+// sortedPaths returns the paths of the files in lexical order
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	all         bool
+	outFieldSep string
+}
+
+func (c *config) addFlags() {
+	flag.BoolVar(&c.all, "all", false, "also output the packages of dpkg status file which aren't installed")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s reads container image tarballs (docker save or OCI image layout, gzip-compressed or not)\n" +
+			"%[2]s or root filesystem tarballs, finds the packages installed by dpkg, apk and rpm in the\n" +
+			"%[2]s image with all the layers applied and produces delimiter-separated output consisting of\n" +
+			"%[2]s CPE name, package manager, package name, version and architecture of every package.\n" +
+			"%[2]s Images are read from the files or standard input, pulling them from registries isn't supported.\n" +
+			"usage: %[1]s [flags] [image.tar...]\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// imagePackage is a package installed in the image
+type imagePackage struct {
+	manager string
+	name    string
+	version string
+	arch    string
+	attr    *wfn.Attributes
+}
+
+// processPackage returns the output record of the package
+func processPackage(p imagePackage) []string {
+	return []string{p.attr.BindToURI(), p.manager, p.name, p.version, p.arch}
+}
+
+// imagePackages calls fn for every package of the package database files; packages which CPE names couldn't be
+// parsed of are reported and skipped
+func imagePackages(files map[string][]byte, cfg config, fn func(imagePackage) error) error {
+	emit := func(p imagePackage, err error) error {
+		if err != nil {
+			sayErr(0, "couldn't parse %s package %q %q: %v", p.manager, p.name, p.version, err)
+			return nil
+		}
+		return fn(p)
+	}
+	for _, name := range sortedPaths(files) {
+		data := files[name]
+		var err error
+		switch databaseOf(name) {
+		case dpkgDB:
+			err = cpeparse.ParseDebStatus(bytes.NewReader(data), func(d cpeparse.DebPackage) error {
+				if !cfg.all && !d.Installed() {
+					return nil
+				}
+				p := imagePackage{manager: dpkgDB, name: d.Package, version: d.Version, arch: d.Architecture}
+				var err error
+				p.attr, err = cpeparse.FromDebPackage(d.Package, d.Version, d.Architecture)
+				return emit(p, err)
+			})
+		case apkDB:
+			err = cpeparse.ParseAPKInstalled(bytes.NewReader(data), func(a cpeparse.APKPackage) error {
+				p := imagePackage{manager: apkDB, name: a.Package, version: a.Version, arch: a.Architecture}
+				var err error
+				p.attr, err = cpeparse.FromAPKPackage(a.Package, a.Version, a.Architecture)
+				return emit(p, err)
+			})
+		case rpmDB:
+			if unsupportedRPMDBs[name] {
+				sayErr(0, "%s: only rpm databases of Berkeley DB format are supported, skipping", name)
+				continue
+			}
+			err = cpeparse.ParseRPMDB(data, func(r cpeparse.RPMPackage) error {
+				if r.Name == "gpg-pubkey" {
+					return nil // imported signing keys aren't packages
+				}
+				version := r.Version + "-" + r.Release
+				if r.Epoch != 0 {
+					version = fmt.Sprintf("%d:%s", r.Epoch, version)
+				}
+				p := imagePackage{manager: rpmDB, name: r.Name, version: version, arch: r.Architecture}
+				var err error
+				p.attr, err = cpeparse.FromRPMPackage(r)
+				return emit(p, err)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func image2cpe(in io.Reader, out io.Writer, cfg config) error {
+	a, err := readArchive(in)
+	if err != nil {
+		return err
+	}
+	images, err := a.images()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	for _, layers := range images {
+		err = imagePackages(mergeLayers(layers), cfg, func(p imagePackage) error {
+			return w.Write(processPackage(p))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		if err := image2cpe(os.Stdin, os.Stdout, cfg); err != nil {
+			sayErr(-1, "%v", err)
+		}
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			sayErr(-1, "couldn't open image tarball: %v (to scan an image of a registry, pull and docker save it first)", err)
+		}
+		err = image2cpe(f, os.Stdout, cfg)
+		f.Close()
+		if err != nil {
+			sayErr(-1, "%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	name string
+	data []byte
+}
+
+func testTar(t *testing.T, compress bool, files ...testFile) []byte {
+	var buf bytes.Buffer
+	var zw *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if compress {
+		zw = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(zw)
+	}
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+const (
+	testStatus = "Package: bash\nStatus: install ok installed\nArchitecture: amd64\nVersion: 5.0-6ubuntu1.2\n\n" +
+		"Package: removed\nStatus: deinstall ok config-files\nArchitecture: all\nVersion: 1.0-1\n"
+	testUpgradedStatus = "Package: bash\nStatus: install ok installed\nArchitecture: amd64\nVersion: 5.0-6ubuntu1.3\n"
+	testInstalled      = "P:musl\nV:1.2.2-r7\nA:x86_64\n\n"
+
+	testBash    = "cpe:/a::bash:5.0:6ubuntu1.3:~~~~amd64~\tdpkg\tbash\t5.0-6ubuntu1.3\tamd64\n"
+	testOldBash = "cpe:/a::bash:5.0:6ubuntu1.2:~~~~amd64~\tdpkg\tbash\t5.0-6ubuntu1.2\tamd64\n"
+	testMusl    = "cpe:/a::musl:1.2.2:r7:~~~~x86_64~\tapk\tmusl\t1.2.2-r7\tx86_64\n"
+)
+
+func TestImage2CPE(t *testing.T) {
+	base := testTar(t, true,
+		testFile{"./var/lib/dpkg/status", []byte(testStatus)},
+		testFile{"lib/apk/db/installed", []byte(testInstalled)},
+		testFile{"etc/hostname", []byte("test\n")},
+	)
+	upgrade := testTar(t, false, testFile{"var/lib/dpkg/status", []byte(testUpgradedStatus)})
+	removeAPK := testTar(t, false, testFile{"lib/apk/.wh.db", nil})
+	opaque := testTar(t, false, testFile{"var/lib/dpkg/.wh..wh..opq", nil})
+
+	cases := []struct {
+		name    string
+		archive []byte
+		out     string
+		fail    bool
+	}{
+		{
+			"docker save",
+			testTar(t, false,
+				testFile{"manifest.json", []byte(`[{"Config":"c.json","RepoTags":["test:latest"],"Layers":["1/layer.tar","2/layer.tar"]}]`)},
+				testFile{"1/layer.tar", base},
+				testFile{"2/layer.tar", upgrade},
+			),
+			testMusl + testBash,
+			false,
+		},
+		{
+			"compressed docker save, manifest last",
+			testTar(t, true,
+				testFile{"2/layer.tar", removeAPK},
+				testFile{"1/layer.tar", base},
+				testFile{"manifest.json", []byte(`[{"Layers":["1/layer.tar","2/layer.tar"]}]`)},
+			),
+			testOldBash,
+			false,
+		},
+		{
+			"OCI image layout",
+			testTar(t, false,
+				testFile{"oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+				testFile{"index.json", []byte(`{"manifests":[{"digest":"sha256:aaa"}]}`)},
+				testFile{"blobs/sha256/aaa", []byte(`{"layers":[{"digest":"sha256:bbb"},{"digest":"sha256:ccc"}]}`)},
+				testFile{"blobs/sha256/bbb", base},
+				testFile{"blobs/sha256/ccc", opaque},
+			),
+			testMusl,
+			false,
+		},
+		{
+			"root filesystem",
+			testTar(t, false, testFile{"var/lib/dpkg/status", []byte(testStatus)}),
+			testOldBash,
+			false,
+		},
+		{
+			"missing layer",
+			testTar(t, false, testFile{"manifest.json", []byte(`[{"Layers":["1/layer.tar"]}]`)}),
+			"",
+			true,
+		},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		err := image2cpe(bytes.NewReader(c.archive), &out, config{outFieldSep: "\t"})
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%s: unexpected failure: %v", c.name, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%s: unexpected success", c.name)
+			continue
+		}
+		if out.String() != c.out {
+			t.Errorf("%s: expected\n%s\ngot\n%s", c.name, c.out, out.String())
+		}
+	}
+}
+
+func TestAllPackages(t *testing.T) {
+	rootfs := testTar(t, false, testFile{"var/lib/dpkg/status", []byte(testStatus)})
+	var out bytes.Buffer
+	if err := image2cpe(bytes.NewReader(rootfs), &out, config{all: true, outFieldSep: ","}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 {
+		t.Errorf("expected 2 packages, got %q", lines)
+	}
+}
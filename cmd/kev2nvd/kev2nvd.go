@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/providers/kev"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+
+	"github.com/golang/glog"
+)
+
+func main() {
+	url := flag.String("url", cvefeed.KEVURL, "URL of KEV catalog in JSON")
+	path := flag.String("catalog", "", "path to KEV catalog in JSON, instead of downloading it from -url")
+	timeout := flag.Duration("timeout", time.Minute, "download timeout")
+	httpConfig := client.DefaultConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Println("Usage: kev2nvd [flags] > kev.cve.json")
+		fmt.Println("Converts CISA Known Exploited Vulnerabilities catalog to NVD CVE JSON feed.")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	var feed *jsonschema.NVDCVEFeedJSON10
+	if *path != "" {
+		catalog, err := cvefeed.LoadKEV(*path)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		feed, err = kev.Convert(catalog)
+		if err != nil {
+			glog.Fatal(err)
+		}
+	} else {
+		httpClient, err := client.New(httpConfig)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		feed, err = kev.FetchWith(ctx, httpClient, *url)
+		cancel()
+		if err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(feed); err != nil {
+		glog.Fatal(err)
+	}
+}
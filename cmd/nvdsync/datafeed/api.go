@@ -0,0 +1,539 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datafeed
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/golang/glog"
+)
+
+// API defines the NVD API 2.0 endpoint for synchronization.
+type API int
+
+// Supported NVD API endpoints.
+const (
+	cveAPI20 API = iota // CVE API 2.0, mirrored as NVD CVE API 2.0 response in JSON, gzip compressed.
+	cpeAPI20            // CPE API 2.0, mirrored as NVD CPE API 2.0 response in JSON, gzip compressed.
+)
+
+// SupportedAPI contains all supported NVD API endpoints indexed by name.
+var SupportedAPI = map[string]API{
+	"cve-api-2.0": cveAPI20,
+	"cpe-api-2.0": cpeAPI20,
+}
+
+// Limits of NVD API 2.0, see https://nvd.nist.gov/developers/start-here
+var (
+	// apiPageSize is the maximum resultsPerPage of every endpoint
+	apiPageSize = map[API]int{
+		cveAPI20: 2000,
+		cpeAPI20: 10000,
+	}
+	// apiMaxRange is the longest range of lastModStartDate and lastModEndDate; mirrors synced
+	// before that are synced in full
+	apiMaxRange = 120 * 24 * time.Hour
+	// apiDelay is the delay between requests for the public rate limit of 5 requests in 30 seconds
+	apiDelay = 6 * time.Second
+	// apiDelayWithKey is the delay between requests for the rate limit of 50 requests in 30 seconds with API key
+	apiDelayWithKey = 600 * time.Millisecond
+	// apiRetries is the number of retries of requests rejected because of rate limits or server overload
+	apiRetries = 2
+	// apiRetryDelay is the delay before the first retry, it doubles with every retry
+	apiRetryDelay = 30 * time.Second
+)
+
+// Set implements the flag.Value interface.
+func (a *API) Set(v string) error {
+	api, exists := SupportedAPI[v]
+	if !exists {
+		return fmt.Errorf("unsupported NVD API: %q", v)
+	}
+	*a = api
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (a API) String() string {
+	return a.object() + "-api-2.0"
+}
+
+// Help returns the API flag help.
+func (a API) Help() string {
+	opts := make([]string, 0, len(SupportedAPI))
+	for k := range SupportedAPI {
+		opts = append(opts, k)
+	}
+	sort.Strings(opts)
+	return fmt.Sprintf(
+		"NVD API to sync instead of the data feeds, can be repeated\navailable:\n%s",
+		strings.Join(opts, "\n"),
+	)
+}
+
+// object returns the kind of objects served by the endpoint: cve or cpe.
+func (a API) object() string {
+	switch a {
+	case cveAPI20:
+		return "cve"
+	case cpeAPI20:
+		return "cpe"
+	default:
+		panic("unsupported NVD API")
+	}
+}
+
+// path returns the path of the endpoint.
+func (a API) path(src SourceConfig) string {
+	switch a {
+	case cveAPI20:
+		return src.CVEAPIPath
+	case cpeAPI20:
+		return src.CPEAPIPath
+	default:
+		panic("unsupported NVD API")
+	}
+}
+
+// Sync synchronizes the objects served by the API to a local directory.
+func (a API) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	basename := "nvd" + a.object() + "-api-2.0"
+	af := apiFile{
+		API:       a,
+		StateFile: basename + ".state",
+		DataFile:  basename + ".json.gz",
+	}
+	return af.Sync(ctx, src, localdir)
+}
+
+// SyncDiff is like Sync, but it also returns the changes of the objects since the previous sync:
+// rejected CVEs and deprecated CPEs are withdrawn, so are the objects gone from the API on full syncs.
+func (a API) SyncDiff(ctx context.Context, src SourceConfig, localdir string) ([]Diff, error) {
+	basename := "nvd" + a.object() + "-api-2.0"
+	af := apiFile{
+		API:       a,
+		StateFile: basename + ".state",
+		DataFile:  basename + ".json.gz",
+	}
+	d, err := af.sync(ctx, src, localdir, true)
+	if err != nil {
+		return nil, err
+	}
+	return []Diff{*d}, nil
+}
+
+// APIs is a list of NVD API endpoints, it implements the flag.Value interface for repeated flags.
+type APIs []API
+
+// Set implements the flag.Value interface.
+func (as *APIs) Set(v string) error {
+	var a API
+	if err := a.Set(v); err != nil {
+		return err
+	}
+	*as = append(*as, a)
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (as APIs) String() string {
+	names := make([]string, len(as))
+	for i, a := range as {
+		names[i] = a.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// Help returns the APIs flag help.
+func (as APIs) Help() string {
+	return API(0).Help()
+}
+
+// Syncers returns the synchronizers of the endpoints.
+func (as APIs) Syncers() []Syncer {
+	syncers := make([]Syncer, len(as))
+	for i, a := range as {
+		syncers[i] = a
+	}
+	return syncers
+}
+
+// apiPage is a page of NVD API 2.0 response, objects are left undecoded.
+// Local mirrors are stored as a single page with all the objects, so they can be read as API responses.
+type apiPage struct {
+	ResultsPerPage  int               `json:"resultsPerPage"`
+	StartIndex      int               `json:"startIndex"`
+	TotalResults    int               `json:"totalResults"`
+	Format          string            `json:"format"`
+	Version         string            `json:"version"`
+	Timestamp       string            `json:"timestamp"`
+	Vulnerabilities []json.RawMessage `json:"vulnerabilities,omitempty"`
+	Products        []json.RawMessage `json:"products,omitempty"`
+}
+
+// objects returns the objects of the page served by the endpoint.
+func (a API) objects(page *apiPage) *[]json.RawMessage {
+	if a == cpeAPI20 {
+		return &page.Products
+	}
+	return &page.Vulnerabilities
+}
+
+// objectID returns the identifier of an object served by the endpoint: CVE ID or CPE name ID.
+func (a API) objectID(raw json.RawMessage) (string, error) {
+	var obj struct {
+		CVE *struct {
+			ID string `json:"id"`
+		} `json:"cve"`
+		CPE *struct {
+			ID string `json:"cpeNameId"`
+		} `json:"cpe"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return "", err
+	}
+	var id string
+	switch {
+	case a == cveAPI20 && obj.CVE != nil:
+		id = obj.CVE.ID
+	case a == cpeAPI20 && obj.CPE != nil:
+		id = obj.CPE.ID
+	}
+	if id == "" {
+		return "", fmt.Errorf("%s object without identifier: %.128s", a, raw)
+	}
+	return id, nil
+}
+
+// objectVersion returns the version of an object served by the endpoint.
+func (a API) objectVersion(raw json.RawMessage) (objectVersion, error) {
+	var obj struct {
+		CVE *struct {
+			LastModified string `json:"lastModified"`
+			VulnStatus   string `json:"vulnStatus"`
+		} `json:"cve"`
+		CPE *struct {
+			LastModified string `json:"lastModified"`
+			Deprecated   bool   `json:"deprecated"`
+		} `json:"cpe"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return objectVersion{}, err
+	}
+	switch {
+	case a == cveAPI20 && obj.CVE != nil:
+		return objectVersion{lastModified: obj.CVE.LastModified, withdrawn: obj.CVE.VulnStatus == "Rejected"}, nil
+	case a == cpeAPI20 && obj.CPE != nil:
+		return objectVersion{lastModified: obj.CPE.LastModified, withdrawn: obj.CPE.Deprecated}, nil
+	}
+	return objectVersion{}, fmt.Errorf("%s object without version: %.128s", a, raw)
+}
+
+// apiTimeFormat is the format of lastModStartDate and lastModEndDate parameters.
+const apiTimeFormat = "2006-01-02T15:04:05.000-07:00"
+
+type apiFile struct {
+	API
+	StateFile string
+	DataFile  string
+}
+
+func (af apiFile) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	_, err := af.sync(ctx, src, localdir, false)
+	return err
+}
+
+// sync synchronizes the local mirror, returning the changes of the objects if diff is set, nil otherwise.
+func (af apiFile) sync(ctx context.Context, src SourceConfig, localdir string, diff bool) (*Diff, error) {
+	now := time.Now().UTC()
+	since, err := af.lastSync(localdir)
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[string]json.RawMessage)
+	if !since.IsZero() && now.Sub(since) < apiMaxRange {
+		if objects, err = af.load(localdir); os.IsNotExist(err) {
+			glog.V(1).Infof("data file %q does not exist in %q, needs full sync", af.DataFile, localdir)
+			objects, since = make(map[string]json.RawMessage), time.Time{}
+		} else if err != nil {
+			return nil, err
+		}
+	} else if !since.IsZero() {
+		glog.V(1).Infof("data file %q was synced before %s, needs full sync", af.DataFile, since.Format(time.RFC3339))
+		since = time.Time{}
+	}
+
+	var prev map[string]objectVersion
+	if diff {
+		if prev, err = af.previousVersions(localdir, objects, since); err != nil {
+			return nil, err
+		}
+	}
+
+	params := url.Values{}
+	if !since.IsZero() {
+		params.Set("lastModStartDate", since.Format(apiTimeFormat))
+		params.Set("lastModEndDate", now.Format(apiTimeFormat))
+	}
+	n, err := af.fetch(ctx, src, params, objects)
+	if err != nil {
+		return nil, err
+	}
+	glog.V(1).Infof("fetched %d objects for %q", n, af.DataFile)
+	if n != 0 || since.IsZero() {
+		if err = af.writeData(filepath.Join(localdir, af.DataFile), objects, now); err != nil {
+			return nil, err
+		}
+	}
+	var d *Diff
+	if diff {
+		next, err := af.versions(objects)
+		if err != nil {
+			return nil, err
+		}
+		changes := diffVersions(af.DataFile, now, prev, next, since.IsZero())
+		d = &changes
+	}
+	return d, af.writeState(filepath.Join(localdir, af.StateFile), now)
+}
+
+// previousVersions returns the versions of the objects before the sync: these of objects loaded for an incremental
+// sync, or these of the local mirror, if any, for a full one.
+func (af apiFile) previousVersions(localdir string, objects map[string]json.RawMessage, since time.Time) (map[string]objectVersion, error) {
+	if since.IsZero() {
+		var err error
+		if objects, err = af.load(localdir); os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return af.versions(objects)
+}
+
+// versions returns the versions of objects indexed by their identifiers.
+func (af apiFile) versions(objects map[string]json.RawMessage) (map[string]objectVersion, error) {
+	versions := make(map[string]objectVersion, len(objects))
+	for id, raw := range objects {
+		v, err := af.objectVersion(raw)
+		if err != nil {
+			return nil, err
+		}
+		versions[id] = v
+	}
+	return versions, nil
+}
+
+// lastSync returns the time of the last sync recorded in the state file, zero time if there's none.
+func (af apiFile) lastSync(localdir string) (time.Time, error) {
+	b, err := ioutil.ReadFile(filepath.Join(localdir, af.StateFile))
+	if os.IsNotExist(err) {
+		glog.V(1).Infof("state file %q does not exist in %q, needs full sync", af.StateFile, localdir)
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(b)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed data in local state %q: %v", af.StateFile, err)
+	}
+	return t, nil
+}
+
+// load loads the objects of the local mirror indexed by their identifiers.
+func (af apiFile) load(localdir string) (map[string]json.RawMessage, error) {
+	f, err := os.Open(filepath.Join(localdir, af.DataFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var page apiPage
+	if err = json.NewDecoder(r).Decode(&page); err != nil {
+		return nil, fmt.Errorf("malformed data in local data file %q: %v", af.DataFile, err)
+	}
+	objects := make(map[string]json.RawMessage, page.TotalResults)
+	for _, raw := range *af.objects(&page) {
+		id, err := af.objectID(raw)
+		if err != nil {
+			return nil, fmt.Errorf("malformed data in local data file %q: %v", af.DataFile, err)
+		}
+		objects[id] = raw
+	}
+	return objects, nil
+}
+
+// fetch fetches all the pages of objects matching params, adding them to objects.
+// Returns the number of objects fetched.
+func (af apiFile) fetch(ctx context.Context, src SourceConfig, params url.Values, objects map[string]json.RawMessage) (int, error) {
+	u := url.URL{
+		Scheme: src.Scheme,
+		Host:   src.APIHost,
+		Path:   af.path(src),
+	}
+	delay := apiDelay
+	if src.APIKey != "" {
+		delay = apiDelayWithKey
+	}
+	params.Set("resultsPerPage", strconv.Itoa(apiPageSize[af.API]))
+	fetched := 0
+	for start := 0; ; {
+		params.Set("startIndex", strconv.Itoa(start))
+		u.RawQuery = params.Encode()
+		if start != 0 {
+			if err := sleepContext(ctx, delay); err != nil {
+				return fetched, err
+			}
+		}
+		page, err := af.fetchPage(ctx, u.String(), src.APIKey)
+		if err != nil {
+			return fetched, err
+		}
+		for _, raw := range *af.objects(page) {
+			id, err := af.objectID(raw)
+			if err != nil {
+				return fetched, fmt.Errorf("malformed data in %q: %v", u.String(), err)
+			}
+			objects[id] = raw
+		}
+		n := len(*af.objects(page))
+		fetched += n
+		start += n
+		if n == 0 || start >= page.TotalResults {
+			return fetched, nil
+		}
+	}
+}
+
+// fetchPage fetches a page of objects; requests rejected because of rate limits or server overload
+// are retried by the client, see HTTPConfig.
+func (af apiFile) fetchPage(ctx context.Context, pageURL, apiKey string) (*apiPage, error) {
+	req, err := httpNewRequestContext(ctx, "GET", pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("apiKey", apiKey)
+	}
+	glog.V(1).Infof("downloading page %q", pageURL)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodePage(resp)
+}
+
+// apiRetryable tells whether the request answered with the response can be retried:
+// NVD rejects requests above the rate limits with 403 Forbidden, the overloaded servers with 503 Service Unavailable
+func apiRetryable(resp *http.Response, body []byte) bool {
+	return resp.StatusCode == http.StatusForbidden || client.DefaultRetryable(resp, body)
+}
+
+func decodePage(resp *http.Response) (*apiPage, error) {
+	if err := httpResponseNotOK(resp); err != nil {
+		return nil, err
+	}
+	var page apiPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("malformed data in %q: %v", resp.Request.URL.String(), err)
+	}
+	return &page, nil
+}
+
+// writeData writes objects to the local mirror, sorted by their identifiers.
+func (af apiFile) writeData(name string, objects map[string]json.RawMessage, now time.Time) error {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	page := apiPage{
+		ResultsPerPage: len(objects),
+		TotalResults:   len(objects),
+		Format:         "NVD_" + strings.ToUpper(af.object()),
+		Version:        "2.0",
+		Timestamp:      now.Format("2006-01-02T15:04:05.000"),
+	}
+	list := af.objects(&page)
+	*list = make([]json.RawMessage, len(ids))
+	for i, id := range ids {
+		(*list)[i] = objects[id]
+	}
+
+	f, err := ioutil.TempFile("", "nvdsync-data-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	w := gzip.NewWriter(f)
+	err = json.NewEncoder(w).Encode(page)
+	if err == nil {
+		err = w.Close()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	bak := name + ".bak"
+	xRename(name, bak)
+	if err = xRename(f.Name(), name); err != nil {
+		xRename(bak, name)
+		return err
+	}
+	os.Remove(bak)
+	filesUpdated.Inc()
+	return nil
+}
+
+// writeState records the time of the sync in the state file.
+func (af apiFile) writeState(name string, now time.Time) error {
+	return ioutil.WriteFile(name, []byte(now.Format(time.RFC3339)+"\n"), 0644)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
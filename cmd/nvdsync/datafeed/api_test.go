@@ -0,0 +1,162 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datafeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// apiTestServer serves CVEs of the last modification dates mods, failing the first request with 503
+type apiTestServer struct {
+	t        *testing.T
+	mods     map[string]time.Time
+	requests int
+}
+
+func (s *apiTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	if s.requests == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if key := r.Header.Get("apiKey"); key != "secret" {
+		s.t.Errorf("unexpected API key %q", key)
+	}
+	q := r.URL.Query()
+	start, _ := strconv.Atoi(q.Get("startIndex"))
+	size, _ := strconv.Atoi(q.Get("resultsPerPage"))
+	var since time.Time
+	if v := q.Get("lastModStartDate"); v != "" {
+		var err error
+		if since, err = time.Parse(apiTimeFormat, v); err != nil {
+			s.t.Errorf("malformed lastModStartDate: %v", err)
+		}
+	}
+	var ids []string
+	for _, id := range []string{"CVE-2023-0001", "CVE-2023-0002", "CVE-2023-0003"} {
+		if s.mods[id].After(since) {
+			ids = append(ids, id)
+		}
+	}
+	page := apiPage{StartIndex: start, TotalResults: len(ids), Format: "NVD_CVE", Version: "2.0"}
+	for i := start; i < len(ids) && i < start+size; i++ {
+		raw := fmt.Sprintf(`{"cve":{"id":%q,"lastModified":%q}}`, ids[i], s.mods[ids[i]].Format("2006-01-02T15:04:05.000"))
+		page.Vulnerabilities = append(page.Vulnerabilities, json.RawMessage(raw))
+	}
+	page.ResultsPerPage = len(page.Vulnerabilities)
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestAPISync(t *testing.T) {
+	defer func(size int, delay time.Duration, c *client.Client) {
+		apiPageSize[cveAPI20], apiDelayWithKey, httpClient = size, delay, c
+	}(apiPageSize[cveAPI20], apiDelayWithKey, httpClient)
+	cfg := HTTPConfig()
+	cfg.Backoff = 0
+	apiPageSize[cveAPI20], apiDelayWithKey, httpClient = 2, 0, client.MustNew(cfg)
+
+	past := time.Now().Add(-time.Hour)
+	s := &apiTestServer{t: t, mods: map[string]time.Time{
+		"CVE-2023-0001": past,
+		"CVE-2023-0002": past,
+		"CVE-2023-0003": past,
+	}}
+	ts, src := httptestNewServer(s)
+	defer ts.Close()
+	src.APIKey = "secret"
+
+	d, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	af := apiFile{API: cveAPI20, StateFile: "nvdcve-api-2.0.state", DataFile: "nvdcve-api-2.0.json.gz"}
+	check := func(wantRequests int, wantModified map[string]time.Time) {
+		t.Helper()
+		if s.requests != wantRequests {
+			t.Errorf("expected %d requests, got %d", wantRequests, s.requests)
+		}
+		objects, err := af.load(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(objects) != len(wantModified) {
+			t.Errorf("expected %d CVEs, got %d", len(wantModified), len(objects))
+		}
+		for id, mod := range wantModified {
+			var obj struct {
+				CVE struct {
+					LastModified string `json:"lastModified"`
+				} `json:"cve"`
+			}
+			if err := json.Unmarshal(objects[id], &obj); err != nil {
+				t.Errorf("%s: %v", id, err)
+			} else if want := mod.Format("2006-01-02T15:04:05.000"); obj.CVE.LastModified != want {
+				t.Errorf("%s: expected last modified %s, got %s", id, want, obj.CVE.LastModified)
+			}
+		}
+	}
+
+	// full sync: 503, then 2 pages of 2 CVEs
+	if err = af.Sync(context.Background(), src, d); err != nil {
+		t.Fatal(err)
+	}
+	check(3, s.mods)
+
+	// incremental sync picks up the modified CVE only
+	if err = ioutil.WriteFile(filepath.Join(d, af.StateFile), []byte(past.Add(time.Minute).Format(time.RFC3339)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.mods["CVE-2023-0002"] = time.Now()
+	if err = af.Sync(context.Background(), src, d); err != nil {
+		t.Fatal(err)
+	}
+	check(4, s.mods)
+
+	since, err := af.lastSync(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(since) > time.Minute {
+		t.Errorf("state file not updated: last sync %s", since)
+	}
+}
+
+func TestAPIFlag(t *testing.T) {
+	var apis APIs
+	for _, v := range []string{"cve-api-2.0", "cpe-api-2.0"} {
+		if err := apis.Set(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := apis.String(); s != "cve-api-2.0,cpe-api-2.0" {
+		t.Errorf("unexpected APIs %q", s)
+	}
+	if err := apis.Set("cve-api-1.0"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -19,13 +19,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/golang/glog"
 )
 
@@ -133,17 +133,13 @@ func (cf cpeFile) baseURL(src SourceConfig) string {
 func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) error {
 	baseURL := cf.baseURL(src)
 	sourceURL := baseURL + cf.DataFile
-	needsUpdate, err := cf.needsUpdate(ctx, sourceURL, localdir)
+	etag, tempDataFilename, err := cf.download(ctx, sourceURL, cf.localEtag(localdir))
 	if err != nil {
 		return err
 	}
-	if !needsUpdate {
+	if tempDataFilename == "" {
 		return nil
 	}
-	etag, tempDataFilename, err := cf.download(ctx, sourceURL)
-	if err != nil {
-		return err
-	}
 	defer os.Remove(tempDataFilename)
 
 	// write etag file
@@ -162,52 +158,42 @@ func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 		return err
 	}
 	os.Remove(bakDataFilename)
+	filesUpdated.Inc()
 	return nil
 }
 
-func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (bool, error) {
-	glog.V(1).Infof("checking etag for %q", targetURL)
-	req, err := httpNewRequestContext(ctx, "HEAD", targetURL)
-	if err != nil {
-		return false, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return false, err
+// localEtag returns the etag of the local data file, empty if there's no data file or no etag file.
+func (cf cpeFile) localEtag(localdir string) string {
+	if _, err := os.Stat(filepath.Join(localdir, cf.DataFile)); err != nil {
+		glog.V(1).Infof("data file %q does not exist in %q, needs sync", cf.DataFile, localdir)
+		return ""
 	}
-	defer resp.Body.Close()
-	if err = httpResponseNotOK(resp); err != nil {
-		return false, err
-	}
-	remoteEtag := resp.Header.Get("Etag")
-	if remoteEtag == "" {
-		return false, fmt.Errorf("server not returning etag header for %q", targetURL)
-	}
-	etagBytes, err := ioutil.ReadFile(filepath.Join(localdir, cf.EtagFile))
+	etag, err := ioutil.ReadFile(filepath.Join(localdir, cf.EtagFile))
 	if err != nil {
-		glog.V(1).Infof("etag file %q for not exist in %q, needs sync", cf.EtagFile, localdir)
-		return true, nil
-	}
-	localEtag := string(etagBytes)
-	if localEtag != remoteEtag {
-		glog.V(1).Infof("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, localEtag, remoteEtag)
-		return true, nil
+		glog.V(1).Infof("etag file %q does not exist in %q, needs sync", cf.EtagFile, localdir)
+		return ""
 	}
-	return false, nil
+	return string(etag)
 }
 
-// download file from targetURL, returns etag and path to local file.
-func (cf cpeFile) download(ctx context.Context, targetURL string) (string, string, error) {
+// download file from targetURL unless its etag is localEtag, returns etag and path to local file;
+// the path is empty if the file is not modified.
+func (cf cpeFile) download(ctx context.Context, targetURL, localEtag string) (string, string, error) {
 	glog.V(1).Infof("downloading data file %q", targetURL)
-	req, err := http.NewRequest("GET", targetURL, nil)
+	req, err := httpNewRequestContext(ctx, "GET", targetURL)
 	if err != nil {
 		return "", "", err
 	}
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	client.Validators{ETag: localEtag}.Set(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", "", err
 	}
 	defer resp.Body.Close()
+	if client.NotModified(resp) {
+		glog.V(1).Infof("data file %q not modified since etag %q", cf.DataFile, localEtag)
+		return localEtag, "", nil
+	}
 	if err = httpResponseNotOK(resp); err != nil {
 		return "", "", err
 	}
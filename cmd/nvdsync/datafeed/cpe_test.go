@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"testing"
 )
 
@@ -30,7 +31,7 @@ func TestCPE(t *testing.T) {
 	}
 	defer os.RemoveAll(td)
 
-	handler := &cpeTestServer{}
+	handler := &cpeTestServer{downloads: make(map[string]int)}
 	ts, src := httptestNewServer(handler)
 	defer ts.Close()
 
@@ -48,14 +49,25 @@ func TestCPE(t *testing.T) {
 				if err != nil {
 					t.Fatal(err)
 				}
+				// the existing sync is up to date, the request is conditional on its etag
+				if n := handler.downloads["official-cpe-dictionary_v"+cpe.version()+".xml."+cpe.compression()]; n != 1 {
+					t.Fatalf("want 1 download, have %d", n)
+				}
 			})
 		}
 	}
 }
 
-type cpeTestServer struct{}
+type cpeTestServer struct {
+	downloads map[string]int
+}
 
 func (ts cpeTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-None-Match") == "foobar" {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	ts.downloads[path.Base(r.URL.Path)]++
 	w.Header().Set("Etag", "foobar")
 	fmt.Fprintf(w, "hello, world")
 }
@@ -21,10 +21,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -138,6 +138,23 @@ func (c CVE) Sync(ctx context.Context, src SourceConfig, localdir string) error
 	return nil
 }
 
+// SyncDiff is like Sync, but it also returns the changes of the CVEs of every updated data file since the
+// previous sync: CVEs gone from the yearly files are withdrawn. Only JSON feeds are diffed, changes of XML feeds
+// aren't reported.
+func (c CVE) SyncDiff(ctx context.Context, src SourceConfig, localdir string) ([]Diff, error) {
+	var diffs []Diff
+	for _, f := range cveFileList(c) {
+		d, err := f.sync(ctx, src, localdir, c.encoding() == "json")
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			diffs = append(diffs, *d)
+		}
+	}
+	return diffs, nil
+}
+
 func cveFileList(c CVE) []cveFile {
 	filefmt := func(version, suffix, encoding, compression string) string {
 		s := fmt.Sprintf("nvdcve-%s-%s.%s", version, suffix, encoding)
@@ -168,6 +185,7 @@ func cveFileList(c CVE) []cveFile {
 			CVE:      c,
 			MetaFile: filefmt(version, suffix, "meta", ""),
 			DataFile: filefmt(version, suffix, encoding, compression),
+			Snapshot: true,
 		}
 	}
 
@@ -192,6 +210,7 @@ type cveFile struct {
 	CVE
 	MetaFile string
 	DataFile string
+	Snapshot bool // the data file has all the CVEs of the year, not just the recent or modified ones
 }
 
 func (cf cveFile) baseURL(src SourceConfig) (string, error) {
@@ -223,43 +242,111 @@ func (cf cveFile) baseURL(src SourceConfig) (string, error) {
 }
 
 func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	_, err := cf.sync(ctx, src, localdir, false)
+	return err
+}
+
+// sync synchronizes the data file, returning the changes of its CVEs if diff is set and the file was updated,
+// nil otherwise; diff only applies to JSON feeds.
+func (cf cveFile) sync(ctx context.Context, src SourceConfig, localdir string, diff bool) (*Diff, error) {
 	baseURL, err := cf.baseURL(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	remoteMetaURL := baseURL + cf.MetaFile
 	glog.V(1).Infof("checking meta file %q for updates to %q", cf.MetaFile, cf.DataFile)
 	remoteMeta, needsUpdate, err := cf.needsUpdate(ctx, remoteMetaURL, localdir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !needsUpdate {
-		return nil
+		return nil, nil
 	}
 	remoteFileURL := baseURL + cf.DataFile
 	tempDataFilename, err := cf.downloadAndVerify(ctx, remoteMeta, remoteFileURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer os.Remove(tempDataFilename)
 
+	dataFilename := filepath.Join(localdir, cf.DataFile)
+	var d *Diff
+	if diff {
+		if d, err = cf.diff(dataFilename, tempDataFilename); err != nil {
+			return nil, err
+		}
+	}
+
 	// write metadata file
 	metaFilename := filepath.Join(localdir, cf.MetaFile)
 	err = remoteMeta.WriteFile(metaFilename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// write data file
-	dataFilename := filepath.Join(localdir, cf.DataFile)
 	bakDataFilename := dataFilename + ".bak"
 	xRename(dataFilename, bakDataFilename)
 	if err = xRename(tempDataFilename, dataFilename); err != nil {
 		xRename(bakDataFilename, dataFilename)
-		return err
+		return nil, err
 	}
 	os.Remove(bakDataFilename)
-	return nil
+	filesUpdated.Inc()
+	return d, nil
+}
+
+// diff returns the changes of the CVEs between the previous (if it exists) and the new JSON data files.
+func (cf cveFile) diff(prevFilename, nextFilename string) (*Diff, error) {
+	var prev map[string]objectVersion
+	if _, err := os.Stat(prevFilename); err == nil {
+		if prev, err = cf.versions(prevFilename); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	next, err := cf.versions(nextFilename)
+	if err != nil {
+		return nil, err
+	}
+	d := diffVersions(cf.DataFile, time.Now().UTC(), prev, next, cf.Snapshot)
+	return &d, nil
+}
+
+// versions returns the versions of the CVEs of the JSON data file indexed by CVE IDs; rejected CVEs
+// (described as ** REJECT **) are withdrawn.
+func (cf cveFile) versions(filename string) (map[string]objectVersion, error) {
+	var feed struct {
+		Items []struct {
+			CVE struct {
+				Meta struct {
+					ID string `json:"ID"`
+				} `json:"CVE_data_meta"`
+				Description struct {
+					Data []struct {
+						Value string `json:"value"`
+					} `json:"description_data"`
+				} `json:"description"`
+			} `json:"cve"`
+			LastModifiedDate string `json:"lastModifiedDate"`
+		} `json:"CVE_Items"`
+	}
+	err := readDataFile(filename, cf.compression(), func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&feed)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("malformed data in data file %q: %v", filename, err)
+	}
+	versions := make(map[string]objectVersion, len(feed.Items))
+	for _, item := range feed.Items {
+		v := objectVersion{lastModified: item.LastModifiedDate}
+		for _, d := range item.CVE.Description.Data {
+			v.withdrawn = v.withdrawn || strings.HasPrefix(d.Value, "** REJECT **")
+		}
+		versions[item.CVE.Meta.ID] = v
+	}
+	return versions, nil
 }
 
 func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir string) (*metaFile, bool, error) {
@@ -323,7 +410,7 @@ func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFile
 		return "", err
 	}
 	glog.V(1).Infof("downloading data file %q", remoteFileURL)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -501,7 +588,7 @@ func newMetaFromURL(ctx context.Context, url string) (metaFile, error) {
 	if err != nil {
 		return m, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return m, err
 	}
@@ -560,21 +647,49 @@ func gunzipFileAndComputeSHA256(filename string) (string, error) {
 }
 
 func unzipFileAndComputeSHA256(filename string) (string, error) {
+	var hash string
+	err := unzipFile(filename, func(r io.Reader) (err error) {
+		hash, err = computeSHA256(r)
+		return err
+	})
+	return hash, err
+}
+
+// unzipFile calls fn with the uncompressed contents of the only file of the zip archive.
+func unzipFile(filename string, fn func(io.Reader) error) error {
 	f, err := zip.OpenReader(filename)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer f.Close()
 	if len(f.File) != 1 {
-		return "", fmt.Errorf(
+		return fmt.Errorf(
 			"unexpected number of files in zip %q: want 1, have %d",
 			filename, len(f.File),
 		)
 	}
 	ff, err := f.File[0].Open()
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer ff.Close()
-	return computeSHA256(ff)
+	return fn(ff)
+}
+
+// readDataFile calls fn with the uncompressed contents of the data file of the compression: gz or zip.
+func readDataFile(filename, compression string, fn func(io.Reader) error) error {
+	if compression == "zip" {
+		return unzipFile(filename, fn)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return fn(r)
 }
@@ -21,10 +21,31 @@ import (
 	"io/ioutil"
 	"net/http"
 	"regexp"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 )
 
 var userAgent = "nvdsync-" + Version
 
+// httpClient executes all requests to NVD, see SetHTTPClient
+var httpClient = client.MustNew(HTTPConfig())
+
+// HTTPConfig returns the default config of the HTTP client, within the limits of NVD
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.Retries = apiRetries
+	cfg.Backoff = apiRetryDelay
+	cfg.MaxBackoff = 4 * apiRetryDelay
+	cfg.Retryable = apiRetryable
+	return cfg
+}
+
+// SetHTTPClient sets the client executing the requests to NVD, e.g. configured by flags,
+// see HTTPConfig for the defaults
+func SetHTTPClient(c *client.Client) {
+	httpClient = c
+}
+
 // http helpers
 
 func httpNewRequestContext(ctx context.Context, method, path string) (*http.Request, error) {
@@ -34,6 +34,9 @@ func httptestNewServer(f http.Handler) (*httptest.Server, SourceConfig) {
 		Host:        tsurl.Host,
 		CVEFeedPath: "/",
 		CPEFeedPath: "/",
+		APIHost:     tsurl.Host,
+		CVEAPIPath:  "/rest/json/cves/2.0",
+		CPEAPIPath:  "/rest/json/cpes/2.0",
 	}
 	return ts, src
 }
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datafeed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// DiffSyncer is implemented by synchronizers which can tell what changed in the data they synced.
+type DiffSyncer interface {
+	Syncer
+	// SyncDiff is like Sync, but it also returns the changes of every local file it synced
+	SyncDiff(ctx context.Context, src SourceConfig, localdir string) ([]Diff, error)
+}
+
+// ChangeKind is the kind of change of an object (CVE or CPE) between syncs.
+type ChangeKind string
+
+// Kinds of changes.
+const (
+	Added     ChangeKind = "added"     // the object is new
+	Modified  ChangeKind = "modified"  // the object was modified since the previous sync
+	Withdrawn ChangeKind = "withdrawn" // the object was rejected, deprecated or dropped from the data
+)
+
+// Diff is the difference between the previous and newly synced data of a local file: object identifiers (CVE IDs or
+// CPE name IDs) of every kind of change, sorted.
+type Diff struct {
+	Feed      string    // the local file
+	Time      time.Time // the time of the sync
+	Added     []string
+	Modified  []string
+	Withdrawn []string
+}
+
+// Empty tells whether nothing changed.
+func (d Diff) Empty() bool {
+	return len(d.Added)+len(d.Modified)+len(d.Withdrawn) == 0
+}
+
+// Change is an entry of the change journal.
+type Change struct {
+	Time time.Time  `json:"time"`
+	Feed string     `json:"feed"`
+	Kind ChangeKind `json:"kind"`
+	ID   string     `json:"id"`
+}
+
+// Changes returns the journal entries of the diff: added, modified and withdrawn objects in this order.
+func (d Diff) Changes() []Change {
+	changes := make([]Change, 0, len(d.Added)+len(d.Modified)+len(d.Withdrawn))
+	for _, kind := range []struct {
+		kind ChangeKind
+		ids  []string
+	}{{Added, d.Added}, {Modified, d.Modified}, {Withdrawn, d.Withdrawn}} {
+		for _, id := range kind.ids {
+			changes = append(changes, Change{Time: d.Time, Feed: d.Feed, Kind: kind.kind, ID: id})
+		}
+	}
+	return changes
+}
+
+// AppendJournal appends the changes of diffs to the change journal, one JSON object per line (JSONL);
+// the journal is created if it doesn't exist.
+func AppendJournal(name string, diffs ...Diff) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, d := range diffs {
+		for _, c := range d.Changes() {
+			if err = enc.Encode(c); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal reads the entries of the change journal recorded after since, zero since reads all of them.
+func ReadJournal(r io.Reader, since time.Time) ([]Change, error) {
+	var changes []Change
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var c Change
+		if err := dec.Decode(&c); err == io.EOF {
+			return changes, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("malformed change journal entry %d: %v", line, err)
+		}
+		if c.Time.After(since) {
+			changes = append(changes, c)
+		}
+	}
+}
+
+// objectVersion identifies the version of an object in the data: its last modification date and
+// whether it's withdrawn.
+type objectVersion struct {
+	lastModified string
+	withdrawn    bool
+}
+
+// diffVersions returns the difference between the previous and the new versions of objects indexed by their
+// identifiers. Objects missing from the new versions are withdrawn if it's a complete snapshot of the data,
+// they're left unchanged otherwise (e.g. by incremental syncs or feeds of recent changes).
+func diffVersions(feed string, now time.Time, prev, next map[string]objectVersion, snapshot bool) Diff {
+	d := Diff{Feed: feed, Time: now}
+	for id, nv := range next {
+		pv, ok := prev[id]
+		switch {
+		case nv.withdrawn:
+			if !ok || !pv.withdrawn {
+				d.Withdrawn = append(d.Withdrawn, id)
+			}
+		case !ok:
+			d.Added = append(d.Added, id)
+		case pv != nv:
+			d.Modified = append(d.Modified, id)
+		}
+	}
+	if snapshot {
+		for id, pv := range prev {
+			if _, ok := next[id]; !ok && !pv.withdrawn {
+				d.Withdrawn = append(d.Withdrawn, id)
+			}
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Modified)
+	sort.Strings(d.Withdrawn)
+	return d
+}
@@ -0,0 +1,213 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datafeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+func TestDiffVersions(t *testing.T) {
+	prev := map[string]objectVersion{
+		"CVE-1": {lastModified: "1"},
+		"CVE-2": {lastModified: "1"},
+		"CVE-3": {lastModified: "1"},
+		"CVE-4": {lastModified: "1", withdrawn: true},
+	}
+	next := map[string]objectVersion{
+		"CVE-1": {lastModified: "1"},
+		"CVE-2": {lastModified: "2"},
+		"CVE-4": {lastModified: "1", withdrawn: true},
+		"CVE-5": {lastModified: "2"},
+		"CVE-6": {lastModified: "2", withdrawn: true},
+	}
+	now := time.Now()
+	cases := []struct {
+		snapshot bool
+		want     Diff
+	}{
+		{false, Diff{Feed: "feed", Time: now, Added: []string{"CVE-5"}, Modified: []string{"CVE-2"}, Withdrawn: []string{"CVE-6"}}},
+		{true, Diff{Feed: "feed", Time: now, Added: []string{"CVE-5"}, Modified: []string{"CVE-2"}, Withdrawn: []string{"CVE-3", "CVE-6"}}},
+	}
+	for _, c := range cases {
+		if d := diffVersions("feed", now, prev, next, c.snapshot); !reflect.DeepEqual(d, c.want) {
+			t.Errorf("snapshot %t: expected %+v, got %+v", c.snapshot, c.want, d)
+		}
+	}
+	if d := diffVersions("feed", now, next, next, true); !d.Empty() {
+		t.Errorf("expected no changes, got %+v", d)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	d, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	journal := filepath.Join(d, "journal.jsonl")
+	first := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	diffs := []Diff{
+		{Feed: "a", Time: first, Added: []string{"CVE-1"}, Withdrawn: []string{"CVE-2"}},
+		{Feed: "b", Time: second, Modified: []string{"CVE-3"}},
+	}
+	for _, diff := range diffs {
+		if err = AppendJournal(journal, diff); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	changes, err := ReadJournal(f, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{Time: second, Feed: "b", Kind: Modified, ID: "CVE-3"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("expected %+v, got %+v", want, changes)
+	}
+	if _, err = ReadJournal(bytes.NewBufferString("{}\nnot json\n"), time.Time{}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCVEFileVersions(t *testing.T) {
+	d, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	feed := `{"CVE_Items":[
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2018-0001"},"description":{"description_data":[{"value":"bug"}]}},"lastModifiedDate":"2018-01-01T00:00Z"},
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2018-0002"},"description":{"description_data":[{"value":"** REJECT ** duplicate"}]}},"lastModifiedDate":"2018-01-02T00:00Z"}
+	]}`
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte(feed))
+	w.Close()
+	name := filepath.Join(d, "nvdcve-1.0-2018.json.gz")
+	if err = ioutil.WriteFile(name, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cf := cveFile{CVE: cve10jsonGz, DataFile: "nvdcve-1.0-2018.json.gz", Snapshot: true}
+	versions, err := cf.versions(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]objectVersion{
+		"CVE-2018-0001": {lastModified: "2018-01-01T00:00Z"},
+		"CVE-2018-0002": {lastModified: "2018-01-02T00:00Z", withdrawn: true},
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("expected %+v, got %+v", want, versions)
+	}
+
+	diff, err := cf.diff(filepath.Join(d, "missing.json.gz"), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diff.Added, []string{"CVE-2018-0001"}) || !reflect.DeepEqual(diff.Withdrawn, []string{"CVE-2018-0002"}) {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+}
+
+func TestAPISyncDiff(t *testing.T) {
+	defer func(size int, delay time.Duration, c *client.Client) {
+		apiPageSize[cveAPI20], apiDelayWithKey, httpClient = size, delay, c
+	}(apiPageSize[cveAPI20], apiDelayWithKey, httpClient)
+	cfg := HTTPConfig()
+	cfg.Backoff = 0
+	apiPageSize[cveAPI20], apiDelayWithKey, httpClient = 2, 0, client.MustNew(cfg)
+
+	past := time.Now().Add(-time.Hour)
+	s := &apiTestServer{t: t, mods: map[string]time.Time{
+		"CVE-2023-0001": past,
+		"CVE-2023-0002": past,
+	}}
+	ts, src := httptestNewServer(s)
+	defer ts.Close()
+	src.APIKey = "secret"
+
+	d, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	var diffs []Diff
+	sync := Sync{
+		Feeds:    []Syncer{cveAPI20},
+		Source:   &src,
+		LocalDir: d,
+		Journal:  filepath.Join(d, "journal.jsonl"),
+		OnDiff:   func(d Diff) { diffs = append(diffs, d) },
+	}
+
+	// full sync adds everything
+	if err = sync.Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// incremental sync picks up the modified and the new CVE
+	if err = ioutil.WriteFile(filepath.Join(d, "nvdcve-api-2.0.state"), []byte(past.Add(time.Minute).Format(time.RFC3339)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.mods["CVE-2023-0002"] = time.Now()
+	s.mods["CVE-2023-0003"] = time.Now()
+	if err = sync.Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d", len(diffs))
+	}
+	if want := []string{"CVE-2023-0001", "CVE-2023-0002"}; !reflect.DeepEqual(diffs[0].Added, want) {
+		t.Errorf("full sync: expected added %v, got %+v", want, diffs[0])
+	}
+	if want := []string{"CVE-2023-0003"}; !reflect.DeepEqual(diffs[1].Added, want) {
+		t.Errorf("incremental sync: expected added %v, got %+v", want, diffs[1])
+	}
+	if want := []string{"CVE-2023-0002"}; !reflect.DeepEqual(diffs[1].Modified, want) {
+		t.Errorf("incremental sync: expected modified %v, got %+v", want, diffs[1])
+	}
+
+	f, err := os.Open(sync.Journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	changes, err := ReadJournal(f, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("expected 4 journal entries, got %+v", changes)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datafeed
+
+import (
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+)
+
+// metrics of the syncs; HTTP requests are counted by the client, see providers/lib/client
+var (
+	feedsSynced = metrics.NewCounter("nvdsync_feeds_synced_total",
+		"Feeds (or APIs) synced, by result: ok or error.", "result")
+	filesUpdated = metrics.NewCounter("nvdsync_files_updated_total",
+		"Local files replaced with newer data of the feeds.")
+	changesSynced = metrics.NewCounter("nvdsync_changes_total",
+		"CVEs (CPEs) added, modified and withdrawn by the syncs; only counted when the changes are tracked, see Sync.", "kind")
+	lastSuccess = metrics.NewGauge("nvdsync_last_success_timestamp_seconds",
+		"Time all the feeds were last synced successfully, in Unix seconds.")
+)
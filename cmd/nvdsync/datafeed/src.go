@@ -26,6 +26,10 @@ type SourceConfig struct {
 	Host        string `envconfig:"NVDSYNC_HOST" default:"nvd.nist.gov"`
 	CVEFeedPath string `envconfig:"NVDSYNC_CVE_FEED_PATH" default:"/feeds/{{.Encoding}}/cve/{{.Version}}/"`
 	CPEFeedPath string `envconfig:"NVDSYNC_CPE_FEED_PATH" default:"/feeds/xml/cpe/dictionary/"`
+	APIHost     string `envconfig:"NVDSYNC_API_HOST" default:"services.nvd.nist.gov"`
+	CVEAPIPath  string `envconfig:"NVDSYNC_CVE_API_PATH" default:"/rest/json/cves/2.0"`
+	CPEAPIPath  string `envconfig:"NVDSYNC_CPE_API_PATH" default:"/rest/json/cpes/2.0"`
+	APIKey      string `envconfig:"NVDSYNC_API_KEY" default:""`
 }
 
 // NewSourceConfig creates and initializes a new SourceConfig with values from envconfig.
@@ -57,4 +61,8 @@ func (src *SourceConfig) AddFlags(fs *flag.FlagSet) {
 	flag.StringVar(&src.Host, "src_host", src.Host, "source host\nenv: NVDSYNC_HOST")
 	flag.StringVar(&src.CVEFeedPath, "src_cve_feed_path", src.CVEFeedPath, "source path for CVE feeds\nenv: NVDSYNC_CVE_FEED_PATH")
 	flag.StringVar(&src.CPEFeedPath, "src_cpe_feed_path", src.CPEFeedPath, "source path for CPE feeds\nenv: NVDSYNC_CPE_FEED_PATH")
+	flag.StringVar(&src.APIHost, "src_api_host", src.APIHost, "source host of NVD API\nenv: NVDSYNC_API_HOST")
+	flag.StringVar(&src.CVEAPIPath, "src_cve_api_path", src.CVEAPIPath, "source path of NVD CVE API\nenv: NVDSYNC_CVE_API_PATH")
+	flag.StringVar(&src.CPEAPIPath, "src_cpe_api_path", src.CPEAPIPath, "source path of NVD CPE API\nenv: NVDSYNC_CPE_API_PATH")
+	flag.StringVar(&src.APIKey, "api_key", src.APIKey, "NVD API key, raises the rate limit of API requests\nenv: NVDSYNC_API_KEY")
 }
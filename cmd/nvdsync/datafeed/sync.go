@@ -17,6 +17,7 @@ package datafeed
 import (
 	"context"
 	"os"
+	"time"
 )
 
 // Syncer is an abstract interface for data feed synchronizers.
@@ -29,6 +30,12 @@ type Sync struct {
 	Feeds    []Syncer
 	Source   *SourceConfig
 	LocalDir string
+	// Journal is the change journal the changes of the feeds implementing DiffSyncer are appended to,
+	// see AppendJournal; changes aren't recorded if it's empty
+	Journal string
+	// OnDiff is called with the changes of every local file synced by the feeds implementing DiffSyncer; may be nil.
+	// The changes are tracked, and counted by the metrics, only if Journal or OnDiff is set.
+	OnDiff func(Diff)
 }
 
 // Do executes the synchronization.
@@ -43,9 +50,37 @@ func (s Sync) Do(ctx context.Context) error {
 	}
 	vsrc := *src
 	for _, feed := range s.Feeds {
-		if err = feed.Sync(ctx, vsrc, s.LocalDir); err != nil {
+		ds, ok := feed.(DiffSyncer)
+		if !ok || (s.Journal == "" && s.OnDiff == nil) {
+			if err = feed.Sync(ctx, vsrc, s.LocalDir); err != nil {
+				feedsSynced.Inc("error")
+				return err
+			}
+			feedsSynced.Inc("ok")
+			continue
+		}
+		diffs, err := ds.SyncDiff(ctx, vsrc, s.LocalDir)
+		if err != nil {
+			feedsSynced.Inc("error")
 			return err
 		}
+		feedsSynced.Inc("ok")
+		for _, d := range diffs {
+			changesSynced.Add(float64(len(d.Added)), string(Added))
+			changesSynced.Add(float64(len(d.Modified)), string(Modified))
+			changesSynced.Add(float64(len(d.Withdrawn)), string(Withdrawn))
+		}
+		if s.Journal != "" {
+			if err = AppendJournal(s.Journal, diffs...); err != nil {
+				return err
+			}
+		}
+		if s.OnDiff != nil {
+			for _, d := range diffs {
+				s.OnDiff(d)
+			}
+		}
 	}
+	lastSuccess.Set(float64(time.Now().Unix()))
 	return nil
 }
@@ -23,6 +23,8 @@ import (
 	"time"
 
 	"github.com/facebookincubator/nvdtools/cmd/nvdsync/datafeed"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/golang/glog"
 )
 
@@ -34,15 +36,22 @@ func main() {
 	var (
 		cvefeed datafeed.CVE
 		cpefeed datafeed.CPE
+		apis    datafeed.APIs
 		timeout time.Duration
 		source  = datafeed.NewSourceConfig()
 	)
 
 	flag.Var(&cvefeed, "cve_feed", cvefeed.Help())
 	flag.Var(&cpefeed, "cpe_feed", cpefeed.Help())
+	flag.Var(&apis, "api", apis.Help())
 	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "sync timeout")
+	journal := flag.String("journal", "", "append the added, modified and withdrawn CVEs (CPEs) of JSON feeds and APIs to this change journal (JSONL)")
 	ua := flag.String("user_agent", datafeed.UserAgent(), "HTTP request User-Agent header")
 	source.AddFlags(flag.CommandLine)
+	httpConfig := datafeed.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
 
 	flag.Usage = func() {
 		fmt.Printf("nvdsync %s\n\n", datafeed.Version)
@@ -67,16 +76,41 @@ func main() {
 	}
 	glog.Infof("Using http User-Agent: %s", datafeed.UserAgent())
 
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	datafeed.SetHTTPClient(httpClient)
+
+	feeds := []datafeed.Syncer{cvefeed, cpefeed}
+	if len(apis) != 0 {
+		feeds = apis.Syncers()
+	}
+
 	dfs := datafeed.Sync{
-		Feeds:    []datafeed.Syncer{cvefeed, cpefeed},
+		Feeds:    feeds,
 		Source:   source,
 		LocalDir: localdir,
+		Journal:  *journal,
+	}
+	if metricsConfig.Enabled() {
+		dfs.OnDiff = func(datafeed.Diff) {} // track the changes, so they're counted
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	if err := dfs.Do(ctx); err != nil {
+	// metrics are written even if the sync fails, e.g. for alerts on the errors
+	err = dfs.Do(ctx)
+	if merr := stopMetrics(); merr != nil {
+		glog.Errorf("could not write metrics: %v", merr)
+	}
+	if err != nil {
 		glog.Fatal(err)
 	}
 }
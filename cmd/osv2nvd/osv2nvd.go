@@ -0,0 +1,152 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/facebookincubator/nvdtools/providers/osv/api"
+	"github.com/facebookincubator/nvdtools/providers/osv/converter"
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+func main() {
+	ecosystems := flag.String("ecosystems", "", "comma separated list of OSV ecosystems to download the exports of, e.g. PyPI,Go,npm,crates.io")
+	archive := flag.String("archive", "", "path to an export downloaded before, e.g. PyPI/all.zip, instead of downloading")
+	purls := flag.String("purls", "", "comma separated list of package URLs to query the vulnerabilities of with the batch API, e.g. pkg:pypi/django@3.2.0")
+	dataURL := flag.String("data_url", api.DataURL, "where the exports of ecosystems are published")
+	apiURL := flag.String("api_url", api.APIURL, "OSV API endpoint")
+	timeout := flag.Duration("timeout", time.Hour, "timeout of the download")
+	dontConvert := flag.Bool("dont_convert", false, "Should the feed be converted to NVD format or not")
+	httpConfig := api.HTTPConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	var metricsConfig metrics.Config
+	metricsConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	stopMetrics, err := metricsConfig.Start()
+	if err != nil {
+		logging.Fatal(err)
+	}
+	defer func() {
+		if err := stopMetrics(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
+	if *ecosystems == "" && *archive == "" && *purls == "" {
+		logging.Errorf("one of -ecosystems, -archive and -purls is required")
+		flag.Usage()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		logging.Fatal(err)
+	}
+
+	client := api.NewClient()
+	client.DataURL, client.APIURL = *dataURL, *apiURL
+	client.HTTP = httpClient
+
+	vulns := make(chan *schema.Vulnerability)
+	go func() {
+		defer close(vulns)
+		if err := fetch(ctx, client, *ecosystems, *archive, *purls, vulns); err != nil {
+			logging.Fatal(err)
+		}
+	}()
+
+	if *dontConvert {
+		var output []*schema.Vulnerability
+		for vuln := range vulns {
+			output = append(output, vuln)
+		}
+		writeOutput(output)
+	} else {
+		writeOutput(converter.Convert(vulns))
+	}
+}
+
+// fetch sends the records of all the sources to output
+func fetch(ctx context.Context, client *api.Client, ecosystems, archive, purls string, output chan<- *schema.Vulnerability) error {
+	forward := func(vulns <-chan *schema.Vulnerability) {
+		for vuln := range vulns {
+			output <- vuln
+		}
+	}
+	if archive != "" {
+		vulns, err := api.ReadArchive(archive)
+		if err != nil {
+			return err
+		}
+		forward(vulns)
+	}
+	for _, ecosystem := range split(ecosystems) {
+		logging.Infof("downloading %s export", ecosystem)
+		vulns, err := client.FetchEcosystem(ctx, ecosystem)
+		if err != nil {
+			return err
+		}
+		forward(vulns)
+	}
+	if purls := split(purls); len(purls) != 0 {
+		queries := make([]*schema.Query, len(purls))
+		for i, p := range purls {
+			queries[i] = &schema.Query{Package: &schema.Package{Purl: p}}
+		}
+		vulns, err := client.QueryBatch(ctx, queries)
+		if err != nil {
+			return err
+		}
+		for _, vuln := range vulns {
+			output <- vuln
+		}
+	}
+	return nil
+}
+
+func split(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func writeOutput(output interface{}) {
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		logging.Fatal(err)
+	}
+}
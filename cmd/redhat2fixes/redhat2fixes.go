@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/redhat"
+
+	"github.com/golang/glog"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", 5*time.Minute, "download timeout, per URL")
+	httpConfig := client.DefaultConfig()
+	httpConfig.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Println("Usage: redhat2fixes [flags] vex.json|oval.xml[.bz2]|URL... > redhat.fixes.csv")
+		fmt.Println("Converts Red Hat CSAF VEX documents and OVAL v2 definitions to distribution fixes CSV (CVE,package,release,status)")
+		fmt.Println("for -distro_fixes flag of cpe2cve, e.g.")
+		fmt.Println("  https://security.access.redhat.com/data/oval/v2/RHEL8/rhel-8-including-unpatched.oval.xml.bz2")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+
+	httpClient, err := client.New(httpConfig)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	fixes := make(cvefeed.DistroFixes)
+	for _, src := range flag.Args() {
+		var fs cvefeed.DistroFixes
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			fs, err = redhat.Fetch(ctx, httpClient, src)
+			cancel()
+		} else {
+			fs, err = redhat.Load(src)
+		}
+		if err != nil {
+			glog.Fatal(err)
+		}
+		glog.V(1).Infof("%s: fixes of %d CVEs", src, len(fs))
+		redhat.Merge(fixes, fs)
+	}
+
+	if err := fixes.Write(os.Stdout); err != nil {
+		glog.Fatal(err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	_ "github.com/lib/pq" // register postgres driver
+
+	"github.com/facebookincubator/nvdtools/vulndb/mysql"
+	"github.com/facebookincubator/nvdtools/vulndb/postgres"
+	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
+)
+
+// openDB opens a connection to postgres if its dsn is set, or to mysql
+// otherwise, for commands supporting both. Either flag must be set.
+func openDB(write bool) (*sql.DB, sqlutil.Dialect, error) {
+	switch {
+	case gFlagPostgres != "":
+		open := postgres.OpenRead
+		if write {
+			open = postgres.OpenWrite
+		}
+		db, err := open(gFlagPostgres)
+		return db, sqlutil.Postgres, err
+	case gFlagMySQL != "":
+		open := mysql.OpenRead
+		if write {
+			open = mysql.OpenWrite
+		}
+		db, err := open(gFlagMySQL)
+		return db, sqlutil.MySQL, err
+	default:
+		return nil, sqlutil.MySQL, errors.New("either --mysql or --postgres must be set")
+	}
+}
@@ -23,12 +23,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/facebookincubator/nvdtools/vulndb"
-	"github.com/facebookincubator/nvdtools/vulndb/mysql"
 )
 
 func init() {
-	addRequiredFlags(exportCmd, "mysql", "format")
-	addOptionalFlags(exportCmd, "provider", "csv_noheader")
+	addRequiredFlags(exportCmd, "format")
+	addOptionalFlags(exportCmd, "mysql", "postgres", "provider", "csv_noheader")
 	RootCmd.AddCommand(exportCmd)
 }
 
@@ -42,11 +41,13 @@ The --format flag is required and must be one of csv or nvdjson.
 
 The --provider flag is optional, as well as the list of IDs to filter in.
 
+Either --mysql or --postgres is required.
+
 Use the JSON_INDENT environment variable to set the indentation character
 for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		db, err := mysql.OpenRead(gFlagMySQL)
+		db, dialect, err := openDB(false)
 		if err != nil {
 			log.Fatalln("cannot open db:", err)
 		}
@@ -59,6 +60,7 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 
 		exp := vulndb.DataExporter{
 			DB:              db,
+			Dialect:         dialect,
 			FilterProviders: providers,
 			FilterCVEs:      args,
 		}
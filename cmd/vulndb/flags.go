@@ -33,6 +33,9 @@ var (
 	// And libfb/go/fbmysql for fbmysql DSN.
 	gFlagMySQL = os.Getenv("MYSQL")
 
+	// See https://pkg.go.dev/github.com/lib/pq#hdr-Connection_String_Parameters for DSN.
+	gFlagPostgres = os.Getenv("POSTGRES")
+
 	// General purpose flags.
 
 	gFlagOwner       = os.Getenv("USER")
@@ -43,6 +46,8 @@ var (
 	gFlagDeadline    deadlineFlag
 	gFlagDeleteAll   bool
 	gFlagCSVNoHeader = false
+	gFlagAllowEmpty  = false
+	gFlagOlderThan   = 30 * 24 * time.Hour
 )
 
 func init() {
@@ -80,6 +85,12 @@ var supportedFlags = map[string]func(*pflag.FlagSet){
 			fs.Set("mysql", gFlagMySQL)
 		}
 	},
+	"postgres": func(fs *pflag.FlagSet) {
+		fs.StringVar(&gFlagPostgres, "postgres", gFlagPostgres, "set postgres dsn (or use $POSTGRES), takes precedence over mysql")
+		if gFlagPostgres != "" {
+			fs.Set("postgres", gFlagPostgres)
+		}
+	},
 	"owner": func(fs *pflag.FlagSet) {
 		fs.StringVar(&gFlagOwner, "owner", gFlagOwner, "set owner of the records")
 		fs.Set("owner", gFlagOwner)
@@ -106,6 +117,12 @@ var supportedFlags = map[string]func(*pflag.FlagSet){
 	"csv_noheader": func(fs *pflag.FlagSet) {
 		fs.BoolVarP(&gFlagCSVNoHeader, "csvnoheader", "n", gFlagCSVNoHeader, "omit csv header in output")
 	},
+	"allow_empty": func(fs *pflag.FlagSet) {
+		fs.BoolVar(&gFlagAllowEmpty, "allow_empty", gFlagAllowEmpty, "allow syncing no records, withdrawing all records of the provider")
+	},
+	"older_than": func(fs *pflag.FlagSet) {
+		fs.DurationVar(&gFlagOlderThan, "older_than", gFlagOlderThan, "set minimum age of the withdrawal of records to delete")
+	},
 }
 
 // deadlineFlag implements the pflag.Value interface.
@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/facebookincubator/nvdtools/vulndb"
+)
+
+func init() {
+	addOptionalFlags(migrateCmd, "mysql", "postgres")
+	RootCmd.AddCommand(migrateCmd)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [flags]",
+	Short: "migrate the database schema to the latest version",
+	Long: `
+The migrate command applies the schema migrations the database lacks, in
+order, each in its own transaction. Databases created from the output of the
+schema command can be migrated too; migrating a database twice is a no-op.
+
+Either --mysql or --postgres is required.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		db, dialect, err := openDB(true)
+		if err != nil {
+			log.Fatalln("cannot open db:", err)
+		}
+		defer db.Close()
+
+		ctx := context.Background()
+		applied, err := vulndb.Migrate(ctx, db, dialect)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		version, err := vulndb.SchemaVersion(ctx, db, dialect)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		log.Printf("applied %d migrations, schema at version %d", applied, version)
+	},
+}
@@ -19,6 +19,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -130,6 +131,100 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 	},
 }
 
+func init() {
+	addRequiredFlags(vendorSyncCmd, "owner", "provider")
+	addOptionalFlags(vendorSyncCmd, "mysql", "postgres", "allow_empty")
+	vendorCmd.AddCommand(vendorSyncCmd)
+}
+
+var vendorSyncCmd = &cobra.Command{
+	Use:   "sync [flags] [file.json[.gz] ...]",
+	Short: "sync vulnerability data in the database with a vendor",
+	Long: `
+The sync command keeps vulnerability data of a provider in sync with the
+files formatted as NVD JSON 1.0, which must hold the full dataset of the
+provider.
+
+Unlike import, records are not versioned: CVEs are upserted, keyed on the
+CVE ID and the provider, and CVEs of the provider missing from the files
+are marked withdrawn and no longer exported. The sync is a single
+transaction. Syncing no records fails, unless --allow_empty is set.
+
+Either --mysql or --postgres is required, the database must be migrated.
+
+File schema: https://csrc.nist.gov/schema/nvd/feed/1.0/nvd_cve_feed_json_1.0.schema
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !gFlagAllowEmpty {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		db, dialect, err := openDB(true)
+		if err != nil {
+			log.Fatalln("cannot open db:", err)
+		}
+		defer db.Close()
+
+		syn := vulndb.VendorDataSyncer{
+			DB:         db,
+			Dialect:    dialect,
+			Owner:      gFlagOwner,
+			Provider:   gFlagProvider,
+			AllowEmpty: gFlagAllowEmpty,
+			OnFile: func(name string) {
+				log.Println("syncing", name)
+			},
+		}
+
+		ctx := context.Background()
+		stats, err := syn.SyncFiles(ctx, args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("synced %s: %d records, %d withdrawn", gFlagProvider, stats.Synced, stats.Withdrawn)
+	},
+}
+
+func init() {
+	addRequiredFlags(vendorPurgeCmd, "provider")
+	addOptionalFlags(vendorPurgeCmd, "mysql", "postgres", "older_than")
+	vendorCmd.AddCommand(vendorPurgeCmd)
+}
+
+var vendorPurgeCmd = &cobra.Command{
+	Use:   "purge [flags]",
+	Short: "delete withdrawn vulnerability data of a vendor",
+	Long: `
+The purge command deletes the records of a provider the sync command marked
+withdrawn longer than --older_than ago (30 days by default).
+
+Either --mysql or --postgres is required.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		db, dialect, err := openDB(true)
+		if err != nil {
+			log.Fatalln("cannot open db:", err)
+		}
+		defer db.Close()
+
+		syn := vulndb.VendorDataSyncer{
+			DB:       db,
+			Dialect:  dialect,
+			Provider: gFlagProvider,
+		}
+
+		ctx := context.Background()
+		n, err := syn.PurgeWithdrawn(ctx, time.Now().Add(-gFlagOlderThan))
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		log.Printf("purged %s: %d records", gFlagProvider, n)
+	},
+}
+
 func init() {
 	addRequiredFlags(vendorTrimCmd, "mysql", "delete_all")
 	addOptionalFlags(vendorTrimCmd, "provider")
@@ -0,0 +1,171 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+)
+
+var progname = path.Base(os.Args[0])
+
+type config struct {
+	format      string
+	tables      string
+	outFieldSep string
+	table       *cpeparse.WindowsTable
+}
+
+func (c *config) addFlags() {
+	flag.StringVar(&c.format, "format", "auto", "format of the input: registry (CSV export of the Uninstall keys), winget (JSON) or auto to detect it")
+	flag.StringVar(&c.tables, "table", "", "comma-separated list of CSV files of kind,pattern,vendor,product rules normalizing publishers, display names\n"+
+		"and winget package identifiers, applied before the built-in ones; kind is publisher, name or id")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s reads the installed programs of Windows, either the CSV export of the Uninstall keys of the registry\n" +
+			"%[2]s (Get-ItemProperty ...\\Uninstall\\* | Export-Csv) or the JSON of winget export or Get-WinGetPackage, of the files\n" +
+			"%[2]s or of stdin, and produces delimiter-separated output consisting of CPE name, source, display name (or package\n" +
+			"%[2]s identifier), publisher, version and architecture of every program, which can be processed by cpe2cve.\n" +
+			"usage: %[1]s [flags] [file...]\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// loadTable returns the built-in table extended with the rules of the files
+func loadTable(paths string) (*cpeparse.WindowsTable, error) {
+	table := cpeparse.DefaultWindowsTable()
+	if paths == "" {
+		return table, nil
+	}
+	files := strings.Split(paths, ",")
+	for i := len(files) - 1; i >= 0; i-- { // the rules of the first files apply first
+		f, err := os.Open(files[i])
+		if err != nil {
+			return nil, err
+		}
+		t, err := cpeparse.ParseWindowsTable(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", files[i], err)
+		}
+		table = t.Extend(table)
+	}
+	return table, nil
+}
+
+// processProgram returns the output record of the program, nil if its CPE name couldn't be parsed
+func processProgram(p cpeparse.WindowsProgram, cfg config) []string {
+	attr, err := cfg.table.CPE(p)
+	if err != nil {
+		sayErr(0, "%v", err)
+		return nil
+	}
+	name := p.Name
+	if name == "" {
+		name = p.ID
+	}
+	return []string{attr.BindToURI(), p.Source, name, p.Publisher, p.Version, p.Architecture}
+}
+
+func win2cpe(in io.Reader, out io.Writer, cfg config) error {
+	parse := cpeparse.ParseWindowsPrograms
+	switch cfg.format {
+	case "registry":
+		parse = cpeparse.ParseWindowsRegistryCSV
+	case "winget":
+		parse = cpeparse.ParseWingetJSON
+	}
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	err := parse(in, func(p cpeparse.WindowsProgram) error {
+		if rec := processProgram(p, cfg); rec != nil {
+			return w.Write(rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	switch cfg.format {
+	case "auto", "registry", "winget":
+	default:
+		sayErr(0, "unknown format %q", cfg.format)
+		flag.Usage()
+	}
+	if cfg.outFieldSep == "" {
+		sayErr(0, "empty output column delimiter")
+		flag.Usage()
+	}
+	var err error
+	if cfg.table, err = loadTable(cfg.tables); err != nil {
+		sayErr(-1, "%v", err)
+	}
+	if flag.NArg() == 0 {
+		if err = win2cpe(os.Stdin, os.Stdout, cfg); err != nil {
+			sayErr(-1, "%v", err)
+		}
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			sayErr(-1, "%v", err)
+		}
+		err = win2cpe(f, os.Stdout, cfg)
+		f.Close()
+		if err != nil {
+			sayErr(-1, "%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+)
+
+func TestWin2CPE(t *testing.T) {
+	in := `[{"Name":"Mozilla Firefox (x64 en-US)","Id":"Mozilla.Firefox","InstalledVersion":"118.0.1","Source":"winget"},` +
+		`{"Name":"Acme Widget","Id":"Acme.Widget","InstalledVersion":"Unknown","Source":"winget"}]`
+	cfg := config{format: "auto", outFieldSep: "\t", table: cpeparse.DefaultWindowsTable()}
+	var out bytes.Buffer
+	if err := win2cpe(strings.NewReader(in), &out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := "cpe:/a:mozilla:firefox:118.0.1::~~~windows~x64~\twinget\tMozilla Firefox (x64 en-US)\t\t118.0.1\t\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+	cfg.format = "registry"
+	if err := win2cpe(strings.NewReader(in), &out, cfg); err == nil {
+		t.Error("winget JSON parsed as registry export: unexpected success")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// apiProduct is a product of NVD CPE API 2.0 response, see https://nvd.nist.gov/developers/products
+type apiProduct struct {
+	CPE struct {
+		Deprecated   bool   `json:"deprecated"`
+		CPEName      string `json:"cpeName"`
+		CPENameID    string `json:"cpeNameId"`
+		LastModified string `json:"lastModified"`
+		Titles       []struct {
+			Title string `json:"title"`
+			Lang  string `json:"lang"`
+		} `json:"titles"`
+		Refs []struct {
+			Ref  string `json:"ref"`
+			Type string `json:"type"`
+		} `json:"refs"`
+		DeprecatedBy []struct {
+			CPEName string `json:"cpeName"`
+		} `json:"deprecatedBy"`
+	} `json:"cpe"`
+}
+
+// apiTimeLayout is the layout of the timestamps of NVD API 2.0
+const apiTimeLayout = "2006-01-02T15:04:05.000"
+
+// DecodeAPIStream is like DecodeStream, but decodes NVD CPE API 2.0 response (e.g. the mirror of nvdsync -api cpe-api-2.0)
+// product by product, converting products into dictionary items: titles, references and the names deprecating
+// the product are kept. The generator is made of the format, version and timestamp of the response.
+func DecodeAPIStream(r io.Reader, fn func(*CPEItem) error) (*Generator, error) {
+	d := json.NewDecoder(r)
+	if err := expectDelim(d, '{'); err != nil {
+		return nil, err
+	}
+	generator := &Generator{}
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key := tok.(string); key {
+		case "format":
+			err = d.Decode(&generator.ProductName)
+		case "version":
+			err = d.Decode(&generator.SchemaVersion)
+		case "timestamp":
+			var ts string
+			if err = d.Decode(&ts); err == nil {
+				generator.TimeStamp, _ = time.Parse(apiTimeLayout, ts) // informational only
+			}
+		case "products":
+			err = decodeAPIProducts(d, fn)
+		default:
+			var skip json.RawMessage
+			err = d.Decode(&skip)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return generator, expectDelim(d, '}')
+}
+
+func decodeAPIProducts(d *json.Decoder, fn func(*CPEItem) error) error {
+	if err := expectDelim(d, '['); err != nil {
+		return err
+	}
+	for d.More() {
+		var product apiProduct
+		if err := d.Decode(&product); err != nil {
+			return fmt.Errorf("decode product: %v", err)
+		}
+		item, err := product.item()
+		if err != nil {
+			return err
+		}
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+	return expectDelim(d, ']')
+}
+
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("malformed CPE API response: expected %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// item converts the product into dictionary item
+func (p *apiProduct) item() (*CPEItem, error) {
+	name, err := wfn.UnbindFmtString(p.CPE.CPEName)
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %v", p.CPE.CPENameID, err)
+	}
+	item := &CPEItem{
+		Name:       NamePattern(*name),
+		Deprecated: p.CPE.Deprecated,
+		CPE23:      CPE23Item{Name: NamePattern(*name)},
+	}
+	for _, title := range p.CPE.Titles {
+		if item.Title == nil {
+			item.Title = TextType{}
+		}
+		item.Title[title.Lang] = title.Title
+	}
+	for _, ref := range p.CPE.Refs {
+		item.References = append(item.References, Reference{URL: ref.Ref, Desc: ref.Type})
+	}
+	if p.CPE.Deprecated {
+		item.CPE23.Deprecation = &Deprecation{}
+		item.DeprecationDate, _ = time.Parse(apiTimeLayout, p.CPE.LastModified) // the closest the API has
+		item.CPE23.Deprecation.Date = item.DeprecationDate
+		for _, by := range p.CPE.DeprecatedBy {
+			byName, err := wfn.UnbindFmtString(by.CPEName)
+			if err != nil {
+				return nil, fmt.Errorf("product %s: deprecated by: %v", p.CPE.CPENameID, err)
+			}
+			item.CPE23.Deprecation.DeprecatedBy = append(item.CPE23.Deprecation.DeprecatedBy, DeprecatedInfo{Name: NamePattern(*byName)})
+		}
+	}
+	return item, nil
+}
+
+// decodeAny calls DecodeStream or DecodeAPIStream, as per the format of the dictionary: XML or JSON, plain or gzip'ed
+func decodeAny(r io.Reader, fn func(*CPEItem) error) (*Generator, error) {
+	br := bufio.NewReader(r)
+	if header, err := br.Peek(2); err == nil && header[0] == 0x1f && header[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+			continue
+		case '{':
+			return DecodeAPIStream(br, fn)
+		}
+		return DecodeStream(br, fn)
+	}
+}
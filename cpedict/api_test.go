@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testAPIResponse = `{
+  "resultsPerPage": 2,
+  "startIndex": 0,
+  "totalResults": 2,
+  "format": "NVD_CPE",
+  "version": "2.0",
+  "timestamp": "2023-06-01T12:00:00.000",
+  "products": [
+    {
+      "cpe": {
+        "deprecated": true,
+        "cpeName": "cpe:2.3:a:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*",
+        "cpeNameId": "3D6D8B23-0B4E-4A0E-8DCC-4F5E0B8C5A1A",
+        "lastModified": "2010-12-28T17:35:59.740",
+        "titles": [{"title": "3Com TippingPoint IPS TOS 2.1.3.6323", "lang": "en"}],
+        "deprecatedBy": [{"cpeName": "cpe:2.3:o:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*"}]
+      }
+    },
+    {
+      "cpe": {
+        "deprecated": false,
+        "cpeName": "cpe:2.3:o:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*",
+        "cpeNameId": "8B7B7A2E-1A4F-4B5E-9C7F-1E2D3C4B5A69",
+        "lastModified": "2010-12-28T17:35:59.740",
+        "titles": [{"title": "3Com TippingPoint IPS TOS 2.1.3.6323", "lang": "en"}, {"title": "3Com TippingPoint IPS TOS 2.1.3.6323 (ja)", "lang": "ja"}],
+        "refs": [{"ref": "https://example.com/tos", "type": "Vendor"}]
+      }
+    }
+  ]
+}`
+
+func TestDecodeAPIStream(t *testing.T) {
+	var items []*CPEItem
+	generator, err := DecodeAPIStream(strings.NewReader(testAPIResponse), func(item *CPEItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generator.ProductName != "NVD_CPE" || generator.SchemaVersion != "2.0" || generator.TimeStamp.Year() != 2023 {
+		t.Errorf("bad generator %+v", generator)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if !items[0].IsDeprecated() || len(items[0].CPE23.Deprecation.DeprecatedBy) != 1 {
+		t.Errorf("expected the first item to be deprecated, got %+v", items[0])
+	}
+	if len(items[1].References) != 1 || items[1].References[0].URL != "https://example.com/tos" {
+		t.Errorf("unexpected references %+v", items[1].References)
+	}
+
+	if _, err := DecodeAPIStream(strings.NewReader(`[]`), func(*CPEItem) error { return nil }); err == nil {
+		t.Error("expected an error for malformed response")
+	}
+}
+
+func TestIndexTitleAndReplacements(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testAPIResponse))
+	w.Close()
+
+	for name, dict := range map[string]string{"xml": testStreamDict, "api": testAPIResponse, "gzip": gz.String()} {
+		idx, err := LoadIndex(strings.NewReader(dict), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		deprecated, err := wfn.Parse("cpe:/a:3com:tippingpoint_ips_tos:2.1.3.6323")
+		if err != nil {
+			t.Fatal(err)
+		}
+		title, ok := idx.Title(NamePattern(*deprecated), "en")
+		if !ok || title != "3Com TippingPoint IPS TOS 2.1.3.6323" {
+			t.Errorf("%s: unexpected title %q", name, title)
+		}
+		replacements, ok := idx.Replacements(NamePattern(*deprecated))
+		if !ok || len(replacements) != 1 || replacements[0].Name.Part != "o" {
+			t.Errorf("%s: expected deprecated name to be replaced, got %+v", name, replacements)
+		}
+		if _, ok := idx.Replacements(replacements[0].Name); ok {
+			t.Errorf("%s: replacement isn't deprecated", name)
+		}
+	}
+}
+
+func TestProductVendors(t *testing.T) {
+	idx, err := LoadIndex(strings.NewReader(testStreamDict), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vendors := idx.ProductVendors()
+	if vs := vendors("tippingpoint_ips_tos"); len(vs) != 1 || vs[0] != "3com" {
+		t.Errorf("unexpected vendors %q", vs)
+	}
+	if vs := vendors("unknown"); len(vs) != 0 {
+		t.Errorf("unexpected vendors of unknown product %q", vs)
+	}
+	guesses := wfn.GuessCPE("Flex SDK 4.6", vendors)
+	if len(guesses) == 0 || guesses[0].Attributes.Vendor != "adobe" || guesses[0].Attributes.Product != "flex_sdk" {
+		t.Errorf("expected the dictionary to pick the vendor, got %+v", guesses)
+	}
+}
+
+func TestTitleIn(t *testing.T) {
+	item := CPEItem{Title: TextType{"ja": "ja title", "en-US": "English title"}}
+	for lang, want := range map[string]string{"ja": "ja title", "de": "English title"} {
+		if title := item.TitleIn(lang); title != want {
+			t.Errorf("%s: expected %q, got %q", lang, want, title)
+		}
+	}
+	item = CPEItem{Title: TextType{"ja": "ja title", "fr": "fr title"}}
+	if title := item.TitleIn("de"); title != "fr title" {
+		t.Errorf("expected the title of the first language, got %q", title)
+	}
+}
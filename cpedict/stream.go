@@ -0,0 +1,221 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// DecodeStream decodes dictionary XML item by item, calling fn for every cpe-item as soon as it's decoded,
+// so the memory footprint doesn't depend on the size of the dictionary, unlike with Decode.
+// Decoding stops at the first error returned by fn. The generator is returned if the dictionary has one.
+func DecodeStream(r io.Reader, fn func(*CPEItem) error) (*Generator, error) {
+	var generator *Generator
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return generator, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "generator":
+			generator = &Generator{}
+			if err := d.DecodeElement(generator, &start); err != nil {
+				return nil, fmt.Errorf("decode generator: %v", err)
+			}
+		case "cpe-item":
+			var item CPEItem
+			if err := d.DecodeElement(&item, &start); err != nil {
+				return nil, fmt.Errorf("decode cpe-item: %v", err)
+			}
+			if err := fn(&item); err != nil {
+				return nil, err
+			}
+		}
+		// other elements, e.g. cpe-list, are descended into
+	}
+}
+
+// Index holds dictionary items keyed by their CPE names for constant time lookups
+type Index struct {
+	Generator *Generator
+	items     map[wfn.Attributes]*CPEItem
+}
+
+// LoadIndex streams dictionary XML or NVD CPE API 2.0 response, plain or gzip'ed, into an Index
+// (see DecodeStream and DecodeAPIStream); only the items keep returns true for are indexed, nil keep indexes all of them
+func LoadIndex(r io.Reader, keep func(*CPEItem) bool) (*Index, error) {
+	idx := &Index{items: make(map[wfn.Attributes]*CPEItem)}
+	generator, err := decodeAny(r, func(item *CPEItem) error {
+		if keep == nil || keep(item) {
+			idx.Add(item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	idx.Generator = generator
+	return idx, nil
+}
+
+// Add adds the item to the index, replacing the item of the same name if there's one
+func (idx *Index) Add(item *CPEItem) {
+	if idx.items == nil {
+		idx.items = make(map[wfn.Attributes]*CPEItem)
+	}
+	idx.items[itemName(item)] = item
+}
+
+// Len returns the number of items in the index
+func (idx *Index) Len() int {
+	return len(idx.items)
+}
+
+// Get returns the item of exactly the given name
+func (idx *Index) Get(name NamePattern) (*CPEItem, bool) {
+	item, ok := idx.items[wfn.Attributes(name)]
+	return item, ok
+}
+
+// Lookup is like Get, but the name is parsed from its URI or formatted string binding first
+func (idx *Index) Lookup(name string) (*CPEItem, error) {
+	attrs, err := wfn.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := idx.Get(NamePattern(*attrs))
+	if !ok {
+		return nil, fmt.Errorf("%q not found in CPE dictionary", name)
+	}
+	return item, nil
+}
+
+// Current returns the item of the given name if it isn't deprecated, or the indexed items deprecating it otherwise;
+// deprecating names which aren't in the index are skipped. It returns nil if the name isn't in the index.
+func (idx *Index) Current(name NamePattern) []*CPEItem {
+	item, ok := idx.Get(name)
+	if !ok {
+		return nil
+	}
+	if !item.Deprecated || item.CPE23.Deprecation == nil {
+		return []*CPEItem{item}
+	}
+	var current []*CPEItem
+	for _, depBy := range item.CPE23.Deprecation.DeprecatedBy {
+		if i, ok := idx.Get(depBy.Name); ok {
+			current = append(current, i)
+		}
+	}
+	return current
+}
+
+// Title returns the title of the item of the given name, see CPEItem.TitleIn; ok is false if the name isn't in the index
+func (idx *Index) Title(name NamePattern, lang string) (title string, ok bool) {
+	item, ok := idx.Get(name)
+	if !ok {
+		return "", false
+	}
+	return item.TitleIn(lang), true
+}
+
+// Replacements tells whether the item of the given name is deprecated, along with the indexed items replacing it
+// (see Current); names which aren't in the index aren't deprecated
+func (idx *Index) Replacements(name NamePattern) (replacements []*CPEItem, deprecated bool) {
+	item, ok := idx.Get(name)
+	if !ok || !item.IsDeprecated() {
+		return nil, false
+	}
+	if item.CPE23.Deprecation == nil { // deprecated by CPE 2.2 dictionary only
+		if item.DeprecatedBy != nil {
+			if i, ok := idx.Get(*item.DeprecatedBy); ok {
+				return []*CPEItem{i}, true
+			}
+		}
+		return nil, true
+	}
+	return idx.Current(name), true
+}
+
+// ProductVendors returns the lookup of the vendors of the products of the indexed items which aren't deprecated,
+// to rate the CPE names guessed from free-form strings by, see wfn.GuessCPE. Items added later aren't looked up.
+func (idx *Index) ProductVendors() wfn.ProductVendors {
+	vendors := make(map[string][]string)
+	for name, item := range idx.items {
+		if item.IsDeprecated() || name.Vendor == wfn.Any {
+			continue
+		}
+		vendors[name.Product] = appendUnique(vendors[name.Product], name.Vendor)
+	}
+	for _, vs := range vendors {
+		sort.Strings(vs)
+	}
+	return func(product string) []string {
+		return vendors[product]
+	}
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// IsDeprecated tells whether the item is deprecated, by CPE 2.2 or CPE 2.3 dictionary
+func (item *CPEItem) IsDeprecated() bool {
+	return item.Deprecated || item.CPE23.Deprecation != nil
+}
+
+// TitleIn returns the title of the item in lang, falling back to English and then to any language,
+// e.g. the dictionary uses en-US; it's empty if the item hasn't any title
+func (item *CPEItem) TitleIn(lang string) string {
+	for _, l := range []string{lang, "en", "en-US"} {
+		if title, ok := item.Title[l]; ok {
+			return title
+		}
+	}
+	var langs []string
+	for l := range item.Title {
+		langs = append(langs, l)
+	}
+	if len(langs) == 0 {
+		return ""
+	}
+	sort.Strings(langs) // deterministic choice
+	return item.Title[langs[0]]
+}
+
+// itemName returns the CPE 2.3 name of the item, or its CPE 2.2 name if the former is missing
+func itemName(item *CPEItem) wfn.Attributes {
+	if item.CPE23.Name != (NamePattern{}) {
+		return wfn.Attributes(item.CPE23.Name)
+	}
+	return wfn.Attributes(item.Name)
+}
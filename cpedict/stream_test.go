@@ -0,0 +1,121 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testStreamDict = `<?xml version='1.0' encoding='UTF-8'?>
+<cpe-list xmlns="http://cpe.mitre.org/dictionary/2.0" xmlns:cpe-23="http://scap.nist.gov/schema/cpe-extension/2.3">
+  <generator>
+    <product_name>National Vulnerability Database (NVD)</product_name>
+    <product_version>4.0</product_version>
+    <schema_version>2.3</schema_version>
+    <timestamp>2021-03-01T03:50:11.922Z</timestamp>
+  </generator>
+  <cpe-item name="cpe:/a:adobe:flex_sdk:-">
+    <title xml:lang="en-US">Adobe Flex</title>
+    <references>
+      <reference href="https://www.adobe.com/products/flex.html">Product</reference>
+    </references>
+    <cpe-23:cpe23-item name="cpe:2.3:a:adobe:flex_sdk:-:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:3com:tippingpoint_ips_tos:2.1.3.6323" deprecated="true" deprecation_date="2010-12-28T17:35:59.740Z">
+    <title xml:lang="en-US">3Com TippingPoint IPS TOS 2.1.3.6323</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*">
+      <cpe-23:deprecation date="2010-12-28T12:35:59.740-05:00">
+        <cpe-23:deprecated-by name="cpe:2.3:o:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*" type="NAME_CORRECTION"/>
+      </cpe-23:deprecation>
+    </cpe-23:cpe23-item>
+  </cpe-item>
+  <cpe-item name="cpe:/o:3com:tippingpoint_ips_tos:2.1.3.6323">
+    <title xml:lang="en-US">3Com TippingPoint IPS TOS 2.1.3.6323</title>
+    <cpe-23:cpe23-item name="cpe:2.3:o:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*"/>
+  </cpe-item>
+</cpe-list>
+`
+
+func TestDecodeStream(t *testing.T) {
+	var titles []string
+	generator, err := DecodeStream(strings.NewReader(testStreamDict), func(item *CPEItem) error {
+		titles = append(titles, item.Title["en-US"])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generator == nil || generator.ProductVersion != "4.0" {
+		t.Errorf("bad generator %+v", generator)
+	}
+	if len(titles) != 3 || titles[0] != "Adobe Flex" {
+		t.Errorf("unexpected items decoded: %q", titles)
+	}
+
+	stop := fmt.Errorf("stop")
+	n := 0
+	_, err = DecodeStream(strings.NewReader(testStreamDict), func(item *CPEItem) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("expected decoding to stop at the first item with the error of callback, decoded %d: %v", n, err)
+	}
+
+	if _, err := DecodeStream(strings.NewReader(testStreamDict[:len(testStreamDict)/2]), func(*CPEItem) error { return nil }); err == nil {
+		t.Error("expected an error for truncated dictionary")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	idx, err := LoadIndex(strings.NewReader(testStreamDict), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Len() != 3 || idx.Generator == nil {
+		t.Fatalf("expected 3 items and the generator, got %d items, generator %+v", idx.Len(), idx.Generator)
+	}
+	for _, name := range []string{"cpe:2.3:a:adobe:flex_sdk:-:*:*:*:*:*:*:*", "cpe:/a:adobe:flex_sdk:-"} {
+		item, err := idx.Lookup(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(item.References) != 1 || item.References[0].Desc != "Product" {
+			t.Errorf("%s: unexpected references %+v", name, item.References)
+		}
+	}
+	if _, err := idx.Lookup("cpe:2.3:a:adobe:flex_sdk:4.0:*:*:*:*:*:*:*"); err == nil {
+		t.Error("expected an error for name missing in the dictionary")
+	}
+
+	deprecated, err := idx.Lookup("cpe:2.3:a:3com:tippingpoint_ips_tos:2.1.3.6323:*:*:*:*:*:*:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := idx.Current(deprecated.CPE23.Name)
+	if len(current) != 1 || current[0].Name.Part != "o" || current[0].Deprecated {
+		t.Errorf("expected deprecated name to resolve to its replacement, got %+v", current)
+	}
+
+	idx, err = LoadIndex(strings.NewReader(testStreamDict), func(item *CPEItem) bool { return !item.Deprecated })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Len() != 2 {
+		t.Errorf("expected deprecated item to be filtered out, got %d items", idx.Len())
+	}
+}
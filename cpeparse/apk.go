@@ -0,0 +1,116 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// APKPackage is a package of Alpine apk database
+type APKPackage struct {
+	Package      string
+	Version      string // version-rrelease, e.g. 1.1.1k-r0
+	Architecture string
+}
+
+// FromAPKPackage parses CPE name of Alpine package of the name, version (version-rrelease) and architecture
+func FromAPKPackage(pkg, version, arch string) (*wfn.Attributes, error) {
+	name, err := wfn.WFNize(strings.ToLower(pkg))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse name of Alpine package %q", pkg)
+	}
+	var rel string
+	if i := strings.LastIndex(version, "-r"); i != -1 {
+		if rel, err = wfn.WFNize(version[i+1:]); err != nil {
+			return nil, fmt.Errorf("couldn't parse release of Alpine package %q: %v", pkg, err)
+		}
+		version = version[:i]
+	}
+	ver, err := wfn.WFNize(version)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse version of Alpine package %q: %v", pkg, err)
+	}
+	if arch, err = wfn.WFNize(arch); err != nil {
+		return nil, fmt.Errorf("couldn't parse architecture of Alpine package %q: %v", pkg, err)
+	}
+	if arch == "noarch" {
+		arch = wfn.Any
+	}
+	attr, err := packageCPE(name, ver, rel, arch)
+	if err != nil {
+		return nil, fmt.Errorf("%v in Alpine package %q %q", err, pkg, version)
+	}
+	return attr, nil
+}
+
+// FromAPKName parses CPE name of Alpine package from name-version-rrelease, as listed by apk info -v,
+// or the file name of the package, name-version-rrelease.apk
+func FromAPKName(s string) (*wfn.Attributes, error) {
+	pkg := strings.TrimSuffix(s, ".apk")
+	i := strings.LastIndex(pkg, "-r")
+	if i <= 0 {
+		return nil, fmt.Errorf("expected name-version-rrelease, got %q", s)
+	}
+	j := strings.LastIndexByte(pkg[:i], '-')
+	if j <= 0 {
+		return nil, fmt.Errorf("expected name-version-rrelease, got %q", s)
+	}
+	return FromAPKPackage(pkg[:j], pkg[j+1:], "")
+}
+
+// ParseAPKInstalled parses installed packages database of apk (lib/apk/db/installed), calling fn for every package.
+// Parsing stops at the first error returned by fn.
+func ParseAPKInstalled(r io.Reader, fn func(APKPackage) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var pkg APKPackage
+	flush := func() error {
+		if pkg.Package == "" {
+			return nil
+		}
+		p := pkg
+		pkg = APKPackage{}
+		return fn(p)
+	}
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			return fmt.Errorf("malformed apk database line %q", line)
+		}
+		switch line[0] {
+		case 'P':
+			pkg.Package = line[2:]
+		case 'V':
+			pkg.Version = line[2:]
+		case 'A':
+			pkg.Architecture = line[2:]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
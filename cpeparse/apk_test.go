@@ -0,0 +1,120 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromAPKPackage(t *testing.T) {
+	cases := []struct {
+		pkg, version, arch string
+		cpe                string
+		fail               bool
+	}{
+		{"", "1.0-r0", "x86_64", "", true},
+		{"musl", "", "x86_64", "", true},
+		{"musl", "1.2.2-r7", "x86_64", "cpe:2.3:a:*:musl:1.2.2:r7:*:*:*:*:x86_64:*", false},
+		{"ca-certificates-bundle", "20220614-r0", "noarch", "cpe:2.3:a:*:ca-certificates-bundle:20220614:r0:*:*:*:*:*:*", false},
+		{"libcrypto1.1", "1.1.1q-r0", "aarch64", "cpe:2.3:a:*:libcrypto1.1:1.1.1q:r0:*:*:*:*:aarch64:*", false},
+		{"busybox", "1.35.0", "", "cpe:2.3:a:*:busybox:1.35.0:*:*:*:*:*:*:*", false},
+	}
+	for _, c := range cases {
+		attr, err := FromAPKPackage(c.pkg, c.version, c.arch)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%q %q: unexpected failure: %v", c.pkg, c.version, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%q %q: unexpected success", c.pkg, c.version)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%q %q: expected %q got %q", c.pkg, c.version, c.cpe, s)
+		}
+	}
+}
+
+func TestFromAPKName(t *testing.T) {
+	cases := []struct {
+		in, cpe string
+		fail    bool
+	}{
+		{"musl-1.2.2-r7", "cpe:2.3:a:*:musl:1.2.2:r7:*:*:*:*:*:*", false},
+		{"ca-certificates-bundle-20220614-r0.apk", "cpe:2.3:a:*:ca-certificates-bundle:20220614:r0:*:*:*:*:*:*", false},
+		{"musl-1.2.2", "", true},
+		{"musl", "", true},
+	}
+	for _, c := range cases {
+		attr, err := FromAPKName(c.in)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%q: unexpected failure: %v", c.in, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%q: unexpected success", c.in)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%q: expected %q got %q", c.in, c.cpe, s)
+		}
+	}
+}
+
+const testAPKInstalled = `C:Q1abc=
+P:musl
+V:1.2.2-r7
+A:x86_64
+S:383152
+T:the musl c library (libc) implementation
+
+C:Q1def=
+P:busybox
+V:1.34.1-r5
+A:x86_64
+F:bin
+R:busybox
+`
+
+func TestParseAPKInstalled(t *testing.T) {
+	var pkgs []APKPackage
+	err := ParseAPKInstalled(strings.NewReader(testAPKInstalled), func(p APKPackage) error {
+		pkgs = append(pkgs, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []APKPackage{
+		{"musl", "1.2.2-r7", "x86_64"},
+		{"busybox", "1.34.1-r5", "x86_64"},
+	}
+	if len(pkgs) != len(want) {
+		t.Fatalf("expected %d packages, got %d: %+v", len(want), len(pkgs), pkgs)
+	}
+	for i := range want {
+		if pkgs[i] != want[i] {
+			t.Errorf("package %d: expected %+v got %+v", i, want[i], pkgs[i])
+		}
+	}
+	if err := ParseAPKInstalled(strings.NewReader("garbage\n"), func(APKPackage) error { return nil }); err == nil {
+		t.Error("expected an error on malformed database")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// FieldsFromDebPackage returns name, version, revision and architecture of Debian package of the name, version and
+// architecture, as listed by dpkg-query -W or dpkg status file. The version is [epoch:]upstream_version[-revision],
+// the epoch isn't used; the name may be qualified with the architecture (multiarch), e.g. libc6:amd64.
+func FieldsFromDebPackage(pkg, version, arch string) (name, ver, rev, archOut string, err error) {
+	// multiarch qualifier
+	if i := strings.IndexByte(pkg, ':'); i != -1 {
+		if arch == "" {
+			arch = pkg[i+1:]
+		}
+		pkg = pkg[:i]
+	}
+	if archOut, err = wfn.WFNize(arch); err != nil {
+		err = fmt.Errorf("couldn't parse architecture of Debian package %q: %v", pkg, err)
+		return
+	}
+	if archOut == "all" {
+		archOut = wfn.Any
+	}
+	// epoch -- we don't use it
+	if i := strings.IndexByte(version, ':'); i != -1 {
+		version = version[i+1:]
+	}
+	// revision
+	if i := strings.LastIndexByte(version, '-'); i != -1 {
+		if rev, err = wfn.WFNize(version[i+1:]); err != nil {
+			err = fmt.Errorf("couldn't parse revision of Debian package %q: %v", pkg, err)
+			return
+		}
+		version = version[:i]
+	}
+	// version
+	if ver, err = wfn.WFNize(version); err != nil {
+		err = fmt.Errorf("couldn't parse version of Debian package %q: %v", pkg, err)
+		return
+	}
+	// name
+	if name, err = wfn.WFNize(strings.ToLower(pkg)); err != nil {
+		err = fmt.Errorf("couldn't parse name of Debian package %q", pkg)
+		return
+	}
+	return
+}
+
+// FromDebPackage parses CPE name of Debian package of the name, version and architecture, see FieldsFromDebPackage
+func FromDebPackage(pkg, version, arch string) (*wfn.Attributes, error) {
+	name, ver, rev, arch, err := FieldsFromDebPackage(pkg, version, arch)
+	if err != nil {
+		return nil, err
+	}
+	attr, err := packageCPE(name, ver, rev, arch)
+	if err != nil {
+		return nil, fmt.Errorf("%v in Debian package %q %q", err, pkg, version)
+	}
+	return attr, nil
+}
+
+// FromDebName parses CPE name from Debian package file name, name_version_architecture.deb;
+// the epoch is escaped as %3a in file names
+func FromDebName(s string) (*wfn.Attributes, error) {
+	parts := strings.Split(strings.TrimSuffix(s, ".deb"), "_")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected name_version_architecture.deb, got %q", s)
+	}
+	return FromDebPackage(parts[0], strings.Replace(parts[1], "%3a", ":", 1), parts[2])
+}
+
+// DebPackage is a package paragraph of dpkg status file
+type DebPackage struct {
+	Package      string
+	Version      string
+	Architecture string
+	Status       string // e.g. install ok installed
+}
+
+// Installed tells whether the package is installed, as per its status
+func (p DebPackage) Installed() bool {
+	fields := strings.Fields(p.Status)
+	return len(fields) == 3 && fields[2] == "installed"
+}
+
+// ParseDebStatus parses dpkg status file (e.g. /var/lib/dpkg/status) calling fn for every package paragraph,
+// installed or not, see DebPackage.Installed. Parsing stops at the first error returned by fn.
+func ParseDebStatus(r io.Reader, fn func(DebPackage) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024) // descriptions and conffiles are folded into long paragraphs
+	var pkg DebPackage
+	flush := func() error {
+		if pkg.Package == "" {
+			return nil
+		}
+		p := pkg
+		pkg = DebPackage{}
+		return fn(p)
+	}
+	for s.Scan() {
+		line := s.Text()
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' { // continuation of multiline field
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i == -1 {
+			return fmt.Errorf("malformed dpkg status line %q", line)
+		}
+		value := strings.TrimSpace(line[i+1:])
+		switch line[:i] {
+		case "Package":
+			pkg.Package = value
+		case "Version":
+			pkg.Version = value
+		case "Architecture":
+			pkg.Architecture = value
+		case "Status":
+			pkg.Status = value
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
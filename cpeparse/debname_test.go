@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFromDebPackage(t *testing.T) {
+	cases := []struct {
+		pkg, version, arch string
+		cpe                string
+		fail               bool
+	}{
+		{"", "1.0", "", "", true},
+		{"name", "", "amd64", "", true},
+		{"openssl", "1.1.1f-1ubuntu2.16", "amd64", "cpe:2.3:a:*:openssl:1.1.1f:1ubuntu2.16:*:*:*:*:amd64:*", false},
+		{"libc6:amd64", "2.31-0ubuntu9.9", "", "cpe:2.3:a:*:libc6:2.31:0ubuntu9.9:*:*:*:*:amd64:*", false},
+		{"tzdata", "2023c-0ubuntu0.20.04.2", "all", "cpe:2.3:a:*:tzdata:2023c:0ubuntu0.20.04.2:*:*:*:*:*:*", false},
+		{"Bash", "5.0-6ubuntu1.2", "amd64", "cpe:2.3:a:*:bash:5.0:6ubuntu1.2:*:*:*:*:amd64:*", false},
+		{"python3-six", "1.14.0", "all", "cpe:2.3:a:*:python3-six:1.14.0:*:*:*:*:*:*:*", false},
+		{"libssl1.1", "1:1.1.1f-1", "amd64", "cpe:2.3:a:*:libssl1.1:1.1.1f:1:*:*:*:*:amd64:*", false},
+		{"vim", "2:8.1.2269-1ubuntu5~esm1", "amd64", "cpe:2.3:a:*:vim:8.1.2269:1ubuntu5\\~esm1:*:*:*:*:amd64:*", false},
+	}
+	for _, c := range cases {
+		attr, err := FromDebPackage(c.pkg, c.version, c.arch)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%q %q: unexpected failure: %v", c.pkg, c.version, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%q %q: unexpected success", c.pkg, c.version)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%q %q: expected %q got %q", c.pkg, c.version, c.cpe, s)
+		}
+	}
+}
+
+func TestFromDebName(t *testing.T) {
+	attr, err := FromDebName("libssl1.1_1%3a1.1.1f-1ubuntu2_amd64.deb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := attr.BindToFmtString(), "cpe:2.3:a:*:libssl1.1:1.1.1f:1ubuntu2:*:*:*:*:amd64:*"; s != want {
+		t.Errorf("expected %q got %q", want, s)
+	}
+	if _, err := FromDebName("libssl1.1-1.1.1f.deb"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+const testDebStatus = `Package: bash
+Status: install ok installed
+Priority: required
+Architecture: amd64
+Version: 5.0-6ubuntu1.2
+Description: GNU Bourne Again SHell
+ Bash is an sh-compatible command language interpreter.
+ .
+ More text.
+
+Package: removed
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0-1
+
+Package: tzdata
+Status: install ok installed
+Architecture: all
+Version: 2023c-0ubuntu0.20.04.2
+`
+
+func TestParseDebStatus(t *testing.T) {
+	var got []string
+	err := ParseDebStatus(strings.NewReader(testDebStatus), func(p DebPackage) error {
+		got = append(got, fmt.Sprintf("%s %s %s %t", p.Package, p.Version, p.Architecture, p.Installed()))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"bash 5.0-6ubuntu1.2 amd64 true",
+		"removed 1.0-1 amd64 false",
+		"tzdata 2023c-0ubuntu0.20.04.2 all true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if err := ParseDebStatus(strings.NewReader("garbage\n"), func(DebPackage) error { return nil }); err == nil {
+		t.Error("expected an error for malformed status file")
+	}
+}
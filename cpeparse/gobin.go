@@ -0,0 +1,181 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// GoModule is a module Go binary is built of
+type GoModule struct {
+	Path    string
+	Version string // e.g. v1.9.1, (devel) for the main module built from a working copy
+}
+
+// GoBinary is the build information and the functions of Go binary
+type GoBinary struct {
+	GoVersion string // e.g. go1.21.3
+	Path      string // the path of the main package
+	Main      GoModule
+	Deps      []GoModule // replaced modules are listed as their replacements
+	GOOS      string     // as per the build settings, empty if they aren't recorded
+	GOARCH    string
+	Symbols   []string // names of the functions, sorted; none if the binary has neither symbol table nor pclntab
+}
+
+// ReadGoBinary reads the build information and the functions of Go binary, ELF, Mach-O or PE executable;
+// executables which aren't Go binaries, or are built without module support, are an error
+func ReadGoBinary(r io.ReaderAt) (*GoBinary, error) {
+	info, err := buildinfo.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read Go build information: %v", err)
+	}
+	bin := &GoBinary{
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Main:      GoModule{Path: info.Main.Path, Version: info.Main.Version},
+		Symbols:   goSymbols(r),
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		bin.Deps = append(bin.Deps, GoModule{Path: dep.Path, Version: dep.Version})
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "GOOS":
+			bin.GOOS = setting.Value
+		case "GOARCH":
+			bin.GOARCH = setting.Value
+		}
+	}
+	return bin, nil
+}
+
+// ReadGoBinaryFile reads Go binary of the file, see ReadGoBinary
+func ReadGoBinaryFile(name string) (*GoBinary, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGoBinary(f)
+}
+
+// WalkGoBinaries calls fn for every Go binary of the files and the directories, which are walked recursively;
+// the files which aren't Go binaries are skipped. Walking stops at the first error, of fn or walking the directories.
+func WalkGoBinaries(paths []string, fn func(path string, bin *GoBinary) error) error {
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			bin, err := ReadGoBinaryFile(path)
+			if err != nil {
+				return nil // not a Go binary
+			}
+			return fn(path, bin)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Modules returns the modules the binary is built of: the standard library of the Go version it's built with,
+// the main module, unless it's built from a working copy, and the dependencies
+func (b *GoBinary) Modules() []GoModule {
+	var mods []GoModule
+	if v := GoReleaseVersion(b.GoVersion); v != "" {
+		mods = append(mods, GoModule{Path: GoStdlib, Version: "v" + v})
+	}
+	if b.Main.Path != "" && GoModuleVersion(b.Main.Version) != "" {
+		mods = append(mods, b.Main)
+	}
+	return append(mods, b.Deps...)
+}
+
+// goSymbols returns sorted names of the functions of the executable: the ones of Go line table (pclntab),
+// which stripped binaries keep, or of the symbol table. Nil is returned if there are neither.
+func goSymbols(r io.ReaderAt) []string {
+	var pclntab []byte
+	var text uint64
+	var names []string
+	if f, err := elf.NewFile(r); err == nil {
+		if s := f.Section(".gopclntab"); s != nil {
+			pclntab, _ = s.Data()
+		}
+		if s := f.Section(".text"); s != nil {
+			text = s.Addr
+		}
+		syms, _ := f.Symbols()
+		for _, sym := range syms {
+			if elf.ST_TYPE(sym.Info) == elf.STT_FUNC {
+				names = append(names, sym.Name)
+			}
+		}
+	} else if f, err := macho.NewFile(r); err == nil {
+		if s := f.Section("__gopclntab"); s != nil {
+			pclntab, _ = s.Data()
+		}
+		if s := f.Section("__text"); s != nil {
+			text = s.Addr
+		}
+		if f.Symtab != nil {
+			for _, sym := range f.Symtab.Syms {
+				names = append(names, sym.Name)
+			}
+		}
+	} else if f, err := pe.NewFile(r); err == nil {
+		// PE binaries have no pclntab section, only their symbol tables are read
+		for _, sym := range f.Symbols {
+			names = append(names, sym.Name)
+		}
+	}
+	if pclntab != nil {
+		if tab, err := gosym.NewTable(nil, gosym.NewLineTable(pclntab, text)); err == nil {
+			names = names[:0]
+			for _, fn := range tab.Funcs {
+				names = append(names, fn.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	j := 0
+	for i := range names {
+		if i == 0 || names[i] != names[j-1] {
+			names[j] = names[i]
+			j++
+		}
+	}
+	return names[:j]
+}
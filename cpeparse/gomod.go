@@ -0,0 +1,165 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	// GoStdlib is the module path the Go vulnerability database reports vulnerabilities of the standard library under
+	GoStdlib = "stdlib"
+	// GoToolchain is the module path the Go vulnerability database reports vulnerabilities of the go command under
+	GoToolchain = "toolchain"
+)
+
+// FromGoModule parses CPE name of Go module of the path and version, as listed by go version -m, e.g.
+// github.com/gin-gonic/gin v1.9.1; empty version is any version. Modules hosted on GitHub, GitLab and Bitbucket
+// are named owner:repository, golang.org/x modules golang:name and gopkg.in modules as the GitHub repositories
+// they redirect to; the host is the vendor of the others. The major version suffix (/v2, .v3) isn't part of the
+// product and the target software is go, except for the standard library and the toolchain, golang:go.
+func FromGoModule(path, version string) (*wfn.Attributes, error) {
+	vendor, product, targetSW := goModuleName(path)
+	if product == "" {
+		return nil, fmt.Errorf("couldn't parse name of Go module %q", path)
+	}
+	attr := &wfn.Attributes{Part: "a", TargetSW: targetSW}
+	var err error
+	if attr.Vendor, err = wfn.WFNize(vendor); err != nil {
+		return nil, fmt.Errorf("couldn't parse vendor of Go module %q: %v", path, err)
+	}
+	if attr.Product, err = wfn.WFNize(product); err != nil {
+		return nil, fmt.Errorf("couldn't parse name of Go module %q: %v", path, err)
+	}
+	if version == "" {
+		return attr, nil
+	}
+	ver := GoModuleVersion(version)
+	if path == GoStdlib || path == GoToolchain {
+		ver = GoReleaseVersion(version)
+	}
+	if ver == "" {
+		return nil, fmt.Errorf("couldn't parse version %q of Go module %q", version, path)
+	}
+	if attr.Version, err = wfn.WFNize(ver); err != nil {
+		return nil, fmt.Errorf("couldn't parse version %q of Go module %q: %v", version, path, err)
+	}
+	return attr, nil
+}
+
+// goModuleName returns vendor, product and target software of CPE name of Go module of the path
+func goModuleName(path string) (vendor, product, targetSW string) {
+	if path == GoStdlib || path == GoToolchain {
+		return "golang", "go", wfn.Any
+	}
+	elems := strings.Split(strings.ToLower(strings.Trim(path, "/")), "/")
+	if n := len(elems); n > 1 && isMajorVersion(elems[n-1]) {
+		elems = elems[:n-1]
+	}
+	switch host := elems[0]; {
+	case len(elems) == 1:
+		return "", "", ""
+	case host == "github.com" || host == "gitlab.com" || host == "bitbucket.org":
+		if len(elems) < 3 {
+			return "", "", ""
+		}
+		return elems[1], elems[2], "go"
+	case host == "golang.org" && elems[1] == "x" && len(elems) > 2:
+		return "golang", elems[2], "go"
+	case host == "gopkg.in":
+		// gopkg.in/pkg.v3 is github.com/go-pkg/pkg, gopkg.in/user/pkg.v3 is github.com/user/pkg
+		name := elems[len(elems)-1]
+		if i := strings.LastIndex(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+			name = name[:i]
+		}
+		if len(elems) == 2 {
+			return "go-" + name, name, "go"
+		}
+		return elems[1], name, "go"
+	default:
+		return host, elems[len(elems)-1], "go"
+	}
+}
+
+// isMajorVersion tells whether the path element is major version suffix of Go module, e.g. v2
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	for _, c := range elem[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GoModuleVersion returns the version of Go module as the Go vulnerability database records it, without the leading v
+// and +incompatible, e.g. 1.9.1 of v1.9.1; pseudo-versions are kept as they are, they compare to the releases they
+// follow. Empty string is returned for the versions which aren't semantic, e.g. (devel) of the main module.
+func GoModuleVersion(version string) string {
+	if !strings.HasPrefix(version, "v") || len(version) < 2 || version[1] < '0' || version[1] > '9' {
+		return ""
+	}
+	return strings.TrimSuffix(version[1:], "+incompatible")
+}
+
+// GoReleaseVersion returns Go release (e.g. go1.21.3, go1.21rc2, as reported by go version) as semantic version,
+// the way the Go vulnerability database records the versions of the standard library: 1.21.3, 1.21.0-rc.2;
+// semantic versions (v1.21.3) are accepted as well. Empty string is returned for the versions it can't parse,
+// e.g. devel builds.
+func GoReleaseVersion(version string) string {
+	if v := GoModuleVersion(version); v != "" {
+		return v
+	}
+	fields := strings.Fields(version) // go1.22.0 X:boringcrypto
+	if len(fields) == 0 {
+		return ""
+	}
+	version = strings.TrimPrefix(fields[0], "go")
+	i := strings.IndexFunc(version, func(c rune) bool { return (c < '0' || c > '9') && c != '.' })
+	if i == 0 || version == "" {
+		return ""
+	}
+	core, pre := version, ""
+	if i > 0 {
+		core, pre = version[:i], version[i:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return ""
+	}
+	for _, part := range parts {
+		if part == "" {
+			return ""
+		}
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	v := strings.Join(parts, ".")
+	if pre == "" {
+		return v
+	}
+	// pre-releases, beta1 and rc2, are followed by the number of the pre-release
+	j := strings.IndexFunc(pre, func(c rune) bool { return c >= '0' && c <= '9' })
+	if j <= 0 || strings.IndexFunc(pre[j:], func(c rune) bool { return c < '0' || c > '9' }) >= 0 {
+		return ""
+	}
+	return v + "-" + pre[:j] + "." + pre[j:]
+}
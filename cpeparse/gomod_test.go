@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func TestFromGoModule(t *testing.T) {
+	cases := []struct {
+		path, version string
+		cpe           string
+		fail          bool
+	}{
+		{"github.com/gin-gonic/gin", "v1.9.1", "cpe:2.3:a:gin-gonic:gin:1.9.1:*:*:*:*:go:*:*", false},
+		{"github.com/go-redis/redis/v8", "v8.11.5", "cpe:2.3:a:go-redis:redis:8.11.5:*:*:*:*:go:*:*", false},
+		{"github.com/Masterminds/goutils", "v1.1.0+incompatible", "cpe:2.3:a:masterminds:goutils:1.1.0:*:*:*:*:go:*:*", false},
+		{"golang.org/x/net", "v0.0.0-20220127200216-cd36cc0744dd", "cpe:2.3:a:golang:net:0.0.0-20220127200216-cd36cc0744dd:*:*:*:*:go:*:*", false},
+		{"gopkg.in/yaml.v3", "v3.0.1", "cpe:2.3:a:go-yaml:yaml:3.0.1:*:*:*:*:go:*:*", false},
+		{"gopkg.in/src-d/go-git.v4", "v4.13.1", "cpe:2.3:a:src-d:go-git:4.13.1:*:*:*:*:go:*:*", false},
+		{"k8s.io/client-go", "v0.26.1", "cpe:2.3:a:k8s.io:client-go:0.26.1:*:*:*:*:go:*:*", false},
+		{"stdlib", "go1.21.3", "cpe:2.3:a:golang:go:1.21.3:*:*:*:*:*:*:*", false},
+		{"toolchain", "v1.21.0", "cpe:2.3:a:golang:go:1.21.0:*:*:*:*:*:*:*", false},
+		{"github.com/gin-gonic/gin", "", "cpe:2.3:a:gin-gonic:gin:*:*:*:*:*:go:*:*", false},
+		{"github.com/gin-gonic", "v1.0.0", "", true},
+		{"example", "v1.0.0", "", true},
+		{"github.com/gin-gonic/gin", "(devel)", "", true},
+		{"stdlib", "devel go1.22-abcdef", "", true},
+	}
+	for _, c := range cases {
+		attr, err := FromGoModule(c.path, c.version)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%q %q: unexpected failure: %v", c.path, c.version, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%q %q: expected failure, got %s", c.path, c.version, attr.BindToFmtString())
+			continue
+		}
+		if cpe := attr.BindToFmtString(); cpe != c.cpe {
+			t.Errorf("%q %q: expected %s, got %s", c.path, c.version, c.cpe, cpe)
+		}
+	}
+}
+
+func TestGoReleaseVersion(t *testing.T) {
+	for in, out := range map[string]string{
+		"go1.21.3":                "1.21.3",
+		"go1.21":                  "1.21.0",
+		"go1.21rc2":               "1.21.0-rc.2",
+		"go1.18beta1":             "1.18.0-beta.1",
+		"go1.22.0 X:boringcrypto": "1.22.0",
+		"v1.20.5":                 "1.20.5",
+		"devel go1.22-abcdef":     "",
+		"go1.21.x":                "",
+		"go1.2.3.4":               "",
+		"":                        "",
+	} {
+		if v := GoReleaseVersion(in); v != out {
+			t.Errorf("%q: expected %q, got %q", in, out, v)
+		}
+	}
+}
+
+func TestReadGoBinary(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	bin, err := ReadGoBinaryFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin.GoVersion != runtime.Version() {
+		t.Errorf("expected Go version %q, got %q", runtime.Version(), bin.GoVersion)
+	}
+	if mods := bin.Modules(); GoReleaseVersion(runtime.Version()) != "" && (len(mods) == 0 || mods[0].Path != GoStdlib) {
+		t.Errorf("expected the standard library to be the first module, got %v", mods)
+	}
+	const fn = "github.com/facebookincubator/nvdtools/cpeparse.TestReadGoBinary"
+	if i := sort.SearchStrings(bin.Symbols, fn); i == len(bin.Symbols) || bin.Symbols[i] != fn {
+		t.Errorf("expected %s among %d functions of the binary", fn, len(bin.Symbols))
+	}
+
+	if _, err := ReadGoBinary(bytes.NewReader([]byte("not a binary"))); err == nil {
+		t.Error("expected failure reading not a binary")
+	}
+}
+
+func TestWalkGoBinaries(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "gobin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := ioutil.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string][]byte{"bin/app": data, "bin/script.sh": []byte("#!/bin/sh\n"), "README": []byte("readme")} {
+		name = filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := ioutil.WriteFile(name, content, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var found []string
+	err = WalkGoBinaries([]string{dir}, func(path string, bin *GoBinary) error {
+		found = append(found, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "bin/app"); len(found) != 1 || found[0] != want {
+		t.Errorf("expected only %s to be found, got %v", want, found)
+	}
+}
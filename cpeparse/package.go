@@ -0,0 +1,40 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// packageCPE returns CPE name of the package of WFNized name, version, release (or revision) and architecture,
+// the way all package formats map to CPE names: the release is the update and the architecture is the target
+// hardware. The name and the version are mandatory.
+func packageCPE(name, ver, rel, arch string) (*wfn.Attributes, error) {
+	if name == wfn.Any {
+		return nil, fmt.Errorf("no name found")
+	}
+	if ver == wfn.Any {
+		return nil, fmt.Errorf("no version found")
+	}
+	return &wfn.Attributes{
+		Part:     "a", // TODO: figure out the way to properly detect os packages (linux_kernel or smth)
+		Product:  name,
+		Version:  ver,
+		Update:   rel,
+		TargetHW: arch,
+	}, nil
+}
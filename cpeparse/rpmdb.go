@@ -0,0 +1,219 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// RPMPackage is a package of rpm database
+type RPMPackage struct {
+	Name         string
+	Epoch        int
+	Version      string
+	Release      string
+	Architecture string
+}
+
+// FromRPMPackage parses CPE name of the package of rpm database, consistently with FromRPMName
+func FromRPMPackage(p RPMPackage) (*wfn.Attributes, error) {
+	name, err := wfn.WFNize(strings.ToLower(p.Name))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse name of RPM package %q", p.Name)
+	}
+	ver, err := wfn.WFNize(p.Version)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse version of RPM package %q: %v", p.Name, err)
+	}
+	rel, err := wfn.WFNize(p.Release)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse release of RPM package %q: %v", p.Name, err)
+	}
+	arch, err := wfn.WFNize(p.Architecture)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse architecture of RPM package %q: %v", p.Name, err)
+	}
+	if arch == "noarch" || arch == "src" {
+		arch = wfn.Any
+	}
+	attr, err := packageCPE(name, ver, rel, arch)
+	if err != nil {
+		return nil, fmt.Errorf("%v in RPM package %q", err, p.Name)
+	}
+	return attr, nil
+}
+
+// Berkeley DB hash database layout, as used by rpm database (var/lib/rpm/Packages)
+const (
+	bdbHashMagic      = 0x061561
+	bdbPageHeaderSize = 26
+	bdbHashUnsorted   = 2  // P_HASH_UNSORTED page type
+	bdbOverflow       = 7  // P_OVERFLOW page type
+	bdbHash           = 13 // P_HASH page type
+	bdbOffPage        = 3  // H_OFFPAGE item type: the value is stored on a chain of overflow pages
+)
+
+// ParseRPMDB parses rpm database of Berkeley DB format (var/lib/rpm/Packages), calling fn for every package.
+// SQLite (rpmdb.sqlite) and NDB (Packages.db) databases are not supported. Parsing stops at the first error
+// returned by fn.
+func ParseRPMDB(db []byte, fn func(RPMPackage) error) error {
+	if len(db) < 512 {
+		return fmt.Errorf("rpm database is too short: %d bytes", len(db))
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	switch uint32(bdbHashMagic) {
+	case binary.LittleEndian.Uint32(db[12:]):
+	case binary.BigEndian.Uint32(db[12:]):
+		order = binary.BigEndian
+	default:
+		return fmt.Errorf("unsupported rpm database format: not a Berkeley DB hash database")
+	}
+	pageSize := int(order.Uint32(db[20:]))
+	lastPage := int(order.Uint32(db[32:]))
+	if pageSize < 512 || (lastPage+1)*pageSize > len(db) {
+		return fmt.Errorf("malformed rpm database: page size %d, last page %d, %d bytes", pageSize, lastPage, len(db))
+	}
+	page := func(n int) []byte {
+		return db[n*pageSize : (n+1)*pageSize]
+	}
+	for n := 1; n <= lastPage; n++ {
+		p := page(n)
+		if t := p[25]; t != bdbHash && t != bdbHashUnsorted {
+			continue
+		}
+		entries := int(order.Uint16(p[20:]))
+		if bdbPageHeaderSize+2*entries > pageSize {
+			return fmt.Errorf("malformed rpm database: page %d has %d entries", n, entries)
+		}
+		// entries are key and value pairs, values of packages are headers too large to be stored in place
+		for i := 1; i < entries; i += 2 {
+			at := int(order.Uint16(p[bdbPageHeaderSize+2*i:]))
+			if at+12 > pageSize || p[at] != bdbOffPage {
+				continue
+			}
+			value, err := bdbOverflowValue(page, order, int(order.Uint32(p[at+4:])), int(order.Uint32(p[at+8:])), lastPage)
+			if err != nil {
+				return err
+			}
+			pkg, err := parseRPMHeader(value)
+			if err != nil {
+				return err
+			}
+			if err = fn(pkg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bdbOverflowValue reads the value of the length stored on the chain of overflow pages starting at the page
+func bdbOverflowValue(page func(int) []byte, order binary.ByteOrder, n, length, lastPage int) ([]byte, error) {
+	value := make([]byte, 0, length)
+	for visited := 0; n != 0; visited++ {
+		if n > lastPage || visited > lastPage {
+			return nil, fmt.Errorf("malformed rpm database: bad overflow page %d", n)
+		}
+		p := page(n)
+		if p[25] != bdbOverflow {
+			return nil, fmt.Errorf("malformed rpm database: page %d isn't an overflow page", n)
+		}
+		end := bdbPageHeaderSize + int(order.Uint16(p[22:])) // the free area offset is the length of data
+		if end > len(p) {
+			return nil, fmt.Errorf("malformed rpm database: overflow page %d overflows", n)
+		}
+		value = append(value, p[bdbPageHeaderSize:end]...)
+		n = int(order.Uint32(p[16:]))
+	}
+	if len(value) < length {
+		return nil, fmt.Errorf("malformed rpm database: truncated value, %d of %d bytes", len(value), length)
+	}
+	return value[:length], nil
+}
+
+// rpm header tags and types
+const (
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+	rpmTagArch    = 1022
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeI18NString  = 9
+	rpmHeaderEntrySize = 16
+)
+
+// parseRPMHeader parses the package of rpm header as stored in rpm database, i.e. without the header magic
+func parseRPMHeader(h []byte) (RPMPackage, error) {
+	var pkg RPMPackage
+	if len(h) < 8 {
+		return pkg, fmt.Errorf("malformed rpm header: %d bytes", len(h))
+	}
+	il := int(binary.BigEndian.Uint32(h))
+	dl := int(binary.BigEndian.Uint32(h[4:]))
+	store := 8 + il*rpmHeaderEntrySize
+	if il < 0 || dl < 0 || store+dl > len(h) {
+		return pkg, fmt.Errorf("malformed rpm header: %d entries, %d bytes of data in %d bytes", il, dl, len(h))
+	}
+	data := h[store : store+dl]
+	str := func(offset int) (string, error) {
+		if offset < 0 || offset >= len(data) {
+			return "", fmt.Errorf("malformed rpm header: string offset %d out of %d bytes", offset, len(data))
+		}
+		end := bytes.IndexByte(data[offset:], 0)
+		if end == -1 {
+			return "", fmt.Errorf("malformed rpm header: unterminated string at %d", offset)
+		}
+		return string(data[offset : offset+end]), nil
+	}
+	for i := 0; i < il; i++ {
+		e := h[8+i*rpmHeaderEntrySize:]
+		tag, typ, offset := binary.BigEndian.Uint32(e), binary.BigEndian.Uint32(e[4:]), int(int32(binary.BigEndian.Uint32(e[8:])))
+		var field *string
+		switch tag {
+		case rpmTagName:
+			field = &pkg.Name
+		case rpmTagVersion:
+			field = &pkg.Version
+		case rpmTagRelease:
+			field = &pkg.Release
+		case rpmTagArch:
+			field = &pkg.Architecture
+		case rpmTagEpoch:
+			if typ == rpmTypeInt32 && offset >= 0 && offset+4 <= len(data) {
+				pkg.Epoch = int(binary.BigEndian.Uint32(data[offset:]))
+			}
+			continue
+		default:
+			continue
+		}
+		if typ != rpmTypeString && typ != rpmTypeI18NString {
+			continue
+		}
+		s, err := str(offset)
+		if err != nil {
+			return pkg, err
+		}
+		*field = s
+	}
+	return pkg, nil
+}
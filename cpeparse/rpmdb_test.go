@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// testRPMHeader builds rpm header blob of the package as stored in rpm database
+func testRPMHeader(p RPMPackage) []byte {
+	type entry struct {
+		tag, typ uint32
+		data     []byte
+	}
+	str := func(s string) []byte { return append([]byte(s), 0) }
+	epoch := make([]byte, 4)
+	binary.BigEndian.PutUint32(epoch, uint32(p.Epoch))
+	entries := []entry{
+		{rpmTagEpoch, rpmTypeInt32, epoch},
+		{rpmTagName, rpmTypeString, str(p.Name)},
+		{rpmTagVersion, rpmTypeString, str(p.Version)},
+		{rpmTagRelease, rpmTypeString, str(p.Release)},
+		{rpmTagArch, rpmTypeString, str(p.Architecture)},
+	}
+	var index, data []byte
+	for _, e := range entries {
+		var b [rpmHeaderEntrySize]byte
+		binary.BigEndian.PutUint32(b[0:], e.tag)
+		binary.BigEndian.PutUint32(b[4:], e.typ)
+		binary.BigEndian.PutUint32(b[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(b[12:], 1)
+		index = append(index, b[:]...)
+		data = append(data, e.data...)
+	}
+	h := make([]byte, 8)
+	binary.BigEndian.PutUint32(h, uint32(len(entries)))
+	binary.BigEndian.PutUint32(h[4:], uint32(len(data)))
+	return append(append(h, index...), data...)
+}
+
+// testRPMDB builds Berkeley DB hash database of the page size with the headers stored on overflow pages
+func testRPMDB(pageSize int, headers ...[]byte) []byte {
+	order := binary.LittleEndian
+	var pages [][]byte
+	newPage := func(typ byte) []byte {
+		p := make([]byte, pageSize)
+		order.PutUint32(p[8:], uint32(len(pages)))
+		p[25] = typ
+		pages = append(pages, p)
+		return p
+	}
+	meta := newPage(8)
+	order.PutUint32(meta[12:], bdbHashMagic)
+	order.PutUint32(meta[20:], uint32(pageSize))
+	hash := newPage(bdbHash)
+	order.PutUint16(hash[20:], uint16(2*len(headers)))
+	at := pageSize
+	for i, h := range headers {
+		// the key is the package number stored in place, the value is an off-page item
+		at -= 5
+		hash[at] = 1 // H_KEYDATA
+		order.PutUint32(hash[at+1:], uint32(i+1))
+		order.PutUint16(hash[bdbPageHeaderSize+4*i:], uint16(at))
+		at -= 12
+		hash[at] = bdbOffPage
+		order.PutUint32(hash[at+4:], uint32(len(pages)))
+		order.PutUint32(hash[at+8:], uint32(len(h)))
+		order.PutUint16(hash[bdbPageHeaderSize+4*i+2:], uint16(at))
+		for len(h) != 0 {
+			n := pageSize - bdbPageHeaderSize
+			if n > len(h) {
+				n = len(h)
+			}
+			p := newPage(bdbOverflow)
+			copy(p[bdbPageHeaderSize:], h[:n])
+			order.PutUint16(p[22:], uint16(n))
+			if h = h[n:]; len(h) != 0 {
+				order.PutUint32(p[16:], uint32(len(pages)))
+			}
+		}
+	}
+	order.PutUint32(meta[32:], uint32(len(pages)-1))
+	var db []byte
+	for _, p := range pages {
+		db = append(db, p...)
+	}
+	return db
+}
+
+func TestParseRPMDB(t *testing.T) {
+	want := []RPMPackage{
+		{Name: "bash", Version: "4.4.20", Release: "4.el8_6", Architecture: "x86_64"},
+		{Name: "openssl-libs", Epoch: 1, Version: "1.1.1k", Release: "7.el8_6", Architecture: "x86_64"},
+		{Name: "tzdata", Version: "2022c", Release: "1.el8", Architecture: "noarch"},
+	}
+	var headers [][]byte
+	for _, p := range want {
+		headers = append(headers, testRPMHeader(p))
+	}
+	// small pages, so headers span several overflow pages
+	db := testRPMDB(512, headers...)
+	var pkgs []RPMPackage
+	if err := ParseRPMDB(db, func(p RPMPackage) error {
+		pkgs = append(pkgs, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != len(want) {
+		t.Fatalf("expected %d packages, got %d: %+v", len(want), len(pkgs), pkgs)
+	}
+	for i := range want {
+		if pkgs[i] != want[i] {
+			t.Errorf("package %d: expected %+v got %+v", i, want[i], pkgs[i])
+		}
+	}
+	if err := ParseRPMDB(make([]byte, 4096), func(RPMPackage) error { return nil }); err == nil {
+		t.Error("expected an error on unknown database format")
+	}
+	if err := ParseRPMDB(db[:len(db)-512], func(RPMPackage) error { return nil }); err == nil {
+		t.Error("expected an error on truncated database")
+	}
+}
+
+func TestFromRPMPackage(t *testing.T) {
+	cases := []struct {
+		pkg RPMPackage
+		cpe string
+	}{
+		{RPMPackage{Name: "bash", Version: "4.4.20", Release: "4.el8_6", Architecture: "x86_64"}, "cpe:2.3:a:*:bash:4.4.20:4.el8_6:*:*:*:*:x86_64:*"},
+		{RPMPackage{Name: "tzdata", Version: "2022c", Release: "1.el8", Architecture: "noarch"}, "cpe:2.3:a:*:tzdata:2022c:1.el8:*:*:*:*:*:*"},
+	}
+	for _, c := range cases {
+		attr, err := FromRPMPackage(c.pkg)
+		if err != nil {
+			t.Errorf("%+v: unexpected failure: %v", c.pkg, err)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%+v: expected %q got %q", c.pkg, c.cpe, s)
+		}
+	}
+	if _, err := FromRPMPackage(RPMPackage{Version: "1.0"}); err == nil {
+		t.Error("expected an error on package without name")
+	}
+}
@@ -72,17 +72,9 @@ func FromRPMName(s string) (*wfn.Attributes, error) {
 	if err != nil {
 		return nil, err
 	}
-	if name == wfn.Any {
-		return nil, fmt.Errorf("no name found in RPM name %q", s)
-	}
-	if ver == wfn.Any {
-		return nil, fmt.Errorf("no version found in RPM name %q", s)
+	attr, err := packageCPE(name, ver, rel, arch)
+	if err != nil {
+		return nil, fmt.Errorf("%v in RPM name %q", err, s)
 	}
-	return &wfn.Attributes{
-		Part:     "a", // TODO: figure out the way to properly detect os packages (linux_kernel or smth)
-		Product:  name,
-		Version:  ver,
-		Update:   rel,
-		TargetHW: arch,
-	}, nil
+	return attr, nil
 }
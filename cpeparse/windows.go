@@ -0,0 +1,544 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// WindowsProgram is a program installed on Windows, as listed by the Uninstall keys of the registry or by winget
+type WindowsProgram struct {
+	Name         string // display name, e.g. Mozilla Firefox (x64 en-US); may be empty for winget packages
+	Publisher    string
+	Version      string // display version
+	Architecture string // x64, x86 or arm64 if known
+	ID           string // winget package identifier, e.g. Mozilla.Firefox
+	Source       string // registry, or the winget source, e.g. winget or msstore
+}
+
+// WindowsTable normalizes the publishers, display names and winget package identifiers of Windows programs
+// into CPE vendors and products. Its CSV form has the fields kind, pattern, vendor and product:
+//
+//	publisher,Igor Pavlov,7-zip,
+//	name,^Mozilla Firefox\b,mozilla,firefox
+//	id,^Mozilla\.Firefox\b,mozilla,firefox
+//
+// Publishers map to vendors, their names compare insensitive to lexical case, punctuation and company suffixes
+// (Inc., Corporation, LLC, GmbH...); display names (name) and winget package identifiers (id) which match
+// the regular expressions, insensitive to lexical case, map to vendors and products. The first matching rule
+// applies; lines starting with # are comments.
+type WindowsTable struct {
+	vendors  map[string]string // normalized publishers to vendors
+	products []windowsProductRule
+}
+
+type windowsProductRule struct {
+	id              bool // the pattern is of winget package identifiers rather than display names
+	re              *regexp.Regexp
+	vendor, product string
+}
+
+// defaultWindowsTable is the table of the programs commonly found on Windows, see DefaultWindowsTable
+const defaultWindowsTable = `# kind,pattern,vendor,product
+publisher,Igor Pavlov,7-zip,
+publisher,Notepad++ Team,notepad-plus-plus,
+publisher,The Git Development Community,git-scm,
+publisher,Python Software Foundation,python,
+publisher,VideoLAN,videolan,
+publisher,Simon Tatham,putty,
+publisher,Martin Prikryl,winscp,
+publisher,Tim Kosse,filezilla-project,
+publisher,win.rar GmbH,rarlab,
+publisher,Dominik Reichl,keepass,
+publisher,Node.js Foundation,nodejs,
+publisher,OpenJS Foundation,nodejs,
+publisher,Wireshark Foundation,wireshark,
+publisher,"The Wireshark developer community, https://www.wireshark.org",wireshark,
+publisher,"Zoom Video Communications, Inc.",zoom,
+publisher,Adobe Systems Incorporated,adobe,
+publisher,Oracle and/or its affiliates,oracle,
+publisher,"Cisco Systems, Inc.",cisco,
+publisher,"Citrix Systems, Inc.",citrix,
+publisher,The Document Foundation,libreoffice,
+publisher,Foxit Software Inc.,foxit,
+publisher,The GIMP Team,gimp,
+publisher,HP Inc.,hp,
+publisher,Hewlett-Packard,hp,
+name,^Mozilla Firefox\b,mozilla,firefox
+name,^Mozilla Thunderbird\b,mozilla,thunderbird
+name,^Google Chrome$,google,chrome
+name,^Microsoft Edge$,microsoft,edge_chromium
+name,^Microsoft Visual Studio Code\b,microsoft,visual_studio_code
+name,^Microsoft Teams\b,microsoft,teams
+name,^Microsoft \.NET( Core)? Runtime\b,microsoft,.net
+name,^7-Zip\b,7-zip,7-zip
+name,^Notepad\+\+,notepad-plus-plus,notepad++
+name,^Git( version [0-9.]+)?$,git-scm,git
+name,^Python [0-9]+\.[0-9]+(\.[0-9]+)?( \((32|64)-bit\))?$,python,python
+name,^VLC media player\b,videolan,vlc_media_player
+name,^Adobe Acrobat Reader\b,adobe,acrobat_reader_dc
+name,^Zoom( Workplace)?( \(.*\))?$,zoom,meetings
+name,^Wireshark\b,wireshark,wireshark
+name,^PuTTY\b,putty,putty
+name,^WinSCP\b,winscp,winscp
+name,^FileZilla( Client)?\b,filezilla-project,filezilla_client
+name,^WinRAR\b,rarlab,winrar
+name,^KeePass\b,keepass,keepass
+name,^Node\.js$,nodejs,node.js
+name,^TeamViewer\b,teamviewer,teamviewer
+name,^Docker Desktop$,docker,desktop
+name,^GIMP\b,gimp,gimp
+name,^LibreOffice\b,libreoffice,libreoffice
+name,^VMware Workstation\b,vmware,workstation
+name,^VMware Tools$,vmware,tools
+name,^Oracle VM VirtualBox\b,oracle,vm_virtualbox
+name,^Foxit (PDF )?Reader\b,foxit,pdf_reader
+name,^OpenSSL\b,openssl,openssl
+name,^Cisco AnyConnect Secure Mobility Client\b,cisco,anyconnect_secure_mobility_client
+id,^Mozilla\.Firefox\b,mozilla,firefox
+id,^Mozilla\.Thunderbird\b,mozilla,thunderbird
+id,^Google\.Chrome$,google,chrome
+id,^Microsoft\.Edge$,microsoft,edge_chromium
+id,^Microsoft\.VisualStudioCode$,microsoft,visual_studio_code
+id,^Microsoft\.Teams$,microsoft,teams
+id,^Microsoft\.DotNet\.(Runtime|DesktopRuntime|AspNetCore)\b,microsoft,.net
+id,^7zip\.7zip$,7-zip,7-zip
+id,^Notepad\+\+\.Notepad\+\+$,notepad-plus-plus,notepad++
+id,^Git\.Git$,git-scm,git
+id,^Python\.Python\.[0-9.]+$,python,python
+id,^VideoLAN\.VLC$,videolan,vlc_media_player
+id,^Adobe\.Acrobat\.Reader\b,adobe,acrobat_reader_dc
+id,^Zoom\.Zoom$,zoom,meetings
+id,^WiresharkFoundation\.Wireshark$,wireshark,wireshark
+id,^PuTTY\.PuTTY$,putty,putty
+id,^WinSCP\.WinSCP$,winscp,winscp
+id,^TimKosse\.FileZilla\.Client$,filezilla-project,filezilla_client
+id,^RARLab\.WinRAR$,rarlab,winrar
+id,^DominikReichl\.KeePass$,keepass,keepass
+id,^OpenJS\.NodeJS\b,nodejs,node.js
+id,^TeamViewer\.TeamViewer$,teamviewer,teamviewer
+id,^Docker\.DockerDesktop$,docker,desktop
+id,^GIMP\.GIMP$,gimp,gimp
+id,^TheDocumentFoundation\.LibreOffice$,libreoffice,libreoffice
+id,^Oracle\.VirtualBox$,oracle,vm_virtualbox
+id,^Foxit\.FoxitReader$,foxit,pdf_reader
+id,^ShiningLight\.OpenSSL\b,openssl,openssl
+`
+
+// DefaultWindowsTable returns the table of the programs commonly found on Windows; the vendors of other publishers
+// are their names, less company suffixes
+func DefaultWindowsTable() *WindowsTable {
+	t, err := ParseWindowsTable(strings.NewReader(defaultWindowsTable))
+	if err != nil {
+		panic(err) // the default table is known to parse
+	}
+	return t
+}
+
+// ParseWindowsTable parses the table in CSV form, see WindowsTable
+func ParseWindowsTable(r io.Reader) (*WindowsTable, error) {
+	t := &WindowsTable{vendors: make(map[string]string)}
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("windows table: %v", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("windows table: line %d: expected kind,pattern,vendor[,product]", line)
+		}
+		vendor, product := strings.TrimSpace(rec[2]), ""
+		if len(rec) > 3 {
+			product = strings.TrimSpace(rec[3])
+		}
+		switch kind := strings.TrimSpace(rec[0]); kind {
+		case "publisher":
+			if _, ok := t.vendors[normalizePublisher(rec[1])]; !ok {
+				t.vendors[normalizePublisher(rec[1])] = vendor
+			}
+		case "name", "id":
+			if product == "" {
+				return nil, fmt.Errorf("windows table: line %d: no product of %s %q", line, kind, rec[1])
+			}
+			re, err := regexp.Compile("(?i)" + rec[1])
+			if err != nil {
+				return nil, fmt.Errorf("windows table: line %d: %v", line, err)
+			}
+			t.products = append(t.products, windowsProductRule{id: kind == "id", re: re, vendor: vendor, product: product})
+		default:
+			return nil, fmt.Errorf("windows table: line %d: unknown kind %q, expected publisher, name or id", line, kind)
+		}
+	}
+}
+
+// Extend returns the table of the rules of t preceding the ones of base, so they're applied first
+func (t *WindowsTable) Extend(base *WindowsTable) *WindowsTable {
+	ext := &WindowsTable{vendors: make(map[string]string, len(t.vendors)+len(base.vendors))}
+	for p, v := range base.vendors {
+		ext.vendors[p] = v
+	}
+	for p, v := range t.vendors {
+		ext.vendors[p] = v
+	}
+	ext.products = append(append(ext.products, t.products...), base.products...)
+	return ext
+}
+
+// companySuffixes are the words which end the names of companies, disregarded comparing publishers
+var companySuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "corporation": true, "corp": true, "llc": true, "ltd": true, "limited": true,
+	"gmbh": true, "co": true, "company": true, "ag": true, "sa": true, "bv": true, "plc": true, "srl": true,
+	"oy": true, "ab": true, "llp": true, "sas": true, "kg": true,
+}
+
+// publisherSepRe matches what separates the words of publisher names
+var publisherSepRe = regexp.MustCompile(`[\s,]+`)
+
+// normalizePublisher returns the words of the publisher, e.g. microsoft of Microsoft Corporation,
+// lowercased and separated by spaces, less company suffixes
+func normalizePublisher(s string) string {
+	words := publisherSepRe.Split(strings.ToLower(strings.TrimSpace(s)), -1)
+	for len(words) > 1 {
+		last := strings.Trim(strings.Replace(words[len(words)-1], ".", "", -1), "()")
+		if last != "" && !companySuffixes[last] {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return strings.Trim(strings.Join(words, " "), " .")
+}
+
+// vendor returns the vendor of the publisher
+func (t *WindowsTable) vendor(publisher string) string {
+	p := normalizePublisher(publisher)
+	if v, ok := t.vendors[p]; ok {
+		return v
+	}
+	return strings.Replace(p, " ", "_", -1)
+}
+
+// product returns the vendor and the product of the first rule matching the program
+func (t *WindowsTable) product(p WindowsProgram) (vendor, product string, ok bool) {
+	for _, r := range t.products {
+		s := p.Name
+		if r.id {
+			s = p.ID
+		}
+		if s != "" && r.re.MatchString(s) {
+			return r.vendor, r.product, true
+		}
+	}
+	return "", "", false
+}
+
+var (
+	// windowsVersionRe matches versions in display names, e.g. 22.01 of 7-Zip 22.01 (x64)
+	windowsVersionRe = regexp.MustCompile(`\bv?[0-9]+(\.[0-9]+)+[a-z]?\b`)
+	// windowsNoiseRe matches parts of display names which aren't a part of the product name:
+	// parenthesized remarks, architectures and versions
+	windowsNoiseRe = regexp.MustCompile(`(?i)\([^)]*\)|\[[^]]*\]|\b(x64|x86|amd64|arm64|win64|win32|(32|64)-bit)\b|\bv?[0-9]+(\.[0-9]+)+[a-z]?\b`)
+)
+
+// windowsArchitecture returns the architecture the display name tells
+func windowsArchitecture(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "arm64"):
+		return "arm64"
+	case strings.Contains(name, "x64"), strings.Contains(name, "64-bit"), strings.Contains(name, "amd64"), strings.Contains(name, "win64"):
+		return "x64"
+	case strings.Contains(name, "x86"), strings.Contains(name, "32-bit"), strings.Contains(name, "win32"):
+		return "x86"
+	}
+	return ""
+}
+
+// CPE returns CPE name of the program: the vendor and the product of the rule of the table matching it, or
+// the vendor of the publisher and the display name less the version, architecture and parenthesized remarks
+// (the vendor less too), or the parts of the winget package identifier; the target software is windows
+// and the target hardware is the architecture. The version is mandatory, the display name if it has none.
+func (t *WindowsTable) CPE(p WindowsProgram) (*wfn.Attributes, error) {
+	vendor, product, ok := t.product(p)
+	if !ok {
+		switch {
+		case p.Name != "":
+			if p.Publisher != "" {
+				vendor = t.vendor(p.Publisher)
+			}
+			words := strings.Fields(strings.ToLower(windowsNoiseRe.ReplaceAllString(p.Name, " ")))
+			if len(words) > 1 && vendor != "" && (words[0] == vendor || words[0] == strings.SplitN(normalizePublisher(p.Publisher), " ", 2)[0]) {
+				words = words[1:]
+			}
+			product = strings.Trim(strings.Join(words, "_"), "_-")
+		case p.ID != "":
+			parts := strings.Split(p.ID, ".")
+			vendor = t.vendor(parts[0])
+			if p.Publisher != "" {
+				vendor = t.vendor(p.Publisher)
+			}
+			var words []string
+			for _, part := range parts[1:] {
+				if strings.Trim(part, "0123456789") != "" {
+					words = append(words, strings.ToLower(part))
+				}
+			}
+			if len(words) == 0 {
+				words = []string{strings.ToLower(parts[0])}
+			}
+			product = strings.Join(words, "_")
+		}
+	}
+	name, err := wfn.WFNize(product)
+	if err != nil || name == wfn.Any {
+		return nil, fmt.Errorf("couldn't parse product of Windows program %q", p.Name+p.ID)
+	}
+	if vendor, err = wfn.WFNize(vendor); err != nil {
+		return nil, fmt.Errorf("couldn't parse vendor of Windows program %q: %v", p.Name+p.ID, err)
+	}
+	version := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(p.Version), "<>"))
+	if version == "" || strings.EqualFold(version, "unknown") {
+		version = windowsVersionRe.FindString(p.Name)
+	}
+	ver, err := wfn.WFNize(strings.TrimPrefix(strings.ToLower(version), "v"))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse version of Windows program %q: %v", p.Name+p.ID, err)
+	}
+	if ver == wfn.Any {
+		return nil, fmt.Errorf("no version of Windows program %q found", p.Name+p.ID)
+	}
+	arch := strings.ToLower(p.Architecture)
+	if arch == "" {
+		arch = windowsArchitecture(p.Name)
+	}
+	if arch, err = wfn.WFNize(arch); err != nil {
+		return nil, fmt.Errorf("couldn't parse architecture of Windows program %q: %v", p.Name+p.ID, err)
+	}
+	return &wfn.Attributes{
+		Part:     "a",
+		Vendor:   vendor,
+		Product:  name,
+		Version:  ver,
+		TargetSW: "windows",
+		TargetHW: arch,
+	}, nil
+}
+
+// FromWindowsProgram returns CPE name of the program as per the default table, see WindowsTable.CPE
+func FromWindowsProgram(p WindowsProgram) (*wfn.Attributes, error) {
+	return DefaultWindowsTable().CPE(p)
+}
+
+// decodeWindowsText returns the text less the byte order mark, decoding UTF-16 (e.g. of PowerShell redirections)
+func decodeWindowsText(data []byte) []byte {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return data[3:]
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		bigEndian = true
+	default:
+		return data
+	}
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// ParseWindowsPrograms parses the installed programs of Windows, either the CSV export of the Uninstall keys of
+// the registry or the JSON of winget, see ParseWindowsRegistryCSV and ParseWingetJSON, calling fn for every program.
+// Parsing stops at the first error returned by fn.
+func ParseWindowsPrograms(r io.Reader, fn func(WindowsProgram) error) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data = decodeWindowsText(data)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return parseWingetJSON(data, fn)
+	}
+	return parseWindowsRegistryCSV(data, fn)
+}
+
+// ParseWindowsRegistryCSV parses the CSV export of the Uninstall keys of the registry, with a header of the names
+// of the values, e.g. of
+//
+//	Get-ItemProperty HKLM:\Software\Microsoft\Windows\CurrentVersion\Uninstall\*,
+//	  HKLM:\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\* | Export-Csv programs.csv
+//
+// calling fn for every program. DisplayName, DisplayVersion and Publisher values are used; programs of 32-bit
+// registry view (WOW6432Node in PSPath) are x86 ones unless Architecture is set. The keys without DisplayName,
+// the system components and the updates of other programs (ParentKeyName) are skipped.
+// Parsing stops at the first error returned by fn.
+func ParseWindowsRegistryCSV(r io.Reader, fn func(WindowsProgram) error) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return parseWindowsRegistryCSV(decodeWindowsText(data), fn)
+}
+
+func parseWindowsRegistryCSV(data []byte, fn func(WindowsProgram) error) error {
+	if bytes.HasPrefix(data, []byte("#TYPE")) { // type information of Export-Csv of Windows PowerShell
+		if i := bytes.IndexByte(data, '\n'); i != -1 {
+			data = data[i+1:]
+		}
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("registry export: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["displayname"]; !ok {
+		return fmt.Errorf("registry export: no DisplayName column in header %q", header)
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("registry export: %v", err)
+		}
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if value("displayname") == "" || value("systemcomponent") == "1" || value("parentkeyname") != "" {
+			continue
+		}
+		p := WindowsProgram{
+			Name:         value("displayname"),
+			Publisher:    value("publisher"),
+			Version:      value("displayversion"),
+			Architecture: value("architecture"),
+			Source:       "registry",
+		}
+		if p.Architecture == "" && strings.Contains(strings.ToLower(value("pspath")), `\wow6432node\`) {
+			p.Architecture = "x86"
+		}
+		if err = fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// wingetPackage is a package of winget JSON: of winget export (PackageIdentifier and Version), or of PowerShell
+// objects of Microsoft.WinGet.Client module (Name, Id, InstalledVersion and Source)
+type wingetPackage struct {
+	PackageIdentifier string
+	Version           string
+	Name              string
+	ID                string `json:"Id"`
+	InstalledVersion  string
+	Publisher         string
+	Source            string
+}
+
+// ParseWingetJSON parses the installed packages of winget: the output of winget export, or the JSON list of
+// packages, e.g. of Get-WinGetPackage | ConvertTo-Json, calling fn for every package.
+// Parsing stops at the first error returned by fn.
+func ParseWingetJSON(r io.Reader, fn func(WindowsProgram) error) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return parseWingetJSON(decodeWindowsText(data), fn)
+}
+
+func parseWingetJSON(data []byte, fn func(WindowsProgram) error) error {
+	type source struct {
+		SourceDetails struct{ Name string }
+		Packages      []wingetPackage
+	}
+	var sources []source
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '[' {
+		var pkgs []wingetPackage
+		if err := json.Unmarshal(data, &pkgs); err != nil {
+			return fmt.Errorf("winget: %v", err)
+		}
+		sources = []source{{Packages: pkgs}}
+	} else {
+		var export struct{ Sources []source }
+		if err := json.Unmarshal(data, &export); err != nil {
+			return fmt.Errorf("winget: %v", err)
+		}
+		sources = export.Sources
+	}
+	for _, src := range sources {
+		for _, pkg := range src.Packages {
+			p := WindowsProgram{
+				Name:      pkg.Name,
+				Publisher: pkg.Publisher,
+				Version:   pkg.InstalledVersion,
+				ID:        pkg.ID,
+				Source:    pkg.Source,
+			}
+			if p.Version == "" {
+				p.Version = pkg.Version
+			}
+			if p.ID == "" {
+				p.ID = pkg.PackageIdentifier
+			}
+			if p.Source == "" {
+				p.Source = src.SourceDetails.Name
+			}
+			if p.Source == "" {
+				p.Source = "winget"
+			}
+			if p.Name == "" && p.ID == "" {
+				continue
+			}
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpeparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromWindowsProgram(t *testing.T) {
+	cases := []struct {
+		p    WindowsProgram
+		cpe  string
+		fail bool
+	}{
+		{WindowsProgram{Name: "Mozilla Firefox (x64 en-US)", Publisher: "Mozilla", Version: "118.0.1"}, "cpe:2.3:a:mozilla:firefox:118.0.1:*:*:*:*:windows:x64:*", false},
+		{WindowsProgram{Name: "7-Zip 22.01 (x64)", Publisher: "Igor Pavlov", Version: "22.01"}, "cpe:2.3:a:7-zip:7-zip:22.01:*:*:*:*:windows:x64:*", false},
+		{WindowsProgram{Name: "Notepad++ (64-bit x64)", Publisher: "Notepad++ Team", Version: "8.5.8"}, `cpe:2.3:a:notepad-plus-plus:notepad\+\+:8.5.8:*:*:*:*:windows:x64:*`, false},
+		{WindowsProgram{Name: "Python 3.11.4 (64-bit)", Publisher: "Python Software Foundation", Version: "3.11.4150.0"}, "cpe:2.3:a:python:python:3.11.4150.0:*:*:*:*:windows:x64:*", false},
+		{WindowsProgram{Name: "Acme Widget Pro 2.3", Publisher: "Acme Software, Inc."}, "cpe:2.3:a:acme_software:widget_pro:2.3:*:*:*:*:windows:*:*", false},
+		{WindowsProgram{Name: "Microsoft Visio Viewer", Publisher: "Microsoft Corporation", Version: "16.0.4266.1001", Architecture: "x86"}, "cpe:2.3:a:microsoft:visio_viewer:16.0.4266.1001:*:*:*:*:windows:x86:*", false},
+		{WindowsProgram{ID: "Mozilla.Firefox", Version: "118.0.1"}, "cpe:2.3:a:mozilla:firefox:118.0.1:*:*:*:*:windows:*:*", false},
+		{WindowsProgram{ID: "Acme.Widget.2", Version: "2.0"}, "cpe:2.3:a:acme:widget:2.0:*:*:*:*:windows:*:*", false},
+		{WindowsProgram{Name: "Acme Widget", Publisher: "Acme"}, "", true},
+		{WindowsProgram{Name: "Acme Widget", Version: "Unknown"}, "", true},
+		{WindowsProgram{Version: "1.0"}, "", true},
+	}
+	for _, c := range cases {
+		attr, err := FromWindowsProgram(c.p)
+		if err != nil {
+			if !c.fail {
+				t.Errorf("%+v: unexpected failure: %v", c.p, err)
+			}
+			continue
+		}
+		if c.fail {
+			t.Errorf("%+v: unexpected success", c.p)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%+v: expected %q got %q", c.p, c.cpe, s)
+		}
+	}
+}
+
+func TestWindowsTable(t *testing.T) {
+	if _, err := ParseWindowsTable(strings.NewReader("version,1.0,acme,widget\n")); err == nil {
+		t.Error("unknown kind: unexpected success")
+	}
+	if _, err := ParseWindowsTable(strings.NewReader("name,^Acme,acme\n")); err == nil {
+		t.Error("no product: unexpected success")
+	}
+	user, err := ParseWindowsTable(strings.NewReader("# ours\npublisher,Mozilla,moz,\nname,^Acme Widget\\b,acme,widget\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := user.Extend(DefaultWindowsTable())
+	for _, c := range []struct {
+		p   WindowsProgram
+		cpe string
+	}{
+		{WindowsProgram{Name: "Acme Widget Pro", Publisher: "Widgets Ltd", Version: "1.0"}, "cpe:/a:acme:widget:1.0::~~~windows~~"},
+		{WindowsProgram{Name: "Mozilla Maintenance Service", Publisher: "Mozilla", Version: "118.0.1"}, "cpe:/a:moz:maintenance_service:118.0.1::~~~windows~~"},
+		{WindowsProgram{Name: "Mozilla Firefox", Publisher: "Mozilla", Version: "118.0.1"}, "cpe:/a:mozilla:firefox:118.0.1::~~~windows~~"},
+	} {
+		attr, err := table.CPE(c.p)
+		if err != nil {
+			t.Errorf("%+v: unexpected failure: %v", c.p, err)
+			continue
+		}
+		if s := attr.BindToURI(); s != c.cpe {
+			t.Errorf("%+v: expected %q got %q", c.p, c.cpe, s)
+		}
+	}
+}
+
+func TestParseWindowsPrograms(t *testing.T) {
+	registry := "#TYPE System.Management.Automation.PSCustomObject\n" +
+		`"DisplayName","DisplayVersion","Publisher","SystemComponent","ParentKeyName","PSPath"` + "\n" +
+		`"Mozilla Firefox (x64 en-US)","118.0.1","Mozilla","","","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\Microsoft\Windows\CurrentVersion\Uninstall\Mozilla Firefox"` + "\n" +
+		`"7-Zip 22.01","22.01","Igor Pavlov","","","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip"` + "\n" +
+		`"Microsoft Update Health Tools","3.74.0.0","Microsoft Corporation","1","",""` + "\n" +
+		`"Update for Office","","Microsoft Corporation","","Office16.PROPLUS",""` + "\n" +
+		`"","","","","",""` + "\n"
+	utf16 := []byte{0xff, 0xfe}
+	for _, r := range registry {
+		utf16 = append(utf16, byte(r), 0)
+	}
+	export := `{"Sources":[{"SourceDetails":{"Name":"winget"},"Packages":[{"PackageIdentifier":"Git.Git","Version":"2.42.0.2"}]}]}`
+	list := `[{"Name":"Google Chrome","Id":"Google.Chrome","InstalledVersion":"118.0.5993.71","Source":"winget"},{"Name":"Paint","Id":"9PCFS5B6T72H","InstalledVersion":"11.2309.20.0","Source":"msstore"}]`
+	cases := []struct {
+		in   string
+		want []WindowsProgram
+	}{
+		{registry, []WindowsProgram{
+			{Name: "Mozilla Firefox (x64 en-US)", Publisher: "Mozilla", Version: "118.0.1", Source: "registry"},
+			{Name: "7-Zip 22.01", Publisher: "Igor Pavlov", Version: "22.01", Architecture: "x86", Source: "registry"},
+		}},
+		{string(utf16), []WindowsProgram{
+			{Name: "Mozilla Firefox (x64 en-US)", Publisher: "Mozilla", Version: "118.0.1", Source: "registry"},
+			{Name: "7-Zip 22.01", Publisher: "Igor Pavlov", Version: "22.01", Architecture: "x86", Source: "registry"},
+		}},
+		{export, []WindowsProgram{{ID: "Git.Git", Version: "2.42.0.2", Source: "winget"}}},
+		{list, []WindowsProgram{
+			{Name: "Google Chrome", ID: "Google.Chrome", Version: "118.0.5993.71", Source: "winget"},
+			{Name: "Paint", ID: "9PCFS5B6T72H", Version: "11.2309.20.0", Source: "msstore"},
+		}},
+	}
+	for i, c := range cases {
+		var got []WindowsProgram
+		err := ParseWindowsPrograms(strings.NewReader(c.in), func(p WindowsProgram) error {
+			got = append(got, p)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("case %d: unexpected failure: %v", i, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("case %d: expected %d programs, got %d: %+v", i, len(c.want), len(got), got)
+			continue
+		}
+		for j := range got {
+			if got[j] != c.want[j] {
+				t.Errorf("case %d: expected %+v, got %+v", i, c.want[j], got[j])
+			}
+		}
+	}
+	if err := ParseWindowsPrograms(strings.NewReader("Name,Version\nfoo,1.0\n"), func(WindowsProgram) error { return nil }); err == nil {
+		t.Error("no DisplayName column: unexpected success")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdjson"
+)
+
+// Advisory is an entry of a custom advisory list, see nvdjson.Advisory
+type Advisory = nvdjson.Advisory
+
+// RowError is a bad entry of a custom advisory list
+type RowError struct {
+	Row int // line of CSV, index of JSON array
+	Err error
+}
+
+// Error implements error interface
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// RowErrors lists bad entries of a custom advisory list
+type RowErrors []RowError
+
+// Error implements error interface
+func (errs RowErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return "advisories: " + strings.Join(msgs, "; ")
+}
+
+// ParseAdvisoriesCSV builds the dictionary from a custom advisory list, e.g. internal one, in CSV with a header
+// naming the columns after JSON fields of Advisory, in any order:
+//
+//	id,vendor,product,versionStartIncluding,versionEndExcluding
+//	ACME-1,acme,widget,1.0,1.4.2
+//
+// Lines starting with # are comments. Advisories sharing the ID make one dictionary entry, matching any of them;
+// the entries are matched the same way NVD feed ones are. Bad rows are skipped and reported by RowErrors
+// along with the dictionary of the good ones; other errors fail the parsing.
+func ParseAdvisoriesCSV(in io.Reader) (Dictionary, error) {
+	r := csv.NewReader(in)
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("advisories: failed to read the header: %v", err)
+	}
+	columns := make([]func(*Advisory) *string, len(header))
+	for i, name := range header {
+		if columns[i] = advisoryColumns[strings.TrimSpace(name)]; columns[i] == nil {
+			return nil, fmt.Errorf("advisories: unknown column %q", name)
+		}
+	}
+
+	var advisories []*Advisory
+	var rows []int
+	var bad RowErrors
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		if errors.Is(err, csv.ErrFieldCount) {
+			bad = append(bad, RowError{Row: line, Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("advisories: %v", err)
+		}
+		a := &Advisory{}
+		for i, field := range rec {
+			*columns[i](a) = strings.TrimSpace(field)
+		}
+		advisories = append(advisories, a)
+		rows = append(rows, line)
+	}
+	return buildAdvisories(advisories, rows, bad)
+}
+
+// advisoryColumns maps CSV columns to the fields of Advisory
+var advisoryColumns = map[string]func(*Advisory) *string{
+	"id":                    func(a *Advisory) *string { return &a.ID },
+	"cpe":                   func(a *Advisory) *string { return &a.CPE },
+	"vendor":                func(a *Advisory) *string { return &a.Vendor },
+	"product":               func(a *Advisory) *string { return &a.Product },
+	"versionStartIncluding": func(a *Advisory) *string { return &a.VersionStartIncluding },
+	"versionStartExcluding": func(a *Advisory) *string { return &a.VersionStartExcluding },
+	"versionEndIncluding":   func(a *Advisory) *string { return &a.VersionEndIncluding },
+	"versionEndExcluding":   func(a *Advisory) *string { return &a.VersionEndExcluding },
+}
+
+// ParseAdvisoriesJSON is like ParseAdvisoriesCSV, but the list is a JSON array of Advisory objects
+func ParseAdvisoriesJSON(in io.Reader) (Dictionary, error) {
+	var advisories []*Advisory
+	if err := json.NewDecoder(in).Decode(&advisories); err != nil {
+		return nil, fmt.Errorf("advisories: %v", err)
+	}
+	rows := make([]int, len(advisories))
+	for i := range rows {
+		rows[i] = i
+	}
+	return buildAdvisories(advisories, rows, nil)
+}
+
+// LoadAdvisories parses the custom advisory list from JSON file if the name ends with .json, CSV otherwise;
+// see ParseAdvisoriesCSV
+func LoadAdvisories(path string) (Dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("advisories: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseAdvisoriesJSON(f)
+	}
+	return ParseAdvisoriesCSV(f)
+}
+
+// buildAdvisories returns the dictionary of valid advisories, reporting the invalid ones along with bad
+func buildAdvisories(advisories []*Advisory, rows []int, bad RowErrors) (Dictionary, error) {
+	matches := make(map[string][]*jsonschema.NVDCVEFeedJSON10DefCPEMatch)
+	for i, a := range advisories {
+		if a == nil {
+			bad = append(bad, RowError{Row: rows[i], Err: fmt.Errorf("advisory is null")})
+			continue
+		}
+		m, err := a.CPEMatch()
+		if err != nil {
+			bad = append(bad, RowError{Row: rows[i], Err: err})
+			continue
+		}
+		id := strings.TrimSpace(a.ID)
+		matches[id] = append(matches[id], m)
+	}
+	dict := make(Dictionary, len(matches))
+	for id, ms := range matches {
+		dict[id] = nvdjson.NewAdvisoryItem(id, ms)
+	}
+	if len(bad) != 0 {
+		sort.SliceStable(bad, func(i, j int) bool { return bad[i].Row < bad[j].Row })
+		return dict, bad
+	}
+	return dict, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testAdvisoriesCSV = `# internal advisories
+id,vendor,product,versionStartIncluding,versionEndExcluding,cpe
+ACME-1,acme,widget,1.0,1.4.2,
+ACME-1,,,,,cpe:2.3:a:acme:gadget:2.0:*:*:*:*:*:*:*
+ACME-2,acme,widget,2.0,,
+ACME-3,acme,,1.0,,
+,acme,widget,,,
+ACME-4,acme
+`
+
+func TestParseAdvisoriesCSV(t *testing.T) {
+	dict, err := ParseAdvisoriesCSV(strings.NewReader(testAdvisoriesCSV))
+	bad, ok := err.(RowErrors)
+	if !ok {
+		t.Fatalf("expected bad rows to be reported, got %v", err)
+	}
+	var rows []int
+	for _, e := range bad {
+		rows = append(rows, e.Row)
+	}
+	if expected := []int{6, 7, 8}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected bad rows %v, got %v", expected, rows)
+	}
+	if len(dict) != 2 {
+		t.Fatalf("expected 2 advisories, got %d", len(dict))
+	}
+
+	cache := NewCache(dict)
+	cases := []struct {
+		cpe      string
+		expected []string
+	}{
+		{"cpe:/a:acme:widget:1.2", []string{"ACME-1"}},
+		{"cpe:/a:acme:widget:1.4.2", nil},
+		{"cpe:/a:acme:widget:2.5", []string{"ACME-2"}},
+		{"cpe:/a:acme:gadget:2.0", []string{"ACME-1"}},
+		{"cpe:/a:acme:gadget:2.1", nil},
+		{"cpe:/a:other:widget:1.2", nil},
+	}
+	for _, c := range cases {
+		cpe, err := wfn.Parse(c.cpe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range cache.Get([]*wfn.Attributes{cpe}) {
+			ids = append(ids, r.CVE.CVEID())
+		}
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.cpe, c.expected, ids)
+		}
+	}
+}
+
+func TestParseAdvisoriesCSVErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"id,product,severity\nACME-1,widget,high\n",
+	} {
+		if _, err := ParseAdvisoriesCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected an error", in)
+		} else if _, ok := err.(RowErrors); ok {
+			t.Errorf("%q: expected parsing to fail, got bad rows %v", in, err)
+		}
+	}
+}
+
+func TestParseAdvisoriesJSON(t *testing.T) {
+	in := `[
+		{"id": "ACME-1", "cpe": "cpe:/a:acme:widget", "versionEndIncluding": "1.0"},
+		null,
+		{"id": "ACME-2", "cpe": "cpe:/a:acme:widget:1.0", "versionEndIncluding": "2.0"}
+	]`
+	dict, err := ParseAdvisoriesJSON(strings.NewReader(in))
+	if bad, ok := err.(RowErrors); !ok || len(bad) != 2 || bad[0].Row != 1 || bad[1].Row != 2 {
+		t.Errorf("expected rows 1 and 2 to be reported, got %v", err)
+	}
+	if len(dict) != 1 || dict["ACME-1"] == nil {
+		t.Fatalf("expected ACME-1 only, got %v", dict)
+	}
+	cpe, _ := wfn.Parse("cpe:/a:acme:widget:0.9")
+	if _, ok := Match([]*wfn.Attributes{cpe}, dict["ACME-1"].Config(), false); !ok {
+		t.Error("expected ACME-1 to match widget 0.9")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// VersionComparators selects the comparators the version bounds of CVE configurations (versionStartIncluding,
+// versionEndExcluding etc.) are evaluated with for the matched CPE names, e.g. rpm comparison for RHEL packages,
+// so 2.4.1-3.el8 is within "up to 2.4.1"; see nvdcommon.VersionComparatorByName for the comparators available.
+// Only the rules implementing nvdcommon.VersionCompareTest (the ones of NVD JSON feeds) and the names matched
+// by their own versions use them, the ranges of wildcard versions are compared as usual.
+type VersionComparators struct {
+	// Default is the comparator of the products not in Products, nil keeps the heuristic comparison of the feed
+	Default nvdcommon.VersionComparator
+	// Products are the comparators of the products, by vendor:product or, for all the vendors, product
+	Products map[string]nvdcommon.VersionComparator
+}
+
+// ParseVersionComparators parses comma separated list of versioning schemes, e.g. rpm,mysql:mysql=dotted:
+// a scheme by itself is the default one, the ones prefixed by [vendor:]product= are of the product
+func ParseVersionComparators(spec string) (*VersionComparators, error) {
+	vc := &VersionComparators{Products: map[string]nvdcommon.VersionComparator{}}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		product, scheme := "", field
+		if i := strings.LastIndexByte(field, '='); i >= 0 {
+			product, scheme = strings.ToLower(strings.TrimSpace(field[:i])), strings.TrimSpace(field[i+1:])
+			if product == "" {
+				return nil, fmt.Errorf("version comparators: no product in %q", field)
+			}
+		}
+		cmp, err := nvdcommon.VersionComparatorByName(scheme)
+		if err != nil {
+			return nil, fmt.Errorf("version comparators: %v", err)
+		}
+		if product == "" {
+			vc.Default = cmp
+		} else {
+			vc.Products[product] = cmp
+		}
+	}
+	return vc, nil
+}
+
+// For returns the comparator of the CPE name, nil if it's compared as usual
+func (vc *VersionComparators) For(platform *wfn.Attributes) nvdcommon.VersionComparator {
+	if vc == nil || platform == nil {
+		return nil
+	}
+	product := strings.ToLower(wfn.StripSlashes(platform.Product))
+	if cmp := vc.Products[strings.ToLower(wfn.StripSlashes(platform.Vendor))+":"+product]; cmp != nil {
+		return cmp
+	}
+	if cmp := vc.Products[product]; cmp != nil {
+		return cmp
+	}
+	return vc.Default
+}
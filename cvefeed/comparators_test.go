@@ -0,0 +1,165 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestVersionComparators(t *testing.T) {
+	cases := []struct {
+		name   string
+		cmp    nvdcommon.VersionComparator
+		v1, v2 string
+		want   int
+	}{
+		{"rpm", nvdcommon.CompareRPM, "2.4.1-3.el8", "2.4.1", 0},
+		{"rpm", nvdcommon.CompareRPM, "2.4.1-3.el8", "2.4.1-10.el8", -1},
+		{"rpm", nvdcommon.CompareRPM, "1:1.0", "2.4.1", 1},
+		{"rpm", nvdcommon.CompareRPM, "1.0~rc1", "1.0", -1},
+		{"rpm", nvdcommon.CompareRPM, "1.0^git1", "1.0", 1},
+		{"rpm", nvdcommon.CompareRPM, "1.0^git1", "1.0.1", -1},
+		{"rpm", nvdcommon.CompareRPM, "1.0a", "1.0.1", -1},
+		{"rpm", nvdcommon.CompareRPM, "1.010", "1.9", 1},
+		{"deb", nvdcommon.CompareDeb, "1.2.3-1ubuntu1", "1.2.3", 0},
+		{"deb", nvdcommon.CompareDeb, "1.2.3-1ubuntu1", "1.2.3-1ubuntu2", -1},
+		{"deb", nvdcommon.CompareDeb, "1.0~beta1", "1.0", -1},
+		{"deb", nvdcommon.CompareDeb, "1.0+dfsg", "1.0", 1},
+		{"deb", nvdcommon.CompareDeb, "1.0a", "1.0+", -1},
+		{"deb", nvdcommon.CompareDeb, "2:0.9", "1:3.0", 1},
+		{"apk", nvdcommon.CompareAPK, "1.1.1k-r0", "1.1.1k", 0},
+		{"apk", nvdcommon.CompareAPK, "1.1.1k-r0", "1.1.1k-r10", -1},
+		{"apk", nvdcommon.CompareAPK, "1.1.1k", "1.1.1l", -1},
+		{"apk", nvdcommon.CompareAPK, "1.1.1", "1.1.1a", -1},
+		{"apk", nvdcommon.CompareAPK, "1.2", "1.2.1", -1},
+		{"apk", nvdcommon.CompareAPK, "1.0_rc1", "1.0", -1},
+		{"apk", nvdcommon.CompareAPK, "1.0_alpha2", "1.0_beta1", -1},
+		{"apk", nvdcommon.CompareAPK, "1.0_p1", "1.0", 1},
+		{"apk", nvdcommon.CompareAPK, "1.0_git20230101", "1.0_p1", -1},
+		{"apk", nvdcommon.CompareAPK, "2.10-r1", "2.9-r3", 1},
+		{"semver", purl.CompareSemver, "1.0.0-alpha", "1.0.0", -1},
+		{"semver", purl.CompareSemver, "v1.10.0", "1.9.0", 1},
+		{"dotted", nvdcommon.CompareDotted, "2.4", "2.4.0", 0},
+		{"dotted", nvdcommon.CompareDotted, "2.10", "2.9", 1},
+	}
+	for _, c := range cases {
+		if got := c.cmp(c.v1, c.v2); got != c.want {
+			t.Errorf("%s: %s vs %s: want %d, got %d", c.name, c.v1, c.v2, c.want, got)
+		}
+		if got := c.cmp(c.v2, c.v1); got != -c.want {
+			t.Errorf("%s: %s vs %s: want %d, got %d", c.name, c.v2, c.v1, -c.want, got)
+		}
+	}
+}
+
+func TestParseVersionComparators(t *testing.T) {
+	vc, err := ParseVersionComparators("rpm, mysql:mysql=dotted,node=semver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pick := func(vendor, product string) string {
+		cmp := vc.For(&wfn.Attributes{Part: "a", Vendor: vendor, Product: product})
+		// 1.0-rc1 is a pre-release of 1.0 for semver, a release of 1.0 for rpm and a later version for dotted
+		switch cmp("1.0-rc1", "1.0") {
+		case -1:
+			return "semver"
+		case 0:
+			return "rpm"
+		}
+		return "dotted"
+	}
+	for _, c := range []struct{ vendor, product, want string }{
+		{"mysql", "mysql", "dotted"},
+		{"oracle", "mysql", "rpm"},
+		{"nodejs", "node", "semver"},
+		{"redhat", "openssl", "rpm"},
+	} {
+		if got := pick(c.vendor, c.product); got != c.want {
+			t.Errorf("%s:%s: want %s comparator, got %s", c.vendor, c.product, c.want, got)
+		}
+	}
+
+	for _, spec := range []string{"rpmx", "=rpm", "foo=bar"} {
+		if _, err := ParseVersionComparators(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestMatchVersionComparators(t *testing.T) {
+	items, err := ParseJSON(strings.NewReader(testJSONdictComparators))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := Dictionary{}
+	for _, item := range items {
+		dict[item.CVEID()] = item
+	}
+	rpm, err := ParseVersionComparators("redhat:openssl=rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		version     string
+		comparators *VersionComparators
+		want        bool
+	}{
+		{"2.4.1-3.el8", nil, false},
+		{"2.4.1-3.el8", rpm, true},
+		{"2.4.0", rpm, true},
+		{"2.4.2-1.el8", rpm, false},
+		{"1:2.0", rpm, false},
+	}
+	for _, c := range cases {
+		for _, target := range []bool{false, true} {
+			cpe := &wfn.Attributes{Part: "a", Vendor: "redhat", Product: "openssl", Version: c.version}
+			var results []MatchResult
+			if target {
+				results = NewCompiledTarget([]*wfn.Attributes{cpe}).SetVersionComparators(c.comparators).Match(dict)
+			} else {
+				results = NewCache(dict).SetVersionComparators(c.comparators).Get([]*wfn.Attributes{cpe})
+			}
+			if got := len(results) != 0; got != c.want {
+				t.Errorf("%s (rpm %t, target %t): want match %t, got %t", c.version, c.comparators != nil, target, c.want, got)
+			}
+		}
+	}
+}
+
+var testJSONdictComparators = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [{
+  "cve" : {"data_type" : "CVE", "data_format" : "MITRE", "data_version" : "4.0", "CVE_data_meta" : {"ID" : "CVE-2020-0100"}},
+  "configurations" : {
+    "CVE_data_version" : "4.0",
+    "nodes" : [{
+      "operator" : "OR",
+      "cpe_match" : [{
+        "vulnerable" : true,
+        "cpe23Uri" : "cpe:2.3:a:redhat:openssl:*:*:*:*:*:*:*:*",
+        "versionStartIncluding" : "2.0",
+        "versionEndIncluding" : "2.4.1"
+      }]
+    }]
+  }
+}]
+}`
@@ -16,12 +16,16 @@ package cvefeed
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"sort"
 	"sync"
+	"time"
 	"unsafe"
 
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
 	"github.com/facebookincubator/nvdtools/wfn"
-	"github.com/golang/glog"
 )
 
 const cacheEvictPercentage = 0.1 // every eviction cycle invalidates this part of cache size at once
@@ -33,27 +37,37 @@ type Index map[string][]CVEItem
 func NewIndex(d Dictionary) Index {
 	idx := Index{}
 	for _, entry := range d {
-		set := map[string]bool{}
-		for _, cpe := range collectCPEs(entry.Config()) {
-			// Can happen, for instance, when the feed contains illegal binding of CPE name. Unfortunately, it happens to NVD,
-			// e.g. embedded ? in cpe:2.3:a:disney:where\\'s_my_perry?_free:1.5.1:*:*:*:*:android:*:* of CVE-2014-5606
-			if cpe == nil {
-				continue
-			}
-			product := cpe.Product
-			if product == wfn.Any || wfn.HasWildcard(product) {
-				set[wfn.Any] = true
-				continue
-			}
-			set[product] = true
-		}
-		for product := range set {
+		for _, product := range indexedProducts(entry) {
 			idx[product] = append(idx[product], entry)
 		}
 	}
 	return idx
 }
 
+// indexedProducts returns the products the CVE is indexed by: the products of CPE names of its configuration,
+// wfn.Any for the ones which match any product
+func indexedProducts(cve CVEItem) []string {
+	set := map[string]bool{}
+	for _, cpe := range collectCPEs(cve.Config()) {
+		// Can happen, for instance, when the feed contains illegal binding of CPE name. Unfortunately, it happens to NVD,
+		// e.g. embedded ? in cpe:2.3:a:disney:where\\'s_my_perry?_free:1.5.1:*:*:*:*:android:*:* of CVE-2014-5606
+		if cpe == nil {
+			continue
+		}
+		product := cpe.Product
+		if product == wfn.Any || wfn.HasWildcard(product) {
+			set[wfn.Any] = true
+			continue
+		}
+		set[product] = true
+	}
+	products := make([]string, 0, len(set))
+	for product := range set {
+		products = append(products, product)
+	}
+	return products
+}
+
 func collectCPEs(dict []LogicalTest) (cpes []*wfn.Attributes) {
 	for _, d := range dict {
 		for _, cpe := range d.CPEs() {
@@ -70,11 +84,47 @@ func collectCPEs(dict []LogicalTest) (cpes []*wfn.Attributes) {
 type MatchResult struct {
 	CVE  CVEItem
 	CPEs []*wfn.Attributes
+	// FixedIn holds the versions CPEs were fixed in, aligned with CPEs; empty string means the fix is unknown.
+	// It is nil when none of the fixes are known.
+	FixedIn []string
+	// Platform tells, aligned with CPEs, which CPEs only satisfied platform constraints of the configuration
+	// (marked as not vulnerable, e.g. the OS in "app X running on OS Y") rather than being vulnerable themselves.
+	// It is nil when all CPEs are vulnerable or the feed doesn't tell them apart.
+	Platform []bool
+	// Rescored holds the severity assigned to the finding by an exception, nil unless rescored; see Exceptions
+	Rescored *cvss.Severity
+	// KnownExploited tells the CVE is listed in the known exploited vulnerabilities catalog; see KEV
+	KnownExploited bool
+	// PURL is the package URL the CVE matched by its affected packages, empty for matches of CPE names; see GetPURL
+	PURL string
+	// Annotations are the suppression rules annotating the finding rather than suppressing it; see Suppressions
+	Annotations []Annotation
+}
+
+// VulnerableCPEs returns the matched CPEs which are vulnerable
+func (r MatchResult) VulnerableCPEs() []*wfn.Attributes {
+	return r.cpes(false)
+}
+
+// PlatformCPEs returns the matched CPEs the vulnerable ones had to run on, see Platform
+func (r MatchResult) PlatformCPEs() []*wfn.Attributes {
+	return r.cpes(true)
+}
+
+func (r MatchResult) cpes(platform bool) []*wfn.Attributes {
+	var cpes []*wfn.Attributes
+	for i, cpe := range r.CPEs {
+		if (r.Platform != nil && r.Platform[i]) == platform {
+			cpes = append(cpes, cpe)
+		}
+	}
+	return cpes
 }
 
 // cachedCVEs stores cached CVEs, a channel to signal if the value is ready
 type cachedCVEs struct {
 	res           []MatchResult
+	errs          []*EvalError // CVEs skipped computing res, see GetWithErrors
 	ready         chan struct{}
 	size          int64
 	evictionIndex int // position in eviction queue
@@ -88,7 +138,11 @@ func (cves *cachedCVEs) updateResSize(key string) {
 	}
 	cves.size += int64(unsafe.Sizeof(cves.res))
 	for i := range cves.res {
-		cves.size += int64(unsafe.Sizeof(cves.res[i].CVE))
+		cves.size += int64(unsafe.Sizeof(cves.res[i].CVE)) + int64(unsafe.Sizeof(cves.res[i].Rescored))
+		cves.size += int64(unsafe.Sizeof(cves.res[i].Platform)) + int64(len(cves.res[i].Platform))
+		for _, v := range cves.res[i].FixedIn {
+			cves.size += int64(len(v)) + int64(unsafe.Sizeof(v))
+		}
 		for _, attr := range cves.res[i].CPEs {
 			cves.size += int64(len(attr.Part)) + int64(unsafe.Sizeof(attr.Part))
 			cves.size += int64(len(attr.Vendor)) + int64(unsafe.Sizeof(attr.Vendor))
@@ -106,14 +160,39 @@ func (cves *cachedCVEs) updateResSize(key string) {
 
 // Cache caches CVEs for known CPEs
 type Cache struct {
-	data           map[string]*cachedCVEs
-	evictionQ      *evictionQueue
-	mu             sync.Mutex
-	Dict           Dictionary
-	Idx            Index
-	RequireVersion bool  // ignore matching specifications that have Version == ANY
-	MaxSize        int64 // maximum size of the cache, 0 -- unlimited, -1 -- no caching
-	size           int64 // current size of the cache
+	data             map[string]*cachedCVEs
+	evictionQ        *evictionQueue
+	mu               sync.Mutex
+	Dict             Dictionary
+	Idx              Index
+	Source           CVESource           // if set, CVEs are taken from the source rather than from Dict or Idx
+	RequireVersion   bool                // ignore matching specifications that have Version == ANY
+	WildcardVersions bool                // match versions like 2.4.* as ranges of versions, see SetWildcardVersions
+	Comparators      *VersionComparators // comparators of the version bounds of CVEs, see SetVersionComparators
+	MatchOptions     wfn.MatchOptions    // semantics of matching CPE names, see SetMatchOptions
+	MaxSize          int64               // maximum size of the cache, 0 -- unlimited, -1 -- no caching
+	Limit            int                 // maximum number of results returned by GetLimited, 0 -- unlimited
+	Order            ResultOrder         // order of the results returned by GetLimited, decides which are kept under the Limit
+	RecoverPanics    bool                // skip CVEs whose evaluation panics instead of crashing, see Skipped
+	PublishedAfter   time.Time           // if set, CVEs published before are skipped, see SetPublishedAfter
+	IncludeUndated   bool                // don't skip CVEs of unknown publication date when PublishedAfter is set
+	Logger           Logger              // if not set, the logger of the package is used, see SetLogger
+	Workers          int                 // number of goroutines MatchStream matches CVEs with, 0 -- GOMAXPROCS
+	size             int64               // current size of the cache
+	skipped          map[string]*EvalError
+	ranges           map[*wfn.Attributes]versionRange // precomputed wildcard version ranges, see CompiledTarget
+	purlsOnce        sync.Once
+	purls            packageIndex // affected packages of Dict by package, see GetPURL
+}
+
+// EvalError reports a CVE skipped because evaluating its configuration panicked, see Cache.SetRecoverPanics
+type EvalError struct {
+	CVEID string
+	Panic interface{} // the recovered value
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("%s: evaluation of configuration panicked: %v", e.CVEID, e.Panic)
 }
 
 // NewCache creates new Cache instance with dictionary dict.
@@ -129,6 +208,32 @@ func (c *Cache) SetRequireVersion(requireVersion bool) *Cache {
 	return c
 }
 
+// SetWildcardVersions sets if the instance of cache matches CPE names whose version ends with a wildcard
+// component, e.g. 2.4.*, as the range of versions the wildcard covers, [2.4.0, 2.5.0), so they match
+// the CVEs affecting any of these versions, see MatchWildcardVersions. Without it such versions are
+// WFN patterns, which don't match version ranges meaningfully.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetWildcardVersions(wildcardVersions bool) *Cache {
+	c.WildcardVersions = wildcardVersions
+	return c
+}
+
+// SetVersionComparators sets the comparators of versions the version bounds of CVEs are evaluated with, e.g. rpm ones
+// for the products of RPM based distributions; nil compares the versions as usual, see VersionComparators.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetVersionComparators(vc *VersionComparators) *Cache {
+	c.Comparators = vc
+	return c
+}
+
+// SetMatchOptions sets the semantics of matching CPE names of the dictionary, e.g. if target_sw and target_hw attributes
+// must match or versions must be equal rather than in range; the zero value matches as usual, see wfn.MatchOptions.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetMatchOptions(opts wfn.MatchOptions) *Cache {
+	c.MatchOptions = opts
+	return c
+}
+
 // SetMaxSize sets maximum size of the cache to some pre-defined value,
 // size of 0 disables eviction (makes the cache grow indefinitely),
 // negative size disables caching.
@@ -138,15 +243,135 @@ func (c *Cache) SetMaxSize(size int64) *Cache {
 	return c
 }
 
+// SetSource sets the source of CVEs to match against instead of the dictionary, see CVESource.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetSource(src CVESource) *Cache {
+	c.Source = src
+	return c
+}
+
+// SetLimit sets the maximum number of results returned by GetLimited and their order,
+// which decides the results kept under the limit; limit of 0 removes the limit.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetLimit(limit int, order ResultOrder) *Cache {
+	c.Limit = limit
+	c.Order = order
+	return c
+}
+
+// SetRecoverPanics sets if a panic evaluating a CVE (e.g. caused by a malformed feed record) is recovered from:
+// the CVE is logged and skipped rather than aborting the whole scan, see Skipped.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetRecoverPanics(recoverPanics bool) *Cache {
+	c.RecoverPanics = recoverPanics
+	return c
+}
+
+// SetLogger sets the logger of the cache's diagnostics, e.g. the CVEs skipped; nil falls back to the logger
+// of the package, see SetLogger function.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetLogger(l Logger) *Cache {
+	c.Logger = l
+	return c
+}
+
+// SetWorkers sets the number of goroutines MatchStream matches CVEs with; non-positive number uses GOMAXPROCS.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetWorkers(n int) *Cache {
+	c.Workers = n
+	return c
+}
+
+// log returns the logger of the cache
+func (c *Cache) log() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return getLogger()
+}
+
+// SetPublishedAfter sets the cutoff of matching: CVEs published before t aren't matched at all.
+// CVEs whose publication date is missing or doesn't parse are skipped too, unless includeUndated is true;
+// zero t removes the cutoff. It must be set before the first lookup, the cached results don't depend on it.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetPublishedAfter(t time.Time, includeUndated bool) *Cache {
+	c.PublishedAfter = t
+	c.IncludeUndated = includeUndated
+	return c
+}
+
+// admit returns true if cve passes the cutoff by publication date, see SetPublishedAfter
+func (c *Cache) admit(cve CVEItem) bool {
+	if c.PublishedAfter.IsZero() {
+		return true
+	}
+	var published time.Time
+	switch dates := cve.(type) {
+	case nvdcommon.StrictCVEDates:
+		published, _ = dates.PublishedStrict()
+	case nvdcommon.CVEDates:
+		published = dates.Published()
+	}
+	if published.IsZero() {
+		return c.IncludeUndated
+	}
+	return !published.Before(c.PublishedAfter)
+}
+
+// Skipped returns the errors of CVEs skipped so far because their evaluation panicked, sorted by CVE ID:
+// the aggregate of all the queries, GetWithErrors tells the ones of a query. Only populated if RecoverPanics is set.
+func (c *Cache) Skipped() []*EvalError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := make([]*EvalError, 0, len(c.skipped))
+	for _, err := range c.skipped {
+		errs = append(errs, err)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].CVEID < errs[j].CVEID })
+	return errs
+}
+
+// eval calls fn evaluating CVE id; if RecoverPanics is set, a panic in fn is logged, recorded in Skipped
+// and returned as the error
+func (c *Cache) eval(id string, fn func()) (err *EvalError) {
+	if c.RecoverPanics {
+		defer func() {
+			if p := recover(); p != nil {
+				err = &EvalError{CVEID: id, Panic: p}
+				c.log().Warnf("skipping CVE: %v", err)
+				c.mu.Lock()
+				if c.skipped == nil {
+					c.skipped = make(map[string]*EvalError)
+				}
+				c.skipped[id] = err
+				c.mu.Unlock()
+			}
+		}()
+	}
+	fn()
+	return nil
+}
+
+// GetLimited is like Get, but returns at most c.Limit results sorted in c.Order (see LimitResults)
+// and whether some results were left out.
+func (c *Cache) GetLimited(cpes []*wfn.Attributes) (results []MatchResult, truncated bool) {
+	return LimitResults(c.Get(cpes), c.Limit, c.Order)
+}
+
 // Get returns slice of CVEs for CPE names from cpes parameter;
 // if CVEs aren't cached (and the feature is enabled) it finds them in cveDict and caches the results
 func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
+	res, _ := c.GetWithErrors(cpes)
+	return res
+}
+
+// GetWithErrors is like Get, but also returns the errors of the CVEs left out of the results because their
+// evaluation panicked (see SetRecoverPanics), sorted by CVE ID; the errors are cached along with the results,
+// so the results found in the cache come with the errors of the query which computed them.
+func (c *Cache) GetWithErrors(cpes []*wfn.Attributes) ([]MatchResult, []*EvalError) {
 	// negative max size of the cache disables caching
 	if c.MaxSize < 0 {
-		if c.Idx == nil {
-			return c.match(cpes, c.Dict)
-		}
-		return c.match(cpes, c.dictFromIndex(cpes))
+		return c.match(cpes, c.candidates(cpes))
 	}
 
 	// otherwise, let's get to the business
@@ -163,18 +388,14 @@ func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
 		c.mu.Lock() // TODO: XXX: ugly, consider using atomic.Value instead
 		cves.evictionIndex = c.evictionQ.touch(cves.evictionIndex)
 		c.mu.Unlock()
-		return cves.res
+		return cves.res, cves.errs
 	}
 	// first request; the goroutine that sent it computes the value
 	cves = &cachedCVEs{ready: make(chan struct{})}
 	c.data[key] = cves
 	c.mu.Unlock()
 	// now other requests for same key wait on the channel, and the requests for the different keys aren't blocked
-	if c.Idx == nil {
-		cves.res = c.match(cpes, c.Dict)
-	} else {
-		cves.res = c.match(cpes, c.dictFromIndex(cpes))
-	}
+	cves.res, cves.errs = c.match(cpes, c.candidates(cpes))
 	cves.updateResSize(key)
 	c.mu.Lock()
 	c.size += cves.size
@@ -184,7 +405,163 @@ func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
 	cves.evictionIndex = c.evictionQ.push(key)
 	c.mu.Unlock()
 	close(cves.ready)
-	return cves.res
+	return cves.res, cves.errs
+}
+
+// MatchIDs returns sorted IDs of CVEs matching CPE names from cpes parameter.
+// It is a lightweight alternative to Get: neither the match results are assembled, nor cached,
+// though the cached results are reused if available.
+func (c *Cache) MatchIDs(cpes ...*wfn.Attributes) []string {
+	if res, ok := c.cached(cpes); ok {
+		ids := make([]string, len(res))
+		for i, r := range res {
+			ids[i] = r.CVE.CVEID()
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	var ids []string
+	for id, v := range c.candidates(cpes) {
+		if !c.admit(v) {
+			continue
+		}
+		c.eval(id, func() {
+			if _, ok := c.matcher().match(cpes, v.Config()); ok {
+				ids = append(ids, id)
+			}
+		})
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// cached returns the results of cpes cached by Get, waiting for them if they're being computed
+func (c *Cache) cached(cpes []*wfn.Attributes) ([]MatchResult, bool) {
+	res, ok, _ := c.cachedContext(context.Background(), cpes)
+	return res, ok
+}
+
+// cachedContext is like cached, but stops waiting for the results being computed when ctx is done,
+// returning ctx.Err()
+func (c *Cache) cachedContext(ctx context.Context, cpes []*wfn.Attributes) ([]MatchResult, bool, error) {
+	if c.MaxSize < 0 {
+		return nil, false, nil
+	}
+	c.mu.Lock()
+	cves := c.data[cacheKey(cpes)]
+	c.mu.Unlock()
+	if cves == nil {
+		return nil, false, nil
+	}
+	select {
+	case <-cves.ready:
+		return cves.res, true, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// VersionMatch is a match of one of the candidate versions of a component, see Cache.MatchAnyVersion
+type VersionMatch struct {
+	MatchResult
+	// Versions lists the candidate versions which matched the CVE in the order they were given, aligned with CPEs
+	Versions []string
+}
+
+// MatchAnyVersion matches base CPE name with each of the candidate versions, for components whose version
+// isn't known exactly, and returns the union of the matches sorted by CVE ID; each result lists the versions
+// which triggered it, its CPEs are base with those versions.
+// Versions are plain strings (e.g. 1.2.3) quoted with wfn.WFNize, the ones which can't be quoted are skipped.
+// The CVEs to match are looked up once for all the versions; the results aren't cached.
+func (c *Cache) MatchAnyVersion(base *wfn.Attributes, versions []string) []VersionMatch {
+	if base == nil {
+		return nil
+	}
+	cpes := make([]*wfn.Attributes, 0, len(versions))
+	valid := make([]string, 0, len(versions))
+	for _, v := range versions {
+		quoted, err := wfn.WFNize(v)
+		if err != nil {
+			c.log().Warnf("skipping version %q: %v", v, err)
+			continue
+		}
+		cpe := *base
+		cpe.Version = quoted
+		cpes = append(cpes, &cpe)
+		valid = append(valid, v)
+	}
+	anyVersion := *base
+	anyVersion.Version = wfn.Any
+	var results []VersionMatch
+	for id, cve := range c.candidates([]*wfn.Attributes{&anyVersion}) {
+		if !c.admit(cve) {
+			continue
+		}
+		var vm VersionMatch
+		err := c.eval(id, func() {
+			for i, cpe := range cpes {
+				if _, ok := c.matcher().match([]*wfn.Attributes{cpe}, cve.Config()); ok {
+					vm.CPEs = append(vm.CPEs, cpe)
+					vm.Versions = append(vm.Versions, valid[i])
+				}
+			}
+			if len(vm.CPEs) != 0 {
+				vm.CVE = cve
+				vm.FixedIn = fixedIn(cve.Config(), vm.CPEs)
+			}
+		})
+		if err == nil && vm.CVE != nil {
+			results = append(results, vm)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CVE.CVEID() < results[j].CVE.CVEID()
+	})
+	return results
+}
+
+// GetSoft is like Get, but NA attributes of cpes are treated as ANY (see wfn.Attributes.Soften),
+// so CPE names of scanners which report NA for unknown attributes match the entries specifying them.
+// Get follows the specification and should be preferred when the inventory is accurate.
+// The results refer to the elements of cpes, not to their softened copies.
+func (c *Cache) GetSoft(cpes []*wfn.Attributes) []MatchResult {
+	soft := make([]*wfn.Attributes, len(cpes))
+	orig := make(map[wfn.Attributes]*wfn.Attributes, len(cpes))
+	for i, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		soft[i] = cpe.Soften()
+		if _, ok := orig[*soft[i]]; !ok {
+			orig[*soft[i]] = cpe
+		}
+	}
+	// cached results are shared, so they're copied rather than amended
+	results := c.Get(soft)
+	out := make([]MatchResult, len(results))
+	for i, r := range results {
+		out[i] = r
+		out[i].CPEs = make([]*wfn.Attributes, len(r.CPEs))
+		for j, cpe := range r.CPEs {
+			if cpe != nil && orig[*cpe] != nil {
+				cpe = orig[*cpe]
+			}
+			out[i].CPEs[j] = cpe
+		}
+	}
+	return out
+}
+
+// candidates returns the CVEs to match cpes against: from Source if set, from Idx if set, Dict otherwise
+func (c *Cache) candidates(cpes []*wfn.Attributes) Dictionary {
+	switch {
+	case c.Source != nil:
+		return dictFromSource(c.Source, cpes, c.log())
+	case c.Idx != nil:
+		return c.dictFromIndex(cpes)
+	default:
+		return c.Dict
+	}
 }
 
 // dictFromIndex creates CVE dictionary from entries indexed by CPE names
@@ -196,7 +573,7 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 	knownEntries := map[CVEItem]bool{}
 	for _, cpe := range cpes {
 		if cpe == nil { // should never happen
-			glog.Warning("nil CPE in list")
+			c.log().Warnf("nil CPE in list")
 			continue
 		}
 		product := cpe.Product
@@ -224,15 +601,105 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 	return d
 }
 
-// match matches the CPE names against internal vulnerability dictionary and returns a slice of matching resutls
-func (c *Cache) match(cpes []*wfn.Attributes, dict Dictionary) (result []MatchResult) {
-	for _, v := range dict {
-		if mm, ok := Match(cpes, v.Config(), c.RequireVersion); ok {
+// match matches the CPE names against internal vulnerability dictionary and returns a slice of matching resutls,
+// along with the errors of the CVEs skipped, sorted by CVE ID
+func (c *Cache) match(cpes []*wfn.Attributes, dict Dictionary) (result []MatchResult, errs []*EvalError) {
+	for id, v := range dict {
+		r, ok, err := c.matchCVE(cpes, id, v)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].CVEID < errs[j].CVEID })
+	return result, errs
+}
+
+// matcher returns the matcher of the matching modes of the cache
+func (c *Cache) matcher() matcher {
+	return matcher{requireVersion: c.RequireVersion, wildcardVersions: c.WildcardVersions, ranges: c.ranges, comparators: c.Comparators,
+		opts: c.MatchOptions}
+}
+
+// matchCVE matches the CPE names against CVE v of the dictionary; the error tells the evaluation panicked
+func (c *Cache) matchCVE(cpes []*wfn.Attributes, id string, v CVEItem) (result MatchResult, matched bool, err *EvalError) {
+	if !c.admit(v) {
+		return result, false, nil
+	}
+	err = c.eval(id, func() {
+		if mm, ok := c.matcher().match(cpes, v.Config()); ok {
 			mm = uniq(mm)
-			result = append(result, MatchResult{v, mm})
+			result = MatchResult{
+				CVE:      v,
+				CPEs:     mm,
+				FixedIn:  fixedIn(v.Config(), mm),
+				Platform: platforms(v.Config(), mm, c.matcher()),
+			}
+			matched = true
+		}
+	})
+	return result, matched, err
+}
+
+// fixedIn returns the versions the matched CPEs were fixed in, if provided by the feed
+func fixedIn(tests []LogicalTest, cpes []*wfn.Attributes) []string {
+	var fixed []string
+	for i, cpe := range cpes {
+		if v := findFixedIn(tests, cpe); v != "" {
+			if fixed == nil {
+				fixed = make([]string, len(cpes))
+			}
+			fixed[i] = v
+		}
+	}
+	return fixed
+}
+
+func findFixedIn(tests []LogicalTest, cpe *wfn.Attributes) string {
+	for _, t := range tests {
+		if ft, ok := t.(nvdcommon.FixedVersionTest); ok {
+			if v := ft.FixedIn(cpe); v != "" {
+				return v
+			}
+		}
+		if v := findFixedIn(t.InnerTests(), cpe); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// platforms tells, aligned with cpes, which of the matched CPEs only satisfied platform constraints
+func platforms(tests []LogicalTest, cpes []*wfn.Attributes, m matcher) []bool {
+	var platform []bool
+	for i, cpe := range cpes {
+		if vulnerable, isPlatform := matchRole(tests, cpe, m); isPlatform && !vulnerable {
+			if platform == nil {
+				platform = make([]bool, len(cpes))
+			}
+			platform[i] = true
+		}
+	}
+	return platform
+}
+
+// matchRole reports whether the tests match cpe as a vulnerable component and as a platform;
+// tests which don't implement nvdcommon.VulnerableTest are assumed to match vulnerable components
+func matchRole(tests []LogicalTest, cpe *wfn.Attributes, m matcher) (vulnerable, platform bool) {
+	for _, t := range tests {
+		if m.matchPlatform(t, cpe) {
+			if vt, ok := t.(nvdcommon.VulnerableTest); ok && !m.matchVulnerable(vt, t, cpe) {
+				platform = true
+			} else {
+				vulnerable = true
+			}
 		}
+		v, p := matchRole(t.InnerTests(), cpe, m)
+		vulnerable, platform = vulnerable || v, platform || p
 	}
-	return result
+	return vulnerable, platform
 }
 
 // evict the least recently used records untile nbytes of capacity is achieved or no more records left.
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
@@ -48,7 +49,10 @@ type CVEItem = nvdcommon.CVEItem
 // }
 type LogicalTest = nvdcommon.LogicalTest
 
-// ParseJSON loads CVE feed from JSON
+// MatchCriteria maps NVD 2.0 matchCriteriaId to the match criteria it identifies
+type MatchCriteria = nvdjson.MatchCriteria
+
+// ParseJSON loads CVE feed from JSON: NVD 1.x feed, NVD CVE API 2.0 response or CVE JSON 5.x record (cve.org)
 func ParseJSON(in io.Reader) ([]CVEItem, error) {
 	feed, err := setupReader(in)
 	if err != nil {
@@ -58,6 +62,62 @@ func ParseJSON(in io.Reader) ([]CVEItem, error) {
 	return nvdjson.Parse(feed)
 }
 
+// ParseJSONWithOptions is like ParseJSON, but the feed is parsed as per opts, e.g. to score CVEs by the highest
+// of CVSS assessments of several sources or to transform them as they're parsed, see nvdjson.ParseOptions
+func ParseJSONWithOptions(in io.Reader, opts nvdjson.ParseOptions) ([]CVEItem, error) {
+	feed, err := setupReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("cvefeed.ParseJSONWithOptions: read error: %v", err)
+	}
+	defer feed.Close()
+	return nvdjson.ParseWithOptions(feed, opts)
+}
+
+// ParseJSONStream is like ParseJSONWithOptions, but CVEs are passed to emit one at a time as they're decoded,
+// so the feed is never held in memory as a whole; see nvdjson.ParseStream
+func ParseJSONStream(in io.Reader, opts nvdjson.ParseOptions, emit func(CVEItem) error) error {
+	feed, err := setupReader(in)
+	if err != nil {
+		return fmt.Errorf("cvefeed.ParseJSONStream: read error: %v", err)
+	}
+	defer feed.Close()
+	return nvdjson.ParseStream(feed, opts, emit)
+}
+
+// LoadByIDs parses CVE feed JSON (NVD 1.x or CVE API 2.0, plain or gzip'ed) retaining only the CVEs of ids,
+// e.g. to re-analyze a handful of CVEs of yearly feeds without loading them in full: CVEs are decoded one at a time
+// and the rest of the feed is skipped once all of ids are found, see nvdjson.ParseByIDs
+func LoadByIDs(in io.Reader, ids map[string]bool) ([]CVEItem, error) {
+	feed, err := setupReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("cvefeed.LoadByIDs: read error: %v", err)
+	}
+	defer feed.Close()
+	return nvdjson.ParseByIDs(feed, ids)
+}
+
+// ValidateJSON checks the structure of CVE feed JSON against NVD feed schema (1.1 or 2.0);
+// the structural problems found are reported as nvdjson.ValidationErrors.
+// It is considerably slower than ParseJSON, so meant to be used before ingesting feeds of unknown quality.
+func ValidateJSON(in io.Reader) error {
+	feed, err := setupReader(in)
+	if err != nil {
+		return fmt.Errorf("cvefeed.ValidateJSON: read error: %v", err)
+	}
+	defer feed.Close()
+	return nvdjson.Validate(feed)
+}
+
+// ParseMatchCriteria loads NVD CPE match criteria feed from JSON
+func ParseMatchCriteria(in io.Reader) (MatchCriteria, error) {
+	feed, err := setupReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("cvefeed.ParseMatchCriteria: read error: %v", err)
+	}
+	defer feed.Close()
+	return nvdjson.ParseMatchCriteria(feed)
+}
+
 func setupReader(in io.Reader) (src io.ReadCloser, err error) {
 	r := bufio.NewReader(in)
 	header, err := r.Peek(2)
@@ -82,10 +142,38 @@ func setupReader(in io.Reader) (src io.ReadCloser, err error) {
 // returns the CPE names that matched and the boolean result of the match.
 // If requireVersion is true, the function ignores rules with no Version attribute.
 func Match(inventory []*wfn.Attributes, rules []LogicalTest, requireVersion bool) ([]*wfn.Attributes, bool) {
+	return matcher{requireVersion: requireVersion}.match(inventory, rules)
+}
+
+// MatchWildcardVersions is like Match, but CPE names of inventory whose version ends with a wildcard
+// component, e.g. 2.4.*, stand for the range of versions the wildcard covers, [2.4.0, 2.5.0) (see WildcardVersionRange):
+// they match the rules affecting any version of the range. Rules which don't implement nvdcommon.VersionRangeTest
+// match such names as Match does.
+func MatchWildcardVersions(inventory []*wfn.Attributes, rules []LogicalTest, requireVersion bool) ([]*wfn.Attributes, bool) {
+	return matcher{requireVersion: requireVersion, wildcardVersions: true}.match(inventory, rules)
+}
+
+// matcher matches CPE names to logical tests as per the matching modes
+type matcher struct {
+	requireVersion   bool
+	wildcardVersions bool // see MatchWildcardVersions
+	// ranges holds the version ranges of the CPE names with wildcard versions, precomputed by CompiledTarget;
+	// if nil, the ranges are computed as the names are matched
+	ranges map[*wfn.Attributes]versionRange
+	comparators *VersionComparators // see Cache.SetVersionComparators
+	opts        wfn.MatchOptions    // see Cache.SetMatchOptions
+}
+
+// versionRange is the range of versions [start, end) a wildcard version stands for, see WildcardVersionRange
+type versionRange struct {
+	start, end string
+}
+
+func (m matcher) match(inventory []*wfn.Attributes, rules []LogicalTest) ([]*wfn.Attributes, bool) {
 	matches := make([]*wfn.Attributes, 0, len(inventory))
 	matched := false
 	for _, op := range rules {
-		matched = matched || matchLogicalTest(&matches, inventory, op, requireVersion)
+		matched = matched || m.matchLogicalTest(&matches, inventory, op)
 	}
 	if matched {
 		return append([]*wfn.Attributes{}, matches...), true
@@ -93,28 +181,112 @@ func Match(inventory []*wfn.Attributes, rules []LogicalTest, requireVersion bool
 	return nil, false
 }
 
-func matchLogicalTest(matches *[]*wfn.Attributes, inventory []*wfn.Attributes, op LogicalTest, requireVersion bool) bool {
+func (m matcher) matchLogicalTest(matches *[]*wfn.Attributes, inventory []*wfn.Attributes, op LogicalTest) bool {
 	matched := false
 	switch strings.ToLower(op.LogicalOperator()) {
 	case "or":
 		for _, o := range op.InnerTests() {
-			if matchLogicalTest(matches, inventory, o, requireVersion) {
+			if m.matchLogicalTest(matches, inventory, o) {
 				return op.NegateIfNeeded(true)
 			}
 		}
 	case "and":
+		n := len(*matches)
 		for _, o := range op.InnerTests() {
-			if !matchLogicalTest(matches, inventory, o, requireVersion) {
+			if !m.matchLogicalTest(matches, inventory, o) {
+				// drop the CPEs matched by the other operands, e.g. firmware not paired with hardware
+				*matches = (*matches)[:n]
 				return op.NegateIfNeeded(false)
 			}
 			matched = true
 		}
 	}
 	for _, name := range inventory {
-		if op.MatchPlatform(name, requireVersion) {
+		if m.matchPlatform(op, name) {
 			*matches = append(*matches, name)
 			matched = true
 		}
 	}
 	return op.NegateIfNeeded(matched)
 }
+
+// matchPlatform matches the platform to the test, as a range of versions if the version is a wildcard one
+func (m matcher) matchPlatform(op LogicalTest, platform *wfn.Attributes) bool {
+	op = m.withOptions(op)
+	if rt, start, end, ok := m.versionRange(op, platform); ok {
+		return rt.MatchPlatformRange(platform, start, end, m.requireVersion)
+	}
+	if ct, cmp, ok := m.comparator(op, platform); ok {
+		return ct.MatchPlatformCompare(platform, m.requireVersion, cmp)
+	}
+	return op.MatchPlatform(platform, m.requireVersion)
+}
+
+// matchVulnerable is like matchPlatform, but only considers the vulnerable components
+func (m matcher) matchVulnerable(vt nvdcommon.VulnerableTest, op LogicalTest, platform *wfn.Attributes) bool {
+	op = m.withOptions(op)
+	if v, ok := op.(nvdcommon.VulnerableTest); ok {
+		vt = v // matching as per the options too
+	}
+	if rt, start, end, ok := m.versionRange(op, platform); ok {
+		return rt.MatchVulnerableRange(platform, start, end, m.requireVersion)
+	}
+	if ct, cmp, ok := m.comparator(op, platform); ok {
+		return ct.MatchVulnerableCompare(platform, m.requireVersion, cmp)
+	}
+	return vt.MatchVulnerable(platform, m.requireVersion)
+}
+
+// withOptions returns the test matching as per the match options, the test itself if they're the usual ones
+// or the test doesn't support them
+func (m matcher) withOptions(op LogicalTest) LogicalTest {
+	if m.opts == (wfn.MatchOptions{}) {
+		return op
+	}
+	if ot, ok := op.(nvdcommon.MatchOptionsTest); ok {
+		return ot.WithMatchOptions(m.opts)
+	}
+	return op
+}
+
+// comparator returns the comparator of versions the platform is matched to op with, if it's not the usual one
+func (m matcher) comparator(op LogicalTest, platform *wfn.Attributes) (ct nvdcommon.VersionCompareTest, cmp nvdcommon.VersionComparator, ok bool) {
+	if cmp = m.comparators.For(platform); cmp == nil {
+		return nil, nil, false
+	}
+	ct, ok = op.(nvdcommon.VersionCompareTest)
+	return ct, cmp, ok
+}
+
+// versionRange returns the range of versions the platform stands for, if it's matched to op as a range
+func (m matcher) versionRange(op LogicalTest, platform *wfn.Attributes) (rt nvdcommon.VersionRangeTest, start, end string, ok bool) {
+	if !m.wildcardVersions || m.opts.ExactVersion || platform == nil {
+		return nil, "", "", false
+	}
+	if rt, ok = op.(nvdcommon.VersionRangeTest); !ok {
+		return nil, "", "", false
+	}
+	if m.ranges != nil {
+		r, ok := m.ranges[platform]
+		return rt, r.start, r.end, ok
+	}
+	start, end, ok = WildcardVersionRange(platform.Version)
+	return rt, start, end, ok
+}
+
+// WildcardVersionRange returns the range of versions [start, end) a version ending with a wildcard
+// component stands for, e.g. [2.4.0, 2.5.0) for 2.4.* (WFN 2\.4\.*) or [2.0, 3.0) for 2.*;
+// the component before the wildcard must be numeric. It returns false for other versions, ANY and NA included.
+func WildcardVersionRange(version string) (start, end string, ok bool) {
+	s := wfn.StripSlashes(version)
+	if !strings.HasSuffix(s, ".*") {
+		return "", "", false
+	}
+	prefix := strings.TrimSuffix(s, ".*")
+	last := prefix[strings.LastIndex(prefix, ".")+1:]
+	n, err := strconv.Atoi(last)
+	if err != nil || strings.ContainsAny(last, "+-") {
+		return "", "", false
+	}
+	return prefix + ".0", prefix[:len(prefix)-len(last)] + strconv.Itoa(n+1) + ".0", true
+}
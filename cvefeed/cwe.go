@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"sort"
+	"strings"
+)
+
+// CWEIndex maps weaknesses (e.g. CWE-79) to the CVEs of the dictionary listing them among problem types,
+// to look the CVEs of a weakness up without matching any CPE names, see ByCWE
+type CWEIndex struct {
+	cves map[string][]CVEItem // by normalized CWE, sorted by CVE ID
+}
+
+// NewCWEIndex creates new CWEIndex from the problem types of dictionary entries
+func NewCWEIndex(d Dictionary) *CWEIndex {
+	idx := &CWEIndex{cves: map[string][]CVEItem{}}
+	for _, cve := range d {
+		seen := map[string]bool{}
+		for _, pt := range cve.ProblemTypes() {
+			cwe := normalizeCWE(pt)
+			if cwe == "" || seen[cwe] {
+				continue
+			}
+			seen[cwe] = true
+			idx.cves[cwe] = append(idx.cves[cwe], cve)
+		}
+	}
+	for _, cves := range idx.cves {
+		sort.Slice(cves, func(i, j int) bool {
+			return cves[i].CVEID() < cves[j].CVEID()
+		})
+	}
+	return idx
+}
+
+// ByCWE returns the CVEs listing the weakness among their problem types, sorted by ID. The weakness is
+// identified as in the feed, e.g. CWE-79, case-insensitively; the number alone (79) will do too.
+func (idx *CWEIndex) ByCWE(cwe string) []CVEItem {
+	cves := idx.cves[normalizeCWE(cwe)]
+	if len(cves) == 0 {
+		return nil
+	}
+	return append([]CVEItem(nil), cves...)
+}
+
+// ByCWE returns the CVEs of the dictionary listing the weakness among their problem types, see CWEIndex.ByCWE.
+// The dictionary is indexed on every call, so index it once with NewCWEIndex for repeated lookups.
+func (d Dictionary) ByCWE(cwe string) []CVEItem {
+	return NewCWEIndex(d).ByCWE(cwe)
+}
+
+// normalizeCWE makes CWE identifiers comparable: uppercased, CWE- prefixed if it's the number alone
+func normalizeCWE(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s != "" && strings.Trim(s, "0123456789") == "" {
+		return "CWE-" + s
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// testJSONdictCWE lists several CWEs in one description, as NVD feeds do, and in several problem types
+var testJSONdictCWE = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0003"}, "problemtype": {"problemtype_data": [
+    {"description": [{"lang": "en", "value": "CWE-79"}]},
+    {"description": [{"lang": "en", "value": "CWE-352"}]}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0001"}, "problemtype": {"problemtype_data": [
+    {"description": [{"lang": "en", "value": "CWE-79"}, {"lang": "en", "value": "CWE-79"}]}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0002"}, "problemtype": {"problemtype_data": [
+    {"description": [{"lang": "en", "value": "NVD-CWE-Other"}]}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0004"}, "problemtype": {"problemtype_data": [
+    {"description": []}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0005"}, "problemtype": {"problemtype_data": [
+    {"description": [{"lang": "en", "value": "CWE-20"}, {"lang": "en", "value": "CWE-400"}, {"lang": "en", "value": "CWE-502"}]}
+  ]}}, "configurations": {"nodes": []}}
+]}`
+
+func TestByCWE(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictCWE))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	idx := NewCWEIndex(dict)
+	cases := []struct {
+		cwe      string
+		expected []string
+	}{
+		{"CWE-79", []string{"CVE-2020-0001", "CVE-2020-0003"}},
+		{"cwe-79", []string{"CVE-2020-0001", "CVE-2020-0003"}},
+		{"79", []string{"CVE-2020-0001", "CVE-2020-0003"}},
+		{"CWE-352", []string{"CVE-2020-0003"}},
+		{"NVD-CWE-Other", []string{"CVE-2020-0002"}},
+		{"CWE-20", []string{"CVE-2020-0005"}},
+		{"CWE-400", []string{"CVE-2020-0005"}},
+		{"CWE-502", []string{"CVE-2020-0005"}},
+		{"CWE-7", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		var ids []string
+		for _, cve := range idx.ByCWE(c.cwe) {
+			ids = append(ids, cve.CVEID())
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.cwe, c.expected, ids)
+		}
+	}
+	if cves := dict.ByCWE("CWE-352"); len(cves) != 1 || cves[0].CVEID() != "CVE-2020-0003" {
+		t.Errorf("unexpected CVEs of the dictionary: %v", cves)
+	}
+	idx.ByCWE("CWE-79")[0] = nil
+	if cves := idx.ByCWE("CWE-79"); cves[0] == nil {
+		t.Error("index was modified through lookup results")
+	}
+}
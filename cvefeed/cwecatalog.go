@@ -0,0 +1,277 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CWE is a weakness of CWE catalog
+type CWE struct {
+	ID    string   // e.g. CWE-79
+	Name  string   // e.g. Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting')
+	CAPEC []string // related CAPEC attack patterns, e.g. CAPEC-63; only catalogs parsed by ParseCWECatalog have them
+}
+
+// CWECatalog maps weaknesses (e.g. CWE-79) to their descriptions; the keys are normalized, use Lookup
+type CWECatalog map[string]*CWE
+
+// Lookup returns the weakness identified as in the feed, e.g. CWE-79, case-insensitively; the number alone (79)
+// will do too
+func (c CWECatalog) Lookup(cwe string) (*CWE, bool) {
+	w, ok := c[normalizeCWE(cwe)]
+	return w, ok
+}
+
+// Names returns the names of the weaknesses in the same order, empty strings for the ones not in the catalog
+func (c CWECatalog) Names(cwes []string) []string {
+	names := make([]string, len(cwes))
+	for i, cwe := range cwes {
+		if w, ok := c.Lookup(cwe); ok {
+			names[i] = w.Name
+		}
+	}
+	return names
+}
+
+// CAPEC returns the CAPEC attack patterns related to any of the weaknesses, without duplicates, in the order
+// of the weaknesses
+func (c CWECatalog) CAPEC(cwes []string) []string {
+	var capec []string
+	seen := map[string]bool{}
+	for _, cwe := range cwes {
+		w, ok := c.Lookup(cwe)
+		if !ok {
+			continue
+		}
+		for _, id := range w.CAPEC {
+			if !seen[id] {
+				seen[id] = true
+				capec = append(capec, id)
+			}
+		}
+	}
+	return capec
+}
+
+// CWEs returns the weaknesses the vulnerability lists among its problem types, normalized (e.g. CWE-79) and
+// without duplicates, in the order of the feed
+func CWEs(cve CVEItem) []string {
+	var cwes []string
+	seen := map[string]bool{}
+	for _, pt := range cve.ProblemTypes() {
+		if cwe := normalizeCWE(pt); cwe != "" && !seen[cwe] {
+			seen[cwe] = true
+			cwes = append(cwes, cwe)
+		}
+	}
+	return cwes
+}
+
+// ParseCWECatalog parses CWE catalog in the CSV format MITRE publishes it in (e.g. the 1000.csv of the research
+// view), plain or gzip'ed; only CWE-ID, Name and Related Attack Patterns columns are read
+func ParseCWECatalog(in io.Reader) (CWECatalog, error) {
+	src, err := setupReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("cwe: %v", err)
+	}
+	defer src.Close()
+	r := csv.NewReader(src)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cwe: can't read header: %v", err)
+	}
+	columns := map[string]int{"CWE-ID": -1, "Name": -1, "Related Attack Patterns": -1}
+	for i, name := range header {
+		if _, ok := columns[strings.TrimSpace(name)]; ok {
+			columns[strings.TrimSpace(name)] = i
+		}
+	}
+	if columns["CWE-ID"] < 0 || columns["Name"] < 0 {
+		return nil, fmt.Errorf("cwe: no CWE-ID or Name column in header %q, not CWE catalog", strings.Join(header, ","))
+	}
+	catalog := make(CWECatalog)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cwe: %v", err)
+		}
+		field := func(name string) string {
+			if i := columns[name]; i >= 0 && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		w := &CWE{ID: normalizeCWE(field("CWE-ID")), Name: field("Name")}
+		if w.ID == "" {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("cwe: line %d: CWE-ID is empty", line)
+		}
+		// related attack patterns are listed as ::63::85::
+		for _, id := range strings.Split(field("Related Attack Patterns"), "::") {
+			if id = strings.TrimSpace(id); id != "" {
+				w.CAPEC = append(w.CAPEC, "CAPEC-"+strings.TrimPrefix(strings.ToUpper(id), "CAPEC-"))
+			}
+		}
+		catalog[w.ID] = w
+	}
+	return catalog, nil
+}
+
+// LoadCWECatalog parses CWE catalog from CSV file, see ParseCWECatalog
+func LoadCWECatalog(path string) (CWECatalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cwe: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseCWECatalog(f)
+}
+
+// DefaultCWECatalog returns the catalog of the names of the weaknesses NVD assigns the most, e.g. CWE Top 25
+// and the NVD-CWE-Other and NVD-CWE-noinfo placeholders; it doesn't relate them to CAPEC attack patterns,
+// parse the full catalog with ParseCWECatalog for that. The catalog is built on every call, so it can be modified freely.
+func DefaultCWECatalog() CWECatalog {
+	catalog := make(CWECatalog, len(cweNames))
+	for id, name := range cweNames {
+		catalog[id] = &CWE{ID: id, Name: name}
+	}
+	return catalog
+}
+
+// cweNames are the names of the weaknesses of DefaultCWECatalog as per CWE 4.x
+var cweNames = map[string]string{
+	"NVD-CWE-OTHER":  "Other",
+	"NVD-CWE-NOINFO": "Insufficient Information",
+	"CWE-16":         "Configuration",
+	"CWE-19":         "Data Processing Errors",
+	"CWE-20":         "Improper Input Validation",
+	"CWE-22":         "Improper Limitation of a Pathname to a Restricted Directory ('Path Traversal')",
+	"CWE-59":         "Improper Link Resolution Before File Access ('Link Following')",
+	"CWE-74":         "Improper Neutralization of Special Elements in Output Used by a Downstream Component ('Injection')",
+	"CWE-77":         "Improper Neutralization of Special Elements used in a Command ('Command Injection')",
+	"CWE-78":         "Improper Neutralization of Special Elements used in an OS Command ('OS Command Injection')",
+	"CWE-79":         "Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting')",
+	"CWE-88":         "Improper Neutralization of Argument Delimiters in a Command ('Argument Injection')",
+	"CWE-89":         "Improper Neutralization of Special Elements used in an SQL Command ('SQL Injection')",
+	"CWE-90":         "Improper Neutralization of Special Elements used in an LDAP Query ('LDAP Injection')",
+	"CWE-93":         "Improper Neutralization of CRLF Sequences ('CRLF Injection')",
+	"CWE-94":         "Improper Control of Generation of Code ('Code Injection')",
+	"CWE-113":        "Improper Neutralization of CRLF Sequences in HTTP Headers ('HTTP Request/Response Splitting')",
+	"CWE-116":        "Improper Encoding or Escaping of Output",
+	"CWE-119":        "Improper Restriction of Operations within the Bounds of a Memory Buffer",
+	"CWE-120":        "Buffer Copy without Checking Size of Input ('Classic Buffer Overflow')",
+	"CWE-121":        "Stack-based Buffer Overflow",
+	"CWE-122":        "Heap-based Buffer Overflow",
+	"CWE-125":        "Out-of-bounds Read",
+	"CWE-129":        "Improper Validation of Array Index",
+	"CWE-134":        "Use of Externally-Controlled Format String",
+	"CWE-189":        "Numeric Errors",
+	"CWE-190":        "Integer Overflow or Wraparound",
+	"CWE-191":        "Integer Underflow (Wrap or Wraparound)",
+	"CWE-200":        "Exposure of Sensitive Information to an Unauthorized Actor",
+	"CWE-203":        "Observable Discrepancy",
+	"CWE-209":        "Generation of Error Message Containing Sensitive Information",
+	"CWE-252":        "Unchecked Return Value",
+	"CWE-254":        "7PK - Security Features",
+	"CWE-255":        "Credentials Management Errors",
+	"CWE-264":        "Permissions, Privileges, and Access Controls",
+	"CWE-269":        "Improper Privilege Management",
+	"CWE-276":        "Incorrect Default Permissions",
+	"CWE-284":        "Improper Access Control",
+	"CWE-285":        "Improper Authorization",
+	"CWE-287":        "Improper Authentication",
+	"CWE-290":        "Authentication Bypass by Spoofing",
+	"CWE-295":        "Improper Certificate Validation",
+	"CWE-306":        "Missing Authentication for Critical Function",
+	"CWE-307":        "Improper Restriction of Excessive Authentication Attempts",
+	"CWE-310":        "Cryptographic Issues",
+	"CWE-311":        "Missing Encryption of Sensitive Data",
+	"CWE-312":        "Cleartext Storage of Sensitive Information",
+	"CWE-319":        "Cleartext Transmission of Sensitive Information",
+	"CWE-326":        "Inadequate Encryption Strength",
+	"CWE-327":        "Use of a Broken or Risky Cryptographic Algorithm",
+	"CWE-330":        "Use of Insufficiently Random Values",
+	"CWE-345":        "Insufficient Verification of Data Authenticity",
+	"CWE-347":        "Improper Verification of Cryptographic Signature",
+	"CWE-352":        "Cross-Site Request Forgery (CSRF)",
+	"CWE-362":        "Concurrent Execution using Shared Resource with Improper Synchronization ('Race Condition')",
+	"CWE-367":        "Time-of-check Time-of-use (TOCTOU) Race Condition",
+	"CWE-369":        "Divide By Zero",
+	"CWE-384":        "Session Fixation",
+	"CWE-399":        "Resource Management Errors",
+	"CWE-400":        "Uncontrolled Resource Consumption",
+	"CWE-401":        "Missing Release of Memory after Effective Lifetime",
+	"CWE-404":        "Improper Resource Shutdown or Release",
+	"CWE-415":        "Double Free",
+	"CWE-416":        "Use After Free",
+	"CWE-426":        "Untrusted Search Path",
+	"CWE-427":        "Uncontrolled Search Path Element",
+	"CWE-434":        "Unrestricted Upload of File with Dangerous Type",
+	"CWE-444":        "Inconsistent Interpretation of HTTP Requests ('HTTP Request/Response Smuggling')",
+	"CWE-457":        "Use of Uninitialized Variable",
+	"CWE-459":        "Incomplete Cleanup",
+	"CWE-476":        "NULL Pointer Dereference",
+	"CWE-502":        "Deserialization of Untrusted Data",
+	"CWE-521":        "Weak Password Requirements",
+	"CWE-522":        "Insufficiently Protected Credentials",
+	"CWE-532":        "Insertion of Sensitive Information into Log File",
+	"CWE-601":        "URL Redirection to Untrusted Site ('Open Redirect')",
+	"CWE-611":        "Improper Restriction of XML External Entity Reference",
+	"CWE-613":        "Insufficient Session Expiration",
+	"CWE-617":        "Reachable Assertion",
+	"CWE-639":        "Authorization Bypass Through User-Controlled Key",
+	"CWE-640":        "Weak Password Recovery Mechanism for Forgotten Password",
+	"CWE-662":        "Improper Synchronization",
+	"CWE-665":        "Improper Initialization",
+	"CWE-667":        "Improper Locking",
+	"CWE-668":        "Exposure of Resource to Wrong Sphere",
+	"CWE-669":        "Incorrect Resource Transfer Between Spheres",
+	"CWE-674":        "Uncontrolled Recursion",
+	"CWE-681":        "Incorrect Conversion between Numeric Types",
+	"CWE-693":        "Protection Mechanism Failure",
+	"CWE-697":        "Incorrect Comparison",
+	"CWE-704":        "Incorrect Type Conversion or Cast",
+	"CWE-732":        "Incorrect Permission Assignment for Critical Resource",
+	"CWE-754":        "Improper Check for Unusual or Exceptional Conditions",
+	"CWE-755":        "Improper Handling of Exceptional Conditions",
+	"CWE-770":        "Allocation of Resources Without Limits or Throttling",
+	"CWE-772":        "Missing Release of Resource after Effective Lifetime",
+	"CWE-776":        "Improper Restriction of Recursive Entity References in DTDs ('XML Entity Expansion')",
+	"CWE-787":        "Out-of-bounds Write",
+	"CWE-798":        "Use of Hard-coded Credentials",
+	"CWE-824":        "Access of Uninitialized Pointer",
+	"CWE-829":        "Inclusion of Functionality from Untrusted Control Sphere",
+	"CWE-835":        "Loop with Unreachable Exit Condition ('Infinite Loop')",
+	"CWE-843":        "Access of Resource Using Incompatible Type ('Type Confusion')",
+	"CWE-862":        "Missing Authorization",
+	"CWE-863":        "Incorrect Authorization",
+	"CWE-908":        "Use of Uninitialized Resource",
+	"CWE-917":        "Improper Neutralization of Special Elements used in an Expression Language Statement ('Expression Language Injection')",
+	"CWE-918":        "Server-Side Request Forgery (SSRF)",
+	"CWE-1021":       "Improper Restriction of Rendered UI Layers or Frames",
+	"CWE-1236":       "Improper Neutralization of Formula Elements in a CSV File",
+	"CWE-1321":       "Improperly Controlled Modification of Object Prototype Attributes ('Prototype Pollution')",
+	"CWE-1333":       "Inefficient Regular Expression Complexity",
+}
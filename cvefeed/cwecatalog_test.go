@@ -0,0 +1,88 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCWECatalogCSV = `CWE-ID,Name,Weakness Abstraction,Status,Description,Related Attack Patterns,Notes
+79,"Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting')",Base,Stable,"The product does not neutralize input, ""quoted"".",::209::588::591::63::,
+352,Cross-Site Request Forgery (CSRF),Compound,Stable,The web application does not verify the request.,::111::462::467::62::,
+1004,Sensitive Cookie Without 'HttpOnly' Flag,Variant,Incomplete,,,
+`
+
+func TestParseCWECatalog(t *testing.T) {
+	catalog, err := ParseCWECatalog(strings.NewReader(testCWECatalogCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog) != 3 {
+		t.Fatalf("expected 3 weaknesses, got %d", len(catalog))
+	}
+	w, ok := catalog.Lookup("cwe-1004")
+	if !ok || w.Name != "Sensitive Cookie Without 'HttpOnly' Flag" || w.CAPEC != nil {
+		t.Errorf("unexpected CWE-1004 %+v", w)
+	}
+	cwes := []string{"CWE-79", "NVD-CWE-Other", "352"}
+	if names, expected := catalog.Names(cwes), []string{
+		"Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting')", "", "Cross-Site Request Forgery (CSRF)",
+	}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected names %q, got %q", expected, names)
+	}
+	if capec, expected := catalog.CAPEC(cwes), []string{
+		"CAPEC-209", "CAPEC-588", "CAPEC-591", "CAPEC-63", "CAPEC-111", "CAPEC-462", "CAPEC-467", "CAPEC-62",
+	}; !reflect.DeepEqual(capec, expected) {
+		t.Errorf("expected CAPEC %q, got %q", expected, capec)
+	}
+	if _, err := ParseCWECatalog(strings.NewReader("cve,epss,percentile\n")); err == nil {
+		t.Error("expected an error on CSV which isn't CWE catalog")
+	}
+}
+
+func TestDefaultCWECatalog(t *testing.T) {
+	catalog := DefaultCWECatalog()
+	for cwe, expected := range map[string]string{
+		"CWE-787":        "Out-of-bounds Write",
+		"79":             "Improper Neutralization of Input During Web Page Generation ('Cross-site Scripting')",
+		"NVD-CWE-noinfo": "Insufficient Information",
+	} {
+		if w, ok := catalog.Lookup(cwe); !ok || w.Name != expected {
+			t.Errorf("%s: expected name %q, got %+v", cwe, expected, w)
+		}
+	}
+	delete(catalog, "CWE-787")
+	if _, ok := DefaultCWECatalog().Lookup("CWE-787"); !ok {
+		t.Error("default catalog was modified")
+	}
+}
+
+func TestCWEs(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(`{"CVE_Items": [{"cve": {"CVE_data_meta": {"ID": "CVE-2020-0001"},
+  "problemtype": {"problemtype_data": [
+    {"description": [{"lang": "en", "value": "CWE-79"}, {"lang": "en", "value": " cwe-79"}]},
+    {"description": [{"lang": "en", "value": "352"}, {"lang": "en", "value": ""}]},
+    {"description": [{"lang": "en", "value": "NVD-CWE-Other"}]}
+  ]}}, "configurations": {"nodes": []}}]}`))
+	if err != nil || len(items) != 1 {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	if cwes, expected := CWEs(items[0]), []string{"CWE-79", "CWE-352", "NVD-CWE-OTHER"}; !reflect.DeepEqual(cwes, expected) {
+		t.Errorf("expected %q, got %q", expected, cwes)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cyclonedx converts match results into CycloneDX vulnerabilities documents and reads the components
+// of CycloneDX SBOMs to match them. See https://cyclonedx.org/docs/1.4/json/#vulnerabilities
+package cyclonedx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	bomFormat   = "CycloneDX"
+	specVersion = "1.4"
+	nvdURL      = "https://nvd.nist.gov/vuln/detail/"
+)
+
+// CVSS methods of the ratings
+const (
+	MethodCVSSv2  = "CVSSv2"
+	MethodCVSSv3  = "CVSSv3"
+	MethodCVSSv31 = "CVSSv31"
+	MethodOther   = "other"
+)
+
+// sourceException is the source of ratings assigned by exceptions, see cvefeed.Exceptions
+const sourceException = "exception"
+
+// BOM is CycloneDX document carrying vulnerabilities only
+type BOM struct {
+	BOMFormat       string           `json:"bomFormat"`
+	SpecVersion     string           `json:"specVersion"`
+	Version         int              `json:"version"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability describes a vulnerability affecting the components
+type Vulnerability struct {
+	ID       string    `json:"id"`
+	Source   *Source   `json:"source,omitempty"`
+	Ratings  []*Rating `json:"ratings,omitempty"`
+	CWEs     []int     `json:"cwes,omitempty"`
+	Analysis *Analysis `json:"analysis,omitempty"`
+	Affects  []*Affect `json:"affects"`
+}
+
+// States of vulnerability analysis
+const (
+	StateInTriage = "in_triage"
+)
+
+// Analysis is the state of triage of vulnerability, as reported by VEX documents
+type Analysis struct {
+	State string `json:"state"`
+}
+
+// Source is the source of vulnerability data
+type Source struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Rating is the severity of vulnerability as scored by the method
+type Rating struct {
+	Source   *Source `json:"source,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity"`
+	Method   string  `json:"method"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+// Affect references the component affected by vulnerability
+type Affect struct {
+	Ref string `json:"ref"`
+}
+
+// FromMatchResults converts results of matching the component against the dictionary into CycloneDX document;
+// component is referenced by its CPE name in formatted string binding.
+func FromMatchResults(component *wfn.Attributes, results []cvefeed.MatchResult) *BOM {
+	bom := &BOM{
+		BOMFormat:       bomFormat,
+		SpecVersion:     specVersion,
+		Version:         1,
+		Vulnerabilities: make([]*Vulnerability, 0, len(results)),
+	}
+	ref := component.BindToFmtString()
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		bom.Vulnerabilities = append(bom.Vulnerabilities, resultVulnerability(r, ref))
+	}
+	return bom
+}
+
+// resultVulnerability converts match result into vulnerability affecting the referenced component
+func resultVulnerability(r cvefeed.MatchResult, ref string) *Vulnerability {
+	v := vulnerability(r.CVE, ref)
+	if r.Rescored != nil {
+		// severity assigned by accepted risk decision goes first
+		r := &Rating{Source: &Source{Name: sourceException}, Severity: severity(*r.Rescored), Method: MethodOther}
+		v.Ratings = append([]*Rating{r}, v.Ratings...)
+	}
+	return v
+}
+
+// Write encodes the document as JSON into w
+func (bom *BOM) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("cyclonedx: failed to encode document: %v", err)
+	}
+	return nil
+}
+
+func vulnerability(cve cvefeed.CVEItem, ref string) *Vulnerability {
+	id := cve.CVEID()
+	v := &Vulnerability{
+		ID:      id,
+		Affects: []*Affect{{Ref: ref}},
+	}
+	if strings.HasPrefix(id, "CVE-") {
+		v.Source = &Source{Name: "NVD", URL: nvdURL + id}
+	}
+	var vectors nvdcommon.CVSSVectors
+	vectors, _ = cve.(nvdcommon.CVSSVectors)
+	if score := cve.CVSS30base(); score > 0 {
+		r := &Rating{Score: score, Severity: severity(cvss.SeverityFromScore(score)), Method: MethodCVSSv3}
+		if vectors != nil {
+			r.Vector = vectors.CVSS30vector()
+			if strings.HasPrefix(r.Vector, "CVSS:3.1/") {
+				r.Method = MethodCVSSv31
+			}
+		}
+		v.Ratings = append(v.Ratings, r)
+	}
+	if score := cve.CVSS20base(); score > 0 {
+		r := &Rating{Score: score, Severity: severity(cvss.SeverityFromV2Score(score)), Method: MethodCVSSv2}
+		if vectors != nil {
+			r.Vector = strings.Trim(vectors.CVSS20vector(), "()")
+		}
+		v.Ratings = append(v.Ratings, r)
+	}
+	for _, pt := range cve.ProblemTypes() {
+		if n, err := strconv.Atoi(strings.TrimPrefix(pt, "CWE-")); err == nil && strings.HasPrefix(pt, "CWE-") {
+			v.CWEs = append(v.CWEs, n)
+		}
+	}
+	return v
+}
+
+func severity(s cvss.Severity) string {
+	return strings.ToLower(s.String())
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cyclonedx
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestFromMatchResults(t *testing.T) {
+	items, err := cvefeed.ParseJSON(bytes.NewBufferString(testDict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	component := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	results := make([]cvefeed.MatchResult, len(items))
+	for i, item := range items {
+		results[i] = cvefeed.MatchResult{CVE: item, CPEs: []*wfn.Attributes{component}}
+	}
+	var buf bytes.Buffer
+	if err := FromMatchResults(component, results).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var actual, expected interface{}
+	if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(testBOM), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected document:\n%s", buf.String())
+	}
+}
+
+var testDict = `{"CVE_Items":[
+  {
+    "cve": {
+      "CVE_data_meta": {"ID": "CVE-2020-0001"},
+      "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]}
+    },
+    "configurations": {"nodes": []},
+    "impact": {
+      "baseMetricV3": {"cvssV3": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8}},
+      "baseMetricV2": {"cvssV2": {"version": "2.0", "vectorString": "AV:N/AC:L/Au:N/C:C/I:C/A:C", "baseScore": 10.0}}
+    }
+  },
+  {
+    "cve": {
+      "CVE_data_meta": {"ID": "CVE-2020-0002"},
+      "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "NVD-CWE-Other"}]}]}
+    },
+    "configurations": {"nodes": []},
+    "impact": {
+      "baseMetricV3": {"cvssV3": {"version": "3.0", "vectorString": "CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", "baseScore": 1.8}}
+    }
+  }
+]}`
+
+var testBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "version": 1,
+  "vulnerabilities": [
+    {
+      "id": "CVE-2020-0001",
+      "source": {"name": "NVD", "url": "https://nvd.nist.gov/vuln/detail/CVE-2020-0001"},
+      "ratings": [
+        {"score": 9.8, "severity": "critical", "method": "CVSSv31", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+        {"score": 10, "severity": "high", "method": "CVSSv2", "vector": "AV:N/AC:L/Au:N/C:C/I:C/A:C"}
+      ],
+      "cwes": [79],
+      "affects": [{"ref": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"}]
+    },
+    {
+      "id": "CVE-2020-0002",
+      "source": {"name": "NVD", "url": "https://nvd.nist.gov/vuln/detail/CVE-2020-0002"},
+      "ratings": [
+        {"score": 1.8, "severity": "low", "method": "CVSSv3", "vector": "CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}
+      ],
+      "affects": [{"ref": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"}]
+    }
+  ]
+}`
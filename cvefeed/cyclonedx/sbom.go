@@ -0,0 +1,293 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cyclonedx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Component is a component of SBOM, only the fields identifying it are interpreted
+type Component struct {
+	BOMRef     string       `json:"bom-ref,omitempty" xml:"bom-ref,attr"`
+	Type       string       `json:"type,omitempty" xml:"type,attr"`
+	Group      string       `json:"group,omitempty" xml:"group"`
+	Name       string       `json:"name" xml:"name"`
+	Version    string       `json:"version,omitempty" xml:"version"`
+	CPE        string       `json:"cpe,omitempty" xml:"cpe"`
+	PURL       string       `json:"purl,omitempty" xml:"purl"`
+	Components []*Component `json:"components,omitempty" xml:"components>component"`
+}
+
+// Ref returns the reference of the component: its bom-ref, its purl or CPE name if it has none
+func (c *Component) Ref() string {
+	switch {
+	case c.BOMRef != "":
+		return c.BOMRef
+	case c.PURL != "":
+		return c.PURL
+	default:
+		return c.CPE
+	}
+}
+
+// Attributes returns the CPE name of the component: the one it declares or, failing that, the one derived
+// from its purl, see PURLToCPE; nil if the component has neither.
+func (c *Component) Attributes() (*wfn.Attributes, error) {
+	if c.CPE != "" {
+		attrs, err := wfn.Parse(c.CPE)
+		if err != nil {
+			return nil, fmt.Errorf("cyclonedx: component %q: %v", c.Ref(), err)
+		}
+		return attrs, nil
+	}
+	if c.PURL != "" {
+		attrs, err := PURLToCPE(c.PURL)
+		if err != nil {
+			return nil, fmt.Errorf("cyclonedx: component %q: %v", c.Ref(), err)
+		}
+		return attrs, nil
+	}
+	return nil, nil
+}
+
+// PURLToCPE derives a CPE name from package URL (https://github.com/package-url/purl-spec):
+// the application named after the package of the version of the package. Package ecosystems rarely agree
+// with NVD on vendors, so the vendor is ANY; it's heuristic, CPE names declared by components are preferred.
+func PURLToCPE(s string) (*wfn.Attributes, error) {
+	p, err := purl.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	attrs := &wfn.Attributes{Part: "a", Vendor: wfn.Any, Version: wfn.Any}
+	if attrs.Product, err = wfn.WFNize(strings.ToLower(p.Name)); err != nil {
+		return nil, fmt.Errorf("purl %q: %v", s, err)
+	}
+	if p.Version != "" {
+		if attrs.Version, err = wfn.WFNize(strings.ToLower(p.Version)); err != nil {
+			return nil, fmt.Errorf("purl %q: %v", s, err)
+		}
+	}
+	return attrs, nil
+}
+
+// SBOM is CycloneDX software bill of materials in JSON or XML format
+type SBOM struct {
+	SerialNumber string
+	Version      int
+	Metadata     *Component // the component described by SBOM, optional
+	Components   []*Component
+	raw          map[string]json.RawMessage // JSON document as parsed, to write it back
+}
+
+type sbomDoc struct {
+	XMLName      xml.Name
+	BOMFormat    string `json:"bomFormat"`
+	SerialNumber string `json:"serialNumber" xml:"serialNumber,attr"`
+	Version      int    `json:"version" xml:"version,attr"`
+	Metadata     *struct {
+		Component *Component `json:"component" xml:"component"`
+	} `json:"metadata" xml:"metadata"`
+	Components []*Component `json:"components" xml:"components>component"`
+}
+
+// ParseSBOM parses CycloneDX SBOM in JSON or XML format
+func ParseSBOM(in io.Reader) (*SBOM, error) {
+	r := bufio.NewReader(in)
+	var doc sbomDoc
+	var raw map[string]json.RawMessage
+	if isXML(r) {
+		if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("cyclonedx: failed to decode SBOM: %v", err)
+		}
+		if doc.XMLName.Local != "bom" {
+			return nil, fmt.Errorf("cyclonedx: root element %q, not CycloneDX SBOM", doc.XMLName.Local)
+		}
+	} else {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("cyclonedx: failed to read SBOM: %v", err)
+		}
+		if err = json.Unmarshal(b, &raw); err == nil {
+			err = json.Unmarshal(b, &doc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cyclonedx: failed to decode SBOM: %v", err)
+		}
+		if doc.BOMFormat != bomFormat {
+			return nil, fmt.Errorf("cyclonedx: bomFormat %q, not CycloneDX SBOM", doc.BOMFormat)
+		}
+	}
+	s := &SBOM{SerialNumber: doc.SerialNumber, Version: doc.Version, Components: doc.Components, raw: raw}
+	if doc.Metadata != nil {
+		s.Metadata = doc.Metadata.Component
+	}
+	return s, nil
+}
+
+// isXML tells whether the buffered document starts with an XML element or declaration
+func isXML(r *bufio.Reader) bool {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		case 0xef: // UTF-8 BOM
+			b, err = r.Peek(3)
+			if err != nil || !bytes.Equal(b, []byte{0xef, 0xbb, 0xbf}) {
+				return false
+			}
+			r.Discard(3)
+		default:
+			return b[0] == '<'
+		}
+	}
+}
+
+// Walk calls fn for the described component and all the components of SBOM, nested ones included,
+// depth first in the order of the document
+func (s *SBOM) Walk(fn func(*Component)) {
+	var walk func([]*Component)
+	walk = func(cs []*Component) {
+		for _, c := range cs {
+			if c != nil {
+				fn(c)
+				walk(c.Components)
+			}
+		}
+	}
+	if s.Metadata != nil {
+		walk([]*Component{s.Metadata})
+	}
+	walk(s.Components)
+}
+
+// Findings maps the references of SBOM components (see Component.Ref) to their match results
+type Findings map[string][]cvefeed.MatchResult
+
+// Match matches the CPE names of all the components (see Component.Attributes) with the match function,
+// e.g. cvefeed.Cache.Get; components without CPE names and purls are skipped. Components with malformed CPE
+// names or purls are skipped as well, their errors are returned along with the findings of the others.
+func (s *SBOM) Match(match func(cpes []*wfn.Attributes) []cvefeed.MatchResult) (Findings, []error) {
+	findings := make(Findings)
+	var errs []error
+	s.Walk(func(c *Component) {
+		attrs, err := c.Attributes()
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if attrs == nil {
+			return
+		}
+		if results := match([]*wfn.Attributes{attrs}); len(results) != 0 {
+			ref := c.Ref()
+			findings[ref] = append(findings[ref], results...)
+		}
+	})
+	return findings, errs
+}
+
+// VEX returns CycloneDX document of the vulnerabilities found in the components of SBOM, in triage;
+// components are referenced by BOM-Link if SBOM has a serial number, by their bom-refs otherwise.
+func (s *SBOM) VEX(findings Findings) *BOM {
+	link := func(ref string) string { return ref }
+	if serial := strings.TrimPrefix(s.SerialNumber, "urn:uuid:"); serial != "" {
+		link = func(ref string) string {
+			return fmt.Sprintf("urn:cdx:%s/%d#%s", serial, s.Version, ref)
+		}
+	}
+	bom := &BOM{
+		BOMFormat:       bomFormat,
+		SpecVersion:     specVersion,
+		Version:         1,
+		Vulnerabilities: vulnerabilities(findings, link),
+	}
+	for _, v := range bom.Vulnerabilities {
+		v.Analysis = &Analysis{State: StateInTriage}
+	}
+	return bom
+}
+
+// WriteAugmented writes SBOM with the vulnerabilities found in its components as JSON into w.
+// JSON documents are written back as parsed, with the vulnerabilities they had replaced; XML ones are
+// converted to JSON, keeping the interpreted parts only.
+func (s *SBOM) WriteAugmented(w io.Writer, findings Findings) error {
+	doc := make(map[string]interface{}, len(s.raw)+1)
+	for k, v := range s.raw {
+		doc[k] = v
+	}
+	if s.raw == nil {
+		doc["bomFormat"] = bomFormat
+		doc["specVersion"] = specVersion
+		if s.SerialNumber != "" {
+			doc["serialNumber"] = s.SerialNumber
+		}
+		doc["version"] = s.Version
+		if s.Metadata != nil {
+			doc["metadata"] = map[string]interface{}{"component": s.Metadata}
+		}
+		doc["components"] = s.Components
+	}
+	doc["vulnerabilities"] = vulnerabilities(findings, func(ref string) string { return ref })
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("cyclonedx: failed to encode document: %v", err)
+	}
+	return nil
+}
+
+// vulnerabilities converts findings into vulnerabilities sorted by ID, affecting the components
+// referenced with the link function
+func vulnerabilities(findings Findings, link func(string) string) []*Vulnerability {
+	refs := make([]string, 0, len(findings))
+	for ref := range findings {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	byID := make(map[string]*Vulnerability)
+	vulns := make([]*Vulnerability, 0)
+	for _, ref := range refs {
+		for _, r := range findings[ref] {
+			if r.CVE == nil {
+				continue
+			}
+			if v, ok := byID[r.CVE.CVEID()]; ok {
+				v.Affects = append(v.Affects, &Affect{Ref: link(ref)})
+				continue
+			}
+			v := resultVulnerability(r, link(ref))
+			byID[v.ID] = v
+			vulns = append(vulns, v)
+		}
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+	return vulns
+}
@@ -0,0 +1,165 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cyclonedx
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+var testSBOMJSON = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+  "version": 2,
+  "metadata": {"component": {"type": "application", "bom-ref": "app", "name": "app"}},
+  "components": [
+    {"type": "library", "bom-ref": "bar", "name": "bar", "version": "1.0", "cpe": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*",
+     "components": [{"type": "library", "name": "baz", "purl": "pkg:npm/%40foo/baz@2.0.1"}]},
+    {"type": "library", "bom-ref": "docs", "name": "docs"}
+  ],
+  "dependencies": [{"ref": "app", "dependsOn": ["bar"]}]
+}`
+
+var testSBOMXML = `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.4" version="1">
+  <components>
+    <component type="library" bom-ref="bar">
+      <name>bar</name>
+      <version>1.0</version>
+      <cpe>cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*</cpe>
+      <components>
+        <component type="library">
+          <name>baz</name>
+          <purl>pkg:npm/%40foo/baz@2.0.1</purl>
+        </component>
+      </components>
+    </component>
+  </components>
+</bom>`
+
+var testSBOMDict = `{"CVE_Items":[
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0001"}},
+    "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+      {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"},
+      {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*", "versionEndExcluding": "2.1"}
+    ]}]}
+  },
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0002"}},
+    "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+      {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:baz:2.0.1:*:*:*:*:*:*:*"}
+    ]}]}
+  }
+]}`
+
+func testSBOMCache(t *testing.T) *cvefeed.Cache {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testSBOMDict))
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cvefeed.NewCache(dict)
+}
+
+func TestSBOMMatch(t *testing.T) {
+	cache := testSBOMCache(t)
+	for name, doc := range map[string]string{"json": testSBOMJSON, "xml": testSBOMXML} {
+		s, err := ParseSBOM(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		findings, errs := s.Match(cache.Get)
+		if len(errs) != 0 {
+			t.Fatalf("%s: %v", name, errs)
+		}
+		vex := s.VEX(findings)
+		if len(vex.Vulnerabilities) != 2 {
+			t.Fatalf("%s: expected 2 vulnerabilities, got %d", name, len(vex.Vulnerabilities))
+		}
+		prefix := ""
+		if name == "json" {
+			prefix = "urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/2#"
+		}
+		expected := map[string][]string{
+			"CVE-2020-0001": {prefix + "bar", prefix + "pkg:npm/%40foo/baz@2.0.1"},
+			"CVE-2020-0002": {prefix + "pkg:npm/%40foo/baz@2.0.1"},
+		}
+		for _, v := range vex.Vulnerabilities {
+			var refs []string
+			for _, a := range v.Affects {
+				refs = append(refs, a.Ref)
+			}
+			if strings.Join(refs, " ") != strings.Join(expected[v.ID], " ") {
+				t.Errorf("%s: %s: expected affects %v, got %v", name, v.ID, expected[v.ID], refs)
+			}
+			if v.Analysis == nil || v.Analysis.State != StateInTriage {
+				t.Errorf("%s: %s: expected analysis in triage", name, v.ID)
+			}
+		}
+	}
+}
+
+func TestWriteAugmented(t *testing.T) {
+	s, err := ParseSBOM(strings.NewReader(testSBOMJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, _ := s.Match(testSBOMCache(t).Get)
+	var buf bytes.Buffer
+	if err = s.WriteAugmented(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Dependencies    []json.RawMessage `json:"dependencies"`
+		Vulnerabilities []*Vulnerability  `json:"vulnerabilities"`
+	}
+	if err = json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Dependencies) != 1 {
+		t.Error("fields of the document not interpreted were dropped")
+	}
+	if len(doc.Vulnerabilities) != 2 || doc.Vulnerabilities[0].Affects[0].Ref != "bar" {
+		t.Errorf("unexpected vulnerabilities:\n%s", buf.String())
+	}
+}
+
+func TestPURLToCPE(t *testing.T) {
+	tests := map[string]string{
+		"pkg:npm/%40foo/baz@2.0.1":                                      "cpe:2.3:a:*:baz:2.0.1:*:*:*:*:*:*:*",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar": "cpe:2.3:a:*:log4j-core:2.14.1:*:*:*:*:*:*:*",
+		"pkg:pypi/Django":                                               "cpe:2.3:a:*:django:*:*:*:*:*:*:*:*",
+	}
+	for purl, expected := range tests {
+		attrs, err := PURLToCPE(purl)
+		if err != nil {
+			t.Errorf("%s: %v", purl, err)
+		} else if fs := attrs.BindToFmtString(); fs != expected {
+			t.Errorf("%s: expected %s, got %s", purl, expected, fs)
+		}
+	}
+	for _, purl := range []string{"npm/foo@1.0", "pkg:npm", "pkg:npm/@1.0"} {
+		if _, err := PURLToCPE(purl); err == nil {
+			t.Errorf("%s: expected an error", purl)
+		}
+	}
+}
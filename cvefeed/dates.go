@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"sort"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// DateIndex is a dictionary index sorted by the time of last modification of CVEs.
+// CVEs are last modified when published at the latest, so the index covers both new and updated CVEs.
+// CVEs with unknown modification time aren't indexed.
+type DateIndex struct {
+	items    []CVEItem
+	modified []time.Time
+}
+
+// NewDateIndex creates new DateIndex from the dictionary
+func NewDateIndex(d Dictionary) *DateIndex {
+	idx := &DateIndex{}
+	for _, cve := range d {
+		dates, ok := cve.(nvdcommon.CVEDates)
+		if !ok {
+			continue
+		}
+		if t := dates.LastModified(); !t.IsZero() {
+			idx.items = append(idx.items, cve)
+			idx.modified = append(idx.modified, t)
+		}
+	}
+	sort.Sort(idx)
+	return idx
+}
+
+// Len implements sort.Interface
+func (idx *DateIndex) Len() int {
+	return len(idx.items)
+}
+
+// Less implements sort.Interface; CVEs modified at the same time are sorted by ID
+func (idx *DateIndex) Less(i, j int) bool {
+	if idx.modified[i].Equal(idx.modified[j]) {
+		return idx.items[i].CVEID() < idx.items[j].CVEID()
+	}
+	return idx.modified[i].Before(idx.modified[j])
+}
+
+// Swap implements sort.Interface
+func (idx *DateIndex) Swap(i, j int) {
+	idx.items[i], idx.items[j] = idx.items[j], idx.items[i]
+	idx.modified[i], idx.modified[j] = idx.modified[j], idx.modified[i]
+}
+
+// Since returns CVEs published or modified at or after t, sorted by modification time
+func (idx *DateIndex) Since(t time.Time) []CVEItem {
+	return idx.Between(t, time.Time{})
+}
+
+// Between returns CVEs published or modified within [from, to) range, sorted by modification time;
+// zero to means no upper bound
+func (idx *DateIndex) Between(from, to time.Time) []CVEItem {
+	i := sort.Search(len(idx.modified), func(i int) bool {
+		return !idx.modified[i].Before(from)
+	})
+	j := len(idx.modified)
+	if !to.IsZero() {
+		j = sort.Search(len(idx.modified), func(i int) bool {
+			return !idx.modified[i].Before(to)
+		})
+	}
+	if i >= j {
+		return nil
+	}
+	return append([]CVEItem{}, idx.items[i:j]...)
+}
+
+// Since returns CVEs published or modified at or after t, sorted by modification time.
+// It builds the index on every call, use DateIndex for repeated queries.
+func (d Dictionary) Since(t time.Time) []CVEItem {
+	return NewDateIndex(d).Since(t)
+}
+
+// Between returns CVEs published or modified within [from, to) range, sorted by modification time.
+// It builds the index on every call, use DateIndex for repeated queries.
+func (d Dictionary) Between(from, to time.Time) []CVEItem {
+	return NewDateIndex(d).Between(from, to)
+}
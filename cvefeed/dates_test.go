@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestDateIndex(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictDates))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	date := func(s string) time.Time {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	ids := func(items []CVEItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.CVEID())
+		}
+		return ids
+	}
+	idx := NewDateIndex(dict)
+	cases := []struct {
+		name     string
+		items    []CVEItem
+		expected []string
+	}{
+		{"since", idx.Since(date("2019-05-01")), []string{"CVE-2018-0001", "CVE-2019-0003", "CVE-2019-0002"}},
+		{"since all", idx.Since(time.Time{}), []string{"CVE-2019-0001", "CVE-2018-0001", "CVE-2019-0003", "CVE-2019-0002"}},
+		{"between", idx.Between(date("2019-05-01"), date("2019-06-01")), []string{"CVE-2018-0001", "CVE-2019-0003"}},
+		{"empty range", idx.Between(date("2019-06-01"), date("2019-05-01")), nil},
+		{"dictionary", dict.Since(date("2019-06-01")), []string{"CVE-2019-0002"}},
+	}
+	for _, c := range cases {
+		if actual := ids(c.items); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+var testJSONdictDates = `{"CVE_Items":[
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0001"}}, "configurations": {"nodes": []},
+   "publishedDate": "2019-01-01T10:00Z", "lastModifiedDate": "2019-01-02T10:00Z"},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0002"}}, "configurations": {"nodes": []},
+   "publishedDate": "2019-06-01T10:00Z", "lastModifiedDate": "2019-06-01T10:00Z"},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2018-0001"}}, "configurations": {"nodes": []},
+   "publishedDate": "2018-01-01T10:00Z", "lastModifiedDate": "2019-05-01T10:00Z"},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0003"}}, "configurations": {"nodes": []},
+   "publishedDate": "2019-05-01T10:00Z", "lastModifiedDate": "2019-05-01T10:00Z"},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0004"}}, "configurations": {"nodes": []}}
+]}`
+
+func TestPublishedAfter(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictPublished))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	cpe := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	cutoff := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		cutoff         time.Time
+		includeUndated bool
+		expected       []string
+	}{
+		{time.Time{}, false, []string{"CVE-2018-0001", "CVE-2019-0001", "CVE-2019-0002", "CVE-2019-0003", "CVE-2019-0004"}},
+		{cutoff, false, []string{"CVE-2019-0001", "CVE-2019-0002"}},
+		{cutoff, true, []string{"CVE-2019-0001", "CVE-2019-0002", "CVE-2019-0003", "CVE-2019-0004"}},
+	}
+	for _, c := range cases {
+		cache := NewCache(dict).SetPublishedAfter(c.cutoff, c.includeUndated)
+		var ids []string
+		for _, r := range cache.Get([]*wfn.Attributes{cpe}) {
+			ids = append(ids, r.CVE.CVEID())
+		}
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("cutoff %v, undated %t: Get: expected %v, got %v", c.cutoff, c.includeUndated, c.expected, ids)
+		}
+		if ids := cache.MatchIDs(cpe); !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("cutoff %v, undated %t: MatchIDs: expected %v, got %v", c.cutoff, c.includeUndated, c.expected, ids)
+		}
+	}
+}
+
+var testJSONdictPublished = `{"CVE_Items":[
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2018-0001"}}, "publishedDate": "2018-12-31T23:59Z", "configurations": {"nodes": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0001"}}, "publishedDate": "2019-01-01T00:00Z", "configurations": {"nodes": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0002"}}, "publishedDate": "2019-06-01T10:00Z", "configurations": {"nodes": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0003"}}, "configurations": {"nodes": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2019-0004"}}, "publishedDate": "June 2019", "configurations": {"nodes": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}}
+]}`
@@ -15,13 +15,20 @@
 package cvefeed
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdjson"
 )
 
 // Dictionary is a slice of entries
@@ -43,36 +50,296 @@ func (d *Dictionary) Override(d2 Dictionary) {
 	}
 }
 
+// WriteJSON writes the dictionary to w as an NVD CVE JSON 1.1 feed, so it can be loaded by the tools speaking
+// the standard feed format, e.g. after merging dictionaries of several sources. Entries parsed from NVD feeds are
+// written as parsed, so loading the output yields the same dictionary; other entries are written as per the data
+// they provide, see nvdjson.FeedItem.
+func (d Dictionary) WriteJSON(w io.Writer) error {
+	items := make([]CVEItem, 0, len(d))
+	for _, cve := range d {
+		items = append(items, cve)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nvdjson.Export(items))
+}
+
+// Unmapped returns CVEs of the dictionary which have no CPE names in their configurations, sorted by ID.
+// These are usually recently published CVEs awaiting NVD analysis: they can't be matched against CPE names
+// until configurations are added to the feed.
+// Feed entries which lack configurations element altogether are considered broken and skipped by the parser.
+func (d Dictionary) Unmapped() []CVEItem {
+	var unmapped []CVEItem
+	for _, cve := range d {
+		if len(collectCPEs(cve.Config())) == 0 {
+			unmapped = append(unmapped, cve)
+		}
+	}
+	sort.Slice(unmapped, func(i, j int) bool {
+		return unmapped[i].CVEID() < unmapped[j].CVEID()
+	})
+	return unmapped
+}
+
+// UnmappedByVendor returns unmapped CVEs (see Unmapped) which the feed lists as affecting any of the vendors,
+// compared insensitive to lexical case. The feed might name the vendors apart from configurations,
+// as NVD JSON 1.x feeds do in affects section; CVEs which don't name any vendors are included if includeUnknown is true.
+func (d Dictionary) UnmappedByVendor(includeUnknown bool, vendors ...string) []CVEItem {
+	var unmapped []CVEItem
+	for _, cve := range d.Unmapped() {
+		av, ok := cve.(nvdcommon.AffectedVendors)
+		var affected []string
+		if ok {
+			affected = av.Vendors()
+		}
+		if len(affected) == 0 {
+			if includeUnknown {
+				unmapped = append(unmapped, cve)
+			}
+			continue
+		}
+	loop:
+		for _, a := range affected {
+			for _, v := range vendors {
+				if strings.EqualFold(a, v) {
+					unmapped = append(unmapped, cve)
+					break loop
+				}
+			}
+		}
+	}
+	return unmapped
+}
+
+// MergePolicy defines field-level precedence of sources when merging dictionaries, see nvdcommon.MergePolicy
+type MergePolicy = nvdcommon.MergePolicy
+
+// Provenance is implemented by CVE items of merged dictionaries, which tell the source of each field,
+// see nvdcommon.Provenance
+type Provenance = nvdcommon.Provenance
+
+// CVESources returns the names of the sources the CVE was merged from, nil if it wasn't merged, see MergeDictionaries
+func CVESources(cve CVEItem) []string {
+	if p, ok := cve.(Provenance); ok {
+		return p.Sources()
+	}
+	return nil
+}
+
+// CVEProvenance returns the sources of the fields of the CVE merged by MergeDictionaries, keyed by the name of the field
+// (see nvdcommon.ParseField); fields no source defined are omitted, nil if the CVE wasn't merged
+func CVEProvenance(cve CVEItem) map[string]string {
+	p, ok := cve.(Provenance)
+	if !ok {
+		return nil
+	}
+	provenance := map[string]string{}
+	for _, f := range nvdcommon.Fields {
+		if src := p.SourceOf(f); src != "" {
+			provenance[f.String()] = src
+		}
+	}
+	return provenance
+}
+
+// ParseMergePolicy builds the merge policy from comma-separated default priority list of sources
+// and field priorities in field=src1,src2 form, e.g. cvss3=nvd,vendor; see nvdcommon.ParseField for field names
+func ParseMergePolicy(defaults string, fields []string) (MergePolicy, error) {
+	policy := MergePolicy{Default: splitSources(defaults)}
+	for _, fp := range fields {
+		i := strings.IndexByte(fp, '=')
+		if i < 0 {
+			return MergePolicy{}, fmt.Errorf("field priority %q: expected field=src1,src2", fp)
+		}
+		f, err := nvdcommon.ParseField(strings.TrimSpace(fp[:i]))
+		if err != nil {
+			return MergePolicy{}, fmt.Errorf("field priority %q: %v", fp, err)
+		}
+		if policy.Fields == nil {
+			policy.Fields = map[nvdcommon.Field][]string{}
+		}
+		policy.Fields[f] = splitSources(fp[i+1:])
+	}
+	return policy, nil
+}
+
+// splitSources splits comma-separated list of sources, skipping empty ones
+func splitSources(list string) []string {
+	var sources []string
+	for _, src := range strings.Split(list, ",") {
+		if src = strings.TrimSpace(src); src != "" {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// MergeDictionaries combines dictionaries keyed by the name of the source into one.
+// Each field of a merged CVE comes from the highest priority source which defines it, as per policy,
+// e.g. NVD's CVSS scores might be combined with vendor's configurations (affected version ranges).
+func MergeDictionaries(sources map[string]Dictionary, policy MergePolicy) Dictionary {
+	ids := map[string]bool{}
+	for _, d := range sources {
+		for id := range d {
+			ids[id] = true
+		}
+	}
+	merged := make(Dictionary, len(ids))
+	for id := range ids {
+		items := make(map[string]CVEItem, len(sources))
+		for src, d := range sources {
+			if cve, ok := d[id]; ok {
+				items[src] = cve
+			}
+		}
+		if cve := nvdcommon.MergeCVEItemsByPolicy(items, policy); cve != nil {
+			merged[id] = cve
+		}
+	}
+	return merged
+}
+
+// ApplyMatchCriteria amends configurations of NVD 2.0 entries in Dictionary with version ranges
+// declared in the NVD CPE match criteria feed, correlated by matchCriteriaId.
+// Configuration leaves that declare version ranges themselves are left intact.
+func (d Dictionary) ApplyMatchCriteria(mc MatchCriteria) {
+	items := make([]CVEItem, 0, len(d))
+	for _, cve := range d {
+		items = append(items, cve)
+	}
+	nvdjson.ApplyMatchCriteria(items, mc)
+}
+
+// LoadMatchCriteria parses NVD CPE match criteria from multiple feed JSON files
+func LoadMatchCriteria(paths ...string) (MatchCriteria, error) {
+	mc := make(MatchCriteria)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("dictionary: failed to load match criteria %q: %v", path, err)
+		}
+		m, err := ParseMatchCriteria(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("dictionary: failed to load match criteria %q: %v", path, err)
+		}
+		for k, v := range m {
+			mc[k] = v
+		}
+	}
+	return mc, nil
+}
+
 // LoadJSONDictionary parses dictionary from multiple NVD vulenrability feed JSON files
 func LoadJSONDictionary(paths ...string) (Dictionary, error) {
 	return LoadFeed(loadJSONFile, paths...)
 }
 
+// LoadJSONDictionaryWithOptions is like LoadJSONDictionary, but the feeds are parsed as per opts,
+// see ParseJSONWithOptions; feeds are parsed concurrently, so opts.Transform must be safe for concurrent use
+func LoadJSONDictionaryWithOptions(opts nvdjson.ParseOptions, paths ...string) (Dictionary, error) {
+	return LoadFeed(func(path string) ([]CVEItem, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+		}
+		defer f.Close()
+		return ParseJSONWithOptions(f, opts)
+	}, paths...)
+}
+
+// feedFileRe matches the names of NVD feed files, e.g. nvdcve-1.1-2002.json.gz or nvdcve-1.1-modified.json
+var feedFileRe = regexp.MustCompile(`^nvdcve-[0-9.]+-([0-9]{4}|recent|modified)\.json(\.gz)?$`)
+
+// LoadDirectory parses dictionary from NVD vulnerability feed JSON files found in the directory,
+// e.g. yearly feeds nvdcve-1.1-2002.json.gz ... nvdcve-1.1-2019.json.gz optionally complemented by
+// nvdcve-1.1-recent.json.gz and nvdcve-1.1-modified.json.gz; other files are skipped.
+// The files are loaded one by one: yearly feeds in chronological order, then recent, then modified feed,
+// so that the CVE from the file loaded later overrides the one loaded earlier.
+func LoadDirectory(path string) (Dictionary, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load directory %q: %v", path, err)
+	}
+	type feedFile struct {
+		name, key string
+	}
+	var feeds []feedFile
+	for _, f := range files {
+		m := feedFileRe.FindStringSubmatch(f.Name())
+		if f.IsDir() || m == nil {
+			getLogger().Debugf("dictionary: skipping %q, not a feed file", f.Name())
+			continue
+		}
+		// digits sort before letters, and "modified" must go after "recent"
+		key := m[1]
+		if key == "modified" {
+			key = "~" + key
+		}
+		feeds = append(feeds, feedFile{name: f.Name(), key: key})
+	}
+	sort.SliceStable(feeds, func(i, j int) bool {
+		return feeds[i].key < feeds[j].key
+	})
+	dict := make(Dictionary)
+	var errs []string
+	for _, f := range feeds {
+		items, err := loadJSONFile(filepath.Join(path, f.name))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, cve := range items {
+			if cveid := cve.CVEID(); cveid != "" {
+				dict[cveid] = cve
+			} else {
+				getLogger().Warnf("dictionary: skipping a record without CVE ID in %q", f.name)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return dict, errors.New(strings.Join(errs, "\n"))
+	}
+	return dict, nil
+}
+
+// LoadValidatedJSONDictionary is like LoadJSONDictionary, but validates the structure of each feed
+// before parsing it; feeds with structural problems are not loaded.
+func LoadValidatedJSONDictionary(paths ...string) (Dictionary, error) {
+	return LoadFeed(loadValidatedJSONFile, paths...)
+}
+
 // LoadFeed calls loadFunc for each file in paths and returns the combined outputs in a Dictionary.
 func LoadFeed(loadFunc func(string) ([]CVEItem, error), paths ...string) (Dictionary, error) {
 	dict := make(Dictionary)
 	var wg sync.WaitGroup
 	done := make(chan struct{})
 	errDone := make(chan struct{})
-	dictChan := make(chan []CVEItem, 1)
+	type feed struct {
+		path  string
+		items []CVEItem
+	}
+	dictChan := make(chan feed, 1)
 	errChan := make(chan error, 1)
 	for _, path := range paths {
 		wg.Add(1)
 		go func(path string) {
 			defer wg.Done()
-			feed, err := loadFunc(path)
+			items, err := loadFunc(path)
 			if err != nil {
 				errChan <- fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
 				return
 			}
-			dictChan <- feed
+			dictChan <- feed{path, items}
 		}(path)
 	}
 	go func() {
-		for d := range dictChan {
-			for _, cve := range d {
+		for f := range dictChan {
+			for _, cve := range f.items {
 				if cveid := cve.CVEID(); cveid != "" {
 					dict[cveid] = cve
+				} else {
+					getLogger().Warnf("dictionary: skipping a record without CVE ID in %q", f.path)
 				}
 			}
 		}
@@ -105,3 +372,19 @@ func loadJSONFile(path string) ([]CVEItem, error) {
 	defer f.Close()
 	return ParseJSON(f)
 }
+
+// loadValidatedJSONFile validates and parses dictionary from NVD vulnerability feed JSON file
+func loadValidatedJSONFile(path string) ([]CVEItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+	}
+	defer f.Close()
+	if err = ValidateJSON(f); err != nil {
+		return nil, fmt.Errorf("dictionary: feed %q failed validation:\n%v", path, err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+	}
+	return ParseJSON(f)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdjson"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestLoadDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdfeeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	feed := func(name string, gzipped bool, cves ...string) {
+		items := ""
+		for i, cve := range cves {
+			if i > 0 {
+				items += ","
+			}
+			items += fmt.Sprintf(testFeedItem, cve, name)
+		}
+		data := []byte(fmt.Sprintf(`{"CVE_Items":[%s]}`, items))
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if gzipped {
+			zw := gzip.NewWriter(f)
+			defer zw.Close()
+			_, err = zw.Write(data)
+		} else {
+			_, err = f.Write(data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	feed("nvdcve-1.1-modified.json.gz", true, "CVE-2002-0001", "CVE-2019-0002")
+	feed("nvdcve-1.1-2019.json.gz", true, "CVE-2019-0001", "CVE-2019-0002")
+	feed("nvdcve-1.1-2002.json", false, "CVE-2002-0001")
+	feed("nvdcve-1.1-recent.json.gz", true, "CVE-2019-0001")
+	feed("nvdcve-1.1-2019.meta", false, "CVE-2019-0003")
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a feed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dict, err := LoadDirectory(dir)
+	if err != nil {
+		t.Fatalf("failed to load directory: %v", err)
+	}
+	expected := map[string]string{
+		"CVE-2002-0001": "nvdcve-1.1-modified.json.gz",
+		"CVE-2019-0001": "nvdcve-1.1-recent.json.gz",
+		"CVE-2019-0002": "nvdcve-1.1-modified.json.gz",
+	}
+	if len(dict) != len(expected) {
+		t.Fatalf("expected %d CVEs, got %d", len(expected), len(dict))
+	}
+	for id, src := range expected {
+		cve, ok := dict[id]
+		if !ok {
+			t.Errorf("%s wasn't loaded", id)
+			continue
+		}
+		if cwes := cve.ProblemTypes(); len(cwes) != 1 || cwes[0] != src {
+			t.Errorf("%s: expected to be loaded from %s, got %v", id, src, cwes)
+		}
+	}
+}
+
+// testFeedItem is a CVE with the name of the feed file stored as problem type
+var testFeedItem = `{
+  "cve": {
+    "CVE_data_meta": {"ID": %q},
+    "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": %q}]}]}
+  },
+  "configurations": {"nodes": []}
+}`
+
+func TestUnmapped(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictUnmapped))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	ids := func(items []CVEItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.CVEID())
+		}
+		return ids
+	}
+	cases := []struct {
+		name     string
+		items    []CVEItem
+		expected []string
+	}{
+		{"all", dict.Unmapped(), []string{"CVE-2020-0002", "CVE-2020-0003"}},
+		{"vendor", dict.UnmappedByVendor(false, "FOO"), []string{"CVE-2020-0002"}},
+		{"vendor and unknown", dict.UnmappedByVendor(true, "foo"), []string{"CVE-2020-0002", "CVE-2020-0003"}},
+		{"other vendor", dict.UnmappedByVendor(false, "bar"), nil},
+	}
+	for _, c := range cases {
+		if actual := ids(c.items); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
+
+// taggedCVE is a CVE tagged on load
+type taggedCVE struct {
+	CVEItem
+}
+
+func (c taggedCVE) Vendors() []string { return []string{"tagged"} }
+
+func TestLoadJSONDictionaryWithTransform(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdfeeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "feed.json")
+	if err = ioutil.WriteFile(path, []byte(testJSONdictUnmapped), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []string
+	opts := nvdjson.ParseOptions{Transform: func(cve CVEItem) (CVEItem, bool) {
+		seen = append(seen, cve.CVEID())
+		switch cve.CVEID() {
+		case "CVE-2020-0002":
+			return taggedCVE{cve}, true
+		case "CVE-2020-0003":
+			return nil, false
+		}
+		return cve, true
+	}}
+	dict, err := LoadJSONDictionaryWithOptions(opts, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"CVE-2020-0001", "CVE-2020-0002", "CVE-2020-0003"}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected transform to be called for %v, got %v", expected, seen)
+	}
+	if len(dict) != 2 || dict["CVE-2020-0001"] == nil || dict["CVE-2020-0003"] != nil {
+		t.Fatalf("expected CVE-2020-0003 to be dropped, got %v", dict)
+	}
+	if cve, ok := dict["CVE-2020-0002"].(taggedCVE); !ok || !reflect.DeepEqual(cve.Vendors(), []string{"tagged"}) {
+		t.Errorf("expected CVE-2020-0002 to be tagged, got %#v", dict["CVE-2020-0002"])
+	}
+}
+
+var testJSONdictUnmapped = `{"CVE_Items":[
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0001"}},
+    "configurations": {"nodes": [{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"}]}]}
+  },
+  {
+    "cve": {
+      "CVE_data_meta": {"ID": "CVE-2020-0002"},
+      "affects": {"vendor": {"vendor_data": [{"vendor_name": "foo"}]}}
+    },
+    "configurations": {"nodes": []}
+  },
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0003"}},
+    "configurations": {"nodes": [{"operator": "OR"}]}
+  }
+]}`
+
+func TestWriteJSON(t *testing.T) {
+	load := func(feed string) Dictionary {
+		t.Helper()
+		dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+			return ParseJSON(bytes.NewBufferString(feed))
+		}, "")
+		if err != nil {
+			t.Fatalf("failed to parse the dictionary: %v", err)
+		}
+		return dict
+	}
+	dict := load(testJSONdictVersions)
+	dict.Override(load(strings.Replace(testJSONdictVersions, `"versionEndExcluding" : "2.0"`, `"versionEndExcluding" : "1.2"`, 1)))
+	var buf bytes.Buffer
+	if err := dict.WriteJSON(&buf); err != nil {
+		t.Fatalf("failed to write the dictionary: %v", err)
+	}
+	exported := load(buf.String())
+	if len(exported) != len(dict) {
+		t.Fatalf("expected %d CVEs, got %d", len(dict), len(exported))
+	}
+	for _, version := range []string{"0\\.9", "1\\.1", "1\\.5", "2\\.1", "3\\.0"} {
+		cpe := []*wfn.Attributes{{Part: "a", Vendor: "foo", Product: "bar", Version: version}}
+		var expected, got []string
+		for _, r := range NewCache(dict).Get(cpe) {
+			expected = append(expected, r.CVE.CVEID())
+		}
+		for _, r := range NewCache(exported).Get(cpe) {
+			got = append(got, r.CVE.CVEID())
+		}
+		sort.Strings(expected)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", version, expected, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// findingKey identifies a finding: CVE ID and normalized CPE name
+type findingKey struct {
+	cve string
+	cpe wfn.Attributes
+}
+
+// findingKeys returns the keys of the result, one per CPE; results without CPEs are keyed by CVE ID alone
+func findingKeys(r MatchResult) []findingKey {
+	id := resultID(r)
+	if len(r.CPEs) == 0 {
+		return []findingKey{{cve: id}}
+	}
+	keys := make([]findingKey, len(r.CPEs))
+	for i, cpe := range r.CPEs {
+		keys[i].cve = id
+		if cpe != nil {
+			keys[i].cpe = *cpe.Normalize()
+		}
+	}
+	return keys
+}
+
+// DiffResults compares two sets of match results, e.g. of two scans of the same host, by (CVE, CPE) pairs:
+// added are the findings only in after (new), removed the ones only in before (fixed) and common the ones in both,
+// as they are in after. CPE names are compared once normalized, see wfn.Attributes.Normalize; results of several
+// CPEs are split between the sets if needed. The sets are sorted by CVE ID and CPE, regardless of the input order.
+func DiffResults(before, after []MatchResult) (added, removed, common []MatchResult) {
+	index := func(results []MatchResult) map[findingKey]bool {
+		keys := make(map[findingKey]bool)
+		for _, r := range results {
+			for _, k := range findingKeys(r) {
+				keys[k] = true
+			}
+		}
+		return keys
+	}
+	beforeKeys, afterKeys := index(before), index(after)
+	for _, r := range after {
+		in, out := splitResult(r, beforeKeys)
+		common = appendResult(common, in)
+		added = appendResult(added, out)
+	}
+	for _, r := range before {
+		_, out := splitResult(r, afterKeys)
+		removed = appendResult(removed, out)
+	}
+	for _, results := range [][]MatchResult{added, removed, common} {
+		sortFindings(results)
+	}
+	return added, removed, common
+}
+
+// splitResult splits the CPEs of the result into the ones whose findings are in keys and the others;
+// either part is nil if it has no CPEs
+func splitResult(r MatchResult, keys map[findingKey]bool) (in, out *MatchResult) {
+	rkeys := findingKeys(r)
+	if len(r.CPEs) == 0 {
+		if keys[rkeys[0]] {
+			return &r, nil
+		}
+		return nil, &r
+	}
+	parts := [2]*MatchResult{}
+	for i, k := range rkeys {
+		part := 1
+		if keys[k] {
+			part = 0
+		}
+		if parts[part] == nil {
+			parts[part] = &MatchResult{CVE: r.CVE, Rescored: r.Rescored, KnownExploited: r.KnownExploited}
+		}
+		p := parts[part]
+		p.CPEs = append(p.CPEs, r.CPEs[i])
+		if r.FixedIn != nil {
+			p.FixedIn = append(p.FixedIn, r.FixedIn[i])
+		}
+		if r.Platform != nil {
+			p.Platform = append(p.Platform, r.Platform[i])
+		}
+	}
+	return parts[0], parts[1]
+}
+
+func appendResult(results []MatchResult, r *MatchResult) []MatchResult {
+	if r == nil {
+		return results
+	}
+	return append(results, *r)
+}
+
+// sortFindings sorts results by CVE ID, then by the first CPE
+func sortFindings(results []MatchResult) {
+	firstCPE := func(r MatchResult) string {
+		if len(r.CPEs) == 0 || r.CPEs[0] == nil {
+			return ""
+		}
+		return r.CPEs[0].Normalize().BindToFmtString()
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if a, b := resultID(results[i]), resultID(results[j]); a != b {
+			return a < b
+		}
+		return firstCPE(results[i]) < firstCPE(results[j])
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestDiffResults(t *testing.T) {
+	bar := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	baz := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "2\\.0"}
+	barUpper := &wfn.Attributes{Part: "a", Vendor: "FOO", Product: "bar", Version: "1\\.0"}
+	before := []MatchResult{
+		{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{bar, baz}, FixedIn: []string{"1.1", "2.1"}},
+		{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{bar}},
+	}
+	after := []MatchResult{
+		{CVE: idCVE{id: "CVE-2020-0003"}, CPEs: []*wfn.Attributes{baz}},
+		{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{barUpper}},
+		{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{baz}, FixedIn: []string{"2.1"}},
+	}
+	added, removed, common := DiffResults(before, after)
+	if len(added) != 1 || added[0].CVE.CVEID() != "CVE-2020-0003" {
+		t.Errorf("expected CVE-2020-0003 to be added, got %+v", added)
+	}
+	if len(removed) != 1 || removed[0].CVE.CVEID() != "CVE-2020-0002" || len(removed[0].CPEs) != 1 ||
+		removed[0].CPEs[0] != bar || len(removed[0].FixedIn) != 1 || removed[0].FixedIn[0] != "1.1" {
+		t.Errorf("expected CVE-2020-0002 to be removed for %v only, got %+v", bar, removed)
+	}
+	if len(common) != 2 || common[0].CVE.CVEID() != "CVE-2020-0001" || common[0].CPEs[0] != barUpper ||
+		common[1].CVE.CVEID() != "CVE-2020-0002" || common[1].CPEs[0] != baz {
+		t.Errorf("expected CVE-2020-0001 and CVE-2020-0002 in common, got %+v", common)
+	}
+	if len(before[0].CPEs) != 2 {
+		t.Error("input results were modified")
+	}
+
+	// the order of the inputs doesn't matter
+	before[0], before[1] = before[1], before[0]
+	after[0], after[2] = after[2], after[0]
+	added2, removed2, common2 := DiffResults(before, after)
+	for i, pair := range [][2][]MatchResult{{added, added2}, {removed, removed2}, {common, common2}} {
+		if len(pair[0]) != len(pair[1]) {
+			t.Fatalf("set %d: lengths differ after reordering: %d vs %d", i, len(pair[0]), len(pair[1]))
+		}
+		for j := range pair[0] {
+			if pair[0][j].CVE.CVEID() != pair[1][j].CVE.CVEID() {
+				t.Errorf("set %d: result %d differs after reordering: %+v vs %+v", i, j, pair[0][j], pair[1][j])
+			}
+		}
+	}
+}
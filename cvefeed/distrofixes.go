@@ -0,0 +1,268 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// distro fix statuses of DistroFixes CSV other than the fixed version
+const (
+	notAffected   = "not_affected"
+	knownAffected = "affected"
+)
+
+// DistroFix is the verdict of a Linux distribution on a CVE affecting a package it ships. Distributions backport
+// fixes without bumping upstream versions, so their own data tell whether a build is vulnerable better than NVD.
+type DistroFix struct {
+	CVE string
+	// Package is the name of the (binary) package, lower case
+	Package string
+	// Release is the distribution release as in the dist tag of package releases, e.g. el8, or alpine3.18 for Alpine;
+	// empty means all releases
+	Release string
+	// FixedIn is [epoch:]version-release the fix shipped in, empty unless fixed
+	FixedIn string
+	// NotAffected tells the package of the release isn't affected at all
+	NotAffected bool
+}
+
+// String returns the status of the fix as in DistroFixes CSV
+func (f *DistroFix) String() string {
+	switch {
+	case f.NotAffected:
+		return notAffected
+	case f.FixedIn != "":
+		return f.FixedIn
+	default:
+		return knownAffected
+	}
+}
+
+// DistroFixes is a list of distribution verdicts applied to matches of rpm packages, keyed by CVE
+type DistroFixes map[string][]*DistroFix
+
+// Add adds the fix, replacing the one of the same CVE, package and release
+func (fs DistroFixes) Add(fix *DistroFix) {
+	for i, f := range fs[fix.CVE] {
+		if f.Package == fix.Package && f.Release == fix.Release {
+			fs[fix.CVE][i] = fix
+			return
+		}
+	}
+	fs[fix.CVE] = append(fs[fix.CVE], fix)
+}
+
+// ParseDistroFixes parses the distribution fixes from CSV with records of four fields:
+//
+//	CVE,package,release,status
+//
+// release is the dist tag of the release, e.g. el8 or alpine3.18, empty applies the record to all releases;
+// status is either the [epoch:]version-release the fix shipped in, "not_affected" or "affected" (not fixed yet).
+// Lines starting with # are comments.
+func ParseDistroFixes(in io.Reader) (DistroFixes, error) {
+	r := csv.NewReader(in)
+	r.Comment = '#'
+	r.FieldsPerRecord = 4
+	r.TrimLeadingSpace = true
+	fixes := make(DistroFixes)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("distro fixes: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+		f := &DistroFix{
+			CVE:     strings.TrimSpace(rec[0]),
+			Package: strings.ToLower(strings.TrimSpace(rec[1])),
+			Release: strings.ToLower(strings.TrimSpace(rec[2])),
+		}
+		if f.CVE == "" || f.Package == "" {
+			return nil, fmt.Errorf("distro fixes: line %d: CVE and package must be set", line)
+		}
+		switch status := strings.TrimSpace(rec[3]); strings.ToLower(status) {
+		case "":
+			return nil, fmt.Errorf("distro fixes: line %d: status is empty", line)
+		case notAffected:
+			f.NotAffected = true
+		case knownAffected:
+		default:
+			f.FixedIn = status
+		}
+		fixes.Add(f)
+	}
+	return fixes, nil
+}
+
+// LoadDistroFixes parses the distribution fixes from CSV file, see ParseDistroFixes
+func LoadDistroFixes(path string) (DistroFixes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("distro fixes: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseDistroFixes(f)
+}
+
+// Write writes the fixes as CSV, see ParseDistroFixes, sorted by CVE
+func (fs DistroFixes) Write(out io.Writer) error {
+	cves := make([]string, 0, len(fs))
+	for cve := range fs {
+		cves = append(cves, cve)
+	}
+	sort.Strings(cves)
+	w := csv.NewWriter(out)
+	for _, cve := range cves {
+		for _, f := range fs[cve] {
+			if err := w.Write([]string{f.CVE, f.Package, f.Release, f.String()}); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Apply returns match results amended by the distribution fixes. Only vulnerable CPEs of distribution packages
+// are considered, others are kept as is: rpm packages (see cpeparse.FromRPMName) with a dist tag in the release,
+// e.g. 7.el8_6, amended by the fixes of the release, and Alpine packages (see cpeparse.FromAPKPackage) with -rN
+// revision, amended by the fixes of alpine releases. CPEs of packages not affected or built at or after the fix
+// are removed from the results, and the others the distribution has a verdict on have FixedIn set to the fix,
+// if any. Results without CPEs left are dropped. The input results are not modified.
+func (fs DistroFixes) Apply(results []MatchResult) []MatchResult {
+	out := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		fixes := fs[r.CVE.CVEID()]
+		if len(fixes) == 0 {
+			out = append(out, r)
+			continue
+		}
+		res := r
+		res.CPEs, res.FixedIn, res.Platform = nil, nil, nil
+		fixedIn := make([]string, 0, len(r.CPEs))
+		var anyFixed bool
+		for i, cpe := range r.CPEs {
+			platform := r.Platform != nil && r.Platform[i]
+			fixed := ""
+			if r.FixedIn != nil {
+				fixed = r.FixedIn[i]
+			}
+			if pkg := distroPackageOf(cpe); pkg != nil && !platform {
+				if f := pkg.find(fixes); f != nil {
+					if f.NotAffected || f.FixedIn != "" && pkg.compare(f.FixedIn) >= 0 {
+						continue
+					}
+					if f.FixedIn != "" {
+						fixed = f.FixedIn
+					}
+				}
+			}
+			res.CPEs = append(res.CPEs, cpe)
+			fixedIn = append(fixedIn, fixed)
+			anyFixed = anyFixed || fixed != ""
+			if r.Platform != nil {
+				res.Platform = append(res.Platform, platform)
+			}
+		}
+		if anyFixed {
+			res.FixedIn = fixedIn
+		}
+		if len(res.CPEs) != 0 {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+var (
+	// rpmDistTag matches the dist tag of rpm releases, e.g. el8 in 7.el8_6 or 1.module+el8.6.0+14880+4e8a8bb6
+	rpmDistTag = regexp.MustCompile(`(?:^|[.+])(el\d+)`)
+	// apkRevision matches the revision of Alpine packages
+	apkRevision = regexp.MustCompile(`^r\d+$`)
+)
+
+// distroPackage is the build of distribution package a CPE names
+type distroPackage struct {
+	name    string
+	version string
+	// release is the dist tag the fixes apply to
+	release string
+	// prefixed tells the release is only the prefix of the dist tags, the build doesn't tell the release
+	prefixed bool
+	cmp      nvdcommon.VersionComparator
+}
+
+// distroPackageOf returns the distribution package the CPE names, nil if it isn't one
+func distroPackageOf(cpe *wfn.Attributes) *distroPackage {
+	if cpe.Version == wfn.Any || cpe.Version == wfn.NA {
+		return nil
+	}
+	name, ver, rel := wfn.StripSlashes(cpe.Product), wfn.StripSlashes(cpe.Version), wfn.StripSlashes(cpe.Update)
+	if m := rpmDistTag.FindStringSubmatch(rel); m != nil {
+		return &distroPackage{name: name, version: ver + "-" + rel, release: m[1], cmp: compareRPMFix}
+	}
+	if apkRevision.MatchString(rel) {
+		// Alpine packages don't tell the release they're built for, so the fixes of any alpine release apply
+		return &distroPackage{name: name, version: ver + "-" + rel, release: "alpine", prefixed: true, cmp: nvdcommon.CompareAPK}
+	}
+	return nil
+}
+
+// find returns the fix of the package for the release of its build;
+// fixes of the release take precedence over the ones of all releases
+func (p *distroPackage) find(fixes []*DistroFix) *DistroFix {
+	var any *DistroFix
+	for _, f := range fixes {
+		if f.Package != p.name {
+			continue
+		}
+		if f.Release == p.release || p.prefixed && strings.HasPrefix(f.Release, p.release) {
+			return f
+		}
+		if f.Release == "" {
+			any = f
+		}
+	}
+	return any
+}
+
+// compare compares the version of the package build to the fixed one
+func (p *distroPackage) compare(fixedIn string) int {
+	return p.cmp(p.version, fixedIn)
+}
+
+// compareRPMFix compares rpm versions disregarding the epoch of the fix: CPE names of rpm packages don't keep
+// the epoch, so it can't be compared
+func compareRPMFix(version, fixedIn string) int {
+	if i := strings.IndexByte(fixedIn, ':'); i != -1 {
+		fixedIn = fixedIn[i+1:]
+	}
+	return nvdcommon.CompareRPM(version, fixedIn)
+}
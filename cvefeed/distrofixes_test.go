@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestDistroFixes(t *testing.T) {
+	fs, err := ParseDistroFixes(strings.NewReader(`# Red Hat
+CVE-2020-0001,openssl,el8,1:1.1.1k-7.el8_6
+CVE-2020-0001,openssl,el9,not_affected
+CVE-2020-0002,openssl,,affected
+CVE-2020-0003,openssl-libs,el8,1.1.1k-9.el8
+CVE-2020-0005,musl,alpine3.16,1.2.3-r1
+CVE-2020-0005,busybox,alpine3.16,0
+CVE-2020-0005,openssl,alpine3.16,not_affected
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := func(name, ver, rel string) *wfn.Attributes {
+		return &wfn.Attributes{Part: "a", Product: name, Version: ver, Update: rel}
+	}
+	old8 := pkg("openssl", "1\\.1\\.1k", "5\\.el8")
+	new8 := pkg("openssl", "1\\.1\\.1k", "7\\.el8_6")
+	el9 := pkg("openssl", "3\\.0\\.1", "1\\.el9")
+	upstream := &wfn.Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: "1\\.1\\.1k"}
+	libs := pkg("openssl\\-libs", "1\\.1\\.1k", "9\\.el8")
+	musl := pkg("musl", "1\\.2\\.3", "r0")
+	muslFixed := pkg("musl", "1\\.2\\.3", "r1")
+	apkOpenSSL := pkg("openssl", "3\\.0\\.8", "r0")
+	results := []MatchResult{
+		{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{old8, new8, el9, upstream}, FixedIn: []string{"", "", "", "1.1.1l"}},
+		{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{new8}},
+		{CVE: idCVE{id: "CVE-2020-0003"}, CPEs: []*wfn.Attributes{libs}},
+		{CVE: idCVE{id: "CVE-2020-0004"}, CPEs: []*wfn.Attributes{old8}},
+		{CVE: idCVE{id: "CVE-2020-0005"}, CPEs: []*wfn.Attributes{musl, muslFixed, apkOpenSSL, new8}},
+	}
+	out := fs.Apply(results)
+	if len(out) != 4 {
+		t.Fatalf("expected 4 results, got %d: %+v", len(out), out)
+	}
+	if r := out[0]; r.CVE.CVEID() != "CVE-2020-0001" || len(r.CPEs) != 2 || r.CPEs[0] != old8 || r.CPEs[1] != upstream ||
+		len(r.FixedIn) != 2 || r.FixedIn[0] != "1:1.1.1k-7.el8_6" || r.FixedIn[1] != "1.1.1l" {
+		t.Errorf("expected the build before the fix and the upstream CPE to be kept, got %+v", r)
+	}
+	if r := out[1]; r.CVE.CVEID() != "CVE-2020-0002" || len(r.CPEs) != 1 || r.FixedIn != nil {
+		t.Errorf("expected the known affected package to be kept, got %+v", r)
+	}
+	if r := out[2]; r.CVE.CVEID() != "CVE-2020-0004" {
+		t.Errorf("expected CVE without fixes to be kept, got %+v", r)
+	}
+	if r := out[3]; r.CVE.CVEID() != "CVE-2020-0005" || len(r.CPEs) != 2 || r.CPEs[0] != musl || r.CPEs[1] != new8 ||
+		len(r.FixedIn) != 2 || r.FixedIn[0] != "1.2.3-r1" || r.FixedIn[1] != "" {
+		t.Errorf("expected Alpine fixes to apply to apk packages only, got %+v", r)
+	}
+	if len(results[0].CPEs) != 4 {
+		t.Errorf("input results were modified: %+v", results[0])
+	}
+
+	var buf bytes.Buffer
+	if err := fs.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseDistroFixes(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 4 || len(again["CVE-2020-0001"]) != 2 || again["CVE-2020-0001"][1].String() != notAffected {
+		t.Errorf("fixes didn't survive the round trip: %+v", again)
+	}
+}
+
+func TestDistroFixesErrors(t *testing.T) {
+	for _, in := range []string{
+		"CVE-2020-0001,openssl,el8\n",
+		",openssl,el8,affected\n",
+		"CVE-2020-0001,openssl,el8,\n",
+	} {
+		if _, err := ParseDistroFixes(strings.NewReader(in)); err == nil {
+			t.Errorf("expected %q to fail", in)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+)
+
+// EnvironmentalProfile is the environment of the asset the findings are rescored for: its security requirements
+// (CVSS CR, IR and AR metrics) as L (low), M (medium) or H (high); empty requirements are left not defined
+type EnvironmentalProfile struct {
+	CR, IR, AR string
+}
+
+// metrics returns the requirements of the profile which are set
+func (p EnvironmentalProfile) metrics() map[string]string {
+	ms := make(map[string]string, 3)
+	for metric, value := range map[string]string{"CR": p.CR, "IR": p.IR, "AR": p.AR} {
+		if value != "" {
+			ms[metric] = value
+		}
+	}
+	return ms
+}
+
+// RescoreFallback tells how findings without CVSS v3 vector are rescored, see RescoreEnvironmental
+type RescoreFallback int
+
+// Possible values of RescoreFallback
+const (
+	// FallbackV2 rescores the findings with CVSS v2 vector instead
+	FallbackV2 RescoreFallback = iota
+	// LeaveUnscored leaves the findings unscored
+	LeaveUnscored
+)
+
+// RescoredResult is a match result along with its environmental score, see RescoreEnvironmental
+type RescoredResult struct {
+	MatchResult
+	Score   float64 // environmental score in the environment of the profile
+	Version string  // CVSS version of the score: "3" or "2", empty if the result wasn't scored
+	Vector  string  // the vector of the CVE with the requirements of the profile, empty if the result wasn't scored
+}
+
+// RescoreEnvironmental scores the CVEs of match results in the environment of the profile: the requirements
+// of the profile are set on CVSS v3 vector of every CVE and its environmental score is computed.
+// CVEs without CVSS v3 vector (see nvdcommon.CVSSVectors) are rescored as per fallback; the ones whose vectors
+// don't parse are left unscored and logged. The results are returned in the same order, invalid profile is an error.
+func RescoreEnvironmental(results []MatchResult, p EnvironmentalProfile, fallback RescoreFallback) ([]RescoredResult, error) {
+	metrics := p.metrics()
+	// validate the profile once rather than for every CVE
+	if err := setMetrics(v3.NewVector().Set, metrics); err != nil {
+		return nil, fmt.Errorf("environmental profile: %v", err)
+	}
+	if err := setMetrics(v2.NewVector().Set, metrics); err != nil {
+		return nil, fmt.Errorf("environmental profile: %v", err)
+	}
+	rescored := make([]RescoredResult, len(results))
+	for i, r := range results {
+		rescored[i].MatchResult = r
+		cv, ok := r.CVE.(nvdcommon.CVSSVectors)
+		if !ok {
+			continue
+		}
+		var err error
+		if vector := cv.CVSS30vector(); vector != "" {
+			err = rescoreV3(&rescored[i], vector, metrics)
+		} else if vector = cv.CVSS20vector(); vector != "" && fallback == FallbackV2 {
+			err = rescoreV2(&rescored[i], vector, metrics)
+		}
+		if err != nil {
+			getLogger().Warnf("%s: leaving unscored: %v", r.CVE.CVEID(), err)
+		}
+	}
+	return rescored, nil
+}
+
+func rescoreV3(r *RescoredResult, vector string, metrics map[string]string) error {
+	v := v3.NewOrderedVector()
+	if err := v.Parse(vector); err != nil {
+		return fmt.Errorf("can't parse CVSS v3 vector %q: %v", vector, err)
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("invalid CVSS v3 vector %q: %v", vector, err)
+	}
+	if err := setMetrics(v.Set, metrics); err != nil {
+		return err
+	}
+	r.Score, r.Version, r.Vector = v.EnvironmentalScore(), "3", v.OriginalString()
+	return nil
+}
+
+func rescoreV2(r *RescoredResult, vector string, metrics map[string]string) error {
+	v := v2.NewOrderedVector()
+	if err := v.Parse(vector); err != nil {
+		return fmt.Errorf("can't parse CVSS v2 vector %q: %v", vector, err)
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("invalid CVSS v2 vector %q: %v", vector, err)
+	}
+	if err := setMetrics(v.Set, metrics); err != nil {
+		return err
+	}
+	r.Score, r.Version, r.Vector = v.EnvironmentalScore(), "2", v.OriginalString()
+	return nil
+}
+
+func setMetrics(set func(metric, value string) error, metrics map[string]string) error {
+	for metric, value := range metrics {
+		if err := set(metric, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"testing"
+)
+
+func TestRescoreEnvironmental(t *testing.T) {
+	const (
+		v2vector = "AV:N/AC:L/Au:N/C:P/I:N/A:N"
+		v3vector = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"
+	)
+	results := []MatchResult{
+		{CVE: vectoredCVE{scoredCVE{cvss20: 5.0, cvss30: 7.5}, v2vector, v3vector}},
+		{CVE: vectoredCVE{scoredCVE{cvss20: 5.0}, v2vector, ""}},
+		{CVE: vectoredCVE{scoredCVE{cvss30: 7.5}, "", "CVSS:3.1/AV:N/AC:L"}},
+		{CVE: scoredCVE{cvss30: 7.5}},
+	}
+	cases := []struct {
+		profile  EnvironmentalProfile
+		fallback RescoreFallback
+		scores   []float64
+		versions []string
+	}{
+		{EnvironmentalProfile{CR: "H"}, FallbackV2, []float64{9.3, 6.0, 0, 0}, []string{"3", "2", "", ""}},
+		{EnvironmentalProfile{CR: "L", IR: "H"}, FallbackV2, []float64{5.7, 3.9, 0, 0}, []string{"3", "2", "", ""}},
+		{EnvironmentalProfile{CR: "H"}, LeaveUnscored, []float64{9.3, 0, 0, 0}, []string{"3", "", "", ""}},
+		{EnvironmentalProfile{}, FallbackV2, []float64{7.5, 5.0, 0, 0}, []string{"3", "2", "", ""}},
+	}
+	for _, c := range cases {
+		rescored, err := RescoreEnvironmental(results, c.profile, c.fallback)
+		if err != nil {
+			t.Fatalf("%+v: %v", c.profile, err)
+		}
+		if len(rescored) != len(results) {
+			t.Fatalf("%+v: expected %d results, got %d", c.profile, len(results), len(rescored))
+		}
+		for i, r := range rescored {
+			if r.Score != c.scores[i] || r.Version != c.versions[i] {
+				t.Errorf("%+v, result %d: expected v%s score %.1f, got v%s %.1f (%s)", c.profile, i, c.versions[i], c.scores[i], r.Version, r.Score, r.Vector)
+			}
+		}
+	}
+	rescored, err := RescoreEnvironmental(results[:1], EnvironmentalProfile{CR: "H", AR: "L"}, FallbackV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := v3vector + "/AR:L/CR:H"; rescored[0].Vector != expected {
+		t.Errorf("expected vector %s, got %s", expected, rescored[0].Vector)
+	}
+	if _, err := RescoreEnvironmental(results, EnvironmentalProfile{CR: "critical"}, FallbackV2); err == nil {
+		t.Error("invalid profile is expected to be an error")
+	}
+}
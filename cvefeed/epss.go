@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EPSSURL is where FIRST publishes the daily Exploit Prediction Scoring System (EPSS) scores of all CVEs
+const EPSSURL = "https://epss.cyentia.com/epss_scores-current.csv.gz"
+
+// EPSSScore is the likelihood of a CVE being exploited in the next 30 days as estimated by EPSS
+type EPSSScore struct {
+	CVEID       string
+	Probability float64 // probability of exploitation, 0 to 1
+	Percentile  float64 // share of CVEs with the same or lower probability, 0 to 1
+}
+
+// EPSS is the set of EPSS scores keyed by CVE ID
+type EPSS map[string]*EPSSScore
+
+// ParseEPSS parses EPSS scores in the CSV format (cve,epss,percentile) FIRST publishes them in, plain or gzip'ed;
+// comment lines, e.g. the leading #model_version one, are skipped
+func ParseEPSS(in io.Reader) (EPSS, error) {
+	src, err := setupReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("epss: %v", err)
+	}
+	defer src.Close()
+	r := csv.NewReader(src)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("epss: can't read header: %v", err)
+	}
+	columns := map[string]int{"cve": -1, "epss": -1, "percentile": -1}
+	for i, name := range header {
+		if _, ok := columns[strings.TrimSpace(name)]; ok {
+			columns[strings.TrimSpace(name)] = i
+		}
+	}
+	for name, i := range columns {
+		if i < 0 {
+			return nil, fmt.Errorf("epss: no %s column in header %q, not EPSS scores", name, strings.Join(header, ","))
+		}
+	}
+	epss := make(EPSS)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("epss: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+		s, err := parseEPSSRecord(rec, columns)
+		if err != nil {
+			return nil, fmt.Errorf("epss: line %d: %v", line, err)
+		}
+		epss[s.CVEID] = s
+	}
+	return epss, nil
+}
+
+func parseEPSSRecord(rec []string, columns map[string]int) (*EPSSScore, error) {
+	for _, i := range columns {
+		if i >= len(rec) {
+			return nil, fmt.Errorf("expected at least %d fields, got %d", i+1, len(rec))
+		}
+	}
+	s := &EPSSScore{CVEID: strings.ToUpper(strings.TrimSpace(rec[columns["cve"]]))}
+	if s.CVEID == "" {
+		return nil, fmt.Errorf("CVE ID is empty")
+	}
+	var err error
+	for _, f := range []struct {
+		name string
+		v    *float64
+	}{
+		{"epss", &s.Probability},
+		{"percentile", &s.Percentile},
+	} {
+		if *f.v, err = strconv.ParseFloat(strings.TrimSpace(rec[columns[f.name]]), 64); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", s.CVEID, f.name, err)
+		}
+		if *f.v < 0 || *f.v > 1 {
+			return nil, fmt.Errorf("%s: %s %v out of range 0-1", s.CVEID, f.name, *f.v)
+		}
+	}
+	return s, nil
+}
+
+// LoadEPSS parses EPSS scores from CSV file, see ParseEPSS
+func LoadEPSS(path string) (EPSS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("epss: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseEPSS(f)
+}
+
+// FetchEPSS downloads EPSS scores from url, EPSSURL if empty, and parses them, see ParseEPSS
+func FetchEPSS(ctx context.Context, url string) (EPSS, error) {
+	if url == "" {
+		url = EPSSURL
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("epss: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("epss: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("epss: %q responded %q: %q", url, resp.Status, body)
+	}
+	return ParseEPSS(resp.Body)
+}
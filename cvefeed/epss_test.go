@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testEPSS = `#model_version:v2023.03.01,score_date:2023-10-14T00:00:00+0000
+cve,epss,percentile
+CVE-2020-0001,0.00043,0.08201
+cve-2020-0002,0.97565,0.99995
+`
+
+func TestEPSS(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	fmt.Fprint(w, testEPSS)
+	w.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gz.Bytes())
+	}))
+	defer ts.Close()
+
+	plain, err := ParseEPSS(strings.NewReader(testEPSS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched, err := FetchEPSS(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, epss := range map[string]EPSS{"plain": plain, "gzip": fetched} {
+		if len(epss) != 2 {
+			t.Errorf("%s: expected 2 scores, got %d", name, len(epss))
+		}
+		if s := epss["CVE-2020-0002"]; s == nil || s.Probability != 0.97565 || s.Percentile != 0.99995 {
+			t.Errorf("%s: unexpected score of CVE-2020-0002: %+v", name, s)
+		}
+	}
+}
+
+func TestParseEPSSErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"cve,percentile\nCVE-2020-0001,0.1\n",
+		"cve,epss,percentile\nCVE-2020-0001,high,0.1\n",
+		"cve,epss,percentile\nCVE-2020-0001,1.5,0.1\n",
+		"cve,epss,percentile\n,0.1,0.1\n",
+		"cve,epss,percentile\nCVE-2020-0001,0.1\n",
+	}
+	for _, in := range tests {
+		if _, err := ParseEPSS(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
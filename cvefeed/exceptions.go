@@ -0,0 +1,151 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// suppress is the action of exception which drops the finding
+const suppress = "suppress"
+
+// Exception is an accepted risk decision about a CVE affecting the CPE
+type Exception struct {
+	CVE string
+	// CPE the exception applies to, may contain ANY and wildcards; nil means all CPEs
+	CPE *wfn.Attributes
+	// Suppress drops the finding, otherwise its severity is overridden with Severity
+	Suppress bool
+	Severity cvss.Severity
+}
+
+// Exceptions is a list of accepted risk decisions applied after matching, keyed by CVE
+type Exceptions map[string][]*Exception
+
+// ParseExceptions parses the exceptions from CSV with records of three fields:
+//
+//	CVE,CPE,action
+//
+// CPE is a CPE name in URI or formatted string binding, empty CPE applies the exception to all CPEs;
+// action is either "suppress" or the severity the finding is rescored to, e.g. "low".
+// Lines starting with # are comments.
+func ParseExceptions(in io.Reader) (Exceptions, error) {
+	r := csv.NewReader(in)
+	r.Comment = '#'
+	r.FieldsPerRecord = 3
+	r.TrimLeadingSpace = true
+	exceptions := make(Exceptions)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("exceptions: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+		e := &Exception{CVE: strings.TrimSpace(rec[0])}
+		if e.CVE == "" {
+			return nil, fmt.Errorf("exceptions: line %d: CVE is empty", line)
+		}
+		if cpe := strings.TrimSpace(rec[1]); cpe != "" {
+			if e.CPE, err = wfn.Parse(cpe); err != nil {
+				return nil, fmt.Errorf("exceptions: line %d: %v", line, err)
+			}
+		}
+		if action := strings.TrimSpace(rec[2]); strings.EqualFold(action, suppress) {
+			e.Suppress = true
+		} else if e.Severity, err = cvss.ParseSeverity(action); err != nil {
+			return nil, fmt.Errorf("exceptions: line %d: %v", line, err)
+		}
+		exceptions[e.CVE] = append(exceptions[e.CVE], e)
+	}
+	return exceptions, nil
+}
+
+// LoadExceptions parses the exceptions from CSV file, see ParseExceptions
+func LoadExceptions(path string) (Exceptions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("exceptions: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseExceptions(f)
+}
+
+// Apply returns match results amended by the exceptions: suppressed CPEs are removed from the results,
+// results without vulnerable CPEs left (only platform ones, if any) are dropped, and rescored results have
+// Rescored set; when CPEs of a result are rescored by different exceptions, the highest severity wins, so
+// the outcome doesn't depend on the order of the CPEs. The input results are not modified.
+func (es Exceptions) Apply(results []MatchResult) []MatchResult {
+	out := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		exceptions := es[r.CVE.CVEID()]
+		if len(exceptions) == 0 {
+			out = append(out, r)
+			continue
+		}
+		res := r
+		res.CPEs, res.FixedIn, res.Platform = nil, nil, nil
+		var rescored *cvss.Severity
+		vulnerable := false
+		for i, cpe := range r.CPEs {
+			e := findException(exceptions, cpe)
+			if e != nil && e.Suppress {
+				continue
+			}
+			res.CPEs = append(res.CPEs, cpe)
+			if r.FixedIn != nil {
+				res.FixedIn = append(res.FixedIn, r.FixedIn[i])
+			}
+			platform := r.Platform != nil && r.Platform[i]
+			if r.Platform != nil {
+				res.Platform = append(res.Platform, platform)
+			}
+			vulnerable = vulnerable || !platform
+			if e != nil && (rescored == nil || e.Severity > *rescored) {
+				severity := e.Severity
+				rescored = &severity
+			}
+		}
+		if rescored != nil {
+			res.Rescored = rescored
+		}
+		if vulnerable {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// findException returns the first exception applicable to the CPE
+func findException(exceptions []*Exception, cpe *wfn.Attributes) *Exception {
+	for _, e := range exceptions {
+		if e.CPE == nil || wfn.Match(e.CPE, cpe) {
+			return e
+		}
+	}
+	return nil
+}
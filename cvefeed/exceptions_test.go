@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+type idCVE struct {
+	CVEItem
+	id string
+}
+
+func (c idCVE) CVEID() string { return c.id }
+
+func TestExceptions(t *testing.T) {
+	es, err := ParseExceptions(strings.NewReader(`# accepted risks
+CVE-2020-0001,cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*,suppress
+CVE-2020-0002,,low
+CVE-2020-0003,cpe:/a:foo:baz,Suppress
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	baz := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "2\\.0"}
+	results := []MatchResult{
+		{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{bar}},
+		{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{bar}},
+		{CVE: idCVE{id: "CVE-2020-0003"}, CPEs: []*wfn.Attributes{bar, baz}, FixedIn: []string{"1.1", "2.1"}},
+		{CVE: idCVE{id: "CVE-2020-0004"}, CPEs: []*wfn.Attributes{baz}},
+	}
+	out := es.Apply(results)
+	if len(out) != 3 {
+		t.Fatalf("expected 3 results, got %d: %+v", len(out), out)
+	}
+	if id := out[0].CVE.CVEID(); id != "CVE-2020-0002" || out[0].Rescored == nil || *out[0].Rescored != cvss.SeverityLow {
+		t.Errorf("expected %s to be rescored to low, got %+v", id, out[0])
+	}
+	if id := out[1].CVE.CVEID(); id != "CVE-2020-0003" || len(out[1].CPEs) != 1 || out[1].CPEs[0] != bar ||
+		len(out[1].FixedIn) != 1 || out[1].FixedIn[0] != "1.1" || out[1].Rescored != nil {
+		t.Errorf("expected %s to be suppressed for %v only, got %+v", id, baz, out[1])
+	}
+	if id := out[2].CVE.CVEID(); id != "CVE-2020-0004" || out[2].Rescored != nil {
+		t.Errorf("expected %s to be left intact, got %+v", id, out[2])
+	}
+	if len(results[2].CPEs) != 2 {
+		t.Error("input results were modified")
+	}
+}
+
+func TestExceptionsApplyMixed(t *testing.T) {
+	es, err := ParseExceptions(strings.NewReader(`CVE-2020-0001,cpe:/a:foo:bar,low
+CVE-2020-0001,cpe:/a:foo:baz,high
+CVE-2020-0002,cpe:/a:foo:bar,suppress
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	baz := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "2\\.0"}
+	os := &wfn.Attributes{Part: "o", Vendor: "foo", Product: "os"}
+	annotations := []Annotation{{Rule: "r1"}}
+	for _, cpes := range [][]*wfn.Attributes{{bar, baz}, {baz, bar}} {
+		out := es.Apply([]MatchResult{{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: cpes, Annotations: annotations}})
+		if len(out) != 1 || out[0].Rescored == nil || *out[0].Rescored != cvss.SeverityHigh {
+			t.Errorf("%v: expected to be rescored to the highest severity, got %+v", cpes, out)
+			continue
+		}
+		if len(out[0].Annotations) != 1 || out[0].Annotations[0].Rule != "r1" {
+			t.Errorf("%v: expected the annotations to be kept, got %+v", cpes, out[0].Annotations)
+		}
+	}
+	// only the platform CPE is left once the vulnerable one is suppressed
+	out := es.Apply([]MatchResult{{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{bar, os}, Platform: []bool{false, true}}})
+	if len(out) != 0 {
+		t.Errorf("expected the result without vulnerable CPEs to be dropped, got %+v", out)
+	}
+}
+
+func TestParseExceptionsErrors(t *testing.T) {
+	for _, in := range []string{
+		"CVE-2020-0001,,urgent\n",
+		",,low\n",
+		"CVE-2020-0001,foo:bar,low\n",
+		"CVE-2020-0001,low\n",
+	} {
+		if _, err := ParseExceptions(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
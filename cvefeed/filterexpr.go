@@ -0,0 +1,498 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+// FilterExpr selects match results by a boolean expression evaluated against each of them, e.g.
+//
+//	cvss3 >= 7 && published_after("2022-01-01") && !rejected
+//	severity >= "high" || kev
+//	contains(description, "overflow") && !has_cwe("CWE-476")
+//
+// The expressions are of Go syntax: &&, ||, !, parentheses, comparisons (==, !=, <, <=, >, >=), numbers, strings
+// and true and false. The variables are the ones of FilterVars and the functions:
+//
+//	published_after(date), published_before(date)  the CVE was published on or after, or before, YYYY-MM-DD (UTC)
+//	modified_after(date), modified_before(date)    the same of the date the CVE was last modified
+//	contains(text, substring)                      text contains the substring, in any lexical case
+//	matches(text, regexp)                          text matches the regular expression, see regexp package
+//	has_cwe(cwe)                                   the CVE lists the weakness (CWE-79 or 79) among its problem types
+//
+// Dates and regular expressions must be string literals. Strings compare insensitive to lexical case, only for
+// equality; severities compare in the order of severity with the severities and their names (none, low, medium,
+// high, critical). CVEs of unknown dates are neither published after nor before any date, their age_days isn't
+// a number, so any comparison of it is false.
+type FilterExpr struct {
+	expr string
+	eval func(*filterInput) bool
+	vars map[string]bool // the variables the expression refers to
+	now  time.Time
+	epss EPSS
+}
+
+// FilterVars lists the variables of filter expressions, see FilterExpr
+var FilterVars = map[string]string{
+	"cve":             "CVE ID",
+	"cvss":            "representative score (see RepresentativeScore), severity-only assessments score the lowest of their band",
+	"cvss2":           "CVSS v2 base score, 0 if the CVE wasn't assessed",
+	"cvss3":           "CVSS v3 base score, 0 if the CVE wasn't assessed",
+	"severity":        "severity of the representative score, or the one the finding was rescored to",
+	"epss":            "EPSS probability of exploitation, 0 if unknown (see FilterExpr.SetEPSS)",
+	"epss_percentile": "EPSS percentile, 0 if unknown",
+	"kev":             "the CVE is known exploited (see KEV)",
+	"rejected":        "the CVE was rejected (see IsRejected)",
+	"status":          "status of the CVE in the source, e.g. Analyzed (see CVEStatus)",
+	"description":     "description of the CVE",
+	"age_days":        "days since the CVE was published",
+	"fixed":           "the version any matched CPE was fixed in is known",
+}
+
+// filterInput is the match result a filter expression is evaluated against
+type filterInput struct {
+	r      MatchResult
+	f      *FilterExpr
+	score  Score
+	scored bool
+}
+
+func (in *filterInput) resultScore() Score {
+	if !in.scored {
+		in.score, in.scored = resultScore(in.r), true
+	}
+	return in.score
+}
+
+func (in *filterInput) epss() *EPSSScore {
+	return in.f.epss[in.r.CVE.CVEID()]
+}
+
+// filterType is the type of subexpressions of filter expressions
+type filterType int
+
+const (
+	filterBool filterType = iota
+	filterNumber
+	filterString
+	filterSeverity // the number of cvss.Severity
+)
+
+func (t filterType) String() string {
+	switch t {
+	case filterBool:
+		return "boolean"
+	case filterNumber:
+		return "number"
+	case filterString:
+		return "string"
+	case filterSeverity:
+		return "severity"
+	default:
+		return "unknown"
+	}
+}
+
+// filterNode is the compiled subexpression of the filter expression; the function of its type is set,
+// numbers and severities are evaluated by num
+type filterNode struct {
+	typ     filterType
+	boolean func(*filterInput) bool
+	num     func(*filterInput) float64
+	str     func(*filterInput) string
+	lit     *string // the value of string literals
+}
+
+// filterVars are the compiled variables of FilterVars
+var filterVars = map[string]filterNode{
+	"cve": {typ: filterString, str: func(in *filterInput) string { return in.r.CVE.CVEID() }},
+	"cvss": {typ: filterNumber, num: func(in *filterInput) float64 {
+		return in.resultScore().MinScore()
+	}},
+	"cvss2": {typ: filterNumber, num: func(in *filterInput) float64 { return in.r.CVE.CVSS20base() }},
+	"cvss3": {typ: filterNumber, num: func(in *filterInput) float64 { return in.r.CVE.CVSS30base() }},
+	"severity": {typ: filterSeverity, num: func(in *filterInput) float64 {
+		return float64(in.resultScore().Severity)
+	}},
+	"epss": {typ: filterNumber, num: func(in *filterInput) float64 {
+		if s := in.epss(); s != nil {
+			return s.Probability
+		}
+		return 0
+	}},
+	"epss_percentile": {typ: filterNumber, num: func(in *filterInput) float64 {
+		if s := in.epss(); s != nil {
+			return s.Percentile
+		}
+		return 0
+	}},
+	"kev":      {typ: filterBool, boolean: func(in *filterInput) bool { return in.r.KnownExploited }},
+	"rejected": {typ: filterBool, boolean: func(in *filterInput) bool { return IsRejected(in.r.CVE) }},
+	"status":   {typ: filterString, str: func(in *filterInput) string { return CVEStatus(in.r.CVE) }},
+	"description": {typ: filterString, str: func(in *filterInput) string {
+		if d, ok := in.r.CVE.(nvdcommon.CVEDescription); ok {
+			return d.Description()
+		}
+		return ""
+	}},
+	"age_days": {typ: filterNumber, num: func(in *filterInput) float64 {
+		published := publishedDate(in.r.CVE)
+		if published.IsZero() {
+			return math.NaN()
+		}
+		return math.Floor(in.f.now.Sub(published).Hours() / 24)
+	}},
+	"fixed": {typ: filterBool, boolean: func(in *filterInput) bool {
+		for _, v := range in.r.FixedIn {
+			if v != "" {
+				return true
+			}
+		}
+		return false
+	}},
+}
+
+func publishedDate(cve CVEItem) time.Time {
+	if d, ok := cve.(nvdcommon.CVEDates); ok {
+		return d.Published()
+	}
+	return time.Time{}
+}
+
+func lastModifiedDate(cve CVEItem) time.Time {
+	if d, ok := cve.(nvdcommon.CVEDates); ok {
+		return d.LastModified()
+	}
+	return time.Time{}
+}
+
+// ParseFilter compiles the filter expression, see FilterExpr; unknown variables and functions and mismatched
+// types are an error
+func ParseFilter(expr string) (*FilterExpr, error) {
+	fset := token.NewFileSet()
+	tree, err := parser.ParseExprFrom(fset, "", expr, 0)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v", err)
+	}
+	f := &FilterExpr{expr: expr, vars: make(map[string]bool), now: time.Now()}
+	c := filterCompiler{fset: fset, f: f}
+	node, err := c.compile(tree)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v", err)
+	}
+	if node.typ != filterBool {
+		return nil, fmt.Errorf("filter: expression is %s, not boolean", node.typ)
+	}
+	f.eval = node.boolean
+	return f, nil
+}
+
+// String returns the source of the expression
+func (f *FilterExpr) String() string {
+	return f.expr
+}
+
+// SetTime sets the time age_days is counted to, now by default.
+// Returns a pointer to the instance of FilterExpr, for easy chaining.
+func (f *FilterExpr) SetTime(t time.Time) *FilterExpr {
+	f.now = t
+	return f
+}
+
+// SetEPSS sets the EPSS scores of epss and epss_percentile variables.
+// Returns a pointer to the instance of FilterExpr, for easy chaining.
+func (f *FilterExpr) SetEPSS(epss EPSS) *FilterExpr {
+	f.epss = epss
+	return f
+}
+
+// Uses tells the expression refers to any of the variables, e.g. the ones which need data from elsewhere
+func (f *FilterExpr) Uses(vars ...string) bool {
+	for _, v := range vars {
+		if f.vars[v] {
+			return true
+		}
+	}
+	return false
+}
+
+// Match tells the match result passes the filter
+func (f *FilterExpr) Match(r MatchResult) bool {
+	if r.CVE == nil {
+		return false
+	}
+	return f.eval(&filterInput{r: r, f: f})
+}
+
+// Apply returns the results which pass the filter
+func (f *FilterExpr) Apply(results []MatchResult) []MatchResult {
+	var filtered []MatchResult
+	for _, r := range results {
+		if f.Match(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// filterCompiler compiles the syntax tree of the filter expression
+type filterCompiler struct {
+	fset *token.FileSet
+	f    *FilterExpr
+}
+
+func (c filterCompiler) errorf(n ast.Node, format string, args ...interface{}) error {
+	pos := c.fset.Position(n.Pos())
+	return fmt.Errorf("%d:%d: %s", pos.Line, pos.Column, fmt.Sprintf(format, args...))
+}
+
+func (c filterCompiler) compile(n ast.Expr) (filterNode, error) {
+	switch n := n.(type) {
+	case *ast.ParenExpr:
+		return c.compile(n.X)
+	case *ast.Ident:
+		switch n.Name {
+		case "true", "false":
+			v := n.Name == "true"
+			return filterNode{typ: filterBool, boolean: func(*filterInput) bool { return v }}, nil
+		}
+		node, ok := filterVars[n.Name]
+		if !ok {
+			return filterNode{}, c.errorf(n, "unknown variable %q", n.Name)
+		}
+		c.f.vars[n.Name] = true
+		return node, nil
+	case *ast.BasicLit:
+		switch n.Kind {
+		case token.INT, token.FLOAT:
+			v, err := strconv.ParseFloat(n.Value, 64)
+			if err != nil {
+				return filterNode{}, c.errorf(n, "bad number %s", n.Value)
+			}
+			return filterNode{typ: filterNumber, num: func(*filterInput) float64 { return v }}, nil
+		case token.STRING:
+			v, err := strconv.Unquote(n.Value)
+			if err != nil {
+				return filterNode{}, c.errorf(n, "bad string %s", n.Value)
+			}
+			return filterNode{typ: filterString, str: func(*filterInput) string { return v }, lit: &v}, nil
+		}
+		return filterNode{}, c.errorf(n, "unsupported literal %s, use double quoted strings", n.Value)
+	case *ast.UnaryExpr:
+		x, err := c.compile(n.X)
+		if err != nil {
+			return filterNode{}, err
+		}
+		switch {
+		case n.Op == token.NOT && x.typ == filterBool:
+			return filterNode{typ: filterBool, boolean: func(in *filterInput) bool { return !x.boolean(in) }}, nil
+		case n.Op == token.SUB && x.typ == filterNumber:
+			return filterNode{typ: filterNumber, num: func(in *filterInput) float64 { return -x.num(in) }}, nil
+		}
+		return filterNode{}, c.errorf(n, "operator %s isn't defined on %s", n.Op, x.typ)
+	case *ast.BinaryExpr:
+		return c.compileBinary(n)
+	case *ast.CallExpr:
+		return c.compileCall(n)
+	}
+	return filterNode{}, c.errorf(n, "unsupported expression")
+}
+
+func (c filterCompiler) compileBinary(n *ast.BinaryExpr) (filterNode, error) {
+	x, err := c.compile(n.X)
+	if err != nil {
+		return filterNode{}, err
+	}
+	y, err := c.compile(n.Y)
+	if err != nil {
+		return filterNode{}, err
+	}
+	switch n.Op {
+	case token.LAND, token.LOR:
+		if x.typ != filterBool || y.typ != filterBool {
+			return filterNode{}, c.errorf(n, "operator %s needs booleans, got %s and %s", n.Op, x.typ, y.typ)
+		}
+		if n.Op == token.LAND {
+			return filterNode{typ: filterBool, boolean: func(in *filterInput) bool { return x.boolean(in) && y.boolean(in) }}, nil
+		}
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool { return x.boolean(in) || y.boolean(in) }}, nil
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+	default:
+		return filterNode{}, c.errorf(n, "unsupported operator %s", n.Op)
+	}
+
+	// severities compare with their names
+	if x.typ == filterSeverity && y.typ == filterString {
+		if y, err = c.severity(n.Y, y); err != nil {
+			return filterNode{}, err
+		}
+	} else if x.typ == filterString && y.typ == filterSeverity {
+		if x, err = c.severity(n.X, x); err != nil {
+			return filterNode{}, err
+		}
+	}
+	if x.typ != y.typ {
+		return filterNode{}, c.errorf(n, "mismatched types %s and %s", x.typ, y.typ)
+	}
+	op := n.Op
+	switch x.typ {
+	case filterNumber, filterSeverity:
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			a, b := x.num(in), y.num(in)
+			switch op {
+			case token.EQL:
+				return a == b
+			case token.NEQ:
+				return a != b
+			case token.LSS:
+				return a < b
+			case token.LEQ:
+				return a <= b
+			case token.GTR:
+				return a > b
+			default:
+				return a >= b
+			}
+		}}, nil
+	case filterString:
+		if op != token.EQL && op != token.NEQ {
+			return filterNode{}, c.errorf(n, "strings only compare with == and !=")
+		}
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			return strings.EqualFold(x.str(in), y.str(in)) == (op == token.EQL)
+		}}, nil
+	default: // booleans
+		if op != token.EQL && op != token.NEQ {
+			return filterNode{}, c.errorf(n, "booleans only compare with == and !=")
+		}
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			return (x.boolean(in) == y.boolean(in)) == (op == token.EQL)
+		}}, nil
+	}
+}
+
+// severity returns the severity of the name of the string literal
+func (c filterCompiler) severity(n ast.Expr, s filterNode) (filterNode, error) {
+	if s.lit == nil {
+		return filterNode{}, c.errorf(n, "severities only compare with severities and string literals")
+	}
+	sev, err := cvss.ParseSeverity(*s.lit)
+	if err != nil {
+		return filterNode{}, c.errorf(n, "%v", err)
+	}
+	return filterNode{typ: filterSeverity, num: func(*filterInput) float64 { return float64(sev) }}, nil
+}
+
+// filterFuncs are the functions of filter expressions in lexical order, see compileCall
+var filterFuncs = []string{
+	"contains", "has_cwe", "matches", "modified_after", "modified_before", "published_after", "published_before",
+}
+
+func (c filterCompiler) compileCall(n *ast.CallExpr) (filterNode, error) {
+	fn, ok := n.Fun.(*ast.Ident)
+	if !ok {
+		return filterNode{}, c.errorf(n, "unsupported call")
+	}
+	var args []filterNode
+	for _, a := range n.Args {
+		arg, err := c.compile(a)
+		if err != nil {
+			return filterNode{}, err
+		}
+		args = append(args, arg)
+	}
+	// checkArgs checks the arguments are of the types, literal ones must be string literals
+	checkArgs := func(literal bool, types ...filterType) error {
+		if len(args) != len(types) || n.Ellipsis.IsValid() {
+			return c.errorf(n, "%s takes %d arguments, got %d", fn.Name, len(types), len(args))
+		}
+		for i, t := range types {
+			if args[i].typ != t {
+				return c.errorf(n.Args[i], "argument %d of %s is %s, not %s", i+1, fn.Name, args[i].typ, t)
+			}
+		}
+		if literal && args[len(args)-1].lit == nil {
+			return c.errorf(n.Args[len(args)-1], "the last argument of %s must be a string literal", fn.Name)
+		}
+		return nil
+	}
+
+	switch fn.Name {
+	case "published_after", "published_before", "modified_after", "modified_before":
+		if err := checkArgs(true, filterString); err != nil {
+			return filterNode{}, err
+		}
+		t, err := time.Parse("2006-01-02", *args[0].lit)
+		if err != nil {
+			return filterNode{}, c.errorf(n.Args[0], "bad date %q, expected YYYY-MM-DD", *args[0].lit)
+		}
+		date := publishedDate
+		if strings.HasPrefix(fn.Name, "modified") {
+			date = lastModifiedDate
+		}
+		after := strings.HasSuffix(fn.Name, "after")
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			d := date(in.r.CVE)
+			if d.IsZero() {
+				return false
+			}
+			return !d.Before(t) == after
+		}}, nil
+	case "contains":
+		if err := checkArgs(false, filterString, filterString); err != nil {
+			return filterNode{}, err
+		}
+		text, sub := args[0], args[1]
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			return strings.Contains(strings.ToLower(text.str(in)), strings.ToLower(sub.str(in)))
+		}}, nil
+	case "matches":
+		if err := checkArgs(true, filterString, filterString); err != nil {
+			return filterNode{}, err
+		}
+		re, err := regexp.Compile(*args[1].lit)
+		if err != nil {
+			return filterNode{}, c.errorf(n.Args[1], "%v", err)
+		}
+		text := args[0]
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool { return re.MatchString(text.str(in)) }}, nil
+	case "has_cwe":
+		if err := checkArgs(true, filterString); err != nil {
+			return filterNode{}, err
+		}
+		cwe := normalizeCWE(*args[0].lit)
+		return filterNode{typ: filterBool, boolean: func(in *filterInput) bool {
+			for _, pt := range in.r.CVE.ProblemTypes() {
+				if normalizeCWE(pt) == cwe {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	}
+	return filterNode{}, c.errorf(n, "unknown function %q, known are %s", fn.Name, strings.Join(filterFuncs, ", "))
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFilterFeed = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2022-0001","vulnStatus":"Analyzed","published":"2022-03-01T10:00:00.000","lastModified":"2023-01-01T00:00:00.000",
+		"descriptions":[{"lang":"en","value":"Buffer overflow in the widget parser."}],
+		"weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-787"}]}],
+		"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1",
+			"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}}]}}},
+	{"cve":{"id":"CVE-2021-0002","vulnStatus":"Analyzed","published":"2021-06-01T10:00:00.000","lastModified":"2021-07-01T00:00:00.000",
+		"descriptions":[{"lang":"en","value":"Cross-site scripting in the widget console."}],
+		"weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-79"}]}],
+		"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1",
+			"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N","baseScore":6.1,"baseSeverity":"MEDIUM"}}]}}},
+	{"cve":{"id":"CVE-2020-0003","vulnStatus":"Rejected","published":"2020-01-01T10:00:00.000",
+		"descriptions":[{"lang":"en","value":"** REJECT ** Duplicate."}]}}]}`
+
+func TestFilterExpr(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(testFilterFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []MatchResult
+	for _, cve := range items {
+		results = append(results, MatchResult{CVE: cve, KnownExploited: cve.CVEID() == "CVE-2021-0002"})
+	}
+	epss := EPSS{"CVE-2021-0002": {CVEID: "CVE-2021-0002", Probability: 0.5, Percentile: 0.97}}
+	now := time.Date(2022, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		expr string
+		ids  string
+	}{
+		{`cvss3 >= 7`, "CVE-2022-0001"},
+		{`cvss >= 6 && cvss < 7`, "CVE-2021-0002"},
+		{`cvss3 >= 7 || kev`, "CVE-2022-0001 CVE-2021-0002"},
+		{`severity >= "high"`, "CVE-2022-0001"},
+		{`severity == "None"`, "CVE-2020-0003"},
+		{`"medium" <= severity`, "CVE-2022-0001 CVE-2021-0002"},
+		{`!rejected`, "CVE-2022-0001 CVE-2021-0002"},
+		{`rejected == false && !(cvss2 > 0)`, "CVE-2022-0001 CVE-2021-0002"},
+		{`published_after("2022-01-01")`, "CVE-2022-0001"},
+		{`published_after("2022-03-01")`, "CVE-2022-0001"},
+		{`published_before("2021-06-01")`, "CVE-2020-0003"},
+		{`modified_after("2022-01-01") || modified_before("2021-07-02")`, "CVE-2022-0001 CVE-2021-0002"},
+		{`age_days <= 10`, "CVE-2022-0001"},
+		{`!(age_days > 10)`, "CVE-2022-0001"},
+		{`contains(description, "OVERFLOW")`, "CVE-2022-0001"},
+		{`matches(description, "^Cross-site")`, "CVE-2021-0002"},
+		{`has_cwe("79") || has_cwe("CWE-787")`, "CVE-2022-0001 CVE-2021-0002"},
+		{`cve == "cve-2020-0003" && status != "analyzed"`, "CVE-2020-0003"},
+		{`epss > 0.1 && epss_percentile >= 0.95`, "CVE-2021-0002"},
+		{`fixed`, ""},
+		{`cvss > -1 && true`, "CVE-2022-0001 CVE-2021-0002 CVE-2020-0003"},
+	}
+	for _, c := range cases {
+		f, err := ParseFilter(c.expr)
+		if err != nil {
+			t.Errorf("%s: %v", c.expr, err)
+			continue
+		}
+		f.SetTime(now).SetEPSS(epss)
+		var ids []string
+		for _, r := range f.Apply(results) {
+			ids = append(ids, r.CVE.CVEID())
+		}
+		if got := strings.Join(ids, " "); got != c.ids {
+			t.Errorf("%s: expected %q, got %q", c.expr, c.ids, got)
+		}
+	}
+
+	f, err := ParseFilter(`epss > 0.5 || cvss3 > 9`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Uses("epss", "epss_percentile") || f.Uses("kev") {
+		t.Error("unexpected variables used")
+	}
+	if len(FilterVars) != len(filterVars) {
+		t.Error("variables of filter expressions aren't all documented")
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`cvss3 >=`,
+		`cvss3`,
+		`cvss4 > 7`,
+		`cvss3 > "7"`,
+		`severity > "severe"`,
+		`severity > description`,
+		`description < "a"`,
+		`kev > rejected`,
+		`!cvss`,
+		`-kev`,
+		`cvss + 1 > 2`,
+		`'a' == cve`,
+		`published_after(description)`,
+		`published_after("2022")`,
+		`published_after("2022-01-01", "2023-01-01")`,
+		`matches(description, "(")`,
+		`contains(description, 7)`,
+		`exploited()`,
+		`strings.Contains(description, "a")`,
+		`cve[0] == "C"`,
+	} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// Kinds of changes recorded in the change journal, see JournalEntry
+const (
+	JournalAdded     = "added"     // the CVE was new to the synced feed
+	JournalModified  = "modified"  // the CVE was modified since the previous sync
+	JournalWithdrawn = "withdrawn" // the CVE was rejected or dropped from the feed
+)
+
+// JournalEntry is an entry of the change journal nvdsync records the changes of synced feeds in (-journal flag)
+type JournalEntry struct {
+	Time time.Time `json:"time"` // the time of the sync
+	Feed string    `json:"feed"` // the synced local file
+	Kind string    `json:"kind"` // JournalAdded, JournalModified or JournalWithdrawn
+	ID   string    `json:"id"`   // CVE ID, or CPE name ID in the journals of CPE feeds
+}
+
+// ParseJournal parses the change journal, one JSON object per line (JSONL)
+func ParseJournal(in io.Reader) ([]JournalEntry, error) {
+	var journal []JournalEntry
+	dec := json.NewDecoder(in)
+	for line := 1; ; line++ {
+		var e JournalEntry
+		if err := dec.Decode(&e); err == io.EOF {
+			return journal, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("journal: malformed entry %d: %v", line, err)
+		}
+		switch e.Kind {
+		case JournalAdded, JournalModified, JournalWithdrawn:
+		default:
+			return nil, fmt.Errorf("journal: entry %d: unknown kind of change %q", line, e.Kind)
+		}
+		journal = append(journal, e)
+	}
+}
+
+// LoadJournal parses the change journals from files, see ParseJournal
+func LoadJournal(paths ...string) ([]JournalEntry, error) {
+	var journal []JournalEntry
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("journal: failed to load %q: %v", path, err)
+		}
+		entries, err := ParseJournal(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("journal: %q: %v", path, err)
+		}
+		journal = append(journal, entries...)
+	}
+	return journal, nil
+}
+
+// Timeline is the history of a CVE: the dates the feed tells and the changes recorded in the change journal
+type Timeline struct {
+	Published    time.Time      // zero if unknown
+	LastModified time.Time      // zero if unknown
+	FirstSeen    time.Time      // the time the CVE was first synced, zero if the journal doesn't tell
+	Withdrawn    time.Time      // the time the CVE was withdrawn, zero if it wasn't or it was synced again since
+	Changes      []JournalEntry // the journal entries of the CVE, in the order of time
+}
+
+// KnownAt tells whether the CVE was known at t. The journal is the record of what was known: the CVE is known
+// since it was added or modified until it's withdrawn. Before the first entry of the journal, and for CVEs the
+// journal doesn't mention, the CVE is known if it was published by t, unless the journal tells it was added later.
+func (tl Timeline) KnownAt(t time.Time) bool {
+	known := len(tl.Changes) == 0 || tl.Changes[0].Kind != JournalAdded
+	known = known && !tl.Published.IsZero() && !tl.Published.After(t)
+	for _, c := range tl.Changes {
+		if c.Time.After(t) {
+			break
+		}
+		known = c.Kind != JournalWithdrawn
+	}
+	return known
+}
+
+// ModifiedAfter tells whether the CVE was modified after t, i.e. the data of the CVE differs from what was known at t
+func (tl Timeline) ModifiedAfter(t time.Time) bool {
+	if tl.LastModified.After(t) {
+		return true
+	}
+	for _, c := range tl.Changes {
+		if c.Time.After(t) && c.Kind == JournalModified {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicationLag returns the time from the publication of the CVE to its first sync, e.g. to measure
+// time-to-publish of the feed against time-to-patch; false if either is unknown
+func (tl Timeline) PublicationLag() (time.Duration, bool) {
+	if tl.Published.IsZero() || tl.FirstSeen.IsZero() {
+		return 0, false
+	}
+	return tl.FirstSeen.Sub(tl.Published), true
+}
+
+// History is a temporal index of the dictionary: it tells what was known about CVEs as of a given time,
+// as per their publication and modification dates and the change journal (see LoadJournal).
+// Only the current data of CVEs is kept, e.g. configurations modified since are matched as they are now;
+// ModifiedAfter tells apart the CVEs whose data changed.
+type History struct {
+	dict      Dictionary
+	timelines map[string]*Timeline
+}
+
+// NewHistory creates new History of the dictionary and the change journal; journal entries of IDs missing from the
+// dictionary (e.g. CPE name IDs or CVEs dropped from the feeds) have timelines, but they're never in AsOf
+func NewHistory(d Dictionary, journal []JournalEntry) *History {
+	h := &History{dict: d, timelines: make(map[string]*Timeline, len(d))}
+	for id, cve := range d {
+		tl := &Timeline{}
+		if dates, ok := cve.(nvdcommon.CVEDates); ok {
+			tl.Published, tl.LastModified = dates.Published(), dates.LastModified()
+		}
+		h.timelines[id] = tl
+	}
+	journal = append([]JournalEntry(nil), journal...)
+	sort.SliceStable(journal, func(i, j int) bool { return journal[i].Time.Before(journal[j].Time) })
+	for _, e := range journal {
+		tl, ok := h.timelines[e.ID]
+		if !ok {
+			tl = &Timeline{}
+			h.timelines[e.ID] = tl
+		}
+		if len(tl.Changes) == 0 && e.Kind == JournalAdded {
+			tl.FirstSeen = e.Time
+		}
+		if e.Kind == JournalWithdrawn {
+			tl.Withdrawn = e.Time
+		} else {
+			tl.Withdrawn = time.Time{}
+		}
+		tl.Changes = append(tl.Changes, e)
+	}
+	return h
+}
+
+// Timeline returns the timeline of the CVE, false if neither the dictionary nor the journal knows it
+func (h *History) Timeline(id string) (Timeline, bool) {
+	tl, ok := h.timelines[id]
+	if !ok {
+		return Timeline{}, false
+	}
+	return *tl, true
+}
+
+// AsOf returns the dictionary of CVEs known at t, see Timeline.KnownAt.
+// Match CPEs against it (e.g. with NewCache) to tell what was known about them at t.
+func (h *History) AsOf(t time.Time) Dictionary {
+	d := Dictionary{}
+	for id, cve := range h.dict {
+		if h.timelines[id].KnownAt(t) {
+			d[id] = cve
+		}
+	}
+	return d
+}
+
+// ModifiedAfter returns IDs of the CVEs known at t which were modified after, sorted
+func (h *History) ModifiedAfter(t time.Time) []string {
+	var ids []string
+	for id := range h.dict {
+		if tl := h.timelines[id]; tl.KnownAt(t) && tl.ModifiedAfter(t) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictDates))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	journal, err := ParseJournal(strings.NewReader(testJournal))
+	if err != nil {
+		t.Fatalf("failed to parse the journal: %v", err)
+	}
+	date := func(s string) time.Time {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	h := NewHistory(dict, journal)
+	cases := []struct {
+		asOf     string
+		expected []string
+	}{
+		{"2018-06-01", []string{"CVE-2018-0001"}},
+		{"2019-05-05", []string{"CVE-2018-0001", "CVE-2019-0001", "CVE-2019-0003"}},
+		{"2019-06-02", []string{"CVE-2018-0001", "CVE-2019-0001"}},
+		{"2019-06-05", []string{"CVE-2018-0001", "CVE-2019-0001", "CVE-2019-0002"}},
+	}
+	for _, c := range cases {
+		var ids []string
+		for id := range h.AsOf(date(c.asOf)) {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("as of %s: expected %v, got %v", c.asOf, c.expected, ids)
+		}
+	}
+
+	if ids := h.ModifiedAfter(date("2018-06-01")); !reflect.DeepEqual(ids, []string{"CVE-2018-0001"}) {
+		t.Errorf("expected CVE-2018-0001 to be modified after 2018-06-01, got %v", ids)
+	}
+	tl, ok := h.Timeline("CVE-2019-0002")
+	if !ok {
+		t.Fatal("no timeline of CVE-2019-0002")
+	}
+	if lag, ok := tl.PublicationLag(); !ok || lag != 48*time.Hour {
+		t.Errorf("expected publication lag of 48h, got %v (%t)", lag, ok)
+	}
+	if tl, _ := h.Timeline("CVE-2019-0003"); !tl.Withdrawn.Equal(date("2019-05-10")) {
+		t.Errorf("expected CVE-2019-0003 withdrawn on 2019-05-10, got %v", tl.Withdrawn)
+	}
+	if tl, _ := h.Timeline("CVE-2018-0001"); !tl.FirstSeen.IsZero() {
+		t.Errorf("CVE-2018-0001 was synced before the journal, expected unknown first sync, got %v", tl.FirstSeen)
+	}
+	if _, ok := h.Timeline("CVE-2017-0001"); !ok {
+		t.Error("CVEs only the journal knows expected to have timelines")
+	}
+
+	if _, err := ParseJournal(strings.NewReader(`{"time":"2019-05-02T00:00:00Z","kind":"renamed","id":"CVE-2019-0001"}`)); err == nil {
+		t.Error("unknown kind of change expected to be an error")
+	}
+}
+
+var testJournal = `{"time":"2019-05-02T10:00:00Z","feed":"nvdcve-1.1-2018.json.gz","kind":"modified","id":"CVE-2018-0001"}
+{"time":"2019-05-02T10:00:00Z","feed":"nvdcve-1.1-2019.json.gz","kind":"added","id":"CVE-2019-0003"}
+{"time":"2019-06-03T10:00:00Z","feed":"nvdcve-1.1-2019.json.gz","kind":"added","id":"CVE-2019-0002"}
+{"time":"2019-05-10T00:00:00Z","feed":"nvdcve-1.1-2019.json.gz","kind":"withdrawn","id":"CVE-2019-0003"}
+{"time":"2019-05-10T00:00:00Z","feed":"nvdcve-1.1-2017.json.gz","kind":"withdrawn","id":"CVE-2017-0001"}
+`
@@ -0,0 +1,129 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+// The types below model CVE records of CVE JSON 5.x format published by CVE Services (cve.org):
+// https://github.com/CVEProject/cve-schema/blob/main/schema/CVE_Record_Format.json
+// Only the fields used by nvdtools are defined.
+
+// CVE5Record is a CVE record.
+type CVE5Record struct {
+	Containers  *CVE5Containers `json:"containers"`
+	CVEMetadata *CVE5Metadata   `json:"cveMetadata"`
+	DataType    string          `json:"dataType"`
+	DataVersion string          `json:"dataVersion"`
+}
+
+// CVE5Metadata is the metadata of a CVE record.
+type CVE5Metadata struct {
+	AssignerOrgID     string `json:"assignerOrgId"`
+	AssignerShortName string `json:"assignerShortName,omitempty"`
+	CVEID             string `json:"cveId"`
+	DatePublished     string `json:"datePublished,omitempty"`
+	DateReserved      string `json:"dateReserved,omitempty"`
+	DateUpdated       string `json:"dateUpdated,omitempty"`
+	State             string `json:"state"`
+}
+
+// CVE5Containers holds the container of the CNA the record was published by and the ones of ADPs
+// (authorized data publishers) which enriched it, e.g. CISA-ADP.
+type CVE5Containers struct {
+	ADP []*CVE5Container `json:"adp,omitempty"`
+	CNA *CVE5Container   `json:"cna"`
+}
+
+// CVE5Container is the information a CNA or an ADP provides about the vulnerability.
+type CVE5Container struct {
+	Affected         []*CVE5Affected       `json:"affected,omitempty"`
+	Descriptions     []*CVE5LangString     `json:"descriptions,omitempty"`
+	Metrics          []*CVE5Metric         `json:"metrics,omitempty"`
+	ProblemTypes     []*CVE5ProblemType    `json:"problemTypes,omitempty"`
+	ProviderMetadata *CVE5ProviderMetadata `json:"providerMetadata"`
+	References       []*CVE5Reference      `json:"references,omitempty"`
+	RejectedReasons  []*CVE5LangString     `json:"rejectedReasons,omitempty"`
+	Title            string                `json:"title,omitempty"`
+}
+
+// CVE5ProviderMetadata identifies the organization which provided a container.
+type CVE5ProviderMetadata struct {
+	DateUpdated string `json:"dateUpdated,omitempty"`
+	OrgID       string `json:"orgId"`
+	ShortName   string `json:"shortName,omitempty"`
+}
+
+// CVE5LangString is a language tagged string.
+type CVE5LangString struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+// CVE5Affected is a product affected by the vulnerability.
+type CVE5Affected struct {
+	CollectionURL string         `json:"collectionURL,omitempty"`
+	CPEs          []string       `json:"cpes,omitempty"`
+	DefaultStatus string         `json:"defaultStatus,omitempty"`
+	PackageName   string         `json:"packageName,omitempty"`
+	Platforms     []string       `json:"platforms,omitempty"`
+	Product       string         `json:"product,omitempty"`
+	Vendor        string         `json:"vendor,omitempty"`
+	Versions      []*CVE5Version `json:"versions,omitempty"`
+}
+
+// CVE5Version is a version or a range of versions of an affected product along with its status:
+// affected, unaffected or unknown.
+type CVE5Version struct {
+	LessThan        string `json:"lessThan,omitempty"`
+	LessThanOrEqual string `json:"lessThanOrEqual,omitempty"`
+	Status          string `json:"status"`
+	Version         string `json:"version"`
+	VersionType     string `json:"versionType,omitempty"`
+}
+
+// CVE5Metric is an impact assessment of the vulnerability, typically in a single CVSS version.
+type CVE5Metric struct {
+	CVSSV20 *CVSSV20     `json:"cvssV2_0,omitempty"`
+	CVSSV30 *CVSSV30     `json:"cvssV3_0,omitempty"`
+	CVSSV31 *CVSSV30     `json:"cvssV3_1,omitempty"`
+	CVSSV40 *CVE5CVSSV40 `json:"cvssV4_0,omitempty"`
+	Format  string       `json:"format,omitempty"`
+}
+
+// CVE5CVSSV40 is a CVSS v4.0 assessment.
+type CVE5CVSSV40 struct {
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity"`
+	VectorString string  `json:"vectorString"`
+	Version      string  `json:"version"`
+}
+
+// CVE5ProblemType is a problem type, typically a CWE, of the vulnerability.
+type CVE5ProblemType struct {
+	Descriptions []*CVE5ProblemTypeDescription `json:"descriptions"`
+}
+
+// CVE5ProblemTypeDescription describes a problem type.
+type CVE5ProblemTypeDescription struct {
+	CWEID       string `json:"cweId,omitempty"`
+	Description string `json:"description"`
+	Lang        string `json:"lang"`
+	Type        string `json:"type,omitempty"`
+}
+
+// CVE5Reference is a reference to information about the vulnerability.
+type CVE5Reference struct {
+	Name string   `json:"name,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	URL  string   `json:"url"`
+}
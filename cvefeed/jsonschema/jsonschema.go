@@ -29,6 +29,8 @@ type NVDCVEFeedJSON10DefCPEMatch struct {
 	CPEName               []*NVDCVEFeedJSON10DefCPEName `json:"cpe_name,omitempty"`
 	Cpe22Uri              string                        `json:"cpe22Uri,omitempty"`
 	Cpe23Uri              string                        `json:"cpe23Uri"`
+	FixedVersion          string                        `json:"fixedVersion,omitempty"`    // nvdtools extension, not part of 1.0 schema
+	MatchCriteriaID       string                        `json:"matchCriteriaId,omitempty"` // NVD 2.0 only, not part of 1.0 schema
 	VersionEndExcluding   string                        `json:"versionEndExcluding,omitempty"`
 	VersionEndIncluding   string                        `json:"versionEndIncluding,omitempty"`
 	VersionStartExcluding string                        `json:"versionStartExcluding,omitempty"`
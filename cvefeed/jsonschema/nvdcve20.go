@@ -0,0 +1,169 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+// The types below model NVD CVE API 2.0 responses and CPE match criteria API 2.0 responses:
+// https://csrc.nist.gov/schema/nvd/api/2.0/cve_api_json_2.0.schema
+// https://csrc.nist.gov/schema/nvd/api/2.0/cpematch_api_json_2.0.schema
+// Only the fields used by nvdtools are defined.
+
+// NVDCVE20LangString is a language tagged string.
+type NVDCVE20LangString struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+// NVDCVE20CPEMatch is a CPE match string or range.
+type NVDCVE20CPEMatch struct {
+	Criteria              string `json:"criteria"`
+	MatchCriteriaID       string `json:"matchCriteriaId"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	Vulnerable            bool   `json:"vulnerable"`
+}
+
+// NVDCVE20Node defines a node in an NVD applicability statement.
+type NVDCVE20Node struct {
+	CPEMatch []*NVDCVE20CPEMatch `json:"cpeMatch"`
+	Negate   bool                `json:"negate,omitempty"`
+	Operator string              `json:"operator"`
+}
+
+// NVDCVE20Config defines a single configuration of an NVD applicability statement.
+type NVDCVE20Config struct {
+	Negate   bool            `json:"negate,omitempty"`
+	Nodes    []*NVDCVE20Node `json:"nodes"`
+	Operator string          `json:"operator,omitempty"`
+}
+
+// NVDCVE20CVSSV2 is a CVSS v2.0 assessment.
+type NVDCVE20CVSSV2 struct {
+	AcInsufInfo             bool     `json:"acInsufInfo,omitempty"`
+	BaseSeverity            string   `json:"baseSeverity,omitempty"`
+	CVSSData                *CVSSV20 `json:"cvssData"`
+	ExploitabilityScore     float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore             float64  `json:"impactScore,omitempty"`
+	ObtainAllPrivilege      bool     `json:"obtainAllPrivilege,omitempty"`
+	ObtainOtherPrivilege    bool     `json:"obtainOtherPrivilege,omitempty"`
+	ObtainUserPrivilege     bool     `json:"obtainUserPrivilege,omitempty"`
+	Source                  string   `json:"source"`
+	Type                    string   `json:"type"`
+	UserInteractionRequired bool     `json:"userInteractionRequired,omitempty"`
+}
+
+// NVDCVE20CVSSV3 is a CVSS v3.x assessment.
+type NVDCVE20CVSSV3 struct {
+	CVSSData            *CVSSV30 `json:"cvssData"`
+	ExploitabilityScore float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore         float64  `json:"impactScore,omitempty"`
+	Source              string   `json:"source"`
+	Type                string   `json:"type"`
+}
+
+// NVDCVE20Metrics holds all CVSS assessments of a vulnerability.
+type NVDCVE20Metrics struct {
+	CVSSMetricV2  []*NVDCVE20CVSSV2 `json:"cvssMetricV2,omitempty"`
+	CVSSMetricV30 []*NVDCVE20CVSSV3 `json:"cvssMetricV30,omitempty"`
+	CVSSMetricV31 []*NVDCVE20CVSSV3 `json:"cvssMetricV31,omitempty"`
+}
+
+// NVDCVE20Weakness is a weakness (CWE) assigned to a vulnerability by a source.
+type NVDCVE20Weakness struct {
+	Description []*NVDCVE20LangString `json:"description"`
+	Source      string                `json:"source"`
+	Type        string                `json:"type"`
+}
+
+// NVDCVE20Reference is a reference to an external resource.
+type NVDCVE20Reference struct {
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	URL    string   `json:"url"`
+}
+
+// NVDCVE20VendorComment is a statement of a vendor on a vulnerability.
+type NVDCVE20VendorComment struct {
+	Comment      string `json:"comment"`
+	LastModified string `json:"lastModified,omitempty"`
+	Organization string `json:"organization"`
+}
+
+// NVDCVE20CVE defines a vulnerability in NVD CVE API 2.0 response.
+type NVDCVE20CVE struct {
+	Configurations   []*NVDCVE20Config        `json:"configurations,omitempty"`
+	Descriptions     []*NVDCVE20LangString    `json:"descriptions"`
+	ID               string                   `json:"id"`
+	LastModified     string                   `json:"lastModified"`
+	Metrics          *NVDCVE20Metrics         `json:"metrics,omitempty"`
+	Published        string                   `json:"published"`
+	References       []*NVDCVE20Reference     `json:"references"`
+	SourceIdentifier string                   `json:"sourceIdentifier,omitempty"`
+	VendorComments   []*NVDCVE20VendorComment `json:"vendorComments,omitempty"`
+	VulnStatus       string                   `json:"vulnStatus,omitempty"`
+	Weaknesses       []*NVDCVE20Weakness      `json:"weaknesses,omitempty"`
+}
+
+// NVDCVE20Vulnerability wraps a single vulnerability of NVD CVE API 2.0 response.
+type NVDCVE20Vulnerability struct {
+	CVE *NVDCVE20CVE `json:"cve"`
+}
+
+// NVDCVE20 is the root of NVD CVE API 2.0 response.
+type NVDCVE20 struct {
+	Format          string                   `json:"format"`
+	ResultsPerPage  int                      `json:"resultsPerPage"`
+	StartIndex      int                      `json:"startIndex"`
+	Timestamp       string                   `json:"timestamp"`
+	TotalResults    int                      `json:"totalResults"`
+	Version         string                   `json:"version"`
+	Vulnerabilities []*NVDCVE20Vulnerability `json:"vulnerabilities"`
+}
+
+// NVDCPEMatch20CPEName is a CPE name matched by a match criteria.
+type NVDCPEMatch20CPEName struct {
+	CPEName   string `json:"cpeName"`
+	CPENameID string `json:"cpeNameId"`
+}
+
+// NVDCPEMatch20MatchString defines a match criteria, identified by MatchCriteriaID.
+type NVDCPEMatch20MatchString struct {
+	Criteria              string                  `json:"criteria"`
+	LastModified          string                  `json:"lastModified,omitempty"`
+	MatchCriteriaID       string                  `json:"matchCriteriaId"`
+	Matches               []*NVDCPEMatch20CPEName `json:"matches,omitempty"`
+	Status                string                  `json:"status,omitempty"`
+	VersionEndExcluding   string                  `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string                  `json:"versionEndIncluding,omitempty"`
+	VersionStartExcluding string                  `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string                  `json:"versionStartIncluding,omitempty"`
+}
+
+// NVDCPEMatch20Item wraps a single match criteria of CPE match criteria API 2.0 response.
+type NVDCPEMatch20Item struct {
+	MatchString *NVDCPEMatch20MatchString `json:"matchString"`
+}
+
+// NVDCPEMatch20 is the root of CPE match criteria API 2.0 response.
+type NVDCPEMatch20 struct {
+	Format         string               `json:"format"`
+	MatchStrings   []*NVDCPEMatch20Item `json:"matchStrings"`
+	ResultsPerPage int                  `json:"resultsPerPage"`
+	StartIndex     int                  `json:"startIndex"`
+	Timestamp      string               `json:"timestamp"`
+	TotalResults   int                  `json:"totalResults"`
+	Version        string               `json:"version"`
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// KEVURL is where CISA publishes the KEV catalog
+const KEVURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// KEVEntry is a vulnerability listed in CISA Known Exploited Vulnerabilities (KEV) catalog
+type KEVEntry struct {
+	CVEID            string   `json:"cveID"`
+	VendorProject    string   `json:"vendorProject"`
+	Product          string   `json:"product"`
+	Name             string   `json:"vulnerabilityName"`
+	DateAdded        string   `json:"dateAdded"`
+	RequiredAction   string   `json:"requiredAction"`
+	DueDate          string   `json:"dueDate"`
+	RansomwareUse    string   `json:"knownRansomwareCampaignUse"`
+	ShortDescription string   `json:"shortDescription"`
+	Notes            string   `json:"notes"`
+	CWEs             []string `json:"cwes"`
+}
+
+// kevDateLayout is the layout of dates in KEV catalog
+const kevDateLayout = "2006-01-02"
+
+// Due returns the date federal agencies are required to remediate the vulnerability by
+func (e *KEVEntry) Due() (time.Time, error) {
+	return time.Parse(kevDateLayout, e.DueDate)
+}
+
+// KEV is the catalog of known exploited vulnerabilities keyed by CVE ID
+type KEV map[string]*KEVEntry
+
+// ParseKEV parses KEV catalog in the JSON format CISA publishes it in
+func ParseKEV(in io.Reader) (KEV, error) {
+	var catalog struct {
+		Vulnerabilities []*KEVEntry `json:"vulnerabilities"`
+	}
+	if err := json.NewDecoder(in).Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("kev: %v", err)
+	}
+	if catalog.Vulnerabilities == nil {
+		return nil, fmt.Errorf("kev: no vulnerabilities list, not a KEV catalog")
+	}
+	kev := make(KEV, len(catalog.Vulnerabilities))
+	for i, e := range catalog.Vulnerabilities {
+		if e == nil || strings.TrimSpace(e.CVEID) == "" {
+			return nil, fmt.Errorf("kev: vulnerability %d: CVE ID is empty", i)
+		}
+		e.CVEID = strings.ToUpper(strings.TrimSpace(e.CVEID))
+		kev[e.CVEID] = e
+	}
+	return kev, nil
+}
+
+// LoadKEV parses KEV catalog from JSON file, see ParseKEV
+func LoadKEV(path string) (KEV, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("kev: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseKEV(f)
+}
+
+// FetchKEV downloads KEV catalog from url, KEVURL if empty, and parses it, see ParseKEV
+func FetchKEV(ctx context.Context, url string) (KEV, error) {
+	if url == "" {
+		url = KEVURL
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kev: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("kev: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("kev: %q responded %q: %q", url, resp.Status, body)
+	}
+	return ParseKEV(resp.Body)
+}
+
+// Filter returns match results of the CVEs listed in the catalog, flagged as known exploited (see Apply);
+// unless dueBefore is zero, only the ones required to be remediated before that date are returned.
+// Entries with malformed due date are considered overdue. The input results are not modified.
+func (kev KEV) Filter(results []MatchResult, dueBefore time.Time) []MatchResult {
+	out := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		e, ok := kev[r.CVE.CVEID()]
+		if !ok {
+			continue
+		}
+		if !dueBefore.IsZero() {
+			if due, err := e.Due(); err == nil && !due.Before(dueBefore) {
+				continue
+			}
+		}
+		r.KnownExploited = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// Apply returns match results with KnownExploited set for the CVEs listed in the catalog.
+// The input results are not modified.
+func (kev KEV) Apply(results []MatchResult) []MatchResult {
+	out := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		if _, ok := kev[r.CVE.CVEID()]; ok {
+			r.KnownExploited = true
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// kevBoost is added to priority of known exploited CVEs, it's enough to rank them above any CVSS score
+const kevBoost = 10
+
+// Priority returns the remediation priority of the result: the representative CVSS score of its CVE (the lowest
+// score of the band for severity-only assessments, see Score.MinScore), boosted for known exploited CVEs so that
+// they rank above all the others, see KEV.Apply. Rescored results are prioritized by their new severity.
+func Priority(r MatchResult) float64 {
+	if r.CVE == nil {
+		return 0
+	}
+	p := resultScore(r).MinScore()
+	if r.KnownExploited {
+		p += kevBoost
+	}
+	return p
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKEV = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "catalogVersion": "2023.01.01",
+  "count": 1,
+  "vulnerabilities": [
+    {
+      "cveID": "CVE-2020-0002",
+      "vendorProject": "Example",
+      "product": "Widget",
+      "vulnerabilityName": "Example Widget Remote Code Execution",
+      "dateAdded": "2022-01-10",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2022-07-10",
+      "knownRansomwareCampaignUse": "Unknown"
+    }
+  ]
+}`
+
+type kevCVE struct {
+	CVEItem
+	id     string
+	cvss30 float64
+}
+
+func (c kevCVE) CVEID() string       { return c.id }
+func (c kevCVE) CVSS20base() float64 { return 0 }
+func (c kevCVE) CVSS30base() float64 { return c.cvss30 }
+
+func TestKEV(t *testing.T) {
+	kev, err := ParseKEV(strings.NewReader(testKEV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := kev["CVE-2020-0002"]; e == nil || e.DueDate != "2022-07-10" {
+		t.Fatalf("expected CVE-2020-0002 in the catalog, got %+v", kev)
+	}
+	results := []MatchResult{
+		{CVE: kevCVE{id: "CVE-2020-0001", cvss30: 9.8}},
+		{CVE: kevCVE{id: "CVE-2020-0002", cvss30: 5.3}},
+		{CVE: kevCVE{id: "CVE-2020-0003", cvss30: 7.5}},
+	}
+	flagged := kev.Apply(results)
+	if results[1].KnownExploited {
+		t.Error("input results were modified")
+	}
+	for i, r := range flagged {
+		if r.KnownExploited != (i == 1) {
+			t.Errorf("%s: unexpected KnownExploited %v", r.CVE.CVEID(), r.KnownExploited)
+		}
+	}
+	if p := Priority(flagged[1]); p <= Priority(flagged[0]) {
+		t.Errorf("known exploited CVE expected to take priority over higher CVSS score, got %.1f", p)
+	}
+	SortResults(flagged, ByPriority)
+	for i, id := range []string{"CVE-2020-0002", "CVE-2020-0001", "CVE-2020-0003"} {
+		if got := flagged[i].CVE.CVEID(); got != id {
+			t.Errorf("position %d: expected %s, got %s", i, id, got)
+		}
+	}
+
+	for _, in := range []string{`{}`, `{"vulnerabilities":[{"cveID":""}]}`, `[`} {
+		if _, err := ParseKEV(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestKEVFilter(t *testing.T) {
+	kev, err := ParseKEV(strings.NewReader(testKEV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kev["CVE-2020-0003"] = &KEVEntry{CVEID: "CVE-2020-0003", DueDate: "2023-01-31"}
+	results := []MatchResult{
+		{CVE: kevCVE{id: "CVE-2020-0001", cvss30: 9.8}},
+		{CVE: kevCVE{id: "CVE-2020-0002", cvss30: 5.3}},
+		{CVE: kevCVE{id: "CVE-2020-0003", cvss30: 7.5}},
+	}
+	tests := []struct {
+		dueBefore time.Time
+		ids       []string
+	}{
+		{time.Time{}, []string{"CVE-2020-0002", "CVE-2020-0003"}},
+		{time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), []string{"CVE-2020-0002"}},
+		{time.Date(2022, 7, 10, 0, 0, 0, 0, time.UTC), nil},
+	}
+	for _, test := range tests {
+		filtered := kev.Filter(results, test.dueBefore)
+		if len(filtered) != len(test.ids) {
+			t.Errorf("due before %s: expected %v, got %d results", test.dueBefore, test.ids, len(filtered))
+			continue
+		}
+		for i, r := range filtered {
+			if r.CVE.CVEID() != test.ids[i] || !r.KnownExploited {
+				t.Errorf("due before %s: unexpected result %s (known exploited %v)", test.dueBefore, r.CVE.CVEID(), r.KnownExploited)
+			}
+		}
+	}
+	if results[1].KnownExploited {
+		t.Error("input results were modified")
+	}
+}
@@ -0,0 +1,538 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdjson"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// LoadOptions configures loading of feeds, see LoadFeedWithOptions and LoadSource
+type LoadOptions struct {
+	// Workers is the number of feeds parsed at once, 0 -- GOMAXPROCS
+	Workers int
+	// Parse parses the feed file, nil -- NVD JSON feed (plain or gzip'ed) streamed one CVE at a time as per
+	// ParseOptions, see ParseJSONStream; must be safe for concurrent use. Unless nil, whole feeds are parsed
+	// at once, so up to Workers parsed feeds are held in memory at a time.
+	Parse func(path string) ([]CVEItem, error)
+	// ParseOptions are the options of parsing NVD JSON feeds unless Parse is set; feeds are parsed concurrently,
+	// so ParseOptions.Transform must be safe for concurrent use
+	ParseOptions nvdjson.ParseOptions
+	// Progress, unless nil, is called every ProgressInterval CVEs loaded from a feed and once the feed is loaded,
+	// with the number of CVEs loaded from the feed so far; it isn't called concurrently
+	Progress func(path string, loaded int)
+	// ProgressInterval is the number of CVEs between the calls of Progress, 0 -- 1000
+	ProgressInterval int
+	// IndexDir, if set, makes LoadSource index CVEs on disk in the directory rather than keep them in memory,
+	// see DiskIndex; the directory is created if it doesn't exist
+	IndexDir string
+}
+
+func (opts LoadOptions) workers() int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func (opts LoadOptions) progressInterval() int {
+	if opts.ProgressInterval > 0 {
+		return opts.ProgressInterval
+	}
+	return 1000
+}
+
+// parse parses the feed and passes its CVEs to emit, one at a time
+func (opts LoadOptions) parse(path string, emit func(CVEItem) error) error {
+	if opts.Parse != nil {
+		items, err := opts.Parse(path)
+		if err != nil {
+			return err
+		}
+		for _, cve := range items {
+			if err := emit(cve); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ParseJSONStream(f, opts.ParseOptions, emit)
+}
+
+// load parses the feeds with a pool of workers and calls add for every CVE of the feeds, one CVE at a time
+// (add isn't called concurrently), as they're parsed; errors of the feeds are combined, the CVEs of the feeds
+// which fail to parse might be added partially. Parsing stops once ctx is done, returning ctx.Err().
+// The feeds are parsed in no particular order, but add is only called with the CVE of the ID added before if
+// it comes from the same or a later path, so the CVE added last is the one of the last path, as if the feeds
+// were loaded one by one.
+func (opts LoadOptions) load(ctx context.Context, paths []string, add func(path string, cve CVEItem) error) error {
+	var mu sync.Mutex             // serializes add and Progress, guards added
+	added := make(map[string]int) // the index of the path the CVE of the ID was added from
+	interval := opts.progressInterval()
+	todo := make(chan int)
+	var errs []string
+	var wg sync.WaitGroup
+	for i := 0; i < opts.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range todo {
+				if ctx.Err() != nil {
+					continue
+				}
+				path, loaded := paths[i], 0
+				err := opts.parse(path, func(cve CVEItem) error {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					if cveid := cve.CVEID(); cveid != "" {
+						if j, ok := added[cveid]; !ok || j <= i {
+							if err := add(path, cve); err != nil {
+								return err
+							}
+							added[cveid] = i
+						}
+					} else if err := add(path, cve); err != nil {
+						return err
+					}
+					if loaded++; opts.Progress != nil && loaded%interval == 0 {
+						opts.Progress(path, loaded)
+					}
+					return nil
+				})
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("dictionary: failed to load feed %q: %v", path, err))
+				} else if opts.Progress != nil {
+					opts.Progress(path, loaded)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range paths {
+		todo <- i
+	}
+	close(todo)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// LoadFeedWithOptions is like LoadFeed, but the feeds are parsed by a bounded pool of workers, by default one CVE
+// at a time, see LoadOptions; IndexDir is disregarded, the dictionary is always in memory. Regardless of Workers,
+// the CVE of the later path overrides the one of the same ID of the earlier path, as with LoadFeed.
+func LoadFeedWithOptions(opts LoadOptions, paths ...string) (Dictionary, error) {
+	return LoadFeedContext(context.Background(), opts, paths...)
+}
+
+// LoadFeedContext is like LoadFeedWithOptions, but loading stops once ctx is done, returning ctx.Err()
+// and the CVEs loaded so far
+func LoadFeedContext(ctx context.Context, opts LoadOptions, paths ...string) (Dictionary, error) {
+	dict := make(Dictionary)
+	err := opts.load(ctx, paths, func(path string, cve CVEItem) error {
+		if cveid := cve.CVEID(); cveid != "" {
+			dict[cveid] = cve
+		} else {
+			getLogger().Warnf("dictionary: skipping a record without CVE ID in %q", path)
+		}
+		return nil
+	})
+	return dict, err
+}
+
+// LoadSource loads the feeds as per opts and returns the source of CVEs to match against, see Cache.SetSource:
+// the Index of the dictionary of the feeds or, if opts.IndexDir is set, the DiskIndex there, which the caller
+// has to close; the index built there before is reused unless the feeds changed, see OpenOrBuildDiskIndex.
+// As with LoadFeed, the CVE of the later path overrides the one of the same ID of the earlier path.
+func LoadSource(opts LoadOptions, paths ...string) (CVESource, error) {
+	if opts.IndexDir == "" {
+		dict, err := LoadFeedWithOptions(opts, paths...)
+		return NewIndex(dict), err
+	}
+	idx, _, err := OpenOrBuildDiskIndex(opts, paths...)
+	if idx == nil {
+		return nil, err // returning nil *DiskIndex would make non-nil CVESource
+	}
+	return idx, err
+}
+
+const (
+	diskIndexRecords = "records.json"
+	diskIndexIndex   = "index.bin"
+	// diskIndexMagic starts the index file, followed by the version of the format
+	diskIndexMagic = "NVDTOOLS-IDX"
+	// diskIndexVersion is the version of the format, bumped on every incompatible change of the index or
+	// the records; indexes of other versions are rebuilt by OpenOrBuildDiskIndex
+	diskIndexVersion = 3
+)
+
+// ErrIndexVersion is the error of opening the index of a format other than the current one (see OpenDiskIndex),
+// the errors wrap it, see errors.Is
+var ErrIndexVersion = errors.New("dictionary: index format version mismatch")
+
+// ErrIndexClosed is the error of reading CVEs of the index closed, see DiskIndex.Close
+var ErrIndexClosed = errors.New("dictionary: index is closed")
+
+// DiskIndex is CVESource keeping CVEs on disk in a directory: the records of CVEs are stored in a file
+// and only their positions are kept in memory, indexed by the products of their configurations like Index;
+// the records of candidates are read and parsed on every lookup. The records file is mapped into memory where
+// the platform supports it, so the index opens instantly regardless of its size. It's safe for concurrent use,
+// Close waits for the reads in progress. Records that fail to read or parse are logged and skipped.
+type DiskIndex struct {
+	f        *os.File
+	mu       sync.RWMutex // guards data and closed: reads hold it shared, Close exclusively
+	closed   bool
+	data     []byte                // the records file mapped into memory, nil if it's read with ReadAt
+	records  map[string]diskRecord // by CVE ID
+	products map[string][]string   // CVE IDs by product, wfn.Any is the key of CVEs matching any product
+	feeds    []indexedFeed
+}
+
+// diskRecord is the position of the record of a CVE in the records file
+type diskRecord struct {
+	Offset int64 `json:"o"`
+	Length int   `json:"l"`
+}
+
+// diskRecordCVE is the record of a CVE in the records file: the item in the shape of NVD JSON 1.1 feeds,
+// along with the status and the vendor comments, which those don't carry
+type diskRecordCVE struct {
+	Feed           json.RawMessage           `json:"feed"` // NVD JSON 1.1 feed of the item alone
+	Status         string                    `json:"status,omitempty"`
+	VendorComments []nvdcommon.VendorComment `json:"vendor_comments,omitempty"`
+}
+
+// diskIndexFile is the index persisted along with the records, so the index can be reopened without the feeds
+type diskIndexFile struct {
+	Records     map[string]diskRecord
+	Products    map[string][]string
+	Feeds       []indexedFeed
+	RecordsSize int64 // the size of the records file, to tell the records of another build
+}
+
+// indexedFeed is the feed file the index was built of, as it was at the time
+type indexedFeed struct {
+	Path    string // absolute
+	ModTime time.Time
+	Size    int64
+}
+
+// statFeeds returns the feed files as they're now
+func statFeeds(paths []string) ([]indexedFeed, error) {
+	feeds := make([]indexedFeed, len(paths))
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		feeds[i] = indexedFeed{Path: abs, ModTime: fi.ModTime(), Size: fi.Size()}
+	}
+	return feeds, nil
+}
+
+// BuildDiskIndex loads the feeds as per opts and indexes them on disk in opts.IndexDir, replacing an index
+// built there before; see DiskIndex. Feeds which fail to load are reported, but the others are still indexed.
+// The files of the index are replaced once they're written, so processes which opened the former index
+// keep reading it intact.
+func BuildDiskIndex(opts LoadOptions, paths ...string) (*DiskIndex, error) {
+	if opts.IndexDir == "" {
+		return nil, errors.New("dictionary: no directory to index in")
+	}
+	if err := os.MkdirAll(opts.IndexDir, 0755); err != nil {
+		return nil, fmt.Errorf("dictionary: failed to create index: %v", err)
+	}
+	feeds, err := statFeeds(paths) // before loading, so the feeds changed while loading are newer than the index
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to index feeds: %v", err)
+	}
+	f, err := ioutil.TempFile(opts.IndexDir, diskIndexRecords+".*")
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to create index: %v", err)
+	}
+	idx := &DiskIndex{f: f, records: make(map[string]diskRecord), feeds: feeds}
+	products := make(map[string][]string) // products of every CVE, to index the last record of the CVE only
+	fail := func(err error) (*DiskIndex, error) {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("dictionary: failed to write index: %v", err)
+	}
+
+	w := bufio.NewWriter(f)
+	var offset int64
+	loadErr := opts.load(context.Background(), paths, func(path string, cve CVEItem) error {
+		cveid := cve.CVEID()
+		if cveid == "" {
+			getLogger().Warnf("dictionary: skipping a record without CVE ID in %q", path)
+			return nil
+		}
+		feed, err := json.Marshal(jsonschema.NVDCVEFeedJSON10{CVEItems: []*jsonschema.NVDCVEFeedJSON10DefCVEItem{nvdjson.FeedItem(cve)}})
+		if err != nil {
+			return fmt.Errorf("%s: %v", cveid, err)
+		}
+		data, err := json.Marshal(diskRecordCVE{Feed: feed, Status: CVEStatus(cve), VendorComments: VendorComments(cve)})
+		if err != nil {
+			return fmt.Errorf("%s: %v", cveid, err)
+		}
+		data = append(data, '\n')
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		idx.records[cveid] = diskRecord{Offset: offset, Length: len(data)}
+		products[cveid] = indexedProducts(cve)
+		offset += int64(len(data))
+		return nil
+	})
+	if err := w.Flush(); err != nil {
+		return fail(err)
+	}
+
+	idx.products = make(map[string][]string)
+	for cveid, prods := range products {
+		for _, product := range prods {
+			idx.products[product] = append(idx.products[product], cveid)
+		}
+	}
+	for _, ids := range idx.products {
+		sort.Strings(ids)
+	}
+	file := diskIndexFile{Records: idx.records, Products: idx.products, Feeds: feeds, RecordsSize: offset}
+	if err := writeDiskIndexFile(filepath.Join(opts.IndexDir, diskIndexIndex), &file); err != nil {
+		return fail(err)
+	}
+	// the records are replaced before the index, so the index is never older than the records: the index
+	// of a build interrupted in between doesn't match the size of the records and is rebuilt, see OpenDiskIndex
+	if err := os.Rename(f.Name(), filepath.Join(opts.IndexDir, diskIndexRecords)); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(filepath.Join(opts.IndexDir, diskIndexIndex+".new"), filepath.Join(opts.IndexDir, diskIndexIndex)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("dictionary: failed to write index: %v", err)
+	}
+	if idx.data, err = mmapFile(f, offset); err != nil {
+		getLogger().Debugf("dictionary: reading the records of the index rather than mapping them: %v", err)
+	}
+	return idx, loadErr
+}
+
+// writeDiskIndexFile writes the index next to path, to be renamed to path once the records are in place
+func writeDiskIndexFile(path string, file *diskIndexFile) error {
+	f, err := os.Create(path + ".new")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	w.WriteString(diskIndexMagic)
+	binary.Write(w, binary.BigEndian, uint32(diskIndexVersion))
+	if err = gob.NewEncoder(w).Encode(file); err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// OpenDiskIndex opens the index built in the directory before, see BuildDiskIndex; indexes of formats
+// other than the current one are ErrIndexVersion, they have to be rebuilt
+func OpenDiskIndex(dir string) (*DiskIndex, error) {
+	in, err := os.Open(filepath.Join(dir, diskIndexIndex))
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to open index: %v", err)
+	}
+	defer in.Close()
+	r := bufio.NewReader(in)
+	header := make([]byte, len(diskIndexMagic)+4)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:len(diskIndexMagic)]) != diskIndexMagic {
+		return nil, fmt.Errorf("dictionary: failed to open index: not an index file")
+	}
+	if v := binary.BigEndian.Uint32(header[len(diskIndexMagic):]); v != diskIndexVersion {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrIndexVersion, v, diskIndexVersion)
+	}
+	var file diskIndexFile
+	if err := gob.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("dictionary: failed to open index: %v", err)
+	}
+	f, err := os.Open(filepath.Join(dir, diskIndexRecords))
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to open index: %v", err)
+	}
+	if fi, err := f.Stat(); err != nil || fi.Size() != file.RecordsSize {
+		f.Close()
+		return nil, fmt.Errorf("dictionary: failed to open index: records don't match the index")
+	}
+	idx := &DiskIndex{f: f, records: file.Records, products: file.Products, feeds: file.Feeds}
+	if idx.data, err = mmapFile(f, file.RecordsSize); err != nil {
+		getLogger().Debugf("dictionary: reading the records of the index rather than mapping them: %v", err)
+	}
+	return idx, nil
+}
+
+// OpenOrBuildDiskIndex opens the index in opts.IndexDir if it's up to date, built of the same feeds none of which
+// changed since, and builds it otherwise, see BuildDiskIndex; it tells whether the index was built.
+// Indexes which fail to open (e.g. of an outdated format or missing) are rebuilt.
+func OpenOrBuildDiskIndex(opts LoadOptions, paths ...string) (*DiskIndex, bool, error) {
+	if opts.IndexDir == "" {
+		return nil, false, errors.New("dictionary: no directory to index in")
+	}
+	idx, err := OpenDiskIndex(opts.IndexDir)
+	if err == nil {
+		if idx.upToDate(paths) {
+			return idx, false, nil
+		}
+		idx.Close()
+	} else {
+		getLogger().Debugf("%v, rebuilding", err)
+	}
+	idx, err = BuildDiskIndex(opts, paths...)
+	return idx, true, err
+}
+
+// upToDate tells if the index was built of the feeds and none of them changed since
+func (idx *DiskIndex) upToDate(paths []string) bool {
+	feeds, err := statFeeds(paths)
+	if err != nil || len(feeds) != len(idx.feeds) {
+		return false
+	}
+	for i, feed := range feeds {
+		indexed := idx.feeds[i]
+		if feed.Path != indexed.Path || feed.Size != indexed.Size || !feed.ModTime.Equal(indexed.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Close closes the records file of the index, once the reads in progress are done; CVEs read after are
+// ErrIndexClosed. Closing the index again does nothing.
+func (idx *DiskIndex) Close() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.closed {
+		return nil
+	}
+	idx.closed = true
+	if idx.data != nil {
+		munmap(idx.data)
+		idx.data = nil
+	}
+	return idx.f.Close()
+}
+
+// Len returns the number of CVEs in the index
+func (idx *DiskIndex) Len() int {
+	return len(idx.records)
+}
+
+// Item reads the CVE of the ID from disk, nil if the index doesn't have it, see ErrIndexClosed
+func (idx *DiskIndex) Item(cveid string) (CVEItem, error) {
+	rec, ok := idx.records[cveid]
+	if !ok {
+		return nil, nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock() // the record is parsed before it's unmapped
+	if idx.closed {
+		return nil, ErrIndexClosed
+	}
+	var data []byte
+	if idx.data != nil {
+		if rec.Offset < 0 || rec.Offset+int64(rec.Length) > int64(len(idx.data)) {
+			return nil, fmt.Errorf("dictionary: failed to read %s: record out of the records file", cveid)
+		}
+		data = idx.data[rec.Offset : rec.Offset+int64(rec.Length)]
+	} else {
+		data = make([]byte, rec.Length)
+		if _, err := idx.f.ReadAt(data, rec.Offset); err != nil {
+			return nil, fmt.Errorf("dictionary: failed to read %s: %v", cveid, err)
+		}
+	}
+	var record diskRecordCVE
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("dictionary: failed to parse %s: %v", cveid, err)
+	}
+	items, err := nvdjson.Parse(bytes.NewReader(record.Feed))
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to parse %s: %v", cveid, err)
+	}
+	if len(items) != 1 {
+		return nil, fmt.Errorf("dictionary: failed to parse %s: no CVE in the record", cveid)
+	}
+	return nvdjson.WithStatus(items[0], record.Status, record.VendorComments), nil
+}
+
+// CPECandidates implements CVESource: the CVEs indexed by the product of cpe and the ones which match any product,
+// read from disk
+func (idx *DiskIndex) CPECandidates(cpe *wfn.Attributes) []CVEItem {
+	var ids []string
+	if cpe != nil && cpe.Product != wfn.Any {
+		ids = append(ids, idx.products[cpe.Product]...)
+	}
+	ids = append(ids, idx.products[wfn.Any]...)
+	items := make([]CVEItem, 0, len(ids))
+	for _, cveid := range ids {
+		cve, err := idx.Item(cveid)
+		if err != nil {
+			getLogger().Warnf("%v", err)
+			continue
+		}
+		items = append(items, cve)
+	}
+	return items
+}
@@ -0,0 +1,289 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func matchedIDs(results []MatchResult) []string {
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.CVE.CVEID())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestLoadOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testJSONdict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+
+	want, err := LoadJSONDictionary(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadFeedWithOptions(LoadOptions{Workers: 1}, feed, missing)
+	if err == nil {
+		t.Error("expected an error loading missing feed")
+	}
+	if len(dict) != len(want) {
+		t.Fatalf("expected %d CVEs, got %d", len(want), len(dict))
+	}
+
+	idx, err := BuildDiskIndex(LoadOptions{Workers: 2, IndexDir: filepath.Join(dir, "index")}, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if idx.Len() != len(want) {
+		t.Fatalf("expected %d CVEs in the index, got %d", len(want), idx.Len())
+	}
+	reopened, err := OpenDiskIndex(filepath.Join(dir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	// the matches of the CPE names of every CVE are the same whether CVEs are on disk or in memory
+	memory := NewCache(want).SetMaxSize(-1)
+	for _, src := range []*DiskIndex{idx, reopened} {
+		disk := NewCache(nil).SetSource(src).SetMaxSize(-1)
+		for id, cve := range want {
+			var cpes []*wfn.Attributes
+			for _, cpe := range collectCPEs(cve.Config()) {
+				if cpe != nil {
+					cpes = append(cpes, cpe)
+				}
+			}
+			if w, got := matchedIDs(memory.Get(cpes)), matchedIDs(disk.Get(cpes)); !reflect.DeepEqual(w, got) {
+				t.Errorf("%s: want matches %v, got %v", id, w, got)
+			}
+			item, err := src.Item(id)
+			if err != nil || item == nil || item.CVEID() != id {
+				t.Errorf("%s: failed to read from disk: %v, %v", id, item, err)
+			}
+		}
+	}
+	if item, err := idx.Item("CVE-0000-0000"); item != nil || err != nil {
+		t.Errorf("expected no CVE, got %v, %v", item, err)
+	}
+
+	// reads racing Close either succeed or fail with ErrIndexClosed, and never read unmapped records
+	closing, err := OpenDiskIndex(filepath.Join(dir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				for id := range want {
+					if _, err := closing.Item(id); err != nil && err != ErrIndexClosed {
+						t.Errorf("%s: unexpected error reading while closing: %v", id, err)
+					}
+				}
+			}
+		}()
+	}
+	if err := closing.Close(); err != nil {
+		t.Error(err)
+	}
+	wg.Wait()
+	for id := range want {
+		if _, err := closing.Item(id); err != ErrIndexClosed {
+			t.Errorf("%s: expected %v reading closed index, got %v", id, ErrIndexClosed, err)
+		}
+		break
+	}
+	if err := closing.Close(); err != nil {
+		t.Errorf("closing again: unexpected error %v", err)
+	}
+
+	progress := map[string]int{}
+	calls := 0
+	opts := LoadOptions{
+		Progress:         func(path string, loaded int) { progress[path] = loaded; calls++ },
+		ProgressInterval: 1,
+	}
+	if _, err := LoadFeedWithOptions(opts, feed); err != nil {
+		t.Fatal(err)
+	}
+	if progress[feed] != len(want) || calls != len(want)+1 {
+		t.Errorf("expected progress of %d CVEs in %d calls, got %v in %d calls", len(want), len(want)+1, progress, calls)
+	}
+
+	// the CVEs of the later path win, even if the earlier path is parsed last
+	parsed := map[string][]CVEItem{}
+	for _, path := range []string{"early", "late"} {
+		if parsed[path], err = ParseJSON(bytes.NewBufferString(testJSONdict)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dict, err = LoadFeedWithOptions(LoadOptions{Workers: 2, Parse: func(path string) ([]CVEItem, error) {
+		if path == "early" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return parsed[path], nil
+	}}, "early", "late")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cve := range parsed["late"] {
+		if dict[cve.CVEID()] != cve {
+			t.Errorf("%s: expected the CVE of the later feed", cve.CVEID())
+		}
+	}
+
+	src, err := LoadSource(LoadOptions{}, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.(Index); !ok {
+		t.Errorf("expected in-memory index, got %T", src)
+	}
+}
+
+func TestOpenOrBuildDiskIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testJSONdict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := LoadOptions{IndexDir: filepath.Join(dir, "index")}
+	open := func(wantBuilt bool) {
+		t.Helper()
+		idx, built, err := OpenOrBuildDiskIndex(opts, feed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer idx.Close()
+		if built != wantBuilt {
+			t.Errorf("expected built %v, got %v", wantBuilt, built)
+		}
+		if idx.Len() == 0 {
+			t.Fatal("expected CVEs in the index")
+		}
+		for cveid := range idx.records {
+			if item, err := idx.Item(cveid); err != nil || item == nil || item.CVEID() != cveid {
+				t.Errorf("%s: failed to read from disk: %v, %v", cveid, item, err)
+			}
+		}
+	}
+
+	open(true)  // no index yet
+	open(false) // up to date
+
+	// feeds newer than the index
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(feed, later, later); err != nil {
+		t.Fatal(err)
+	}
+	open(true)
+	open(false)
+
+	// indexes of other feeds
+	if _, _, err := OpenOrBuildDiskIndex(opts, feed, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error indexing missing feed")
+	}
+
+	// indexes of other format versions
+	index := filepath.Join(opts.IndexDir, diskIndexIndex)
+	data, err := ioutil.ReadFile(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(diskIndexMagic)+3]++
+	if err := ioutil.WriteFile(index, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDiskIndex(opts.IndexDir); !errors.Is(err, ErrIndexVersion) {
+		t.Errorf("expected version mismatch, got %v", err)
+	}
+	open(true)
+
+	// records of another build
+	if err := ioutil.WriteFile(filepath.Join(opts.IndexDir, diskIndexRecords), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDiskIndex(opts.IndexDir); err == nil {
+		t.Error("expected records of another build to fail to open")
+	}
+	open(true)
+}
+
+func TestDiskIndexStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testJSONdictStatus), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want, err := LoadJSONDictionary(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := BuildDiskIndex(LoadOptions{IndexDir: filepath.Join(dir, "index")}, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built.Close()
+	// the status and the vendor comments of NVD CVE API 2.0 items aren't lost in the records of the index
+	idx, err := OpenDiskIndex(filepath.Join(dir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for id, cve := range want {
+		item, err := idx.Item(id)
+		if err != nil || item == nil {
+			t.Fatalf("%s: failed to read from disk: %v", id, err)
+		}
+		if w, got := CVEStatus(cve), CVEStatus(item); w == "" || w != got {
+			t.Errorf("%s: expected status %q, got %q", id, w, got)
+		}
+		if w, got := VendorComments(cve), VendorComments(item); !reflect.DeepEqual(w, got) {
+			t.Errorf("%s: expected vendor comments %+v, got %+v", id, w, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// Logger receives the diagnostics of loading dictionaries and matching, e.g. the skipped records;
+// it lets the library log to the logger of the application, see SetLogger and Cache.SetLogger
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// GlogLogger is the Logger writing to glog: debug messages are logged at verbosity level 2
+type GlogLogger struct{}
+
+// Debugf implements Logger
+func (GlogLogger) Debugf(format string, args ...interface{}) {
+	glog.V(2).Infof(format, args...)
+}
+
+// Warnf implements Logger
+func (GlogLogger) Warnf(format string, args ...interface{}) {
+	glog.Warningf(format, args...)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = nopLogger{}
+)
+
+// SetLogger sets the logger of the functions loading dictionaries (e.g. LoadJSONDictionary) and of the caches
+// which don't have their own, see Cache.SetLogger; the library doesn't log by default, nil restores the default
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+}
+
+// getLogger returns the logger set by SetLogger
+func getLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
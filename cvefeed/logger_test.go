@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// recordingLogger records the warnings
+type recordingLogger struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLogger) Debugf(string, ...interface{}) {}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+func TestLogger(t *testing.T) {
+	pkg := &recordingLogger{}
+	SetLogger(pkg)
+	defer SetLogger(nil)
+
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(`{"CVE_Items":[{"cve":{"CVE_data_meta":{"ID":""}},"configurations":{"nodes":[]}}]}`))
+	}, "feed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkg.warnings) != 1 || !strings.Contains(pkg.warnings[0], "without CVE ID") {
+		t.Errorf("expected a warning about the record without ID, got %q", pkg.warnings)
+	}
+
+	dict["CVE-2020-9999"] = brokenCVE{}
+	cpe := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar"}
+	own := &recordingLogger{}
+	NewCache(dict).SetRecoverPanics(true).SetLogger(own).Get([]*wfn.Attributes{cpe})
+	if len(own.warnings) != 1 || !strings.Contains(own.warnings[0], "CVE-2020-9999") {
+		t.Errorf("expected a warning about the skipped CVE, got %q", own.warnings)
+	}
+	if len(pkg.warnings) != 1 {
+		t.Errorf("cache with own logger logged to the logger of the package: %q", pkg.warnings[1:])
+	}
+	NewCache(dict).SetRecoverPanics(true).Get([]*wfn.Attributes{cpe})
+	if len(pkg.warnings) != 2 {
+		t.Errorf("cache without own logger is expected to log to the logger of the package, got %q", pkg.warnings)
+	}
+}
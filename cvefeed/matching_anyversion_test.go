@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchJSONAnyVersion(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictAnyVersion))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	cases := []struct {
+		name      string
+		inventory []*wfn.Attributes
+		cves      []string
+	}{
+		{
+			name:      "top level node",
+			inventory: []*wfn.Attributes{{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.2\\.3"}},
+			cves:      []string{"CVE-2019-0001"},
+		},
+		{
+			name: "nested nodes",
+			inventory: []*wfn.Attributes{
+				{Part: "a", Vendor: "foo", Product: "baz", Version: "4\\.5"},
+				{Part: "o", Vendor: "foo", Product: "os", Version: "10"},
+			},
+			cves: []string{"CVE-2019-0002"},
+		},
+		{
+			name:      "nested nodes, platform missing",
+			inventory: []*wfn.Attributes{{Part: "a", Vendor: "foo", Product: "baz", Version: "4\\.5"}},
+		},
+		{
+			name:      "version not applicable",
+			inventory: []*wfn.Attributes{{Part: "a", Vendor: "foo", Product: "bar", Version: wfn.NA}},
+			cves:      []string{"CVE-2019-0001"},
+		},
+	}
+	for _, indexed := range []bool{false, true} {
+		cache := NewCache(dict).SetMaxSize(-1)
+		if indexed {
+			cache.Idx = NewIndex(dict)
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				ids := cache.MatchIDs(c.inventory...)
+				if len(ids) != len(c.cves) || (len(ids) != 0 && ids[0] != c.cves[0]) {
+					t.Fatalf("indexed %t: expected %v, got %v", indexed, c.cves, ids)
+				}
+			})
+		}
+	}
+}
+
+var testJSONdictAnyVersion = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "CVE_data_meta" : { "ID" : "CVE-2019-0001" }
+    },
+    "configurations" : {
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "CVE_data_meta" : { "ID" : "CVE-2019-0002" }
+    },
+    "configurations" : {
+      "nodes" : [
+        {
+          "operator" : "AND",
+          "children" : [
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*" }
+              ]
+            },
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                { "vulnerable" : false, "cpe23Uri" : "cpe:2.3:o:foo:os:*:*:*:*:*:*:*:*" }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  }
+]
+}`
@@ -0,0 +1,139 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchJSONFirmware(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictFirmware))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	rv320 := &wfn.Attributes{Part: "h", Vendor: "cisco", Product: "rv320"}
+	rv320NA := &wfn.Attributes{Part: "h", Vendor: "cisco", Product: "rv320", Version: wfn.NA}
+	rv325 := &wfn.Attributes{Part: "h", Vendor: "cisco", Product: "rv325"}
+	firmware := func(product, version string) *wfn.Attributes {
+		return &wfn.Attributes{Part: "o", Vendor: "cisco", Product: product, Version: version}
+	}
+	cases := []struct {
+		name      string
+		inventory []*wfn.Attributes
+		match     bool
+	}{
+		{"vulnerable firmware", []*wfn.Attributes{rv320, firmware("rv320_firmware", "1\\.4\\.2\\.17")}, true},
+		{"vulnerable firmware, hardware version N/A", []*wfn.Attributes{firmware("rv320_firmware", "1\\.4\\.2\\.15"), rv320NA}, true},
+		{"fixed firmware", []*wfn.Attributes{rv320, firmware("rv320_firmware", "1\\.4\\.2\\.22")}, false},
+		{"older firmware", []*wfn.Attributes{rv320, firmware("rv320_firmware", "1\\.4\\.2\\.14")}, false},
+		{"firmware without hardware", []*wfn.Attributes{firmware("rv320_firmware", "1\\.4\\.2\\.17")}, false},
+		{"hardware without firmware", []*wfn.Attributes{rv320}, false},
+		{"firmware of another model", []*wfn.Attributes{rv325, firmware("rv320_firmware", "1\\.4\\.2\\.17")}, false},
+		{"another model", []*wfn.Attributes{rv325, firmware("rv325_firmware", "1\\.4\\.2\\.20")}, true},
+		// rv320_firmware matches the first configuration partially and must not be reported
+		{"another model, unpaired firmware", []*wfn.Attributes{rv325, firmware("rv325_firmware", "1\\.4\\.2\\.20"), firmware("rv320_firmware", "1\\.4\\.2\\.17")}, true},
+	}
+	for _, indexed := range []bool{false, true} {
+		for _, requireVersion := range []bool{false, true} {
+			cache := NewCache(dict).SetMaxSize(-1).SetRequireVersion(requireVersion)
+			if indexed {
+				cache.Idx = NewIndex(dict)
+			}
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					res := cache.Get(c.inventory)
+					if c.match != (len(res) == 1) {
+						t.Fatalf("indexed %t, require version %t: expected match %t, got %d results", indexed, requireVersion, c.match, len(res))
+					}
+					if !c.match {
+						return
+					}
+					if len(res[0].CPEs) != 2 || res[0].CPEs[0].Product[:5] != res[0].CPEs[1].Product[:5] {
+						t.Fatalf("expected paired hardware and firmware to be reported, got %v", res[0].CPEs)
+					}
+					vulnerable, platforms := res[0].VulnerableCPEs(), res[0].PlatformCPEs()
+					if len(vulnerable) != 1 || vulnerable[0].Part != "o" || len(platforms) != 1 || platforms[0].Part != "h" {
+						t.Fatalf("expected firmware to be reported vulnerable on hardware platform, got %v on %v", vulnerable, platforms)
+					}
+				})
+			}
+		}
+	}
+}
+
+// testJSONdictFirmware is the configuration of CVE-2019-1653
+var testJSONdictFirmware = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "CVE-2019-1653",
+        "ASSIGNER" : "ykramarz@cisco.com"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [ {
+        "operator" : "AND",
+        "children" : [ {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:o:cisco:rv320_firmware:*:*:*:*:*:*:*:*",
+            "versionStartIncluding" : "1.4.2.15",
+            "versionEndIncluding" : "1.4.2.20"
+          } ]
+        }, {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : false,
+            "cpe23Uri" : "cpe:2.3:h:cisco:rv320:-:*:*:*:*:*:*:*"
+          } ]
+        } ]
+      }, {
+        "operator" : "AND",
+        "children" : [ {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:o:cisco:rv325_firmware:*:*:*:*:*:*:*:*",
+            "versionStartIncluding" : "1.4.2.15",
+            "versionEndIncluding" : "1.4.2.20"
+          } ]
+        }, {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : false,
+            "cpe23Uri" : "cpe:2.3:h:cisco:rv325:-:*:*:*:*:*:*:*"
+          } ]
+        } ]
+      } ]
+    },
+    "publishedDate" : "2019-01-24T15:29Z",
+    "lastModifiedDate" : "2020-10-16T17:03Z"
+  }
+]
+}`
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchFixedVersion(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictFixed))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	cache := NewCache(dict).SetMaxSize(-1)
+	cases := []struct {
+		version string
+		match   bool
+		fixedIn string
+	}{
+		{"1\\.1\\.0", true, ""},
+		{"1\\.1\\.1j", true, "1.1.1k"},
+		{"1\\.1\\.1k", false, ""},
+		{"1\\.1\\.2", false, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.version, func(t *testing.T) {
+			cpe := &wfn.Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: c.version}
+			res := cache.Get([]*wfn.Attributes{cpe})
+			if c.match != (len(res) == 1) {
+				t.Fatalf("expected match %t, got %d results", c.match, len(res))
+			}
+			if !c.match {
+				return
+			}
+			if c.fixedIn == "" && res[0].FixedIn != nil {
+				t.Fatalf("expected fix version to be unknown, got %q", res[0].FixedIn)
+			}
+			if c.fixedIn != "" && (len(res[0].FixedIn) != 1 || res[0].FixedIn[0] != c.fixedIn) {
+				t.Fatalf("expected fix version %q to be reported, got %q", c.fixedIn, res[0].FixedIn)
+			}
+		})
+	}
+}
+
+var testJSONdictFixed = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "CVE-2021-3449",
+        "ASSIGNER" : "openssl-security@openssl.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*",
+            "versionStartIncluding" : "1.1.1",
+            "fixedVersion" : "1.1.1k"
+          }, {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:a:openssl:openssl:1.1.0:*:*:*:*:*:*:*"
+          } ]
+        }
+      ]
+    }
+  }
+] }`
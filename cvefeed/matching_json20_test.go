@@ -0,0 +1,137 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchJSON20(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(testJSON20dict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	if id := items[0].CVEID(); id != "CVE-2020-0002" {
+		t.Fatalf("unexpected CVE ID %q", id)
+	}
+	if assigner := items[0].(nvdcommon.CVEAssigner).Assigner(); assigner != "cve@mitre.org" {
+		t.Errorf("expected sourceIdentifier cve@mitre.org as assigner, got %q", assigner)
+	}
+	if score := items[0].CVSS30base(); score != 9.8 {
+		t.Errorf("expected primary CVSS v3 base score 9.8, got %.1f", score)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "a", Vendor: "example", Product: "widget", Version: "2\\.5"},
+	}
+	// without match criteria the leaf is matched as all versions
+	if _, ok := Match(inventory, items[0].Config(), false); !ok {
+		t.Fatal("expected to match before applying match criteria")
+	}
+
+	mc, err := ParseMatchCriteria(bytes.NewBufferString(testJSON20matchCriteria))
+	if err != nil {
+		t.Fatalf("failed to parse match criteria: %v", err)
+	}
+	dict := Dictionary{items[0].CVEID(): items[0]}
+	dict.ApplyMatchCriteria(mc)
+	if _, ok := Match(inventory, items[0].Config(), false); ok {
+		t.Fatal("version 2.5 is out of range declared by the match criteria, but matched")
+	}
+	inventory[0].Version = "1\\.5"
+	if _, ok := Match(inventory, items[0].Config(), false); !ok {
+		t.Fatal("version 1.5 is in range declared by the match criteria, but didn't match")
+	}
+}
+
+var testJSON20dict = `{
+  "resultsPerPage": 1,
+  "startIndex": 0,
+  "totalResults": 1,
+  "format": "NVD_CVE",
+  "version": "2.0",
+  "timestamp": "2023-01-01T00:00:00.000",
+  "vulnerabilities": [
+    {
+      "cve": {
+        "id": "CVE-2020-0002",
+        "sourceIdentifier": "cve@mitre.org",
+        "published": "2020-01-01T10:15:00.000",
+        "lastModified": "2020-02-01T10:15:00.000",
+        "vulnStatus": "Analyzed",
+        "descriptions": [{"lang": "en", "value": "Widget is vulnerable."}],
+        "metrics": {
+          "cvssMetricV31": [
+            {
+              "source": "cna@example.com",
+              "type": "Secondary",
+              "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", "baseScore": 8.8, "baseSeverity": "HIGH"}
+            },
+            {
+              "source": "nvd@nist.gov",
+              "type": "Primary",
+              "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8, "baseSeverity": "CRITICAL"}
+            }
+          ]
+        },
+        "weaknesses": [{"source": "nvd@nist.gov", "type": "Primary", "description": [{"lang": "en", "value": "CWE-79"}]}],
+        "configurations": [
+          {
+            "nodes": [
+              {
+                "operator": "OR",
+                "negate": false,
+                "cpeMatch": [
+                  {
+                    "vulnerable": true,
+                    "criteria": "cpe:2.3:a:example:widget:*:*:*:*:*:*:*:*",
+                    "matchCriteriaId": "8D2E7E29-5D0A-4E05-A8D5-C2E5D3B5B7C1"
+                  }
+                ]
+              }
+            ]
+          }
+        ],
+        "references": [{"url": "https://example.com/advisory", "source": "cve@mitre.org"}]
+      }
+    }
+  ]
+}`
+
+var testJSON20matchCriteria = `{
+  "resultsPerPage": 1,
+  "startIndex": 0,
+  "totalResults": 1,
+  "format": "NVD_CPEMatchString",
+  "version": "2.0",
+  "timestamp": "2023-01-01T00:00:00.000",
+  "matchStrings": [
+    {
+      "matchString": {
+        "matchCriteriaId": "8D2E7E29-5D0A-4E05-A8D5-C2E5D3B5B7C1",
+        "criteria": "cpe:2.3:a:example:widget:*:*:*:*:*:*:*:*",
+        "versionStartIncluding": "1.0",
+        "versionEndExcluding": "2.0",
+        "status": "Active"
+      }
+    }
+  ]
+}`
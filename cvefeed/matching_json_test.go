@@ -17,6 +17,8 @@ package cvefeed
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/facebookincubator/nvdtools/wfn"
@@ -147,6 +149,93 @@ func BenchmarkMatchJSON(b *testing.B) {
 	}
 }
 
+func TestMatchIDs(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.4"},
+	}
+	for _, size := range []int64{-1, 0} {
+		cache := NewCache(dict).SetMaxSize(size)
+		for run := 0; run < 2; run++ {
+			var expected []string
+			for _, r := range cache.Get(inventory) {
+				expected = append(expected, r.CVE.CVEID())
+			}
+			sort.Strings(expected)
+			ids := cache.MatchIDs(inventory...)
+			if len(ids) == 0 || !reflect.DeepEqual(ids, expected) {
+				t.Errorf("cache size %d, run %d: expected %v, got %v", size, run, expected, ids)
+			}
+		}
+	}
+}
+
+// BenchmarkMatchIDs compares MatchIDs with Get of the same inventory, caching is disabled so both match every time
+func BenchmarkMatchIDs(b *testing.B) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		b.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"},
+	}
+	cache := NewCache(dict).SetMaxSize(-1)
+	b.Run("MatchIDs", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if ids := cache.MatchIDs(inventory...); len(ids) == 0 {
+				b.Fatal("expected MatchIDs to match, it did not")
+			}
+		}
+	})
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if results := cache.Get(inventory); len(results) == 0 {
+				b.Fatal("expected Get to match, it did not")
+			}
+		}
+	})
+}
+
+func TestGetSoft(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: wfn.NA},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.1", Language: wfn.NA},
+	}
+	cache := NewCache(dict)
+	if results := cache.Get(inventory); len(results) != 0 {
+		t.Fatalf("NA update is not expected to match strictly, got %d results", len(results))
+	}
+	results := cache.GetSoft(inventory)
+	if len(results) != 1 || results[0].CVE.CVEID() != "TESTVE-2018-0001" {
+		t.Fatalf("expected TESTVE-2018-0001 to match, got %v", results)
+	}
+	if !matchesAll(results[0].CPEs, inventory) {
+		t.Errorf("expected results to refer to the inventory, got %v", results[0].CPEs)
+	}
+	for _, r := range cache.GetSoft(inventory) { // cached
+		for _, cpe := range r.CPEs {
+			if cpe != inventory[0] && cpe != inventory[1] {
+				t.Errorf("expected results to refer to the inventory, got %v", cpe)
+			}
+		}
+	}
+}
+
 var testJSONdictBroken = `{
   "CVE_data_format":"",
   "CVE_data_type":"",
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestCacheMatchOptions(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictMatchOptions))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	plain := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	targeted := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0", TargetSW: "ios", TargetHW: "arm64"}
+	anyVersion := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar"}
+	cases := []struct {
+		name     string
+		opts     wfn.MatchOptions
+		cpe      *wfn.Attributes
+		expected []string
+	}{
+		{"default", wfn.MatchOptions{}, plain, []string{"CVE-2022-0001", "CVE-2022-0002", "CVE-2022-0003", "CVE-2022-0004"}},
+		{"default targeted", wfn.MatchOptions{}, targeted, []string{"CVE-2022-0002", "CVE-2022-0003"}},
+		{"ignore target_sw", wfn.MatchOptions{IgnoreTargetSW: true}, targeted, []string{"CVE-2022-0001", "CVE-2022-0002", "CVE-2022-0003"}},
+		{"ignore target_hw", wfn.MatchOptions{IgnoreTargetHW: true}, targeted, []string{"CVE-2022-0002", "CVE-2022-0003", "CVE-2022-0004"}},
+		{"any not NA", wfn.MatchOptions{AnyNotNA: true}, plain, []string{"CVE-2022-0001", "CVE-2022-0002", "CVE-2022-0004"}},
+		{"exact version", wfn.MatchOptions{ExactVersion: true}, plain, []string{"CVE-2022-0001", "CVE-2022-0003", "CVE-2022-0004"}},
+		{"exact version ANY", wfn.MatchOptions{ExactVersion: true}, anyVersion, nil},
+	}
+	for _, c := range cases {
+		cache := NewCache(dict).SetMatchOptions(c.opts)
+		target := NewCompiledTarget([]*wfn.Attributes{c.cpe}).SetMatchOptions(c.opts)
+		for i, results := range [][]MatchResult{cache.Get([]*wfn.Attributes{c.cpe}), target.Match(dict)} {
+			var got []string
+			for _, r := range results {
+				got = append(got, r.CVE.CVEID())
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("%s (%d): expected %v, got %v", c.name, i, c.expected, got)
+			}
+		}
+	}
+}
+
+var testJSONdictMatchOptions = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2022-0001" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:1.0:*:*:*:*:android:*:*" } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2022-0002" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionEndExcluding" : "2.0"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2022-0003" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:1.0:-:*:*:*:*:*:*" } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2022-0004" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:x64:*" } ]
+      } ]
+    }
+  }
+]
+}`
@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchJSONOther(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictOther))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	edition := func(other, version string) *wfn.Attributes {
+		return &wfn.Attributes{Part: "a", Vendor: "example", Product: "server", Version: version, Other: other}
+	}
+	cases := []struct {
+		name  string
+		cpe   *wfn.Attributes
+		match bool
+	}{
+		{"vulnerable edition", edition("enterprise", "2\\.1"), true},
+		{"vulnerable edition, fixed version", edition("enterprise", "2\\.4"), false},
+		{"another edition", edition("community", "2\\.1"), false},
+		{"edition not applicable", edition(wfn.NA, "2\\.1"), false},
+		{"any edition", edition(wfn.Any, "2\\.1"), true},
+	}
+	for _, indexed := range []bool{false, true} {
+		cache := NewCache(dict).SetMaxSize(-1)
+		if indexed {
+			cache.Idx = NewIndex(dict)
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				if res := cache.Get([]*wfn.Attributes{c.cpe}); c.match != (len(res) == 1) {
+					t.Fatalf("indexed %t: expected match %t, got %d results", indexed, c.match, len(res))
+				}
+			})
+		}
+	}
+	// soft matching treats NA other of inventory as ANY
+	if res := NewCache(dict).SetMaxSize(-1).GetSoft([]*wfn.Attributes{edition(wfn.NA, "2\\.1")}); len(res) != 1 {
+		t.Fatalf("expected soft match of NA other, got %d results", len(res))
+	}
+}
+
+var testJSONdictOther = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "CVE-2020-0001",
+        "ASSIGNER" : "cve@mitre.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true,
+          "cpe23Uri" : "cpe:2.3:a:example:server:*:*:*:*:*:*:*:enterprise",
+          "versionStartIncluding" : "2.0",
+          "versionEndExcluding" : "2.3"
+        } ]
+      } ]
+    }
+  }
+]
+}`
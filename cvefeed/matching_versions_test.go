@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchAnyVersion(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictVersions))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	base := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "2\\.0"}
+	cases := []struct {
+		name     string
+		versions []string
+		expected map[string][]string
+	}{
+		{"none vulnerable", []string{"0.9", "3.0"}, map[string][]string{}},
+		{"one vulnerable", []string{"0.9", "1.5", "3.0"}, map[string][]string{"CVE-2020-0001": {"1.5"}}},
+		{
+			"union",
+			[]string{"2.5", "1.5", "0.9", "2.1"},
+			map[string][]string{"CVE-2020-0001": {"1.5"}, "CVE-2020-0002": {"2.5", "2.1"}},
+		},
+		{"no versions", nil, map[string][]string{}},
+	}
+	for _, indexed := range []bool{false, true} {
+		cache := NewCache(dict)
+		if indexed {
+			cache.Idx = NewIndex(dict)
+		}
+		for _, c := range cases {
+			results := cache.MatchAnyVersion(base, c.versions)
+			got := map[string][]string{}
+			for i, r := range results {
+				if i > 0 && results[i-1].CVE.CVEID() >= r.CVE.CVEID() {
+					t.Errorf("indexed %t, %s: results aren't sorted by CVE ID", indexed, c.name)
+				}
+				got[r.CVE.CVEID()] = r.Versions
+				for j, cpe := range r.CPEs {
+					if v, _ := wfn.WFNize(r.Versions[j]); cpe.Version != v || cpe.Product != base.Product {
+						t.Errorf("indexed %t, %s: CPE %v not aligned with version %s", indexed, c.name, cpe, r.Versions[j])
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("indexed %t, %s: expected %v, got %v", indexed, c.name, c.expected, got)
+			}
+		}
+	}
+	if base.Version != "2\\.0" {
+		t.Errorf("base CPE was modified: %v", base)
+	}
+}
+
+var testJSONdictVersions = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2020-0001" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionStartIncluding" : "1.0", "versionEndExcluding" : "2.0"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2020-0002" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionStartExcluding" : "2.0", "versionEndIncluding" : "2.5"
+        } ]
+      } ]
+    }
+  }
+]
+}`
@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestWildcardVersionRange(t *testing.T) {
+	cases := []struct {
+		version    string
+		start, end string
+		ok         bool
+	}{
+		{"2\\.4\\.*", "2.4.0", "2.5.0", true},
+		{"2\\.*", "2.0", "3.0", true},
+		{"1\\.9\\.*", "1.9.0", "1.10.0", true},
+		{"2\\.4", "", "", false},
+		{"2\\.4*", "", "", false},
+		{"2\\.4\\.", "", "", false}, // escaped, but no wildcard
+		{"2\\.rc\\.*", "", "", false},
+		{"\\.*", "", "", false},
+		{wfn.Any, "", "", false},
+		{wfn.NA, "", "", false},
+	}
+	for _, c := range cases {
+		start, end, ok := WildcardVersionRange(c.version)
+		if start != c.start || end != c.end || ok != c.ok {
+			t.Errorf("%q: expected %q, %q, %t, got %q, %q, %t", c.version, c.start, c.end, c.ok, start, end, ok)
+		}
+	}
+}
+
+func TestCacheWildcardVersions(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictWildcardVersions))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	cases := []struct {
+		version  string
+		expected []string
+	}{
+		{"2\\.4\\.*", []string{"CVE-2021-0001", "CVE-2021-0003", "CVE-2021-0005"}},
+		{"2\\.*", []string{"CVE-2021-0001", "CVE-2021-0003", "CVE-2021-0004", "CVE-2021-0005"}},
+		{"2\\.4\\.5", []string{"CVE-2021-0001", "CVE-2021-0005"}},
+		{"1\\.*", []string{"CVE-2021-0002", "CVE-2021-0005"}},
+	}
+	for _, indexed := range []bool{false, true} {
+		cache := NewCache(dict).SetWildcardVersions(true)
+		if indexed {
+			cache.Idx = NewIndex(dict)
+		}
+		for _, c := range cases {
+			cpe := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: c.version}
+			var got []string
+			for _, r := range cache.Get([]*wfn.Attributes{cpe}) {
+				got = append(got, r.CVE.CVEID())
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("indexed %t, %s: expected %v, got %v", indexed, c.version, c.expected, got)
+			}
+		}
+	}
+}
+
+var testJSONdictWildcardVersions = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-0001" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionStartIncluding" : "2.4.3", "versionEndExcluding" : "2.4.8"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-0002" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionEndExcluding" : "2.0"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-0003" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:2.4.1:*:*:*:*:*:*:*"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-0004" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*",
+          "versionStartIncluding" : "2.5.0"
+        } ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-0005" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {
+          "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*"
+        } ]
+      } ]
+    }
+  }
+]
+}`
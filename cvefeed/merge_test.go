@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMergeDictionaries(t *testing.T) {
+	load := func(feed string) Dictionary {
+		dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+			return ParseJSON(bytes.NewBufferString(feed))
+		}, "")
+		if err != nil {
+			t.Fatalf("failed to parse the dictionary: %v", err)
+		}
+		return dict
+	}
+	sources := map[string]Dictionary{
+		"nvd":    load(testJSONdictMergeNVD),
+		"vendor": load(testJSONdictMergeVendor),
+	}
+	policy := MergePolicy{
+		Default: []string{"nvd", "vendor"},
+		Fields: map[nvdcommon.Field][]string{
+			nvdcommon.FieldConfig: {"vendor", "nvd"},
+		},
+	}
+	merged := MergeDictionaries(sources, policy)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 merged items, got %d", len(merged))
+	}
+
+	cve := merged["CVE-2019-0001"]
+	if cve == nil {
+		t.Fatal("CVE-2019-0001 is missing")
+	}
+	if score := cve.CVSS30base(); score != 9.8 {
+		t.Errorf("expected NVD CVSS 3.0 score 9.8, got %.1f", score)
+	}
+	if score := cve.CVSS20base(); score != 5.0 {
+		t.Errorf("expected vendor CVSS 2.0 score 5.0 since NVD doesn't define it, got %.1f", score)
+	}
+	inventory := []*wfn.Attributes{{Part: "a", Vendor: "acme", Product: "anvil", Version: "1\\.5"}}
+	if _, ok := Match(inventory, cve.Config(), false); ok {
+		t.Error("expected vendor configuration to win, but NVD one was used")
+	}
+	inventory[0].Version = "2\\.5"
+	if _, ok := Match(inventory, cve.Config(), false); !ok {
+		t.Error("expected vendor configuration to match")
+	}
+
+	if cve := merged["CVE-2019-0002"]; cve == nil || len(cve.Config()) == 0 {
+		t.Error("CVE known only to NVD expected to be merged with its configuration")
+	}
+
+	p, ok := cve.(Provenance)
+	if !ok {
+		t.Fatal("merged item expected to implement Provenance")
+	}
+	if srcs := p.Sources(); len(srcs) != 2 || srcs[0] != "nvd" || srcs[1] != "vendor" {
+		t.Errorf("expected sources [nvd vendor], got %v", srcs)
+	}
+	for f, want := range map[nvdcommon.Field]string{
+		nvdcommon.FieldConfig: "vendor",
+		nvdcommon.FieldCVSS20: "vendor",
+		nvdcommon.FieldCVSS30: "nvd",
+	} {
+		if src := p.SourceOf(f); src != want {
+			t.Errorf("expected %s from %q, got %q", f, want, src)
+		}
+	}
+	if p, ok := merged["CVE-2019-0002"].(Provenance); !ok || len(p.Sources()) != 1 || p.SourceOf(nvdcommon.FieldCVSS20) != "" {
+		t.Error("CVE known only to NVD expected to have a single source and no CVSS 2.0 provenance")
+	}
+
+	policy.Default = []string{"vendor"}
+	policy.Fields = nil
+	if merged = MergeDictionaries(sources, policy); len(merged) != 1 {
+		t.Errorf("sources missing in policy shouldn't contribute, got %d items", len(merged))
+	}
+}
+
+func TestParseMergePolicy(t *testing.T) {
+	policy, err := ParseMergePolicy("nvd, vendor", []string{"config=vendor,nvd", "cvss2=vendor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.Default) != 2 || policy.Default[0] != "nvd" || policy.Default[1] != "vendor" {
+		t.Errorf("unexpected default priority %v", policy.Default)
+	}
+	if order := policy.Fields[nvdcommon.FieldConfig]; len(order) != 2 || order[0] != "vendor" {
+		t.Errorf("unexpected config priority %v", order)
+	}
+	if order := policy.Fields[nvdcommon.FieldCVSS20]; len(order) != 1 || order[0] != "vendor" {
+		t.Errorf("unexpected cvss2 priority %v", order)
+	}
+	for _, fp := range []string{"config", "score=nvd"} {
+		if _, err := ParseMergePolicy("nvd", []string{fp}); err == nil {
+			t.Errorf("field priority %q expected to be an error", fp)
+		}
+	}
+}
+
+var testJSONdictMergeNVD = `{
+"CVE_Items" : [
+  {
+    "cve" : {"CVE_data_meta" : {"ID" : "CVE-2019-0001"}},
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {"vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:anvil:*:*:*:*:*:*:*:*", "versionEndExcluding" : "2.0"} ]
+      } ]
+    },
+    "impact" : {"baseMetricV3" : {"cvssV3" : {"baseScore" : 9.8}}}
+  },
+  {
+    "cve" : {"CVE_data_meta" : {"ID" : "CVE-2019-0002"}},
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {"vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:hammer:*:*:*:*:*:*:*:*"} ]
+      } ]
+    }
+  }
+] }`
+
+var testJSONdictMergeVendor = `{
+"CVE_Items" : [
+  {
+    "cve" : {"CVE_data_meta" : {"ID" : "CVE-2019-0001"}},
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [ {"vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:anvil:*:*:*:*:*:*:*:*", "versionStartIncluding" : "2.0", "versionEndExcluding" : "3.0"} ]
+      } ]
+    },
+    "impact" : {
+      "baseMetricV2" : {"cvssV2" : {"baseScore" : 5.0}},
+      "baseMetricV3" : {"cvssV3" : {"baseScore" : 7.5}}
+    }
+  }
+] }`
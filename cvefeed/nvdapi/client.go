@@ -0,0 +1,256 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nvdapi provides a client of NVD CVE API 2.0 which takes care of pagination and rate limits.
+package nvdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdjson"
+)
+
+// Defaults of Client configuration, as per NVD API documentation
+const (
+	DefaultBaseURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+	// DefaultPeriod is the rolling window the number of requests is limited in
+	DefaultPeriod = 30 * time.Second
+	// DefaultRequestsPerPeriod is the limit of unauthenticated requests
+	DefaultRequestsPerPeriod = 5
+	// DefaultRequestsPerPeriodWithKey is the limit of requests made with an API key
+	DefaultRequestsPerPeriodWithKey = 50
+	// DefaultResultsPerPage is the maximum page size allowed by the API
+	DefaultResultsPerPage = 2000
+	DefaultMaxRetries     = 3
+	DefaultBackoff        = 6 * time.Second
+)
+
+// Error is returned for failed requests; Retryable tells temporary failures (e.g. rate limit exceeded, server
+// or network errors), which might succeed if repeated later, from fatal ones (e.g. invalid parameters or API key)
+type Error struct {
+	URL        string
+	StatusCode int    // 0 if no response was received
+	Message    string // the reason reported by the API, the response status or the network error
+	Retryable  bool
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("nvdapi: request to %q failed: %s", e.URL, e.Message)
+	}
+	return fmt.Sprintf("nvdapi: %q responded %d: %s", e.URL, e.StatusCode, e.Message)
+}
+
+// IsRetryable reports whether err is a temporary failure of the API, see Error
+func IsRetryable(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Retryable
+}
+
+// Client fetches CVEs from NVD CVE API 2.0; zero values of the fields mean their defaults.
+// Requests are throttled to RequestsPerPeriod in a rolling window of Period shared by all the calls,
+// responses 403 (NVD way to report exceeded rate limit), 429 and 5xx are retried with exponential backoff.
+type Client struct {
+	BaseURL           string
+	APIKey            string // sent in apiKey header, raises the default rate limit
+	Period            time.Duration
+	RequestsPerPeriod int
+	ResultsPerPage    int
+	MaxRetries        int // negative disables retries
+	Backoff           time.Duration
+	HTTPClient        *http.Client
+	UserAgent         string
+
+	mu       sync.Mutex
+	requests []time.Time // start times of the recent requests, at most RequestsPerPeriod
+}
+
+// NewClient creates a client of NVD API with the default configuration; apiKey may be empty
+func NewClient(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+// Fetch fetches all CVEs selected by params (e.g. lastModStartDate and lastModEndDate) page by page,
+// calling fn with the CVE items of every page; fetching stops at the first error returned by fn.
+// Pagination parameters in params are overridden.
+func (c *Client) Fetch(ctx context.Context, params url.Values, fn func([]nvdcommon.CVEItem) error) error {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	perPage := c.ResultsPerPage
+	if perPage <= 0 {
+		perPage = DefaultResultsPerPage
+	}
+	query.Set("resultsPerPage", strconv.Itoa(perPage))
+	for start := 0; ; {
+		query.Set("startIndex", strconv.Itoa(start))
+		page, err := c.fetchPage(ctx, query)
+		if err != nil {
+			return err
+		}
+		items, err := nvdjson.ConvertVulnerabilities(page.Vulnerabilities)
+		if err != nil {
+			return fmt.Errorf("nvdapi: page at %d: %v", start, err)
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+		if len(page.Vulnerabilities) == 0 {
+			return nil
+		}
+		start = page.StartIndex + len(page.Vulnerabilities)
+		if start >= page.TotalResults {
+			return nil
+		}
+	}
+}
+
+// fetchPage requests one page, retrying temporary failures
+func (c *Client) fetchPage(ctx context.Context, query url.Values) (*jsonschema.NVDCVE20, error) {
+	retries := c.MaxRetries
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		page, retryAfter, err := c.get(ctx, query)
+		if err == nil || !IsRetryable(err) || attempt >= retries {
+			return page, err
+		}
+		delay := backoff << uint(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// get makes a single request once the rate limit allows it;
+// the delay requested by the server in Retry-After header is returned along with the retryable errors
+func (c *Client) get(ctx context.Context, query url.Values) (*jsonschema.NVDCVE20, time.Duration, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u := baseURL + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, &Error{URL: u, Message: err.Error()}
+	}
+	req = req.WithContext(ctx)
+	if c.APIKey != "" {
+		req.Header.Set("apiKey", c.APIKey)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, 0, err
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		return nil, 0, &Error{URL: u, Message: err.Error(), Retryable: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg := resp.Header.Get("message") // NVD explains the failures in message header
+		if msg == "" {
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4*1024))
+			msg = fmt.Sprintf("%s %q", resp.Status, body)
+		}
+		e := &Error{URL: u, StatusCode: resp.StatusCode, Message: msg, Retryable: retryableStatus(resp.StatusCode)}
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, retryAfter, e
+	}
+	var page jsonschema.NVDCVE20
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		// truncated responses are usually network failures
+		return nil, 0, &Error{URL: u, StatusCode: resp.StatusCode, Message: "decode response: " + err.Error(), Retryable: err == io.ErrUnexpectedEOF}
+	}
+	return &page, 0, nil
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusForbidden || code == http.StatusTooManyRequests || code >= 500
+}
+
+// wait blocks until the next request is allowed by the rate limit and records it
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	period := c.Period
+	if period <= 0 {
+		period = DefaultPeriod
+	}
+	limit := c.RequestsPerPeriod
+	if limit <= 0 {
+		limit = DefaultRequestsPerPeriod
+		if c.APIKey != "" {
+			limit = DefaultRequestsPerPeriodWithKey
+		}
+	}
+	if len(c.requests) >= limit {
+		oldest := c.requests[len(c.requests)-limit]
+		if err := sleep(ctx, time.Until(oldest.Add(period))); err != nil {
+			return err
+		}
+	}
+	c.requests = append(c.requests, time.Now())
+	if len(c.requests) > limit {
+		c.requests = append(c.requests[:0], c.requests[len(c.requests)-limit:]...)
+	}
+	return nil
+}
+
+// sleep waits for d or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// testServer serves total CVEs page by page, failing the first requests with failStatus
+func testServer(t *testing.T, total, failures, failStatus int) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if r.Header.Get("apiKey") != "secret" {
+			t.Errorf("request %d: expected API key header, got %q", n, r.Header.Get("apiKey"))
+		}
+		if int(n) <= failures {
+			w.Header().Set("message", "try again later")
+			w.WriteHeader(failStatus)
+			return
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("resultsPerPage"))
+		if r.URL.Query().Get("lastModStartDate") == "" {
+			t.Errorf("request %d: query parameters weren't passed: %v", n, r.URL.Query())
+		}
+		fmt.Fprintf(w, `{"resultsPerPage":%d,"startIndex":%d,"totalResults":%d,"format":"NVD_CVE","version":"2.0","vulnerabilities":[`, perPage, start, total)
+		for i := start; i < start+perPage && i < total; i++ {
+			if i != start {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"cve":{"id":"CVE-2020-%04d","configurations":[]}}`, i)
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	return srv, &requests
+}
+
+func testClient(srv *httptest.Server) *Client {
+	c := NewClient("secret")
+	c.BaseURL = srv.URL
+	c.ResultsPerPage = 2
+	c.Backoff = time.Millisecond
+	c.Period = time.Second
+	return c
+}
+
+var testParams = url.Values{"lastModStartDate": {"2020-01-01T00:00:00.000"}}
+
+func TestFetchPagination(t *testing.T) {
+	srv, requests := testServer(t, 5, 0, 0)
+	defer srv.Close()
+	var ids []string
+	err := testClient(srv).Fetch(context.Background(), testParams, func(items []nvdcommon.CVEItem) error {
+		for _, item := range items {
+			ids = append(ids, item.CVEID())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 5 || ids[0] != "CVE-2020-0000" || ids[4] != "CVE-2020-0004" {
+		t.Errorf("unexpected CVEs fetched: %v", ids)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 pages requested, got %d", *requests)
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	for _, status := range []int{http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		srv, requests := testServer(t, 1, 2, status)
+		n := 0
+		err := testClient(srv).Fetch(context.Background(), testParams, func(items []nvdcommon.CVEItem) error {
+			n += len(items)
+			return nil
+		})
+		if err != nil || n != 1 || *requests != 3 {
+			t.Errorf("status %d: expected the failures to be retried, got %d CVEs in %d requests: %v", status, n, *requests, err)
+		}
+		srv.Close()
+	}
+
+	srv, requests := testServer(t, 1, 10, http.StatusTooManyRequests)
+	defer srv.Close()
+	err := testClient(srv).Fetch(context.Background(), testParams, func([]nvdcommon.CVEItem) error { return nil })
+	if !IsRetryable(err) {
+		t.Errorf("expected retryable error once retries are exhausted, got %v", err)
+	}
+	if *requests != DefaultMaxRetries+1 {
+		t.Errorf("expected %d requests, got %d", DefaultMaxRetries+1, *requests)
+	}
+}
+
+func TestFetchFatal(t *testing.T) {
+	srv, requests := testServer(t, 1, 10, http.StatusNotFound)
+	defer srv.Close()
+	err := testClient(srv).Fetch(context.Background(), testParams, func([]nvdcommon.CVEItem) error { return nil })
+	e, ok := err.(*Error)
+	if !ok || e.Retryable || e.StatusCode != http.StatusNotFound || e.Message != "try again later" {
+		t.Errorf("expected fatal error with the message of the API, got %#v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("fatal error isn't expected to be retried, got %d requests", *requests)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv, _ := testServer(t, 8, 0, 0)
+	defer srv.Close()
+	c := testClient(srv)
+	c.RequestsPerPeriod = 2
+	c.Period = 200 * time.Millisecond
+	start := time.Now()
+	if err := c.Fetch(context.Background(), testParams, func([]nvdcommon.CVEItem) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// 4 pages, 2 requests per period: the last 2 wait for the first period to pass
+	if elapsed := time.Since(start); elapsed < c.Period {
+		t.Errorf("expected requests to be throttled, 4 pages took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Fetch(ctx, testParams, func([]nvdcommon.CVEItem) error { return nil }); err != context.Canceled {
+		t.Errorf("expected throttled request to be canceled with the context, got %v", err)
+	}
+}
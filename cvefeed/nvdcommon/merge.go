@@ -0,0 +1,225 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdcommon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field identifies a part of CVE item which can be merged independently of the others
+type Field int
+
+// Possible values of Field
+const (
+	FieldConfig Field = iota
+	FieldProblemTypes
+	FieldCVSS20
+	FieldCVSS30
+	FieldDescription
+	FieldDates
+)
+
+// Fields lists all fields of CVE item in the order they're merged
+var Fields = []Field{FieldConfig, FieldProblemTypes, FieldCVSS20, FieldCVSS30, FieldDescription, FieldDates}
+
+var fieldNames = map[Field]string{
+	FieldConfig:       "config",
+	FieldProblemTypes: "cwe",
+	FieldCVSS20:       "cvss2",
+	FieldCVSS30:       "cvss3",
+	FieldDescription:  "description",
+	FieldDates:        "dates",
+}
+
+// String returns the name of the field, see ParseField
+func (f Field) String() string {
+	if name, ok := fieldNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Field(%d)", int(f))
+}
+
+// ParseField returns the field of the name: config, cwe, cvss2, cvss3, description or dates
+func ParseField(name string) (Field, error) {
+	for f, n := range fieldNames {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown field %q, expected one of config, cwe, cvss2, cvss3, description or dates", name)
+}
+
+// MergePolicy defines field-level precedence of sources when merging CVE items.
+// For each field the value is taken from the first source in the field's priority list that has it defined
+// (non-empty configuration, problem types or description, non-zero score, known publication date);
+// fields not listed in Fields use Default priority list.
+// Sources missing in the priority list never contribute to the field.
+type MergePolicy struct {
+	Default []string
+	Fields  map[Field][]string
+}
+
+// priority returns the list of sources ordered by priority for the field
+func (p MergePolicy) priority(f Field) []string {
+	if order, ok := p.Fields[f]; ok {
+		return order
+	}
+	return p.Default
+}
+
+// sources returns the sources of items which contribute to any field: the ones of Default priority list
+// in its order, then the others in lexical order
+func (p MergePolicy) sources(items map[string]CVEItem) []string {
+	var sources, others []string
+	seen := map[string]bool{}
+	for _, src := range p.Default {
+		if _, ok := items[src]; ok && !seen[src] {
+			sources = append(sources, src)
+			seen[src] = true
+		}
+	}
+	for _, order := range p.Fields {
+		for _, src := range order {
+			if _, ok := items[src]; ok && !seen[src] {
+				others = append(others, src)
+				seen[src] = true
+			}
+		}
+	}
+	sort.Strings(others)
+	return append(sources, others...)
+}
+
+// Provenance is implemented by CVE items merged from several sources, which tell where each field came from,
+// see MergeCVEItemsByPolicy
+type Provenance interface {
+	// Sources returns the names of the sources which provided the item, see MergeCVEItemsByPolicy
+	Sources() []string
+	// SourceOf returns the name of the source the field was taken from, empty string if none defined it
+	SourceOf(f Field) string
+}
+
+// MergeCVEItemsByPolicy merges different views (keyed by source name) of the same CVE into one item as per policy.
+// It returns nil if none of the sources in policy provided the item.
+// The merged item implements Provenance: its sources are the ones of Default priority list in its order, then the
+// other contributing ones in lexical order. Apart from CVEItem, it implements CVEDescription, CVEDates and CVSSVectors,
+// the vectors come along with the scores.
+func MergeCVEItemsByPolicy(items map[string]CVEItem, policy MergePolicy) CVEItem {
+	z := policyCVEItem{provenance: map[Field]string{}}
+	pick := func(f Field, defined func(CVEItem) bool) CVEItem {
+		for _, src := range policy.priority(f) {
+			if item, ok := items[src]; ok && item != nil && defined(item) {
+				z.provenance[f] = src
+				return item
+			}
+		}
+		return nil
+	}
+	for _, f := range Fields {
+		for _, src := range policy.priority(f) {
+			if item, ok := items[src]; ok && item != nil {
+				z.id = item.CVEID()
+				break
+			}
+		}
+		if z.id != "" {
+			break
+		}
+	}
+	if z.id == "" {
+		return nil
+	}
+	z.sources = policy.sources(items)
+	if item := pick(FieldConfig, func(i CVEItem) bool { return len(i.Config()) != 0 }); item != nil {
+		z.config = item.Config()
+	}
+	if item := pick(FieldProblemTypes, func(i CVEItem) bool { return len(i.ProblemTypes()) != 0 }); item != nil {
+		z.problemTypes = item.ProblemTypes()
+	}
+	if item := pick(FieldCVSS20, func(i CVEItem) bool { return i.CVSS20base() != 0 }); item != nil {
+		z.cvss20base = item.CVSS20base()
+		if v, ok := item.(CVSSVectors); ok {
+			z.cvss20vector = v.CVSS20vector()
+		}
+	}
+	if item := pick(FieldCVSS30, func(i CVEItem) bool { return i.CVSS30base() != 0 }); item != nil {
+		z.cvss30base = item.CVSS30base()
+		if v, ok := item.(CVSSVectors); ok {
+			z.cvss30vector = v.CVSS30vector()
+		}
+	}
+	if item := pick(FieldDescription, func(i CVEItem) bool {
+		d, ok := i.(CVEDescription)
+		return ok && d.Description() != ""
+	}); item != nil {
+		z.description = item.(CVEDescription).Description()
+	}
+	if item := pick(FieldDates, func(i CVEItem) bool {
+		d, ok := i.(CVEDates)
+		return ok && !d.Published().IsZero()
+	}); item != nil {
+		d := item.(CVEDates)
+		z.published, z.lastModified = d.Published(), d.LastModified()
+	}
+	return z
+}
+
+// policyCVEItem is a CVE item merged by MergeCVEItemsByPolicy
+type policyCVEItem struct {
+	mergeCVEItem
+	cvss20vector, cvss30vector string
+	description                string
+	published, lastModified    time.Time
+	sources                    []string
+	provenance                 map[Field]string
+}
+
+// Sources is a part of Provenance interface implementation
+func (i policyCVEItem) Sources() []string {
+	return i.sources
+}
+
+// SourceOf is a part of Provenance interface implementation
+func (i policyCVEItem) SourceOf(f Field) string {
+	return i.provenance[f]
+}
+
+// Description is a part of CVEDescription interface implementation
+func (i policyCVEItem) Description() string {
+	return i.description
+}
+
+// Published is a part of CVEDates interface implementation
+func (i policyCVEItem) Published() time.Time {
+	return i.published
+}
+
+// LastModified is a part of CVEDates interface implementation
+func (i policyCVEItem) LastModified() time.Time {
+	return i.lastModified
+}
+
+// CVSS20vector is a part of CVSSVectors interface implementation
+func (i policyCVEItem) CVSS20vector() string {
+	return i.cvss20vector
+}
+
+// CVSS30vector is a part of CVSSVectors interface implementation
+func (i policyCVEItem) CVSS30vector() string {
+	return i.cvss30vector
+}
@@ -18,6 +18,10 @@
 package nvdcommon
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/purl"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -33,6 +37,209 @@ type LogicalTest interface {
 	CPEs() []*wfn.Attributes
 }
 
+// FixedVersionTest is implemented by logical tests which know the version vulnerability was fixed in
+type FixedVersionTest interface {
+	// FixedIn returns the version the vulnerability was fixed in for the given platform or empty string if unknown
+	FixedIn(platform *wfn.Attributes) string
+}
+
+// VulnerableTest is implemented by logical tests which tell vulnerable components from the platforms
+// they have to run on, e.g. in "app X running on OS Y" only X is vulnerable, Y is a platform constraint
+type VulnerableTest interface {
+	// MatchVulnerable is like MatchPlatform, but only considers the CPEs marked as vulnerable
+	MatchVulnerable(platform *wfn.Attributes, requireVersion bool) bool
+}
+
+// VersionRangeTest is implemented by logical tests which can match platforms standing for a range of versions,
+// e.g. the inventory version 2.4.* standing for [2.4.0, 2.5.0); the version of the platform itself is disregarded
+type VersionRangeTest interface {
+	// MatchPlatformRange is like MatchPlatform, but the platform matches if any version in [start, end) does
+	MatchPlatformRange(platform *wfn.Attributes, start, end string, requireVersion bool) bool
+	// MatchVulnerableRange is like MatchPlatformRange, but only considers the CPEs marked as vulnerable
+	MatchVulnerableRange(platform *wfn.Attributes, start, end string, requireVersion bool) bool
+}
+
+// MatchOptionsTest is implemented by logical tests which can match platforms with other semantics than the usual one,
+// e.g. disregarding target_sw, see wfn.MatchOptions
+type MatchOptionsTest interface {
+	// WithMatchOptions returns a copy of the test matching the platforms as per opts; the inner tests aren't copied
+	WithMatchOptions(opts wfn.MatchOptions) LogicalTest
+}
+
+// CVSSVectors is implemented by CVE items which provide CVSS vectors along with the scores
+type CVSSVectors interface {
+	// CVSS20vector returns CVSS 2.0 vector string or empty string if unknown
+	CVSS20vector() string
+	// CVSS30vector returns CVSS 3.x vector string (e.g. CVSS:3.1/AV:N/...) or empty string if unknown
+	CVSS30vector() string
+}
+
+// CVSSSeverities is implemented by CVE items which provide qualitative severity ratings of CVSS assessments;
+// some sources provide only the rating, without the vector and the score
+type CVSSSeverities interface {
+	// CVSS20severity returns CVSS 2.0 severity rating (e.g. HIGH) or empty string if unknown
+	CVSS20severity() string
+	// CVSS30severity returns CVSS 3.x severity rating (e.g. CRITICAL) or empty string if unknown
+	CVSS30severity() string
+}
+
+// DisplayedScore is implemented by CVE items which select the single base score the way NVD displays it
+type DisplayedScore interface {
+	// DisplayedScore returns the base score NVD displays for the CVE along with its CVSS version (e.g. 3.1),
+	// empty version if the CVE wasn't scored
+	DisplayedScore() (score float64, version string)
+}
+
+// CVSSAssessment is a CVSS assessment of the vulnerability made by one of the sources
+type CVSSAssessment struct {
+	Source    string // e.g. nvd@nist.gov
+	Type      string // Primary (made by the source responsible for the data) or Secondary
+	Version   string // CVSS version, e.g. 3.1
+	Vector    string
+	BaseScore float64
+}
+
+// CVSSAssessments is implemented by CVE items which can keep all CVSS assessments of the vulnerability,
+// not just the one of each version selected for the scores, e.g. of every source of NVD CVE API 2.0
+type CVSSAssessments interface {
+	// Assessments returns the assessments in the order of the feed, nil if they weren't kept
+	Assessments() []CVSSAssessment
+}
+
+// CVSSSelection tells which of the assessments of a CVSS version the scores of the vulnerability are of and why,
+// e.g. "highest base score, tied between 2: preferred source nvd@nist.gov"
+type CVSSSelection struct {
+	Assessment CVSSAssessment
+	Reason     string
+}
+
+// CVSSSelections is implemented by CVE items which tell how the assessment of each CVSS version was selected
+// for the scores among the ones of several sources, to audit the selection
+type CVSSSelections interface {
+	// Selections returns the selections of CVSS v3 and v2 assessments, nil if they weren't kept
+	Selections() []CVSSSelection
+}
+
+// AffectedVendors is implemented by CVE items which name the affected vendors apart from configurations,
+// e.g. in CVE_data_meta affects section of NVD JSON 1.x feeds
+type AffectedVendors interface {
+	// Vendors returns the names of affected vendors
+	Vendors() []string
+}
+
+// CVEReferences is implemented by CVE items which list the references of the vulnerability
+type CVEReferences interface {
+	// References returns the URLs of advisories, patches and other references, in the order of the feed
+	References() []string
+}
+
+// CVEDescription is implemented by CVE items which describe the vulnerability in prose
+type CVEDescription interface {
+	// Description returns the description of the vulnerability, in English if available, empty string if none
+	Description() string
+}
+
+// CVEAssigner is implemented by CVE items which know the CNA (CVE numbering authority) that assigned the CVE
+type CVEAssigner interface {
+	// Assigner returns the identifier of the assigning CNA, e.g. cve@mitre.org, empty string if unknown
+	Assigner() string
+}
+
+// CVEDates is implemented by CVE items which know when they were published and last modified
+type CVEDates interface {
+	// Published returns the time CVE was published, zero time if unknown
+	Published() time.Time
+	// LastModified returns the time CVE was last modified, zero time if unknown
+	LastModified() time.Time
+}
+
+// VendorComment is a statement of a vendor on the vulnerability, e.g. of vendorComments of NVD CVE API 2.0
+type VendorComment struct {
+	Organization string
+	Comment      string
+	LastModified time.Time // zero if unknown
+}
+
+// CVEStatus is implemented by CVE items which know the status of the vulnerability in the source,
+// along with the vendor statements on it
+type CVEStatus interface {
+	// Status returns the status of the vulnerability, e.g. Analyzed, Awaiting Analysis or Rejected,
+	// empty string if unknown
+	Status() string
+	// VendorComments returns the statements of vendors on the vulnerability, in the order of the feed
+	VendorComments() []VendorComment
+}
+
+// StrictCVEDates is implemented by CVE items which report dates that are missing or don't parse,
+// unlike CVEDates accessors which return zero time for them
+type StrictCVEDates interface {
+	// PublishedStrict returns the time CVE was published or *DateError
+	PublishedStrict() (time.Time, error)
+	// LastModifiedStrict returns the time CVE was last modified or *DateError
+	LastModifiedStrict() (time.Time, error)
+}
+
+// DateError is returned by StrictCVEDates accessors when the date of CVE is missing or doesn't parse
+type DateError struct {
+	CVEID string
+	Field string // e.g. publishedDate
+	Value string
+	Err   error // parsing error, nil if the date is missing
+}
+
+// Error implements error interface
+func (e *DateError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %s is missing", e.CVEID, e.Field)
+	}
+	return fmt.Sprintf("%s: bad %s %q: %v", e.CVEID, e.Field, e.Value, e.Err)
+}
+
+// Unwrap returns the parsing error
+func (e *DateError) Unwrap() error {
+	return e.Err
+}
+
+// ContentHasher is implemented by CVE items which can hash their semantically meaningful content
+type ContentHasher interface {
+	// ContentHash returns a stable hash over configurations, CVSS, descriptions and references,
+	// two items describing the same CVE hash identically regardless of the feed serialization
+	ContentHash() string
+}
+
+// ConfigurationComparer is implemented by CVE items which can compare their configurations with the ones of
+// other items, e.g. to detect the changes of the affected set between feed versions
+type ConfigurationComparer interface {
+	// SameConfiguration returns true if other describes the same configuration trees, regardless of the order
+	// of nodes and CPE matches; items of other implementations are never the same
+	SameConfiguration(other CVEItem) bool
+}
+
+// ConfigurationDescriber is implemented by CVE items which can render their configurations as prose for human review
+type ConfigurationDescriber interface {
+	// DescribeConfiguration returns a readable summary of the configurations, one line per top level node,
+	// e.g. "affects foo bar versions >=1.0 <2.0 when running on linux linux_kernel"
+	DescribeConfiguration() string
+}
+
+// AffectedPackage is a package affected by the vulnerability, identified by package URL rather than CPE name,
+// as reported by advisory sources like OSV or GHSA
+type AffectedPackage struct {
+	// PURL is the package URL of the package without version, e.g. pkg:npm/lodash, see purl.PURL.Package
+	PURL string
+	// Ranges are the ranges of affected versions
+	Ranges []purl.Range
+	// Versions are the affected versions listed explicitly, apart from the ranges
+	Versions []string
+}
+
+// PackageAffects is implemented by CVE items which identify affected packages by package URLs,
+// apart from configurations of CPE names
+type PackageAffects interface {
+	// AffectedPackages returns the affected packages, in the order of the source
+	AffectedPackages() []AffectedPackage
+}
+
 // CVEItem is an interface that provides access to CVE data from vulnerability feed
 type CVEItem interface {
 	CVEID() string
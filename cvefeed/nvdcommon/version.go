@@ -0,0 +1,391 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdcommon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// VersionComparator compares versions of software, returns -1 if v1 < v2, 1 if v1 > v2 and 0 if v1 == v2
+type VersionComparator func(v1, v2 string) int
+
+// VersionCompareTest is implemented by logical tests which can evaluate the version bounds of their CPE matches
+// (versionStartIncluding, versionEndExcluding etc.) with another comparator than their own
+type VersionCompareTest interface {
+	// MatchPlatformCompare is like MatchPlatform, but versions are compared with cmp
+	MatchPlatformCompare(platform *wfn.Attributes, requireVersion bool, cmp VersionComparator) bool
+	// MatchVulnerableCompare is like MatchPlatformCompare, but only considers the CPEs marked as vulnerable
+	MatchVulnerableCompare(platform *wfn.Attributes, requireVersion bool, cmp VersionComparator) bool
+}
+
+// versionComparators are the comparators of VersionComparatorByName
+var versionComparators = map[string]VersionComparator{
+	"rpm":    CompareRPM,
+	"deb":    CompareDeb,
+	"dpkg":   CompareDeb,
+	"apk":    CompareAPK,
+	"semver": purl.CompareSemver,
+	"dotted": CompareDotted,
+}
+
+// VersionComparatorByName returns the comparator of versioning scheme: rpm, deb (or dpkg), apk, semver or dotted,
+// see CompareRPM, CompareDeb, CompareAPK, purl.CompareSemver and CompareDotted
+func VersionComparatorByName(name string) (VersionComparator, error) {
+	if cmp, ok := versionComparators[strings.ToLower(name)]; ok {
+		return cmp, nil
+	}
+	return nil, fmt.Errorf("unknown versioning scheme %q, expected rpm, deb, apk, semver or dotted", name)
+}
+
+// CompareRPM compares [epoch:]version[-release] as rpm does (rpmvercmp): epochs numerically (missing is 0),
+// then versions and releases alphanumeric segment by segment, with ~ sorting before anything, even the end,
+// and ^ after the end, but before anything else. Releases are only compared if both versions have one,
+// so 2.4.1 equals any release of it, e.g. 2.4.1-3.el8, which lets upstream version bounds cover the builds
+func CompareRPM(v1, v2 string) int {
+	e1, ver1, rel1 := splitEVR(v1)
+	e2, ver2, rel2 := splitEVR(v2)
+	if c := compareEpochs(e1, e2); c != 0 {
+		return c
+	}
+	if c := rpmvercmp(ver1, ver2); c != 0 {
+		return c
+	}
+	if rel1 == "" || rel2 == "" {
+		return 0
+	}
+	return rpmvercmp(rel1, rel2)
+}
+
+// CompareDeb compares [epoch:]upstream_version[-debian_revision] as dpkg does: epochs numerically (missing is 0),
+// then upstream versions and revisions alternating runs of non-digits, compared lexically with letters sorting
+// before other characters and ~ before anything, even the end, and runs of digits, compared numerically.
+// Like CompareRPM, revisions are only compared if both versions have one
+func CompareDeb(v1, v2 string) int {
+	e1, up1, rev1 := splitEVR(v1)
+	e2, up2, rev2 := splitEVR(v2)
+	if c := compareEpochs(e1, e2); c != 0 {
+		return c
+	}
+	if c := verrevcmp(up1, up2); c != 0 {
+		return c
+	}
+	if rev1 == "" || rev2 == "" {
+		return 0
+	}
+	return verrevcmp(rev1, rev2)
+}
+
+// CompareDotted compares dot separated versions component by component: numerically if both components are
+// numbers, lexically otherwise; missing components are 0, so 2.4 equals 2.4.0
+func CompareDotted(v1, v2 string) int {
+	c1, c2 := strings.Split(v1, "."), strings.Split(v2, ".")
+	for i := 0; i < len(c1) || i < len(c2); i++ {
+		x, y := "0", "0"
+		if i < len(c1) {
+			x = c1[i]
+		}
+		if i < len(c2) {
+			y = c2[i]
+		}
+		n1, err1 := strconv.ParseUint(x, 10, 64)
+		n2, err2 := strconv.ParseUint(y, 10, 64)
+		if err1 != nil || err2 != nil {
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := compareUints(n1, n2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// apkSuffixes are the ranks of Alpine version suffixes, pre-releases sort before the release (no suffix), the others after
+var apkSuffixes = map[string]int{
+	"alpha": -4, "beta": -3, "pre": -2, "rc": -1,
+	"cvs": 1, "svn": 2, "git": 3, "hg": 4, "p": 5,
+}
+
+// CompareAPK compares version[-rrevision] of Alpine packages as apk does: dot separated numbers numerically
+// (missing are less than any), then the letter following them, then _suffixes with their numbers, _alpha, _beta,
+// _pre and _rc sorting before the release, _cvs, _svn, _git, _hg and _p after it; ~hashes are disregarded.
+// Like CompareRPM, revisions are only compared if both versions have one
+func CompareAPK(v1, v2 string) int {
+	ver1, rev1 := splitAPKRevision(v1)
+	ver2, rev2 := splitAPKRevision(v2)
+	if c := compareAPKVersions(ver1, ver2); c != 0 || rev1 < 0 || rev2 < 0 {
+		return c
+	}
+	return compareUints(uint64(rev1), uint64(rev2))
+}
+
+// splitAPKRevision splits version[-rrevision], the revision is -1 if there's none
+func splitAPKRevision(v string) (string, int64) {
+	if i := strings.LastIndex(v, "-r"); i >= 0 {
+		if rev, err := strconv.ParseUint(v[i+2:], 10, 63); err == nil {
+			return v[:i], int64(rev)
+		}
+	}
+	return v, -1
+}
+
+func compareAPKVersions(v1, v2 string) int {
+	if i := strings.IndexByte(v1, '~'); i >= 0 {
+		v1 = v1[:i]
+	}
+	if i := strings.IndexByte(v2, '~'); i >= 0 {
+		v2 = v2[:i]
+	}
+	s1, s2 := strings.Split(v1, "_"), strings.Split(v2, "_")
+	n1, l1 := splitAPKLetter(s1[0])
+	n2, l2 := splitAPKLetter(s2[0])
+	c1, c2 := strings.Split(n1, "."), strings.Split(n2, ".")
+	for i := 0; i < len(c1) && i < len(c2); i++ {
+		x, err1 := strconv.ParseUint(c1[i], 10, 64)
+		y, err2 := strconv.ParseUint(c2[i], 10, 64)
+		if err1 != nil || err2 != nil {
+			if c := strings.Compare(c1[i], c2[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := compareUints(x, y); c != 0 {
+			return c
+		}
+	}
+	if c := sign(int64(len(c1) - len(c2))); c != 0 {
+		return c
+	}
+	if c := strings.Compare(l1, l2); c != 0 {
+		return c
+	}
+	for i := 1; i < len(s1) || i < len(s2); i++ {
+		r1, x := apkSuffix(s1, i)
+		r2, y := apkSuffix(s2, i)
+		if c := sign(int64(r1 - r2)); c != 0 {
+			return c
+		}
+		if c := compareUints(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// splitAPKLetter splits the letter following the numbers of Alpine version, e.g. 1.1.1k
+func splitAPKLetter(v string) (string, string) {
+	if n := len(v); n > 1 && isAlpha(v[n-1]) && isDigit(v[n-2]) {
+		return v[:n-1], v[n-1:]
+	}
+	return v, ""
+}
+
+// apkSuffix returns the rank and the number of i-th suffix of Alpine version split at underscores,
+// missing suffixes are ranked as the release
+func apkSuffix(s []string, i int) (int, uint64) {
+	if i >= len(s) {
+		return 0, 0
+	}
+	name := strings.TrimRight(s[i], "0123456789")
+	n, _ := strconv.ParseUint(s[i][len(name):], 10, 64)
+	return apkSuffixes[name], n
+}
+
+// splitEVR splits [epoch:]version[-release]: the epoch ends at the first colon, the release starts after the last hyphen
+func splitEVR(v string) (epoch, version, release string) {
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		epoch, v = v[:i], v[i+1:]
+	}
+	if i := strings.LastIndexByte(v, '-'); i >= 0 {
+		v, release = v[:i], v[i+1:]
+	}
+	return epoch, v, release
+}
+
+func compareEpochs(e1, e2 string) int {
+	n1, _ := strconv.ParseUint(strings.TrimSpace(e1), 10, 64)
+	n2, _ := strconv.ParseUint(strings.TrimSpace(e2), 10, 64)
+	return compareUints(n1, n2)
+}
+
+func compareUints(n1, n2 uint64) int {
+	switch {
+	case n1 < n2:
+		return -1
+	case n1 > n2:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func sign(n int64) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// rpmvercmp compares versions or releases as rpmvercmp of rpm library does
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	sep := func(r rune) bool {
+		c := byte(r)
+		return r < 0x80 && !isDigit(c) && !isAlpha(c) && c != '~' && c != '^'
+	}
+	for len(a) > 0 || len(b) > 0 {
+		a, b = strings.TrimLeftFunc(a, sep), strings.TrimLeftFunc(b, sep)
+
+		// ~ sorts before everything, even the end
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		// ^ sorts after the end, but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := isDigit(a[0])
+		class := isAlpha
+		if numeric {
+			class = isDigit
+		}
+		var seg1, seg2 string
+		seg1, a = span(a, class)
+		seg2, b = span(b, class)
+		if seg2 == "" {
+			// segments of different types, the numeric one is newer
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		if numeric {
+			seg1, seg2 = strings.TrimLeft(seg1, "0"), strings.TrimLeft(seg2, "0")
+			if c := sign(int64(len(seg1)) - int64(len(seg2))); c != 0 {
+				return c
+			}
+		}
+		if c := strings.Compare(seg1, seg2); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+// span splits s at the first character not of the class
+func span(s string, class func(byte) bool) (string, string) {
+	i := 0
+	for i < len(s) && class(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// debOrder is the weight of a character of a non-digit run of dpkg versions, 0 stands for the end of the run
+func debOrder(s string) int {
+	switch {
+	case s == "" || isDigit(s[0]):
+		return 0
+	case isAlpha(s[0]):
+		return int(s[0])
+	case s[0] == '~':
+		return -1
+	}
+	return int(s[0]) + 256
+}
+
+// verrevcmp compares upstream versions or revisions as verrevcmp of dpkg does
+func verrevcmp(a, b string) int {
+	for len(a) > 0 || len(b) > 0 {
+		for len(a) > 0 && !isDigit(a[0]) || len(b) > 0 && !isDigit(b[0]) {
+			if c := sign(int64(debOrder(a) - debOrder(b))); c != 0 {
+				return c
+			}
+			if len(a) > 0 {
+				a = a[1:]
+			}
+			if len(b) > 0 {
+				b = b[1:]
+			}
+		}
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		firstDiff := 0
+		for len(a) > 0 && isDigit(a[0]) && len(b) > 0 && isDigit(b[0]) {
+			if firstDiff == 0 {
+				firstDiff = sign(int64(a[0]) - int64(b[0]))
+			}
+			a, b = a[1:], b[1:]
+		}
+		if len(a) > 0 && isDigit(a[0]) {
+			return 1
+		}
+		if len(b) > 0 && isDigit(b[0]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
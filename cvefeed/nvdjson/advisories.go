@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Advisory is an entry of a custom advisory list, e.g. internal one: the versions of the product it affects.
+// The product is named either by CPE, which may not set the version if the range is bounded,
+// or by vendor and product, which stand for the application (part a) of any version; vendor may be omitted.
+// Bounds of the range are compared the same way the version ranges of the feed are, empty ones are unbounded.
+type Advisory struct {
+	ID                    string `json:"id"`
+	CPE                   string `json:"cpe,omitempty"`
+	Vendor                string `json:"vendor,omitempty"`
+	Product               string `json:"product,omitempty"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+}
+
+// CPEMatch validates the advisory and returns the cpe_match entry matching the versions affected by it
+func (a *Advisory) CPEMatch() (*jsonschema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	if strings.TrimSpace(a.ID) == "" {
+		return nil, fmt.Errorf("advisory: ID is empty")
+	}
+	if a.VersionStartIncluding != "" && a.VersionStartExcluding != "" {
+		return nil, fmt.Errorf("advisory %s: both inclusive and exclusive start of the range", a.ID)
+	}
+	if a.VersionEndIncluding != "" && a.VersionEndExcluding != "" {
+		return nil, fmt.Errorf("advisory %s: both inclusive and exclusive end of the range", a.ID)
+	}
+	bounded := a.VersionStartIncluding+a.VersionStartExcluding+a.VersionEndIncluding+a.VersionEndExcluding != ""
+
+	var attr *wfn.Attributes
+	var err error
+	switch {
+	case a.CPE != "" && (a.Vendor != "" || a.Product != ""):
+		return nil, fmt.Errorf("advisory %s: both CPE and vendor/product set", a.ID)
+	case a.CPE != "":
+		if attr, err = wfn.Parse(a.CPE); err != nil {
+			return nil, fmt.Errorf("advisory %s: %v", a.ID, err)
+		}
+		if bounded && attr.Version != wfn.Any {
+			return nil, fmt.Errorf("advisory %s: CPE %s sets the version of the range", a.ID, a.CPE)
+		}
+	case a.Product != "":
+		attr = &wfn.Attributes{Part: "a", Vendor: wfn.Any, Version: wfn.Any}
+		if a.Vendor != "" {
+			if attr.Vendor, err = wfn.WFNize(strings.ToLower(a.Vendor)); err != nil {
+				return nil, fmt.Errorf("advisory %s: bad vendor: %v", a.ID, err)
+			}
+		}
+		if attr.Product, err = wfn.WFNize(strings.ToLower(a.Product)); err != nil {
+			return nil, fmt.Errorf("advisory %s: bad product: %v", a.ID, err)
+		}
+	default:
+		return nil, fmt.Errorf("advisory %s: neither CPE nor product set", a.ID)
+	}
+	return &jsonschema.NVDCVEFeedJSON10DefCPEMatch{
+		Cpe23Uri:              attr.BindToFmtString(),
+		Vulnerable:            true,
+		VersionStartIncluding: a.VersionStartIncluding,
+		VersionStartExcluding: a.VersionStartExcluding,
+		VersionEndIncluding:   a.VersionEndIncluding,
+		VersionEndExcluding:   a.VersionEndExcluding,
+	}, nil
+}
+
+// NewAdvisoryItem returns the vulnerability of the given ID matching platforms any of cpe_match entries match,
+// e.g. of the advisories sharing the ID (see Advisory.CPEMatch); it's matched the same way the feed entries are
+func NewAdvisoryItem(id string, matches []*jsonschema.NVDCVEFeedJSON10DefCPEMatch) nvdcommon.CVEItem {
+	return newCveItem(&jsonschema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &jsonschema.CVEJSON40{CVEDataMeta: &jsonschema.CVEJSON40CVEDataMeta{ID: id}},
+		Configurations: &jsonschema.NVDCVEFeedJSON10DefConfigurations{
+			Nodes: []*jsonschema.NVDCVEFeedJSON10DefNode{{Operator: "OR", CPEMatch: matches}},
+		},
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import "testing"
+
+func TestAdvisoryCPEMatch(t *testing.T) {
+	a := &Advisory{ID: "ACME-1", Vendor: "ACME", Product: "Widget", VersionStartIncluding: "1.0", VersionEndExcluding: "2.0"}
+	m, err := a.CPEMatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"; m.Cpe23Uri != expected || !m.Vulnerable {
+		t.Errorf("expected vulnerable %s, got %+v", expected, m)
+	}
+	if m.VersionStartIncluding != "1.0" || m.VersionEndExcluding != "2.0" {
+		t.Errorf("expected range [1.0, 2.0), got %+v", m)
+	}
+
+	for _, a := range []*Advisory{
+		{Product: "widget"},
+		{ID: "ACME-1"},
+		{ID: "ACME-1", Vendor: "acme"},
+		{ID: "ACME-1", CPE: "cpe:/a:acme:widget", Product: "widget"},
+		{ID: "ACME-1", CPE: "acme:widget"},
+		{ID: "ACME-1", CPE: "cpe:/a:acme:widget:1.0", VersionEndExcluding: "2.0"},
+		{ID: "ACME-1", Product: "widget", VersionStartIncluding: "1.0", VersionStartExcluding: "1.0"},
+		{ID: "ACME-1", Product: "widget", VersionEndIncluding: "2.0", VersionEndExcluding: "2.0"},
+	} {
+		if _, err := a.CPEMatch(); err == nil {
+			t.Errorf("%+v: expected an error", a)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestAssigner(t *testing.T) {
+	feed := `{"CVE_Items":[
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001","ASSIGNER":"security-advisories@github.com"}},"configurations":{"nodes":[]}},
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0002"}},"configurations":{"nodes":[]}}]}`
+	items, err := Parse(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"security-advisories@github.com", ""} {
+		if assigner := items[i].(nvdcommon.CVEAssigner).Assigner(); assigner != expected {
+			t.Errorf("%s: expected assigner %q, got %q", items[i].CVEID(), expected, assigner)
+		}
+	}
+}
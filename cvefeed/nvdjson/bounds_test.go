@@ -0,0 +1,66 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestSplitVersionRange(t *testing.T) {
+	cases := map[string]struct {
+		cpeMatch   string
+		vulnerable []string
+		safe       []string
+	}{
+		"split range": {
+			`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0"},
+			 {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0"}`,
+			[]string{"1.0", "1.9"},
+			[]string{"0.9", "2.0", "2.5"},
+		},
+		"disjoint ranges": {
+			`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionStartExcluding":"5.0"},
+			 {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndIncluding":"2.0"}`,
+			[]string{"1.0", "2.0", "5.1"},
+			[]string{"3.0", "5.0"},
+		},
+		"different products": {
+			`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0"},
+			 {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0"}`,
+			[]string{"1.0", "2.5"},
+			[]string{"0.9"},
+		},
+	}
+	for name, c := range cases {
+		feed := `{"CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"}},"configurations":{"nodes":[` +
+			`{"operator":"OR","cpe_match":[` + c.cpeMatch + `]}]}}]}`
+		items, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		n := items[0].Config()[0]
+		for expected, versions := range map[bool][]string{true: c.vulnerable, false: c.safe} {
+			for _, version := range versions {
+				cpe := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: strings.Replace(version, ".", "\\.", -1)}
+				if got := n.MatchPlatform(cpe, false); got != expected {
+					t.Errorf("%s: version %s: expected match %t, got %t", name, version, expected, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// ParseByIDs is like Parse, but only the CVEs of ids are retained, in the order of the feed.
+// The feed is decoded one CVE at a time, so the memory taken is proportional to the CVEs retained rather than
+// to the feed, and decoding stops as soon as all the CVEs of ids are found; the IDs not found are left out.
+func ParseByIDs(in io.Reader, ids map[string]bool) ([]nvdcommon.CVEItem, error) {
+	wanted := 0
+	for _, ok := range ids {
+		if ok {
+			wanted++
+		}
+	}
+	if wanted == 0 {
+		return nil, nil
+	}
+	p := &idsParser{dec: json.NewDecoder(in), ids: ids, wanted: wanted, found: map[string]bool{}}
+	if err := p.delim('{'); err != nil {
+		return nil, err
+	}
+	for p.dec.More() && !p.done() {
+		key, err := p.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "CVE_Items":
+			err = p.array(p.item)
+		case "vulnerabilities":
+			err = p.array(p.vulnerability)
+		default:
+			var skipped json.RawMessage
+			err = p.dec.Decode(&skipped)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", key, err)
+		}
+	}
+	return p.items, nil
+}
+
+// idsParser decodes feeds element by element, retaining the CVEs of ids
+type idsParser struct {
+	dec    *json.Decoder
+	ids    map[string]bool
+	wanted int             // number of ids to find
+	found  map[string]bool // ids found so far
+	items  []nvdcommon.CVEItem
+}
+
+func (p *idsParser) done() bool {
+	return len(p.found) == p.wanted
+}
+
+// delim reads the delimiter d
+func (p *idsParser) delim(d json.Delim) error {
+	t, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return fmt.Errorf("expected %v, got %v", d, t)
+	}
+	return nil
+}
+
+// array decodes the elements of an array with decode until all the CVEs are found
+func (p *idsParser) array(decode func() error) error {
+	if err := p.delim('['); err != nil {
+		return err
+	}
+	for p.dec.More() {
+		if err := decode(); err != nil {
+			return err
+		}
+		if p.done() {
+			return nil // the rest of the feed isn't needed
+		}
+	}
+	return p.delim(']')
+}
+
+func (p *idsParser) keep(id string) bool {
+	if !p.ids[id] || p.found[id] {
+		return false
+	}
+	p.found[id] = true
+	return true
+}
+
+// item decodes CVE item of NVD JSON 1.x feed
+func (p *idsParser) item() error {
+	var item jsonschema.NVDCVEFeedJSON10DefCVEItem
+	if err := p.dec.Decode(&item); err != nil {
+		return err
+	}
+	if item.CVE == nil || item.CVE.CVEDataMeta == nil || item.Configurations == nil {
+		return nil
+	}
+	if p.keep(item.CVE.CVEDataMeta.ID) {
+		p.items = append(p.items, newCveItem(&item))
+	}
+	return nil
+}
+
+// vulnerability decodes vulnerability of NVD CVE API 2.0 response
+func (p *idsParser) vulnerability() error {
+	var v jsonschema.NVDCVE20Vulnerability
+	if err := p.dec.Decode(&v); err != nil {
+		return err
+	}
+	if v.CVE == nil || !p.keep(v.CVE.ID) {
+		return nil
+	}
+	items, err := traverse20([]*jsonschema.NVDCVE20Vulnerability{&v}, ParseOptions{})
+	if err != nil {
+		return err
+	}
+	p.items = append(p.items, items...)
+	return nil
+}
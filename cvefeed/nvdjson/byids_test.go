@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const byIDsFeed = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[
+	{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"}},"configurations":{"nodes":[]}},
+	{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0002"}},"configurations":{"nodes":[]}},
+	{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0003"}},"configurations":{"nodes":[]}}
+]}`
+
+func TestParseByIDs(t *testing.T) {
+	ids := func(feed string, wanted ...string) []string {
+		t.Helper()
+		set := map[string]bool{}
+		for _, id := range wanted {
+			set[id] = true
+		}
+		items, err := ParseByIDs(strings.NewReader(feed), set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		for _, item := range items {
+			found = append(found, item.CVEID())
+		}
+		return found
+	}
+	cases := []struct {
+		name     string
+		feed     string
+		wanted   []string
+		expected []string
+	}{
+		{"1.1", byIDsFeed, []string{"CVE-2020-0003", "CVE-2020-0001"}, []string{"CVE-2020-0001", "CVE-2020-0003"}},
+		{"not found", byIDsFeed, []string{"CVE-2020-0002", "CVE-2021-0001"}, []string{"CVE-2020-0002"}},
+		{"none", byIDsFeed, nil, nil},
+		{"2.0", hashFeed20, []string{"CVE-2020-0001"}, []string{"CVE-2020-0001"}},
+		// decoding stops once all are found, the broken rest of the feed isn't read
+		{"early stop", strings.TrimSuffix(byIDsFeed, "\n]}") + ",{broken", []string{"CVE-2020-0002"}, []string{"CVE-2020-0002"}},
+	}
+	for _, c := range cases {
+		if found := ids(c.feed, c.wanted...); !reflect.DeepEqual(found, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, found)
+		}
+	}
+	if _, err := ParseByIDs(strings.NewReader(strings.TrimSuffix(byIDsFeed, "\n]}")+",{broken"), map[string]bool{"CVE-2021-0001": true}); err == nil {
+		t.Error("expected an error for the broken feed")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// CanonicalNodes returns a deep copy of the configuration nodes in canonical form, which doesn't depend on the order
+// of the feed: operators are upper case, CPE matches are sorted within each node and nodes are sorted at every level
+// of the tree; nil nodes and matches are dropped. The order is the one ContentHash hashes the nodes in.
+func CanonicalNodes(nodes []*jsonschema.NVDCVEFeedJSON10DefNode) []*jsonschema.NVDCVEFeedJSON10DefNode {
+	type keyed struct {
+		key  string
+		node *jsonschema.NVDCVEFeedJSON10DefNode
+	}
+	var canonical []keyed
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		cn := &jsonschema.NVDCVEFeedJSON10DefNode{
+			Operator: strings.ToUpper(n.Operator),
+			Negate:   n.Negate,
+			Children: CanonicalNodes(n.Children),
+		}
+		for _, m := range n.CPEMatch {
+			if m != nil {
+				cm := *m
+				cm.CPEName = append([]*jsonschema.NVDCVEFeedJSON10DefCPEName(nil), m.CPEName...)
+				cn.CPEMatch = append(cn.CPEMatch, &cm)
+			}
+		}
+		sort.SliceStable(cn.CPEMatch, func(i, j int) bool {
+			return mustMarshal(contentOf(cn.CPEMatch[i])) < mustMarshal(contentOf(cn.CPEMatch[j]))
+		})
+		canonical = append(canonical, keyed{canonicalNodes([]*jsonschema.NVDCVEFeedJSON10DefNode{n})[0], cn})
+	}
+	sort.SliceStable(canonical, func(i, j int) bool { return canonical[i].key < canonical[j].key })
+	var out []*jsonschema.NVDCVEFeedJSON10DefNode
+	for _, k := range canonical {
+		out = append(out, k.node)
+	}
+	return out
+}
+
+// EqualNodes returns true if a and b are the same configuration trees regardless of the order of nodes and
+// CPE matches; only the fields affecting matching are compared, the ones ContentHash hashes
+func EqualNodes(a, b []*jsonschema.NVDCVEFeedJSON10DefNode) bool {
+	ca, cb := canonicalNodes(a), canonicalNodes(b)
+	if len(ca) != len(cb) {
+		return false
+	}
+	for i := range ca {
+		if ca[i] != cb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SameConfiguration is a part of nvdcommon.ConfigurationComparer interface implementation
+func (i *cveItem) SameConfiguration(other nvdcommon.CVEItem) bool {
+	o, ok := other.(*cveItem)
+	if !ok {
+		return false
+	}
+	return EqualNodes(i.nodes(), o.nodes())
+}
+
+// nodes returns the configuration nodes of the item as they are in the feed
+func (i *cveItem) nodes() []*jsonschema.NVDCVEFeedJSON10DefNode {
+	if i == nil || i.cveItem == nil || i.cveItem.Configurations == nil {
+		return nil
+	}
+	return i.cveItem.Configurations.Nodes
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestSameConfiguration(t *testing.T) {
+	parse := func(feed string) nvdcommon.CVEItem {
+		items, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items[0]
+	}
+	item := parse(hashFeed11)
+	c, ok := item.(nvdcommon.ConfigurationComparer)
+	if !ok {
+		t.Fatal("CVE item doesn't implement nvdcommon.ConfigurationComparer")
+	}
+	if !c.SameConfiguration(parse(hashFeed20)) {
+		t.Error("the same configurations of 1.1 and 2.0 feeds in different order are expected to be the same")
+	}
+	changed := strings.Replace(hashFeed11, `"versionEndExcluding":"1.2"`, `"versionEndExcluding":"1.3"`, 1)
+	if c.SameConfiguration(parse(changed)) {
+		t.Error("different version ranges are expected to differ")
+	}
+	// descriptions don't matter
+	changed = strings.Replace(hashFeed11, "Something bad", "Something worse", 1)
+	if !c.SameConfiguration(parse(changed)) {
+		t.Error("configurations of CVEs with different descriptions are expected to be the same")
+	}
+}
+
+func TestCanonicalNodes(t *testing.T) {
+	match := func(uri string) *jsonschema.NVDCVEFeedJSON10DefCPEMatch {
+		return &jsonschema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: uri, Vulnerable: true}
+	}
+	nodes := []*jsonschema.NVDCVEFeedJSON10DefNode{
+		{Operator: "or", CPEMatch: []*jsonschema.NVDCVEFeedJSON10DefCPEMatch{
+			match("cpe:2.3:a:foo:qux:*:*:*:*:*:*:*:*"), nil, match("cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*")}},
+		nil,
+		{Operator: "AND", Children: []*jsonschema.NVDCVEFeedJSON10DefNode{
+			{Operator: "OR", CPEMatch: []*jsonschema.NVDCVEFeedJSON10DefCPEMatch{match("cpe:2.3:o:foo:os:*:*:*:*:*:*:*:*")}},
+			{Operator: "OR", CPEMatch: []*jsonschema.NVDCVEFeedJSON10DefCPEMatch{match("cpe:2.3:a:foo:app:*:*:*:*:*:*:*:*")}},
+		}},
+	}
+	canonical := CanonicalNodes(nodes)
+	if len(canonical) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(canonical))
+	}
+	if n := canonical[0]; n.Operator != "AND" || n.Children[0].CPEMatch[0].Cpe23Uri != "cpe:2.3:a:foo:app:*:*:*:*:*:*:*:*" {
+		t.Errorf("unexpected first node: %+v", n)
+	}
+	if n := canonical[1]; n.Operator != "OR" || len(n.CPEMatch) != 2 || n.CPEMatch[0].Cpe23Uri != "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*" {
+		t.Errorf("unexpected second node: %+v", n)
+	}
+	if nodes[0].Operator != "or" || nodes[0].CPEMatch[0].Cpe23Uri != "cpe:2.3:a:foo:qux:*:*:*:*:*:*:*:*" {
+		t.Error("the original nodes were modified")
+	}
+	if !EqualNodes(nodes, canonical) || !EqualNodes(canonical, CanonicalNodes(canonical)) {
+		t.Error("canonical nodes are expected to equal the original ones")
+	}
+	if EqualNodes(nodes, canonical[:1]) {
+		t.Error("different trees are expected to differ")
+	}
+}
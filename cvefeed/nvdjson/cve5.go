@@ -0,0 +1,370 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	// secondary is the type of assessments made by other sources than the one responsible for the data
+	secondary = "Secondary"
+	// rejected is the state of CVE records withdrawn by their CNA
+	rejected = "REJECTED"
+)
+
+// ConvertRecord converts CVE JSON 5.x record (e.g. of cvelistV5 repository or CVE Services API) decoded elsewhere
+// to CVE item; rejected records convert to nil
+func ConvertRecord(rec *jsonschema.CVE5Record) (nvdcommon.CVEItem, error) {
+	items, err := traverse5(rec, ParseOptions{})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func traverse5(rec *jsonschema.CVE5Record, opts ParseOptions) ([]nvdcommon.CVEItem, error) {
+	if rec.CVEMetadata == nil || rec.CVEMetadata.CVEID == "" {
+		return nil, fmt.Errorf("CVE JSON 5.x record has no cveId")
+	}
+	if strings.EqualFold(rec.CVEMetadata.State, rejected) {
+		return nil, nil
+	}
+	metrics := metrics5(rec.Containers)
+	converted, selections := convert5(rec, metrics, opts)
+	item := &cveItem{cveItem: converted, status: rec.CVEMetadata.State}
+	item.configNodes = configNodes(item.cveItem)
+	if opts.KeepAssessments {
+		item.assessments = append(assessments20(metrics), assessments5V40(rec.Containers)...)
+		item.selections = selections
+	}
+	if transformed, ok := opts.transform(item); ok {
+		return []nvdcommon.CVEItem{transformed}, nil
+	}
+	return nil, nil
+}
+
+// containers5 returns the CNA container followed by the ADP ones
+func containers5(c *jsonschema.CVE5Containers) []*jsonschema.CVE5Container {
+	if c == nil {
+		return nil
+	}
+	var containers []*jsonschema.CVE5Container
+	if c.CNA != nil {
+		containers = append(containers, c.CNA)
+	}
+	for _, adp := range c.ADP {
+		if adp != nil {
+			containers = append(containers, adp)
+		}
+	}
+	return containers
+}
+
+// provider5 returns the short name of the organization which provided the container, its ID if it has none
+func provider5(c *jsonschema.CVE5Container) string {
+	if c.ProviderMetadata == nil {
+		return ""
+	}
+	if c.ProviderMetadata.ShortName != "" {
+		return c.ProviderMetadata.ShortName
+	}
+	return c.ProviderMetadata.OrgID
+}
+
+// convert5 converts CVE JSON 5.x record to the NVD 1.0 JSON feed item, the descriptions are the CNA's,
+// the rest is gathered from the CNA and the ADP containers. The assessments of each CVSS version are selected
+// out of metrics as per opts, the selections are returned along
+func convert5(rec *jsonschema.CVE5Record, metrics *jsonschema.NVDCVE20Metrics, opts ParseOptions) (*jsonschema.NVDCVEFeedJSON10DefCVEItem, []nvdcommon.CVSSSelection) {
+	meta := rec.CVEMetadata
+	assigner := meta.AssignerShortName
+	if assigner == "" {
+		assigner = meta.AssignerOrgID
+	}
+	item := &jsonschema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &jsonschema.CVEJSON40{
+			CVEDataMeta: &jsonschema.CVEJSON40CVEDataMeta{
+				ID:       meta.CVEID,
+				ASSIGNER: assigner,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &jsonschema.CVEJSON40Description{},
+			Problemtype: &jsonschema.CVEJSON40Problemtype{},
+			References:  &jsonschema.CVEJSON40References{},
+		},
+		Configurations: &jsonschema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+		},
+		Impact:           &jsonschema.NVDCVEFeedJSON10DefImpact{},
+		LastModifiedDate: convertTime5(meta.DateUpdated),
+		PublishedDate:    convertTime5(meta.DatePublished),
+	}
+
+	if rec.Containers != nil && rec.Containers.CNA != nil {
+		for _, d := range rec.Containers.CNA.Descriptions {
+			if d != nil {
+				item.CVE.Description.DescriptionData = append(item.CVE.Description.DescriptionData,
+					&jsonschema.CVEJSON40LangString{Lang: d.Lang, Value: d.Value})
+			}
+		}
+	}
+
+	node := &jsonschema.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	seenURLs := map[string]bool{}
+	for _, c := range containers5(rec.Containers) {
+		for _, pt := range c.ProblemTypes {
+			if pt == nil {
+				continue
+			}
+			ptd := &jsonschema.CVEJSON40ProblemtypeProblemtypeData{}
+			for _, d := range pt.Descriptions {
+				if d == nil {
+					continue
+				}
+				value := d.CWEID
+				if value == "" {
+					value = d.Description
+				}
+				ptd.Description = append(ptd.Description, &jsonschema.CVEJSON40LangString{Lang: d.Lang, Value: value})
+			}
+			if len(ptd.Description) != 0 {
+				item.CVE.Problemtype.ProblemtypeData = append(item.CVE.Problemtype.ProblemtypeData, ptd)
+			}
+		}
+
+		for _, r := range c.References {
+			if r == nil || r.URL == "" || seenURLs[r.URL] {
+				continue
+			}
+			seenURLs[r.URL] = true
+			name := r.Name
+			if name == "" {
+				name = r.URL
+			}
+			item.CVE.References.ReferenceData = append(item.CVE.References.ReferenceData, &jsonschema.CVEJSON40Reference{
+				Name:      name,
+				Refsource: provider5(c),
+				Tags:      r.Tags,
+				URL:       r.URL,
+			})
+		}
+
+		for _, a := range c.Affected {
+			if a != nil {
+				node.CPEMatch = append(node.CPEMatch, cpeMatches5(a)...)
+			}
+		}
+	}
+	if len(node.CPEMatch) != 0 {
+		item.Configurations.Nodes = []*jsonschema.NVDCVEFeedJSON10DefNode{node}
+	}
+
+	var selections []nvdcommon.CVSSSelection
+	if m, sel := selectCVSSV3(metrics.CVSSMetricV31, opts); m != nil {
+		item.Impact.BaseMetricV3 = convertCVSSV3(m)
+		selections = append(selections, sel)
+	} else if m, sel := selectCVSSV3(metrics.CVSSMetricV30, opts); m != nil {
+		item.Impact.BaseMetricV3 = convertCVSSV3(m)
+		selections = append(selections, sel)
+	}
+	if m, sel := selectCVSSV2(metrics.CVSSMetricV2, opts); m != nil {
+		selections = append(selections, sel)
+		item.Impact.BaseMetricV2 = &jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2:   m.CVSSData,
+			Severity: m.BaseSeverity,
+		}
+	}
+
+	return item, selections
+}
+
+// metrics5 gathers CVSS assessments of the containers as NVD 2.0 metrics, so they're selected the same way:
+// the CNA's ones are primary (the CNA is responsible for the record), the ADPs' ones secondary
+func metrics5(containers *jsonschema.CVE5Containers) *jsonschema.NVDCVE20Metrics {
+	metrics := &jsonschema.NVDCVE20Metrics{}
+	for _, c := range containers5(containers) {
+		typ := secondary
+		if c == containers.CNA {
+			typ = primary
+		}
+		for _, m := range c.Metrics {
+			if m == nil {
+				continue
+			}
+			if m.CVSSV31 != nil {
+				metrics.CVSSMetricV31 = append(metrics.CVSSMetricV31, &jsonschema.NVDCVE20CVSSV3{CVSSData: m.CVSSV31, Source: provider5(c), Type: typ})
+			}
+			if m.CVSSV30 != nil {
+				metrics.CVSSMetricV30 = append(metrics.CVSSMetricV30, &jsonschema.NVDCVE20CVSSV3{CVSSData: m.CVSSV30, Source: provider5(c), Type: typ})
+			}
+			if m.CVSSV20 != nil {
+				metrics.CVSSMetricV2 = append(metrics.CVSSMetricV2, &jsonschema.NVDCVE20CVSSV2{CVSSData: m.CVSSV20, Source: provider5(c), Type: typ})
+			}
+		}
+	}
+	return metrics
+}
+
+// assessments5V40 returns CVSS v4.0 assessments of the containers; NVD 1.0 items have no place for them,
+// so they're only kept as assessments
+func assessments5V40(containers *jsonschema.CVE5Containers) []nvdcommon.CVSSAssessment {
+	var assessments []nvdcommon.CVSSAssessment
+	for _, c := range containers5(containers) {
+		typ := secondary
+		if c == containers.CNA {
+			typ = primary
+		}
+		for _, m := range c.Metrics {
+			if m != nil && m.CVSSV40 != nil {
+				assessments = append(assessments, nvdcommon.CVSSAssessment{
+					Source:    provider5(c),
+					Type:      typ,
+					Version:   m.CVSSV40.Version,
+					Vector:    m.CVSSV40.VectorString,
+					BaseScore: m.CVSSV40.BaseScore,
+				})
+			}
+		}
+	}
+	return assessments
+}
+
+// cpeMatches5 converts the affected product to CPE matches: of its CPE names if it lists any, of a:vendor:product
+// otherwise. Every affected version or range of versions is a match, git commits are dropped; products without
+// affected versions listed match all their versions if they're affected by default. Unaffected versions
+// aren't expressed, so the matches may be broader than the record
+func cpeMatches5(a *jsonschema.CVE5Affected) []*jsonschema.NVDCVEFeedJSON10DefCPEMatch {
+	var names []*wfn.Attributes
+	for _, cpe := range a.CPEs {
+		if attrs, err := wfn.Parse(cpe); err == nil {
+			names = append(names, attrs)
+		}
+	}
+	if len(names) == 0 {
+		if attrs := attrs5(a.Vendor, a.Product); attrs != nil {
+			names = append(names, attrs)
+		}
+	}
+
+	var matches []*jsonschema.NVDCVEFeedJSON10DefCPEMatch
+	if len(a.Versions) == 0 && len(a.CPEs) != 0 {
+		for _, name := range names {
+			matches = append(matches, cpeMatch5(*name, "", nil))
+		}
+		return matches
+	}
+	for _, name := range names {
+		affected := false
+		for _, v := range a.Versions {
+			if v == nil || v.Status != "affected" || v.VersionType == "git" {
+				continue
+			}
+			affected = true
+			if v.LessThan == "" && v.LessThanOrEqual == "" {
+				matches = append(matches, cpeMatch5(*name, v.Version, nil))
+				continue
+			}
+			matches = append(matches, cpeMatch5(*name, wfn.Any, func(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) {
+				if !unbounded5(v.Version) {
+					m.VersionStartIncluding = v.Version
+				}
+				if v.LessThan != "" && !unbounded5(v.LessThan) {
+					m.VersionEndExcluding = v.LessThan
+				} else if v.LessThanOrEqual != "" && !unbounded5(v.LessThanOrEqual) {
+					m.VersionEndIncluding = v.LessThanOrEqual
+				}
+			}))
+		}
+		if !affected && a.DefaultStatus == "affected" {
+			matches = append(matches, cpeMatch5(*name, wfn.Any, nil))
+		}
+	}
+	return matches
+}
+
+// attrs5 returns the CPE name a:vendor:product, nil if the product is missing
+func attrs5(vendor, product string) *wfn.Attributes {
+	if unknown5(product) {
+		return nil
+	}
+	attrs := &wfn.Attributes{Part: "a", Vendor: wfn.Any, Product: wfn.Any}
+	var err error
+	if attrs.Product, err = wfn.WFNize(strings.ToLower(product)); err != nil {
+		return nil
+	}
+	if !unknown5(vendor) {
+		if attrs.Vendor, err = wfn.WFNize(strings.ToLower(vendor)); err != nil {
+			return nil
+		}
+	}
+	return attrs
+}
+
+// cpeMatch5 returns the match of the CPE name with the version set, unless it's empty; set tunes the match further
+func cpeMatch5(name wfn.Attributes, version string, set func(*jsonschema.NVDCVEFeedJSON10DefCPEMatch)) *jsonschema.NVDCVEFeedJSON10DefCPEMatch {
+	if version == "*" {
+		version = wfn.Any
+	}
+	if version != "" && version != wfn.Any {
+		v, err := wfn.WFNize(version)
+		if err != nil {
+			v = wfn.Any
+		}
+		version = v
+	}
+	if version != "" {
+		name.Version = version
+	}
+	m := &jsonschema.NVDCVEFeedJSON10DefCPEMatch{
+		Cpe23Uri:   name.BindToFmtString(),
+		Vulnerable: true,
+	}
+	if set != nil {
+		set(m)
+	}
+	return m
+}
+
+// unknown5 tells if vendor or product name is missing, CNAs put n/a there
+func unknown5(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.EqualFold(s, "n/a")
+}
+
+// unbounded5 tells if the version doesn't bound a range, e.g. "0" of "versions from 0 less than 1.2"
+func unbounded5(v string) bool {
+	return v == "" || v == "0" || v == "*"
+}
+
+// timeLayouts5 are the layouts of timestamps in CVE JSON 5.x records, the time zone is optional there
+var timeLayouts5 = []string{time.RFC3339Nano, timeLayout20, timeLayout20[:strings.LastIndexByte(timeLayout20, '.')]}
+
+// convertTime5 converts CVE JSON 5.x timestamp into NVD 1.0 one; unparseable timestamps are passed as is
+func convertTime5(s string) string {
+	for _, layout := range timeLayouts5 {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(nvdcommon.TimeLayout)
+		}
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+var cve5Record = `{
+  "dataType": "CVE_RECORD",
+  "dataVersion": "5.1",
+  "cveMetadata": {
+    "cveId": "CVE-2021-44228",
+    "assignerOrgId": "f0158376-9dc2-43b6-827c-5f631a4d8d09",
+    "assignerShortName": "apache",
+    "state": "PUBLISHED",
+    "datePublished": "2021-12-10T00:00:00.000Z",
+    "dateUpdated": "2024-08-04T04:17:24.696Z"
+  },
+  "containers": {
+    "cna": {
+      "providerMetadata": {"orgId": "f0158376-9dc2-43b6-827c-5f631a4d8d09", "shortName": "apache"},
+      "descriptions": [{"lang": "en", "value": "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP."}],
+      "affected": [
+        {
+          "vendor": "Apache Software Foundation",
+          "product": "Apache Log4j2",
+          "versions": [
+            {"status": "affected", "version": "2.0-beta9", "lessThan": "2.3.1", "versionType": "custom"},
+            {"status": "affected", "version": "2.4", "lessThan": "2.12.2", "versionType": "custom"},
+            {"status": "unaffected", "version": "2.16.0"},
+            {"status": "affected", "version": "a1b2c3", "versionType": "git"}
+          ]
+        },
+        {"vendor": "n/a", "product": "n/a", "versions": [{"status": "affected", "version": "n/a"}]}
+      ],
+      "problemTypes": [{"descriptions": [{"lang": "en", "cweId": "CWE-502", "description": "CWE-502 Deserialization of Untrusted Data", "type": "CWE"}]}],
+      "metrics": [{"cvssV3_1": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}}],
+      "references": [{"url": "https://logging.apache.org/log4j/2.x/security.html", "tags": ["vendor-advisory"]}]
+    },
+    "adp": [
+      {
+        "providerMetadata": {"orgId": "134c704f-9b21-4f2e-91b3-4a467353bcc0", "shortName": "CISA-ADP"},
+        "affected": [{"cpes": ["cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*"], "vendor": "apache", "product": "log4j", "defaultStatus": "unknown",
+          "versions": [{"status": "affected", "version": "2.13.0", "lessThanOrEqual": "2.15.0", "versionType": "custom"}]}],
+        "metrics": [{"cvssV3_1": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:C/C:H/I:H/A:H", "baseScore": 9.0, "baseSeverity": "CRITICAL"}},
+          {"cvssV4_0": {"version": "4.0", "vectorString": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}}],
+        "references": [{"url": "https://logging.apache.org/log4j/2.x/security.html"}, {"url": "https://www.cisa.gov/known-exploited-vulnerabilities-catalog"}]
+      }
+    ]
+  }
+}`
+
+func TestParseCVE5(t *testing.T) {
+	items, err := ParseWithOptions(strings.NewReader(cve5Record), ParseOptions{KeepAssessments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if id := item.CVEID(); id != "CVE-2021-44228" {
+		t.Errorf("unexpected ID %q", id)
+	}
+	if cwes := item.ProblemTypes(); !reflect.DeepEqual(cwes, []string{"CWE-502"}) {
+		t.Errorf("unexpected problem types %v", cwes)
+	}
+	// the CNA's assessment takes precedence
+	if score := item.CVSS30base(); score != 10.0 {
+		t.Errorf("expected CVSS v3 base score 10.0, got %v", score)
+	}
+
+	ci := item.(*cveItem).cveItem
+	if ci.PublishedDate != "2021-12-10T00:00Z" || ci.LastModifiedDate != "2024-08-04T04:17Z" {
+		t.Errorf("unexpected dates %q, %q", ci.PublishedDate, ci.LastModifiedDate)
+	}
+	if n := len(ci.CVE.References.ReferenceData); n != 2 {
+		t.Errorf("expected 2 distinct references, got %d", n)
+	}
+
+	type match struct{ cpe, startIncl, endExcl, endIncl string }
+	var got []match
+	for _, n := range ci.Configurations.Nodes {
+		for _, m := range n.CPEMatch {
+			got = append(got, match{m.Cpe23Uri, m.VersionStartIncluding, m.VersionEndExcluding, m.VersionEndIncluding})
+		}
+	}
+	want := []match{
+		{"cpe:2.3:a:apache_software_foundation:apache_log4j2:*:*:*:*:*:*:*:*", "2.0-beta9", "2.3.1", ""},
+		{"cpe:2.3:a:apache_software_foundation:apache_log4j2:*:*:*:*:*:*:*:*", "2.4", "2.12.2", ""},
+		{"cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "2.13.0", "", "2.15.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected matches:\nwant %v\ngot  %v", want, got)
+	}
+
+	assessments := item.(nvdcommon.CVSSAssessments).Assessments()
+	if len(assessments) != 3 {
+		t.Fatalf("expected 3 assessments, got %v", assessments)
+	}
+	if a := assessments[0]; a.Source != "apache" || a.Type != "Primary" || a.BaseScore != 10.0 {
+		t.Errorf("unexpected CNA assessment %+v", a)
+	}
+	if a := assessments[2]; a.Source != "CISA-ADP" || a.Version != "4.0" {
+		t.Errorf("unexpected CVSS v4.0 assessment %+v", a)
+	}
+}
+
+func TestParseCVE5Rejected(t *testing.T) {
+	rec := `{"dataType":"CVE_RECORD","dataVersion":"5.1","cveMetadata":{"cveId":"CVE-2023-0001","state":"REJECTED"},` +
+		`"containers":{"cna":{"rejectedReasons":[{"lang":"en","value":"duplicate"}]}}}`
+	items, err := Parse(strings.NewReader(rec))
+	if err != nil || len(items) != 0 {
+		t.Errorf("expected rejected record to be skipped, got %v, %v", items, err)
+	}
+
+	if _, err := Parse(strings.NewReader(`{"containers":{}}`)); err == nil {
+		t.Error("expected error on the record without cveId")
+	}
+}
+
+func TestConvertRecord(t *testing.T) {
+	item, err := ConvertRecord(&jsonschema.CVE5Record{
+		CVEMetadata: &jsonschema.CVE5Metadata{CVEID: "CVE-2023-0002", State: "PUBLISHED"},
+		Containers: &jsonschema.CVE5Containers{CNA: &jsonschema.CVE5Container{
+			Affected: []*jsonschema.CVE5Affected{{Vendor: "acme", Product: "widget", DefaultStatus: "affected"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := item.(*cveItem).cveItem.Configurations.Nodes
+	if len(nodes) != 1 || len(nodes[0].CPEMatch) != 1 || nodes[0].CPEMatch[0].Cpe23Uri != "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*" {
+		t.Errorf("expected all versions of acme:widget to match, got %v", nodes)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestStrictDates(t *testing.T) {
+	feed := `{"CVE_Items":[
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"}},"configurations":{"nodes":[]},"publishedDate":"2020-01-02T03:04Z","lastModifiedDate":"2020-02-03T04:05:06Z"},
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0002"}},"configurations":{"nodes":[]},"publishedDate":"2020-13-01T00:00Z"}]}`
+	items, err := Parse(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := items[0].(nvdcommon.StrictCVEDates)
+	if published, err := good.PublishedStrict(); err != nil || !published.Equal(time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC)) {
+		t.Errorf("unexpected published date %v, %v", published, err)
+	}
+	if modified, err := good.LastModifiedStrict(); err != nil || !modified.Equal(time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)) {
+		t.Errorf("unexpected last modified date %v, %v", modified, err)
+	}
+
+	bad := items[1].(nvdcommon.StrictCVEDates)
+	_, err = bad.PublishedStrict()
+	var dateErr *nvdcommon.DateError
+	if !errors.As(err, &dateErr) || dateErr.Field != "publishedDate" || dateErr.Value != "2020-13-01T00:00Z" || dateErr.Err == nil {
+		t.Errorf("expected DateError for unparseable date, got %v", err)
+	}
+	if published := items[1].(nvdcommon.CVEDates).Published(); !published.IsZero() {
+		t.Errorf("lenient accessor expected to return zero time, got %v", published)
+	}
+	_, err = bad.LastModifiedStrict()
+	if !errors.As(err, &dateErr) || dateErr.Field != "lastModifiedDate" || dateErr.Err != nil {
+		t.Errorf("expected DateError for missing date, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// DescribeConfiguration is a part of nvdcommon.ConfigurationDescriber interface implementation
+func (i *cveItem) DescribeConfiguration() string {
+	if i == nil || i.cveItem.Configurations == nil || len(i.cveItem.Configurations.Nodes) == 0 {
+		return "no known affected configurations"
+	}
+	lines := make([]string, 0, len(i.cveItem.Configurations.Nodes))
+	for _, n := range i.cveItem.Configurations.Nodes {
+		if s := describeNode(n); s != "" {
+			lines = append(lines, "affects "+s)
+		}
+	}
+	if len(lines) == 0 {
+		return "no known affected configurations"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeNode renders the node as prose; AND nodes which combine vulnerable components with platform-only
+// ones are rendered as "<vulnerable> when running on <platform>"
+func describeNode(n *jsonschema.NVDCVEFeedJSON10DefNode) string {
+	if n == nil {
+		return ""
+	}
+	and := strings.ToUpper(n.Operator) == "AND"
+	var s string
+	if vulnerable, platform := splitPlatform(n); and && len(vulnerable) != 0 && len(platform) != 0 {
+		s = group(joinParts(vulnerable, " and ", true)) + " when running on " + group(joinParts(platform, " and ", true))
+	} else {
+		sep := " or "
+		if and {
+			sep = " and "
+		}
+		s = joinParts(describeParts(n), sep, len(n.CPEMatch)+len(n.Children) > 1)
+	}
+	if n.Negate && s != "" {
+		return "anything but " + group(s)
+	}
+	return s
+}
+
+// splitPlatform splits parts of the node into the ones mentioning vulnerable components and the platform-only ones
+func splitPlatform(n *jsonschema.NVDCVEFeedJSON10DefNode) (vulnerable, platform []string) {
+	add := func(s string, isVulnerable bool) {
+		if s == "" {
+			return
+		}
+		if isVulnerable {
+			vulnerable = append(vulnerable, s)
+		} else {
+			platform = append(platform, s)
+		}
+	}
+	for _, m := range n.CPEMatch {
+		add(describeCPEMatch(m), m != nil && m.Vulnerable)
+	}
+	for _, child := range n.Children {
+		add(describeNode(child), hasVulnerable(child))
+	}
+	return vulnerable, platform
+}
+
+func describeParts(n *jsonschema.NVDCVEFeedJSON10DefNode) []string {
+	parts := make([]string, 0, len(n.CPEMatch)+len(n.Children))
+	for _, m := range n.CPEMatch {
+		if s := describeCPEMatch(m); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	for _, child := range n.Children {
+		if s := describeNode(child); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return parts
+}
+
+// joinParts joins the parts with sep, parts combining other operators are parenthesized if nested is set
+func joinParts(parts []string, sep string, nested bool) string {
+	if !nested || len(parts) == 1 {
+		return strings.Join(parts, sep)
+	}
+	wrapped := make([]string, len(parts))
+	for i, part := range parts {
+		wrapped[i] = group(part)
+	}
+	return strings.Join(wrapped, sep)
+}
+
+// group parenthesizes s if it combines several parts with an operator
+func group(s string) string {
+	if strings.Contains(s, " and ") || strings.Contains(s, " or ") || strings.Contains(s, " when running on ") {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// hasVulnerable tells whether any of the node's cpe_match entries, direct or nested, is vulnerable
+func hasVulnerable(n *jsonschema.NVDCVEFeedJSON10DefNode) bool {
+	if n == nil {
+		return false
+	}
+	for _, m := range n.CPEMatch {
+		if m != nil && m.Vulnerable {
+			return true
+		}
+	}
+	for _, child := range n.Children {
+		if hasVulnerable(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeCPEMatch renders cpe_match entry as "<vendor> <product> <versions>", e.g. "foo bar versions >=1.0 <2.0"
+func describeCPEMatch(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) string {
+	if m == nil {
+		return ""
+	}
+	attrs, err := node2CPE(&cpeMatch{cpeMatch: m})
+	if err != nil {
+		return ""
+	}
+	var words []string
+	for _, attr := range []string{attrs.Vendor, attrs.Product} {
+		if attr == wfn.Any {
+			attr = "any"
+		}
+		words = append(words, unquote(attr))
+	}
+	var bounds []string
+	for _, b := range []struct{ op, ver string }{
+		{">=", m.VersionStartIncluding},
+		{">", m.VersionStartExcluding},
+		{"<=", m.VersionEndIncluding},
+		{"<", m.VersionEndExcluding},
+	} {
+		if b.ver != "" {
+			bounds = append(bounds, b.op+b.ver)
+		}
+	}
+	switch {
+	case len(bounds) != 0:
+		words = append(words, "versions "+strings.Join(bounds, " "))
+	case attrs.Version == wfn.Any:
+		words = append(words, "all versions")
+	case attrs.Version != wfn.NA:
+		words = append(words, "version "+unquote(attrs.Version))
+	}
+	if attrs.Update != wfn.Any && attrs.Update != wfn.NA {
+		words = append(words, "update "+unquote(attrs.Update))
+	}
+	if m.FixedVersion != "" {
+		words = append(words, "(fixed in "+m.FixedVersion+")")
+	}
+	return strings.Join(words, " ")
+}
+
+// unquote removes WFN quoting, e.g. qux_\(beta\) becomes qux_(beta)
+func unquote(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i < len(s)-1 {
+			i++
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
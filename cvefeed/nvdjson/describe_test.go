@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestDescribeConfiguration(t *testing.T) {
+	cases := map[string]struct {
+		nodes    string
+		expected string
+	}{
+		"none": {``, "no known affected configurations"},
+		"ranges": {
+			`{"operator":"OR","cpe_match":[
+				{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0","versionEndExcluding":"2.0"},
+				{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz:1.5:sp1:*:*:*:*:*:*"},
+				{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:qux_\\(beta\\):*:*:*:*:*:*:*:*","fixedVersion":"3.1"}]}`,
+			"affects foo bar versions >=1.0 <2.0 or foo baz version 1.5 update sp1 or foo qux_(beta) all versions (fixed in 3.1)",
+		},
+		"running on": {
+			`{"operator":"AND","children":[
+				{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndIncluding":"2.0"}]},
+				{"operator":"OR","cpe_match":[
+					{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"},
+					{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:microsoft:windows:10:*:*:*:*:*:*:*"}]}]},
+			 {"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*","versionStartExcluding":"0.9"}]}`,
+			"affects foo bar versions <=2.0 when running on (linux linux_kernel or microsoft windows version 10)\n" +
+				"affects foo baz versions >0.9",
+		},
+		"nested": {
+			`{"operator":"AND","children":[
+				{"operator":"OR","cpe_match":[
+					{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"},
+					{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*"}]},
+				{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:qux:*:*:*:*:*:*:*:*"}]},
+				{"operator":"OR","negate":true,"cpe_match":[{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:foo:os:*:*:*:*:*:*:*:*"}]}]}`,
+			"affects ((foo bar version 1.0 or foo baz version 1.0) and foo qux all versions) when running on anything but foo os all versions",
+		},
+	}
+	for name, c := range cases {
+		feed := `{"CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"}},"configurations":{"nodes":[` + c.nodes + `]}}]}`
+		items, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		d, ok := items[0].(nvdcommon.ConfigurationDescriber)
+		if !ok {
+			t.Fatal("CVE item doesn't implement nvdcommon.ConfigurationDescriber")
+		}
+		if got := d.DescribeConfiguration(); got != c.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", name, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// Export returns the items as an NVD CVE JSON 1.1 feed, sorted by CVE ID, see FeedItem;
+// the feed timestamp is left for the caller to set
+func Export(items []nvdcommon.CVEItem) *jsonschema.NVDCVEFeedJSON10 {
+	feed := &jsonschema.NVDCVEFeedJSON10{
+		CVEDataFormat:  "MITRE",
+		CVEDataType:    "CVE",
+		CVEDataVersion: "4.0",
+		CVEItems:       make([]*jsonschema.NVDCVEFeedJSON10DefCVEItem, 0, len(items)),
+	}
+	for _, item := range items {
+		if item != nil {
+			feed.CVEItems = append(feed.CVEItems, FeedItem(item))
+		}
+	}
+	sort.SliceStable(feed.CVEItems, func(i, j int) bool {
+		return feed.CVEItems[i].CVE.CVEDataMeta.ID < feed.CVEItems[j].CVE.CVEDataMeta.ID
+	})
+	feed.CVEDataNumberOfCVEs = strconv.Itoa(len(feed.CVEItems))
+	return feed
+}
+
+// FeedItem returns the item in the shape of NVD CVE JSON 1.1 feeds.
+// Items parsed from NVD feeds (JSON 1.x or CVE API 2.0) are returned as parsed and must not be modified.
+// Other items, e.g. merged ones, are built from the data they provide: configuration, problem types,
+// CVSS vectors, scores and severities, references, assigner and dates, see nvdcommon interfaces;
+// logical tests other than the nodes of NVD feeds mark all their CPE names vulnerable.
+func FeedItem(item nvdcommon.CVEItem) *jsonschema.NVDCVEFeedJSON10DefCVEItem {
+	if i, ok := item.(*cveItem); ok {
+		return i.cveItem
+	}
+	out := &jsonschema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &jsonschema.CVEJSON40{
+			CVEDataMeta: &jsonschema.CVEJSON40CVEDataMeta{ID: item.CVEID()},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &jsonschema.CVEJSON40Description{},
+			Problemtype: &jsonschema.CVEJSON40Problemtype{},
+			References:  &jsonschema.CVEJSON40References{},
+		},
+		Configurations: &jsonschema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+			Nodes:          feedNodes(item.Config()),
+		},
+		Impact: &jsonschema.NVDCVEFeedJSON10DefImpact{},
+	}
+	if a, ok := item.(nvdcommon.CVEAssigner); ok {
+		out.CVE.CVEDataMeta.ASSIGNER = a.Assigner()
+	}
+	if pt := item.ProblemTypes(); len(pt) != 0 {
+		ptd := &jsonschema.CVEJSON40ProblemtypeProblemtypeData{}
+		for _, cwe := range pt {
+			ptd.Description = append(ptd.Description, &jsonschema.CVEJSON40LangString{Lang: "en", Value: cwe})
+		}
+		out.CVE.Problemtype.ProblemtypeData = []*jsonschema.CVEJSON40ProblemtypeProblemtypeData{ptd}
+	}
+	if r, ok := item.(nvdcommon.CVEReferences); ok {
+		for _, url := range r.References() {
+			out.CVE.References.ReferenceData = append(out.CVE.References.ReferenceData, &jsonschema.CVEJSON40Reference{Name: url, URL: url})
+		}
+	}
+	var v2vector, v3vector, v2severity, v3severity string
+	if v, ok := item.(nvdcommon.CVSSVectors); ok {
+		v2vector, v3vector = v.CVSS20vector(), v.CVSS30vector()
+	}
+	if s, ok := item.(nvdcommon.CVSSSeverities); ok {
+		v2severity, v3severity = s.CVSS20severity(), s.CVSS30severity()
+	}
+	if score := item.CVSS20base(); score != 0 || v2vector != "" {
+		out.Impact.BaseMetricV2 = &jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2:   &jsonschema.CVSSV20{BaseScore: score, VectorString: v2vector, Version: "2.0"},
+			Severity: v2severity,
+		}
+	}
+	if score := item.CVSS30base(); score != 0 || v3vector != "" {
+		version := "3.0"
+		if strings.HasPrefix(v3vector, "CVSS:") {
+			version = strings.SplitN(strings.TrimPrefix(v3vector, "CVSS:"), "/", 2)[0]
+		}
+		out.Impact.BaseMetricV3 = &jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &jsonschema.CVSSV30{BaseScore: score, BaseSeverity: v3severity, VectorString: v3vector, Version: version},
+		}
+	}
+	if d, ok := item.(nvdcommon.CVEDates); ok {
+		out.PublishedDate = feedTime(d.Published())
+		out.LastModifiedDate = feedTime(d.LastModified())
+	}
+	return out
+}
+
+// feedNodes returns the configuration nodes of the logical tests
+func feedNodes(tests []nvdcommon.LogicalTest) []*jsonschema.NVDCVEFeedJSON10DefNode {
+	var nodes []*jsonschema.NVDCVEFeedJSON10DefNode
+	for _, t := range tests {
+		if t == nil {
+			continue
+		}
+		if n, ok := t.(*node); ok {
+			nodes = append(nodes, n.node)
+			continue
+		}
+		n := &jsonschema.NVDCVEFeedJSON10DefNode{
+			Operator: strings.ToUpper(t.LogicalOperator()),
+			Negate:   t.NegateIfNeeded(false),
+			Children: feedNodes(t.InnerTests()),
+		}
+		for _, cpe := range t.CPEs() {
+			if cpe != nil {
+				n.CPEMatch = append(n.CPEMatch, &jsonschema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: cpe.BindToFmtString(), Vulnerable: true})
+			}
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// feedTime formats the time as NVD feeds do, zero time as empty string
+func feedTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(nvdcommon.TimeLayout)
+}
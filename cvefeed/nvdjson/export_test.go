@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	for name, feed := range map[string]string{"1.1": hashFeed11, "2.0": hashFeed20} {
+		items, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(Export(items)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		exported, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("%s: can't parse the exported feed: %v", name, err)
+		}
+		if len(exported) != 1 {
+			t.Fatalf("%s: expected 1 item, got %d", name, len(exported))
+		}
+		if h, expected := exported[0].(nvdcommon.ContentHasher).ContentHash(), items[0].(nvdcommon.ContentHasher).ContentHash(); h != expected {
+			t.Errorf("%s: exported item hashed differently: %s != %s", name, h, expected)
+		}
+		if p, expected := exported[0].(nvdcommon.CVEDates).Published(), items[0].(nvdcommon.CVEDates).Published(); !p.Equal(expected) {
+			t.Errorf("%s: expected publication date %v, got %v", name, expected, p)
+		}
+	}
+}
+
+func TestFeedItemMerged(t *testing.T) {
+	items, err := Parse(strings.NewReader(hashFeed11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := FeedItem(nvdcommon.MergeCVEItems(items[0], items[0]))
+	if id := item.CVE.CVEDataMeta.ID; id != "CVE-2020-0001" {
+		t.Errorf("expected CVE-2020-0001, got %q", id)
+	}
+	if item.Impact.BaseMetricV3 == nil || item.Impact.BaseMetricV3.CVSSV3.BaseScore != 9.8 || item.Impact.BaseMetricV3.CVSSV3.Version != "3.0" {
+		t.Errorf("unexpected CVSS v3 metric: %+v", item.Impact.BaseMetricV3)
+	}
+	nodes := item.Configurations.Nodes
+	if len(nodes) != 1 || nodes[0].Operator != "AND" || len(nodes[0].Children) != 2 {
+		t.Fatalf("expected AND node of the merged configurations, got %+v", nodes)
+	}
+	if x, y := nodes[0].Children[0], nodes[0].Children[1]; x.Negate || !y.Negate || len(x.Children) != 1 || len(y.Children) != 1 {
+		t.Errorf("expected the configuration of x and negated one of y, got %+v and %+v", x, y)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+)
+
+// contentCPEMatch holds the fields of cpe_match which affect matching;
+// cpe22Uri, cpe_name and matchCriteriaId are derived or feed specific and left out
+type contentCPEMatch struct {
+	URI                   string `json:"uri"`
+	Vulnerable            bool   `json:"vulnerable"`
+	VersionStartIncluding string `json:"vsi,omitempty"`
+	VersionStartExcluding string `json:"vse,omitempty"`
+	VersionEndIncluding   string `json:"vei,omitempty"`
+	VersionEndExcluding   string `json:"vee,omitempty"`
+	FixedVersion          string `json:"fixed,omitempty"`
+}
+
+type contentNode struct {
+	Operator string            `json:"operator"`
+	Negate   bool              `json:"negate"`
+	CPEMatch []contentCPEMatch `json:"cpe_match,omitempty"`
+	Children []string          `json:"children,omitempty"` // canonical JSON of children
+}
+
+type contentCVSS struct {
+	Vector   string  `json:"vector,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity,omitempty"`
+}
+
+type contentReference struct {
+	URL  string   `json:"url"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+type content struct {
+	ID             string             `json:"id"`
+	Configurations []string           `json:"configurations"` // canonical JSON of nodes
+	CVSSV2         contentCVSS        `json:"cvss_v2"`
+	CVSSV3         contentCVSS        `json:"cvss_v3"`
+	Descriptions   [][2]string        `json:"descriptions"`
+	References     []contentReference `json:"references"`
+}
+
+// ContentHash returns hex encoded SHA-256 over the semantically meaningful fields of the item:
+// configurations, CVSS assessments, descriptions and references.
+// Unordered collections are sorted, so two feeds representing the same CVE hash identically
+// regardless of key order, whitespace or the order of nodes, matches, descriptions and references.
+func (i *cveItem) ContentHash() string {
+	c := content{
+		ID:     i.CVEID(),
+		CVSSV2: contentCVSS{i.CVSS20vector(), i.CVSS20base(), strings.ToUpper(i.CVSS20severity())},
+		CVSSV3: contentCVSS{i.CVSS30vector(), i.CVSS30base(), strings.ToUpper(i.CVSS30severity())},
+	}
+	if i.cveItem.Configurations != nil {
+		c.Configurations = canonicalNodes(i.cveItem.Configurations.Nodes)
+	}
+	if cve := i.cveItem.CVE; cve != nil {
+		if cve.Description != nil {
+			for _, d := range cve.Description.DescriptionData {
+				if d != nil {
+					c.Descriptions = append(c.Descriptions, [2]string{d.Lang, d.Value})
+				}
+			}
+			sort.Slice(c.Descriptions, func(i, j int) bool {
+				a, b := c.Descriptions[i], c.Descriptions[j]
+				return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+			})
+		}
+		if cve.References != nil {
+			for _, r := range cve.References.ReferenceData {
+				if r != nil {
+					tags := append([]string(nil), r.Tags...)
+					sort.Strings(tags)
+					c.References = append(c.References, contentReference{r.URL, tags})
+				}
+			}
+			sort.Slice(c.References, func(i, j int) bool {
+				return mustMarshal(c.References[i]) < mustMarshal(c.References[j])
+			})
+		}
+	}
+	sum := sha256.Sum256([]byte(mustMarshal(c)))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalNodes returns sorted canonical JSON representations of nodes
+func canonicalNodes(nodes []*jsonschema.NVDCVEFeedJSON10DefNode) []string {
+	var canonical []string
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		cn := contentNode{
+			Operator: strings.ToUpper(n.Operator),
+			Negate:   n.Negate,
+			Children: canonicalNodes(n.Children),
+		}
+		for _, m := range n.CPEMatch {
+			if m == nil {
+				continue
+			}
+			cn.CPEMatch = append(cn.CPEMatch, contentOf(m))
+		}
+		sort.Slice(cn.CPEMatch, func(i, j int) bool {
+			return mustMarshal(cn.CPEMatch[i]) < mustMarshal(cn.CPEMatch[j])
+		})
+		canonical = append(canonical, mustMarshal(cn))
+	}
+	sort.Strings(canonical)
+	return canonical
+}
+
+// contentOf returns the fields of cpe_match which affect matching
+func contentOf(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) contentCPEMatch {
+	uri := m.Cpe23Uri
+	if uri == "" {
+		uri = m.Cpe22Uri
+	}
+	return contentCPEMatch{
+		URI:                   uri,
+		Vulnerable:            m.Vulnerable,
+		VersionStartIncluding: m.VersionStartIncluding,
+		VersionStartExcluding: m.VersionStartExcluding,
+		VersionEndIncluding:   m.VersionEndIncluding,
+		VersionEndExcluding:   m.VersionEndExcluding,
+		FixedVersion:          m.FixedVersion,
+	}
+}
+
+// mustMarshal encodes v to JSON; it's only used on the plain structs above, which always encode
+func mustMarshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
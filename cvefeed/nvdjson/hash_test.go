@@ -0,0 +1,92 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+const hashFeed11 = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[{
+	"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"},
+		"references":{"reference_data":[
+			{"url":"https://example.com/a","name":"https://example.com/a","refsource":"MISC","tags":["Vendor Advisory","Patch"]},
+			{"url":"https://example.com/b","name":"https://example.com/b","refsource":"CONFIRM"}]},
+		"description":{"description_data":[{"lang":"en","value":"Something bad"}]}},
+	"configurations":{"CVE_data_version":"4.0","nodes":[
+		{"operator":"OR","cpe_match":[
+			{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"1.2"},
+			{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*","cpe22Uri":"cpe:/a:foo:baz:1.0"}]}]},
+	"impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"},
+		"exploitabilityScore":3.9,"impactScore":5.9}}}]}`
+
+// hashFeed20 is the same CVE as hashFeed11 in NVD CVE API 2.0 format, with collections in different order
+const hashFeed20 = `{"resultsPerPage":1,"startIndex":0,"totalResults":1,"format":"NVD_CVE","version":"2.0",
+	"vulnerabilities":[{"cve":{"id":"CVE-2020-0001","published":"2020-01-01T00:00:00.000","lastModified":"2020-01-02T00:00:00.000",
+		"descriptions":[{"lang":"en","value":"Something bad"}],
+		"references":[
+			{"url":"https://example.com/b","source":"secure@example.com"},
+			{"url":"https://example.com/a","source":"secure@example.com","tags":["Patch","Vendor Advisory"]}],
+		"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary",
+			"cvssData":{"baseSeverity":"CRITICAL","baseScore":9.8,"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","version":"3.1"}}]},
+		"configurations":[{"nodes":[{"operator":"OR","negate":false,"cpeMatch":[
+			{"vulnerable":true,"criteria":"cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*","matchCriteriaId":"B"},
+			{"vulnerable":true,"criteria":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","matchCriteriaId":"A","versionEndExcluding":"1.2"}]}]}]}}]}`
+
+func contentHash(t *testing.T, feed string) string {
+	t.Helper()
+	items, err := Parse(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	h, ok := items[0].(nvdcommon.ContentHasher)
+	if !ok {
+		t.Fatal("CVE item doesn't implement nvdcommon.ContentHasher")
+	}
+	return h.ContentHash()
+}
+
+func TestContentHash(t *testing.T) {
+	hash := contentHash(t, hashFeed11)
+	if len(hash) != 64 {
+		t.Fatalf("expected hex encoded SHA-256, got %q", hash)
+	}
+	if h := contentHash(t, hashFeed11); h != hash {
+		t.Errorf("hash isn't stable: %s != %s", h, hash)
+	}
+	if h := contentHash(t, hashFeed20); h != hash {
+		t.Errorf("the same CVE hashed differently in 1.1 and 2.0 feeds: %s != %s", hash, h)
+	}
+	changes := map[string][2]string{
+		"description":   {"Something bad", "Something worse"},
+		"reference":     {"https://example.com/b", "https://example.com/c"},
+		"version range": {`"versionEndExcluding":"1.2"`, `"versionEndExcluding":"1.3"`},
+		"vulnerable":    {`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:foo:baz`, `{"vulnerable":false,"cpe23Uri":"cpe:2.3:a:foo:baz`},
+		"score":         {`"baseScore":9.8`, `"baseScore":9.1`},
+	}
+	for name, change := range changes {
+		if !strings.Contains(hashFeed11, change[0]) {
+			t.Fatalf("%s: test feed doesn't contain %q", name, change[0])
+		}
+		if h := contentHash(t, strings.Replace(hashFeed11, change[0], change[1], 1)); h == hash {
+			t.Errorf("%s: changed content hashed the same", name)
+		}
+	}
+}
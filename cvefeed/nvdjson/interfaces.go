@@ -17,6 +17,7 @@ package nvdjson
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
@@ -26,12 +27,17 @@ import (
 type cveItem struct {
 	cveItem     *jsonschema.NVDCVEFeedJSON10DefCVEItem
 	configNodes []nvdcommon.LogicalTest
+	assessments []nvdcommon.CVSSAssessment // all CVSS assessments, only kept if requested, see ParseOptions
+	selections  []nvdcommon.CVSSSelection  // how the scored assessments were selected, kept along with assessments
+	status      string                     // vulnStatus of NVD CVE API 2.0 or state of CVE JSON 5.x, see Status
+	comments    []nvdcommon.VendorComment  // vendor comments of NVD CVE API 2.0
 }
 
 type node struct {
 	node              *jsonschema.NVDCVEFeedJSON10DefNode
 	nvdcommonChildren []nvdcommon.LogicalTest
 	wfnCPEs           []*wfn.Attributes
+	opts              wfn.MatchOptions // see WithMatchOptions
 }
 
 type cpeMatch struct {
@@ -40,20 +46,31 @@ type cpeMatch struct {
 }
 
 func newCveItem(json *jsonschema.NVDCVEFeedJSON10DefCVEItem) nvdcommon.CVEItem {
-	item := &cveItem{cveItem: json}
-	for _, n := range item.cveItem.Configurations.Nodes {
-		item.configNodes = append(item.configNodes, nvdcommon.LogicalTest(newNode(n)))
+	return &cveItem{cveItem: json, configNodes: configNodes(json)}
+}
+
+// configNodes returns the logical tests of the configurations of the item
+func configNodes(json *jsonschema.NVDCVEFeedJSON10DefCVEItem) []nvdcommon.LogicalTest {
+	var nodes []nvdcommon.LogicalTest
+	for _, n := range json.Configurations.Nodes {
+		nodes = append(nodes, nvdcommon.LogicalTest(newNode(n)))
 	}
-	return item
+	return nodes
 }
 
 func newNode(json *jsonschema.NVDCVEFeedJSON10DefNode) nvdcommon.LogicalTest {
 	n := &node{node: json}
+	if merged, ok := mergeBounds(json.CPEMatch); ok {
+		// the feed isn't modified, the node matches against its copy
+		copied := *json
+		copied.CPEMatch = merged
+		n.node = &copied
+	}
 
 	if len(n.node.Children) != 0 {
 		children := make([]nvdcommon.LogicalTest, len(n.node.Children))
 		for i, child := range n.node.Children {
-			children[i] = nvdcommon.LogicalTest(&node{node: child})
+			children[i] = newNode(child)
 		}
 		n.nvdcommonChildren = children
 	}
@@ -72,6 +89,62 @@ func newNode(json *jsonschema.NVDCVEFeedJSON10DefNode) nvdcommon.LogicalTest {
 	return n
 }
 
+// mergeBounds joins cpe_match entries of the same CPE name which split a version range between them,
+// one with the lower bound only, another with the upper one only: matched independently they'd cover all versions.
+// Only the bounds making a non-empty range are joined, e.g. >=1.0 and <2.0, but not >=2.0 and <1.0,
+// which are two disjoint open ranges. Returns the entries with the joined ones replaced, false if none were joined.
+func mergeBounds(matches []*jsonschema.NVDCVEFeedJSON10DefCPEMatch) ([]*jsonschema.NVDCVEFeedJSON10DefCPEMatch, bool) {
+	lowerOnly := func(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) bool {
+		return (m.VersionStartIncluding != "" || m.VersionStartExcluding != "") &&
+			m.VersionEndIncluding == "" && m.VersionEndExcluding == "" && m.FixedVersion == ""
+	}
+	upperOnly := func(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) bool {
+		return m.VersionStartIncluding == "" && m.VersionStartExcluding == "" &&
+			(m.VersionEndIncluding != "" || m.VersionEndExcluding != "") && m.FixedVersion == ""
+	}
+	uri := func(m *jsonschema.NVDCVEFeedJSON10DefCPEMatch) string {
+		if m.Cpe23Uri != "" {
+			return m.Cpe23Uri
+		}
+		return m.Cpe22Uri
+	}
+	var merged []*jsonschema.NVDCVEFeedJSON10DefCPEMatch
+	joined := make(map[int]bool)
+	for i, lower := range matches {
+		if lower == nil || !lowerOnly(lower) {
+			continue
+		}
+		for j, upper := range matches {
+			if upper == nil || joined[j] || !upperOnly(upper) || uri(upper) != uri(lower) || upper.Vulnerable != lower.Vulnerable {
+				continue
+			}
+			from := lower.VersionStartIncluding + lower.VersionStartExcluding
+			to := upper.VersionEndIncluding + upper.VersionEndExcluding
+			if smartVerCmp(from, to) >= 0 {
+				continue
+			}
+			m := *lower
+			m.VersionEndIncluding, m.VersionEndExcluding = upper.VersionEndIncluding, upper.VersionEndExcluding
+			if merged == nil {
+				merged = append(make([]*jsonschema.NVDCVEFeedJSON10DefCPEMatch, 0, len(matches)), matches...)
+			}
+			merged[i], merged[j] = &m, nil
+			joined[i], joined[j] = true, true
+			break
+		}
+	}
+	if merged == nil {
+		return matches, false
+	}
+	out := merged[:0]
+	for _, m := range merged {
+		if m != nil {
+			out = append(out, m)
+		}
+	}
+	return out, true
+}
+
 // CVEID returns the identifier of the vulnerability (e.g. CVE).
 func (i *cveItem) CVEID() string {
 	if i == nil {
@@ -88,7 +161,8 @@ func (i *cveItem) Config() []nvdcommon.LogicalTest {
 	return i.configNodes
 }
 
-// ProblemTypes returns weakness types associated with vulnerability (e.g. CWE)
+// ProblemTypes returns weakness types associated with vulnerability (e.g. CWE): every value of the descriptions
+// of problem types, as feeds list several CWEs in one description, in order of appearance and without repeats
 func (i *cveItem) ProblemTypes() []string {
 	var cwes []string
 	if i.cveItem.CVE == nil || i.cveItem.CVE.CVEDataMeta == nil || i.cveItem.CVE.CVEDataMeta.ID == "" {
@@ -96,16 +170,109 @@ func (i *cveItem) ProblemTypes() []string {
 	}
 
 	if i.cveItem.CVE.Problemtype != nil {
+		seen := map[string]bool{}
 		for _, pt := range i.cveItem.CVE.Problemtype.ProblemtypeData {
-			if pt != nil {
-				cwe := getLangStr(pt.Description)
-				cwes = append(cwes, cwe)
+			if pt == nil {
+				continue
+			}
+			for _, d := range pt.Description {
+				if d != nil && d.Value != "" && !seen[d.Value] {
+					seen[d.Value] = true
+					cwes = append(cwes, d.Value)
+				}
 			}
 		}
 	}
 	return cwes
 }
 
+// Vendors returns the names of vendors listed as affected by vulnerability
+func (i *cveItem) Vendors() []string {
+	if i.cveItem.CVE == nil || i.cveItem.CVE.Affects == nil || i.cveItem.CVE.Affects.Vendor == nil {
+		return nil
+	}
+	var vendors []string
+	for _, vd := range i.cveItem.CVE.Affects.Vendor.VendorData {
+		if vd != nil && vd.VendorName != "" {
+			vendors = append(vendors, vd.VendorName)
+		}
+	}
+	return vendors
+}
+
+// References returns the URLs of the references of vulnerability, e.g. advisories and patches
+func (i *cveItem) References() []string {
+	if i.cveItem.CVE == nil || i.cveItem.CVE.References == nil {
+		return nil
+	}
+	var urls []string
+	for _, r := range i.cveItem.CVE.References.ReferenceData {
+		if r != nil && r.URL != "" {
+			urls = append(urls, r.URL)
+		}
+	}
+	return urls
+}
+
+// Description is a part of nvdcommon.CVEDescription interface implementation
+func (i *cveItem) Description() string {
+	if i.cveItem.CVE == nil || i.cveItem.CVE.Description == nil {
+		return ""
+	}
+	return getLangStr(i.cveItem.CVE.Description.DescriptionData)
+}
+
+// Assigner returns the CNA which assigned the CVE: ASSIGNER of NVD 1.1 feeds, sourceIdentifier of NVD 2.0 ones
+func (i *cveItem) Assigner() string {
+	if i.cveItem.CVE == nil || i.cveItem.CVE.CVEDataMeta == nil {
+		return ""
+	}
+	return i.cveItem.CVE.CVEDataMeta.ASSIGNER
+}
+
+// Published returns the time vulnerability was published
+func (i *cveItem) Published() time.Time {
+	return parseTime(i.cveItem.PublishedDate)
+}
+
+// LastModified returns the time vulnerability was last modified
+func (i *cveItem) LastModified() time.Time {
+	return parseTime(i.cveItem.LastModifiedDate)
+}
+
+// PublishedStrict is a part of nvdcommon.StrictCVEDates interface implementation
+func (i *cveItem) PublishedStrict() (time.Time, error) {
+	return parseTimeStrict(i.CVEID(), "publishedDate", i.cveItem.PublishedDate)
+}
+
+// LastModifiedStrict is a part of nvdcommon.StrictCVEDates interface implementation
+func (i *cveItem) LastModifiedStrict() (time.Time, error) {
+	return parseTimeStrict(i.CVEID(), "lastModifiedDate", i.cveItem.LastModifiedDate)
+}
+
+// parseTimeStrict parses timestamp in NVD layout or RFC 3339, anything else is *nvdcommon.DateError
+func parseTimeStrict(id, field, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, &nvdcommon.DateError{CVEID: id, Field: field}
+	}
+	t, err := time.Parse(nvdcommon.TimeLayout, s)
+	if err != nil {
+		var err3339 error
+		if t, err3339 = time.Parse(time.RFC3339, s); err3339 != nil {
+			return time.Time{}, &nvdcommon.DateError{CVEID: id, Field: field, Value: s, Err: err}
+		}
+	}
+	return t, nil
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(nvdcommon.TimeLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // CVSS20base returns CVSS 2.0 base score of vulnerability
 func (i *cveItem) CVSS20base() float64 {
 	if i.cveItem.Impact != nil && i.cveItem.Impact.BaseMetricV2 != nil && i.cveItem.Impact.BaseMetricV2.CVSSV2 != nil {
@@ -123,6 +290,77 @@ func (i *cveItem) CVSS30base() float64 {
 	return 0.0
 }
 
+// CVSS20vector returns CVSS 2.0 vector string of vulnerability
+func (i *cveItem) CVSS20vector() string {
+	if i.cveItem.Impact != nil && i.cveItem.Impact.BaseMetricV2 != nil && i.cveItem.Impact.BaseMetricV2.CVSSV2 != nil {
+		return i.cveItem.Impact.BaseMetricV2.CVSSV2.VectorString
+	}
+	return ""
+}
+
+// CVSS30vector returns CVSS 3.x vector string of vulnerability
+func (i *cveItem) CVSS30vector() string {
+	if i.cveItem.Impact != nil && i.cveItem.Impact.BaseMetricV3 != nil && i.cveItem.Impact.BaseMetricV3.CVSSV3 != nil {
+		return i.cveItem.Impact.BaseMetricV3.CVSSV3.VectorString
+	}
+	return ""
+}
+
+// CVSS20severity returns CVSS 2.0 severity rating of vulnerability
+func (i *cveItem) CVSS20severity() string {
+	if i.cveItem.Impact != nil && i.cveItem.Impact.BaseMetricV2 != nil {
+		return i.cveItem.Impact.BaseMetricV2.Severity
+	}
+	return ""
+}
+
+// CVSS30severity returns CVSS 3.x severity rating of vulnerability
+func (i *cveItem) CVSS30severity() string {
+	if i.cveItem.Impact != nil && i.cveItem.Impact.BaseMetricV3 != nil && i.cveItem.Impact.BaseMetricV3.CVSSV3 != nil {
+		return i.cveItem.Impact.BaseMetricV3.CVSSV3.BaseSeverity
+	}
+	return ""
+}
+
+// DisplayedScore is a part of nvdcommon.DisplayedScore interface implementation.
+// Precedence replicates the one of NVD UI: CVSS v3.1 over v3.0 over v2.0 and, within the same version,
+// the Primary assessment (NVD's own) over the Secondary ones (e.g. CNA's), the first listed one if none is Primary.
+// NVD JSON 1.x feeds carry a single assessment of each major version, so they're only ordered by version.
+func (i *cveItem) DisplayedScore() (float64, string) {
+	// CVE API 2.0 assessments are selected as per the precedence on conversion, see convert20
+	if impact := i.cveItem.Impact; impact != nil {
+		if impact.BaseMetricV3 != nil && impact.BaseMetricV3.CVSSV3 != nil {
+			cvss := impact.BaseMetricV3.CVSSV3
+			version := cvss.Version
+			if version == "" {
+				version = "3.0"
+			}
+			return cvss.BaseScore, version
+		}
+		if impact.BaseMetricV2 != nil && impact.BaseMetricV2.CVSSV2 != nil {
+			cvss := impact.BaseMetricV2.CVSSV2
+			version := cvss.Version
+			if version == "" {
+				version = "2.0"
+			}
+			return cvss.BaseScore, version
+		}
+	}
+	return 0, ""
+}
+
+// Assessments is a part of nvdcommon.CVSSAssessments interface implementation;
+// only NVD CVE API 2.0 and CVE JSON 5.x items parsed with ParseOptions.KeepAssessments keep them
+func (i *cveItem) Assessments() []nvdcommon.CVSSAssessment {
+	return i.assessments
+}
+
+// Selections is a part of nvdcommon.CVSSSelections interface implementation;
+// like assessments, only NVD CVE API 2.0 and CVE JSON 5.x items parsed with ParseOptions.KeepAssessments keep them
+func (i *cveItem) Selections() []nvdcommon.CVSSSelection {
+	return i.selections
+}
+
 // LogicalOperator implements part of cvefeed.LogicalTest interface
 func (n *node) LogicalOperator() string {
 	if n == nil {
@@ -155,8 +393,23 @@ func (n *node) CPEs() []*wfn.Attributes {
 	return n.wfnCPEs
 }
 
+// WithMatchOptions is a part of nvdcommon.MatchOptionsTest interface implementation
+func (n *node) WithMatchOptions(opts wfn.MatchOptions) nvdcommon.LogicalTest {
+	if n == nil {
+		return n
+	}
+	copied := *n
+	copied.opts = opts
+	return &copied
+}
+
 // MatchPlatform implements part of cvefeed.LogicalTest interface
 func (n *node) MatchPlatform(platform *wfn.Attributes, requireVersion bool) bool {
+	return n.MatchPlatformCompare(platform, requireVersion, smartVerCmp)
+}
+
+// MatchPlatformCompare is a part of nvdcommon.VersionCompareTest interface implementation
+func (n *node) MatchPlatformCompare(platform *wfn.Attributes, requireVersion bool, cmp nvdcommon.VersionComparator) bool {
 	if n == nil {
 		return false
 	}
@@ -169,17 +422,25 @@ func (n *node) MatchPlatform(platform *wfn.Attributes, requireVersion bool) bool
 		// but better safe, than sorry.
 		if cpeNode.VersionStartIncluding != "" || cpeNode.VersionStartExcluding != "" ||
 			cpeNode.VersionEndIncluding != "" || cpeNode.VersionEndExcluding != "" {
+			if n.opts.ExactVersion {
+				continue
+			}
 			cpe.Version = wfn.Any
 		} else if requireVersion && cpe.Version == wfn.Any {
 			continue
 		}
-		if wfn.Match(cpe, platform) {
+		if n.opts.Match(cpe, platform) {
 			if platform.Version == wfn.Any || platform.Version == wfn.NA {
 				// logical value of N/A only matches logical value of ANY, so technically, this should
 				// return platform.Version == wfn.Any || cpe.Version == wfn.Any
 				// but these checks have already been performed by wfn.Match() above
 				return true
 			}
+			ver := wfn.StripSlashes(platform.Version)
+			// versions at or past the fix are not affected
+			if cpeNode.FixedVersion != "" && cmp(ver, cpeNode.FixedVersion) >= 0 {
+				continue
+			}
 			if cpeNode.VersionStartIncluding == "" && cpeNode.VersionStartExcluding == "" &&
 				cpeNode.VersionEndIncluding == "" && cpeNode.VersionEndExcluding == "" {
 				return true
@@ -187,17 +448,16 @@ func (n *node) MatchPlatform(platform *wfn.Attributes, requireVersion bool) bool
 			if cpe.Version == wfn.NA {
 				return false
 			}
-			ver := wfn.StripSlashes(platform.Version)
-			if cpeNode.VersionStartIncluding != "" && smartVerCmp(ver, cpeNode.VersionStartIncluding) < 0 {
+			if cpeNode.VersionStartIncluding != "" && cmp(ver, cpeNode.VersionStartIncluding) < 0 {
 				continue
 			}
-			if cpeNode.VersionStartExcluding != "" && smartVerCmp(ver, cpeNode.VersionStartExcluding) <= 0 {
+			if cpeNode.VersionStartExcluding != "" && cmp(ver, cpeNode.VersionStartExcluding) <= 0 {
 				continue
 			}
-			if cpeNode.VersionEndIncluding != "" && smartVerCmp(ver, cpeNode.VersionEndIncluding) > 0 {
+			if cpeNode.VersionEndIncluding != "" && cmp(ver, cpeNode.VersionEndIncluding) > 0 {
 				continue
 			}
-			if cpeNode.VersionEndExcluding != "" && smartVerCmp(ver, cpeNode.VersionEndExcluding) >= 0 {
+			if cpeNode.VersionEndExcluding != "" && cmp(ver, cpeNode.VersionEndExcluding) >= 0 {
 				continue
 			}
 			return true
@@ -206,6 +466,121 @@ func (n *node) MatchPlatform(platform *wfn.Attributes, requireVersion bool) bool
 	return false
 }
 
+// MatchVulnerable is a part of nvdcommon.VulnerableTest interface implementation
+func (n *node) MatchVulnerable(platform *wfn.Attributes, requireVersion bool) bool {
+	if n == nil || platform == nil {
+		return false
+	}
+	return n.vulnerable().MatchPlatform(platform, requireVersion)
+}
+
+// MatchVulnerableCompare is a part of nvdcommon.VersionCompareTest interface implementation
+func (n *node) MatchVulnerableCompare(platform *wfn.Attributes, requireVersion bool, cmp nvdcommon.VersionComparator) bool {
+	if n == nil || platform == nil {
+		return false
+	}
+	return n.vulnerable().MatchPlatformCompare(platform, requireVersion, cmp)
+}
+
+// vulnerable returns the leaf node of the CPEs marked as vulnerable, nil if there are none
+func (n *node) vulnerable() *node {
+	var vulnerable []*jsonschema.NVDCVEFeedJSON10DefCPEMatch
+	for _, cpeNode := range n.node.CPEMatch {
+		if cpeNode.Vulnerable {
+			vulnerable = append(vulnerable, cpeNode)
+		}
+	}
+	if len(vulnerable) == 0 {
+		return nil
+	}
+	return &node{node: &jsonschema.NVDCVEFeedJSON10DefNode{CPEMatch: vulnerable}, opts: n.opts}
+}
+
+// MatchPlatformRange is a part of nvdcommon.VersionRangeTest interface implementation
+func (n *node) MatchPlatformRange(platform *wfn.Attributes, start, end string, requireVersion bool) bool {
+	if n == nil || platform == nil {
+		return false
+	}
+	anyVersion := *platform
+	anyVersion.Version = wfn.Any
+	for _, cpeNode := range n.node.CPEMatch {
+		cpe, err := node2CPE(&cpeMatch{cpeMatch: cpeNode})
+		if err != nil {
+			continue
+		}
+		ranged := cpeNode.VersionStartIncluding != "" || cpeNode.VersionStartExcluding != "" ||
+			cpeNode.VersionEndIncluding != "" || cpeNode.VersionEndExcluding != ""
+		if !ranged && requireVersion && cpe.Version == wfn.Any {
+			continue
+		}
+		opts := n.opts
+		opts.ExactVersion = false // the platform stands for a range of versions of its own
+		if !opts.Match(cpe, &anyVersion) {
+			continue
+		}
+		// versions at or past the fix are not affected
+		if cpeNode.FixedVersion != "" && smartVerCmp(start, cpeNode.FixedVersion) >= 0 {
+			continue
+		}
+		if !ranged {
+			if cpe.Version == wfn.Any {
+				return true
+			}
+			if cpe.Version == wfn.NA {
+				continue
+			}
+			ver := wfn.StripSlashes(cpe.Version)
+			if smartVerCmp(ver, start) >= 0 && smartVerCmp(ver, end) < 0 {
+				return true
+			}
+			continue
+		}
+		// the ranges overlap unless one of them ends before the other starts
+		if cpeNode.VersionStartIncluding != "" && smartVerCmp(cpeNode.VersionStartIncluding, end) >= 0 {
+			continue
+		}
+		if cpeNode.VersionStartExcluding != "" && smartVerCmp(cpeNode.VersionStartExcluding, end) >= 0 {
+			continue
+		}
+		if cpeNode.VersionEndIncluding != "" && smartVerCmp(cpeNode.VersionEndIncluding, start) < 0 {
+			continue
+		}
+		if cpeNode.VersionEndExcluding != "" && smartVerCmp(cpeNode.VersionEndExcluding, start) <= 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// MatchVulnerableRange is a part of nvdcommon.VersionRangeTest interface implementation
+func (n *node) MatchVulnerableRange(platform *wfn.Attributes, start, end string, requireVersion bool) bool {
+	if n == nil || platform == nil {
+		return false
+	}
+	return n.vulnerable().MatchPlatformRange(platform, start, end, requireVersion)
+}
+
+// FixedIn is a part of nvdcommon.FixedVersionTest interface implementation
+func (n *node) FixedIn(platform *wfn.Attributes) string {
+	if n == nil || platform == nil {
+		return ""
+	}
+	for _, cpeNode := range n.node.CPEMatch {
+		if cpeNode.FixedVersion == "" {
+			continue
+		}
+		// check if the platform is covered by this cpe_match before the fix
+		unfixed := *cpeNode
+		unfixed.FixedVersion = ""
+		leaf := &node{node: &jsonschema.NVDCVEFeedJSON10DefNode{CPEMatch: []*jsonschema.NVDCVEFeedJSON10DefCPEMatch{&unfixed}}}
+		if leaf.MatchPlatform(platform, false) {
+			return cpeNode.FixedVersion
+		}
+	}
+	return ""
+}
+
 func node2CPE(node *cpeMatch) (*wfn.Attributes, error) {
 	var err error
 	if node == nil {
@@ -0,0 +1,342 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+const (
+	// timeLayout20 is the layout of timestamps in NVD 2.0 data
+	timeLayout20 = "2006-01-02T15:04:05.000"
+	// primary is the type of assessments made by the source responsible for the data (NVD)
+	primary = "Primary"
+)
+
+// AssessmentRule selects the CVSS assessment CVEs are scored by when NVD CVE API 2.0 lists several of the same version
+type AssessmentRule int
+
+const (
+	// PrimaryPreferred selects the primary assessment (NVD's own), as NVD UI does; ties, e.g. no primary assessment,
+	// are broken as per ParseOptions.TieBreak
+	PrimaryPreferred AssessmentRule = iota
+	// HighestScore selects the assessment of the highest base score, ties are broken as per ParseOptions.TieBreak
+	HighestScore
+)
+
+// ParseOptions tune parsing of NVD feeds, mostly of NVD CVE API 2.0 responses; the zero value parses them
+// the way Parse does
+type ParseOptions struct {
+	// Assessment selects the assessment of each CVSS version CVEs are scored by, once at parsing;
+	// CVSS v3.1 assessments take precedence over v3.0 ones regardless
+	Assessment AssessmentRule
+	// TieBreak selects among the assessments Assessment finds equally good, the first listed one by default
+	TieBreak TieBreak
+	// PreferredSource is the source the PreferSource tie-break prefers, e.g. nvd@nist.gov
+	PreferredSource string
+	// KeepAssessments keeps all the assessments of CVEs and tells how the ones CVEs are scored by were selected,
+	// see nvdcommon.CVSSAssessments and nvdcommon.CVSSSelections
+	KeepAssessments bool
+	// Transform, unless nil, is called for every CVE item of any feed format once it's parsed, in the order of
+	// the feed: the item it returns replaces the parsed one, e.g. wrapping it to tag or redact it,
+	// and the item is dropped if it returns false
+	Transform func(nvdcommon.CVEItem) (nvdcommon.CVEItem, bool)
+}
+
+// transform returns the item transformed as per opts, false if it's dropped
+func (opts ParseOptions) transform(item nvdcommon.CVEItem) (nvdcommon.CVEItem, bool) {
+	if opts.Transform == nil {
+		return item, true
+	}
+	return opts.Transform(item)
+}
+
+// MatchCriteria maps NVD 2.0 matchCriteriaId to the match criteria it identifies
+type MatchCriteria map[string]*jsonschema.NVDCPEMatch20MatchString
+
+// ParseMatchCriteria loads match criteria from NVD CPE match criteria API 2.0 response
+func ParseMatchCriteria(in io.Reader) (MatchCriteria, error) {
+	var root jsonschema.NVDCPEMatch20
+	if err := json.NewDecoder(in).Decode(&root); err != nil && err != io.EOF {
+		return nil, err
+	}
+	mc := make(MatchCriteria, len(root.MatchStrings))
+	for _, item := range root.MatchStrings {
+		if item == nil || item.MatchString == nil || item.MatchString.MatchCriteriaID == "" {
+			continue
+		}
+		mc[item.MatchString.MatchCriteriaID] = item.MatchString
+	}
+	return mc, nil
+}
+
+// ApplyMatchCriteria amends configurations of CVE items with the version ranges declared in match criteria
+// referenced by the configuration leaves. Leaves that declare version bounds themselves are not altered.
+// Items which weren't produced by this package are skipped.
+func ApplyMatchCriteria(items []nvdcommon.CVEItem, mc MatchCriteria) {
+	for _, item := range items {
+		i, ok := item.(*cveItem)
+		if !ok || i.cveItem.Configurations == nil {
+			continue
+		}
+		for _, n := range i.cveItem.Configurations.Nodes {
+			applyMatchCriteria(n, mc)
+		}
+	}
+}
+
+func applyMatchCriteria(n *jsonschema.NVDCVEFeedJSON10DefNode, mc MatchCriteria) {
+	if n == nil {
+		return
+	}
+	for _, m := range n.CPEMatch {
+		if m == nil || m.MatchCriteriaID == "" {
+			continue
+		}
+		if m.VersionStartIncluding != "" || m.VersionStartExcluding != "" ||
+			m.VersionEndIncluding != "" || m.VersionEndExcluding != "" {
+			continue
+		}
+		if c, ok := mc[m.MatchCriteriaID]; ok {
+			m.VersionStartIncluding = c.VersionStartIncluding
+			m.VersionStartExcluding = c.VersionStartExcluding
+			m.VersionEndIncluding = c.VersionEndIncluding
+			m.VersionEndExcluding = c.VersionEndExcluding
+		}
+	}
+	for _, child := range n.Children {
+		applyMatchCriteria(child, mc)
+	}
+}
+
+// convert20 converts a vulnerability from NVD 2.0 format to the NVD 1.0 JSON feed item,
+// the assessments of each CVSS version are selected as per opts, the selections are returned along
+func convert20(cve *jsonschema.NVDCVE20CVE, opts ParseOptions) (*jsonschema.NVDCVEFeedJSON10DefCVEItem, []nvdcommon.CVSSSelection) {
+	item := &jsonschema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &jsonschema.CVEJSON40{
+			CVEDataMeta: &jsonschema.CVEJSON40CVEDataMeta{
+				ID:       cve.ID,
+				ASSIGNER: cve.SourceIdentifier,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &jsonschema.CVEJSON40Description{},
+			Problemtype: &jsonschema.CVEJSON40Problemtype{},
+			References:  &jsonschema.CVEJSON40References{},
+		},
+		Configurations: &jsonschema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+		},
+		Impact:           &jsonschema.NVDCVEFeedJSON10DefImpact{},
+		LastModifiedDate: convertTime20(cve.LastModified),
+		PublishedDate:    convertTime20(cve.Published),
+	}
+
+	for _, d := range cve.Descriptions {
+		if d != nil {
+			item.CVE.Description.DescriptionData = append(item.CVE.Description.DescriptionData, convertLangString20(d))
+		}
+	}
+
+	for _, w := range cve.Weaknesses {
+		if w == nil {
+			continue
+		}
+		ptd := &jsonschema.CVEJSON40ProblemtypeProblemtypeData{}
+		for _, d := range w.Description {
+			if d != nil {
+				ptd.Description = append(ptd.Description, convertLangString20(d))
+			}
+		}
+		item.CVE.Problemtype.ProblemtypeData = append(item.CVE.Problemtype.ProblemtypeData, ptd)
+	}
+
+	for _, r := range cve.References {
+		if r != nil {
+			item.CVE.References.ReferenceData = append(item.CVE.References.ReferenceData, &jsonschema.CVEJSON40Reference{
+				Name:      r.URL,
+				Refsource: r.Source,
+				Tags:      r.Tags,
+				URL:       r.URL,
+			})
+		}
+	}
+
+	for _, c := range cve.Configurations {
+		if c == nil {
+			continue
+		}
+		nodes := make([]*jsonschema.NVDCVEFeedJSON10DefNode, 0, len(c.Nodes))
+		for _, n := range c.Nodes {
+			if n != nil {
+				nodes = append(nodes, convertNode20(n))
+			}
+		}
+		// 1.0 schema expresses multi-node configurations as a parent node
+		if len(nodes) > 1 || c.Negate {
+			operator := c.Operator
+			if operator == "" {
+				operator = "OR"
+			}
+			nodes = []*jsonschema.NVDCVEFeedJSON10DefNode{{
+				Operator: operator,
+				Negate:   c.Negate,
+				Children: nodes,
+			}}
+		}
+		item.Configurations.Nodes = append(item.Configurations.Nodes, nodes...)
+	}
+
+	var selections []nvdcommon.CVSSSelection
+	if cve.Metrics != nil {
+		if m, sel := selectCVSSV3(cve.Metrics.CVSSMetricV31, opts); m != nil {
+			item.Impact.BaseMetricV3 = convertCVSSV3(m)
+			selections = append(selections, sel)
+		} else if m, sel := selectCVSSV3(cve.Metrics.CVSSMetricV30, opts); m != nil {
+			item.Impact.BaseMetricV3 = convertCVSSV3(m)
+			selections = append(selections, sel)
+		}
+		if m, sel := selectCVSSV2(cve.Metrics.CVSSMetricV2, opts); m != nil {
+			selections = append(selections, sel)
+			item.Impact.BaseMetricV2 = &jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+				AcInsufInfo:             m.AcInsufInfo,
+				CVSSV2:                  m.CVSSData,
+				ExploitabilityScore:     m.ExploitabilityScore,
+				ImpactScore:             m.ImpactScore,
+				ObtainAllPrivilege:      m.ObtainAllPrivilege,
+				ObtainOtherPrivilege:    m.ObtainOtherPrivilege,
+				ObtainUserPrivilege:     m.ObtainUserPrivilege,
+				Severity:                m.BaseSeverity,
+				UserInteractionRequired: m.UserInteractionRequired,
+			}
+		}
+	}
+
+	return item, selections
+}
+
+func convertNode20(n *jsonschema.NVDCVE20Node) *jsonschema.NVDCVEFeedJSON10DefNode {
+	node := &jsonschema.NVDCVEFeedJSON10DefNode{
+		Operator: n.Operator,
+		Negate:   n.Negate,
+	}
+	for _, m := range n.CPEMatch {
+		if m == nil {
+			continue
+		}
+		node.CPEMatch = append(node.CPEMatch, &jsonschema.NVDCVEFeedJSON10DefCPEMatch{
+			Cpe23Uri:              m.Criteria,
+			MatchCriteriaID:       m.MatchCriteriaID,
+			VersionEndExcluding:   m.VersionEndExcluding,
+			VersionEndIncluding:   m.VersionEndIncluding,
+			VersionStartExcluding: m.VersionStartExcluding,
+			VersionStartIncluding: m.VersionStartIncluding,
+			Vulnerable:            m.Vulnerable,
+		})
+	}
+	return node
+}
+
+func convertCVSSV3(m *jsonschema.NVDCVE20CVSSV3) *jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV3 {
+	return &jsonschema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+		CVSSV3:              m.CVSSData,
+		ExploitabilityScore: m.ExploitabilityScore,
+		ImpactScore:         m.ImpactScore,
+	}
+}
+
+// assessments20 returns all the CVSS assessments of NVD 2.0 metrics, v3.1 ones first
+func assessments20(metrics *jsonschema.NVDCVE20Metrics) []nvdcommon.CVSSAssessment {
+	if metrics == nil {
+		return nil
+	}
+	var assessments []nvdcommon.CVSSAssessment
+	for _, ms := range [][]*jsonschema.NVDCVE20CVSSV3{metrics.CVSSMetricV31, metrics.CVSSMetricV30} {
+		for _, m := range ms {
+			if m != nil && m.CVSSData != nil {
+				assessments = append(assessments, nvdcommon.CVSSAssessment{
+					Source:    m.Source,
+					Type:      m.Type,
+					Version:   m.CVSSData.Version,
+					Vector:    m.CVSSData.VectorString,
+					BaseScore: m.CVSSData.BaseScore,
+				})
+			}
+		}
+	}
+	for _, m := range metrics.CVSSMetricV2 {
+		if m != nil && m.CVSSData != nil {
+			assessments = append(assessments, nvdcommon.CVSSAssessment{
+				Source:    m.Source,
+				Type:      m.Type,
+				Version:   m.CVSSData.Version,
+				Vector:    m.CVSSData.VectorString,
+				BaseScore: m.CVSSData.BaseScore,
+			})
+		}
+	}
+	return assessments
+}
+
+func convertLangString20(ls *jsonschema.NVDCVE20LangString) *jsonschema.CVEJSON40LangString {
+	return &jsonschema.CVEJSON40LangString{Lang: ls.Lang, Value: ls.Value}
+}
+
+// convertTime20 converts NVD 2.0 timestamp into NVD 1.0 one; unparseable timestamps are passed as is
+func convertTime20(s string) string {
+	t, err := time.Parse(timeLayout20, s)
+	if err != nil {
+		if t, err = time.Parse(timeLayout20[:strings.LastIndexByte(timeLayout20, '.')], s); err != nil {
+			return s
+		}
+	}
+	return t.Format(nvdcommon.TimeLayout)
+}
+
+// ConvertVulnerabilities converts vulnerabilities of NVD CVE API 2.0 response decoded elsewhere (e.g. page by page) to CVE items
+func ConvertVulnerabilities(vulns []*jsonschema.NVDCVE20Vulnerability) ([]nvdcommon.CVEItem, error) {
+	return traverse20(vulns, ParseOptions{})
+}
+
+func traverse20(vulns []*jsonschema.NVDCVE20Vulnerability, opts ParseOptions) ([]nvdcommon.CVEItem, error) {
+	items := make([]nvdcommon.CVEItem, 0, len(vulns))
+	for _, v := range vulns {
+		if v == nil || v.CVE == nil {
+			continue
+		}
+		if v.CVE.ID == "" {
+			return nil, fmt.Errorf("NVD CVE 2.0 vulnerability has no id")
+		}
+		converted, selections := convert20(v.CVE, opts)
+		item := &cveItem{cveItem: converted, status: v.CVE.VulnStatus, comments: vendorComments20(v.CVE.VendorComments)}
+		item.configNodes = configNodes(item.cveItem)
+		if opts.KeepAssessments {
+			item.assessments = assessments20(v.CVE.Metrics)
+			item.selections = selections
+		}
+		if transformed, ok := opts.transform(item); ok {
+			items = append(items, transformed)
+		}
+	}
+	return items, nil
+}
@@ -19,35 +19,26 @@
 package nvdjson
 
 import (
-	"encoding/json"
-	"fmt"
 	"io"
 
-	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
 )
 
-// Parse parses dictionary from NVD vulnerability feed JSON
+// Parse parses dictionary from NVD vulnerability feed JSON 1.x, NVD CVE API 2.0 response or CVE JSON 5.x record (cve.org)
 func Parse(in io.Reader) ([]nvdcommon.CVEItem, error) {
-	var root jsonschema.NVDCVEFeedJSON10
-	err := json.NewDecoder(in).Decode(&root)
-	if err != nil && err != io.EOF {
-		return nil, err
-	}
-	return traverse(&root)
+	return ParseWithOptions(in, ParseOptions{})
 }
 
-func traverse(root *jsonschema.NVDCVEFeedJSON10) ([]nvdcommon.CVEItem, error) {
-	srcItems := root.CVEItems
-	if len(srcItems) == 0 {
-		return nil, fmt.Errorf("NVD CVE JSON feed had no CVE_Items element")
-	}
-	items := make([]nvdcommon.CVEItem, 0, len(srcItems))
-	for _, item := range srcItems {
-		if item == nil || item.Configurations == nil {
-			continue
-		}
-		items = append(items, newCveItem(item))
+// ParseWithOptions is like Parse, but the feed is parsed as per opts, see ParseOptions.
+// The feed is decoded one CVE item at a time, see ParseStream.
+func ParseWithOptions(in io.Reader, opts ParseOptions) ([]nvdcommon.CVEItem, error) {
+	var items []nvdcommon.CVEItem
+	err := ParseStream(in, opts, func(item nvdcommon.CVEItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return items, nil
 }
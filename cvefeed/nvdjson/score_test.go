@@ -0,0 +1,181 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestDisplayedScore(t *testing.T) {
+	cases := map[string]struct {
+		metrics string
+		score   float64
+		version string
+	}{
+		"none": {`{}`, 0, ""},
+		"v2 only": {
+			`{"cvssMetricV2":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:P/A:P","baseScore":7.5}}]}`,
+			7.5, "2.0",
+		},
+		"v3.1 over v3.0 and v2": {
+			`{"cvssMetricV31":[{"source":"cna@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","baseScore":7.5}}],
+			"cvssMetricV30":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.0","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}}],
+			"cvssMetricV2":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:P/A:P","baseScore":7.5}}]}`,
+			7.5, "3.1",
+		},
+		"v3.0 over v2": {
+			`{"cvssMetricV30":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.0","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}}],
+			"cvssMetricV2":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:P/A:P","baseScore":7.5}}]}`,
+			9.8, "3.0",
+		},
+		"primary over secondary": {
+			`{"cvssMetricV31":[
+				{"source":"cna@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","baseScore":7.5}},
+				{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N","baseScore":0.0}}]}`,
+			0, "3.1",
+		},
+		"first secondary": {
+			`{"cvssMetricV31":[
+				{"source":"a@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","baseScore":7.5}},
+				{"source":"b@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}}]}`,
+			7.5, "3.1",
+		},
+	}
+	for name, c := range cases {
+		feed := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001","metrics":` + c.metrics + `}}]}`
+		items, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		ds, ok := items[0].(nvdcommon.DisplayedScore)
+		if !ok {
+			t.Fatal("CVE item doesn't implement nvdcommon.DisplayedScore")
+		}
+		if score, version := ds.DisplayedScore(); score != c.score || version != c.version {
+			t.Errorf("%s: expected %.1f (v%s), got %.1f (v%s)", name, c.score, c.version, score, version)
+		}
+	}
+	items, err := Parse(strings.NewReader(hashFeed11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score, version := items[0].(nvdcommon.DisplayedScore).DisplayedScore(); score != 9.8 || version != "3.1" {
+		t.Errorf("1.x feed: expected 9.8 (v3.1), got %.1f (v%s)", score, version)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	feed := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001","metrics":{
+		"cvssMetricV31":[
+			{"source":"cna@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}},
+			{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","baseScore":7.5}},
+			{"source":"other@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}}],
+		"cvssMetricV2":[
+			{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:N/A:N","baseScore":5.0}},
+			{"source":"cna@example.com","type":"Secondary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:P/A:P","baseScore":7.5}}]}}}]}`
+
+	cases := []struct {
+		name           string
+		opts           ParseOptions
+		cvss30, cvss20 float64
+		vector30       string
+		assessments    int
+	}{
+		{"default", ParseOptions{}, 7.5, 5.0, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", 0},
+		{"highest", ParseOptions{Assessment: HighestScore}, 9.8, 7.5, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0},
+		{"kept", ParseOptions{Assessment: HighestScore, KeepAssessments: true}, 9.8, 7.5, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 5},
+	}
+	for _, c := range cases {
+		items, err := ParseWithOptions(strings.NewReader(feed), c.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		item := items[0]
+		if item.CVSS30base() != c.cvss30 || item.CVSS20base() != c.cvss20 {
+			t.Errorf("%s: expected scores %.1f and %.1f, got %.1f and %.1f", c.name, c.cvss30, c.cvss20, item.CVSS30base(), item.CVSS20base())
+		}
+		if vector := item.(nvdcommon.CVSSVectors).CVSS30vector(); vector != c.vector30 {
+			t.Errorf("%s: expected vector %s, got %s", c.name, c.vector30, vector)
+		}
+		assessments := item.(nvdcommon.CVSSAssessments).Assessments()
+		if len(assessments) != c.assessments {
+			t.Errorf("%s: expected %d assessments, got %d", c.name, c.assessments, len(assessments))
+		}
+		if len(assessments) != 0 {
+			if a := assessments[1]; a.Source != "nvd@nist.gov" || a.Type != "Primary" || a.Version != "3.1" || a.BaseScore != 7.5 {
+				t.Errorf("%s: unexpected assessment %+v", c.name, a)
+			}
+			if a := assessments[4]; a.Version != "2.0" || a.Vector != "AV:N/AC:L/Au:N/C:P/I:P/A:P" {
+				t.Errorf("%s: unexpected v2 assessment %+v", c.name, a)
+			}
+		}
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	feed := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001","metrics":{
+		"cvssMetricV31":[
+			{"source":"cna@example.com","type":"Secondary","exploitabilityScore":2.8,
+				"cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H","baseScore":8.8}},
+			{"source":"other@example.com","type":"Secondary","exploitabilityScore":2.8,
+				"cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H/E:F","baseScore":8.8}},
+			{"source":"third@example.com","type":"Secondary","exploitabilityScore":3.9,
+				"cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:L/A:H","baseScore":8.8}}],
+		"cvssMetricV2":[
+			{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:N/A:N","baseScore":5.0}}]}}}]}`
+
+	cases := []struct {
+		name   string
+		opts   ParseOptions
+		source string
+		reason string
+	}{
+		{"first listed", ParseOptions{}, "cna@example.com", "no primary assessment, tied between 3: first listed"},
+		{"highest", ParseOptions{Assessment: HighestScore}, "cna@example.com", "highest base score, tied between 3: first listed"},
+		{
+			"source", ParseOptions{Assessment: HighestScore, TieBreak: PreferSource, PreferredSource: "other@example.com"},
+			"other@example.com", "highest base score, tied between 3: preferred source other@example.com",
+		},
+		{
+			"absent source", ParseOptions{Assessment: HighestScore, TieBreak: PreferSource, PreferredSource: "nvd@nist.gov"},
+			"cna@example.com", "highest base score, tied between 3: first listed",
+		},
+		{"groups", ParseOptions{TieBreak: MoreMetricGroups}, "other@example.com", "no primary assessment, tied between 3: more metric groups"},
+		{"exploitability", ParseOptions{TieBreak: HigherExploitability}, "third@example.com", "no primary assessment, tied between 3: higher exploitability subscore"},
+	}
+	for _, c := range cases {
+		c.opts.KeepAssessments = true
+		items, err := ParseWithOptions(strings.NewReader(feed), c.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		selections := items[0].(nvdcommon.CVSSSelections).Selections()
+		if len(selections) != 2 {
+			t.Fatalf("%s: expected selections of v3 and v2 assessments, got %+v", c.name, selections)
+		}
+		if s := selections[0]; s.Assessment.Source != c.source || s.Reason != c.reason {
+			t.Errorf("%s: expected %s selected for %q, got %s for %q", c.name, c.source, c.reason, s.Assessment.Source, s.Reason)
+		}
+		if vector := items[0].(nvdcommon.CVSSVectors).CVSS30vector(); vector != selections[0].Assessment.Vector {
+			t.Errorf("%s: scored by %s rather than the selected %s", c.name, vector, selections[0].Assessment.Vector)
+		}
+		if s := selections[1]; s.Assessment.Version != "2.0" || s.Reason != "the only assessment" {
+			t.Errorf("%s: unexpected v2 selection %+v", c.name, s)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+// TieBreak selects among the assessments of a CVSS version the AssessmentRule finds equally good, e.g. of the same
+// highest base score but different vectors, so the selection doesn't depend on the order of sources in the feed
+type TieBreak int
+
+const (
+	// FirstListed selects the first of the tied assessments in the order of the feed
+	FirstListed TieBreak = iota
+	// PreferSource selects the assessment of ParseOptions.PreferredSource
+	PreferSource
+	// MoreMetricGroups selects the assessment whose vector has metrics of more groups, e.g. base and temporal
+	MoreMetricGroups
+	// HigherExploitability selects the assessment of the highest exploitability subscore
+	HigherExploitability
+)
+
+// candidate is an assessment to select from
+type candidate struct {
+	nvdcommon.CVSSAssessment
+	exploitability float64
+}
+
+// selectCVSSV3 returns the assessment selected as per opts along with the selection, nil if there are none
+func selectCVSSV3(ms []*jsonschema.NVDCVE20CVSSV3, opts ParseOptions) (*jsonschema.NVDCVE20CVSSV3, nvdcommon.CVSSSelection) {
+	var valid []*jsonschema.NVDCVE20CVSSV3
+	var cands []candidate
+	for _, m := range ms {
+		if m == nil || m.CVSSData == nil {
+			continue
+		}
+		valid = append(valid, m)
+		cands = append(cands, candidate{
+			CVSSAssessment: nvdcommon.CVSSAssessment{
+				Source:    m.Source,
+				Type:      m.Type,
+				Version:   m.CVSSData.Version,
+				Vector:    m.CVSSData.VectorString,
+				BaseScore: m.CVSSData.BaseScore,
+			},
+			exploitability: m.ExploitabilityScore,
+		})
+	}
+	i, sel := selectAssessment(cands, opts)
+	if i < 0 {
+		return nil, sel
+	}
+	return valid[i], sel
+}
+
+// selectCVSSV2 returns the assessment selected as per opts along with the selection, nil if there are none
+func selectCVSSV2(ms []*jsonschema.NVDCVE20CVSSV2, opts ParseOptions) (*jsonschema.NVDCVE20CVSSV2, nvdcommon.CVSSSelection) {
+	var valid []*jsonschema.NVDCVE20CVSSV2
+	var cands []candidate
+	for _, m := range ms {
+		if m == nil || m.CVSSData == nil {
+			continue
+		}
+		valid = append(valid, m)
+		cands = append(cands, candidate{
+			CVSSAssessment: nvdcommon.CVSSAssessment{
+				Source:    m.Source,
+				Type:      m.Type,
+				Version:   m.CVSSData.Version,
+				Vector:    m.CVSSData.VectorString,
+				BaseScore: m.CVSSData.BaseScore,
+			},
+			exploitability: m.ExploitabilityScore,
+		})
+	}
+	i, sel := selectAssessment(cands, opts)
+	if i < 0 {
+		return nil, sel
+	}
+	return valid[i], sel
+}
+
+// selectAssessment returns the index of the candidate selected as per opts along with the selection, -1 if there are none
+func selectAssessment(cands []candidate, opts ParseOptions) (int, nvdcommon.CVSSSelection) {
+	if len(cands) == 0 {
+		return -1, nvdcommon.CVSSSelection{}
+	}
+	selected := func(i int, reason string) (int, nvdcommon.CVSSSelection) {
+		return i, nvdcommon.CVSSSelection{Assessment: cands[i].CVSSAssessment, Reason: reason}
+	}
+	if len(cands) == 1 {
+		return selected(0, "the only assessment")
+	}
+	all := make([]int, len(cands))
+	for i := range all {
+		all[i] = i
+	}
+	var tied []int
+	var reason string
+	if opts.Assessment == HighestScore {
+		tied = best(cands, all, func(c candidate) float64 { return c.BaseScore })
+		reason = "highest base score"
+	} else {
+		tied = best(cands, all, func(c candidate) float64 { return boolRank(c.Type == primary) })
+		reason = "primary assessment"
+		if cands[tied[0]].Type != primary {
+			reason = "no primary assessment"
+		}
+	}
+	if len(tied) == 1 {
+		return selected(tied[0], reason)
+	}
+	reason = fmt.Sprintf("%s, tied between %d", reason, len(tied))
+	var rank func(candidate) float64
+	var by string
+	switch opts.TieBreak {
+	case PreferSource:
+		rank = func(c candidate) float64 { return boolRank(c.Source == opts.PreferredSource) }
+		by = "preferred source " + opts.PreferredSource
+	case MoreMetricGroups:
+		rank = func(c candidate) float64 { return float64(metricGroups(c.CVSSAssessment)) }
+		by = "more metric groups"
+	case HigherExploitability:
+		rank = func(c candidate) float64 { return c.exploitability }
+		by = "higher exploitability subscore"
+	}
+	if rank != nil {
+		broken := best(cands, tied, rank)
+		if len(broken) == 1 {
+			return selected(broken[0], reason+": "+by)
+		}
+		tied = broken
+	}
+	return selected(tied[0], reason+": first listed")
+}
+
+// best returns the indices of the candidates of the highest rank among the ones of indices, in the same order
+func best(cands []candidate, indices []int, rank func(candidate) float64) []int {
+	var top []int
+	var max float64
+	for _, i := range indices {
+		switch r := rank(cands[i]); {
+		case len(top) == 0 || r > max:
+			top, max = []int{i}, r
+		case r == max:
+			top = append(top, i)
+		}
+	}
+	return top
+}
+
+func boolRank(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// metricGroups returns the number of metric groups the vector of the assessment has metrics of, 0 if it's invalid
+func metricGroups(a nvdcommon.CVSSAssessment) int {
+	v, err := cvss.NewVector(a.Version)
+	if err != nil || v.Parse(a.Vector) != nil {
+		return 0
+	}
+	groups, err := cvss.Completeness(v)
+	if err != nil {
+		return 0
+	}
+	return bits.OnesCount8(uint8(groups))
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// statusRejected is vulnStatus of NVD CVE API 2.0 vulnerabilities withdrawn by their CNA
+const statusRejected = "Rejected"
+
+// rejectPrefix starts the descriptions of rejected CVEs of NVD JSON 1.x feeds, which don't tell the status otherwise
+const rejectPrefix = "** REJECT **"
+
+// Status is a part of nvdcommon.CVEStatus interface implementation: vulnStatus of NVD CVE API 2.0 and state of
+// CVE JSON 5.x items; NVD JSON 1.x items are only known to be Rejected, as told by their descriptions
+func (i *cveItem) Status() string {
+	if i.status != "" {
+		return i.status
+	}
+	if strings.HasPrefix(i.Description(), rejectPrefix) {
+		return statusRejected
+	}
+	return ""
+}
+
+// VendorComments is a part of nvdcommon.CVEStatus interface implementation, only NVD CVE API 2.0 items have them
+func (i *cveItem) VendorComments() []nvdcommon.VendorComment {
+	return i.comments
+}
+
+// WithStatus returns a copy of the item parsed by this package with the status and the vendor comments, e.g. of
+// the item exported with FeedItem, as NVD JSON 1.x doesn't carry them; other items are returned as they are
+func WithStatus(item nvdcommon.CVEItem, status string, comments []nvdcommon.VendorComment) nvdcommon.CVEItem {
+	i, ok := item.(*cveItem)
+	if !ok {
+		return item
+	}
+	c := *i
+	c.status, c.comments = status, comments
+	return &c
+}
+
+func vendorComments20(comments []*jsonschema.NVDCVE20VendorComment) []nvdcommon.VendorComment {
+	var vcs []nvdcommon.VendorComment
+	for _, c := range comments {
+		if c == nil || c.Comment == "" {
+			continue
+		}
+		vc := nvdcommon.VendorComment{Organization: c.Organization, Comment: c.Comment}
+		if t, err := time.Parse(timeLayout20, c.LastModified); err == nil {
+			vc.LastModified = t
+		}
+		vcs = append(vcs, vc)
+	}
+	return vcs
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestStatus(t *testing.T) {
+	feed20 := `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+		{"cve":{"id":"CVE-2020-0001","vulnStatus":"Analyzed","vendorComments":[
+			{"organization":"Red Hat","comment":"Not vulnerable.","lastModified":"2020-01-02T00:00:00.000"},
+			{"organization":"Empty","comment":""}]}},
+		{"cve":{"id":"CVE-2020-0002","vulnStatus":"Rejected"}},
+		{"cve":{"id":"CVE-2020-0003"}}]}`
+	feed11 := `{"CVE_Items":[
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0004"},"description":{"description_data":[{"lang":"en","value":"** REJECT ** DO NOT USE THIS CANDIDATE NUMBER."}]}},"configurations":{"nodes":[]}},
+		{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0005"},"description":{"description_data":[{"lang":"en","value":"Buffer overflow."}]}},"configurations":{"nodes":[]}}]}`
+	var items []nvdcommon.CVEItem
+	for _, feed := range []string{feed20, feed11} {
+		parsed, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, parsed...)
+	}
+	for i, expected := range []string{"Analyzed", "Rejected", "", "Rejected", ""} {
+		if status := items[i].(nvdcommon.CVEStatus).Status(); status != expected {
+			t.Errorf("%s: expected status %q, got %q", items[i].CVEID(), expected, status)
+		}
+	}
+	comments := items[0].(nvdcommon.CVEStatus).VendorComments()
+	expected := nvdcommon.VendorComment{Organization: "Red Hat", Comment: "Not vulnerable.", LastModified: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if len(comments) != 1 || comments[0] != expected {
+		t.Errorf("expected vendor comments %+v, got %+v", []nvdcommon.VendorComment{expected}, comments)
+	}
+	if comments := items[3].(nvdcommon.CVEStatus).VendorComments(); comments != nil {
+		t.Errorf("%s: expected no vendor comments, got %+v", items[3].CVEID(), comments)
+	}
+}
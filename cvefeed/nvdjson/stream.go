@@ -0,0 +1,182 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// ErrStop is returned by the callback of ParseStream to stop parsing early, ParseStream returns nil then
+var ErrStop = errors.New("stop parsing")
+
+// ParseStream is like ParseWithOptions, but the CVE items are passed to emit one at a time, in the order
+// of the feed, as they're decoded: the memory taken is proportional to the largest CVE item rather than
+// to the feed. Parsing stops at the first error of emit, which is returned unless it's ErrStop.
+// Unlike ParseWithOptions, the items parsed before a malformed part of the feed are emitted.
+func ParseStream(in io.Reader, opts ParseOptions, emit func(nvdcommon.CVEItem) error) error {
+	p := &streamParser{dec: json.NewDecoder(in), opts: opts, emit: emit}
+	err := p.parse()
+	if err == ErrStop {
+		return nil
+	}
+	return err
+}
+
+// streamParser decodes feeds element by element
+type streamParser struct {
+	dec      *json.Decoder
+	opts     ParseOptions
+	emit     func(nvdcommon.CVEItem) error
+	emitErr  error // the error of emit, returned as is
+	elements int   // number of elements of CVE_Items and vulnerabilities arrays, or CVE JSON 5.x records
+}
+
+func (p *streamParser) parse() error {
+	t, err := p.dec.Token()
+	if err == io.EOF {
+		return fmt.Errorf("NVD CVE JSON feed had no CVE_Items element")
+	}
+	if err != nil {
+		return err
+	}
+	if t != json.Delim('{') {
+		return fmt.Errorf("expected {, got %v", t)
+	}
+	var record *jsonschema.CVE5Record // the feed is a CVE JSON 5.x record if it has its keys
+	for p.dec.More() {
+		key, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "CVE_Items":
+			err = p.array(p.item)
+		case "vulnerabilities":
+			err = p.array(p.vulnerability)
+		case "cveMetadata", "containers":
+			if record == nil {
+				record = &jsonschema.CVE5Record{}
+			}
+			if key == "cveMetadata" {
+				err = p.dec.Decode(&record.CVEMetadata)
+			} else {
+				err = p.dec.Decode(&record.Containers)
+			}
+		default:
+			var skipped json.RawMessage
+			err = p.dec.Decode(&skipped)
+		}
+		if err != nil && err == p.emitErr {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %v", key, err)
+		}
+	}
+	if record != nil {
+		p.elements++
+		if err := p.record(record); err != nil {
+			return err
+		}
+	}
+	if p.elements == 0 {
+		return fmt.Errorf("NVD CVE JSON feed had no CVE_Items element")
+	}
+	return nil
+}
+
+// array decodes the elements of an array with decode, null is an empty array
+func (p *streamParser) array(decode func() error) error {
+	t, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return nil
+	}
+	if t != json.Delim('[') {
+		return fmt.Errorf("expected [, got %v", t)
+	}
+	for p.dec.More() {
+		p.elements++
+		if err := decode(); err != nil {
+			return err
+		}
+	}
+	_, err = p.dec.Token() // ]
+	return err
+}
+
+func (p *streamParser) transformed(item nvdcommon.CVEItem) error {
+	if transformed, ok := p.opts.transform(item); ok {
+		return p.emitItem(transformed)
+	}
+	return nil
+}
+
+func (p *streamParser) emitItem(item nvdcommon.CVEItem) error {
+	p.emitErr = p.emit(item)
+	return p.emitErr
+}
+
+// item decodes CVE item of NVD JSON 1.x feed
+func (p *streamParser) item() error {
+	var item *jsonschema.NVDCVEFeedJSON10DefCVEItem
+	if err := p.dec.Decode(&item); err != nil {
+		return err
+	}
+	if item == nil || item.Configurations == nil {
+		return nil
+	}
+	return p.transformed(newCveItem(item))
+}
+
+// vulnerability decodes vulnerability of NVD CVE API 2.0 response
+func (p *streamParser) vulnerability() error {
+	var v *jsonschema.NVDCVE20Vulnerability
+	if err := p.dec.Decode(&v); err != nil {
+		return err
+	}
+	items, err := traverse20([]*jsonschema.NVDCVE20Vulnerability{v}, p.opts)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := p.emitItem(item); err != nil { // already transformed
+			return err
+		}
+	}
+	return nil
+}
+
+// record converts CVE JSON 5.x record, decoded as its keys go
+func (p *streamParser) record(rec *jsonschema.CVE5Record) error {
+	items, err := traverse5(rec, p.opts)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := p.emitItem(item); err != nil { // already transformed
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+func TestParseStream(t *testing.T) {
+	stream := func(feed string, stopAt string) ([]string, error) {
+		var ids []string
+		err := ParseStream(strings.NewReader(feed), ParseOptions{}, func(item nvdcommon.CVEItem) error {
+			ids = append(ids, item.CVEID())
+			if item.CVEID() == stopAt {
+				return ErrStop
+			}
+			return nil
+		})
+		return ids, err
+	}
+
+	ids, err := stream(byIDsFeed, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CVE-2020-0001", "CVE-2020-0002", "CVE-2020-0003"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("want %v, got %v", want, ids)
+	}
+
+	// decoding stops at ErrStop, the broken rest of the feed isn't read
+	ids, err = stream(strings.TrimSuffix(byIDsFeed, "\n]}")+",{broken", "CVE-2020-0002")
+	if err != nil || len(ids) != 2 {
+		t.Errorf("expected to stop after 2 CVEs without error, got %v, %v", ids, err)
+	}
+
+	// the CVEs before the broken part are emitted
+	ids, err = stream(strings.TrimSuffix(byIDsFeed, "\n]}")+",{broken", "")
+	if err == nil || len(ids) != 3 {
+		t.Errorf("expected error after 3 CVEs, got %v, %v", ids, err)
+	}
+
+	if ids, err = stream(hashFeed20, ""); err != nil || len(ids) == 0 {
+		t.Errorf("expected NVD CVE API 2.0 vulnerabilities, got %v, %v", ids, err)
+	}
+
+	for _, feed := range []string{"", "{}", `{"CVE_Items":[]}`, "[]"} {
+		if _, err := stream(feed, ""); err == nil {
+			t.Errorf("%q: expected an error", feed)
+		}
+	}
+
+	failure := errors.New("failure")
+	err = ParseStream(strings.NewReader(byIDsFeed), ParseOptions{}, func(nvdcommon.CVEItem) error { return failure })
+	if err != failure {
+		t.Errorf("expected the error of the callback, got %v", err)
+	}
+}
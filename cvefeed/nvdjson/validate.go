@@ -0,0 +1,418 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a structural problem found in the feed
+type ValidationError struct {
+	Path    string // JSON path of the offending element, e.g. $.CVE_Items[3].cve
+	Message string
+}
+
+// Error implements error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is a list of structural problems found in the feed
+type ValidationErrors []ValidationError
+
+// Error implements error interface
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// JSON value types used in schemas
+const (
+	typeObject  = "object"
+	typeArray   = "array"
+	typeString  = "string"
+	typeNumber  = "number"
+	typeInteger = "integer"
+	typeBoolean = "boolean"
+)
+
+// schema is a minimal subset of JSON schema sufficient to validate NVD feeds structure
+type schema struct {
+	typ        string
+	required   []string
+	properties map[string]*schema
+	items      *schema
+}
+
+var (
+	schemaString  = &schema{typ: typeString}
+	schemaNumber  = &schema{typ: typeNumber}
+	schemaInteger = &schema{typ: typeInteger}
+	schemaBoolean = &schema{typ: typeBoolean}
+	schemaStrings = &schema{typ: typeArray, items: schemaString}
+)
+
+var schemaLangStrings = &schema{
+	typ: typeArray,
+	items: &schema{
+		typ:        typeObject,
+		required:   []string{"lang", "value"},
+		properties: map[string]*schema{"lang": schemaString, "value": schemaString},
+	},
+}
+
+// NVD JSON 1.1 feed schema: https://csrc.nist.gov/schema/nvd/feed/1.1/nvd_cve_feed_json_1.1.schema
+
+var schemaNode11 = &schema{
+	typ: typeObject,
+	properties: map[string]*schema{
+		"operator": schemaString,
+		"negate":   schemaBoolean,
+		"cpe_match": {
+			typ: typeArray,
+			items: &schema{
+				typ:      typeObject,
+				required: []string{"vulnerable", "cpe23Uri"},
+				properties: map[string]*schema{
+					"vulnerable":            schemaBoolean,
+					"cpe22Uri":              schemaString,
+					"cpe23Uri":              schemaString,
+					"versionStartExcluding": schemaString,
+					"versionStartIncluding": schemaString,
+					"versionEndExcluding":   schemaString,
+					"versionEndIncluding":   schemaString,
+				},
+			},
+		},
+	},
+}
+
+func init() {
+	// nodes are recursive
+	schemaNode11.properties["children"] = &schema{typ: typeArray, items: schemaNode11}
+}
+
+var schemaCVSS11 = &schema{
+	typ:      typeObject,
+	required: []string{"version", "vectorString", "baseScore"},
+	properties: map[string]*schema{
+		"version":            schemaString,
+		"vectorString":       schemaString,
+		"baseScore":          schemaNumber,
+		"temporalScore":      schemaNumber,
+		"environmentalScore": schemaNumber,
+	},
+}
+
+var schemaFeed11 = &schema{
+	typ:      typeObject,
+	required: []string{"CVE_data_type", "CVE_data_format", "CVE_data_version", "CVE_Items"},
+	properties: map[string]*schema{
+		"CVE_data_type":         schemaString,
+		"CVE_data_format":       schemaString,
+		"CVE_data_version":      schemaString,
+		"CVE_data_numberOfCVEs": schemaString,
+		"CVE_data_timestamp":    schemaString,
+		"CVE_Items": {
+			typ: typeArray,
+			items: &schema{
+				typ:      typeObject,
+				required: []string{"cve"},
+				properties: map[string]*schema{
+					"cve": {
+						typ:      typeObject,
+						required: []string{"data_type", "data_format", "data_version", "CVE_data_meta", "problemtype", "references", "description"},
+						properties: map[string]*schema{
+							"data_type":    schemaString,
+							"data_format":  schemaString,
+							"data_version": schemaString,
+							"CVE_data_meta": {
+								typ:        typeObject,
+								required:   []string{"ID"},
+								properties: map[string]*schema{"ID": schemaString, "ASSIGNER": schemaString, "STATE": schemaString},
+							},
+							"problemtype": {
+								typ:      typeObject,
+								required: []string{"problemtype_data"},
+								properties: map[string]*schema{
+									"problemtype_data": {
+										typ: typeArray,
+										items: &schema{
+											typ:        typeObject,
+											required:   []string{"description"},
+											properties: map[string]*schema{"description": schemaLangStrings},
+										},
+									},
+								},
+							},
+							"references": {
+								typ:      typeObject,
+								required: []string{"reference_data"},
+								properties: map[string]*schema{
+									"reference_data": {
+										typ: typeArray,
+										items: &schema{
+											typ:      typeObject,
+											required: []string{"url"},
+											properties: map[string]*schema{
+												"url":       schemaString,
+												"name":      schemaString,
+												"refsource": schemaString,
+												"tags":      schemaStrings,
+											},
+										},
+									},
+								},
+							},
+							"description": {
+								typ:        typeObject,
+								required:   []string{"description_data"},
+								properties: map[string]*schema{"description_data": schemaLangStrings},
+							},
+						},
+					},
+					"configurations": {
+						typ: typeObject,
+						properties: map[string]*schema{
+							"CVE_data_version": schemaString,
+							"nodes":            {typ: typeArray, items: schemaNode11},
+						},
+					},
+					"impact": {
+						typ: typeObject,
+						properties: map[string]*schema{
+							"baseMetricV3": {
+								typ:        typeObject,
+								properties: map[string]*schema{"cvssV3": schemaCVSS11, "exploitabilityScore": schemaNumber, "impactScore": schemaNumber},
+							},
+							"baseMetricV2": {
+								typ:        typeObject,
+								properties: map[string]*schema{"cvssV2": schemaCVSS11, "severity": schemaString, "exploitabilityScore": schemaNumber, "impactScore": schemaNumber},
+							},
+						},
+					},
+					"publishedDate":    schemaString,
+					"lastModifiedDate": schemaString,
+				},
+			},
+		},
+	},
+}
+
+// NVD CVE API 2.0 schema: https://csrc.nist.gov/schema/nvd/api/2.0/cve_api_json_2.0.schema
+
+var schemaCVSSMetrics20 = &schema{
+	typ: typeArray,
+	items: &schema{
+		typ:      typeObject,
+		required: []string{"source", "type", "cvssData"},
+		properties: map[string]*schema{
+			"source":              schemaString,
+			"type":                schemaString,
+			"cvssData":            schemaCVSS11,
+			"exploitabilityScore": schemaNumber,
+			"impactScore":         schemaNumber,
+		},
+	},
+}
+
+var schemaFeed20 = &schema{
+	typ:      typeObject,
+	required: []string{"resultsPerPage", "startIndex", "totalResults", "format", "version", "timestamp", "vulnerabilities"},
+	properties: map[string]*schema{
+		"resultsPerPage": schemaInteger,
+		"startIndex":     schemaInteger,
+		"totalResults":   schemaInteger,
+		"format":         schemaString,
+		"version":        schemaString,
+		"timestamp":      schemaString,
+		"vulnerabilities": {
+			typ: typeArray,
+			items: &schema{
+				typ:      typeObject,
+				required: []string{"cve"},
+				properties: map[string]*schema{
+					"cve": {
+						typ:      typeObject,
+						required: []string{"id", "published", "lastModified", "references", "descriptions"},
+						properties: map[string]*schema{
+							"id":               schemaString,
+							"sourceIdentifier": schemaString,
+							"published":        schemaString,
+							"lastModified":     schemaString,
+							"vulnStatus":       schemaString,
+							"descriptions":     schemaLangStrings,
+							"references": {
+								typ: typeArray,
+								items: &schema{
+									typ:        typeObject,
+									required:   []string{"url"},
+									properties: map[string]*schema{"url": schemaString, "source": schemaString, "tags": schemaStrings},
+								},
+							},
+							"metrics": {
+								typ: typeObject,
+								properties: map[string]*schema{
+									"cvssMetricV31": schemaCVSSMetrics20,
+									"cvssMetricV30": schemaCVSSMetrics20,
+									"cvssMetricV2":  schemaCVSSMetrics20,
+								},
+							},
+							"weaknesses": {
+								typ: typeArray,
+								items: &schema{
+									typ:        typeObject,
+									required:   []string{"source", "type", "description"},
+									properties: map[string]*schema{"source": schemaString, "type": schemaString, "description": schemaLangStrings},
+								},
+							},
+							"vendorComments": {
+								typ: typeArray,
+								items: &schema{
+									typ:        typeObject,
+									required:   []string{"organization", "comment"},
+									properties: map[string]*schema{"organization": schemaString, "comment": schemaString, "lastModified": schemaString},
+								},
+							},
+							"configurations": {
+								typ: typeArray,
+								items: &schema{
+									typ:      typeObject,
+									required: []string{"nodes"},
+									properties: map[string]*schema{
+										"operator": schemaString,
+										"negate":   schemaBoolean,
+										"nodes": {
+											typ: typeArray,
+											items: &schema{
+												typ:      typeObject,
+												required: []string{"operator", "cpeMatch"},
+												properties: map[string]*schema{
+													"operator": schemaString,
+													"negate":   schemaBoolean,
+													"cpeMatch": {
+														typ: typeArray,
+														items: &schema{
+															typ:      typeObject,
+															required: []string{"vulnerable", "criteria", "matchCriteriaId"},
+															properties: map[string]*schema{
+																"vulnerable":            schemaBoolean,
+																"criteria":              schemaString,
+																"matchCriteriaId":       schemaString,
+																"versionStartExcluding": schemaString,
+																"versionStartIncluding": schemaString,
+																"versionEndExcluding":   schemaString,
+																"versionEndIncluding":   schemaString,
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// Validate checks the structure of NVD JSON 1.1 feed or NVD CVE API 2.0 response against the expected schema.
+// The schema version is detected from the document itself.
+// It returns ValidationErrors if the feed has structural problems, or any other error if it couldn't be decoded.
+func Validate(in io.Reader) error {
+	var doc interface{}
+	if err := json.NewDecoder(in).Decode(&doc); err != nil {
+		return err
+	}
+	s := schemaFeed11
+	if root, ok := doc.(map[string]interface{}); ok {
+		if _, ok := root["vulnerabilities"]; ok {
+			s = schemaFeed20
+		}
+	}
+	var errs ValidationErrors
+	s.validate("$", doc, &errs)
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *schema) validate(path string, v interface{}, errs *ValidationErrors) {
+	if s == nil {
+		return
+	}
+	if v == nil {
+		// null is accepted as absent value
+		return
+	}
+	if t := jsonType(v); t != s.typ && !(s.typ == typeNumber && t == typeInteger) {
+		*errs = append(*errs, ValidationError{path, fmt.Sprintf("expected %s, got %s", s.typ, t)})
+		return
+	}
+	switch s.typ {
+	case typeObject:
+		obj := v.(map[string]interface{})
+		for _, name := range s.required {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, ValidationError{path + "." + name, "required field is missing"})
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s.properties[name].validate(path+"."+name, obj[name], errs)
+		}
+	case typeArray:
+		for i, item := range v.([]interface{}) {
+			s.items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return typeObject
+	case []interface{}:
+		return typeArray
+	case string:
+		return typeString
+	case bool:
+		return typeBoolean
+	case float64:
+		if x == math.Trunc(x) {
+			return typeInteger
+		}
+		return typeNumber
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		feed   string
+		errors []string
+	}{
+		{
+			name: "valid 1.1",
+			feed: `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[
+				{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-0001"},
+				"problemtype":{"problemtype_data":[]},"references":{"reference_data":[]},"description":{"description_data":[]}},
+				"configurations":{"nodes":[{"operator":"OR","children":[{"cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:a:b:*:*:*:*:*:*:*:*"}]}]}]}}]}`,
+		},
+		{
+			name: "broken 1.1",
+			feed: `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":4,"CVE_Items":[
+				{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{},
+				"problemtype":{"problemtype_data":[]},"references":{"reference_data":[]},"description":{"description_data":[]}},
+				"configurations":{"nodes":[{"children":[{"cpe_match":[{"vulnerable":"yes"}]}]}]}}]}`,
+			errors: []string{
+				"$.CVE_Items[0].configurations.nodes[0].children[0].cpe_match[0].cpe23Uri: required field is missing",
+				"$.CVE_Items[0].configurations.nodes[0].children[0].cpe_match[0].vulnerable: expected boolean, got string",
+				"$.CVE_Items[0].cve.CVE_data_meta.ID: required field is missing",
+				"$.CVE_data_version: expected string, got integer",
+			},
+		},
+		{
+			name: "broken 2.0",
+			feed: `{"resultsPerPage":1.5,"startIndex":0,"totalResults":1,"format":"NVD_CVE","version":"2.0","timestamp":"",
+				"vulnerabilities":[{"cve":{"id":"CVE-2020-0001","published":"","lastModified":"","references":[],"descriptions":[],
+				"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:a:b:*:*:*:*:*:*:*:*"}]}]}]}}]}`,
+			errors: []string{
+				"$.resultsPerPage: expected integer, got number",
+				"$.vulnerabilities[0].cve.configurations[0].nodes[0].cpeMatch[0].matchCriteriaId: required field is missing",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate(strings.NewReader(c.feed))
+			if len(c.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if len(errs) != len(c.errors) {
+				t.Fatalf("expected %d errors, got %d:\n%v", len(c.errors), len(errs), errs)
+			}
+			for i, e := range errs {
+				if e.Error() != c.errors[i] {
+					t.Errorf("expected error %q, got %q", c.errors[i], e.Error())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// VersionMatches returns the shortest list of cpe_match entries which match exactly the given versions of product,
+// e.g. for generating a feed of custom advisories. Known are all the released versions of product, in any order:
+// the versions following each other in it are collapsed into an inclusive range, the solitary ones are matched
+// by CPE name with the version set; versions not in known are matched by name too. Ranges match the versions
+// between the known ones as well, e.g. 1.5 of range 1.2 to 2.0 collapsed from known 1.2, 2.0.
+// Versions are plain strings (e.g. 1.2.3), compared the same way the version ranges of the feed are matched;
+// the version of product is ignored.
+func VersionMatches(product *wfn.Attributes, versions, known []string, vulnerable bool) ([]*jsonschema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	if product == nil {
+		return nil, fmt.Errorf("version matches: no product")
+	}
+	ordered := sortVersions(known)
+	var positions []int // of the versions in ordered, ascending
+	var unknown []string
+	for _, v := range sortVersions(versions) {
+		i := sort.Search(len(ordered), func(i int) bool { return smartVerCmp(ordered[i], v) >= 0 })
+		if i < len(ordered) && smartVerCmp(ordered[i], v) == 0 {
+			positions = append(positions, i)
+		} else {
+			unknown = append(unknown, v)
+		}
+	}
+
+	var matches []*jsonschema.NVDCVEFeedJSON10DefCPEMatch
+	exact := func(v string) error {
+		m, err := newCPEMatch(product, v, vulnerable)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, m)
+		return nil
+	}
+	for i := 0; i < len(positions); {
+		j := i + 1
+		for j < len(positions) && positions[j] == positions[j-1]+1 {
+			j++
+		}
+		first, last := ordered[positions[i]], ordered[positions[j-1]]
+		if first == last {
+			if err := exact(first); err != nil {
+				return nil, err
+			}
+		} else {
+			m, err := newCPEMatch(product, "", vulnerable)
+			if err != nil {
+				return nil, err
+			}
+			m.VersionStartIncluding, m.VersionEndIncluding = first, last
+			matches = append(matches, m)
+		}
+		i = j
+	}
+	for _, v := range unknown {
+		if err := exact(v); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// newCPEMatch returns cpe_match entry of product with the version set, ANY if it's empty
+func newCPEMatch(product *wfn.Attributes, version string, vulnerable bool) (*jsonschema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	attr := *product
+	attr.Version = wfn.Any
+	if version != "" {
+		var err error
+		if attr.Version, err = wfn.WFNize(version); err != nil {
+			return nil, fmt.Errorf("version matches: bad version %q: %v", version, err)
+		}
+	}
+	return &jsonschema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: attr.BindToFmtString(), Vulnerable: vulnerable}, nil
+}
+
+// sortVersions returns the versions without duplicates sorted as the ranges of the feed compare them
+func sortVersions(versions []string) []string {
+	sorted := append([]string(nil), versions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return smartVerCmp(sorted[i], sorted[j]) < 0 })
+	out := sorted[:0]
+	for _, v := range sorted {
+		if v != "" && (len(out) == 0 || smartVerCmp(out[len(out)-1], v) != 0) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdjson
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestVersionMatches(t *testing.T) {
+	product := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar"}
+	known := []string{"1.0", "1.1", "1.2", "1.10", "2.0", "2.1", "3.0", "1.0.1"}
+	cases := []struct {
+		name     string
+		versions []string
+		ranges   int
+		exact    int
+	}{
+		{"one range", []string{"1.1", "1.0.1", "1.2", "1.0", "1.10"}, 1, 0},
+		{"two ranges", []string{"1.0", "1.0.1", "2.0", "2.1", "3.0"}, 2, 0},
+		{"solitary versions", []string{"1.0", "1.2", "3.0"}, 0, 3},
+		{"unknown version", []string{"2.0", "2.1", "2.5"}, 1, 1},
+		{"no versions", nil, 0, 0},
+	}
+	for _, c := range cases {
+		matches, err := VersionMatches(product, c.versions, known, true)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var ranges, exact int
+		for _, m := range matches {
+			if m.VersionStartIncluding != "" {
+				ranges++
+			} else {
+				exact++
+			}
+		}
+		if ranges != c.ranges || exact != c.exact {
+			t.Errorf("%s: expected %d ranges and %d CPEs, got %d and %d", c.name, c.ranges, c.exact, ranges, exact)
+		}
+
+		// the entries match exactly the intended versions of the known ones
+		n := newNode(&jsonschema.NVDCVEFeedJSON10DefNode{Operator: "OR", CPEMatch: matches})
+		intended := map[string]bool{}
+		for _, v := range c.versions {
+			intended[v] = true
+		}
+		for _, v := range append(append([]string{"0.9", "4.0"}, known...), c.versions...) {
+			version, _ := wfn.WFNize(v)
+			attr := *product
+			attr.Version = version
+			if got := n.MatchPlatform(&attr, false); got != intended[v] {
+				t.Errorf("%s: version %s expected to match %t, got %t", c.name, v, intended[v], got)
+			}
+		}
+	}
+	if _, err := VersionMatches(nil, []string{"1.0"}, known, true); err == nil {
+		t.Error("nil product is expected to be an error")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ProductIndex maps product names mentioned in the CVE dictionary to the CPE names of the products,
+// to bridge human search terms (e.g. log4j) to CPE-based matching, see Search
+type ProductIndex struct {
+	names    []string                     // sorted normalized product names
+	products map[string][]*wfn.Attributes // the products named so, sorted by vendor
+}
+
+// NewProductIndex creates new ProductIndex from the CPE names in the configurations of dictionary entries;
+// products with ANY or wildcarded names are left out
+func NewProductIndex(d Dictionary) *ProductIndex {
+	seen := map[wfn.Attributes]bool{}
+	idx := &ProductIndex{products: map[string][]*wfn.Attributes{}}
+	for _, entry := range d {
+		for _, cpe := range collectCPEs(entry.Config()) {
+			if cpe == nil || cpe.Product == wfn.Any || cpe.Product == wfn.NA || wfn.HasWildcard(cpe.Product) {
+				continue
+			}
+			product := wfn.Attributes{Part: cpe.Part, Vendor: cpe.Vendor, Product: cpe.Product}
+			if seen[product] {
+				continue
+			}
+			seen[product] = true
+			name := normalizeProduct(cpe.Product)
+			if _, ok := idx.products[name]; !ok {
+				idx.names = append(idx.names, name)
+			}
+			idx.products[name] = append(idx.products[name], &product)
+		}
+	}
+	sort.Strings(idx.names)
+	for _, products := range idx.products {
+		sort.Slice(products, func(i, j int) bool {
+			if products[i].Vendor != products[j].Vendor {
+				return products[i].Vendor < products[j].Vendor
+			}
+			return products[i].Part < products[j].Part
+		})
+	}
+	return idx
+}
+
+// Search returns the products whose names contain term, case-insensitively and regardless of underscores
+// standing for spaces (e.g. "Internet Explorer" finds internet_explorer), sorted by product name and vendor.
+// The returned CPE names have only part, vendor and product set, so fed to Cache.Get they match
+// the CVEs of any version of the product. Empty term finds nothing.
+func (idx *ProductIndex) Search(term string) []*wfn.Attributes {
+	term = normalizeProduct(strings.TrimSpace(term))
+	if term == "" {
+		return nil
+	}
+	var found []*wfn.Attributes
+	for _, name := range idx.names {
+		if strings.Contains(name, term) {
+			for _, product := range idx.products[name] {
+				cpe := *product // copied, so the callers can't modify the index
+				found = append(found, &cpe)
+			}
+		}
+	}
+	return found
+}
+
+// ProductRef refers to a product regardless of version
+type ProductRef struct {
+	Part, Vendor, Product string // WFN values, as in wfn.Attributes
+}
+
+// AffectedProducts returns the distinct products the CPE names of the configuration of cve refer to,
+// nested nodes included, sorted by part, vendor and product; CPE names of ANY or NA product are left out.
+// It tells the products a CVE concerns without matching, e.g. to find the tracked products having any CVEs.
+func AffectedProducts(cve CVEItem) []ProductRef {
+	seen := map[ProductRef]bool{}
+	var products []ProductRef
+	for _, cpe := range collectCPEs(cve.Config()) {
+		if cpe == nil || cpe.Product == wfn.Any || cpe.Product == wfn.NA {
+			continue
+		}
+		product := ProductRef{Part: cpe.Part, Vendor: cpe.Vendor, Product: cpe.Product}
+		if !seen[product] {
+			seen[product] = true
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Part != products[j].Part {
+			return products[i].Part < products[j].Part
+		}
+		if products[i].Vendor != products[j].Vendor {
+			return products[i].Vendor < products[j].Vendor
+		}
+		return products[i].Product < products[j].Product
+	})
+	return products
+}
+
+// productNormalizer drops WFN quoting and spells underscores as spaces
+var productNormalizer = strings.NewReplacer("\\", "", "_", " ")
+
+// normalizeProduct makes product names and search terms comparable
+func normalizeProduct(s string) string {
+	return strings.ToLower(productNormalizer.Replace(s))
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestProductIndex(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictProducts))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	idx := NewProductIndex(dict)
+	cases := []struct {
+		term     string
+		expected []string
+	}{
+		{"log4j", []string{"cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "cpe:2.3:a:example:log4j_scanner:*:*:*:*:*:*:*:*"}},
+		{"LOG4J_S", []string{"cpe:2.3:a:example:log4j_scanner:*:*:*:*:*:*:*:*"}},
+		{"Internet Explorer", []string{"cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*"}},
+		{"c++", []string{"cpe:2.3:a:example:c\\+\\+_runtime:*:*:*:*:*:*:*:*"}},
+		{"windows", []string{"cpe:2.3:o:microsoft:windows:*:*:*:*:*:*:*:*"}},
+		{"nonexistent", nil},
+		{" ", nil},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, cpe := range idx.Search(c.term) {
+			got = append(got, cpe.BindToFmtString())
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.term, c.expected, got)
+		}
+	}
+	idx.Search("log4j")[0].Product = "modified"
+	if got := idx.Search("log4j")[0].Product; got != "log4j" {
+		t.Errorf("index was modified through search results: %q", got)
+	}
+	// the products found feed the matcher
+	cache := NewCache(dict)
+	if ids := cache.MatchIDs(idx.Search("log4j")[:1]...); !reflect.DeepEqual(ids, []string{"CVE-2021-44228"}) {
+		t.Errorf("unexpected matches of the product found: %v", ids)
+	}
+}
+
+func TestAffectedProducts(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictProducts))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	cases := map[string][]ProductRef{
+		"CVE-2021-44228": {{"a", "apache", "log4j"}},
+		"CVE-2020-0001":  {{"a", "microsoft", "internet_explorer"}, {"o", "microsoft", "windows"}},
+		"CVE-2020-0002":  {{"a", "example", "c\\+\\+_runtime"}, {"a", "example", "log4j_scanner"}},
+	}
+	for id, expected := range cases {
+		if actual := AffectedProducts(dict[id]); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v, got %v", id, expected, actual)
+		}
+	}
+}
+
+var testJSONdictProducts = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_Items" : [
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2021-44228" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [
+          { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding" : "2.0", "versionEndExcluding" : "2.15.0" },
+          { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:apache:log4j:2.0:beta9:*:*:*:*:*:*" }
+        ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2020-0001" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "AND",
+        "children" : [
+          { "operator" : "OR", "cpe_match" : [ { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:microsoft:internet_explorer:11:*:*:*:*:*:*:*" } ] },
+          { "operator" : "OR", "cpe_match" : [ { "vulnerable" : false, "cpe23Uri" : "cpe:2.3:o:microsoft:windows:*:*:*:*:*:*:*:*" } ] }
+        ]
+      } ]
+    }
+  },
+  {
+    "cve" : { "CVE_data_meta" : { "ID" : "CVE-2020-0002" } },
+    "configurations" : {
+      "nodes" : [ {
+        "operator" : "OR",
+        "cpe_match" : [
+          { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:example:log4j_scanner:1.0:*:*:*:*:*:*:*" },
+          { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:example:c\\+\\+_runtime:1.0:*:*:*:*:*:*:*" },
+          { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:example:*:1.0:*:*:*:*:*:*:*" }
+        ]
+      } ]
+    }
+  }
+]
+}`
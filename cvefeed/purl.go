@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/purl"
+)
+
+// AffectedPackage is a package affected by the vulnerability, identified by package URL, see nvdcommon.PackageAffects
+type AffectedPackage = nvdcommon.AffectedPackage
+
+// packageCVE is a CVE item with the affected packages attached, see WithPackages
+type packageCVE struct {
+	CVEItem
+	packages []AffectedPackage
+}
+
+// AffectedPackages implements nvdcommon.PackageAffects interface
+func (c packageCVE) AffectedPackages() []AffectedPackage {
+	return c.packages
+}
+
+// WithPackages returns CVE item which is cve with packages appended to its affected packages, so the CVE
+// can be matched by package URLs (see Cache.GetPURL), e.g. to attach the packages of OSV or GHSA advisories
+// to NVD CVEs. Package URLs of the packages are normalized, ones which don't parse are an error.
+// Only CVEItem methods and affected packages are kept, optional interfaces of cve are not.
+func WithPackages(cve CVEItem, packages ...AffectedPackage) (CVEItem, error) {
+	var all []AffectedPackage
+	if affects, ok := cve.(nvdcommon.PackageAffects); ok {
+		all = append(all, affects.AffectedPackages()...)
+	}
+	for _, pkg := range packages {
+		p, err := purl.Parse(pkg.PURL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", cve.CVEID(), err)
+		}
+		pkg.PURL = p.Package()
+		all = append(all, pkg)
+	}
+	if wrapped, ok := cve.(packageCVE); ok {
+		cve = wrapped.CVEItem
+	}
+	return packageCVE{CVEItem: cve, packages: all}, nil
+}
+
+// packageIndex maps package URLs without version (see purl.PURL.Package) to the CVEs affecting them
+type packageIndex map[string][]packageRef
+
+type packageRef struct {
+	cve CVEItem
+	pkg AffectedPackage
+}
+
+// packageIndex returns the index of affected packages of the dictionary, built on the first call
+func (c *Cache) packageIndex() packageIndex {
+	c.purlsOnce.Do(func() {
+		c.purls = make(packageIndex)
+		for _, cve := range c.Dict {
+			affects, ok := cve.(nvdcommon.PackageAffects)
+			if !ok {
+				continue
+			}
+			for _, pkg := range affects.AffectedPackages() {
+				key := pkg.PURL
+				if p, err := purl.Parse(key); err == nil {
+					key = p.Package()
+				}
+				c.purls[key] = append(c.purls[key], packageRef{cve: cve, pkg: pkg})
+			}
+		}
+	})
+	return c.purls
+}
+
+// GetPURL returns CVEs the package identified by package URL is affected by, as per the affected packages of CVEs
+// of the dictionary (see nvdcommon.PackageAffects): the version of the package has to be listed or be in one of
+// the ranges, compared as per the type of the package (see purl.CompareVersions). Package URL without version
+// matches all the affected packages, unless RequireVersion is set. Qualifiers and subpath are disregarded.
+// The results are sorted by CVE ID and aren't cached; the affected packages are indexed on the first call,
+// so CVEs must not be added to the dictionary afterwards.
+func (c *Cache) GetPURL(s string) ([]MatchResult, error) {
+	p, err := purl.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if p.Version == "" && c.RequireVersion {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var results []MatchResult
+	for _, ref := range c.packageIndex()[p.Package()] {
+		id := ref.cve.CVEID()
+		if seen[id] || !c.admit(ref.cve) || !affected(p, ref.pkg) {
+			continue
+		}
+		seen[id] = true
+		results = append(results, MatchResult{CVE: ref.cve, PURL: p.String()})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CVE.CVEID() < results[j].CVE.CVEID() })
+	return results, nil
+}
+
+// affected returns true if the version of package p is one of the affected versions of pkg
+func affected(p *purl.PURL, pkg AffectedPackage) bool {
+	if p.Version == "" {
+		return true
+	}
+	for _, v := range pkg.Versions {
+		if purl.CompareVersions(p.Type, p.Version, v) == 0 {
+			return true
+		}
+	}
+	for _, r := range pkg.Ranges {
+		if r.Contains(p.Type, p.Version) {
+			return true
+		}
+	}
+	return false
+}
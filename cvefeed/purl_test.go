@@ -0,0 +1,83 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/purl"
+)
+
+func TestGetPURL(t *testing.T) {
+	lodash, err := WithPackages(idCVE{id: "CVE-2021-23337"}, AffectedPackage{
+		PURL:   "pkg:npm/lodash@4.17.20",
+		Ranges: []purl.Range{{Introduced: "0", Fixed: "4.17.21"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	django, err := WithPackages(idCVE{id: "CVE-2023-1000"}, AffectedPackage{
+		PURL:     "pkg:pypi/Django",
+		Versions: []string{"4.1.5"},
+		Ranges:   []purl.Range{{Introduced: "3.2", LastAffected: "3.2.16"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = WithPackages(idCVE{id: "CVE-2023-1001"}, AffectedPackage{PURL: "npm/lodash"}); err == nil {
+		t.Fatal("expected an error for a malformed package URL")
+	}
+	cache := NewCache(Dictionary{"CVE-2021-23337": lodash, "CVE-2023-1000": django, "CVE-2023-1002": idCVE{id: "CVE-2023-1002"}})
+
+	cases := []struct {
+		purl string
+		want []string
+	}{
+		{"pkg:npm/lodash@4.17.20", []string{"CVE-2021-23337"}},
+		{"pkg:npm/lodash@4.17.21", nil},
+		{"pkg:npm/lodash@4.1.0?foo=bar", []string{"CVE-2021-23337"}},
+		{"pkg:npm/lodash", []string{"CVE-2021-23337"}},
+		{"pkg:npm/underscore@1.0.0", nil},
+		{"pkg:pypi/django@4.1.5", []string{"CVE-2023-1000"}},
+		{"pkg:pypi/django@3.2.16", []string{"CVE-2023-1000"}},
+		{"pkg:pypi/django@3.2.17", nil},
+		{"pkg:pypi/django@3.1", nil},
+	}
+	for _, c := range cases {
+		results, err := cache.GetPURL(c.purl)
+		if err != nil {
+			t.Fatalf("%s: %v", c.purl, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.CVE.CVEID())
+			if r.PURL == "" || len(r.CPEs) != 0 {
+				t.Errorf("%s: unexpected result %+v", c.purl, r)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: want %v, got %v", c.purl, c.want, got)
+		}
+	}
+
+	cache.SetRequireVersion(true)
+	if results, err := cache.GetPURL("pkg:npm/lodash"); err != nil || len(results) != 0 {
+		t.Errorf("expected no results without version when version is required, got %v, %v", results, err)
+	}
+	if _, err := cache.GetPURL("lodash@4.17.20"); err == nil {
+		t.Error("expected an error for a malformed package URL")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ScanRecord is the structured record of the vulnerabilities of an inventory record, e.g. of an input row of
+// cpe2cve in JSON and NDJSON output modes. The JSON schema is stable: fields may be added, but not renamed or removed.
+type ScanRecord struct {
+	Input           []string      `json:"input"`           // the fields of the inventory record
+	Vulnerabilities []MatchRecord `json:"vulnerabilities"` // empty if none were found
+}
+
+// MatchRecord is the structured record of a CVE matched by an inventory record, see ScanRecord
+type MatchRecord struct {
+	CVE            string            `json:"cve"`
+	Matches        []string          `json:"matches"`             // URI bindings of the matched vulnerable CPEs
+	Platforms      []string          `json:"platforms,omitempty"` // URI bindings of the matched platform CPEs
+	CWEs           []string          `json:"cwes,omitempty"`      // problem types as listed in the feed
+	Score          float64           `json:"score"`               // representative score, see RepresentativeScore; exceptions don't change it
+	Severity       string            `json:"severity"`            // severity of the score, or the one rescored by exceptions
+	CVSSVersion    string            `json:"cvss_version,omitempty"`
+	CVSS2          float64           `json:"cvss2,omitempty"`
+	CVSS3          float64           `json:"cvss3,omitempty"`
+	EPSS           *float64          `json:"epss,omitempty"`            // probability of exploitation, see EPSSScore
+	EPSSPercentile *float64          `json:"epss_percentile,omitempty"` // see EPSSScore
+	KnownExploited bool              `json:"known_exploited,omitempty"`
+	KEVDueDate     string            `json:"kev_due_date,omitempty"` // YYYY-MM-DD
+	Status         string            `json:"status,omitempty"`       // see CVEStatus
+	Published      *time.Time        `json:"published,omitempty"`
+	LastModified   *time.Time        `json:"last_modified,omitempty"`
+	References     []string          `json:"references,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	VendorComments []VendorComment   `json:"vendor_comments,omitempty"`
+	Sources        []string          `json:"sources,omitempty"`     // sources of merged CVEs, see CVESources
+	Provenance     map[string]string `json:"provenance,omitempty"`  // sources of the fields of merged CVEs, see CVEProvenance
+	Annotations    []Annotation      `json:"annotations,omitempty"` // suppression rules annotating the match, see Suppressions
+}
+
+// VendorComment is the statement of a vendor on the CVE, see MatchRecord
+type VendorComment struct {
+	Organization string `json:"organization"`
+	Comment      string `json:"comment"`
+}
+
+// NewMatchRecord returns the record of the match result with the data the CVE item provides; enrichments
+// which come from elsewhere (EPSS scores, KEV due dates) are left for the caller to fill in
+func NewMatchRecord(r MatchResult) MatchRecord {
+	score := resultScore(r) // the severity of rescored results, which have no score of their own
+	rec := MatchRecord{
+		CVE:            r.CVE.CVEID(),
+		Matches:        bindURIs(r.VulnerableCPEs()),
+		Platforms:      bindURIs(r.PlatformCPEs()),
+		Score:          RepresentativeScore(r.CVE).Score,
+		Severity:       score.Severity.String(),
+		CVSSVersion:    score.Version,
+		CVSS2:          r.CVE.CVSS20base(),
+		CVSS3:          r.CVE.CVSS30base(),
+		KnownExploited: r.KnownExploited,
+		Status:         CVEStatus(r.CVE),
+		Sources:        CVESources(r.CVE),
+		Provenance:     CVEProvenance(r.CVE),
+		Annotations:    r.Annotations,
+	}
+	if score.Version == "" && !score.SeverityOnly {
+		rec.Severity = ""
+	}
+	for _, cwe := range r.CVE.ProblemTypes() {
+		if cwe != "" { // problem types without description
+			rec.CWEs = append(rec.CWEs, cwe)
+		}
+	}
+	if dates, ok := r.CVE.(nvdcommon.CVEDates); ok {
+		if t := dates.Published(); !t.IsZero() {
+			rec.Published = &t
+		}
+		if t := dates.LastModified(); !t.IsZero() {
+			rec.LastModified = &t
+		}
+	}
+	if refs, ok := r.CVE.(nvdcommon.CVEReferences); ok {
+		rec.References = refs.References()
+	}
+	if d, ok := r.CVE.(nvdcommon.CVEDescription); ok {
+		rec.Description = d.Description()
+	}
+	for _, c := range VendorComments(r.CVE) {
+		rec.VendorComments = append(rec.VendorComments, VendorComment{Organization: c.Organization, Comment: c.Comment})
+	}
+	return rec
+}
+
+// bindURIs binds the CPE names to URIs, skipping nil ones; the result isn't nil, so it's encoded as an empty list
+func bindURIs(cpes []*wfn.Attributes) []string {
+	uris := make([]string, 0, len(cpes))
+	for _, attr := range cpes {
+		if attr != nil {
+			uris = append(uris, attr.BindToURI())
+		}
+	}
+	return uris
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testJSONdictRecord = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[{"cve":{"id":"CVE-2020-0001",
+	"published":"2020-01-01T10:00:00.000","lastModified":"2020-02-01T10:00:00.000","vulnStatus":"Analyzed",
+	"descriptions":[{"lang":"es","value":"Desbordamiento."},{"lang":"en","value":"Overflow, with \"quotes\"\tand tabs."}],
+	"references":[{"url":"https://example.com/advisory"}],
+	"vendorComments":[{"organization":"Acme","comment":"Fixed in 1.1."}],
+	"weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-787"}]}],
+	"metrics":{"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1",
+		"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}}]},
+	"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+
+// noCWECVE lists a problem type without description
+type noCWECVE struct {
+	CVEItem
+}
+
+func (noCWECVE) ProblemTypes() []string { return []string{""} }
+
+func TestNewMatchRecord(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictRecord))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	attr, err := wfn.Parse("cpe:/a:acme:widget:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := NewCache(dict).Get([]*wfn.Attributes{attr})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(results))
+	}
+	b, err := json.Marshal(NewMatchRecord(results[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"cve":"CVE-2020-0001","matches":["cpe:/a:acme:widget:1.0"],"cwes":["CWE-787"],"score":9.8,` +
+		`"severity":"Critical","cvss_version":"3","cvss3":9.8,"status":"Analyzed",` +
+		`"published":"2020-01-01T10:00:00Z","last_modified":"2020-02-01T10:00:00Z",` +
+		`"references":["https://example.com/advisory"],"description":"Overflow, with \"quotes\"\tand tabs.",` +
+		`"vendor_comments":[{"organization":"Acme","comment":"Fixed in 1.1."}]}`
+	if string(b) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b)
+	}
+
+	// the severity of the result rescored by an exception goes along with the score of the CVE
+	low := cvss.SeverityLow
+	rescored := results[0]
+	rescored.Rescored = &low
+	rec := NewMatchRecord(rescored)
+	if rec.Score != 9.8 || rec.Severity != "Low" || rec.CVSSVersion != "3" {
+		t.Errorf("rescored: expected score 9.8 of severity Low, CVSS version 3, got %v of %q, %q", rec.Score, rec.Severity, rec.CVSSVersion)
+	}
+
+	// problem types without description are left out
+	nocwe := results[0]
+	nocwe.CVE = noCWECVE{nocwe.CVE}
+	if b, err = json.Marshal(NewMatchRecord(nocwe)); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte(`"cwes"`)) {
+		t.Errorf("expected no CWEs, got %s", b)
+	}
+}
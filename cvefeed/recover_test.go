@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// brokenCVE stands for a malformed record whose configuration can't be evaluated
+type brokenCVE struct {
+	CVEItem
+}
+
+func (brokenCVE) CVEID() string         { return "CVE-2020-9999" }
+func (brokenCVE) Config() []LogicalTest { panic("malformed configuration") }
+
+func TestRecoverPanics(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictVersions))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	dict["CVE-2020-9999"] = brokenCVE{}
+	cpe := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.5"}
+
+	cache := NewCache(dict).SetRecoverPanics(true)
+	var ids []string
+	for _, r := range cache.Get([]*wfn.Attributes{cpe}) {
+		ids = append(ids, r.CVE.CVEID())
+	}
+	if expected := []string{"CVE-2020-0001"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Get: expected %v, got %v", expected, ids)
+	}
+	if ids, expected := cache.MatchIDs(cpe), []string{"CVE-2020-0001"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("MatchIDs: expected %v, got %v", expected, ids)
+	}
+	if results := cache.MatchAnyVersion(cpe, []string{"1.5"}); len(results) != 1 {
+		t.Errorf("MatchAnyVersion: expected 1 result, got %d", len(results))
+	}
+	skipped := cache.Skipped()
+	if len(skipped) != 1 {
+		t.Fatalf("expected 1 skipped CVE, got %v", skipped)
+	}
+	if err := skipped[0]; err.CVEID != "CVE-2020-9999" || err.Panic != "malformed configuration" {
+		t.Errorf("unexpected error of the skipped CVE: %+v", err)
+	}
+
+	// the errors come along with the results of the query, cached or not
+	for _, cache := range []*Cache{NewCache(dict).SetRecoverPanics(true), NewCache(dict).SetRecoverPanics(true).SetMaxSize(-1)} {
+		for i := 0; i < 2; i++ {
+			results, errs := cache.GetWithErrors([]*wfn.Attributes{cpe})
+			if len(results) != 1 || len(errs) != 1 || errs[0].CVEID != "CVE-2020-9999" {
+				t.Errorf("GetWithErrors #%d: expected 1 result and the error of CVE-2020-9999, got %d results and %v", i+1, len(results), errs)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic with recovery disabled")
+		}
+	}()
+	NewCache(dict).Get([]*wfn.Attributes{cpe})
+}
@@ -0,0 +1,294 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sarif converts match results into SARIF 2.1.0 logs, e.g. for GitHub code scanning.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+	toolName  = "nvdtools"
+	toolURI   = "https://github.com/facebookincubator/nvdtools"
+	nvdURL    = "https://nvd.nist.gov/vuln/detail/"
+)
+
+// Levels of results
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+	LevelNone    = "none"
+)
+
+// Log is SARIF log of a single run
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []*Run `json:"runs"`
+}
+
+// Run is a run of the tool which found the results
+type Run struct {
+	Tool    Tool      `json:"tool"`
+	Results []*Result `json:"results"`
+}
+
+// Tool describes the tool and the rules it checks, one rule per CVE
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver is the component of the tool which defines the rules
+type Driver struct {
+	Name           string  `json:"name"`
+	InformationURI string  `json:"informationUri,omitempty"`
+	Rules          []*Rule `json:"rules"`
+}
+
+// Rule describes the CVE
+type Rule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     *Message               `json:"shortDescription,omitempty"`
+	FullDescription      *Message               `json:"fullDescription,omitempty"`
+	Help                 *Message               `json:"help,omitempty"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration *Configuration         `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Configuration is the default configuration of the rule
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+// Message is a plain text message, optionally along with its Markdown rendering
+type Message struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// Result is a finding of the CVE in the component identified by its CPE name
+type Result struct {
+	RuleID    string      `json:"ruleId"`
+	RuleIndex int         `json:"ruleIndex"`
+	Level     string      `json:"level"`
+	Message   Message     `json:"message"`
+	Locations []*Location `json:"locations"`
+}
+
+// Location is where the component was found: the artifact (e.g. the manifest declaring it) if known,
+// and the component's CPE name as the logical location
+type Location struct {
+	PhysicalLocation *PhysicalLocation  `json:"physicalLocation,omitempty"`
+	LogicalLocations []*LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// PhysicalLocation references the artifact
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+}
+
+// ArtifactLocation is the URI of the artifact, relative to the repository root for code scanning
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// LogicalLocation names the component
+type LogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// FromMatchResults converts match results into SARIF log: every CVE becomes a rule with the severity of its
+// CVSS score (v3 if known, v2 otherwise), CVSS vector and score in the properties, the description and references
+// of the CVE as the description and help of the rule, and every matched CPE a result.
+// GitHub code scanning requires results to point at a file, artifact is the one they're attributed to
+// (e.g. the manifest the components were taken from); results only have logical locations if it's empty.
+func FromMatchResults(results []cvefeed.MatchResult, artifact string) *Log {
+	run := &Run{
+		Tool:    Tool{Driver: Driver{Name: toolName, InformationURI: toolURI, Rules: []*Rule{}}},
+		Results: []*Result{},
+	}
+	rules := map[string]int{}
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		id := r.CVE.CVEID()
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule(r.CVE))
+		}
+		level := run.Tool.Driver.Rules[index].DefaultConfiguration.Level
+		if r.Rescored != nil {
+			// severity assigned by accepted risk decision overrides the one of CVSS
+			level = Level(*r.Rescored)
+		}
+		for _, cpe := range r.CPEs {
+			if cpe == nil {
+				continue
+			}
+			name := cpe.BindToFmtString()
+			loc := &Location{LogicalLocations: []*LogicalLocation{{Name: name, FullyQualifiedName: name, Kind: "module"}}}
+			if artifact != "" {
+				loc.PhysicalLocation = &PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: artifact}}
+			}
+			run.Results = append(run.Results, &Result{
+				RuleID:    id,
+				RuleIndex: index,
+				Level:     level,
+				Message:   Message{Text: fmt.Sprintf("%s affects %s", id, name)},
+				Locations: []*Location{loc},
+			})
+		}
+	}
+	return &Log{Schema: schemaURI, Version: version, Runs: []*Run{run}}
+}
+
+// Write encodes the log as JSON into w
+func (l *Log) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l); err != nil {
+		return fmt.Errorf("sarif: failed to encode log: %v", err)
+	}
+	return nil
+}
+
+// Level returns SARIF level of the severity: error for high and critical, warning for medium,
+// note for low and none for unscored findings
+func Level(s cvss.Severity) string {
+	switch s {
+	case cvss.SeverityCritical, cvss.SeverityHigh:
+		return LevelError
+	case cvss.SeverityMedium:
+		return LevelWarning
+	case cvss.SeverityLow, cvss.SeverityNone:
+		return LevelNote
+	default:
+		return LevelNone
+	}
+}
+
+func rule(cve cvefeed.CVEItem) *Rule {
+	id := cve.CVEID()
+	r := &Rule{
+		ID:                   id,
+		ShortDescription:     &Message{Text: id},
+		DefaultConfiguration: &Configuration{Level: Level(cvss.SeverityUnknown)},
+		Properties:           map[string]interface{}{"tags": tags(cve)},
+	}
+	if strings.HasPrefix(id, "CVE-") {
+		r.HelpURI = nvdURL + id
+	}
+	describe(r, cve)
+	vectors, _ := cve.(nvdcommon.CVSSVectors)
+	var score float64
+	var severity cvss.Severity
+	var vector string
+	switch {
+	case cve.CVSS30base() > 0:
+		score = cve.CVSS30base()
+		severity = cvss.SeverityFromScore(score)
+		if vectors != nil {
+			vector = vectors.CVSS30vector()
+		}
+	case cve.CVSS20base() > 0:
+		score = cve.CVSS20base()
+		severity = cvss.SeverityFromV2Score(score)
+		if vectors != nil {
+			vector = strings.Trim(vectors.CVSS20vector(), "()")
+		}
+	default:
+		return r
+	}
+	r.DefaultConfiguration.Level = Level(severity)
+	// security-severity is the score GitHub code scanning ranks the findings by
+	r.Properties["security-severity"] = fmt.Sprintf("%.1f", score)
+	r.Properties["cvssScore"] = score
+	r.Properties["cvssSeverity"] = severity.String()
+	if vector != "" {
+		r.Properties["cvssVector"] = vector
+	}
+	return r
+}
+
+// maxShortDescription is the length of short descriptions of rules, in runes, descriptions are cut beyond it
+const maxShortDescription = 256
+
+// describe sets the description of the rule to the one of the CVE, if any: the first sentence is the short
+// description, the whole one the full description; the help lists the references of the CVE too
+func describe(r *Rule, cve cvefeed.CVEItem) {
+	var description string
+	if d, ok := cve.(nvdcommon.CVEDescription); ok {
+		description = strings.TrimSpace(d.Description())
+	}
+	var refs []string
+	if r, ok := cve.(nvdcommon.CVEReferences); ok {
+		refs = r.References()
+	}
+	if description == "" && len(refs) == 0 {
+		return
+	}
+	if description != "" {
+		short := description
+		if i := strings.Index(short, ". "); i != -1 {
+			short = short[:i+1]
+		}
+		if runes := []rune(short); len(runes) > maxShortDescription {
+			short = string(runes[:maxShortDescription-1]) + "…"
+		}
+		r.ShortDescription = &Message{Text: short}
+		r.FullDescription = &Message{Text: description}
+	}
+	text, markdown := []string{description}, []string{description}
+	if len(refs) != 0 {
+		text = append(text, "References:")
+		markdown = append(markdown, "**References**", "")
+		for _, ref := range refs {
+			text = append(text, ref)
+			markdown = append(markdown, fmt.Sprintf("- <%s>", ref))
+		}
+	}
+	r.Help = &Message{
+		Text:     strings.TrimSpace(strings.Join(text, "\n")),
+		Markdown: strings.TrimSpace(strings.Join(markdown, "\n")),
+	}
+}
+
+// tags returns tags of the rule: security and the weaknesses of the CVE (e.g. CWE-79)
+func tags(cve cvefeed.CVEItem) []string {
+	tags := []string{"security"}
+	for _, pt := range cve.ProblemTypes() {
+		if strings.HasPrefix(pt, "CWE-") {
+			tags = append(tags, pt)
+		}
+	}
+	return tags
+}
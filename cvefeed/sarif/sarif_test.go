@@ -0,0 +1,213 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestFromMatchResults(t *testing.T) {
+	items, err := cvefeed.ParseJSON(bytes.NewBufferString(testDict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	foo := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	baz := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "2\\.0"}
+	low := cvss.SeverityLow
+	results := []cvefeed.MatchResult{
+		{CVE: items[0], CPEs: []*wfn.Attributes{foo, baz}},
+		{CVE: items[1], CPEs: []*wfn.Attributes{foo}},
+		{CVE: items[0], CPEs: []*wfn.Attributes{baz}, Rescored: &low},
+		{CVE: items[2], CPEs: []*wfn.Attributes{baz}},
+	}
+	var buf bytes.Buffer
+	if err := FromMatchResults(results, "go.sum").Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var actual, expected interface{}
+	if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(testLog), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected log:\n%s", buf.String())
+	}
+	log := FromMatchResults(results[1:2], "")
+	if loc := log.Runs[0].Results[0].Locations[0]; loc.PhysicalLocation != nil {
+		t.Errorf("expected no physical location without the artifact, got %v", loc.PhysicalLocation)
+	}
+}
+
+func TestRuleDescription(t *testing.T) {
+	items, err := cvefeed.ParseJSON(bytes.NewBufferString(testDictDescribed))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	foo := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	log := FromMatchResults([]cvefeed.MatchResult{{CVE: items[0], CPEs: []*wfn.Attributes{foo}}}, "")
+	r := log.Runs[0].Tool.Driver.Rules[0]
+	if r.ShortDescription == nil || r.ShortDescription.Text != "Buffer overflow in foo bar 1.0." {
+		t.Errorf("unexpected short description %+v", r.ShortDescription)
+	}
+	full := "Buffer overflow in foo bar 1.0. Remote attackers can execute arbitrary code."
+	if r.FullDescription == nil || r.FullDescription.Text != full {
+		t.Errorf("unexpected full description %+v", r.FullDescription)
+	}
+	help := &Message{
+		Text:     full + "\nReferences:\nhttps://example.com/advisory\nhttps://example.com/patch",
+		Markdown: full + "\n**References**\n\n- <https://example.com/advisory>\n- <https://example.com/patch>",
+	}
+	if !reflect.DeepEqual(r.Help, help) {
+		t.Errorf("unexpected help:\nexpected %+v\nactual   %+v", help, r.Help)
+	}
+}
+
+var testDictDescribed = `{"CVE_Items":[
+  {
+    "cve": {
+      "CVE_data_meta": {"ID": "CVE-2020-0004"},
+      "description": {"description_data": [
+        {"lang": "en", "value": "Buffer overflow in foo bar 1.0. Remote attackers can execute arbitrary code."}
+      ]},
+      "references": {"reference_data": [
+        {"url": "https://example.com/advisory"},
+        {"url": "https://example.com/patch"}
+      ]}
+    },
+    "configurations": {"nodes": []}
+  }
+]}`
+
+var testDict = `{"CVE_Items":[
+  {
+    "cve": {
+      "CVE_data_meta": {"ID": "CVE-2020-0001"},
+      "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]}
+    },
+    "configurations": {"nodes": []},
+    "impact": {
+      "baseMetricV3": {"cvssV3": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8}},
+      "baseMetricV2": {"cvssV2": {"version": "2.0", "vectorString": "AV:N/AC:L/Au:N/C:C/I:C/A:C", "baseScore": 10.0}}
+    }
+  },
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0002"}},
+    "configurations": {"nodes": []},
+    "impact": {
+      "baseMetricV2": {"cvssV2": {"version": "2.0", "vectorString": "AV:N/AC:M/Au:N/C:P/I:N/A:N", "baseScore": 4.3}}
+    }
+  },
+  {
+    "cve": {"CVE_data_meta": {"ID": "CVE-2020-0003"}},
+    "configurations": {"nodes": []}
+  }
+]}`
+
+var testLog = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {
+      "name": "nvdtools",
+      "informationUri": "https://github.com/facebookincubator/nvdtools",
+      "rules": [
+        {
+          "id": "CVE-2020-0001",
+          "shortDescription": {"text": "CVE-2020-0001"},
+          "helpUri": "https://nvd.nist.gov/vuln/detail/CVE-2020-0001",
+          "defaultConfiguration": {"level": "error"},
+          "properties": {
+            "tags": ["security", "CWE-79"],
+            "security-severity": "9.8",
+            "cvssScore": 9.8,
+            "cvssSeverity": "Critical",
+            "cvssVector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+          }
+        },
+        {
+          "id": "CVE-2020-0002",
+          "shortDescription": {"text": "CVE-2020-0002"},
+          "helpUri": "https://nvd.nist.gov/vuln/detail/CVE-2020-0002",
+          "defaultConfiguration": {"level": "warning"},
+          "properties": {
+            "tags": ["security"],
+            "security-severity": "4.3",
+            "cvssScore": 4.3,
+            "cvssSeverity": "Medium",
+            "cvssVector": "AV:N/AC:M/Au:N/C:P/I:N/A:N"
+          }
+        },
+        {
+          "id": "CVE-2020-0003",
+          "shortDescription": {"text": "CVE-2020-0003"},
+          "helpUri": "https://nvd.nist.gov/vuln/detail/CVE-2020-0003",
+          "defaultConfiguration": {"level": "none"},
+          "properties": {"tags": ["security"]}
+        }
+      ]
+    }},
+    "results": [
+      {
+        "ruleId": "CVE-2020-0001", "ruleIndex": 0, "level": "error",
+        "message": {"text": "CVE-2020-0001 affects cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"},
+        "locations": [{
+          "physicalLocation": {"artifactLocation": {"uri": "go.sum"}},
+          "logicalLocations": [{"name": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*", "fullyQualifiedName": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*", "kind": "module"}]
+        }]
+      },
+      {
+        "ruleId": "CVE-2020-0001", "ruleIndex": 0, "level": "error",
+        "message": {"text": "CVE-2020-0001 affects cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*"},
+        "locations": [{
+          "physicalLocation": {"artifactLocation": {"uri": "go.sum"}},
+          "logicalLocations": [{"name": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "fullyQualifiedName": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "kind": "module"}]
+        }]
+      },
+      {
+        "ruleId": "CVE-2020-0002", "ruleIndex": 1, "level": "warning",
+        "message": {"text": "CVE-2020-0002 affects cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"},
+        "locations": [{
+          "physicalLocation": {"artifactLocation": {"uri": "go.sum"}},
+          "logicalLocations": [{"name": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*", "fullyQualifiedName": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*", "kind": "module"}]
+        }]
+      },
+      {
+        "ruleId": "CVE-2020-0001", "ruleIndex": 0, "level": "note",
+        "message": {"text": "CVE-2020-0001 affects cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*"},
+        "locations": [{
+          "physicalLocation": {"artifactLocation": {"uri": "go.sum"}},
+          "logicalLocations": [{"name": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "fullyQualifiedName": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "kind": "module"}]
+        }]
+      },
+      {
+        "ruleId": "CVE-2020-0003", "ruleIndex": 2, "level": "none",
+        "message": {"text": "CVE-2020-0003 affects cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*"},
+        "locations": [{
+          "physicalLocation": {"artifactLocation": {"uri": "go.sum"}},
+          "logicalLocations": [{"name": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "fullyQualifiedName": "cpe:2.3:a:foo:baz:2.0:*:*:*:*:*:*:*", "kind": "module"}]
+        }]
+      }
+    ]
+  }]
+}`
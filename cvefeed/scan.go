@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ScanOptions tunes Scan, the zero value scans with the defaults
+type ScanOptions struct {
+	RequireVersion bool        // ignore matching specifications without version, see Cache.RequireVersion
+	Soft           bool        // treat NA attributes of the CPE names as ANY, see Cache.GetSoft
+	Filter         ScoreFilter // report only the findings which pass the filter
+	Order          ResultOrder // order of the findings, the most severe first by default
+	KEV            KEV         // known exploited vulnerabilities to flag the findings with, optional
+}
+
+// Finding is a vulnerability of a CPE name found by Scan
+type Finding struct {
+	CVE         string
+	CPE         string        // the CPE name as it was passed to Scan
+	Score       float64       // representative score of the CVE, see RepresentativeScore
+	Severity    cvss.Severity // severity of the score
+	CVSSVersion string        // CVSS version of the score: "3" or "2", empty if the CVE wasn't scored
+	References  []string      // URLs of advisories, patches etc.
+	// KnownExploited tells the CVE is listed in ScanOptions.KEV; order ByPriority puts such findings first
+	KnownExploited bool
+}
+
+// Scan matches the CPE names against the CVEs of the NVD feed at feedPath and reports the findings,
+// one per CVE and CPE name. It ties together loading (see LoadJSONDictionary, the feed may be gzipped,
+// or a directory, see LoadDirectory), matching and scoring (CVSS v3 preferred, see RepresentativeScore);
+// the CPE names are URI or formatted string bindings, the ones which are the same once normalized
+// are matched once (see wfn.Dedup). Use the granular APIs for anything else.
+func Scan(feedPath string, cpes []string, opts ScanOptions) ([]Finding, error) {
+	var dict Dictionary
+	fi, err := os.Stat(feedPath)
+	if err == nil {
+		if fi.IsDir() {
+			dict, err = LoadDirectory(feedPath)
+		} else {
+			dict, err = LoadJSONDictionary(feedPath)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan: %v", err)
+	}
+
+	names := make(map[wfn.Attributes]string, len(cpes)) // normalized names to the input ones first seen
+	var attrs []*wfn.Attributes
+	for _, s := range cpes {
+		attr, err := wfn.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("scan: bad CPE name %q: %v", s, err)
+		}
+		norm := attr.Normalize()
+		if _, ok := names[*norm]; !ok {
+			names[*norm] = s
+			attrs = append(attrs, norm)
+		}
+	}
+
+	cache := NewCache(dict).SetRequireVersion(opts.RequireVersion).SetMaxSize(-1)
+	var results []MatchResult
+	for _, attr := range attrs {
+		cpe := []*wfn.Attributes{attr}
+		if opts.Soft {
+			results = append(results, cache.GetSoft(cpe)...)
+		} else {
+			results = append(results, cache.Get(cpe)...)
+		}
+	}
+	results = opts.Filter.Apply(results)
+	if opts.KEV != nil {
+		results = opts.KEV.Apply(results)
+	}
+	SortResults(results, opts.Order)
+
+	findings := make([]Finding, 0, len(results))
+	for _, r := range results {
+		score := RepresentativeScore(r.CVE)
+		f := Finding{
+			CVE:            r.CVE.CVEID(),
+			Score:          score.Score,
+			Severity:       score.Severity,
+			CVSSVersion:    score.Version,
+			KnownExploited: r.KnownExploited,
+		}
+		if len(r.CPEs) != 0 && r.CPEs[0] != nil {
+			f.CPE = names[*r.CPEs[0]]
+		}
+		if refs, ok := r.CVE.(nvdcommon.CVEReferences); ok {
+			f.References = refs.References()
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+func TestScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nvdcve-1.1-2020.json.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err = zw.Write([]byte(testJSONdictScan)); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cpes := []string{
+		"cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*",
+		"cpe:/a:Foo:Bar:1.5", // same as the above, once normalized
+		"cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*",
+	}
+	expected := []Finding{
+		{CVE: "CVE-2020-0002", CPE: cpes[0], Score: 9.8, Severity: cvss.SeverityCritical, CVSSVersion: "3",
+			References: []string{"https://example.com/advisory"}},
+		{CVE: "CVE-2020-0001", CPE: cpes[0], Score: 5, Severity: cvss.SeverityMedium, CVSSVersion: "2"},
+		{CVE: "CVE-2020-0001", CPE: cpes[2], Score: 5, Severity: cvss.SeverityMedium, CVSSVersion: "2"},
+	}
+	for _, feedPath := range []string{path, dir} {
+		findings, err := Scan(feedPath, cpes, ScanOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(findings, expected) {
+			t.Errorf("%s: expected %+v, got %+v", feedPath, expected, findings)
+		}
+	}
+
+	findings, err := Scan(path, cpes, ScanOptions{Filter: ScoreFilter{MinSeverity: cvss.SeverityHigh}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].CVE != "CVE-2020-0002" {
+		t.Errorf("expected the critical finding only, got %+v", findings)
+	}
+
+	if _, err = Scan(path, []string{"foo:bar"}, ScanOptions{}); err == nil {
+		t.Error("bad CPE name is expected to be an error")
+	}
+	if _, err = Scan(filepath.Join(dir, "missing.json"), cpes, ScanOptions{}); err == nil {
+		t.Error("missing feed is expected to be an error")
+	}
+}
+
+var testJSONdictScan = `{"CVE_Items":[
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0001"}},
+   "impact": {"baseMetricV2": {"cvssV2": {"baseScore": 5.0, "vectorString": "AV:N/AC:L/Au:N/C:P/I:N/A:N"}}},
+   "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+     {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:*:*:*:*:*:*:*:*:*", "versionEndExcluding": "2.0"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0002"},
+     "references": {"reference_data": [{"url": "https://example.com/advisory"}]}},
+   "impact": {"baseMetricV3": {"cvssV3": {"version": "3.1", "baseScore": 9.8,
+     "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+   "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+     {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*"}]}]}}
+]}`
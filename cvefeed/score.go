@@ -0,0 +1,252 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+// Score is the representative CVSS score of a CVE
+type Score struct {
+	Score        float64
+	Severity     cvss.Severity
+	Version      string // CVSS version of the score: "3" or "2", empty if the CVE wasn't scored
+	SeverityOnly bool   // only the severity rating is known, there's no score nor vector
+}
+
+// MinScore returns the score, or the lowest score of the severity band for severity-only assessments
+func (s Score) MinScore() float64 {
+	if s.SeverityOnly {
+		return s.Severity.MinScore()
+	}
+	return s.Score
+}
+
+// RepresentativeScore selects the highest priority CVSS assessment available for the CVE:
+//  1. CVSS v3.1 primary (NVD) assessment
+//  2. any other CVSS v3 assessment, v3.1 preferred over v3.0
+//  3. CVSS v2 assessment
+//  4. CVSS v3 severity rating, for the sources which provide no vector nor score
+//  5. CVSS v2 severity rating
+//
+// The choice between several v3 assessments of NVD 2.0 feeds happens when the feed is parsed,
+// so only the one made there is considered here.
+// Severity of v2 scores follows NVD v2 qualitative rating, which has no critical severity.
+func RepresentativeScore(cve CVEItem) Score {
+	if score := cve.CVSS30base(); score > 0 {
+		return Score{Score: score, Severity: cvss.SeverityFromScore(score), Version: "3"}
+	}
+	if score := cve.CVSS20base(); score > 0 {
+		return Score{Score: score, Severity: cvss.SeverityFromV2Score(score), Version: "2"}
+	}
+	if cs, ok := cve.(nvdcommon.CVSSSeverities); ok {
+		if s, err := cvss.ParseSeverity(cs.CVSS30severity()); err == nil && s != cvss.SeverityUnknown {
+			return Score{Severity: s, Version: "3", SeverityOnly: true}
+		}
+		if s, err := cvss.ParseSeverity(cs.CVSS20severity()); err == nil && s != cvss.SeverityUnknown {
+			return Score{Severity: s, Version: "2", SeverityOnly: true}
+		}
+	}
+	return Score{Severity: cvss.SeverityNone}
+}
+
+// ConvertedScore returns the base score of the CVE in the CVSS version (see cvss.NewVector): the score of its vector
+// of the major version if it has one, otherwise the score of its v3, v4 or v2 vector, in this order, converted to
+// the version (see cvss.Convert) along with the notes of the conversion, which tell it's lossy.
+// Zero score if the CVE has no vectors.
+func ConvertedScore(cve CVEItem, version string) (float64, []string, error) {
+	vectors := CVSSVectors(cve)
+	major := version
+	if i := strings.IndexByte(major, '.'); i != -1 {
+		major = major[:i]
+	}
+	for _, from := range []string{major, "3", "4", "2"} {
+		vector, ok := vectors[from]
+		if !ok {
+			continue
+		}
+		v, notes, err := cvss.ConvertVector(vector, version)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %v", cve.CVEID(), err)
+		}
+		if v, err = cvss.BaseOnly(v); err != nil {
+			return 0, nil, fmt.Errorf("%s: %v", cve.CVEID(), err)
+		}
+		return v.Score(), notes, nil
+	}
+	return 0, nil, nil
+}
+
+// CVSSVectors returns the CVSS vectors of the CVE keyed by the major version ("2", "3" or "4"), v4 ones come from
+// the assessments; the map is empty if the CVE has no vectors
+func CVSSVectors(cve CVEItem) map[string]string {
+	vectors := make(map[string]string)
+	if cv, ok := cve.(nvdcommon.CVSSVectors); ok {
+		if v := cv.CVSS30vector(); v != "" {
+			vectors["3"] = v
+		}
+		if v := cv.CVSS20vector(); v != "" {
+			vectors["2"] = v
+		}
+	}
+	if ca, ok := cve.(nvdcommon.CVSSAssessments); ok {
+		for _, a := range ca.Assessments() {
+			if strings.HasPrefix(a.Version, "4") && a.Vector != "" {
+				if _, ok := vectors["4"]; !ok {
+					vectors["4"] = a.Vector
+				}
+			}
+		}
+	}
+	return vectors
+}
+
+// Summarize counts match results per severity of their representative score (see RepresentativeScore);
+// results without any CVSS score are counted under cvss.SeverityUnknown, rescored results count under the new severity
+func Summarize(results []MatchResult) map[cvss.Severity]int {
+	summary := make(map[cvss.Severity]int)
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		if r.Rescored != nil {
+			summary[*r.Rescored]++
+			continue
+		}
+		score := RepresentativeScore(r.CVE)
+		if score.Version == "" {
+			summary[cvss.SeverityUnknown]++
+			continue
+		}
+		summary[score.Severity]++
+	}
+	return summary
+}
+
+// ScoreFilter selects match results by the representative score of their CVEs (see RepresentativeScore);
+// the zero value lets everything through
+type ScoreFilter struct {
+	// MinSeverity filters out the results of lower severity, including unscored ones unless it's cvss.SeverityNone
+	MinSeverity cvss.Severity
+	// MinCVSSScore filters out the results of lower score, severity-only assessments score the lowest of their band
+	MinCVSSScore float64
+}
+
+// Apply returns the results which pass the filter, rescored results are judged by their new severity
+func (f ScoreFilter) Apply(results []MatchResult) []MatchResult {
+	if f.MinSeverity <= cvss.SeverityNone && f.MinCVSSScore <= 0 {
+		return results
+	}
+	var filtered []MatchResult
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		score := resultScore(r)
+		if score.Version == "" && f.MinSeverity > cvss.SeverityNone {
+			continue
+		}
+		if score.Severity < f.MinSeverity || score.MinScore() < f.MinCVSSScore {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+// resultScore returns the representative score of the result's CVE, or the severity it was rescored to
+func resultScore(r MatchResult) Score {
+	score := RepresentativeScore(r.CVE)
+	if r.Rescored != nil {
+		score = Score{Severity: *r.Rescored, Version: score.Version, SeverityOnly: true}
+	}
+	return score
+}
+
+// ResultOrder defines the order of match results, see SortResults
+type ResultOrder int
+
+// Possible values of ResultOrder
+const (
+	// BySeverity orders the most severe results first: by severity, then by score, then by CVE ID;
+	// rescored results are ordered by their new severity, unscored results come last
+	BySeverity ResultOrder = iota
+	// ByCVEID orders results by CVE ID
+	ByCVEID
+	// ByPriority orders the results of the highest priority first (see Priority): known exploited CVEs
+	// come first, then the order is the same as BySeverity
+	ByPriority
+)
+
+// SortResults sorts results in place in the given order; the order is deterministic,
+// so the same results are sorted the same way regardless of the order they were matched in
+func SortResults(results []MatchResult, order ResultOrder) {
+	if order != BySeverity && order != ByPriority {
+		sort.SliceStable(results, func(i, j int) bool {
+			return resultID(results[i]) < resultID(results[j])
+		})
+		return
+	}
+	scored := make([]struct {
+		MatchResult
+		score Score
+	}, len(results))
+	for i, r := range results {
+		scored[i].MatchResult = r
+		if r.CVE != nil {
+			scored[i].score = resultScore(r)
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		a, b := scored[i].score, scored[j].score
+		if ka, kb := scored[i].KnownExploited, scored[j].KnownExploited; order == ByPriority && ka != kb {
+			return ka
+		}
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.MinScore() != b.MinScore() {
+			return a.MinScore() > b.MinScore()
+		}
+		return resultID(scored[i].MatchResult) < resultID(scored[j].MatchResult)
+	})
+	for i := range scored {
+		results[i] = scored[i].MatchResult
+	}
+}
+
+// LimitResults returns at most limit of the results, the first ones in the given order,
+// and whether any results were left out; non-positive limit keeps all the results.
+// The results are sorted in a copy, the input slice isn't modified.
+func LimitResults(results []MatchResult, limit int, order ResultOrder) ([]MatchResult, bool) {
+	sorted := append([]MatchResult(nil), results...)
+	SortResults(sorted, order)
+	if limit <= 0 || len(sorted) <= limit {
+		return sorted, false
+	}
+	return sorted[:limit], true
+}
+
+func resultID(r MatchResult) string {
+	if r.CVE == nil {
+		return ""
+	}
+	return r.CVE.CVEID()
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+)
+
+type scoredCVE struct {
+	CVEItem
+	cvss20, cvss30 float64
+}
+
+func (c scoredCVE) CVSS20base() float64 { return c.cvss20 }
+func (c scoredCVE) CVSS30base() float64 { return c.cvss30 }
+
+func TestRepresentativeScore(t *testing.T) {
+	cases := []struct {
+		cvss20, cvss30 float64
+		expected       Score
+	}{
+		{0, 0, Score{Severity: cvss.SeverityNone}},
+		{5.0, 9.8, Score{9.8, cvss.SeverityCritical, "3", false}},
+		{9.3, 0, Score{9.3, cvss.SeverityHigh, "2", false}},
+		{4.3, 0, Score{4.3, cvss.SeverityMedium, "2", false}},
+		{0, 3.1, Score{3.1, cvss.SeverityLow, "3", false}},
+	}
+	for _, c := range cases {
+		if s := RepresentativeScore(scoredCVE{cvss20: c.cvss20, cvss30: c.cvss30}); s != c.expected {
+			t.Errorf("v2 %.1f, v3 %.1f: expected %+v, got %+v", c.cvss20, c.cvss30, c.expected, s)
+		}
+	}
+}
+
+type vectorCVE struct {
+	CVEItem
+	cvss20, cvss30 string
+	assessments    []nvdcommon.CVSSAssessment
+}
+
+func (c vectorCVE) CVEID() string                           { return "CVE-2020-0001" }
+func (c vectorCVE) CVSS20vector() string                    { return c.cvss20 }
+func (c vectorCVE) CVSS30vector() string                    { return c.cvss30 }
+func (c vectorCVE) Assessments() []nvdcommon.CVSSAssessment { return c.assessments }
+
+func TestConvertedScore(t *testing.T) {
+	v4 := []nvdcommon.CVSSAssessment{{Version: "4.0", Vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}}
+	cases := []struct {
+		cve     vectorCVE
+		version string
+		score   float64
+		lossy   bool
+	}{
+		{vectorCVE{}, "3.1", 0, false},
+		{vectorCVE{cvss20: "AV:N/AC:L/Au:N/C:C/I:C/A:C"}, "3.1", 9.8, true},
+		{vectorCVE{cvss20: "AV:N/AC:L/Au:N/C:C/I:C/A:C", cvss30: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N"}, "3.1", 6.5, false},
+		{vectorCVE{cvss20: "AV:N/AC:L/Au:N/C:C/I:C/A:C", cvss30: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N"}, "2.0", 10.0, false},
+		{vectorCVE{cvss30: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", assessments: v4}, "4.0", 9.3, false},
+		{vectorCVE{assessments: v4}, "3.1", 9.8, false},
+	}
+	for i, c := range cases {
+		score, notes, err := ConvertedScore(c.cve, c.version)
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+		if score != c.score || (len(notes) != 0) != c.lossy {
+			t.Errorf("case %d: expected %.1f (lossy %t), got %.1f %q", i, c.score, c.lossy, score, notes)
+		}
+	}
+	if _, _, err := ConvertedScore(vectorCVE{cvss20: "AV:N"}, "3.1"); err == nil {
+		t.Error("expected incomplete vector to fail")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []MatchResult{
+		{CVE: scoredCVE{cvss30: 9.8}},
+		{CVE: scoredCVE{cvss20: 9.3, cvss30: 9.1}},
+		{CVE: scoredCVE{cvss20: 9.3}},
+		{CVE: scoredCVE{cvss20: 2.1}},
+		{CVE: scoredCVE{}},
+		{},
+	}
+	expected := map[cvss.Severity]int{
+		cvss.SeverityCritical: 2,
+		cvss.SeverityHigh:     1,
+		cvss.SeverityLow:      1,
+		cvss.SeverityUnknown:  1,
+	}
+	if summary := Summarize(results); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected %v, got %v", expected, summary)
+	}
+}
+
+type ratedCVE struct {
+	scoredCVE
+	severity20, severity30 string
+}
+
+func (c ratedCVE) CVSS20severity() string { return c.severity20 }
+func (c ratedCVE) CVSS30severity() string { return c.severity30 }
+
+func TestRepresentativeScoreSeverityOnly(t *testing.T) {
+	cases := []struct {
+		cve      ratedCVE
+		expected Score
+	}{
+		{ratedCVE{scoredCVE{}, "", "HIGH"}, Score{Severity: cvss.SeverityHigh, Version: "3", SeverityOnly: true}},
+		{ratedCVE{scoredCVE{}, "MEDIUM", ""}, Score{Severity: cvss.SeverityMedium, Version: "2", SeverityOnly: true}},
+		{ratedCVE{scoredCVE{cvss20: 9.3}, "HIGH", "LOW"}, Score{9.3, cvss.SeverityHigh, "2", false}}, // scores come first
+		{ratedCVE{scoredCVE{}, "", "bogus"}, Score{Severity: cvss.SeverityNone}},
+	}
+	for _, c := range cases {
+		if s := RepresentativeScore(c.cve); s != c.expected {
+			t.Errorf("%+v: expected %+v, got %+v", c.cve, c.expected, s)
+		}
+	}
+}
+
+func TestScoreFilter(t *testing.T) {
+	high := cvss.SeverityHigh
+	results := []MatchResult{
+		{CVE: scoredCVE{cvss30: 9.8}},
+		{CVE: scoredCVE{cvss30: 6.5}},
+		{CVE: scoredCVE{cvss20: 7.5}},
+		{CVE: ratedCVE{severity30: "HIGH"}},
+		{CVE: ratedCVE{severity30: "LOW"}},
+		{CVE: scoredCVE{}},
+		{CVE: scoredCVE{cvss30: 3.1}, Rescored: &high},
+	}
+	cases := []struct {
+		filter   ScoreFilter
+		expected []int
+	}{
+		{ScoreFilter{}, []int{0, 1, 2, 3, 4, 5, 6}},
+		{ScoreFilter{MinSeverity: cvss.SeverityHigh}, []int{0, 2, 3, 6}},
+		{ScoreFilter{MinCVSSScore: 7.0}, []int{0, 2, 3, 6}},
+		{ScoreFilter{MinCVSSScore: 7.5}, []int{0, 2}},
+		{ScoreFilter{MinSeverity: cvss.SeverityLow}, []int{0, 1, 2, 3, 4, 6}},
+	}
+	for _, c := range cases {
+		var expected []MatchResult
+		for _, i := range c.expected {
+			expected = append(expected, results[i])
+		}
+		if filtered := c.filter.Apply(results); !reflect.DeepEqual(filtered, expected) {
+			t.Errorf("%+v: expected %d results, got %d: %+v", c.filter, len(expected), len(filtered), filtered)
+		}
+	}
+}
+
+type namedCVE struct {
+	scoredCVE
+	id string
+}
+
+func (c namedCVE) CVEID() string { return c.id }
+
+func TestLimitResults(t *testing.T) {
+	low := cvss.SeverityLow
+	results := []MatchResult{
+		{CVE: namedCVE{scoredCVE{cvss30: 6.5}, "CVE-2020-0001"}},
+		{CVE: namedCVE{scoredCVE{}, "CVE-2020-0002"}},
+		{CVE: namedCVE{scoredCVE{cvss30: 9.8}, "CVE-2020-0003"}},
+		{CVE: namedCVE{scoredCVE{cvss20: 7.5}, "CVE-2020-0004"}},
+		{CVE: namedCVE{scoredCVE{cvss30: 9.8}, "CVE-2020-0000"}},
+		{CVE: namedCVE{scoredCVE{cvss30: 9.1}, "CVE-2020-0005"}, Rescored: &low},
+	}
+	ids := func(results []MatchResult) []string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.CVE.CVEID())
+		}
+		return ids
+	}
+	cases := []struct {
+		limit     int
+		order     ResultOrder
+		expected  []string
+		truncated bool
+	}{
+		{0, BySeverity, []string{"CVE-2020-0000", "CVE-2020-0003", "CVE-2020-0004", "CVE-2020-0001", "CVE-2020-0005", "CVE-2020-0002"}, false},
+		{3, BySeverity, []string{"CVE-2020-0000", "CVE-2020-0003", "CVE-2020-0004"}, true},
+		{6, BySeverity, []string{"CVE-2020-0000", "CVE-2020-0003", "CVE-2020-0004", "CVE-2020-0001", "CVE-2020-0005", "CVE-2020-0002"}, false},
+		{2, ByCVEID, []string{"CVE-2020-0000", "CVE-2020-0001"}, true},
+	}
+	for _, c := range cases {
+		in := append([]MatchResult(nil), results...)
+		limited, truncated := LimitResults(in, c.limit, c.order)
+		if got := ids(limited); !reflect.DeepEqual(got, c.expected) || truncated != c.truncated {
+			t.Errorf("limit %d, order %d: expected %v (truncated %t), got %v (truncated %t)", c.limit, c.order, c.expected, c.truncated, got, truncated)
+		}
+		if !reflect.DeepEqual(in, results) {
+			t.Errorf("limit %d, order %d: input results were modified", c.limit, c.order)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gRPC method paths of service nvdtools.cveserver.CVEServer, see cveserver.proto
+const (
+	grpcMatch  = "/nvdtools.cveserver.CVEServer/Match"
+	grpcStatus = "/nvdtools.cveserver.CVEServer/Status"
+)
+
+// gRPC status codes answered by the server
+const (
+	grpcOK                = 0
+	grpcInvalidArgument   = 3
+	grpcResourceExhausted = 8
+	grpcUnimplemented     = 12
+	grpcInternal          = 13
+)
+
+// GRPCHandler returns the handler of the gRPC service of the server, nvdtools.cveserver.CVEServer described by
+// cveserver.proto: unary Match and Status calls answering as Server.Match and Server.Status do. Messages must be
+// uncompressed. gRPC runs over HTTP/2 only, so the handler has to be served with HTTP/2, e.g. by http.Server
+// whose Protocols allow unencrypted HTTP/2 for plaintext clients; HTTP/1 requests are rejected.
+func (s *Server) GRPCHandler() http.Handler {
+	return http.HandlerFunc(s.serveGRPC)
+}
+
+func (s *Server) serveGRPC(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/grpc" && !strings.HasPrefix(ct, "application/grpc+proto") {
+		http.Error(w, fmt.Sprintf("content type %q not supported", ct), http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message") // the status of the call follows the message
+	w.WriteHeader(http.StatusOK)
+	msg, code, err := s.grpcCall(r)
+	if err == nil {
+		if err = writeGRPCMessage(w, msg); err != nil {
+			code = grpcInternal
+		}
+	}
+	if err != nil {
+		w.Header().Set("Grpc-Message", grpcPercentEncode(err.Error()))
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+}
+
+// grpcCall answers the call of the request with the message of the response; the error comes with its gRPC
+// status code
+func (s *Server) grpcCall(r *http.Request) ([]byte, int, error) {
+	if r.URL.Path != grpcMatch && r.URL.Path != grpcStatus {
+		return nil, grpcUnimplemented, fmt.Errorf("unknown method %s", r.URL.Path)
+	}
+	msg, code, err := readGRPCMessage(r.Body)
+	if err != nil {
+		return nil, code, err
+	}
+	if r.URL.Path == grpcStatus {
+		return marshalStatus(s.Status()), grpcOK, nil
+	}
+	req, err := unmarshalMatchRequest(msg)
+	if err != nil {
+		return nil, grpcInvalidArgument, fmt.Errorf("couldn't decode request: %v", err)
+	}
+	rec, err := s.Match(req)
+	if err != nil {
+		return nil, grpcInvalidArgument, err
+	}
+	return marshalScanRecord(rec), grpcOK, nil
+}
+
+// readGRPCMessage reads the only message of the request from the body; the error comes with its gRPC status code
+func readGRPCMessage(body io.Reader) ([]byte, int, error) {
+	var prefix [5]byte // compressed flag and big endian length
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, grpcInvalidArgument, fmt.Errorf("couldn't read request: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, grpcUnimplemented, fmt.Errorf("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxRequestSize {
+		return nil, grpcResourceExhausted, fmt.Errorf("request of %d bytes exceeds %d bytes", size, maxRequestSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return nil, grpcInvalidArgument, fmt.Errorf("couldn't read request: %v", err)
+	}
+	return msg, grpcOK, nil
+}
+
+// writeGRPCMessage writes the message of the response, uncompressed
+func writeGRPCMessage(w io.Writer, msg []byte) error {
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(msg)))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(msg)
+	return err
+}
+
+// grpcPercentEncode encodes grpc-message as gRPC over HTTP/2 requires: bytes other than printable ASCII and %
+// are percent-encoded
+func grpcPercentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// grpcTestCall calls the method with the message, returning the message of the response and the gRPC status
+func grpcTestCall(t *testing.T, client *http.Client, url string, msg []byte) ([]byte, string, string) {
+	t.Helper()
+	body := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(append(body, msg...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body) // the trailers come after the body
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2 status 200, got %s %d", resp.Proto, resp.StatusCode)
+	}
+	if len(data) != 0 {
+		if len(data) < 5 || int(binary.BigEndian.Uint32(data[1:5])) != len(data)-5 {
+			t.Fatalf("malformed response message % x", data)
+		}
+		data = data[5:]
+	}
+	return data, resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+func TestGRPCHandler(t *testing.T) {
+	s, _, cleanup := testServer(t)
+	defer cleanup()
+	ts := httptest.NewUnstartedServer(s.GRPCHandler())
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	req := appendStrings(nil, 1, []string{"cpe:/a:acme:widget:1.0"})
+	msg, status, _ := grpcTestCall(t, client, ts.URL+grpcMatch, req)
+	if status != "0" {
+		t.Fatalf("Match: expected status 0, got %s", status)
+	}
+	var ids, severities []string
+	err := readProto(msg, func(f protoField) error {
+		if f.num != 2 {
+			return nil
+		}
+		return readProto(f.data, func(f protoField) error {
+			switch f.num {
+			case 1:
+				ids = append(ids, string(f.data))
+			case 6:
+				severities = append(severities, string(f.data))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Match: couldn't decode response: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "CVE-2020-0002" || ids[1] != "CVE-2020-0001" || severities[0] != "Critical" {
+		t.Errorf("Match: expected the most severe CVE first, got %v of %v", ids, severities)
+	}
+
+	msg, status, _ = grpcTestCall(t, client, ts.URL+grpcMatch, appendUint(req, 3, 1))
+	if n := bytes.Count(msg, []byte("CVE-2020-")); status != "0" || n != 1 {
+		t.Errorf("Match: expected 1 CVE of limit 1, got %d, status %s", n, status)
+	}
+
+	msg, status, _ = grpcTestCall(t, client, ts.URL+grpcStatus, nil)
+	var cves uint64
+	readProto(msg, func(f protoField) error {
+		if f.num == 2 {
+			cves = f.v
+		}
+		return nil
+	})
+	if status != "0" || cves != 2 {
+		t.Errorf("Status: expected 2 CVEs, got %d, status %s", cves, status)
+	}
+
+	for _, c := range []struct {
+		method string
+		msg    []byte
+		status string
+	}{
+		{grpcMatch, nil, "3"}, // no CPE names
+		{grpcMatch, appendStrings(nil, 1, []string{"bogus"}), "3"},
+		{grpcMatch, []byte{0x0a, 0x10}, "3"}, // truncated
+		{"/nvdtools.cveserver.CVEServer/Scan", nil, "12"},
+	} {
+		msg, status, message := grpcTestCall(t, client, ts.URL+c.method, c.msg)
+		if status != c.status || message == "" || len(msg) != 0 {
+			t.Errorf("%s % x: expected status %s with message, got %s %q", c.method, c.msg, c.status, status, message)
+		}
+	}
+
+	resp, err := http.Post(ts.URL+grpcStatus, "application/grpc", bytes.NewReader(make([]byte, 5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusHTTPVersionNotSupported {
+		t.Errorf("HTTP/1: expected status %d, got %d", http.StatusHTTPVersionNotSupported, resp.StatusCode)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// Protocol buffers wire format of the messages of cveserver.proto, see Server.GRPCHandler
+
+// wire types of protocol buffers
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("truncated message")
+
+func appendVarint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendBytes appends the field unless it's empty, as proto3 does for strings and messages
+func appendBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendString(b []byte, field int, v string) []byte {
+	return appendBytes(b, field, []byte(v))
+}
+
+func appendStrings(b []byte, field int, vs []string) []byte {
+	for _, v := range vs {
+		b = appendTag(b, field, wireBytes)
+		b = appendVarint(b, uint64(len(v)))
+		b = append(b, v...)
+	}
+	return b
+}
+
+func appendUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendVarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendUint(b, field, 1)
+}
+
+func appendDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), math.Float64bits(v))
+}
+
+// protoField is a field of an encoded message: varint and fixed values are in num, bytes in data
+type protoField struct {
+	num  int
+	wire int
+	v    uint64
+	data []byte
+}
+
+// readProto calls fn for every field of the encoded message, in the order of the encoding
+func readProto(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		b = b[n:]
+		f := protoField{num: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case wireVarint:
+			if f.v, n = binary.Uvarint(b); n <= 0 {
+				return errProtoTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errProtoTruncated
+			}
+			f.v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errProtoTruncated
+			}
+			f.v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return errProtoTruncated
+			}
+			f.data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			return errors.New("unsupported wire type")
+		}
+		if f.num == 0 {
+			return errors.New("field number 0")
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalMatchRequest decodes MatchRequest message, skipping unknown fields
+func unmarshalMatchRequest(b []byte) (*MatchRequest, error) {
+	var req MatchRequest
+	err := readProto(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.wire == wireBytes:
+			req.CPEs = append(req.CPEs, string(f.data))
+		case f.num == 2 && f.wire == wireVarint:
+			req.Soft = f.v != 0
+		case f.num == 3 && f.wire == wireVarint:
+			req.Limit = int(int32(f.v))
+		case f.num <= 3:
+			return errors.New("field of wrong wire type")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// marshalScanRecord encodes the record as ScanRecord message
+func marshalScanRecord(rec *cvefeed.ScanRecord) []byte {
+	b := appendStrings(nil, 1, rec.Input)
+	for i := range rec.Vulnerabilities {
+		v := &rec.Vulnerabilities[i]
+		m := appendString(nil, 1, v.CVE)
+		m = appendStrings(m, 2, v.Matches)
+		m = appendStrings(m, 3, v.Platforms)
+		m = appendStrings(m, 4, v.CWEs)
+		m = appendDouble(m, 5, v.Score)
+		m = appendString(m, 6, v.Severity)
+		m = appendString(m, 7, v.CVSSVersion)
+		m = appendDouble(m, 8, v.CVSS2)
+		m = appendDouble(m, 9, v.CVSS3)
+		m = appendBool(m, 10, v.KnownExploited)
+		m = appendString(m, 11, v.Status)
+		m = appendString(m, 12, protoTime(v.Published))
+		m = appendString(m, 13, protoTime(v.LastModified))
+		m = appendStrings(m, 14, v.References)
+		m = appendString(m, 15, v.Description)
+		// the message of a CVE is never empty, so it's always appended
+		b = appendBytes(b, 2, m)
+	}
+	return b
+}
+
+// marshalStatus encodes the status as Status message
+func marshalStatus(st Status) []byte {
+	b := appendStrings(nil, 1, st.Feeds)
+	b = appendUint(b, 2, uint64(st.CVEs))
+	return appendString(b, 3, protoTime(&st.LoadedAt))
+}
+
+func protoTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
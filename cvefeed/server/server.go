@@ -0,0 +1,290 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server answers CPE to CVE match queries over HTTP and gRPC from the CVE feeds held in memory,
+// reloading the feeds as their files change, so the feeds are loaded once rather than on every scan.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Config configures the server
+type Config struct {
+	// Feeds are the paths of the vulnerability feeds
+	Feeds []string
+	// Overrides are the paths of the feeds overriding them, see cvefeed.Dictionary.Override
+	Overrides []string
+	// Load loads the feeds, cvefeed.LoadJSONDictionary if nil
+	Load func(paths ...string) (cvefeed.Dictionary, error)
+	// SkipRejected drops rejected CVEs from the feeds, see cvefeed.Dictionary.DropRejected
+	SkipRejected bool
+	// Indexed builds an index of the feeds, see cvefeed.NewIndex
+	Indexed bool
+	// Setup configures the caches built of the feeds, e.g. cache.SetRequireVersion(true); optional
+	Setup func(cache *cvefeed.Cache)
+	// Logger receives the errors of reloading the feeds, see Watch; optional
+	Logger cvefeed.Logger
+}
+
+// MatchRequest is the query of the CVEs matching the CPE names of the inventory
+type MatchRequest struct {
+	CPEs []string `json:"cpes"`
+	// Soft treats NA attributes of the CPE names (except part, vendor and product) as ANY
+	Soft bool `json:"soft,omitempty"`
+	// Limit is the number of the most severe CVEs answered, 0 answers all of them
+	Limit int `json:"limit,omitempty"`
+}
+
+// Status describes the feeds the server answers from
+type Status struct {
+	Feeds    []string  `json:"feeds"`
+	CVEs     int       `json:"cves"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// Server holds the cache of the feeds and answers match queries, it's safe for concurrent use
+type Server struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	cache  *cvefeed.Cache
+	status Status
+	stamps map[string]stamp
+}
+
+// stamp identifies the version of the feed file
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New returns the server of the feeds, loading them
+func New(cfg Config) (*Server, error) {
+	if len(cfg.Feeds) == 0 {
+		return nil, fmt.Errorf("server: no feeds")
+	}
+	if cfg.Load == nil {
+		cfg.Load = cvefeed.LoadJSONDictionary
+	}
+	s := &Server{cfg: cfg}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload loads the feeds and replaces the cache of the server with the one of the new feeds; queries are answered
+// from the former feeds while loading, and keep being answered from them if it fails
+func (s *Server) Reload() error {
+	stamps, err := s.stat()
+	if err != nil {
+		return err
+	}
+	dict, err := s.cfg.Load(s.cfg.Feeds...)
+	if err != nil {
+		return fmt.Errorf("server: couldn't load feeds: %v", err)
+	}
+	if len(s.cfg.Overrides) != 0 {
+		overrides, err := s.cfg.Load(s.cfg.Overrides...)
+		if err != nil {
+			return fmt.Errorf("server: couldn't load overrides: %v", err)
+		}
+		dict.Override(overrides)
+	}
+	if s.cfg.SkipRejected {
+		dict.DropRejected()
+	}
+	cache := cvefeed.NewCache(dict)
+	if s.cfg.Setup != nil {
+		s.cfg.Setup(cache)
+	}
+	if s.cfg.Indexed {
+		cache.Idx = cvefeed.NewIndex(dict)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache, s.stamps = cache, stamps
+	s.status = Status{
+		Feeds:    append([]string(nil), s.cfg.Feeds...),
+		CVEs:     len(dict),
+		LoadedAt: time.Now().UTC(),
+	}
+	return nil
+}
+
+// stat returns the stamps of the feed files
+func (s *Server) stat() (map[string]stamp, error) {
+	stamps := make(map[string]stamp)
+	for _, paths := range [][]string{s.cfg.Feeds, s.cfg.Overrides} {
+		for _, path := range paths {
+			fi, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("server: %v", err)
+			}
+			stamps[path] = stamp{modTime: fi.ModTime(), size: fi.Size()}
+		}
+	}
+	return stamps, nil
+}
+
+// Changed tells if any feed file changed since the feeds were loaded; feed files which can't be read,
+// e.g. while they're being replaced, are an error
+func (s *Server) Changed() (bool, error) {
+	stamps, err := s.stat()
+	if err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for path, st := range stamps {
+		if s.stamps[path] != st {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Watch checks the feed files every interval and reloads the feeds once they change, until ctx is done;
+// errors are reported to the logger of the config and the feeds are checked again on the next interval
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		changed, err := s.Changed()
+		if err == nil && changed {
+			err = s.Reload()
+		}
+		if err != nil && s.cfg.Logger != nil {
+			s.cfg.Logger.Warnf("%v", err)
+		}
+	}
+}
+
+// Status returns the status of the server
+func (s *Server) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Match returns the CVEs matching the CPE names of the request, the most severe first; it's the query
+// every transport answers, see Handler and GRPCHandler
+func (s *Server) Match(req *MatchRequest) (*cvefeed.ScanRecord, error) {
+	if len(req.CPEs) == 0 {
+		return nil, fmt.Errorf("no CPE names requested")
+	}
+	cpes := make([]*wfn.Attributes, len(req.CPEs))
+	for i, name := range req.CPEs {
+		attr, err := wfn.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse CPE name %q: %v", name, err)
+		}
+		cpes[i] = attr
+	}
+	s.mu.RLock()
+	cache := s.cache
+	s.mu.RUnlock()
+	var results []cvefeed.MatchResult
+	if req.Soft {
+		results = cache.GetSoft(cpes)
+	} else {
+		results = cache.Get(cpes)
+	}
+	results, _ = cvefeed.LimitResults(results, req.Limit, cvefeed.BySeverity) // sorts a copy of the cached results
+	rec := &cvefeed.ScanRecord{Input: req.CPEs, Vulnerabilities: make([]cvefeed.MatchRecord, len(results))}
+	for i, r := range results {
+		rec.Vulnerabilities[i] = cvefeed.NewMatchRecord(r)
+	}
+	return rec, nil
+}
+
+// Handler returns HTTP handler of the server:
+//
+//	GET /match?cpe=...&cpe=...[&soft=true][&limit=N] or POST /match with MatchRequest JSON
+//	GET /status
+//
+// Match queries are answered with cvefeed.ScanRecord JSON, the status with Status JSON
+// and errors with {"error": "..."} JSON.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/match", s.serveMatch)
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	return mux
+}
+
+// maxRequestSize limits the size of POST'ed match requests
+const maxRequestSize = 1 << 20
+
+func (s *Server) serveMatch(w http.ResponseWriter, r *http.Request) {
+	var req MatchRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.CPEs = q["cpe"]
+		req.Soft = q.Get("soft") == "true"
+		if limit := q.Get("limit"); limit != "" {
+			var err error
+			if req.Limit, err = strconv.Atoi(limit); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("limit is invalid: %v", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("couldn't decode request: %v", err))
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	rec, err := s.Match(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testFeed = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2020-0001",
+		"metrics":{"cvssMetricV31":[{"cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:N/A:N","baseScore":5.3}}]},
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}},
+	{"cve":{"id":"CVE-2020-0002",
+		"metrics":{"cvssMetricV31":[{"cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8}}]},
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+
+const testFeedUpdated = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2020-0003",
+		"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:gadget:2.0:*:*:*:*:*:*:*"}]}]}]}}]}`
+
+func testServer(t *testing.T) (*Server, string, func()) {
+	dir, err := ioutil.TempDir("", "cvefeedserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testFeed), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	s, err := New(Config{Feeds: []string{feed}})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, feed, func() { os.RemoveAll(dir) }
+}
+
+func cveIDs(rec *cvefeed.ScanRecord) []string {
+	var ids []string
+	for _, v := range rec.Vulnerabilities {
+		ids = append(ids, v.CVE)
+	}
+	return ids
+}
+
+func TestMatch(t *testing.T) {
+	s, _, cleanup := testServer(t)
+	defer cleanup()
+	rec, err := s.Match(&MatchRequest{CPEs: []string{"cpe:/a:acme:widget:1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := cveIDs(rec); len(ids) != 2 || ids[0] != "CVE-2020-0002" || ids[1] != "CVE-2020-0001" {
+		t.Errorf("expected the most severe CVE first, got %v", ids)
+	}
+	rec, err = s.Match(&MatchRequest{CPEs: []string{"cpe:/a:acme:widget:1.0"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := cveIDs(rec); len(ids) != 1 || ids[0] != "CVE-2020-0002" {
+		t.Errorf("expected the most severe CVE only, got %v", ids)
+	}
+	rec, err = s.Match(&MatchRequest{CPEs: []string{"cpe:/a:acme:gadget:1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Vulnerabilities == nil || len(rec.Vulnerabilities) != 0 {
+		t.Errorf("expected no vulnerabilities, got %+v", rec.Vulnerabilities)
+	}
+	for _, req := range []*MatchRequest{{}, {CPEs: []string{"cpe:2.3:a:acme"}}} {
+		if _, err := s.Match(req); err == nil {
+			t.Errorf("expected request %+v to fail", req)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	s, feed, cleanup := testServer(t)
+	defer cleanup()
+	if changed, err := s.Changed(); err != nil || changed {
+		t.Fatalf("expected the feed unchanged, got %v, %v", changed, err)
+	}
+	loaded := s.Status().LoadedAt
+	if err := ioutil.WriteFile(feed, []byte(testFeedUpdated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := s.Changed(); err != nil || !changed {
+		t.Fatalf("expected the feed changed, got %v, %v", changed, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Watch(ctx, time.Millisecond)
+		close(done)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); s.Status().LoadedAt.Equal(loaded); {
+		if time.Now().After(deadline) {
+			t.Fatal("the feed wasn't reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if st := s.Status(); st.CVEs != 1 {
+		t.Errorf("expected 1 CVE reloaded, got %+v", st)
+	}
+	rec, err := s.Match(&MatchRequest{CPEs: []string{"cpe:/a:acme:gadget:2.0", "cpe:/a:acme:widget:1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := cveIDs(rec); len(ids) != 1 || ids[0] != "CVE-2020-0003" {
+		t.Errorf("expected the CVE of the reloaded feed, got %v", ids)
+	}
+
+	// failed reload keeps the feeds loaded
+	if err := ioutil.WriteFile(feed, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Error("expected reload of malformed feed to fail")
+	}
+	if st := s.Status(); st.CVEs != 1 {
+		t.Errorf("expected the former feed kept, got %+v", st)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s, _, cleanup := testServer(t)
+	defer cleanup()
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	decode := func(resp *http.Response, err error, status int, v interface{}) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("expected status %d, got %d", status, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rec cvefeed.ScanRecord
+	resp, err := http.Get(ts.URL + "/match?" + url.Values{"cpe": {"cpe:/a:acme:widget:1.0"}, "limit": {"1"}}.Encode())
+	decode(resp, err, http.StatusOK, &rec)
+	if ids := cveIDs(&rec); len(ids) != 1 || ids[0] != "CVE-2020-0002" {
+		t.Errorf("GET: unexpected CVEs %v", ids)
+	}
+
+	rec = cvefeed.ScanRecord{}
+	body, _ := json.Marshal(MatchRequest{CPEs: []string{"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"}})
+	resp, err = http.Post(ts.URL+"/match", "application/json", bytes.NewReader(body))
+	decode(resp, err, http.StatusOK, &rec)
+	if ids := cveIDs(&rec); len(ids) != 2 {
+		t.Errorf("POST: unexpected CVEs %v", ids)
+	}
+
+	var e map[string]string
+	resp, err = http.Post(ts.URL+"/match", "application/json", bytes.NewBufferString("{"))
+	decode(resp, err, http.StatusBadRequest, &e)
+	if e["error"] == "" {
+		t.Errorf("expected error, got %v", e)
+	}
+
+	var st Status
+	resp, err = http.Get(ts.URL + "/status")
+	decode(resp, err, http.StatusOK, &st)
+	if st.CVEs != 2 || len(st.Feeds) != 1 || st.LoadedAt.IsZero() {
+		t.Errorf("unexpected status %+v", st)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// CVESource provides the matcher with CVEs to match CPE names against, e.g. from a database
+// for corpora too large to be held in memory; see Cache.Source.
+// Dictionary and Index are the in-memory implementations.
+type CVESource interface {
+	// CPECandidates returns the CVEs which may match the CPE name; returning CVEs which don't match is fine,
+	// they're filtered out by the matcher, but the CVEs which match must not be left out
+	CPECandidates(cpe *wfn.Attributes) []CVEItem
+}
+
+// CPECandidates implements CVESource: all the CVEs of the dictionary are candidates.
+// Cache matches against Dict directly, without collecting the candidates.
+func (d Dictionary) CPECandidates(_ *wfn.Attributes) []CVEItem {
+	items := make([]CVEItem, 0, len(d))
+	for _, cve := range d {
+		items = append(items, cve)
+	}
+	return items
+}
+
+// CPECandidates implements CVESource: the CVEs indexed by the product of cpe and the ones which match any product
+func (idx Index) CPECandidates(cpe *wfn.Attributes) []CVEItem {
+	var items []CVEItem
+	if cpe != nil && cpe.Product != wfn.Any {
+		items = append(items, idx[cpe.Product]...)
+	}
+	return append(items, idx[wfn.Any]...)
+}
+
+// dictFromSource creates CVE dictionary from the candidates of the source for all CPE names
+func dictFromSource(src CVESource, cpes []*wfn.Attributes, log Logger) Dictionary {
+	d := Dictionary{}
+	for _, cpe := range cpes {
+		if cpe == nil { // should never happen
+			log.Warnf("nil CPE in list")
+			continue
+		}
+		for _, e := range src.CPECandidates(cpe) {
+			if e != nil {
+				d[e.CVEID()] = e
+			}
+		}
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// countingSource counts the lookups of the CPE names
+type countingSource struct {
+	Index
+	lookups int
+}
+
+func (s *countingSource) CPECandidates(cpe *wfn.Attributes) []CVEItem {
+	s.lookups++
+	return s.Index.CPECandidates(cpe)
+}
+
+func TestCVESource(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictFirmware))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "h", Vendor: "cisco", Product: "rv320"},
+		{Part: "o", Vendor: "cisco", Product: "rv320_firmware", Version: "1\\.4\\.2\\.17"},
+	}
+	other := []*wfn.Attributes{{Part: "a", Vendor: "gnu", Product: "glibc", Version: "2\\.28"}}
+	src := &countingSource{Index: NewIndex(dict)}
+	sources := map[string]CVESource{"dictionary": dict, "index": NewIndex(dict), "custom": src}
+	for name, s := range sources {
+		cache := NewCache(nil).SetSource(s)
+		if res := cache.Get(inventory); len(res) != 1 || res[0].CVE.CVEID() != "CVE-2019-1653" || len(res[0].CPEs) != 2 {
+			t.Errorf("%s: expected CVE-2019-1653 to match both CPEs, got %+v", name, res)
+		}
+		if res := cache.Get(other); len(res) != 0 {
+			t.Errorf("%s: expected no match, got %+v", name, res)
+		}
+	}
+	if src.lookups != len(inventory)+len(other) {
+		t.Errorf("expected a lookup per CPE name, got %d", src.lookups)
+	}
+	// the source takes precedence over the dictionary
+	if res := NewCache(dict).SetSource(Index{}).Get(inventory); len(res) != 0 {
+		t.Errorf("expected no match from empty source, got %+v", res)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+)
+
+// CVEStatus returns the status of the vulnerability as per nvdcommon.CVEStatus, e.g. Analyzed, Awaiting Analysis
+// or Rejected; empty string if unknown
+func CVEStatus(cve CVEItem) string {
+	if s, ok := cve.(nvdcommon.CVEStatus); ok {
+		return s.Status()
+	}
+	return ""
+}
+
+// VendorComments returns the statements of vendors on the vulnerability as per nvdcommon.CVEStatus
+func VendorComments(cve CVEItem) []nvdcommon.VendorComment {
+	if s, ok := cve.(nvdcommon.CVEStatus); ok {
+		return s.VendorComments()
+	}
+	return nil
+}
+
+// IsRejected tells the vulnerability was rejected (withdrawn) in the source; the status is compared insensitive
+// to lexical case, for CVE JSON 5.x records state REJECTED
+func IsRejected(cve CVEItem) bool {
+	return strings.EqualFold(CVEStatus(cve), "Rejected")
+}
+
+// DropRejected removes rejected CVEs (see IsRejected) from the dictionary and returns the number of them
+func (d Dictionary) DropRejected() int {
+	var n int
+	for id, cve := range d {
+		if IsRejected(cve) {
+			delete(d, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"testing"
+)
+
+const testJSONdictStatus = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+	{"cve":{"id":"CVE-2020-0001","vulnStatus":"Analyzed","vendorComments":[{"organization":"Red Hat","comment":"Not vulnerable."}]}},
+	{"cve":{"id":"CVE-2020-0002","vulnStatus":"Rejected"}},
+	{"cve":{"id":"CVE-2020-0003","vulnStatus":"Awaiting Analysis"}}]}`
+
+func TestDropRejected(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictStatus))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	if status := CVEStatus(dict["CVE-2020-0003"]); status != "Awaiting Analysis" {
+		t.Errorf("expected status %q, got %q", "Awaiting Analysis", status)
+	}
+	if comments := VendorComments(dict["CVE-2020-0001"]); len(comments) != 1 || comments[0].Organization != "Red Hat" {
+		t.Errorf("unexpected vendor comments %+v", comments)
+	}
+	if n := dict.DropRejected(); n != 1 {
+		t.Errorf("expected 1 rejected CVE, got %d", n)
+	}
+	if _, ok := dict["CVE-2020-0002"]; ok || len(dict) != 2 {
+		t.Errorf("rejected CVE wasn't dropped: %v", dict)
+	}
+}
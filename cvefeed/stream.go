@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// MatchStream matches the CPE names like Get, but sends the results to out as they're found, e.g. to render them
+// incrementally, and closes out when done or when ctx is canceled, returning ctx.Err() if it stopped short.
+// The CVEs are matched by c.Workers goroutines (see SetWorkers) and the results come in no particular order;
+// they aren't cached, but the ones cached by Get are reused, waiting for the ones Get is computing until ctx is done.
+// It's safe to call concurrently with the other lookups.
+func (c *Cache) MatchStream(ctx context.Context, cpes []*wfn.Attributes, out chan<- MatchResult) error {
+	defer close(out)
+	res, ok, err := c.cachedContext(ctx, cpes)
+	if err != nil {
+		return err
+	}
+	if ok {
+		for _, r := range res {
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+
+	dict := c.candidates(cpes)
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	ids := make(chan string)
+	var stopped int32 // set if matching stopped short because ctx is done
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				r, ok, _ := c.matchCVE(cpes, id, dict[id]) // skipped CVEs are logged and recorded in Skipped
+				if !ok {
+					continue
+				}
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					atomic.StoreInt32(&stopped, 1)
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for id := range dict {
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			atomic.StoreInt32(&stopped, 1)
+			break feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+	if atomic.LoadInt32(&stopped) != 0 {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// MatchUntil is like Get, but stops matching at the deadline and returns the results found until then, with
+// timedOut set, rather than failing: for best effort lookups, where partial results are better than none.
+// If Get is computing the results of the same CPE names, they're waited for until the deadline only.
+// The matching goroutines (see MatchStream) are done when it returns; the results aren't cached.
+func (c *Cache) MatchUntil(deadline time.Time, cpes []*wfn.Attributes) (results []MatchResult, timedOut bool) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	out := make(chan MatchResult)
+	errc := make(chan error, 1)
+	go func() { errc <- c.MatchStream(ctx, cpes, out) }()
+	for r := range out {
+		results = append(results, r)
+	}
+	return results, <-errc != nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestMatchStream(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(testJSONdict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	dict := Dictionary{}
+	for _, item := range items {
+		dict[item.CVEID()] = item
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.4"},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"},
+	}
+	ids := func(results []MatchResult) []string {
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.CVE.CVEID()
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	stream := func(ctx context.Context, cache *Cache) ([]MatchResult, error) {
+		out := make(chan MatchResult)
+		errc := make(chan error, 1)
+		go func() { errc <- cache.MatchStream(ctx, inventory, out) }()
+		var results []MatchResult
+		for r := range out {
+			results = append(results, r)
+		}
+		return results, <-errc
+	}
+
+	expected := ids(NewCache(dict).SetMaxSize(-1).Get(inventory))
+	if len(expected) < 2 {
+		t.Fatalf("expected several matches to stream, got %v", expected)
+	}
+	for _, workers := range []int{0, 1, 3} {
+		results, err := stream(context.Background(), NewCache(dict).SetMaxSize(-1).SetWorkers(workers))
+		if err != nil {
+			t.Fatalf("%d workers: unexpected error: %v", workers, err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%d workers: expected %v, got %v", workers, expected, got)
+		}
+	}
+
+	// cached results are streamed as is
+	cache := NewCache(dict)
+	cache.Get(inventory)
+	if results, err := stream(context.Background(), cache); err != nil || !reflect.DeepEqual(ids(results), expected) {
+		t.Errorf("cached: expected %v, got %v (%v)", expected, ids(results), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := stream(ctx, NewCache(dict).SetMaxSize(-1)); err != context.Canceled {
+		t.Errorf("expected canceled stream to return %v, got %v", context.Canceled, err)
+	}
+}
+
+// slowCVE takes a while to evaluate
+type slowCVE struct {
+	CVEItem
+	delay time.Duration
+}
+
+func (c slowCVE) Config() []LogicalTest {
+	time.Sleep(c.delay)
+	return c.CVEItem.Config()
+}
+
+func TestMatchUntil(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(testJSONdict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	var cve CVEItem
+	for _, item := range items {
+		if item.CVEID() == "CVE-2002-2436" {
+			cve = item
+		}
+	}
+	if cve == nil {
+		t.Fatal("CVE-2002-2436 not found in the test dictionary")
+	}
+	inventory := []*wfn.Attributes{{Part: "a", Vendor: "mozilla", Product: "firefox", Version: "3\\.0"}}
+	dict := Dictionary{}
+	for i := 0; i < 20; i++ {
+		dict[fmt.Sprintf("CVE-2020-%04d", i)] = slowCVE{cve, 10 * time.Millisecond}
+	}
+	cache := NewCache(dict).SetMaxSize(-1).SetWorkers(2)
+
+	goroutines := runtime.NumGoroutine()
+	results, timedOut := cache.MatchUntil(time.Now().Add(100*time.Millisecond), inventory)
+	if !timedOut || len(results) == 0 || len(results) >= len(dict) {
+		t.Errorf("expected partial results and time out, got %d results, timed out %t", len(results), timedOut)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("%d goroutines left running", n-goroutines)
+	}
+
+	results, timedOut = cache.MatchUntil(time.Now().Add(time.Minute), inventory)
+	if timedOut || len(results) != len(dict) {
+		t.Errorf("expected all %d results, got %d, timed out %t", len(dict), len(results), timedOut)
+	}
+
+	// the deadline isn't exceeded waiting for the results Get is computing
+	cache = NewCache(dict)
+	got := make(chan int)
+	go func() { got <- len(cache.Get(inventory)) }()
+	for computing := false; !computing; {
+		time.Sleep(time.Millisecond)
+		cache.mu.Lock()
+		computing = cache.data[cacheKey(inventory)] != nil
+		cache.mu.Unlock()
+	}
+	start := time.Now()
+	results, timedOut = cache.MatchUntil(start.Add(20*time.Millisecond), inventory)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected to return at the deadline, waited for %v", elapsed)
+	}
+	if !timedOut || len(results) != 0 {
+		t.Errorf("expected no results and time out while Get is in flight, got %d results, timed out %t", len(results), timedOut)
+	}
+	if n := <-got; n != len(dict) {
+		t.Errorf("expected Get to find all %d results, got %d", len(dict), n)
+	}
+}
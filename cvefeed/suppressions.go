@@ -0,0 +1,370 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	// SuppressAction is the action of suppression rule which drops the matches
+	SuppressAction = "suppress"
+	// AnnotateAction is the action of suppression rule which keeps the matches, annotated with the rule
+	AnnotateAction = "annotate"
+)
+
+// SuppressionRule is an accepted risk decision: the matches of the CVEs by the CPEs of the rule are suppressed
+// or annotated with the justification until the rule expires
+type SuppressionRule struct {
+	ID string `json:"id,omitempty"` // defaults to the position of the rule in the file, #1 being the first
+	// CVE is the CVE ID or a glob of them, e.g. CVE-2021-*, see path.Match; empty means all CVEs
+	CVE string `json:"cve,omitempty"`
+	// CPE is the CPE name in URI or formatted string binding the matched CPEs must match, may contain ANY and
+	// wildcards; the components formatted strings omit are ANY, e.g. cpe:2.3:a:openssl:openssl:1.1.1*.
+	// Empty means all CPEs; either CVE or CPE is required.
+	CPE           string `json:"cpe,omitempty"`
+	Justification string `json:"justification"`
+	// Expires is the last day the rule applies on, YYYY-MM-DD; empty means the rule doesn't expire
+	Expires string `json:"expires,omitempty"`
+	// Action is either SuppressAction, the default, or AnnotateAction
+	Action string `json:"action,omitempty"`
+
+	cpe     *wfn.Attributes
+	expires string // normalized Expires, compares as string
+}
+
+// Annotation is the rule of AnnotateAction which applied to the match, see MatchResult
+type Annotation struct {
+	Rule          string `json:"rule"`
+	Justification string `json:"justification"`
+	Expires       string `json:"expires,omitempty"`
+}
+
+// String returns the annotation as rule: justification
+func (a Annotation) String() string {
+	return a.Rule + ": " + a.Justification
+}
+
+// RuleAudit tells how many matches a suppression rule applied to, see Suppressions.Audit
+type RuleAudit struct {
+	Rule    *SuppressionRule
+	Expired bool     // the rule expired before the time of the suppressions, it didn't apply
+	Fired   int      // the number of matches of a CVE by an inventory the rule applied to, or would have if expired
+	CVEs    []string // sorted CVEs of the matches
+}
+
+// Suppressions is a list of suppression rules applied after matching; the first rule in effect matching the CVE
+// and the CPE applies. The rules keep count of the matches they apply to for audit, so they're safe for
+// concurrent use; see Audit.
+type Suppressions struct {
+	rules []*SuppressionRule
+	today string // YYYY-MM-DD of the time the rules are in effect on
+
+	mu    sync.Mutex
+	fired []int
+	cves  []map[string]bool
+}
+
+// ParseSuppressions parses the suppression rules from JSON, either a list of the rules or an object listing them
+// under "rules":
+//
+//	{"rules": [
+//	  {"id": "openssl-not-reachable", "cve": "CVE-2022-0778", "cpe": "cpe:2.3:a:openssl:openssl:1.1.1*",
+//	   "justification": "we don't parse certificates", "expires": "2024-06-30"}
+//	]}
+//
+// The rules are in effect as of now, see SetTime.
+func ParseSuppressions(in io.Reader) (*Suppressions, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("suppressions: %v", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' && data[0] != '{' {
+		return nil, fmt.Errorf("suppressions: expected JSON list of rules or object of them")
+	}
+	rules, err := parseRulesJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("suppressions: %v", err)
+	}
+	ids := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("#%d", i+1)
+		}
+		if ids[rule.ID] {
+			return nil, fmt.Errorf("suppressions: rule %s: duplicate ID", rule.ID)
+		}
+		ids[rule.ID] = true
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("suppressions: rule %s: %v", rule.ID, err)
+		}
+	}
+	s := &Suppressions{rules: rules, fired: make([]int, len(rules)), cves: make([]map[string]bool, len(rules))}
+	return s.SetTime(time.Now()), nil
+}
+
+// LoadSuppressions parses the suppression rules from file, see ParseSuppressions
+func LoadSuppressions(path string) (*Suppressions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("suppressions: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseSuppressions(f)
+}
+
+// SetTime sets the time the rules are in effect on: the rules which expired before the day of the time don't apply
+func (s *Suppressions) SetTime(t time.Time) *Suppressions {
+	s.today = t.Format("2006-01-02")
+	return s
+}
+
+// Rules returns the rules in the order they're applied in
+func (s *Suppressions) Rules() []*SuppressionRule {
+	return s.rules
+}
+
+// compile validates the rule and parses its CPE and expiration date
+func (r *SuppressionRule) compile() error {
+	if r.CVE == "" && r.CPE == "" {
+		return fmt.Errorf("either cve or cpe is required")
+	}
+	if strings.TrimSpace(r.Justification) == "" {
+		return fmt.Errorf("justification is required")
+	}
+	r.CVE = strings.ToUpper(strings.TrimSpace(r.CVE))
+	if _, err := path.Match(r.CVE, ""); err != nil {
+		return fmt.Errorf("cve %q: %v", r.CVE, err)
+	}
+	if r.CPE != "" {
+		var err error
+		if r.cpe, err = wfn.Parse(padFmtString(strings.TrimSpace(r.CPE))); err != nil {
+			return err
+		}
+	}
+	if r.Expires != "" {
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(r.Expires))
+		if err != nil {
+			return fmt.Errorf("expires: expected YYYY-MM-DD, got %q", r.Expires)
+		}
+		r.expires = t.Format("2006-01-02")
+	}
+	switch r.Action = strings.ToLower(strings.TrimSpace(r.Action)); r.Action {
+	case "":
+		r.Action = SuppressAction
+	case SuppressAction, AnnotateAction:
+	default:
+		return fmt.Errorf("unknown action %q, expected %s or %s", r.Action, SuppressAction, AnnotateAction)
+	}
+	return nil
+}
+
+// padFmtString appends the components the CPE name in formatted string binding omits as ANY
+func padFmtString(s string) string {
+	if !strings.HasPrefix(s, "cpe:2.3:") {
+		return s
+	}
+	n := 1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			n++
+		}
+	}
+	for ; n < 13; n++ {
+		s += ":*"
+	}
+	return s
+}
+
+// matches tells whether the rule matches the CVE and the CPE
+func (r *SuppressionRule) matches(cve string, cpe *wfn.Attributes) bool {
+	if r.CVE != "" {
+		if ok, _ := path.Match(r.CVE, strings.ToUpper(cve)); !ok {
+			return false
+		}
+	}
+	return r.cpe == nil || cpe != nil && wfn.Match(r.cpe, cpe)
+}
+
+// expired tells whether the rule expired before the day
+func (r *SuppressionRule) expired(today string) bool {
+	return r.expires != "" && r.expires < today
+}
+
+// Apply returns match results amended by the rules: the CPEs suppressed are removed from the results, results
+// without vulnerable CPEs left are dropped, and the annotations of the rules annotating the others are added.
+// The input results are not modified.
+func (s *Suppressions) Apply(results []MatchResult) []MatchResult {
+	out := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		if r.CVE == nil {
+			continue
+		}
+		cve := r.CVE.CVEID()
+		res := r
+		res.CPEs, res.FixedIn, res.Platform = nil, nil, nil
+		res.Annotations = append([]Annotation(nil), r.Annotations...)
+		fired := make(map[int]bool) // rules fire once per match of a CVE by an inventory
+		vulnerable := false
+		for i, cpe := range r.CPEs {
+			rule := s.find(cve, cpe, fired)
+			if rule != nil && rule.Action == SuppressAction {
+				continue
+			}
+			res.CPEs = append(res.CPEs, cpe)
+			if r.FixedIn != nil {
+				res.FixedIn = append(res.FixedIn, r.FixedIn[i])
+			}
+			platform := r.Platform != nil && r.Platform[i]
+			if r.Platform != nil {
+				res.Platform = append(res.Platform, platform)
+			}
+			vulnerable = vulnerable || !platform
+			if rule != nil && !hasAnnotation(res.Annotations, rule.ID) {
+				res.Annotations = append(res.Annotations, Annotation{Rule: rule.ID, Justification: rule.Justification, Expires: rule.expires})
+			}
+		}
+		s.record(cve, fired)
+		if vulnerable {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// find returns the first rule in effect matching the CVE and the CPE, adding it and the expired rules matching
+// before it to fired
+func (s *Suppressions) find(cve string, cpe *wfn.Attributes, fired map[int]bool) *SuppressionRule {
+	for i, rule := range s.rules {
+		if !rule.matches(cve, cpe) {
+			continue
+		}
+		fired[i] = true
+		if !rule.expired(s.today) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func hasAnnotation(annotations []Annotation, rule string) bool {
+	for _, a := range annotations {
+		if a.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// record counts the match of the CVE for the rules fired
+func (s *Suppressions) record(cve string, fired map[int]bool) {
+	if len(fired) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range fired {
+		s.fired[i]++
+		if s.cves[i] == nil {
+			s.cves[i] = make(map[string]bool)
+		}
+		s.cves[i][cve] = true
+	}
+}
+
+// Audit returns the audit of the rules, in the order they're applied in, the ones which never fired included
+func (s *Suppressions) Audit() []RuleAudit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	audit := make([]RuleAudit, len(s.rules))
+	for i, rule := range s.rules {
+		audit[i] = RuleAudit{Rule: rule, Expired: rule.expired(s.today), Fired: s.fired[i], CVEs: sortedKeys(s.cves[i])}
+	}
+	return audit
+}
+
+// WriteAudit writes the audit of the rules as CSV with a header: rule ID, action, CVE, CPE, expiration date,
+// status (active or expired), the number of matches the rule applied to (or would have, if expired) and their CVEs,
+// space separated
+func (s *Suppressions) WriteAudit(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"rule", "action", "cve", "cpe", "expires", "status", "fired", "cves"})
+	for _, a := range s.Audit() {
+		status := "active"
+		if a.Expired {
+			status = "expired"
+		}
+		w.Write([]string{
+			a.Rule.ID, a.Rule.Action, a.Rule.CVE, a.Rule.CPE, a.Rule.expires, status,
+			strconv.Itoa(a.Fired), strings.Join(a.CVEs, " "),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseRulesJSON parses the rules of JSON list or of "rules" of JSON object; unknown fields are an error
+func parseRulesJSON(data []byte) ([]*SuppressionRule, error) {
+	var rules []*SuppressionRule
+	var doc struct {
+		Rules *[]*SuppressionRule `json:"rules"`
+	}
+	var v interface{} = &rules
+	if data[0] == '{' {
+		doc.Rules = &rules
+		v = &doc
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		if rule == nil {
+			return nil, fmt.Errorf("rule #%d is null", i+1)
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testSuppressionsRules = `
+  {"id": "bar-not-reachable", "cve": "CVE-2020-0001", "cpe": "cpe:2.3:a:foo:bar", "justification": "we don't call the vulnerable function", "expires": "2030-06-30"},
+  {"cve": "CVE-2020-00*", "cpe": "cpe:/a:foo:baz", "justification": "mitigated by the firewall, see 'FW-1'", "action": "annotate"},
+  {"id": "expired", "cve": "CVE-2020-0003", "justification": "fixed in the next release", "expires": "2020-01-31"}
+`
+
+func TestSuppressions(t *testing.T) {
+	for name, in := range map[string]string{"list": "[" + testSuppressionsRules + "]", "object": `{"rules": [` + testSuppressionsRules + "]}"} {
+		s, err := ParseSuppressions(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		s.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		var ids []string
+		for _, rule := range s.Rules() {
+			ids = append(ids, rule.ID+" "+rule.Action+" "+rule.Justification)
+		}
+		want := []string{
+			"bar-not-reachable suppress we don't call the vulnerable function",
+			"#2 annotate mitigated by the firewall, see 'FW-1'",
+			"expired suppress fixed in the next release",
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("%s: expected rules %q, got %q", name, want, ids)
+		}
+
+		bar := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+		baz := &wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "2\\.0"}
+		os := &wfn.Attributes{Part: "o", Vendor: "foo", Product: "os"}
+		results := []MatchResult{
+			{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{bar}},
+			{CVE: idCVE{id: "CVE-2020-0001"}, CPEs: []*wfn.Attributes{bar, os}, Platform: []bool{false, true}},
+			{CVE: idCVE{id: "CVE-2020-0002"}, CPEs: []*wfn.Attributes{bar, baz}, FixedIn: []string{"1.1", "2.1"}, PURL: "pkg:generic/baz"},
+			{CVE: idCVE{id: "CVE-2020-0003"}, CPEs: []*wfn.Attributes{bar}},
+		}
+		out := s.Apply(results)
+		if len(out) != 2 {
+			t.Fatalf("%s: expected 2 results, got %d: %+v", name, len(out), out)
+		}
+		annotation := Annotation{Rule: "#2", Justification: "mitigated by the firewall, see 'FW-1'"}
+		if r := out[0]; r.CVE.CVEID() != "CVE-2020-0002" || len(r.CPEs) != 2 || !reflect.DeepEqual(r.FixedIn, []string{"1.1", "2.1"}) ||
+			r.PURL != "pkg:generic/baz" || !reflect.DeepEqual(r.Annotations, []Annotation{annotation}) {
+			t.Errorf("%s: expected CVE-2020-0002 to be annotated, got %+v", name, r)
+		}
+		if r := out[1]; r.CVE.CVEID() != "CVE-2020-0003" || len(r.CPEs) != 1 || r.Annotations != nil {
+			t.Errorf("%s: expected CVE-2020-0003 to be left intact, the rule expired, got %+v", name, r)
+		}
+		if len(results[1].CPEs) != 2 || results[2].Annotations != nil {
+			t.Errorf("%s: input results were modified", name)
+		}
+
+		audit := s.Audit()
+		var fired []int
+		for _, a := range audit {
+			fired = append(fired, a.Fired)
+		}
+		if !reflect.DeepEqual(fired, []int{2, 1, 1}) || !audit[2].Expired || audit[0].Expired ||
+			!reflect.DeepEqual(audit[0].CVEs, []string{"CVE-2020-0001"}) {
+			t.Errorf("%s: unexpected audit %+v", name, audit)
+		}
+		var buf bytes.Buffer
+		if err := s.WriteAudit(&buf); err != nil {
+			t.Fatal(err)
+		}
+		wantAudit := `rule,action,cve,cpe,expires,status,fired,cves
+bar-not-reachable,suppress,CVE-2020-0001,cpe:2.3:a:foo:bar,2030-06-30,active,2,CVE-2020-0001
+#2,annotate,CVE-2020-00*,cpe:/a:foo:baz,,active,1,CVE-2020-0002
+expired,suppress,CVE-2020-0003,,2020-01-31,expired,1,CVE-2020-0003
+`
+		if buf.String() != wantAudit {
+			t.Errorf("%s: expected audit\n%s\ngot\n%s", name, wantAudit, buf.String())
+		}
+	}
+}
+
+func TestParseSuppressionsErrors(t *testing.T) {
+	for _, in := range []string{
+		`[{"cve": "CVE-2020-0001"}]`,         // no justification
+		`[{"justification": "all of them"}]`, // neither CVE nor CPE
+		`[{"cve": "CVE-2020-0001", "justification": "x", "action": "ignore"}]`,
+		`[{"cve": "CVE-2020-0001", "justification": "x", "expires": "30/06/2030"}]`,
+		`[{"cve": "CVE-2020-0001", "justification": "x", "owner": "me"}]`,
+		`[{"cve": "CVE-2020-0001", "justification": ["x"]}]`,
+		`[{"cve": "CVE-2020-0001", "justification": "x}]`,
+		`{"cve": "CVE-2020-0001", "justification": "x"}`,
+		`[{"cpe": "foo:bar", "justification": "x"}]`,
+		`[{"cve": "CVE-[2020", "justification": "x"}]`,
+		`[{"id": "a", "cve": "CVE-2020-0001", "justification": "x"}, {"id": "a", "cve": "CVE-2020-0002", "justification": "x"}]`,
+		"- cve: CVE-2020-0001\n  justification: x\n", // YAML isn't supported
+		"",
+		`[null]`,
+	} {
+		if _, err := ParseSuppressions(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
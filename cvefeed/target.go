@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// CompiledTarget is a set of CPE names, e.g. the inventory of a host, prepared once to be matched against several
+// dictionaries (e.g. NVD and vendor feeds): the names are parsed and the version ranges of wildcard versions are
+// derived once rather than for every dictionary. Matching a dictionary yields the same results as Cache.Get of
+// a cache of the dictionary with the same modes, without caching.
+type CompiledTarget struct {
+	cpes             []*wfn.Attributes
+	requireVersion   bool
+	wildcardVersions bool
+	comparators      *VersionComparators
+	opts             wfn.MatchOptions
+	ranges           map[*wfn.Attributes]versionRange
+}
+
+// NewCompiledTarget creates new CompiledTarget of copies of the CPE names, so they can be modified after;
+// nil names are skipped
+func NewCompiledTarget(cpes []*wfn.Attributes) *CompiledTarget {
+	t := &CompiledTarget{cpes: make([]*wfn.Attributes, 0, len(cpes)), ranges: map[*wfn.Attributes]versionRange{}}
+	copies := make(map[*wfn.Attributes]*wfn.Attributes, len(cpes))
+	for _, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		c, ok := copies[cpe]
+		if !ok {
+			copied := *cpe
+			c = &copied
+			copies[cpe] = c
+			if start, end, ok := WildcardVersionRange(c.Version); ok {
+				t.ranges[c] = versionRange{start: start, end: end}
+			}
+		}
+		t.cpes = append(t.cpes, c)
+	}
+	return t
+}
+
+// CompileTarget creates new CompiledTarget of the CPE names given as formatted strings or URIs, see wfn.Parse
+func CompileTarget(names ...string) (*CompiledTarget, error) {
+	cpes := make([]*wfn.Attributes, 0, len(names))
+	for _, name := range names {
+		cpe, err := wfn.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("can't compile target %q: %v", name, err)
+		}
+		cpes = append(cpes, cpe)
+	}
+	return NewCompiledTarget(cpes), nil
+}
+
+// SetRequireVersion sets if the target fails matching the dictionary records without Version attribute of CPE name,
+// see Cache.SetRequireVersion.
+// Returns a pointer to the instance of CompiledTarget, for easy chaining.
+func (t *CompiledTarget) SetRequireVersion(requireVersion bool) *CompiledTarget {
+	t.requireVersion = requireVersion
+	return t
+}
+
+// SetWildcardVersions sets if the target matches CPE names whose version ends with a wildcard component
+// as the range of versions the wildcard covers, see Cache.SetWildcardVersions.
+// Returns a pointer to the instance of CompiledTarget, for easy chaining.
+func (t *CompiledTarget) SetWildcardVersions(wildcardVersions bool) *CompiledTarget {
+	t.wildcardVersions = wildcardVersions
+	return t
+}
+
+// SetVersionComparators sets the comparators of versions the version bounds of CVEs are evaluated with,
+// see Cache.SetVersionComparators.
+// Returns a pointer to the instance of CompiledTarget, for easy chaining.
+func (t *CompiledTarget) SetVersionComparators(vc *VersionComparators) *CompiledTarget {
+	t.comparators = vc
+	return t
+}
+
+// SetMatchOptions sets the semantics of matching the CPE names of the target, see Cache.SetMatchOptions.
+// Returns a pointer to the instance of CompiledTarget, for easy chaining.
+func (t *CompiledTarget) SetMatchOptions(opts wfn.MatchOptions) *CompiledTarget {
+	t.opts = opts
+	return t
+}
+
+// CPEs returns the CPE names of the target, the ones the match results refer to; they must not be modified
+func (t *CompiledTarget) CPEs() []*wfn.Attributes {
+	return t.cpes
+}
+
+// Match matches the target against the dictionary; it's safe to call concurrently
+func (t *CompiledTarget) Match(dict Dictionary) []MatchResult {
+	c := NewCache(dict).SetRequireVersion(t.requireVersion).SetWildcardVersions(t.wildcardVersions).SetVersionComparators(t.comparators).
+		SetMatchOptions(t.opts).SetMaxSize(-1)
+	c.ranges = t.ranges
+	return c.Get(t.cpes)
+}
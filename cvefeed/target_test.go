@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestCompiledTarget(t *testing.T) {
+	var dicts []Dictionary
+	for _, feed := range []string{testJSONdictVersions, testJSONdictWildcardVersions} {
+		dict, err := LoadFeed(func(_ string) ([]CVEItem, error) {
+			return ParseJSON(bytes.NewBufferString(feed))
+		}, "")
+		if err != nil {
+			t.Fatalf("failed to parse the dictionary: %v", err)
+		}
+		dicts = append(dicts, dict)
+	}
+	names := []string{"cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*", "cpe:2.3:a:foo:bar:2.4.*:*:*:*:*:*:*:*", "cpe:/a:foo:bar:2.1"}
+	var cpes []*wfn.Attributes
+	for _, name := range names {
+		cpe, err := wfn.Parse(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cpes = append(cpes, cpe)
+	}
+	// results as CVE IDs mapped to the matched CPE names
+	results := func(rr []MatchResult) map[string][]string {
+		out := map[string][]string{}
+		for _, r := range rr {
+			for _, cpe := range r.CPEs {
+				out[r.CVE.CVEID()] = append(out[r.CVE.CVEID()], cpe.BindToFmtString())
+			}
+			sort.Strings(out[r.CVE.CVEID()])
+		}
+		return out
+	}
+	for _, wildcards := range []bool{false, true} {
+		target, err := CompileTarget(names...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target.SetWildcardVersions(wildcards)
+		for i, dict := range dicts {
+			expected := results(NewCache(dict).SetWildcardVersions(wildcards).Get(cpes))
+			if len(expected) == 0 {
+				t.Fatalf("wildcards %t, dictionary %d: nothing matched, the test is broken", wildcards, i)
+			}
+			if actual := results(target.Match(dict)); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("wildcards %t, dictionary %d: expected %v, got %v", wildcards, i, expected, actual)
+			}
+		}
+	}
+	if _, err := CompileTarget("cpe:2.3:a:foo"); err == nil {
+		t.Error("expected an error for the malformed CPE name")
+	}
+	target := NewCompiledTarget(cpes)
+	cpes[0].Version = "9\\.9"
+	if target.CPEs()[0].Version != "1\\.5" {
+		t.Errorf("the target wasn't copied: %v", target.CPEs()[0])
+	}
+}
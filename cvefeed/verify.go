@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+)
+
+// scoreTolerance is the maximum difference between stated and computed scores which isn't a mismatch;
+// it absorbs the rounding differences between implementations (e.g. CVSS v3.0 and v3.1 round up functions)
+const scoreTolerance = 0.1 + 1e-9
+
+// v3PrefixRe matches version prefixes of CVSS v3.x vectors, base score equations are the same for all of them
+var v3PrefixRe = regexp.MustCompile(`^CVSS:3\.[0-9]/`)
+
+// ScoreMismatch is a CVSS base score stated by the feed which differs from the one computed from the vector
+type ScoreMismatch struct {
+	CVE      string
+	Version  string // CVSS version: "3" or "2"
+	Vector   string
+	Stated   float64
+	Computed float64
+}
+
+// Error implements error interface
+func (m ScoreMismatch) Error() string {
+	return fmt.Sprintf("%s: CVSS v%s base score %.1f doesn't match %.1f computed from %s", m.CVE, m.Version, m.Stated, m.Computed, m.Vector)
+}
+
+// ScoreMismatches is a list of mismatching CVSS base scores of a CVE
+type ScoreMismatches []ScoreMismatch
+
+// Error implements error interface
+func (ms ScoreMismatches) Error() string {
+	msgs := make([]string, len(ms))
+	for i, m := range ms {
+		msgs[i] = m.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// VerifyCVSS recomputes CVSS base scores of the CVE from its vectors and compares them to the scores stated by the feed.
+// It returns ScoreMismatches if any of them differ, or any other error if the vectors couldn't be parsed.
+// CVE items which don't provide vectors (see nvdcommon.CVSSVectors) or scores are not verified.
+func VerifyCVSS(cve CVEItem) error {
+	cv, ok := cve.(nvdcommon.CVSSVectors)
+	if !ok {
+		return nil
+	}
+	var ms ScoreMismatches
+	if vector, stated := cv.CVSS30vector(), cve.CVSS30base(); vector != "" && stated != 0 {
+		v := v3.NewVector()
+		if err := v.Parse(v3PrefixRe.ReplaceAllString(vector, "")); err != nil {
+			return fmt.Errorf("%s: can't parse CVSS v3 vector %q: %v", cve.CVEID(), vector, err)
+		}
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s: invalid CVSS v3 vector %q: %v", cve.CVEID(), vector, err)
+		}
+		if computed := v.BaseScore(); math.Abs(computed-stated) > scoreTolerance {
+			ms = append(ms, ScoreMismatch{CVE: cve.CVEID(), Version: "3", Vector: vector, Stated: stated, Computed: computed})
+		}
+	}
+	if vector, stated := cv.CVSS20vector(), cve.CVSS20base(); vector != "" && stated != 0 {
+		v := v2.NewVector()
+		if err := v.Parse(vector); err != nil {
+			return fmt.Errorf("%s: can't parse CVSS v2 vector %q: %v", cve.CVEID(), vector, err)
+		}
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s: invalid CVSS v2 vector %q: %v", cve.CVEID(), vector, err)
+		}
+		if computed := v.BaseScore(); math.Abs(computed-stated) > scoreTolerance {
+			ms = append(ms, ScoreMismatch{CVE: cve.CVEID(), Version: "2", Vector: vector, Stated: stated, Computed: computed})
+		}
+	}
+	if len(ms) != 0 {
+		return ms
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"testing"
+)
+
+type vectoredCVE struct {
+	scoredCVE
+	vector20, vector30 string
+}
+
+func (c vectoredCVE) CVEID() string        { return "CVE-2014-0160" }
+func (c vectoredCVE) CVSS20vector() string { return c.vector20 }
+func (c vectoredCVE) CVSS30vector() string { return c.vector30 }
+
+func TestVerifyCVSS(t *testing.T) {
+	const (
+		v2vector = "AV:N/AC:L/Au:N/C:P/I:N/A:N"
+		v3vector = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"
+	)
+	cases := []struct {
+		cve        vectoredCVE
+		mismatches int
+		fail       bool
+	}{
+		{cve: vectoredCVE{scoredCVE{cvss20: 5.0, cvss30: 7.5}, v2vector, v3vector}},
+		{cve: vectoredCVE{scoredCVE{cvss30: 7.5}, "", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"}},
+		{cve: vectoredCVE{scoredCVE{cvss20: 5.0}, v2vector, ""}},
+		{cve: vectoredCVE{scoredCVE{cvss20: 5.0, cvss30: 9.8}, v2vector, v3vector}, mismatches: 1},
+		{cve: vectoredCVE{scoredCVE{cvss20: 7.5, cvss30: 9.8}, v2vector, v3vector}, mismatches: 2},
+		{cve: vectoredCVE{scoredCVE{cvss30: 7.5}, "", "CVSS:3.1/AV:N/AC:L"}, fail: true},
+		{cve: vectoredCVE{scoredCVE{cvss20: 5.0}, "AV:N/AC:L/Au:N/C:P/I:X/A:N", ""}, fail: true},
+		{cve: vectoredCVE{vector20: v2vector}}, // no scores to verify
+	}
+	for i, c := range cases {
+		err := VerifyCVSS(c.cve)
+		if c.fail {
+			if err == nil {
+				t.Errorf("case #%d: expected an error", i)
+			}
+			continue
+		}
+		if c.mismatches == 0 {
+			if err != nil {
+				t.Errorf("case #%d: unexpected error %v", i, err)
+			}
+			continue
+		}
+		ms, ok := err.(ScoreMismatches)
+		if !ok {
+			t.Errorf("case #%d: expected mismatches, got %v", i, err)
+		} else if len(ms) != c.mismatches {
+			t.Errorf("case #%d: expected %d mismatches, got %d: %v", i, c.mismatches, len(ms), ms)
+		}
+	}
+	if err := VerifyCVSS(scoredCVE{cvss30: 1}); err != nil {
+		t.Errorf("CVE without vectors expected to be skipped, got %v", err)
+	}
+}
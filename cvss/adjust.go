@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+	"github.com/facebookincubator/nvdtools/cvss/v4"
+)
+
+// Adjust sets the temporal and environmental metrics of overrides on the vector, replacing its own, e.g. to score it
+// in the environment of an asset by its security requirements (CR, IR and AR). Overriding base metrics is an error;
+// the metrics the CVSS version of the vector doesn't define are skipped, so the same overrides apply to the vectors
+// of every version. v must be a vector of one of the supported CVSS versions, see NewVector
+func Adjust(v Vector, overrides map[string]string) error {
+	groups, err := metricGroupsOf(v)
+	if err != nil {
+		return err
+	}
+	metrics := make([]string, 0, len(overrides))
+	for metric := range overrides {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics) // report the errors deterministically
+	for _, metric := range metrics {
+		group, ok := groups[metric]
+		if !ok {
+			continue
+		}
+		if group == common.BaseGroup {
+			return fmt.Errorf("base metric %q can't be overridden", metric)
+		}
+		if err := v.Set(metric, overrides[metric]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metricGroupsOf maps the metrics of the CVSS version of the vector to their groups
+func metricGroupsOf(v Vector) (map[string]common.MetricGroups, error) {
+	var byGroup map[common.MetricGroups][]string
+	switch v.(type) {
+	case v2.Vector:
+		byGroup = v2.MetricGroups()
+	case v3.Vector:
+		byGroup = v3.MetricGroups()
+	case v4.Vector:
+		byGroup = v4.MetricGroups()
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+	groups := make(map[string]common.MetricGroups)
+	for group, metrics := range byGroup {
+		common.AddGroup(groups, group, metrics...)
+	}
+	return groups, nil
+}
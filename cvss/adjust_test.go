@@ -0,0 +1,62 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"testing"
+)
+
+func TestAdjust(t *testing.T) {
+	overrides := map[string]string{"CR": "H", "IR": "H", "AR": "L", "E": "U", "CDP": "H"}
+	cases := []struct {
+		vector    string
+		canonical string
+		score     float64
+	}{
+		{
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N/CR:L",
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N/E:U/CR:H/IR:H/AR:L",
+			8.5,
+		},
+		{
+			"AV:N/AC:L/Au:N/C:P/I:N/A:N",
+			"AV:N/AC:L/Au:N/C:P/I:N/A:N/E:U/CDP:H/CR:H/IR:H/AR:L",
+			7.6,
+		},
+	}
+	for _, c := range cases {
+		v, err := Parse(c.vector, Strict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Adjust(v, overrides); err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if got, _ := Canonical(v); got != c.canonical {
+			t.Errorf("%s: want %s, got %s", c.vector, c.canonical, got)
+		}
+		if score := v.Score(); score != c.score {
+			t.Errorf("%s: want score %.1f, got %.1f", c.vector, c.score, score)
+		}
+	}
+
+	v, _ := Parse("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", Strict)
+	if err := Adjust(v, map[string]string{"AV": "L"}); err == nil {
+		t.Error("expected error overriding base metric")
+	}
+	if err := Adjust(v, map[string]string{"CR": "Z"}); err == nil {
+		t.Error("expected error overriding with invalid value")
+	}
+}
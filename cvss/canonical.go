@@ -0,0 +1,138 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+	"github.com/facebookincubator/nvdtools/cvss/v4"
+)
+
+// ParseMode tells how strictly Parse treats vector strings
+type ParseMode int
+
+const (
+	// Strict accepts the vector strings as the specifications write them: CVSS v3 and v4 vectors must start with
+	// the version prefix (e.g. CVSS:3.1/), no metric may repeat and CVSS v4 metrics must be in the order of the specification
+	Strict ParseMode = iota
+	// Lenient accepts the vector strings as found in the wild: CVSS v2 vectors in parentheses, metrics repeated with
+	// the same value, CVSS v3 metrics set to X (not defined), which the parser of v3 doesn't take, and CVSS v4 metrics
+	// in any order; metrics repeated with conflicting values are still an error
+	Lenient
+)
+
+// Parse parses the vector string of any supported CVSS version as per mode; the version is detected by the prefix
+// (e.g. CVSS:3.0/, in any case in Lenient mode), vectors without one are CVSS v2, as in ScoreAndSeverity. Completeness isn't checked, see Validate.
+func Parse(vector string, mode ParseMode) (Vector, error) {
+	version, prefixed := "2", vector
+	if mode == Lenient {
+		prefixed = strings.ToUpper(strings.TrimPrefix(vector, "("))
+	}
+	if m := versionRe.FindStringSubmatch(prefixed); m != nil {
+		version = m[1]
+	}
+	v, err := NewVector(version)
+	if err != nil {
+		return nil, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	if mode == Lenient {
+		err = parseLenient(v, vector)
+	} else {
+		err = parseStrict(v, vector)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	return v, nil
+}
+
+func parseStrict(v Vector, str string) error {
+	switch v := v.(type) {
+	case v3.Vector:
+		if !versionRe.MatchString(str) {
+			return fmt.Errorf("CVSS v3 vector must start with CVSS:3.x/ prefix")
+		}
+		return v.Parse(str)
+	case v4.Vector:
+		return v.ParseStrict(str)
+	}
+	return v.Parse(str)
+}
+
+func parseLenient(v Vector, str string) error {
+	switch v := v.(type) {
+	case v2.Vector:
+		return v.ParseLenient(str)
+	case v3.Vector:
+		return v.ParseLenient(dropValue(str, "X"))
+	}
+	str, err := common.DedupMetrics(str)
+	if err != nil {
+		return err
+	}
+	return v.Parse(str)
+}
+
+// Normalize returns a copy of the vector without the metrics other than base ones set to their not defined value
+// (ND in CVSS v2, X in later versions), which don't affect the scores (see e.g. v3.Vector.Normalize);
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func Normalize(v Vector) (Vector, error) {
+	switch v := v.(type) {
+	case v2.Vector:
+		return v.Normalize(), nil
+	case v3.Vector:
+		return v.Normalize(), nil
+	case v4.Vector:
+		return v.Normalize(), nil
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+}
+
+// Canonical returns the canonical vector string of the vector: normalized (see Normalize) and with metrics
+// in the order of the specification, so equivalent vectors have the same string, suitable for diffing and
+// deduplication; v must be a vector of one of the supported CVSS versions, see NewVector
+func Canonical(v Vector) (string, error) {
+	n, err := Normalize(v)
+	if err != nil {
+		return "", err
+	}
+	return n.(interface{ CanonicalString() string }).CanonicalString(), nil
+}
+
+// CanonicalVector parses the vector string as per mode (see Parse) and returns its canonical string, see Canonical
+func CanonicalVector(vector string, mode ParseMode) (string, error) {
+	v, err := Parse(vector, mode)
+	if err != nil {
+		return "", err
+	}
+	return Canonical(v)
+}
+
+// dropValue removes the metrics set to value from the vector string
+func dropValue(str, value string) string {
+	parts := strings.Split(str, "/")
+	kept := parts[:0]
+	for _, part := range parts {
+		if !strings.HasSuffix(part, ":"+value) {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "/")
+}
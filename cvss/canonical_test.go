@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"testing"
+)
+
+func TestCanonicalVector(t *testing.T) {
+	cases := []struct {
+		vector    string
+		mode      ParseMode
+		canonical string
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", Strict, "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		{"A:P/I:P/C:P/Au:N/AC:L/AV:N/E:ND/RL:OF/CDP:ND", Strict, "AV:N/AC:L/Au:N/C:P/I:P/A:P/RL:OF"},
+		{"(AV:N/AC:L/Au:N/C:P/I:P/A:P/AV:N)", Lenient, "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		{"CVSS:3.1/S:U/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H/CR:H", Strict, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:H"},
+		{"CVSS:3.1/S:U/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H/E:X/RL:X/RC:X/MAV:X/CR:H", Lenient, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:H"},
+		{"cvss:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/AV:N", Lenient, "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:X/S:X", Strict, "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+		{"CVSS:4.0/AC:L/AV:N/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:A", Lenient, "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:A"},
+	}
+	for _, c := range cases {
+		got, err := CanonicalVector(c.vector, c.mode)
+		if err != nil {
+			t.Errorf("%s: %v", c.vector, err)
+			continue
+		}
+		if got != c.canonical {
+			t.Errorf("%s: want %s, got %s", c.vector, c.canonical, got)
+		}
+	}
+
+	// rejected in strict mode only
+	for _, vector := range []string{
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P/AV:N",
+		"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:X",
+		"cvss:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:X",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/AV:N",
+		"CVSS:4.0/AC:L/AV:N/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+	} {
+		if _, err := CanonicalVector(vector, Strict); err == nil {
+			t.Errorf("%s: expected error in strict mode", vector)
+		}
+		if _, err := CanonicalVector(vector, Lenient); err != nil && vector != "AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:X" {
+			t.Errorf("%s: unexpected error in lenient mode: %v", vector, err)
+		}
+	}
+
+	// conflicting values are rejected in both modes
+	for _, mode := range []ParseMode{Strict, Lenient} {
+		if _, err := CanonicalVector("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/AV:L", mode); err == nil {
+			t.Errorf("mode %d: expected error on conflicting values", mode)
+		}
+	}
+}
+
+func TestNormalizeKeepsVector(t *testing.T) {
+	v, err := Parse("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:X", Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Normalize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Get("E"); err == nil {
+		t.Error("expected E to be dropped by Normalize")
+	}
+	if e, err := v.Get("E"); err != nil || e != "X" {
+		t.Errorf("expected the original vector to keep E:X, got %q, %v", e, err)
+	}
+	if v.Score() != n.Score() {
+		t.Errorf("normalized score %v differs from %v", n.Score(), v.Score())
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// Defaults maps metrics to the values they're taken as when the vector omits them, e.g. {"RC": "R"}
+type Defaults map[string]string
+
+// Validate checks that defaults only set the given metrics, to values with a weight in weights
+func (d Defaults) Validate(weights map[string]map[string]float64, metrics ...string) error {
+	allowed := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		allowed[metric] = true
+	}
+	for metric, value := range d {
+		if !allowed[metric] {
+			return ErrUnknownMetric{Metric: metric}
+		}
+		if _, ok := weights[metric][value]; !ok {
+			return ErrInvalidValue{Metric: metric, Value: value}
+		}
+	}
+	return nil
+}
+
+// WeightOmitted is like WeightDefault, but the metric omitted by the vector, i.e. unset or set to notDefined,
+// weighs as its value in defaults, if it has one; def is the weight of omitted metrics without a default
+func (wms WeightsMetrics) WeightOmitted(metric, notDefined string, defaults Defaults, def float64) float64 {
+	if value, ok := wms.Metrics[metric]; ok && value != notDefined {
+		return wms.Weights[metric][value]
+	}
+	if value, ok := defaults[metric]; ok {
+		if w, ok := wms.Weights[metric][value]; ok {
+			return w
+		}
+	}
+	return def
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	weights := map[string]map[string]float64{"A": {"B": 0.5, "C": 0.8, "ND": 1}, "D": {"E": 0.9}}
+	defaults := Defaults{"A": "C"}
+	if err := defaults.Validate(weights, "A"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Defaults{"D": "E"}).Validate(weights, "A"); err != (ErrUnknownMetric{Metric: "D"}) {
+		t.Errorf("expected unknown metric D, got %v", err)
+	}
+	if err := (Defaults{"A": "Z"}).Validate(weights, "A"); err != (ErrInvalidValue{Metric: "A", Value: "Z"}) {
+		t.Errorf("expected invalid value of A, got %v", err)
+	}
+
+	cases := []struct {
+		str    string
+		weight float64
+	}{
+		{"D:E", 0.8},
+		{"A:ND", 0.8},
+		{"A:B", 0.5},
+	}
+	for _, c := range cases {
+		wms := WeightsMetrics{Metrics: make(Metrics), Weights: weights}
+		if err := wms.Parse(c.str); err != nil {
+			t.Fatal(err)
+		}
+		if w := wms.WeightOmitted("A", "ND", defaults, 1); w != c.weight {
+			t.Errorf("%s: expected weight %.1f, got %.1f", c.str, c.weight, w)
+		}
+		if w := wms.WeightOmitted("A", "ND", nil, 1); c.str != "A:B" && w != 1 {
+			t.Errorf("%s: expected weight 1 without defaults, got %.1f", c.str, w)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Enumerate sets metrics of wms to every combination of their values defined by weights and calls fn for each of them.
+// The order is deterministic: the last metric varies the fastest and values of each metric go in lexical order.
+// Combinations are made one by one, so metrics must not be retained by fn; enumeration stops if fn returns false.
+func (wms WeightsMetrics) Enumerate(metrics []string, fn func() bool) error {
+	values := make([][]string, len(metrics))
+	for i, metric := range metrics {
+		weights, ok := wms.Weights[metric]
+		if !ok {
+			return ErrUnknownMetric{Metric: metric}
+		}
+		for value := range weights {
+			values[i] = append(values[i], value)
+		}
+		sort.Strings(values[i])
+	}
+	var next func(i int) bool
+	next = func(i int) bool {
+		if i == len(metrics) {
+			return fn()
+		}
+		for _, value := range values[i] {
+			if err := wms.Metrics.Set(metrics[i], value); err != nil {
+				panic(err) // metrics not initialized
+			}
+			if !next(i + 1) {
+				return false
+			}
+		}
+		return true
+	}
+	next(0)
+	return nil
+}
+
+// ScoreRange returns the minimum and maximum of score over all completions of the metrics wms lacks:
+// each combination of values of the missing ones (see Enumerate) is set in turn and scored by score.
+// Metrics of wms are set directly, the missing ones are unset afterwards; vectors which must not change
+// should be copied first. Metrics set in all of them score the same minimum and maximum.
+func (wms WeightsMetrics) ScoreRange(metrics []string, score func() float64) (min, max float64, err error) {
+	var missing []string
+	for _, metric := range metrics {
+		if !wms.Has(metric) {
+			missing = append(missing, metric)
+		}
+	}
+	defer func() {
+		for _, metric := range missing {
+			delete(wms.Metrics, metric)
+		}
+	}()
+	first := true
+	err = wms.Enumerate(missing, func() bool {
+		s := score()
+		if first || s < min {
+			min = s
+		}
+		if first || s > max {
+			max = s
+		}
+		first = false
+		return true
+	})
+	return min, max, err
+}
+
+// MetricDelta is the contribution of the value of a metric to a score, see Sensitivity
+type MetricDelta struct {
+	Metric string
+	Value  string
+	Delta  float64
+}
+
+// Sensitivity returns the contribution of each of the metrics to score, in the same order: the score less
+// the mean of its scores with the metric set to each of its values defined by weights in turn, i.e. less the score
+// expected were the metric unknown; e.g. AC:L of CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H (9.8) contributes 0.85,
+// as the vector scores 8.1 with AC:H.
+// Metrics of wms are set directly and restored afterwards; vectors which must not change should be copied first.
+// All the metrics must be set.
+func (wms WeightsMetrics) Sensitivity(metrics []string, score func() float64) ([]MetricDelta, error) {
+	for _, metric := range metrics {
+		if !wms.Has(metric) {
+			return nil, fmt.Errorf("sensitivity: metric %q not defined", metric)
+		}
+	}
+	s := score()
+	deltas := make([]MetricDelta, len(metrics))
+	for i, metric := range metrics {
+		value := wms.Metrics[metric]
+		var sum float64
+		var n int
+		err := wms.Enumerate([]string{metric}, func() bool {
+			sum += score()
+			n++
+			return true
+		})
+		wms.Metrics[metric] = value
+		if err != nil {
+			return nil, err
+		}
+		deltas[i] = MetricDelta{Metric: metric, Value: value, Delta: s - sum/float64(n)}
+	}
+	return deltas, nil
+}
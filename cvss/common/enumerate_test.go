@@ -0,0 +1,99 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: map[string]map[string]float64{
+		"A": {"X": 0, "Y": 0},
+		"B": {"3": 0, "1": 0, "2": 0},
+	}}
+	var all []string
+	if err := wms.Enumerate([]string{"A", "B"}, func() bool {
+		all = append(all, wms.String())
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"A:X/B:1", "A:X/B:2", "A:X/B:3", "A:Y/B:1", "A:Y/B:2", "A:Y/B:3"}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("expected %v, got %v", expected, all)
+	}
+
+	n := 0
+	wms.Enumerate([]string{"A", "B"}, func() bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Errorf("enumeration expected to stop after 2 combinations, got %d", n)
+	}
+
+	if err := wms.Enumerate([]string{"C"}, func() bool { return true }); err == nil {
+		t.Error("expected an error for unknown metric")
+	}
+}
+
+func TestScoreRange(t *testing.T) {
+	wms := WeightsMetrics{Metrics: Metrics{"A": "Y"}, Weights: map[string]map[string]float64{
+		"A": {"X": 1, "Y": 2},
+		"B": {"1": 10, "2": 20, "3": 30},
+	}}
+	score := func() float64 { return wms.WeightMust("A") * wms.WeightMust("B") }
+	min, max, err := wms.ScoreRange([]string{"A", "B"}, score)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min != 20 || max != 60 {
+		t.Errorf("expected range 20-60, got %v-%v", min, max)
+	}
+	if expected := (Metrics{"A": "Y"}); !reflect.DeepEqual(wms.Metrics, expected) {
+		t.Errorf("expected missing metrics to be unset, got %v", wms.Metrics)
+	}
+	wms.Metrics["B"] = "1"
+	if min, max, _ = wms.ScoreRange([]string{"A", "B"}, score); min != 20 || max != 20 {
+		t.Errorf("expected complete metrics to score 20, got %v-%v", min, max)
+	}
+	if _, _, err = wms.ScoreRange([]string{"C"}, score); err == nil {
+		t.Error("expected an error for unknown metric")
+	}
+}
+
+func TestSensitivity(t *testing.T) {
+	wms := WeightsMetrics{Metrics: Metrics{"A": "Y", "B": "3"}, Weights: map[string]map[string]float64{
+		"A": {"X": 1, "Y": 2},
+		"B": {"1": 10, "2": 20, "3": 30},
+	}}
+	score := func() float64 { return wms.WeightMust("A") * wms.WeightMust("B") }
+	deltas, err := wms.Sensitivity([]string{"A", "B"}, score)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 60 less the mean of 30 and 60, 60 less the mean of 20, 40 and 60
+	expected := []MetricDelta{{"A", "Y", 15}, {"B", "3", 20}}
+	if !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("expected %v, got %v", expected, deltas)
+	}
+	if s := wms.String(); s != "A:Y/B:3" {
+		t.Errorf("metrics expected to be restored, got %s", s)
+	}
+	if _, err := wms.Sensitivity([]string{"A", "C"}, score); err == nil {
+		t.Error("expected an error for metric not set")
+	}
+}
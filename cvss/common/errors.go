@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+)
+
+// ErrUnknownMetric is returned when the metric isn't defined for the vector
+type ErrUnknownMetric struct {
+	Metric string
+}
+
+// Error implements error interface
+func (e ErrUnknownMetric) Error() string {
+	return fmt.Sprintf("metric %q not defined for vector", e.Metric)
+}
+
+// ErrInvalidValue is returned when the value isn't defined for the metric of the vector
+type ErrInvalidValue struct {
+	Metric string
+	Value  string
+}
+
+// Error implements error interface
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("can't set metric %q to %q", e.Metric, e.Value)
+}
+
+// ErrFrozen is returned when a metric of frozen vector is set, see WeightsMetrics.Freeze
+type ErrFrozen struct {
+	Metric string
+}
+
+// Error implements error interface
+func (e ErrFrozen) Error() string {
+	return fmt.Sprintf("can't set metric %q of frozen vector", e.Metric)
+}
+
+// ErrScoreOutOfRange is returned when the computed score is outside of the CVSS range of 0.0 to 10.0,
+// which reveals a broken weight table, see CheckScore
+type ErrScoreOutOfRange struct {
+	Score float64
+}
+
+// Error implements error interface
+func (e ErrScoreOutOfRange) Error() string {
+	return fmt.Sprintf("score %v out of range [0.0, 10.0], the weight table is broken", e.Score)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// MetricDefinition is the human-readable name of a metric and of its values, as in the specification
+type MetricDefinition struct {
+	Name   string
+	Values map[string]string // value names, e.g. N: Network
+}
+
+// MetricDefinitions maps metric names to their definitions
+type MetricDefinitions map[string]MetricDefinition
+
+// Copy returns a copy of the definitions, which doesn't share the tables of value names with them
+func (defs MetricDefinitions) Copy() MetricDefinitions {
+	copied := make(MetricDefinitions, len(defs))
+	for metric, def := range defs {
+		values := make(map[string]string, len(def.Values))
+		for value, name := range def.Values {
+			values[value] = name
+		}
+		copied[metric] = MetricDefinition{Name: def.Name, Values: values}
+	}
+	return copied
+}
+
+// MetricExplanation explains the contribution of a metric of a vector to its score, suitable for JSON encoding
+type MetricExplanation struct {
+	Metric    string  `json:"metric"`    // e.g. AV
+	Name      string  `json:"name"`      // e.g. Attack Vector
+	Group     string  `json:"group"`     // e.g. Base, see MetricGroups.String
+	Value     string  `json:"value"`     // e.g. N
+	ValueName string  `json:"valueName"` // e.g. Network
+	Weight    float64 `json:"weight"`    // the weight the value contributes to the equations of the score
+}
+
+// Explain returns the explanations of the metrics of the vector in the given order (e.g. the order of the specification),
+// metrics missing in it follow in lexical order; names are taken from defs and groups from groups, mapping metric
+// names to their groups. The weights are the ones of the table, see WeightsMetrics.Weight.
+func (wms WeightsMetrics) Explain(order []string, defs MetricDefinitions, groups map[string]MetricGroups) []MetricExplanation {
+	metrics := make([]string, 0, len(wms.Metrics))
+	seen := make(map[string]bool, len(order))
+	for _, metric := range order {
+		if wms.Has(metric) && !seen[metric] {
+			metrics = append(metrics, metric)
+			seen[metric] = true
+		}
+	}
+	for _, metric := range wms.Keys() {
+		if !seen[metric] {
+			metrics = append(metrics, metric)
+		}
+	}
+	explanations := make([]MetricExplanation, len(metrics))
+	for i, metric := range metrics {
+		value := wms.Metrics[metric]
+		def := defs[metric]
+		explanations[i] = MetricExplanation{
+			Metric:    metric,
+			Name:      def.Name,
+			Group:     groups[metric].String(),
+			Value:     value,
+			ValueName: def.Values[value],
+			Weight:    wms.WeightDefault(metric, 0),
+		}
+	}
+	return explanations
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+)
+
+// MetricGroups is a set of metric groups
+type MetricGroups uint8
+
+// Metric groups of CVSS specifications
+const (
+	BaseGroup MetricGroups = 1 << iota
+	// TemporalGroup is called threat in CVSS v4
+	TemporalGroup
+	EnvironmentalGroup
+	// SupplementalGroup is defined by CVSS v4 only
+	SupplementalGroup
+)
+
+var groupNames = []struct {
+	group MetricGroups
+	name  string
+}{
+	{BaseGroup, "Base"},
+	{TemporalGroup, "Temporal"},
+	{EnvironmentalGroup, "Environmental"},
+	{SupplementalGroup, "Supplemental"},
+}
+
+// Has reports whether all the groups are in the set
+func (g MetricGroups) Has(groups MetricGroups) bool {
+	return g&groups == groups
+}
+
+// String returns names of the groups joined with " + ", e.g. "Base + Temporal", or "None" for the empty set
+func (g MetricGroups) String() string {
+	var names []string
+	for _, gn := range groupNames {
+		if g.Has(gn.group) {
+			names = append(names, gn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, " + ")
+}
+
+// AddGroup assigns the metrics to the group in groups, which maps metric names to their groups
+func AddGroup(groups map[string]MetricGroups, group MetricGroups, metrics ...string) {
+	for _, metric := range metrics {
+		groups[metric] = group
+	}
+}
+
+// GroupMetrics inverts groups, which maps metric names to their groups: it returns the metrics of every group
+// in the given order, e.g. the order of the specification. Metrics missing in order are left out.
+func GroupMetrics(groups map[string]MetricGroups, order []string) map[MetricGroups][]string {
+	metrics := make(map[MetricGroups][]string)
+	for _, metric := range order {
+		if group, ok := groups[metric]; ok {
+			metrics[group] = append(metrics[group], metric)
+		}
+	}
+	return metrics
+}
+
+// Completeness returns the groups which have any of their metrics set, as per groups mapping metrics to their groups;
+// metrics set to notDefined value (e.g. X or ND) don't count
+func (ms Metrics) Completeness(groups map[string]MetricGroups, notDefined string) MetricGroups {
+	var present MetricGroups
+	for metric, value := range ms {
+		if value != notDefined {
+			present |= groups[metric]
+		}
+	}
+	return present
+}
+
+// Defined returns a copy of the metrics without the ones outside of BaseGroup set to notDefined value (e.g. X or ND),
+// as per groups mapping metrics to their groups: such metrics don't affect the scores, so the vectors differing
+// only by them are equivalent
+func (ms Metrics) Defined(groups map[string]MetricGroups, notDefined string) Metrics {
+	defined := make(Metrics, len(ms))
+	for metric, value := range ms {
+		if value != notDefined || groups[metric] == BaseGroup {
+			defined[metric] = value
+		}
+	}
+	return defined
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetricGroups(t *testing.T) {
+	groups := map[string]MetricGroups{}
+	AddGroup(groups, BaseGroup, "A", "B")
+	AddGroup(groups, TemporalGroup, "T")
+	AddGroup(groups, SupplementalGroup, "S")
+	cases := []struct {
+		metrics  Metrics
+		expected MetricGroups
+		str      string
+	}{
+		{Metrics{}, 0, "None"},
+		{Metrics{"A": "1", "B": "2"}, BaseGroup, "Base"},
+		{Metrics{"A": "1", "T": "X"}, BaseGroup, "Base"},
+		{Metrics{"A": "1", "T": "1", "S": "1"}, BaseGroup | TemporalGroup | SupplementalGroup, "Base + Temporal + Supplemental"},
+		{Metrics{"Z": "1"}, 0, "None"}, // unknown metrics belong to no group
+	}
+	for _, c := range cases {
+		g := c.metrics.Completeness(groups, "X")
+		if g != c.expected || g.String() != c.str {
+			t.Errorf("%v: expected %s, got %s", c.metrics, c.str, g)
+		}
+	}
+	metrics := GroupMetrics(groups, []string{"T", "B", "A", "Z"})
+	if len(metrics) != 2 || len(metrics[BaseGroup]) != 2 || metrics[BaseGroup][0] != "B" || metrics[BaseGroup][1] != "A" ||
+		len(metrics[TemporalGroup]) != 1 || metrics[TemporalGroup][0] != "T" {
+		t.Errorf("unexpected metrics of groups in order: %v", metrics)
+	}
+	all := BaseGroup | TemporalGroup | EnvironmentalGroup
+	if !all.Has(BaseGroup|EnvironmentalGroup) || all.Has(SupplementalGroup) {
+		t.Errorf("unexpected Has of %s", all)
+	}
+}
+
+func TestDefined(t *testing.T) {
+	groups := map[string]MetricGroups{"AV": BaseGroup, "E": TemporalGroup, "CR": EnvironmentalGroup}
+	ms := Metrics{"AV": "X", "E": "X", "CR": "H"}
+	defined := ms.Defined(groups, "X")
+	if want := (Metrics{"AV": "X", "CR": "H"}); !reflect.DeepEqual(defined, want) {
+		t.Errorf("want %v, got %v", want, defined)
+	}
+	if len(ms) != 3 {
+		t.Errorf("the metrics were modified: %v", ms)
+	}
+}
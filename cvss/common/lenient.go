@@ -0,0 +1,52 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DedupMetrics removes metrics repeated with the same value from A:B/C:D string, e.g.
+// AV:N/AC:L/AV:N becomes AV:N/AC:L. Metrics repeated with conflicting values are an error.
+// Malformed parts are left intact for the parser to report.
+func DedupMetrics(str string) (string, error) {
+	parts := strings.Split(str, partSeparator)
+	seen := make(map[string]string, len(parts))
+	dedup := parts[:0]
+	for _, part := range parts {
+		tmp := strings.Split(part, metricSeparator)
+		if len(tmp) == 2 {
+			if value, ok := seen[tmp[0]]; ok {
+				if value != tmp[1] {
+					return "", fmt.Errorf("metric %q set to conflicting values %q and %q", tmp[0], value, tmp[1])
+				}
+				continue
+			}
+			seen[tmp[0]] = tmp[1]
+		}
+		dedup = append(dedup, part)
+	}
+	return strings.Join(dedup, partSeparator), nil
+}
+
+// ParseLenient is like Parse, but accepts metrics repeated with the same value, see DedupMetrics
+func (wms WeightsMetrics) ParseLenient(str string) error {
+	str, err := DedupMetrics(str)
+	if err != nil {
+		return err
+	}
+	return wms.Parse(str)
+}
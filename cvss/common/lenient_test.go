@@ -0,0 +1,52 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+)
+
+func TestDedupMetrics(t *testing.T) {
+	tests := map[string]string{
+		"A:B/C:D":         "A:B/C:D",
+		"A:B/C:D/A:B":     "A:B/C:D",
+		"A:B/A:B/A:B/C:D": "A:B/C:D",
+		"A:B/C/C":         "A:B/C/C", // malformed parts are left for the parser
+	}
+	for str, expected := range tests {
+		actual, err := DedupMetrics(str)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", str, err)
+		} else if actual != expected {
+			t.Errorf("%q: expected %q, got %q", str, expected, actual)
+		}
+	}
+	if _, err := DedupMetrics("A:B/C:D/A:C"); err == nil {
+		t.Error("expected an error for conflicting values")
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: map[string]map[string]float64{"A": {"B": 1, "C": 2}}}
+	if err := wms.Parse("A:B/A:B"); err == nil {
+		t.Error("strict parser expected to reject repeated metric")
+	}
+	if err := wms.ParseLenient("A:B/A:B"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := wms.ParseLenient("A:B/A:C"); err == nil {
+		t.Error("lenient parser expected to reject conflicting values")
+	}
+}
@@ -16,6 +16,7 @@ package common
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -38,23 +39,117 @@ func (ms Metrics) Get(m string) (string, error) {
 	return "", fmt.Errorf("metric %q not defined", m)
 }
 
+// Has reports whether the metric is set
+func (ms Metrics) Has(m string) bool {
+	_, ok := ms[m]
+	return ok
+}
+
+// HasMetricValue reports whether the metric is set to the value, e.g. AV to N, to compose policy rules
+// like score >= 7 and AV:N; metrics and values are case-sensitive, as in vector strings. Unset metrics have no value,
+// even if the specification assumes one for them (e.g. X, not defined).
+func (ms Metrics) HasMetricValue(metric, value string) bool {
+	v, ok := ms[metric]
+	return ok && v == value
+}
+
+// Only returns a copy of the metrics limited to the given ones
+func (ms Metrics) Only(metrics ...string) Metrics {
+	only := make(Metrics, len(metrics))
+	for _, metric := range metrics {
+		if value, ok := ms[metric]; ok {
+			only[metric] = value
+		}
+	}
+	return only
+}
+
+// Merge combines the metrics with other ones: metrics set in either of them or set to the same value in both
+// are merged, metrics set to different values are conflicts, mapped to the pair of values (ours, other's)
+// and left out of merged for the caller to resolve. Neither of the metrics is modified.
+func (ms Metrics) Merge(other Metrics) (merged Metrics, conflicts map[string][2]string) {
+	merged = make(Metrics, len(ms)+len(other))
+	for metric, value := range ms {
+		merged[metric] = value
+	}
+	for metric, value := range other {
+		ours, ok := merged[metric]
+		if !ok || ours == value {
+			merged[metric] = value
+			continue
+		}
+		if conflicts == nil {
+			conflicts = make(map[string][2]string)
+		}
+		conflicts[metric] = [2]string{ours, value}
+		delete(merged, metric)
+	}
+	return merged, conflicts
+}
+
 func (ms Metrics) Set(metric string, value string) error {
+	if ms == nil {
+		return fmt.Errorf("can't set metric %q: metrics not initialized", metric)
+	}
 	ms[metric] = value
 	return nil
 }
 
 func (ms Metrics) String() string {
-	var parts []string
-	for metric, value := range ms {
-		parts = append(parts, fmt.Sprintf("%s%s%s", metric, metricSeparator, value))
+	keys := ms.Keys()
+	parts := make([]string, len(keys))
+	for i, metric := range keys {
+		parts[i] = fmt.Sprintf("%s%s%s", metric, metricSeparator, ms[metric])
 	}
 	return strings.Join(parts, partSeparator)
 }
 
-// parse A:B/C:D into map{A:B, C:D}
+// CanonicalString returns metrics in the given order followed by the metrics missing in it in lexical order.
+// The output doesn't depend on the order metrics were set in, so it is suitable as e.g. a cache key.
+func (ms Metrics) CanonicalString(order []string) string {
+	parts := make([]string, 0, len(ms))
+	seen := make(map[string]bool, len(order))
+	for _, metric := range order {
+		if value, ok := ms[metric]; ok && !seen[metric] {
+			parts = append(parts, metric+metricSeparator+value)
+			seen[metric] = true
+		}
+	}
+	for _, metric := range ms.Keys() {
+		if !seen[metric] {
+			parts = append(parts, metric+metricSeparator+ms[metric])
+		}
+	}
+	return strings.Join(parts, partSeparator)
+}
+
+// Keys returns metrics names sorted in canonical (lexical) order
+func (ms Metrics) Keys() []string {
+	keys := make([]string, 0, len(ms))
+	for metric := range ms {
+		keys = append(keys, metric)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Values returns metrics values in the same order as Keys
+func (ms Metrics) Values() []string {
+	keys := ms.Keys()
+	values := make([]string, len(keys))
+	for i, metric := range keys {
+		values[i] = ms[metric]
+	}
+	return values
+}
+
+// parse A:B/C:D into map{A:B, C:D}; empty parts (e.g. A:B//C:D/) carry no information and are skipped
 func strToMetrics(str string) (Metrics, error) {
 	metrics := make(Metrics)
 	for _, part := range strings.Split(str, partSeparator) {
+		if part == "" {
+			continue
+		}
 		tmp := strings.Split(part, metricSeparator)
 		if len(tmp) != 2 {
 			return nil, fmt.Errorf("need two values separated by %s, got %q", metricSeparator, part)
@@ -67,29 +162,80 @@ func strToMetrics(str string) (Metrics, error) {
 	return metrics, nil
 }
 
-// WeightsMetrics uses weights to do Set and Parse metrics
+// MetricNames returns the names of metrics in A:B/C:D string in order of appearance, without validating it
+func MetricNames(str string) []string {
+	parts := strings.Split(str, partSeparator)
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if i := strings.Index(part, metricSeparator); i >= 0 {
+			names = append(names, part[:i])
+		}
+	}
+	return names
+}
+
+// WeightsMetrics uses weights to do Set and Parse metrics.
+// The unexported state of Freeze breaks unkeyed literals of it, which older code may have,
+// the fields must be named: WeightsMetrics{Metrics: make(Metrics), Weights: weights}
 type WeightsMetrics struct {
 	Metrics
 	Weights map[string]map[string]float64
+	frozen  bool
+}
+
+// Freeze returns a read-only copy of the metrics: Set and Parse of the copy return ErrFrozen.
+// The copy doesn't share Metrics with the original, so it's safe for concurrent reads
+// as long as Metrics isn't modified directly.
+func (wms WeightsMetrics) Freeze() WeightsMetrics {
+	metrics := make(Metrics, len(wms.Metrics))
+	for metric, value := range wms.Metrics {
+		metrics[metric] = value
+	}
+	return WeightsMetrics{Metrics: metrics, Weights: wms.Weights, frozen: true}
 }
 
+// WeightTable returns a copy of the weights, mapping metrics to the weights of their values
+func (wms WeightsMetrics) WeightTable() map[string]map[string]float64 {
+	table := make(map[string]map[string]float64, len(wms.Weights))
+	for metric, values := range wms.Weights {
+		table[metric] = make(map[string]float64, len(values))
+		for value, w := range values {
+			table[metric][value] = w
+		}
+	}
+	return table
+}
+
+// Frozen reports whether the metrics are read-only, see Freeze
+func (wms WeightsMetrics) Frozen() bool {
+	return wms.frozen
+}
+
+// Set returns ErrUnknownMetric or ErrInvalidValue if the metric or its value aren't defined by weights,
+// ErrFrozen if the metrics are frozen
 func (wms WeightsMetrics) Set(metric string, value string) error {
+	if wms.frozen {
+		return ErrFrozen{Metric: metric}
+	}
 	values, ok := wms.Weights[metric]
 	if !ok {
-		return fmt.Errorf("metric %q not defined for vector", metric)
+		return ErrUnknownMetric{Metric: metric}
 	}
 	if _, ok = values[value]; !ok {
-		return fmt.Errorf("can't set metric %q to %q", metric, value)
+		return ErrInvalidValue{Metric: metric, Value: value}
 	}
 	return wms.Metrics.Set(metric, value)
 }
 
+// Parse sets metrics of A:B/C:D string; errors of Set are wrapped, so they're available via errors.As
 func (wms WeightsMetrics) Parse(str string) error {
 	metrics, err := strToMetrics(str)
 	if err != nil {
 		return errors.Wrapf(err, "unable to parse metrics")
 	}
-	for metric, value := range metrics {
+	// set in order of appearance, so the error is about the first bad metric
+	for _, metric := range MetricNames(str) {
+		value := metrics[metric]
 		if err = wms.Set(metric, value); err != nil {
 			return errors.Wrapf(err, "unable to set metric %q to %q", metric, value)
 		}
@@ -122,3 +268,23 @@ func (wms WeightsMetrics) WeightDefault(metric string, def float64) float64 {
 	}
 	return def
 }
+
+// MetricWeight is a metric of vector, its value and the weight it contributes to the score with
+type MetricWeight struct {
+	Metric string
+	Value  string
+	Weight float64
+}
+
+// MetricWeights returns given metrics with their values and weights in the same order
+func (wms WeightsMetrics) MetricWeights(metrics ...string) ([]MetricWeight, error) {
+	mws := make([]MetricWeight, len(metrics))
+	for i, metric := range metrics {
+		w, err := wms.Weight(metric)
+		if err != nil {
+			return nil, err
+		}
+		mws[i] = MetricWeight{Metric: metric, Value: wms.Metrics[metric], Weight: w}
+	}
+	return mws, nil
+}
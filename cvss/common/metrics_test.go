@@ -15,6 +15,7 @@
 package common
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -39,6 +40,22 @@ func TestStrToMetrics(t *testing.T) {
 	}
 }
 
+func TestStrToMetricsEmptyParts(t *testing.T) {
+	expected := Metrics{"A": "B", "C": "D"}
+	for _, str := range []string{"/A:B/C:D", "A:B/C:D/", "A:B//C:D", "//A:B///C:D//"} {
+		if m, err := strToMetrics(str); err != nil {
+			t.Errorf("%q: %v", str, err)
+		} else if !reflect.DeepEqual(m, expected) {
+			t.Errorf("parsed %s incorrectly, expecting %q, got %q", str, expected, m)
+		}
+	}
+	for _, str := range []string{"A:B/ /C:D", "A:B/C/"} {
+		if _, err := strToMetrics(str); err == nil {
+			t.Errorf("shouldn't be able to parse %q", str)
+		}
+	}
+}
+
 func TestMetrics(t *testing.T) {
 	metrics, _ := strToMetrics("A:B/C:D")
 	if b, err := metrics.Get("A"); err != nil || b != "B" {
@@ -52,9 +69,22 @@ func TestMetrics(t *testing.T) {
 	}
 }
 
+func TestMetricsKeysValues(t *testing.T) {
+	metrics := Metrics{"C": "D", "X": "Y", "A": "B"}
+	if keys := metrics.Keys(); !reflect.DeepEqual(keys, []string{"A", "C", "X"}) {
+		t.Errorf("unexpected keys order: %q", keys)
+	}
+	if values := metrics.Values(); !reflect.DeepEqual(values, []string{"B", "D", "Y"}) {
+		t.Errorf("values aren't aligned with keys: %q", values)
+	}
+	if s := metrics.String(); s != "A:B/C:D/X:Y" {
+		t.Errorf("unexpected string representation: %q", s)
+	}
+}
+
 func TestWeightsMetrics(t *testing.T) {
 	weights := map[string]map[string]float64{"A": {"B": 1, "C": 2}}
-	wms := WeightsMetrics{make(Metrics), weights}
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: weights}
 
 	// test set
 	if wms.Set("A", "B") != nil {
@@ -83,3 +113,136 @@ func TestWeightsMetrics(t *testing.T) {
 		t.Errorf("should return an error on invalid metric")
 	}
 }
+
+func TestParseWithSource(t *testing.T) {
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: map[string]map[string]float64{"AV": {"N": 1}}}
+	err := wms.ParseWithSource("AV:Q", "line 42")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if expected := `line 42: unable to set metric "AV" to "Q": can't set metric "AV" to "Q"`; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+	var se *SourceError
+	if !errors.As(err, &se) || se.Source != "line 42" {
+		t.Errorf("expected source error attributed to line 42, got %#v", err)
+	}
+	if err := wms.ParseWithSource("AV:N", "line 43"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := WithSource(errors.New("test"), ""); err.Error() != "test" {
+		t.Errorf("expected error to be unmodified for empty source, got %q", err.Error())
+	}
+}
+
+func TestMetricsHas(t *testing.T) {
+	wms := WeightsMetrics{Metrics: Metrics{"AV": "N", "MAV": ""}}
+	for metric, expected := range map[string]bool{"AV": true, "MAV": true, "AC": false} {
+		if actual := wms.Has(metric); actual != expected {
+			t.Errorf("Has(%q): expected %t, got %t", metric, expected, actual)
+		}
+	}
+}
+
+func TestMetricsHasMetricValue(t *testing.T) {
+	wms := WeightsMetrics{Metrics: Metrics{"AV": "N", "MAV": ""}}
+	cases := []struct {
+		metric, value string
+		expected      bool
+	}{
+		{"AV", "N", true},
+		{"AV", "L", false},
+		{"AV", "n", false},
+		{"MAV", "", true},
+		{"AC", "", false},
+		{"E", "X", false},
+	}
+	for _, c := range cases {
+		if actual := wms.HasMetricValue(c.metric, c.value); actual != c.expected {
+			t.Errorf("HasMetricValue(%q, %q): expected %t, got %t", c.metric, c.value, c.expected, actual)
+		}
+	}
+}
+
+func TestMetricsMerge(t *testing.T) {
+	ms := Metrics{"AV": "N", "AC": "L", "C": "H"}
+	other := Metrics{"AV": "N", "AC": "H", "I": "L"}
+	merged, conflicts := ms.Merge(other)
+	if expected := (Metrics{"AV": "N", "C": "H", "I": "L"}); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected merged %v, got %v", expected, merged)
+	}
+	if expected := map[string][2]string{"AC": {"L", "H"}}; !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected conflicts %v, got %v", expected, conflicts)
+	}
+	if len(ms) != 3 || len(other) != 3 || ms["AC"] != "L" {
+		t.Errorf("merge modified its inputs: %v, %v", ms, other)
+	}
+	merged, conflicts = ms.Merge(nil)
+	if !reflect.DeepEqual(merged, ms) || conflicts != nil {
+		t.Errorf("expected merge with nil to copy the metrics, got %v, %v", merged, conflicts)
+	}
+}
+
+func TestUninitializedMetrics(t *testing.T) {
+	weights := map[string]map[string]float64{"AV": {"N": 1}}
+	for _, wms := range []WeightsMetrics{{}, {Weights: weights}} {
+		if err := wms.Parse("AV:N"); err == nil {
+			t.Errorf("expected an error when parsing into uninitialized metrics %#v", wms)
+		}
+	}
+}
+
+func TestCanonicalString(t *testing.T) {
+	ms := Metrics{"C": "D", "A": "B", "X": "Y", "E": "F"}
+	if s := ms.CanonicalString([]string{"E", "C", "Z", "C"}); s != "E:F/C:D/A:B/X:Y" {
+		t.Errorf("unexpected canonical string %q", s)
+	}
+	if s := ms.CanonicalString(nil); s != ms.String() {
+		t.Errorf("expected lexical order %q, got %q", ms.String(), s)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: map[string]map[string]float64{"AV": {"N": 1}, "AC": {"L": 1}}}
+
+	err := wms.Parse("AV:N/XX:Y/AC:H")
+	var unknown ErrUnknownMetric
+	if !errors.As(err, &unknown) || unknown.Metric != "XX" {
+		t.Errorf("expected unknown metric XX, got %v", err)
+	} else if err.Error() != `unable to set metric "XX" to "Y": metric "XX" not defined for vector` {
+		t.Errorf("unexpected message %q", err)
+	}
+
+	err = wms.Parse("AC:H/AV:X")
+	var invalid ErrInvalidValue
+	if !errors.As(err, &invalid) || invalid != (ErrInvalidValue{Metric: "AC", Value: "H"}) {
+		t.Errorf("expected invalid value H of AC, got %v", err)
+	} else if err.Error() != `unable to set metric "AC" to "H": can't set metric "AC" to "H"` {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: map[string]map[string]float64{"AV": {"N": 1, "L": 0.5}}}
+	if err := wms.Parse("AV:N"); err != nil {
+		t.Fatal(err)
+	}
+	frozen := wms.Freeze()
+	if !frozen.Frozen() || wms.Frozen() {
+		t.Fatal("only the copy is expected to be frozen")
+	}
+	var ef ErrFrozen
+	if err := frozen.Set("AV", "L"); !errors.As(err, &ef) || ef.Metric != "AV" {
+		t.Errorf("expected ErrFrozen, got %v", err)
+	}
+	if err := frozen.Parse("AV:L"); !errors.As(err, &ef) {
+		t.Errorf("expected ErrFrozen, got %v", err)
+	}
+	// the original stays mutable and doesn't affect the frozen copy
+	if err := wms.Set("AV", "L"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := frozen.Get("AV"); v != "N" || frozen.WeightMust("AV") != 1 {
+		t.Errorf("frozen copy was modified: AV:%s", v)
+	}
+}
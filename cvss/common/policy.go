@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action tells how to handle a metric or value the vector doesn't define, see Policy
+type Action int
+
+// Possible values of Action
+const (
+	// Reject fails parsing with ErrUnknownMetric or ErrInvalidValue, as Parse does
+	Reject Action = iota
+	// Accept keeps the metric in the vector verbatim, it doesn't contribute to the score. Only metrics the vector
+	// doesn't define can be accepted: values of defined metrics without a weight are rejected.
+	Accept
+	// Ignore drops the metric
+	Ignore
+)
+
+// Policy decides how to handle the metric set to value when either of them isn't defined for the vector;
+// nil policy rejects them all
+type Policy func(metric, value string) Action
+
+// StrictPolicy rejects all unknown metrics and values, it's the behavior of Parse
+func StrictPolicy(metric, value string) Action {
+	return Reject
+}
+
+// FilterUnknown applies policy to A:B/C:D str: it returns str without the ignored and accepted metrics,
+// and the accepted metrics separately; rejected and malformed parts are left intact for the parser to report.
+// Version prefix (e.g. CVSS:3.0) is always left intact.
+func FilterUnknown(str string, weights map[string]map[string]float64, policy Policy) (string, Metrics, error) {
+	if policy == nil {
+		return str, nil, nil
+	}
+	parts := strings.Split(str, partSeparator)
+	known := parts[:0]
+	var accepted Metrics
+	for _, part := range parts {
+		tmp := strings.Split(part, metricSeparator)
+		if len(tmp) != 2 || strings.EqualFold(tmp[0], "CVSS") {
+			known = append(known, part)
+			continue
+		}
+		metric, value := tmp[0], tmp[1]
+		values, defined := weights[metric]
+		if _, ok := values[value]; ok {
+			known = append(known, part)
+			continue
+		}
+		switch action := policy(metric, value); {
+		case action == Ignore:
+		case action == Accept && !defined:
+			if accepted.Has(metric) {
+				return "", nil, fmt.Errorf("metric %q already set", metric)
+			}
+			if accepted == nil {
+				accepted = make(Metrics)
+			}
+			accepted[metric] = value
+		default:
+			known = append(known, part)
+		}
+	}
+	return strings.Join(known, partSeparator), accepted, nil
+}
+
+// SetVerbatim sets metrics bypassing the weights, e.g. the ones accepted by policy (see FilterUnknown);
+// it returns ErrFrozen if the metrics are frozen
+func (wms WeightsMetrics) SetVerbatim(metrics Metrics) error {
+	for _, metric := range metrics.Keys() {
+		if wms.frozen {
+			return ErrFrozen{Metric: metric}
+		}
+		if err := wms.Metrics.Set(metric, metrics[metric]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseWithPolicy is like Parse, but metrics and values not defined by weights are handled as per policy
+func (wms WeightsMetrics) ParseWithPolicy(str string, policy Policy) error {
+	known, accepted, err := FilterUnknown(str, wms.Weights, policy)
+	if err != nil {
+		return err
+	}
+	if err = wms.Parse(known); err != nil {
+		return err
+	}
+	return wms.SetVerbatim(accepted)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseWithPolicy(t *testing.T) {
+	weights := map[string]map[string]float64{"A": {"B": 1, "C": 2}, "D": {"E": 1}}
+	// vendor extensions X-* are accepted, unknown values of D are ignored
+	policy := func(metric, value string) Action {
+		switch {
+		case len(metric) > 2 && metric[:2] == "X-":
+			return Accept
+		case metric == "D":
+			return Ignore
+		case metric == "A":
+			return Accept // can't be accepted, A is defined
+		}
+		return Reject
+	}
+	cases := []struct {
+		str      string
+		expected string
+		err      error
+	}{
+		{"A:B/D:E", "A:B/D:E", nil},
+		{"A:B/D:Z/X-FOO:1", "A:B/X-FOO:1", nil},
+		{"A:Z/D:E", "", ErrInvalidValue{Metric: "A", Value: "Z"}},
+		{"A:B/F:G", "", ErrUnknownMetric{Metric: "F"}},
+	}
+	for _, c := range cases {
+		wms := WeightsMetrics{Metrics: make(Metrics), Weights: weights}
+		err := wms.ParseWithPolicy(c.str, policy)
+		if c.err != nil {
+			if errors.Cause(err) != c.err {
+				t.Errorf("%q: expected error %v, got %v", c.str, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.str, err)
+		} else if wms.String() != c.expected {
+			t.Errorf("%q: expected %q, got %q", c.str, c.expected, wms.String())
+		}
+	}
+	for _, p := range []Policy{nil, StrictPolicy} {
+		wms := WeightsMetrics{Metrics: make(Metrics), Weights: weights}
+		if err := wms.ParseWithPolicy("A:B/X-FOO:1", p); errors.Cause(err) != (ErrUnknownMetric{Metric: "X-FOO"}) {
+			t.Errorf("strict policy expected to reject unknown metric, got %v", err)
+		}
+	}
+	wms := WeightsMetrics{Metrics: make(Metrics), Weights: weights}
+	if err := wms.ParseWithPolicy("X-FOO:1/X-FOO:2", policy); err == nil {
+		t.Error("expected an error for repeated accepted metric")
+	}
+	frozen := WeightsMetrics{Metrics: make(Metrics), Weights: weights}.Freeze()
+	if err := frozen.ParseWithPolicy("X-FOO:1", policy); err == nil {
+		t.Error("expected frozen metrics to reject accepted metric")
+	}
+}
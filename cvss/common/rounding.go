@@ -0,0 +1,87 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+)
+
+// Rounding is a strategy used to round intermediate and final scores to one decimal
+type Rounding interface {
+	Round(x float64) float64
+}
+
+// RoundingFunc allows to use an ordinary function as a Rounding strategy
+type RoundingFunc func(float64) float64
+
+// Round implements Rounding interface
+func (f RoundingFunc) Round(x float64) float64 {
+	return f(x)
+}
+
+// Built-in rounding strategies
+var (
+	// RoundUp rounds up to one decimal, as defined by CVSS v3 specification
+	RoundUp Rounding = RoundingFunc(func(x float64) float64 {
+		return math.Ceil(x*10) / 10
+	})
+	// RoundUpV31 rounds up to one decimal as defined by CVSS v3.1 specification: unlike RoundUp it's immune to
+	// floating point errors, e.g. 4.000000000000001 (which should be 4.0) rounds to 4.0 rather than 4.1
+	RoundUpV31 Rounding = RoundingFunc(func(x float64) float64 {
+		i := math.Round(x * 100000)
+		if math.Mod(i, 10000) == 0 {
+			return i / 100000
+		}
+		return (math.Floor(i/10000) + 1) / 10
+	})
+	// RoundHalfUp rounds to the nearest decimal, halves away from zero, as defined by CVSS v2 specification
+	RoundHalfUp Rounding = RoundingFunc(func(x float64) float64 {
+		return math.Round(x*10) / 10
+	})
+	// RoundHalfEven rounds to the nearest decimal, halves to the even one
+	RoundHalfEven Rounding = RoundingFunc(func(x float64) float64 {
+		return math.RoundToEven(x*10) / 10
+	})
+	// NoRounding keeps full precision, e.g. for statistics over scores; the scores computed with it aren't the ones
+	// of the specification
+	NoRounding Rounding = RoundingFunc(func(x float64) float64 {
+		return x
+	})
+	// Truncate drops everything after the first decimal
+	Truncate Rounding = RoundingFunc(func(x float64) float64 {
+		return math.Trunc(x*10) / 10
+	})
+)
+
+// ClampScore limits the score to the CVSS range of 0.0 to 10.0. The standard weight tables never score out of
+// the range, custom ones (see WeightsMetrics.Weights) might; NaN isn't clamped, see CheckScore
+func ClampScore(score float64) float64 {
+	switch {
+	case score < 0:
+		return 0
+	case score > 10:
+		return 10
+	default:
+		return score
+	}
+}
+
+// CheckScore returns ErrScoreOutOfRange if the score, NaN included, is outside of the CVSS range of 0.0 to 10.0
+func CheckScore(score float64) error {
+	if score >= 0 && score <= 10 {
+		return nil
+	}
+	return ErrScoreOutOfRange{Score: score}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	cases := []struct {
+		name     string
+		r        Rounding
+		x        float64
+		expected float64
+	}{
+		{"RoundUp", RoundUp, 1.51, 1.6},
+		{"RoundUp", RoundUp, 1.50, 1.5},
+		{"RoundUp", RoundUp, 4.000000000000001, 4.1}, // floating point error
+		{"RoundUpV31", RoundUpV31, 4.000000000000001, 4.0},
+		{"RoundUpV31", RoundUpV31, 1.51, 1.6},
+		{"RoundUpV31", RoundUpV31, 1.50, 1.5},
+		{"RoundUpV31", RoundUpV31, 0, 0},
+		{"RoundHalfUp", RoundHalfUp, 1.55, 1.6},
+		{"RoundHalfUp", RoundHalfUp, 1.54, 1.5},
+		{"RoundHalfEven", RoundHalfEven, 0.25, 0.2},
+		{"RoundHalfEven", RoundHalfEven, 0.35, 0.4},
+		{"Truncate", Truncate, 1.59, 1.5},
+		{"NoRounding", NoRounding, 1.5432, 1.5432},
+		{"RoundingFunc", RoundingFunc(func(float64) float64 { return 42 }), 1.0, 42},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s(%.2f)", c.name, c.x), func(t *testing.T) {
+			if actual := c.r.Round(c.x); actual != c.expected {
+				t.Errorf("expected %.1f, actual %.1f", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClampScore(t *testing.T) {
+	cases := []struct {
+		score, clamped float64
+		inRange        bool
+	}{
+		{0, 0, true},
+		{7.5, 7.5, true},
+		{10, 10, true},
+		{-0.1, 0, false},
+		{28.8, 10, false},
+	}
+	for _, c := range cases {
+		if clamped := ClampScore(c.score); clamped != c.clamped {
+			t.Errorf("%v: expected to be clamped to %v, got %v", c.score, c.clamped, clamped)
+		}
+		if err := CheckScore(c.score); (err == nil) != c.inRange {
+			t.Errorf("%v: expected in range %t, got error %v", c.score, c.inRange, err)
+		}
+	}
+	if err := CheckScore(math.NaN()); err == nil {
+		t.Error("NaN is expected out of range")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+)
+
+// SourceError attributes an error to the input which caused it,
+// e.g. file name and line number or an arbitrary label
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+// Error implements error interface
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying error, compatible with github.com/pkg/errors
+func (e *SourceError) Cause() error {
+	return e.Err
+}
+
+// WithSource attributes err to the source, e.g. "line 42";
+// it returns err unmodified if it's nil or source is empty
+func WithSource(err error, source string) error {
+	if err == nil || source == "" {
+		return err
+	}
+	return &SourceError{Source: source, Err: err}
+}
+
+// ParseWithSource is like Parse, but attributes the error (if any) to the source
+func (wms WeightsMetrics) ParseWithSource(str, source string) error {
+	return WithSource(wms.Parse(str), source)
+}
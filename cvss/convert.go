@@ -0,0 +1,385 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+	"github.com/facebookincubator/nvdtools/cvss/v4"
+)
+
+// Convert converts the vector into APPROXIMATE vector of the CVSS version (see NewVector for the versions),
+// e.g. to score assessments of mixed versions on a single scale. The mapping is best-effort, following the guidance
+// of the specifications where there's one; the notes returned describe where the information was lost, so
+// a conversion without notes is lossless. Vectors of v2 and v4 are converted to each other through v3.1, and
+// vectors of the same major version keep their metrics (scores of v3.0 and v3.1 equations may still differ).
+// See DowngradeV3, UpgradeV2, V3ToV4 and V4ToV3 for the mappings.
+func Convert(v Vector, version string) (Vector, []string, error) {
+	to, err := NewVector(version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert: %v", err)
+	}
+	if err := v.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("convert: %v", err)
+	}
+	switch from := v.(type) {
+	case v2.Vector:
+		switch to := to.(type) {
+		case v2.Vector:
+			return to, nil, copyMetrics(to, from)
+		case v3.Vector:
+			v3v, notes, err := UpgradeV2(from)
+			if err != nil {
+				return nil, nil, err
+			}
+			return v3v, notes, v3v.SetVersion(to.Version())
+		case v4.Vector:
+			v3v, notes, err := UpgradeV2(from)
+			if err != nil {
+				return nil, nil, err
+			}
+			v4v, more, err := V3ToV4(v3v)
+			return v4v, append(notes, more...), err
+		}
+	case v3.Vector:
+		switch to := to.(type) {
+		case v2.Vector:
+			return DowngradeV3(from)
+		case v3.Vector:
+			if err := copyMetrics(to, from); err != nil {
+				return nil, nil, err
+			}
+			return to, nil, to.SetVersion(version3(version))
+		case v4.Vector:
+			return V3ToV4(from)
+		}
+	case v4.Vector:
+		switch to := to.(type) {
+		case v2.Vector:
+			v3v, notes, err := V4ToV3(from)
+			if err != nil {
+				return nil, nil, err
+			}
+			v2v, more, err := DowngradeV3(v3v)
+			return v2v, append(notes, more...), err
+		case v3.Vector:
+			v3v, notes, err := V4ToV3(from)
+			if err != nil {
+				return nil, nil, err
+			}
+			return v3v, notes, v3v.SetVersion(version3(version))
+		case v4.Vector:
+			return to, nil, copyMetrics(to, from)
+		}
+	}
+	return nil, nil, fmt.Errorf("convert: unsupported vector type %T", v)
+}
+
+// ConvertVector parses the vector of any supported CVSS version (see ScoreAndSeverity) and converts it, see Convert
+func ConvertVector(vector, version string) (Vector, []string, error) {
+	from := "2"
+	if m := versionRe.FindStringSubmatch(vector); m != nil {
+		from = m[1]
+	}
+	v, err := NewVector(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	if err = v.Parse(vector); err != nil {
+		return nil, nil, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	return Convert(v, version)
+}
+
+// version3 returns the minor version of CVSS v3 version, 3 is 3.0
+func version3(version string) string {
+	if version == "3" {
+		return "3.0"
+	}
+	return version
+}
+
+// copyMetrics sets the metrics of the vector of the same version to the vector
+func copyMetrics(to, from Vector) error {
+	if err := to.Parse(from.String()); err != nil {
+		return fmt.Errorf("convert: %v", err)
+	}
+	return nil
+}
+
+// converter sets metrics of the target vector by the values of the source vector, collecting the notes
+type converter struct {
+	from  Vector
+	to    Vector
+	notes []string
+	err   error
+}
+
+// get returns the value of the metric of the source vector, empty if it's not defined (X or ND)
+func (c *converter) get(metric string) string {
+	value, _ := c.from.Get(metric)
+	if value == "X" || value == "ND" {
+		return ""
+	}
+	return value
+}
+
+func (c *converter) set(metric, value string) {
+	if c.err == nil && value != "" {
+		if err := c.to.Set(metric, value); err != nil {
+			c.err = fmt.Errorf("convert: %v", err)
+		}
+	}
+}
+
+// mapValues sets the metric of the target vector to the value the metric of the source vector maps to,
+// identity if values is nil; undefined source metrics are skipped
+func (c *converter) mapValues(from, to string, values map[string]string) {
+	value := c.get(from)
+	if value == "" {
+		return
+	}
+	if values != nil {
+		mapped, ok := values[value]
+		if !ok {
+			if c.err == nil {
+				c.err = fmt.Errorf("convert: no mapping for metric %q value %q", from, value)
+			}
+			return
+		}
+		value = mapped
+	}
+	c.set(to, value)
+}
+
+func (c *converter) note(format string, args ...interface{}) {
+	c.notes = append(c.notes, fmt.Sprintf(format, args...))
+}
+
+// dropped adds the note if any of the metrics of the source vector is defined, they have no counterpart
+// in the target version
+func (c *converter) dropped(note string, metrics ...string) {
+	for _, metric := range metrics {
+		if c.get(metric) != "" {
+			c.notes = append(c.notes, note)
+			return
+		}
+	}
+}
+
+var (
+	// v2ToV3 maps values of CVSS v2 metrics to CVSS v3 metrics with the same name
+	v2ToV3 = map[string]map[string]string{
+		"C":  {"C": "H", "P": "L", "N": "N"},
+		"I":  {"C": "H", "P": "L", "N": "N"},
+		"A":  {"C": "H", "P": "L", "N": "N"},
+		"E":  {"U": "U", "POC": "P", "F": "F", "H": "H"},
+		"RL": {"OF": "O", "TF": "T", "W": "W", "U": "U"},
+		"RC": {"UC": "U", "UR": "R", "C": "C"},
+	}
+	// v3ToV4Exploit and v4ToV3Exploit map v3 exploit code maturity to v4 exploit maturity and back
+	v3ToV4Exploit = map[string]string{"U": "U", "P": "P", "F": "A", "H": "A"}
+	v4ToV3Exploit = map[string]string{"U": "U", "P": "P", "A": "H"}
+	// v3ToV4UI and v4ToV3UI map v3 user interaction to v4 and back, v4 tells passive from active interaction
+	v3ToV4UI = map[string]string{"N": "N", "R": "P"}
+	v4ToV3UI = map[string]string{"N": "N", "P": "R", "A": "R"}
+)
+
+// UpgradeV2 converts CVSS v2 vector into APPROXIMATE CVSS v3.1 vector, the inverse of DowngradeV3 where the versions
+// overlap. The mapping is best-effort and lossy, the notes returned describe where the information was lost:
+//
+//	AC:M becomes AC:L with UI:R, v3 has no medium access complexity and folds most of it into user interaction
+//	Au is mapped onto PR (N -> N, S -> L, M -> H) since v3 has no authentication metric
+//	scope is unchanged (S:U), v2 doesn't assess the impact beyond the vulnerable component
+//	impacts C(omplete) and P(artial) become H and L
+//	CDP and TD are dropped, v3 has no collateral damage potential nor target distribution
+func UpgradeV2(v2v v2.Vector) (v3.Vector, []string, error) {
+	if err := v2v.Validate(); err != nil {
+		return v3.Vector{}, nil, err
+	}
+	v3v := v3.NewVector()
+	if err := v3v.SetVersion("3.1"); err != nil {
+		return v3.Vector{}, nil, err
+	}
+	c := &converter{from: v2v, to: v3v}
+	c.mapValues("AV", "AV", nil)
+	switch c.get("AC") {
+	case "M":
+		c.set("AC", "L")
+		c.set("UI", "R")
+		c.note("AC:M mapped to AC:L/UI:R, medium access complexity isn't defined in v3")
+	default:
+		c.mapValues("AC", "AC", nil)
+		c.set("UI", "N")
+	}
+	pr := map[string]string{"N": "N", "S": "L", "M": "H"}[c.get("Au")]
+	c.set("PR", pr)
+	c.note("Au:%s mapped to PR:%s, authentication isn't defined in v3", c.get("Au"), pr)
+	c.set("S", "U")
+	c.note("S:U assumed, scope isn't defined in v2")
+	for _, metric := range []string{"C", "I", "A"} {
+		c.mapValues(metric, metric, v2ToV3[metric])
+		if value := c.get(metric); value != "N" {
+			c.note("%s:%s mapped to %s:%s", metric, value, metric, v2ToV3[metric][value])
+		}
+	}
+	for _, metric := range []string{"E", "RL", "RC"} {
+		c.mapValues(metric, metric, v2ToV3[metric])
+	}
+	for _, metric := range []string{"CR", "IR", "AR"} {
+		c.mapValues(metric, metric, nil)
+	}
+	c.dropped("CDP and TD dropped, collateral damage potential and target distribution aren't defined in v3", "CDP", "TD")
+	if c.err != nil {
+		return v3.Vector{}, nil, c.err
+	}
+	return v3v, c.notes, nil
+}
+
+// V3ToV4 converts CVSS v3 vector into APPROXIMATE CVSS v4 vector. The mapping is best-effort and lossy,
+// the notes returned describe where the information was lost:
+//
+//	attack requirements are none (AT:N), v3 folds them into AC:H
+//	UI:R becomes UI:P, v3 doesn't tell passive from active interaction
+//	impacts are the ones of the vulnerable system, S:C copies them to the subsequent systems
+//	E:F becomes E:A, v4 exploit maturity tells whether the vulnerability is attacked
+//	RL and RC are dropped, v4 has no remediation level nor report confidence
+//	modified scope (MS) is dropped, other modified metrics are mapped as the base ones
+func V3ToV4(v3v v3.Vector) (v4.Vector, []string, error) {
+	if err := v3v.Validate(); err != nil {
+		return v4.Vector{}, nil, err
+	}
+	v4v := v4.NewVector()
+	c := &converter{from: v3v, to: v4v}
+	for _, metric := range []string{"AV", "AC", "PR"} {
+		c.mapValues(metric, metric, nil)
+		c.mapValues("M"+metric, "M"+metric, nil)
+	}
+	c.set("AT", "N")
+	if c.get("AC") == "H" {
+		c.note("AC:H mapped to AC:H/AT:N, attack requirements aren't defined in v3")
+	}
+	c.mapValues("UI", "UI", v3ToV4UI)
+	c.mapValues("MUI", "MUI", v3ToV4UI)
+	if c.get("UI") == "R" {
+		c.note("UI:R mapped to UI:P, v3 doesn't tell passive from active user interaction")
+	}
+	changed := c.get("S") == "C"
+	for _, metric := range []string{"C", "I", "A"} {
+		c.mapValues(metric, "V"+metric, nil)
+		c.mapValues("M"+metric, "MV"+metric, nil)
+		if changed {
+			c.mapValues(metric, "S"+metric, nil)
+		} else {
+			c.set("S"+metric, "N")
+		}
+	}
+	if changed {
+		c.note("S:C mapped to SC/SI/SA of the impacts, v3 doesn't assess subsequent systems separately")
+	}
+	c.mapValues("E", "E", v3ToV4Exploit)
+	if c.get("E") == "F" {
+		c.note("E:F mapped to E:A, v4 exploit maturity tells whether the vulnerability is attacked")
+	}
+	for _, metric := range []string{"CR", "IR", "AR"} {
+		c.mapValues(metric, metric, nil)
+	}
+	c.dropped("RL and RC dropped, remediation level and report confidence aren't defined in v4", "RL", "RC")
+	c.dropped("MS dropped, v4 assesses modified subsequent systems separately", "MS")
+	if c.err != nil {
+		return v4.Vector{}, nil, c.err
+	}
+	return v4v, c.notes, nil
+}
+
+// V4ToV3 converts CVSS v4 vector into APPROXIMATE CVSS v3.1 vector. The mapping is best-effort and lossy,
+// the notes returned describe where the information was lost:
+//
+//	AT:P raises attack complexity to AC:H, v3 has no attack requirements
+//	UI:P and UI:A become UI:R
+//	impacts on subsequent systems change the scope (S:C) and raise the impacts to the highest of both systems
+//	E:A becomes E:H
+//	MAT and modified subsequent system impacts (MSC, MSI, MSA) are dropped, the other modified metrics are mapped
+//	supplemental metrics are dropped, v3 has none
+func V4ToV3(v4v v4.Vector) (v3.Vector, []string, error) {
+	if err := v4v.Validate(); err != nil {
+		return v3.Vector{}, nil, err
+	}
+	v3v := v3.NewVector()
+	if err := v3v.SetVersion("3.1"); err != nil {
+		return v3.Vector{}, nil, err
+	}
+	c := &converter{from: v4v, to: v3v}
+	for _, metric := range []string{"AV", "PR"} {
+		c.mapValues(metric, metric, nil)
+		c.mapValues("M"+metric, "M"+metric, nil)
+	}
+	c.mapValues("MAC", "MAC", nil)
+	if c.get("AT") == "P" {
+		c.set("AC", "H")
+		c.note("AT:P folded into AC:H, attack requirements aren't defined in v3")
+	} else {
+		c.mapValues("AC", "AC", nil)
+	}
+	c.mapValues("UI", "UI", v4ToV3UI)
+	c.mapValues("MUI", "MUI", v4ToV3UI)
+	if ui := c.get("UI"); ui != "N" {
+		c.note("UI:%s mapped to UI:R, v3 doesn't tell passive from active user interaction", ui)
+	}
+	changed := false
+	for _, metric := range []string{"C", "I", "A"} {
+		if c.get("S"+metric) != "N" {
+			changed = true
+		}
+	}
+	if changed {
+		c.set("S", "C")
+		for _, metric := range []string{"C", "I", "A"} {
+			c.set(metric, maxImpact(c.get("V"+metric), c.get("S"+metric)))
+		}
+		c.note("SC/SI/SA folded into S:C and the impacts, v3 doesn't assess subsequent systems separately")
+	} else {
+		c.set("S", "U")
+		for _, metric := range []string{"C", "I", "A"} {
+			c.mapValues("V"+metric, metric, nil)
+		}
+	}
+	for _, metric := range []string{"C", "I", "A"} {
+		c.mapValues("MV"+metric, "M"+metric, nil)
+	}
+	c.mapValues("E", "E", v4ToV3Exploit)
+	for _, metric := range []string{"CR", "IR", "AR"} {
+		c.mapValues(metric, metric, nil)
+	}
+	c.dropped("MAT and MSC/MSI/MSA dropped, modified attack requirements and subsequent systems aren't defined in v3",
+		"MAT", "MSC", "MSI", "MSA")
+	c.dropped("supplemental metrics dropped, v3 has none", "S", "AU", "R", "V", "RE", "U")
+	if c.err != nil {
+		return v3.Vector{}, nil, c.err
+	}
+	return v3v, c.notes, nil
+}
+
+// maxImpact returns the higher of impacts H, L and N
+func maxImpact(a, b string) string {
+	rank := func(v string) int { return strings.Index("NLH", v) }
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"testing"
+)
+
+func TestConvertVector(t *testing.T) {
+	cases := []struct {
+		from, version, to string
+		score             float64
+		lossless          bool
+	}{
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", "3.1", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, false},
+		{"AV:N/AC:M/Au:S/C:P/I:N/A:N/E:POC/RL:OF/RC:C/CDP:L/CR:H", "3.0",
+			"CVSS:3.0/AV:N/AC:L/PR:L/UI:R/S:U/C:L/I:N/A:N/E:P/RL:O/RC:C/CR:H", 0, false},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "3.1", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "4.0",
+			"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 9.3, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:C/C:L/I:L/A:N/E:F/RL:O", "4.0",
+			"CVSS:4.0/AV:N/AC:H/AT:N/PR:N/UI:P/VC:L/VI:L/VA:N/SC:L/SI:L/SA:N/E:A", 0, false},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "3.1",
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:4.0/AV:L/AC:L/AT:P/PR:L/UI:A/VC:L/VI:N/VA:N/SC:H/SI:N/SA:N/E:A/AU:Y", "3.1",
+			"CVSS:3.1/AV:L/AC:H/PR:L/UI:R/S:C/C:H/I:N/A:N/E:H", 0, false},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "2.0", "AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0, false},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", "4.0", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 9.3, false},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", "2", "AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0, true},
+	}
+	for _, c := range cases {
+		v, notes, err := ConvertVector(c.from, c.version)
+		if err != nil {
+			t.Errorf("%s to %s: %v", c.from, c.version, err)
+			continue
+		}
+		if s := canonical(v); s != c.to {
+			t.Errorf("%s to %s: expected %s, got %s", c.from, c.version, c.to, s)
+		}
+		if c.score != 0 && v.Score() != c.score {
+			t.Errorf("%s to %s: expected score %.1f, got %.1f", c.from, c.version, c.score, v.Score())
+		}
+		if lossless := len(notes) == 0; lossless != c.lossless {
+			t.Errorf("%s to %s: expected lossless %t, got notes %q", c.from, c.version, c.lossless, notes)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	if _, _, err := ConvertVector("AV:N/AC:L/Au:N/C:C/I:C/A:C", "5.0"); err == nil {
+		t.Error("expected unsupported version to fail")
+	}
+	if _, _, err := ConvertVector("CVSS:3.1/AV:N/AC:L", "2.0"); err == nil {
+		t.Error("expected incomplete vector to fail")
+	}
+}
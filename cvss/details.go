@@ -0,0 +1,88 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+	"github.com/facebookincubator/nvdtools/cvss/v4"
+)
+
+// ScoreDetails is the breakdown of the score of a vector, suitable for JSON encoding;
+// the scores which don't apply to the vector are nil and left out of JSON
+type ScoreDetails struct {
+	Version                string   `json:"version"`
+	Vector                 string   `json:"vector"`
+	BaseScore              float64  `json:"baseScore"`
+	ImpactSubscore         *float64 `json:"impactSubscore,omitempty"`         // CVSS v2 and v3 only
+	ExploitabilitySubscore *float64 `json:"exploitabilitySubscore,omitempty"` // CVSS v2 and v3 only
+	TemporalScore          *float64 `json:"temporalScore,omitempty"`          // threat score of CVSS v4
+	EnvironmentalScore     *float64 `json:"environmentalScore,omitempty"`
+	Score                  float64  `json:"score"`    // the score of the whole vector
+	Severity               string   `json:"severity"` // severity of Score in upper case, as in NVD feeds, e.g. HIGH
+}
+
+// Details returns the score breakdown of the vector: temporal and environmental scores are only set if the vector
+// has metrics of the respective groups (see Completeness); subscores are rounded to one decimal, as published by NVD.
+// v must be a valid vector of one of the supported CVSS versions, see NewVector
+func Details(v Vector) (*ScoreDetails, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	d := &ScoreDetails{Vector: v.String(), Score: v.Score()}
+	var temporal, environmental func() float64
+	severity := SeverityFromScore(d.Score)
+	switch v := v.(type) {
+	case v2.Vector:
+		d.Version = "2.0"
+		d.BaseScore = v.BaseScore()
+		d.ImpactSubscore = scorePtr(v.ImpactSubscore())
+		d.ExploitabilitySubscore = scorePtr(v.ExploitabilitySubscore())
+		temporal, environmental = v.TemporalScore, v.EnvironmentalScore
+		severity = SeverityFromV2Score(d.Score)
+	case v3.Vector:
+		d.Version = v.Version()
+		d.BaseScore = v.BaseScore()
+		d.ImpactSubscore = scorePtr(v.ImpactSubscore())
+		d.ExploitabilitySubscore = scorePtr(v.ExploitabilitySubscore())
+		temporal, environmental = v.TemporalScore, v.EnvironmentalScore
+	case v4.Vector:
+		d.Version = "4.0"
+		d.BaseScore = v.BaseScore()
+		temporal, environmental = v.ThreatScore, v.EnvironmentalScore
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+	groups, err := Completeness(v)
+	if err != nil {
+		return nil, err
+	}
+	if groups.Has(common.TemporalGroup) {
+		d.TemporalScore = scorePtr(temporal())
+	}
+	if groups.Has(common.EnvironmentalGroup) {
+		d.EnvironmentalScore = scorePtr(environmental())
+	}
+	d.Severity = strings.ToUpper(severity.String())
+	return d, nil
+}
+
+func scorePtr(score float64) *float64 {
+	return &score
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDetails(t *testing.T) {
+	cases := []struct {
+		version, vector string
+		expected        string
+	}{
+		{
+			"3.1", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			`{"version":"3.1","vector":"CVSS:3.1/A:H/AC:L/AV:N/C:H/I:H/PR:N/S:U/UI:N","baseScore":9.8,` +
+				`"impactSubscore":5.9,"exploitabilitySubscore":3.9,"score":9.8,"severity":"CRITICAL"}`,
+		},
+		{
+			"2", "AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C",
+			`{"version":"2.0","vector":"A:P/AC:L/AV:N/Au:N/C:P/E:F/I:P/RC:C/RL:OF","baseScore":7.5,` +
+				`"impactSubscore":6.4,"exploitabilitySubscore":10,"temporalScore":6.2,"score":6.2,"severity":"MEDIUM"}`,
+		},
+		{
+			"4", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/CR:X",
+			`{"version":"4.0","vector":"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/CR:X","baseScore":9.3,` +
+				`"score":9.3,"severity":"CRITICAL"}`,
+		},
+	}
+	for _, c := range cases {
+		v, err := NewVector(c.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = v.Parse(c.vector); err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		d, err := Details(v)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		got, err := json.Marshal(d)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if string(got) != c.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", c.vector, c.expected, got)
+		}
+	}
+
+	if _, err := Details(NewVectorV3()); err == nil {
+		t.Error("incomplete vector is expected to be an error")
+	}
+}
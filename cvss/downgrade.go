@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/cvss/v2"
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+)
+
+// v3ToV2 maps values of CVSS v3 metrics to the closest values of CVSS v2 metrics with the same name
+var v3ToV2 = map[string]map[string]string{
+	"AV": {"N": "N", "A": "A", "L": "L", "P": "L"},
+	"AC": {"L": "L", "H": "H"},
+	"C":  {"H": "C", "L": "P", "N": "N"},
+	"I":  {"H": "C", "L": "P", "N": "N"},
+	"A":  {"H": "C", "L": "P", "N": "N"},
+	"E":  {"U": "U", "P": "POC", "F": "F", "H": "H"},
+	"RL": {"O": "OF", "T": "TF", "W": "W", "U": "U"},
+	"RC": {"U": "UC", "R": "UR", "C": "C"},
+}
+
+// DowngradeV3 converts CVSS v3 vector into APPROXIMATE CVSS v2 vector for the systems which don't support v3.
+// The mapping is best-effort and lossy, the notes returned describe where the information was lost:
+//
+//	AV:P becomes AV:L, v2 has no physical access vector
+//	PR is mapped onto Au (N -> N, L -> S, H -> M) since v2 has no privileges required metric
+//	UI:R raises access complexity one level (L -> M), v2 has no user interaction metric
+//	impacts H and L become C(omplete) and P(artial)
+//	S is dropped, v2 has no scope
+//	environmental metrics are dropped
+//
+// The scores of the original and downgraded vectors are not expected to be equal.
+// CVSS v3.1 vectors use the same metrics, so they can be downgraded once parsed.
+func DowngradeV3(v3v v3.Vector) (v2.Vector, []string, error) {
+	if err := v3v.Validate(); err != nil {
+		return v2.Vector{}, nil, err
+	}
+	v2v := v2.NewVector()
+	var notes []string
+	set := func(metric, value string) error {
+		if err := v2v.Set(metric, value); err != nil {
+			return fmt.Errorf("downgrade: %v", err)
+		}
+		return nil
+	}
+	get := func(metric string) string {
+		value, _ := v3v.Get(metric)
+		return value
+	}
+
+	for _, metric := range []string{"AV", "AC", "C", "I", "A", "E", "RL", "RC"} {
+		value := get(metric)
+		if value == "" {
+			continue // temporal metrics are optional
+		}
+		v2value, ok := v3ToV2[metric][value]
+		if !ok {
+			return v2.Vector{}, nil, fmt.Errorf("downgrade: no mapping for metric %q value %q", metric, value)
+		}
+		if err := set(metric, v2value); err != nil {
+			return v2.Vector{}, nil, err
+		}
+	}
+	if get("AV") == "P" {
+		notes = append(notes, "AV:P mapped to AV:L, physical access vector isn't defined in v2")
+	}
+	if get("UI") == "R" {
+		ac := map[string]string{"L": "M", "H": "H"}[get("AC")]
+		if err := set("AC", ac); err != nil {
+			return v2.Vector{}, nil, err
+		}
+		notes = append(notes, fmt.Sprintf("UI:R folded into AC:%s, user interaction isn't defined in v2", ac))
+	}
+	au := map[string]string{"N": "N", "L": "S", "H": "M"}[get("PR")]
+	if err := set("Au", au); err != nil {
+		return v2.Vector{}, nil, err
+	}
+	notes = append(notes, fmt.Sprintf("PR:%s mapped to Au:%s, privileges required isn't defined in v2", get("PR"), au))
+	for _, metric := range []string{"C", "I", "A"} {
+		if value := get(metric); value != "N" {
+			notes = append(notes, fmt.Sprintf("%s:%s mapped to %s:%s", metric, value, metric, v3ToV2[metric][value]))
+		}
+	}
+	if get("S") == "C" {
+		notes = append(notes, "S:C dropped, scope isn't defined in v2")
+	}
+	for _, metric := range []string{"CR", "IR", "AR", "MAV", "MAC", "MPR", "MUI", "MS", "MC", "MI", "MA"} {
+		if v3v.Has(metric) {
+			notes = append(notes, "environmental metrics dropped")
+			break
+		}
+	}
+	return v2v, notes, nil
+}
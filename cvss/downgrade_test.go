@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/v3"
+)
+
+func TestDowngradeV3(t *testing.T) {
+	cases := []struct {
+		v3    string
+		v2    string
+		notes []string
+	}{
+		{
+			v3: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			v2: "AV:N/AC:L/Au:N/C:C/I:C/A:C",
+			notes: []string{
+				"PR:N mapped to Au:N, privileges required isn't defined in v2",
+				"C:H mapped to C:C", "I:H mapped to I:C", "A:H mapped to A:C",
+			},
+		},
+		{
+			v3: "CVSS:3.0/AV:P/AC:L/PR:L/UI:R/S:C/C:L/I:N/A:N/E:P/RL:O/RC:C/MAV:N",
+			v2: "AV:L/AC:M/Au:S/C:P/I:N/A:N/E:POC/RL:OF/RC:C",
+			notes: []string{
+				"AV:P mapped to AV:L, physical access vector isn't defined in v2",
+				"UI:R folded into AC:M, user interaction isn't defined in v2",
+				"PR:L mapped to Au:S, privileges required isn't defined in v2",
+				"C:L mapped to C:P",
+				"S:C dropped, scope isn't defined in v2",
+				"environmental metrics dropped",
+			},
+		},
+	}
+	for _, c := range cases {
+		v := v3.NewVector()
+		if err := v.Parse(c.v3); err != nil {
+			t.Fatal(err)
+		}
+		v2v, notes, err := DowngradeV3(v)
+		if err != nil {
+			t.Fatalf("%s: %v", c.v3, err)
+		}
+		if s := v2v.CanonicalString(); s != c.v2 {
+			t.Errorf("%s: expected %q, got %q", c.v3, c.v2, s)
+		}
+		if !reflect.DeepEqual(notes, c.notes) {
+			t.Errorf("%s: expected notes %q, got %q", c.v3, c.notes, notes)
+		}
+		if err := v2v.Validate(); err != nil {
+			t.Errorf("%s: downgraded vector is invalid: %v", c.v3, err)
+		}
+	}
+	if _, _, err := DowngradeV3(v3.NewVector()); err == nil {
+		t.Error("expected an error for incomplete vector")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+// Explanation tells why a vector scores as it does: the score breakdown (see Details) along with the metrics
+// of the vector, their names and weights, suitable for JSON encoding, e.g. to render by tools
+type Explanation struct {
+	ScoreDetails
+	Metrics []common.MetricExplanation `json:"metrics"`
+}
+
+// Explain returns the explanation of the score of the vector (see e.g. v3.Vector.Explain); v must be a valid vector
+// of CVSS v2 or v3, CVSS v4 scores don't come from weights of metrics, see package v4
+func Explain(v Vector) (*Explanation, error) {
+	e, ok := v.(interface {
+		Explain() ([]common.MetricExplanation, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+	metrics, err := e.Explain()
+	if err != nil {
+		return nil, err
+	}
+	details, err := Details(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{ScoreDetails: *details, Metrics: metrics}, nil
+}
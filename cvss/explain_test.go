@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/v4"
+)
+
+func TestExplain(t *testing.T) {
+	v, err := Parse("CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H/E:F", Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Explain(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "CRITICAL" || e.BaseScore != 9.9 {
+		t.Errorf("unexpected score details %+v", e.ScoreDetails)
+	}
+	var metrics []string
+	for _, m := range e.Metrics {
+		metrics = append(metrics, m.Metric)
+	}
+	if got := strings.Join(metrics, "/"); got != "AV/AC/PR/UI/S/C/I/A/E" {
+		t.Errorf("metrics aren't in the order of the specification: %s", got)
+	}
+	pr := e.Metrics[2]
+	if pr.Name != "Privileges Required" || pr.ValueName != "Low" || pr.Group != "Base" || pr.Weight != 0.68 {
+		t.Errorf("unexpected explanation of PR with changed scope %+v", pr)
+	}
+	if ecm := e.Metrics[8]; ecm.Name != "Exploit Code Maturity" || ecm.ValueName != "Functional" || ecm.Group != "Temporal" || ecm.Weight != 0.97 {
+		t.Errorf("unexpected explanation of E %+v", ecm)
+	}
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"severity":"CRITICAL"`) || !strings.Contains(string(out), `"valueName":"Network"`) {
+		t.Errorf("unexpected JSON %s", out)
+	}
+}
+
+func TestExplainV2(t *testing.T) {
+	v, err := Parse("AV:N/AC:L/Au:N/C:P/I:P/A:P/RL:OF", Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Explain(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "MEDIUM" || e.Score != 6.5 || len(e.Metrics) != 7 {
+		t.Errorf("unexpected explanation %+v", e)
+	}
+	if au := e.Metrics[2]; au.Name != "Authentication" || au.ValueName != "None" || au.Weight != 0.704 {
+		t.Errorf("unexpected explanation of Au %+v", au)
+	}
+	if rl := e.Metrics[6]; rl.ValueName != "Official Fix" || rl.Group != "Temporal" {
+		t.Errorf("unexpected explanation of RL %+v", rl)
+	}
+
+	if _, err := Explain(v4.NewVector()); err == nil {
+		t.Error("expected error explaining CVSS v4 vector")
+	}
+	if _, err := Explain(NewVectorV3()); err == nil {
+		t.Error("expected error explaining incomplete vector")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// ParseQuery builds a vector of the given version out of URL query parameters, e.g. ?AV=N&AC=L&...
+// Parameters which aren't metrics of the version (e.g. lang or Page, also metrics of other versions) are ignored,
+// invalid or conflicting values are reported as errors.
+// The vector is validated before it's returned.
+func ParseQuery(values url.Values, version string) (Vector, error) {
+	v, err := NewVector(version)
+	if err != nil {
+		return nil, err
+	}
+	wt, ok := v.(interface {
+		WeightTable() map[string]map[string]float64
+	})
+	if !ok {
+		return nil, fmt.Errorf("query: unsupported vector type %T", v)
+	}
+	weights := wt.WeightTable()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if _, ok := weights[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vals := values[name]
+		if len(vals) == 0 {
+			continue
+		}
+		for _, val := range vals[1:] {
+			if val != vals[0] {
+				return nil, fmt.Errorf("query: conflicting values %q and %q of metric %q", vals[0], val, name)
+			}
+		}
+		if err := v.Set(name, vals[0]); err != nil {
+			return nil, fmt.Errorf("query: %v", err)
+		}
+	}
+	if err := v.Validate(); err != nil {
+		return nil, fmt.Errorf("query: %v", err)
+	}
+	return v, nil
+}
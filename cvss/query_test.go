@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		query   string
+		version string
+		vector  string
+	}{
+		{"AV=N&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H&lang=en&page=2", "3.0", "CVSS:3.0/A:H/AC:L/AV:N/C:H/I:H/PR:N/S:U/UI:N"},
+		{"AV=N&AC=L&Au=N&C=P&I=P&A=P&E=F&version=2", "2", "A:P/AC:L/AV:N/Au:N/C:P/E:F/I:P"},
+		{"AV=N&AV=N&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H", "3", "CVSS:3.0/A:H/AC:L/AV:N/C:H/I:H/PR:N/S:U/UI:N"},
+		// capitalized parameters other than metrics of the version
+		{"AV=N&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H&Q=1&Lang=en&Foo=x&XX=1", "3.1", "CVSS:3.1/A:H/AC:L/AV:N/C:H/I:H/PR:N/S:U/UI:N"},
+		{"AV=N&AC=L&Au=N&C=P&I=P&A=P&PR=N&S=U", "2", "A:P/AC:L/AV:N/Au:N/C:P/I:P"},
+	}
+	for _, c := range cases {
+		q, err := url.ParseQuery(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := ParseQuery(q, c.version)
+		if err != nil {
+			t.Errorf("%s: %v", c.query, err)
+			continue
+		}
+		if v.String() != c.vector {
+			t.Errorf("%s: expected %q, got %q", c.query, c.vector, v.String())
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := map[string]string{
+		"AV=Q&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H":      "3", // unknown value
+		"AV=N&AV=L&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H": "3", // conflicting values
+		"AV=N&AC=L":                           "3",   // incomplete
+		"AV=N&AC=L&PR=N&UI=N&S=U&C=H&I=H&A=H": "5.0", // unknown version
+		"AV=N&AC=L&Lang=en&PR=N":              "2",   // incomplete, Lang and PR aren't metrics of v2
+	}
+	for query, version := range cases {
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseQuery(q, version); err == nil {
+			t.Errorf("%s: expected an error", query)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+var (
+	impactValues      = map[string]string{"N": "None", "P": "Partial", "C": "Complete"}
+	requirementValues = map[string]string{"L": "Low", "M": "Medium", "H": "High", "ND": "Not Defined"}
+
+	// definitions are the names of metrics and values of CVSS v2 specification
+	definitions = common.MetricDefinitions{
+		"AV":  {Name: "Access Vector", Values: map[string]string{"L": "Local", "A": "Adjacent Network", "N": "Network"}},
+		"AC":  {Name: "Access Complexity", Values: map[string]string{"H": "High", "M": "Medium", "L": "Low"}},
+		"Au":  {Name: "Authentication", Values: map[string]string{"M": "Multiple", "S": "Single", "N": "None"}},
+		"C":   {Name: "Confidentiality Impact", Values: impactValues},
+		"I":   {Name: "Integrity Impact", Values: impactValues},
+		"A":   {Name: "Availability Impact", Values: impactValues},
+		"E":   {Name: "Exploitability", Values: map[string]string{"U": "Unproven", "POC": "Proof-of-Concept", "F": "Functional", "H": "High", "ND": "Not Defined"}},
+		"RL":  {Name: "Remediation Level", Values: map[string]string{"OF": "Official Fix", "TF": "Temporary Fix", "W": "Workaround", "U": "Unavailable", "ND": "Not Defined"}},
+		"RC":  {Name: "Report Confidence", Values: map[string]string{"UC": "Unconfirmed", "UR": "Uncorroborated", "C": "Confirmed", "ND": "Not Defined"}},
+		"CDP": {Name: "Collateral Damage Potential", Values: map[string]string{"N": "None", "L": "Low", "LM": "Low-Medium", "MH": "Medium-High", "H": "High", "ND": "Not Defined"}},
+		"TD":  {Name: "Target Distribution", Values: map[string]string{"N": "None", "L": "Low", "M": "Medium", "H": "High", "ND": "Not Defined"}},
+		"CR":  {Name: "Confidentiality Requirement", Values: requirementValues},
+		"IR":  {Name: "Integrity Requirement", Values: requirementValues},
+		"AR":  {Name: "Availability Requirement", Values: requirementValues},
+	}
+)
+
+// Definitions returns the names of metrics and values of the specification, by metric;
+// the table is built on every call, so it can be modified freely
+func Definitions() common.MetricDefinitions {
+	return definitions.Copy()
+}
+
+// Explain returns the metrics of the vector in the order of the specification with the names of the metrics and values
+// and the weights they contribute to the scores, to tell why the vector scores as it does; see also BaseWeights.
+// Vectors lacking base metrics are an error.
+func (v Vector) Explain() ([]common.MetricExplanation, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return v.WeightsMetrics.Explain(canonicalOrder, definitions, metricGroups), nil
+}
@@ -16,32 +16,97 @@ package v2
 
 import (
 	"math"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
 func roundTo1Decimal(x float64) float64 {
 	// round up to one decimal
-	return math.Round(x*10) / 10
+	return common.RoundHalfUp.Round(x)
 }
 
 // Score = combined score for the whole Vector
 func (v Vector) Score() float64 {
+	return v.ScoreWith(common.RoundHalfUp)
+}
+
+// ScoreWith calculates combined score for the whole Vector using a custom rounding strategy.
+// Only use it to reproduce scores of legacy systems, specification requires rounding to the nearest decimal.
+func (v Vector) ScoreWith(r common.Rounding) float64 {
 	// combines all of them
-	return v.environmentalScore()
+	return common.ClampScore(v.environmentalScoreWith(r))
+}
+
+// ScoreStrict is like Score, but the score out of the CVSS range, which only broken custom weight tables yield,
+// is an error rather than clamped, see common.CheckScore
+func (v Vector) ScoreStrict() (float64, error) {
+	score := v.environmentalScoreWith(common.RoundHalfUp)
+	if err := common.CheckScore(score); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// BaseScore returns the score of base metrics only, temporal and environmental metrics are ignored
+func (v Vector) BaseScore() float64 {
+	return common.ClampScore(v.baseScore())
+}
+
+// BaseScoreRaw returns the base score at full precision, before the final rounding, e.g. for distributions of scores
+// the one decimal steps would distort. It isn't the score of the specification, display BaseScore instead.
+func (v Vector) BaseScoreRaw() float64 {
+	return v.baseScoreWith(v.impactScore(), common.NoRounding)
+}
+
+// TemporalScore returns the score of base and temporal metrics, environmental metrics are ignored
+func (v Vector) TemporalScore() float64 {
+	return common.ClampScore(v.temporalScore())
+}
+
+// TemporalScoreWithDefaults is like TemporalScore, but temporal metrics the vector omits, i.e. lacks or has set
+// to ND (not defined), are taken as set to their value in defaults, e.g. {"RC": "UR"} to penalize unknown report
+// confidence; the specification takes them as neutral, as do omitted metrics without a default. Defaults of other
+// than temporal metrics or of invalid values are an error.
+func (v Vector) TemporalScoreWithDefaults(defaults common.Defaults) (float64, error) {
+	if err := defaults.Validate(v.Weights, temporalMetrics...); err != nil {
+		return 0, err
+	}
+	base := v.baseScoreWith(v.impactScore(), common.RoundHalfUp)
+	return common.ClampScore(common.RoundHalfUp.Round(base * v.temporalWeight(defaults))), nil
+}
+
+// EnvironmentalScore returns the score adjusted by environmental metrics, i.e. the score of the whole vector
+func (v Vector) EnvironmentalScore() float64 {
+	return common.ClampScore(v.environmentalScore())
+}
+
+// ImpactSubscore returns the impact subscore of base metrics rounded to one decimal, as published by NVD
+func (v Vector) ImpactSubscore() float64 {
+	return roundTo1Decimal(v.impactScore())
+}
+
+// ExploitabilitySubscore returns the exploitability subscore of base metrics rounded to one decimal, as published by NVD
+func (v Vector) ExploitabilitySubscore() float64 {
+	return roundTo1Decimal(v.exploitabilityScore())
 }
 
 func (v Vector) baseScore() float64 {
-	return v.baseScoreWith(v.impactScore())
+	return v.baseScoreWith(v.impactScore(), common.RoundHalfUp)
 }
 
 func (v Vector) temporalScore() float64 {
-	return v.temporalScoreWith(v.impactScore())
+	return v.temporalScoreWith(v.impactScore(), common.RoundHalfUp)
 }
 
 func (v Vector) environmentalScore() float64 {
+	return v.environmentalScoreWith(common.RoundHalfUp)
+}
+
+func (v Vector) environmentalScoreWith(r common.Rounding) float64 {
 	ai := v.adjustedImpactScore()
-	at := v.temporalScoreWith(ai)
+	at := v.temporalScoreWith(ai, r)
 
-	return roundTo1Decimal((at + (10-at)*v.WeightDefault("CDP", 0.0)) * v.WeightDefault("TD", 1.0))
+	return r.Round((at + (10-at)*v.WeightDefault("CDP", 0.0)) * v.WeightDefault("TD", 1.0))
 }
 
 // helpers
@@ -64,16 +129,28 @@ func (v Vector) adjustedImpactScore() float64 {
 	)
 }
 
-func (v Vector) temporalScoreWith(impact float64) float64 {
-	base := v.baseScoreWith(impact)
-	return roundTo1Decimal(base * v.WeightDefault("E", 1.0) * v.WeightDefault("RL", 1.0) * v.WeightDefault("RC", 1.0))
+func (v Vector) temporalScoreWith(impact float64, r common.Rounding) float64 {
+	base := v.baseScoreWith(impact, r)
+	return r.Round(base * v.temporalWeight(nil))
+}
+
+// temporalMetrics are the metrics of the temporal group
+var temporalMetrics = []string{"E", "RL", "RC"}
+
+// temporalWeight returns the product of temporal metrics weights, omitted metrics weigh as set in defaults or 1
+func (v Vector) temporalWeight(defaults common.Defaults) float64 {
+	w := 1.0
+	for _, metric := range temporalMetrics {
+		w *= v.WeightOmitted(metric, "ND", defaults, 1.0)
+	}
+	return w
 }
 
-func (v Vector) baseScoreWith(impact float64) float64 {
+func (v Vector) baseScoreWith(impact float64, r common.Rounding) float64 {
 	i, e := impact, v.exploitabilityScore()
 	fi := 1.176
 	if i == 0.0 {
 		fi = 0.0
 	}
-	return roundTo1Decimal((0.6*i + 0.4*e - 1.5) * fi)
+	return r.Round((0.6*i + 0.4*e - 1.5) * fi)
 }
@@ -17,6 +17,8 @@ package v2
 import (
 	"fmt"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
 func TestRoundTo1Decimal(t *testing.T) {
@@ -63,6 +65,21 @@ func TestScores(t *testing.T) {
 	}
 }
 
+func TestScoreWith(t *testing.T) {
+	v := NewVector()
+	v.Parse("(AV:A/AC:L/Au:S/C:C/I:P/A:C/E:F/RL:W/RC:UR/CDP:MH/TD:M/CR:M/IR:L/AR:H)")
+
+	if s, def := v.ScoreWith(common.RoundHalfUp), v.Score(); s != def {
+		t.Errorf("score with default rounding expected to be %.1f, got %.1f", def, s)
+	}
+	if s := v.ScoreWith(common.Truncate); s > v.Score() {
+		t.Errorf("truncated score %.1f expected to be less or equal to the default one %.1f", s, v.Score())
+	}
+	if s := v.ScoreWith(common.RoundingFunc(func(float64) float64 { return 0 })); s != 0 {
+		t.Errorf("custom rounding wasn't applied, got %.1f", s)
+	}
+}
+
 func BenchmarkScore(b *testing.B) {
 	v := NewVector()
 	v.Parse("(AV:A/AC:L/Au:S/C:C/I:P/A:C/E:F/RL:W/RC:UR/CDP:MH/TD:M/CR:M/IR:L/AR:H)")
@@ -71,3 +88,72 @@ func BenchmarkScore(b *testing.B) {
 		v.Score()
 	}
 }
+
+func TestTemporalScoreWithDefaults(t *testing.T) {
+	uncorroborated := common.Defaults{"RC": "UR"}
+	cases := []struct {
+		vector string
+		score  float64
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.1},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P/RC:ND", 7.1},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P/RC:C", 7.5},
+	}
+	for _, c := range cases {
+		v := NewVector()
+		if err := v.Parse(c.vector); err != nil {
+			t.Fatal(err)
+		}
+		score, err := v.TemporalScoreWithDefaults(uncorroborated)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if score != c.score {
+			t.Errorf("%s: expected %.1f, got %.1f", c.vector, c.score, score)
+		}
+		if score, err = v.TemporalScoreWithDefaults(nil); err != nil || score != v.TemporalScore() {
+			t.Errorf("%s: without defaults expected %.1f, got %.1f (%v)", c.vector, v.TemporalScore(), score, err)
+		}
+	}
+	if _, err := NewVector().TemporalScoreWithDefaults(common.Defaults{"CDP": "H"}); err == nil {
+		t.Error("defaults of environmental metrics are expected to be an error")
+	}
+}
+
+func TestBaseScoreRaw(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("AV:N/AC:M/Au:S/C:P/I:N/A:N"); err != nil {
+		t.Fatal(err)
+	}
+	raw, base := v.BaseScoreRaw(), v.BaseScore()
+	if base != 3.5 || raw == base || common.RoundHalfUp.Round(raw) != base {
+		t.Errorf("expected raw score rounding to 3.5, got %v (base score %.1f)", raw, base)
+	}
+}
+
+func TestScoreStrict(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("AV:N/AC:L/Au:N/C:C/I:C/A:C"); err != nil {
+		t.Fatal(err)
+	}
+	if score, err := v.ScoreStrict(); err != nil || score != v.Score() {
+		t.Errorf("standard weights: expected %v, got %v, %v", v.Score(), score, err)
+	}
+	// a broken custom weight table, the exploitability subscore is far above 10
+	v.Weights = v.WeightTable()
+	v.Weights["AV"]["N"] = 5.0
+	if score := v.Score(); score != 10 {
+		t.Errorf("expected the score clamped to 10, got %v", score)
+	}
+	if score := v.BaseScore(); score != 10 {
+		t.Errorf("expected the base score clamped to 10, got %v", score)
+	}
+	if _, err := v.ScoreStrict(); err == nil {
+		t.Error("expected an error for the broken weight table")
+	} else if _, ok := err.(common.ErrScoreOutOfRange); !ok {
+		t.Errorf("expected common.ErrScoreOutOfRange, got %T: %v", err, err)
+	}
+	if w := weights["AV"]["N"]; w != 1.0 {
+		t.Errorf("the weight table of the package was modified: %v", w)
+	}
+}
@@ -119,10 +119,88 @@ var (
 
 type Vector struct {
 	common.WeightsMetrics
+	order *[]string // metrics in the order of the parsed input, only recorded by vectors from NewOrderedVector
 }
 
 func NewVector() Vector {
-	return Vector{common.WeightsMetrics{make(common.Metrics), weights}}
+	return Vector{WeightsMetrics: common.WeightsMetrics{Metrics: make(common.Metrics), Weights: weights}}
+}
+
+// NewOrderedVector is like NewVector, but the vector records the order metrics appear in the parsed input, see OriginalString
+func NewOrderedVector() Vector {
+	v := NewVector()
+	v.order = new([]string)
+	return v
+}
+
+// OriginalString returns the vector string with metrics in the order they appeared in the parsed input,
+// metrics set otherwise follow in lexical order. Vectors not created with NewOrderedVector return String.
+func (v Vector) OriginalString() string {
+	if v.order == nil {
+		return v.String()
+	}
+	return v.Metrics.CanonicalString(*v.order)
+}
+
+// recordOrder appends metrics of successfully parsed str to the input order, if it's recorded
+func (v Vector) recordOrder(str string) {
+	if v.order != nil {
+		*v.order = append(*v.order, common.MetricNames(str)...)
+	}
+}
+
+// Freeze returns a read-only copy of the vector, safe for concurrent use: Set and Parse of the copy fail
+// with common.ErrFrozen, see common.WeightsMetrics.Freeze
+func (v Vector) Freeze() Vector {
+	v.WeightsMetrics = v.WeightsMetrics.Freeze()
+	if v.order != nil {
+		order := append([]string(nil), *v.order...)
+		v.order = &order
+	}
+	return v
+}
+
+// BaseOnly returns a copy of the vector limited to the base metrics, without temporal and environmental ones;
+// the order of the parsed input is kept for the base metrics, see OriginalString
+func (v Vector) BaseOnly() Vector {
+	base := v
+	base.Metrics = v.Metrics.Only(baseMetricsWeights...)
+	base.order = v.orderOf(base.Metrics)
+	return base
+}
+
+// Normalize returns a copy of the vector without temporal and environmental metrics set to ND (not defined),
+// which don't affect the scores, so equivalent vectors normalize to the same CanonicalString;
+// the order of the parsed input is kept for the rest, see OriginalString
+func (v Vector) Normalize() Vector {
+	n := v
+	n.Metrics = v.Metrics.Defined(metricGroups, "ND")
+	n.order = v.orderOf(n.Metrics)
+	return n
+}
+
+// orderOf returns a copy of the input order limited to the metrics, nil if the order isn't recorded
+func (v Vector) orderOf(metrics common.Metrics) *[]string {
+	if v.order == nil {
+		return nil
+	}
+	order := make([]string, 0, len(metrics))
+	for _, metric := range *v.order {
+		if metrics.Has(metric) {
+			order = append(order, metric)
+		}
+	}
+	return &order
+}
+
+// ParseLenient is like Parse, but accepts metrics repeated with the same value, e.g. AV:N/AC:L/.../AV:N;
+// metrics repeated with conflicting values are still an error
+func (v Vector) ParseLenient(str string) error {
+	str, err := common.DedupMetrics(strings.Trim(str, "()"))
+	if err != nil {
+		return err
+	}
+	return v.Parse(str)
 }
 
 func (v Vector) Validate() error {
@@ -134,8 +212,116 @@ func (v Vector) Validate() error {
 	return nil
 }
 
+// canonicalOrder lists all metrics in the order of the specification
+var canonicalOrder = []string{
+	"AV", "AC", "Au", "C", "I", "A", // base
+	"E", "RL", "RC", // temporal
+	"CDP", "TD", "CR", "IR", "AR", // environmental
+}
+
+// metricGroups maps metrics to their groups
+var metricGroups = map[string]common.MetricGroups{}
+
+func init() {
+	common.AddGroup(metricGroups, common.BaseGroup, baseMetricsWeights...)
+	common.AddGroup(metricGroups, common.TemporalGroup, temporalMetrics...)
+	common.AddGroup(metricGroups, common.EnvironmentalGroup, "CDP", "TD", "CR", "IR", "AR")
+}
+
+// MetricGroups returns the metrics of every metric group in the order of the specification;
+// the table is built on every call, so it can be modified freely
+func MetricGroups() map[common.MetricGroups][]string {
+	return common.GroupMetrics(metricGroups, canonicalOrder)
+}
+
+// Completeness returns the metric groups the vector has metrics of, metrics set to ND (not defined) don't count
+func (v Vector) Completeness() common.MetricGroups {
+	return v.Metrics.Completeness(metricGroups, "ND")
+}
+
+// CanonicalString returns the vector string with metrics in the order of the specification.
+// Unlike String, the order is frozen and won't change, so it's suitable as a cache key.
+func (v Vector) CanonicalString() string {
+	return v.Metrics.CanonicalString(canonicalOrder)
+}
+
+// BaseWeights returns base metrics in the order of the specification along with their values
+// and the weights that contribute to the base score
+func (v Vector) BaseWeights() ([]common.MetricWeight, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return v.MetricWeights(baseMetricsWeights...)
+}
+
 // Override parse because it can contain parenthesis
 
+// v3Metrics are CVSS v3 metrics absent in v2, they reveal vectors of mismatched version
+var v3Metrics = map[string]bool{
+	"PR": true, "UI": true, "S": true,
+	"MAV": true, "MAC": true, "MPR": true, "MUI": true, "MS": true, "MC": true, "MI": true, "MA": true,
+}
+
 func (v Vector) Parse(str string) error {
-	return v.WeightsMetrics.Parse(strings.Trim(str, "()"))
+	str = strings.Trim(str, "()")
+	if strings.HasPrefix(strings.ToUpper(str), "CVSS:") {
+		if i := strings.Index(str, "/"); i > 0 {
+			return fmt.Errorf("vector declares %s, it can't be parsed as CVSS v2", str[:i])
+		}
+	}
+	for _, metric := range common.MetricNames(str) {
+		if v3Metrics[metric] {
+			return fmt.Errorf("CVSS v2 vector can't have CVSS v3 metric %q", metric)
+		}
+	}
+	if err := v.WeightsMetrics.Parse(str); err != nil {
+		return err
+	}
+	v.recordOrder(str)
+	return nil
+}
+
+// ParseWithPolicy is like Parse, but metrics and values not defined for CVSS v2 are handled as per policy,
+// e.g. to accept vendor extensions; nil policy is as strict as Parse, see common.Policy
+func (v Vector) ParseWithPolicy(str string, policy common.Policy) error {
+	str = strings.Trim(str, "()")
+	known, accepted, err := common.FilterUnknown(str, v.Weights, policy)
+	if err != nil {
+		return err
+	}
+	if err = v.Parse(known); err != nil {
+		return err
+	}
+	return v.SetVerbatim(accepted)
+}
+
+// EnumerateBase calls fn for every valid base vector, in deterministic order (see common.WeightsMetrics.Enumerate).
+// The same vector is reused for every call, so fn must not retain it; enumeration stops if fn returns false.
+func EnumerateBase(fn func(Vector) bool) {
+	v := NewVector()
+	if err := v.Enumerate(baseMetricsWeights, func() bool { return fn(v) }); err != nil {
+		panic(err) // base metrics are always defined
+	}
+}
+
+// BaseScoreRange returns the minimum and maximum base score over all completions of the base metrics the vector lacks,
+// bounding the score of incomplete vectors; temporal and environmental metrics are disregarded. The vector isn't modified.
+func (v Vector) BaseScoreRange() (min, max float64) {
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	min, max, err := base.ScoreRange(baseMetricsWeights, base.BaseScore)
+	if err != nil {
+		panic(err) // base metrics are always defined
+	}
+	return min, max
+}
+
+// BaseSensitivity returns the contribution of each base metric to the base score, in the order of the specification,
+// to explain the score: the base score less the mean base score over the values of the metric, see
+// common.WeightsMetrics.Sensitivity. The vector isn't modified; vectors lacking base metrics are an error.
+func (v Vector) BaseSensitivity() ([]common.MetricDelta, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	return base.Sensitivity(baseMetricsWeights, base.BaseScore)
 }
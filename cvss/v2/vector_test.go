@@ -16,9 +16,29 @@ package v2
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
+func TestParseLenient(t *testing.T) {
+	str := "(AV:N/AC:L/Au:N/C:P/I:P/A:P/AV:N)"
+	if err := NewVector().Parse(str); err == nil {
+		t.Errorf("%q: strict parser expected to reject repeated metric", str)
+	}
+	v := NewVector()
+	if err := v.ParseLenient(str); err != nil {
+		t.Errorf("%q: unexpected error %v", str, err)
+	} else if err := v.Validate(); err != nil {
+		t.Errorf("%q: %v", str, err)
+	}
+	str = "(AV:N/AC:L/Au:N/C:P/I:P/A:P/AV:L)"
+	if err := NewVector().ParseLenient(str); err == nil {
+		t.Errorf("%q: lenient parser expected to reject conflicting values", str)
+	}
+}
+
 func TestParse(t *testing.T) {
 	// all possible metrics are defined in these 3 strings
 	base := "AV:A/AC:L/Au:S/C:C/I:P/A:C"
@@ -74,3 +94,85 @@ func BenchmarkParse(b *testing.B) {
 		v.Parse("(AV:A/AC:L/Au:S/C:C/I:P/A:C/E:F/RL:W/RC:UR/CDP:MH/TD:M/CR:M/IR:L/AR:H)")
 	}
 }
+
+func FuzzParseV2(f *testing.F) {
+	f.Add("(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC/RL:OF/RC:C/CDP:H/TD:H/CR:M/IR:M/AR:H)")
+	f.Add("AV:N/AC:L/Au:N/C:N/I:N/A:C")
+	f.Add("(")
+	f.Add("AV:N/AV:N")
+	f.Fuzz(func(t *testing.T, str string) {
+		v := NewVector()
+		if err := v.Parse(str); err != nil {
+			return
+		}
+		_ = v.String()
+		if err := v.Validate(); err != nil {
+			return
+		}
+		if s := v.Score(); s < 0 || s > 10 {
+			t.Errorf("%q: score %.1f out of range", str, s)
+		}
+	})
+}
+
+func TestBaseWeights(t *testing.T) {
+	v := NewVector()
+	if _, err := v.BaseWeights(); err == nil {
+		t.Error("expected an error for incomplete vector")
+	}
+	if err := v.Parse("AV:A/AC:L/Au:S/C:C/I:P/A:N"); err != nil {
+		t.Fatal(err)
+	}
+	mws, err := v.BaseWeights()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []common.MetricWeight{
+		{Metric: "AV", Value: "A", Weight: 0.646},
+		{Metric: "AC", Value: "L", Weight: 0.71},
+		{Metric: "Au", Value: "S", Weight: 0.56},
+		{Metric: "C", Value: "C", Weight: 0.660},
+		{Metric: "I", Value: "P", Weight: 0.275},
+		{Metric: "A", Value: "N", Weight: 0.0},
+	}
+	if !reflect.DeepEqual(mws, expected) {
+		t.Errorf("expected %v, got %v", expected, mws)
+	}
+}
+
+func TestCanonicalString(t *testing.T) {
+	str := "AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC/RL:OF/RC:C/CDP:H/TD:H/CR:M/IR:M/AR:H"
+	v := NewVector()
+	if err := v.Parse("(AR:H/IR:M/CR:M/TD:H/CDP:H/RC:C/RL:OF/E:POC/A:P/I:P/C:P/Au:N/AC:L/AV:N)"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.CanonicalString(); s != str {
+		t.Errorf("expected %q, got %q", str, s)
+	}
+}
+
+func TestParseVersionMismatch(t *testing.T) {
+	tests := map[string]string{
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": `vector declares CVSS:3.0, it can't be parsed as CVSS v2`,
+		"AV:N/AC:L/PR:N/C:P/I:P/A:P":                   `CVSS v2 vector can't have CVSS v3 metric "PR"`,
+	}
+	for str, expected := range tests {
+		err := NewVector().Parse(str)
+		if err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %v", str, expected, err)
+		}
+	}
+}
+
+func TestOriginalString(t *testing.T) {
+	v := NewOrderedVector()
+	if err := v.Parse("(C:P/AV:N/AC:L/Au:N/I:P/A:P)"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.OriginalString(); s != "C:P/AV:N/AC:L/Au:N/I:P/A:P" {
+		t.Errorf("expected original order, got %q", s)
+	}
+	if s := v.String(); s != "A:P/AC:L/AV:N/Au:N/C:P/I:P" {
+		t.Errorf("String is expected to stay canonical, got %q", s)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+// AttackVector is a value of AV metric
+type AttackVector string
+
+// AttackVector values
+const (
+	AttackVectorNetwork  AttackVector = "N"
+	AttackVectorAdjacent AttackVector = "A"
+	AttackVectorLocal    AttackVector = "L"
+	AttackVectorPhysical AttackVector = "P"
+)
+
+// AttackComplexity is a value of AC metric
+type AttackComplexity string
+
+// AttackComplexity values
+const (
+	AttackComplexityLow  AttackComplexity = "L"
+	AttackComplexityHigh AttackComplexity = "H"
+)
+
+// PrivilegesRequired is a value of PR metric
+type PrivilegesRequired string
+
+// PrivilegesRequired values
+const (
+	PrivilegesRequiredNone PrivilegesRequired = "N"
+	PrivilegesRequiredLow  PrivilegesRequired = "L"
+	PrivilegesRequiredHigh PrivilegesRequired = "H"
+)
+
+// UserInteraction is a value of UI metric
+type UserInteraction string
+
+// UserInteraction values
+const (
+	UserInteractionNone     UserInteraction = "N"
+	UserInteractionRequired UserInteraction = "R"
+)
+
+// Scope is a value of S metric
+type Scope string
+
+// Scope values
+const (
+	ScopeUnchanged Scope = "U"
+	ScopeChanged   Scope = "C"
+)
+
+// Impact is a value of C, I and A metrics
+type Impact string
+
+// Impact values
+const (
+	ImpactHigh Impact = "H"
+	ImpactLow  Impact = "L"
+	ImpactNone Impact = "N"
+)
+
+// V3Base holds CVSS v3 base metrics as typed fields.
+// It allows to compute the base score without building and parsing a vector string.
+type V3Base struct {
+	AttackVector       AttackVector
+	AttackComplexity   AttackComplexity
+	PrivilegesRequired PrivilegesRequired
+	UserInteraction    UserInteraction
+	Scope              Scope
+	Confidentiality    Impact
+	Integrity          Impact
+	Availability       Impact
+}
+
+// metrics returns base metrics in the same order as they go in vector string
+func (b V3Base) metrics() [][2]string {
+	return [][2]string{
+		{"AV", string(b.AttackVector)},
+		{"AC", string(b.AttackComplexity)},
+		{"PR", string(b.PrivilegesRequired)},
+		{"UI", string(b.UserInteraction)},
+		{"S", string(b.Scope)},
+		{"C", string(b.Confidentiality)},
+		{"I", string(b.Integrity)},
+		{"A", string(b.Availability)},
+	}
+}
+
+// Validate checks whether all metrics are set to valid values
+func (b V3Base) Validate() error {
+	for _, m := range b.metrics() {
+		if _, ok := baseMetricsWeights[m[0]][m[1]]; !ok {
+			return fmt.Errorf("base vector: invalid value %q for metric %q", m[1], m[0])
+		}
+	}
+	return nil
+}
+
+// Score calculates the base score; result is undefined unless b passes Validate
+func (b V3Base) Score() float64 {
+	return b.ScoreWith(common.RoundUp)
+}
+
+// ScoreWith calculates the base score using a custom rounding strategy, see Vector.ScoreWith
+func (b V3Base) ScoreWith(r common.Rounding) float64 {
+	scopeChanged := b.Scope == ScopeChanged
+	w := func(metric string, value string) float64 {
+		return baseMetricsWeights[metric][value]
+	}
+	pr := w("PR", string(b.PrivilegesRequired))
+	if scopeChanged {
+		switch b.PrivilegesRequired {
+		case PrivilegesRequiredLow:
+			pr = 0.68
+		case PrivilegesRequiredHigh:
+			pr = 0.50
+		}
+	}
+	isc := 1 - (1-w("C", string(b.Confidentiality)))*(1-w("I", string(b.Integrity)))*(1-w("A", string(b.Availability)))
+	i := impactSubscore(isc, scopeChanged)
+	e := exploitabilitySubscore(w("AV", string(b.AttackVector)), w("AC", string(b.AttackComplexity)), pr, w("UI", string(b.UserInteraction)))
+	return combinedScore(i, e, scopeChanged, r)
+}
+
+// ToVector serializes base metrics into a Vector
+func (b V3Base) ToVector() (Vector, error) {
+	if err := b.Validate(); err != nil {
+		return Vector{}, err
+	}
+	v := NewVector()
+	for _, m := range b.metrics() {
+		if err := v.Set(m[0], m[1]); err != nil {
+			return Vector{}, err
+		}
+	}
+	return v, nil
+}
+
+// Base returns base metrics of the vector as typed struct
+func (v Vector) Base() (V3Base, error) {
+	if err := v.Validate(); err != nil {
+		return V3Base{}, err
+	}
+	get := func(metric string) string {
+		value, _ := v.Get(metric) // defined because of Validate
+		return value
+	}
+	b := V3Base{
+		AttackVector:       AttackVector(get("AV")),
+		AttackComplexity:   AttackComplexity(get("AC")),
+		PrivilegesRequired: PrivilegesRequired(get("PR")),
+		UserInteraction:    UserInteraction(get("UI")),
+		Scope:              Scope(get("S")),
+		Confidentiality:    Impact(get("C")),
+		Integrity:          Impact(get("I")),
+		Availability:       Impact(get("A")),
+	}
+	return b, b.Validate()
+}
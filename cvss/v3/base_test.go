@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+)
+
+func TestV3Base(t *testing.T) {
+	vectors := []string{
+		"CVSS:3.0/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:H/A:H",
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.0/AV:A/AC:L/PR:L/UI:N/S:C/C:L/I:N/A:N",
+		"CVSS:3.0/AV:L/AC:H/PR:L/UI:R/S:U/C:N/I:L/A:H",
+	}
+	for _, str := range vectors {
+		t.Run(str, func(t *testing.T) {
+			v := NewVector()
+			if err := v.Parse(str); err != nil {
+				t.Fatal(err)
+			}
+			b, err := v.Base()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := v.baseScore(), b.Score(); expected != actual {
+				t.Errorf("expected score %.1f, got %.1f", expected, actual)
+			}
+			v2, err := b.ToVector()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v2.String() != v.String() {
+				t.Errorf("expected vector %q, got %q", v.String(), v2.String())
+			}
+		})
+	}
+}
+
+func TestV3BaseValidate(t *testing.T) {
+	b := V3Base{
+		AttackVector:       AttackVectorNetwork,
+		AttackComplexity:   AttackComplexityLow,
+		PrivilegesRequired: PrivilegesRequiredNone,
+		UserInteraction:    UserInteractionNone,
+		Scope:              ScopeUnchanged,
+		Confidentiality:    ImpactHigh,
+		Integrity:          ImpactHigh,
+		Availability:       ImpactHigh,
+	}
+	if err := b.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := b.Score(); s != 9.8 {
+		t.Errorf("expected score 9.8, got %.1f", s)
+	}
+	b.AttackVector = "Q"
+	if err := b.Validate(); err == nil {
+		t.Error("expected an error for invalid attack vector")
+	}
+	if _, err := b.ToVector(); err == nil {
+		t.Error("expected an error when serializing invalid vector")
+	}
+}
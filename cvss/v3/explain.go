@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+var (
+	impactValues      = map[string]string{"H": "High", "L": "Low", "N": "None"}
+	requirementValues = map[string]string{"H": "High", "M": "Medium", "L": "Low"}
+
+	// definitions are the names of metrics and values of CVSS v3 specification, modified base metrics are added by init
+	definitions = common.MetricDefinitions{
+		"AV": {Name: "Attack Vector", Values: map[string]string{"N": "Network", "A": "Adjacent", "L": "Local", "P": "Physical"}},
+		"AC": {Name: "Attack Complexity", Values: map[string]string{"L": "Low", "H": "High"}},
+		"PR": {Name: "Privileges Required", Values: map[string]string{"N": "None", "L": "Low", "H": "High"}},
+		"UI": {Name: "User Interaction", Values: map[string]string{"N": "None", "R": "Required"}},
+		"S":  {Name: "Scope", Values: map[string]string{"U": "Unchanged", "C": "Changed"}},
+		"C":  {Name: "Confidentiality", Values: impactValues},
+		"I":  {Name: "Integrity", Values: impactValues},
+		"A":  {Name: "Availability", Values: impactValues},
+		"E":  {Name: "Exploit Code Maturity", Values: map[string]string{"H": "High", "F": "Functional", "P": "Proof-of-Concept", "U": "Unproven"}},
+		"RL": {Name: "Remediation Level", Values: map[string]string{"U": "Unavailable", "W": "Workaround", "T": "Temporary Fix", "O": "Official Fix"}},
+		"RC": {Name: "Report Confidence", Values: map[string]string{"C": "Confirmed", "R": "Reasonable", "U": "Unknown"}},
+		"CR": {Name: "Confidentiality Requirement", Values: requirementValues},
+		"IR": {Name: "Integrity Requirement", Values: requirementValues},
+		"AR": {Name: "Availability Requirement", Values: requirementValues},
+	}
+)
+
+func init() {
+	for _, metric := range baseMetrics {
+		def := definitions[metric]
+		definitions["M"+metric] = common.MetricDefinition{Name: "Modified " + def.Name, Values: def.Values}
+	}
+}
+
+// Definitions returns the names of metrics and values of the specification, by metric;
+// the table is built on every call, so it can be modified freely
+func Definitions() common.MetricDefinitions {
+	return definitions.Copy()
+}
+
+// Explain returns the metrics of the vector in the order of the specification with the names of the metrics and values
+// and the weights they contribute to the scores, to tell why the vector scores as it does; privileges required weights
+// are adjusted for the scope, as in BaseWeights. Vectors lacking base metrics are an error.
+func (v Vector) Explain() ([]common.MetricExplanation, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	explanations := v.WeightsMetrics.Explain(canonicalOrder, definitions, metricGroups)
+	for i := range explanations {
+		switch explanations[i].Metric {
+		case "PR":
+			explanations[i].Weight = v.prWeight()
+		case "MPR":
+			explanations[i].Weight = v.modifiedPRWeight()
+		}
+	}
+	return explanations, nil
+}
@@ -16,62 +16,188 @@ package v3
 
 import (
 	"math"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
 func roundUp(x float64) float64 {
 	// round up to one decimal
-	return math.Ceil(x*10) / 10
+	return common.RoundUp.Round(x)
 }
 
-// Score = combined score for the whole Vector
+// Score = combined score for the whole Vector.
+// Formulas are the ones of the vector's minor version (see Version): CVSS v3.1 rounds up without floating point
+// errors (see common.RoundUpV31) and changed the modified impact of environmental score for changed scope.
 func (v Vector) Score() float64 {
+	return v.ScoreWith(v.rounding())
+}
+
+// ScoreWith calculates combined score for the whole Vector using a custom rounding strategy.
+// Only use it to reproduce scores of legacy systems, specification requires round up, see Score.
+func (v Vector) ScoreWith(r common.Rounding) float64 {
+	return common.ClampScore(v.scoreWith(r))
+}
+
+// ScoreStrict is like Score, but the score out of the CVSS range, which only broken custom weight tables yield,
+// is an error rather than clamped, see common.CheckScore
+func (v Vector) ScoreStrict() (float64, error) {
+	score := v.scoreWith(v.rounding())
+	if err := common.CheckScore(score); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+func (v Vector) scoreWith(r common.Rounding) float64 {
 	// combines all of them
-	return v.environmentalScore()
+	if v.Version() == "3.1" && !v.Completeness().Has(common.EnvironmentalGroup) {
+		// CVSS v3.1 modified impact doesn't reduce to the base impact, so environmental score of vectors
+		// without environmental metrics might differ from the temporal one, which is the score of such vectors
+		return v.temporalScoreWith(r)
+	}
+	return v.environmentalScoreWith(r)
 }
 
-func (v Vector) baseScore() float64 {
-	i, e := v.impactScore(), v.exploitabilityScore()
-	if i < 0 {
-		return 0
+// BaseScore returns the score of base metrics only, temporal and environmental metrics are ignored
+func (v Vector) BaseScore() float64 {
+	return common.ClampScore(v.baseScore())
+}
+
+// BaseScoreRaw returns the base score at full precision, before the final round up, e.g. for distributions of scores
+// the one decimal steps would distort. It isn't the score of the specification, display BaseScore instead.
+func (v Vector) BaseScoreRaw() float64 {
+	return v.baseScoreWith(common.NoRounding)
+}
+
+// TemporalScore returns the score of base and temporal metrics, environmental metrics are ignored
+func (v Vector) TemporalScore() float64 {
+	return common.ClampScore(v.temporalScore())
+}
+
+// TemporalScoreWithDefaults is like TemporalScore, but temporal metrics the vector lacks are taken as set to their
+// value in defaults, e.g. {"RC": "R"} to penalize omitted report confidence; the specification takes them as neutral,
+// as do lacking metrics without a default. Defaults of other than temporal metrics or of invalid values are an error.
+func (v Vector) TemporalScoreWithDefaults(defaults common.Defaults) (float64, error) {
+	if err := defaults.Validate(v.Weights, temporalMetrics...); err != nil {
+		return 0, err
 	}
-	c := 1.0
-	if v.baseScopeChanged() {
-		c = 1.08
+	r := v.rounding()
+	return common.ClampScore(r.Round(v.baseScoreWith(r) * v.temporalWeight(defaults))), nil
+}
+
+// Residual returns the base score of the vector and the temporal score it has once remediated, i.e. with
+// remediation level set to rl (e.g. O for official fix) and, unless empty, exploit code maturity set to e;
+// the scores are computed on a copy, the vector isn't modified. Invalid vectors and values are an error.
+func (v Vector) Residual(rl, e string) (base, residual float64, err error) {
+	if err = v.Validate(); err != nil {
+		return 0, 0, err
 	}
+	remediated := v.Clone()
+	if err = remediated.Set("RL", rl); err != nil {
+		return 0, 0, err
+	}
+	if e != "" {
+		if err = remediated.Set("E", e); err != nil {
+			return 0, 0, err
+		}
+	}
+	return v.BaseScore(), remediated.TemporalScore(), nil
+}
 
-	return roundUp(math.Min(c*(e+i), 10.0))
+// EnvironmentalScore returns the score of the environmental equations, which override base metrics with modified ones
+func (v Vector) EnvironmentalScore() float64 {
+	return common.ClampScore(v.environmentalScore())
+}
+
+// ImpactSubscore returns the impact subscore of base metrics rounded to one decimal, as published by NVD;
+// it's never negative, though the equation for changed scope might be for tiny impacts
+func (v Vector) ImpactSubscore() float64 {
+	return common.RoundHalfUp.Round(math.Max(v.impactScore(), 0))
+}
+
+// ExploitabilitySubscore returns the exploitability subscore of base metrics rounded to one decimal, as published by NVD
+func (v Vector) ExploitabilitySubscore() float64 {
+	return common.RoundHalfUp.Round(v.exploitabilityScore())
+}
+
+func (v Vector) baseScore() float64 {
+	return v.baseScoreWith(v.rounding())
+}
+
+// rounding returns round up function of the vector's minor version
+func (v Vector) rounding() common.Rounding {
+	if v.Version() == "3.1" {
+		return common.RoundUpV31
+	}
+	return common.RoundUp
+}
+
+func (v Vector) baseScoreWith(r common.Rounding) float64 {
+	return combinedScore(v.impactScore(), v.exploitabilityScore(), v.baseScopeChanged(), r)
 }
 
 func (v Vector) temporalScore() float64 {
-	return roundUp(v.baseScore() * v.WeightDefault("E", 1.0) * v.WeightDefault("RL", 1.0) * v.WeightDefault("RC", 1.0))
+	return v.temporalScoreWith(v.rounding())
+}
+
+func (v Vector) temporalScoreWith(r common.Rounding) float64 {
+	return r.Round(v.baseScoreWith(r) * v.temporalWeight(nil))
+}
+
+// temporalMetrics are the metrics of the temporal group
+var temporalMetrics = []string{"E", "RL", "RC"}
+
+// temporalWeight returns the product of temporal metrics weights, omitted metrics weigh as set in defaults or 1
+func (v Vector) temporalWeight(defaults common.Defaults) float64 {
+	w := 1.0
+	for _, metric := range temporalMetrics {
+		w *= v.WeightOmitted(metric, "X", defaults, 1.0)
+	}
+	return w
 }
 
 func (v Vector) environmentalScore() float64 {
-	i, e := v.modifiedImpactScore(), v.modifiedExploitabilityScore()
-	if i < 0 {
+	return v.environmentalScoreWith(v.rounding())
+}
+
+func (v Vector) environmentalScoreWith(r common.Rounding) float64 {
+	s := combinedScore(v.modifiedImpactScore(), v.modifiedExploitabilityScore(), v.modifiedScopeChanged(), r)
+	return r.Round(s * v.temporalWeight(nil))
+}
+
+// helpers
+
+// combinedScore computes the score out of impact and exploitability subscores
+func combinedScore(i, e float64, scopeChanged bool, r common.Rounding) float64 {
+	if i <= 0 { // no impact, no score
 		return 0
 	}
 	c := 1.0
-	if v.modifiedScopeChanged() {
+	if scopeChanged {
 		c = 1.08
 	}
+	return r.Round(math.Min(c*(e+i), 10.0))
+}
 
-	return roundUp(roundUp(math.Min(c*(e+i), 10.0)) * v.WeightDefault("E", 1.0) * v.WeightDefault("RL", 1.0) * v.WeightDefault("RC", 1.0))
+// impactSubscore computes the impact subscore out of the impact sub score base (ISC_base)
+func impactSubscore(isc float64, scopeChanged bool) float64 {
+	if scopeChanged {
+		return 7.52*(isc-0.029) - 3.25*math.Pow((isc-0.02), 15)
+	}
+	return 6.42 * isc
 }
 
-// helpers
+func exploitabilitySubscore(av, ac, pr, ui float64) float64 {
+	return 8.22 * av * ac * pr * ui
+}
 
 func (v Vector) impactScore() float64 {
 	iscBase := 1 - (1-v.WeightMust("C"))*(1-v.WeightMust("I"))*(1-v.WeightMust("A"))
-	if v.baseScopeChanged() {
-		return 7.52*(iscBase-0.029) - 3.25*math.Pow((iscBase-0.02), 15)
-	} else {
-		return 6.42 * iscBase
-	}
+	return impactSubscore(iscBase, v.baseScopeChanged())
 }
 
 func (v Vector) exploitabilityScore() float64 {
-	return 8.22 * v.WeightMust("AV") * v.WeightMust("AC") * v.prWeight() * v.WeightMust("UI")
+	return exploitabilitySubscore(v.WeightMust("AV"), v.WeightMust("AC"), v.prWeight(), v.WeightMust("UI"))
 }
 
 func (v Vector) modifiedImpactScore() float64 {
@@ -81,13 +207,13 @@ func (v Vector) modifiedImpactScore() float64 {
 			(1-v.modifiedWeight("A")*v.WeightDefault("AR", 1.0)),
 		0.915,
 	)
-	if v.modifiedScopeChanged() {
-		return 7.52*(iscModified-0.029) - 3.25*math.Pow((iscModified-0.02), 15)
-	} else {
-		return 6.42 * iscModified
+	if v.Version() == "3.1" && v.modifiedScopeChanged() {
+		// CVSS v3.1 changed the equation
+		return 7.52*(iscModified-0.029) - 3.25*math.Pow(iscModified*0.9731-0.02, 13)
 	}
+	return impactSubscore(iscModified, v.modifiedScopeChanged())
 }
 
 func (v Vector) modifiedExploitabilityScore() float64 {
-	return 8.22 * v.modifiedWeight("AV") * v.modifiedWeight("AC") * v.modifiedPRWeight() * v.modifiedWeight("UI")
+	return exploitabilitySubscore(v.modifiedWeight("AV"), v.modifiedWeight("AC"), v.modifiedPRWeight(), v.modifiedWeight("UI"))
 }
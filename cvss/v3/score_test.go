@@ -17,6 +17,8 @@ package v3
 import (
 	"fmt"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
 func TestRoundUp(t *testing.T) {
@@ -63,6 +65,21 @@ func TestScores(t *testing.T) {
 	}
 }
 
+func TestScoreWith(t *testing.T) {
+	v := NewVector()
+	v.Parse("CVSS:3.0/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:H/A:H/E:P/RL:T/RC:C/AR:L/MAV:P/MPR:H/MS:C/MC:H/MI:N/MA:H")
+
+	if s, def := v.ScoreWith(common.RoundUp), v.Score(); s != def {
+		t.Errorf("score with default rounding expected to be %.1f, got %.1f", def, s)
+	}
+	if s := v.ScoreWith(common.Truncate); s > v.Score() {
+		t.Errorf("truncated score %.1f expected to be less or equal to the default one %.1f", s, v.Score())
+	}
+	if s := v.ScoreWith(common.RoundingFunc(func(float64) float64 { return 0 })); s != 0 {
+		t.Errorf("custom rounding wasn't applied, got %.1f", s)
+	}
+}
+
 func BenchmarkScore(b *testing.B) {
 	v := NewVector()
 	v.Parse("CVSS:3.0/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:H/A:H/E:P/RL:T/RC:C/AR:L/MAV:P/MPR:H/MS:C/MC:H/MI:N/MA:H")
@@ -71,3 +88,196 @@ func BenchmarkScore(b *testing.B) {
 		v.Score()
 	}
 }
+
+func TestScoresNoImpact(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:N/I:N/A:N"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.Score(); s != 0 {
+		t.Errorf("vector without impact expected to score 0, got %.1f", s)
+	}
+}
+
+func TestScoreMinorVersions(t *testing.T) {
+	// v3.1 changed modified impact equation for changed scope, see section 7.3 of the specifications
+	const metrics = "AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:L/A:N/MAV:P/MS:C/MC:H/MI:H/MA:H"
+	cases := map[string]float64{"3.0": 7.6, "3.1": 7.7}
+	for version, expected := range cases {
+		v := NewVector()
+		if err := v.Parse("CVSS:" + version + "/" + metrics); err != nil {
+			t.Fatal(err)
+		}
+		if v.Version() != version {
+			t.Errorf("expected version %s, got %s", version, v.Version())
+		}
+		if s := v.String(); s[:9] != "CVSS:"+version+"/" {
+			t.Errorf("expected version %s to be kept in %s", version, s)
+		}
+		if s := v.Score(); s != expected {
+			t.Errorf("CVSS v%s: expected %.1f, got %.1f", version, expected, s)
+		}
+		if s := v.BaseScore(); s != 8.2 {
+			t.Errorf("CVSS v%s: expected base score 8.2, got %.1f", version, s)
+		}
+	}
+
+	// vectors without environmental metrics score the same in both
+	v := NewVector()
+	if err := v.Parse("CVSS:3.1/AV:A/AC:H/PR:H/UI:N/S:C/C:H/I:H/A:H"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.Score(); s != 7.6 {
+		t.Errorf("expected 7.6, got %.1f", s)
+	}
+
+	if err := NewVector().Parse("CVSS:3.2/AV:N"); err == nil {
+		t.Error("expected an error for unsupported minor version")
+	}
+	v = NewVector()
+	if v.Version() != "3.0" {
+		t.Errorf("expected default version 3.0, got %s", v.Version())
+	}
+	if err := v.SetVersion("3.1"); err != nil || v.Version() != "3.1" {
+		t.Errorf("failed to set version: %v", err)
+	}
+	if err := v.Freeze().SetVersion("3.0"); err == nil {
+		t.Error("expected frozen vector to reject version change")
+	}
+}
+
+func TestResidual(t *testing.T) {
+	const vector = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/RL:U"
+	cases := []struct {
+		rl, e    string
+		residual float64
+	}{
+		{"O", "", 9.4},
+		{"O", "P", 8.8},
+		{"U", "", 9.8},
+	}
+	for _, c := range cases {
+		v := NewVector()
+		if err := v.Parse(vector); err != nil {
+			t.Fatal(err)
+		}
+		base, residual, err := v.Freeze().Residual(c.rl, c.e)
+		if err != nil {
+			t.Fatalf("RL:%s E:%s: %v", c.rl, c.e, err)
+		}
+		if base != 9.8 || residual != c.residual {
+			t.Errorf("RL:%s E:%s: expected 9.8 and %.1f, got %.1f and %.1f", c.rl, c.e, c.residual, base, residual)
+		}
+		if s := v.String(); s != "CVSS:3.1/A:H/AC:L/AV:N/C:H/I:H/PR:N/RL:U/S:U/UI:N" {
+			t.Errorf("RL:%s E:%s: vector was modified: %s", c.rl, c.e, s)
+		}
+	}
+	v := NewVector()
+	if err := v.Parse(vector); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := v.Residual("OF", ""); err == nil {
+		t.Error("invalid remediation level is expected to be an error")
+	}
+	if _, _, err := NewVector().Residual("O", ""); err == nil {
+		t.Error("incomplete vector is expected to be an error")
+	}
+}
+
+func TestModifiedMetrics(t *testing.T) {
+	cases := []struct {
+		vector   string
+		expected float64
+	}{
+		// defined modified privileges replace the base ones regardless of the scope
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/MPR:H", 7.2},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H/MPR:N", 10.0},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H/MS:C", 9.9},
+		// modified metrics only, the base ones aren't needed
+		{"CVSS:3.0/MAV:N/MAC:L/MPR:N/MUI:N/MS:U/MC:H/MI:H/MA:H", 9.8},
+		{"CVSS:3.0/MAV:N/MAC:L/MPR:L/MUI:N/MS:C/MC:H/MI:H/MA:H", 9.9},
+	}
+	for _, c := range cases {
+		v := NewVector()
+		if err := v.Parse(c.vector); err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if s := v.EnvironmentalScore(); s != c.expected {
+			t.Errorf("%s: expected environmental score %.1f, got %.1f", c.vector, c.expected, s)
+		}
+	}
+}
+
+func TestTemporalScoreWithDefaults(t *testing.T) {
+	reasonable := common.Defaults{"RC": "R"}
+	cases := []struct {
+		vector string
+		score  float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.5},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P", 8.9},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/RC:C", 9.8},
+	}
+	for _, c := range cases {
+		v := NewVector()
+		if err := v.Parse(c.vector); err != nil {
+			t.Fatal(err)
+		}
+		score, err := v.TemporalScoreWithDefaults(reasonable)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if score != c.score {
+			t.Errorf("%s: expected %.1f, got %.1f", c.vector, c.score, score)
+		}
+		if score, err = v.TemporalScoreWithDefaults(nil); err != nil || score != v.TemporalScore() {
+			t.Errorf("%s: without defaults expected %.1f, got %.1f (%v)", c.vector, v.TemporalScore(), score, err)
+		}
+	}
+	v := NewVector()
+	if err := v.Parse(cases[0].vector); err != nil {
+		t.Fatal(err)
+	}
+	for _, defaults := range []common.Defaults{{"RC": "UR"}, {"MAV": "N"}} {
+		if _, err := v.TemporalScoreWithDefaults(defaults); err == nil {
+			t.Errorf("defaults %v are expected to be an error", defaults)
+		}
+	}
+}
+
+func TestBaseScoreRaw(t *testing.T) {
+	for _, vector := range []string{
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:H/PR:L/UI:R/S:C/C:L/I:L/A:N",
+	} {
+		v := NewVector()
+		if err := v.Parse(vector); err != nil {
+			t.Fatal(err)
+		}
+		raw, base := v.BaseScoreRaw(), v.BaseScore()
+		if raw == base || v.rounding().Round(raw) != base {
+			t.Errorf("%s: expected raw score rounding up to %.1f, got %v", vector, base, raw)
+		}
+	}
+}
+
+func TestScoreStrict(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"); err != nil {
+		t.Fatal(err)
+	}
+	if score, err := v.ScoreStrict(); err != nil || score != v.Score() {
+		t.Errorf("standard weights: expected %v, got %v, %v", v.Score(), score, err)
+	}
+	// a broken custom weight table, the exploitability subscore outweighs the impact negatively
+	v.Weights = v.WeightTable()
+	v.Weights["AV"]["N"] = -5.0
+	if score := v.Score(); score != 0 {
+		t.Errorf("expected the score clamped to 0, got %v", score)
+	}
+	if _, err := v.ScoreStrict(); err == nil {
+		t.Error("expected an error for the broken weight table")
+	} else if _, ok := err.(common.ErrScoreOutOfRange); !ok {
+		t.Errorf("expected common.ErrScoreOutOfRange, got %T: %v", err, err)
+	}
+}
@@ -16,17 +16,20 @@ package v3
 
 import (
 	"fmt"
-	"strings"
+	"regexp"
 
 	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
-const (
-	prefix = "CVSS:3.0/"
-)
+// defaultVersion is CVSS v3 minor version of vectors which don't declare one
+const defaultVersion = "3.0"
+
+// supportedVersions are CVSS v3 minor versions, they differ in scoring, see Vector.Score
+var supportedVersions = map[string]bool{"3.0": true, "3.1": true}
 
 var (
-	weights map[string]map[string]float64 // main weights, filled with the ones below
+	weights      map[string]map[string]float64 // main weights, filled with the ones below
+	metricGroups map[string]common.MetricGroups
 
 	baseMetricsWeights = map[string]map[string]float64{
 		"AV": { // Attack Vector
@@ -111,24 +114,161 @@ var (
 func init() {
 	// create weights
 	weights = make(map[string]map[string]float64)
+	metricGroups = make(map[string]common.MetricGroups)
 	for metric, values := range baseMetricsWeights {
 		weights[metric] = values
 		weights["M"+metric] = values // environmental
+		metricGroups[metric] = common.BaseGroup
+		metricGroups["M"+metric] = common.EnvironmentalGroup
 	}
 	for metric, values := range temporalMetricsWeights {
 		weights[metric] = values
+		metricGroups[metric] = common.TemporalGroup
 	}
 	for metric, values := range environmentalMetricsWeights {
 		weights[metric] = values
+		metricGroups[metric] = common.EnvironmentalGroup
 	}
 }
 
 type Vector struct {
 	common.WeightsMetrics
+	order   *[]string // metrics in the order of the parsed input, only recorded by vectors from NewOrderedVector
+	version *string   // minor version declared by the parsed input or set with SetVersion, empty for the default
 }
 
 func NewVector() Vector {
-	return Vector{common.WeightsMetrics{make(common.Metrics), weights}}
+	return Vector{WeightsMetrics: common.WeightsMetrics{Metrics: make(common.Metrics), Weights: weights}, version: new(string)}
+}
+
+// Version returns CVSS v3 minor version of the vector, 3.0 or 3.1: the one declared by the prefix of the parsed
+// input (e.g. CVSS:3.1/) or set with SetVersion, 3.0 if neither. The version selects the scoring formulas, see Score.
+func (v Vector) Version() string {
+	if v.version == nil || *v.version == "" {
+		return defaultVersion
+	}
+	return *v.version
+}
+
+// SetVersion sets CVSS v3 minor version of the vector, see Version
+func (v Vector) SetVersion(version string) error {
+	if !supportedVersions[version] {
+		return fmt.Errorf("unsupported CVSS version %q", version)
+	}
+	if v.Frozen() {
+		return common.ErrFrozen{Metric: "CVSS"}
+	}
+	if v.version == nil {
+		return fmt.Errorf("can't set version %q: vector not initialized", version)
+	}
+	*v.version = version
+	return nil
+}
+
+// prefix returns the prefix of the vector string, e.g. CVSS:3.0/
+func (v Vector) prefix() string {
+	return "CVSS:" + v.Version() + "/"
+}
+
+// copyVersion returns the version of the vector not shared with the vector
+func (v Vector) copyVersion() *string {
+	version := v.Version()
+	return &version
+}
+
+// NewOrderedVector is like NewVector, but the vector records the order metrics appear in the parsed input, see OriginalString
+func NewOrderedVector() Vector {
+	v := NewVector()
+	v.order = new([]string)
+	return v
+}
+
+// OriginalString returns the vector string with metrics in the order they appeared in the parsed input,
+// metrics set otherwise follow in lexical order. Vectors not created with NewOrderedVector return String.
+func (v Vector) OriginalString() string {
+	if v.order == nil {
+		return v.String()
+	}
+	return v.prefix() + v.Metrics.CanonicalString(*v.order)
+}
+
+// recordOrder appends metrics of successfully parsed str to the input order, if it's recorded
+func (v Vector) recordOrder(str string) {
+	if v.order != nil {
+		*v.order = append(*v.order, common.MetricNames(str)...)
+	}
+}
+
+// Freeze returns a read-only copy of the vector, safe for concurrent use: Set and Parse of the copy fail
+// with common.ErrFrozen, see common.WeightsMetrics.Freeze
+func (v Vector) Freeze() Vector {
+	v.WeightsMetrics = v.WeightsMetrics.Freeze()
+	v.version = v.copyVersion()
+	if v.order != nil {
+		order := append([]string(nil), *v.order...)
+		v.order = &order
+	}
+	return v
+}
+
+// Clone returns a modifiable copy of the vector, which doesn't share metrics with it, even if the vector is frozen
+func (v Vector) Clone() Vector {
+	c := v
+	c.WeightsMetrics = common.WeightsMetrics{Metrics: make(common.Metrics, len(v.Metrics)), Weights: v.Weights}
+	for metric, value := range v.Metrics {
+		c.Metrics[metric] = value
+	}
+	c.version = v.copyVersion()
+	if v.order != nil {
+		order := append([]string(nil), *v.order...)
+		c.order = &order
+	}
+	return c
+}
+
+// BaseOnly returns a copy of the vector limited to the base metrics, without temporal and environmental ones;
+// the order of the parsed input is kept for the base metrics, see OriginalString
+func (v Vector) BaseOnly() Vector {
+	base := v
+	base.Metrics = v.Metrics.Only(baseMetrics...)
+	base.version = v.copyVersion()
+	base.order = v.orderOf(base.Metrics)
+	return base
+}
+
+// Normalize returns a copy of the vector without temporal and environmental metrics set to X (not defined),
+// which don't affect the scores, so equivalent vectors normalize to the same CanonicalString;
+// the order of the parsed input is kept for the rest, see OriginalString
+func (v Vector) Normalize() Vector {
+	n := v
+	n.Metrics = v.Metrics.Defined(metricGroups, "X")
+	n.version = v.copyVersion()
+	n.order = v.orderOf(n.Metrics)
+	return n
+}
+
+// orderOf returns a copy of the input order limited to the metrics, nil if the order isn't recorded
+func (v Vector) orderOf(metrics common.Metrics) *[]string {
+	if v.order == nil {
+		return nil
+	}
+	order := make([]string, 0, len(metrics))
+	for _, metric := range *v.order {
+		if metrics.Has(metric) {
+			order = append(order, metric)
+		}
+	}
+	return &order
+}
+
+// ParseLenient is like Parse, but accepts metrics repeated with the same value, e.g. AV:N/AC:L/.../AV:N;
+// metrics repeated with conflicting values are still an error
+func (v Vector) ParseLenient(str string) error {
+	str, err := common.DedupMetrics(str)
+	if err != nil {
+		return err
+	}
+	return v.Parse(str)
 }
 
 func (v Vector) Validate() error {
@@ -140,18 +280,102 @@ func (v Vector) Validate() error {
 	return nil
 }
 
+// baseMetrics lists base metrics in the order of the specification
+var baseMetrics = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"}
+
+// canonicalOrder lists all metrics in the order of the specification
+var canonicalOrder = []string{
+	"AV", "AC", "PR", "UI", "S", "C", "I", "A", // base
+	"E", "RL", "RC", // temporal
+	"CR", "IR", "AR", "MAV", "MAC", "MPR", "MUI", "MS", "MC", "MI", "MA", // environmental
+}
+
+// MetricGroups returns the metrics of every metric group in the order of the specification;
+// the table is built on every call, so it can be modified freely
+func MetricGroups() map[common.MetricGroups][]string {
+	return common.GroupMetrics(metricGroups, canonicalOrder)
+}
+
+// Completeness returns the metric groups the vector has metrics of, metrics set to X (not defined) don't count
+func (v Vector) Completeness() common.MetricGroups {
+	return v.Metrics.Completeness(metricGroups, "X")
+}
+
+// CanonicalString returns the vector string with metrics in the order of the specification.
+// Unlike String, the order is frozen and won't change, so it's suitable as a cache key.
+func (v Vector) CanonicalString() string {
+	return v.prefix() + v.Metrics.CanonicalString(canonicalOrder)
+}
+
+// BaseWeights returns base metrics in the order of the specification along with their values
+// and the weights that contribute to the base score; privileges required weight is adjusted for the scope
+func (v Vector) BaseWeights() ([]common.MetricWeight, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	mws, err := v.MetricWeights(baseMetrics...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mws {
+		if mws[i].Metric == "PR" {
+			mws[i].Weight = v.prWeight()
+		}
+	}
+	return mws, nil
+}
+
 // Override Parse and String to remove/add prefix
 
+// prefixRe matches the version prefix of vector string; ASCII case folding only, see TestParsePrefix
+var prefixRe = regexp.MustCompile(`^[Cc][Vv][Ss][Ss]:([0-9]+\.[0-9]+)/`)
+
+// v2Metrics are CVSS v2 metrics absent in v3, they reveal vectors of mismatched version
+var v2Metrics = map[string]bool{"Au": true, "CDP": true, "TD": true}
+
 func (v Vector) Parse(str string) error {
 	// remove prefix if exists
-	if strings.HasPrefix(strings.ToUpper(str), prefix) {
-		str = str[len(prefix):]
+	declared := ""
+	if m := prefixRe.FindStringSubmatch(str); m != nil {
+		declared, str = m[1], str[len(m[0]):]
+	}
+	for _, metric := range common.MetricNames(str) {
+		if !v2Metrics[metric] {
+			continue
+		}
+		if declared != "" {
+			return fmt.Errorf("vector declares CVSS v%s, but metric %q belongs to CVSS v2", declared, metric)
+		}
+		return fmt.Errorf("CVSS v3 vector can't have CVSS v2 metric %q", metric)
+	}
+	if declared != "" && !supportedVersions[declared] {
+		return fmt.Errorf("unsupported CVSS version %q", declared)
+	}
+	if err := v.WeightsMetrics.Parse(str); err != nil {
+		return err
+	}
+	if declared != "" && v.version != nil {
+		*v.version = declared
+	}
+	v.recordOrder(str)
+	return nil
+}
+
+// ParseWithPolicy is like Parse, but metrics and values not defined for CVSS v3 are handled as per policy,
+// e.g. to accept vendor extensions; nil policy is as strict as Parse, see common.Policy
+func (v Vector) ParseWithPolicy(str string, policy common.Policy) error {
+	known, accepted, err := common.FilterUnknown(str, v.Weights, policy)
+	if err != nil {
+		return err
 	}
-	return v.WeightsMetrics.Parse(str)
+	if err = v.Parse(known); err != nil {
+		return err
+	}
+	return v.SetVerbatim(accepted)
 }
 
 func (v Vector) String() string {
-	return prefix + v.WeightsMetrics.String()
+	return v.prefix() + v.WeightsMetrics.String()
 }
 
 // weight functions
@@ -159,33 +383,29 @@ func (v Vector) String() string {
 func (v Vector) modifiedWeight(metric string) float64 {
 	// get M${metric} from environmental vector
 	// if it's not defined, then get the same for $metric from the base vector
-	if w, err := v.Weight("M" + metric); err == nil {
-		return w
+	if v.Has("M" + metric) {
+		return v.WeightMust("M" + metric)
 	}
 	return v.WeightMust(metric)
 }
 
 func (v Vector) prWeight() float64 {
-	return v.prWeightWith(v.baseScopeChanged())
+	return v.prWeightOf("PR", v.baseScopeChanged())
 }
 
 func (v Vector) modifiedPRWeight() float64 {
-	msc := v.modifiedScopeChanged()
-	if msc {
-		if mpr, err := v.Get("MPR"); err == nil {
-			if mpr == "L" {
-				return 0.68
-			} else if mpr == "H" {
-				return 0.50
-			}
-		}
+	// defined modified privileges replace the base ones, including the adjustment for changed scope;
+	// only when they're not defined the base ones are needed
+	if v.Has("MPR") {
+		return v.prWeightOf("MPR", v.modifiedScopeChanged())
 	}
-	return v.prWeightWith(msc)
+	return v.prWeightOf("PR", v.modifiedScopeChanged())
 }
 
-func (v Vector) prWeightWith(scopeChanged bool) float64 {
+// prWeightOf returns the weight of privileges required metric (PR or MPR), which depends on the scope
+func (v Vector) prWeightOf(metric string, scopeChanged bool) float64 {
 	if scopeChanged {
-		pr, err := v.Get("PR")
+		pr, err := v.Get(metric)
 		if err != nil {
 			panic(err) // must be present because of Validate
 		}
@@ -196,7 +416,7 @@ func (v Vector) prWeightWith(scopeChanged bool) float64 {
 			return 0.50
 		}
 	}
-	return v.WeightMust("PR")
+	return v.WeightMust(metric)
 }
 
 // scope functions
@@ -217,3 +437,34 @@ func (v Vector) modifiedScopeChanged() bool {
 	}
 	return v.baseScopeChanged()
 }
+
+// EnumerateBase calls fn for every valid base vector, in deterministic order (see common.WeightsMetrics.Enumerate).
+// The same vector is reused for every call, so fn must not retain it; enumeration stops if fn returns false.
+func EnumerateBase(fn func(Vector) bool) {
+	v := NewVector()
+	if err := v.Enumerate(baseMetrics, func() bool { return fn(v) }); err != nil {
+		panic(err) // base metrics are always defined
+	}
+}
+
+// BaseScoreRange returns the minimum and maximum base score over all completions of the base metrics the vector lacks,
+// bounding the score of incomplete vectors; temporal and environmental metrics are disregarded. The vector isn't modified.
+func (v Vector) BaseScoreRange() (min, max float64) {
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	min, max, err := base.ScoreRange(baseMetrics, base.BaseScore)
+	if err != nil {
+		panic(err) // base metrics are always defined
+	}
+	return min, max
+}
+
+// BaseSensitivity returns the contribution of each base metric to the base score, in the order of the specification,
+// to explain the score: the base score less the mean base score over the values of the metric, see
+// common.WeightsMetrics.Sensitivity. The vector isn't modified; vectors lacking base metrics are an error.
+func (v Vector) BaseSensitivity() ([]common.MetricDelta, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	return base.Sensitivity(baseMetrics, base.BaseScore)
+}
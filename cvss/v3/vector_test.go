@@ -16,9 +16,30 @@ package v3
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 )
 
+func TestParseLenient(t *testing.T) {
+	str := "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/AV:N"
+	if err := NewVector().Parse(str); err == nil {
+		t.Errorf("%q: strict parser expected to reject repeated metric", str)
+	}
+	v := NewVector()
+	if err := v.ParseLenient(str); err != nil {
+		t.Errorf("%q: unexpected error %v", str, err)
+	} else if err := v.Validate(); err != nil {
+		t.Errorf("%q: %v", str, err)
+	}
+	str = "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/AV:L"
+	if err := NewVector().ParseLenient(str); err == nil {
+		t.Errorf("%q: lenient parser expected to reject conflicting values", str)
+	}
+}
+
 func TestParse(t *testing.T) {
 	// all possible metrics are defined in these 3 strings
 	base := "AV:P/AC:H/PR:L/UI:R/S:C/C:L/I:L/A:L"
@@ -82,3 +103,185 @@ func BenchmarkParse(b *testing.B) {
 		v.Parse("CVSS:3.0/AV:P/AC:H/PR:L/UI:R/S:C/C:L/I:L/A:L/E:U/RL:T/RC:R/CR:H/IR:M/AR:L/MAV:P/MAC:H/MPR:L/MUI:R/MS:U/MC:L/MI:L/MA:H")
 	}
 }
+
+func FuzzParseV3(f *testing.F) {
+	f.Add("CVSS:3.0/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:H/A:H/E:P/RL:T/RC:C/AR:L/MAV:P/MPR:H/MS:C/MC:H/MI:N/MA:H")
+	f.Add("AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+	f.Add("CVSS:3.0/")
+	f.Add("cvss:3.0/AV:N/AV:N")
+	f.Add("AV:/:N//")
+	f.Fuzz(func(t *testing.T, str string) {
+		v := NewVector()
+		if err := v.Parse(str); err != nil {
+			return
+		}
+		_ = v.String()
+		if err := v.Validate(); err != nil {
+			return
+		}
+		if s := v.Score(); s < 0 || s > 10 {
+			t.Errorf("%q: score %.1f out of range", str, s)
+		}
+	})
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := map[string]bool{
+		"CVSS:3.0/AV:N": true,
+		"cvss:3.0/AV:N": true,
+		"CVſſ:3.0/AV:N": false, // U+017F upper-cases to S, but it's not the prefix
+	}
+	for str, ok := range tests {
+		if err := NewVector().Parse(str); (err == nil) != ok {
+			t.Errorf("%q: unexpected parse result: %v", str, err)
+		}
+	}
+}
+
+func TestBaseWeights(t *testing.T) {
+	v := NewVector()
+	if _, err := v.BaseWeights(); err == nil {
+		t.Error("expected an error for incomplete vector")
+	}
+	if err := v.Parse("CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:L/A:N"); err != nil {
+		t.Fatal(err)
+	}
+	mws, err := v.BaseWeights()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []common.MetricWeight{
+		{Metric: "AV", Value: "N", Weight: 0.85},
+		{Metric: "AC", Value: "L", Weight: 0.77},
+		{Metric: "PR", Value: "L", Weight: 0.68}, // scope changed
+		{Metric: "UI", Value: "N", Weight: 0.85},
+		{Metric: "S", Value: "C", Weight: 0.0},
+		{Metric: "C", Value: "H", Weight: 0.56},
+		{Metric: "I", Value: "L", Weight: 0.22},
+		{Metric: "A", Value: "N", Weight: 0.0},
+	}
+	if !reflect.DeepEqual(mws, expected) {
+		t.Errorf("expected %v, got %v", expected, mws)
+	}
+}
+
+func TestCanonicalString(t *testing.T) {
+	str := "CVSS:3.0/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:H/A:H/E:P/RL:T/RC:C/AR:L/MAV:P/MPR:H/MS:C/MC:H/MI:N/MA:H"
+	v1, v2 := NewVector(), NewVector()
+	if err := v1.Parse(str); err != nil {
+		t.Fatal(err)
+	}
+	if err := v2.Parse("MA:H/MI:N/MC:H/MS:C/MPR:H/MAV:P/AR:L/RC:C/RL:T/E:P/A:H/I:H/C:H/S:C/UI:R/PR:H/AC:H/AV:P"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v1.CanonicalString(); s != str {
+		t.Errorf("expected %q, got %q", str, s)
+	}
+	if s := v2.CanonicalString(); s != str {
+		t.Errorf("expected %q, got %q", str, s)
+	}
+}
+
+func TestParseVersionMismatch(t *testing.T) {
+	tests := map[string]string{
+		"CVSS:3.1/AV:N/AC:L/Au:N/C:H/I:H/A:H": `vector declares CVSS v3.1, but metric "Au" belongs to CVSS v2`,
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P":          `CVSS v3 vector can't have CVSS v2 metric "Au"`,
+		"CVSS:2.0/AV:N/AC:L/PR:N/UI:N/S:U":    `unsupported CVSS version "2.0"`,
+	}
+	for str, expected := range tests {
+		err := NewVector().Parse(str)
+		if err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %v", str, expected, err)
+		}
+	}
+}
+
+func TestOriginalString(t *testing.T) {
+	const str = "CVSS:3.0/S:U/AV:N/C:H/AC:L/PR:N/UI:N/I:H/A:H"
+	v := NewOrderedVector()
+	if err := v.Parse(str); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.OriginalString(); s != str {
+		t.Errorf("expected original order %q, got %q", str, s)
+	}
+	if s := v.String(); s != "CVSS:3.0/A:H/AC:L/AV:N/C:H/I:H/PR:N/S:U/UI:N" {
+		t.Errorf("String is expected to stay canonical, got %q", s)
+	}
+	if err := v.Set("E", "F"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.OriginalString(); s != str+"/E:F" {
+		t.Errorf("expected metrics not from input to follow, got %q", s)
+	}
+
+	v = NewVector()
+	if err := v.Parse(str); err != nil {
+		t.Fatal(err)
+	}
+	if v.OriginalString() != v.String() {
+		t.Errorf("unordered vector expected to return String, got %q", v.OriginalString())
+	}
+}
+
+func TestParseEmptyParts(t *testing.T) {
+	for _, str := range []string{
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/",
+		"CVSS:3.0//AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.0/AV:N/AC:L/PR:N//UI:N/S:U/C:H/I:H/A:H",
+	} {
+		v := NewVector()
+		if err := v.Parse(str); err != nil {
+			t.Errorf("%q: %v", str, err)
+		} else if s := v.Score(); s != 9.8 {
+			t.Errorf("%q: expected score 9.8, got %.1f", str, s)
+		}
+	}
+}
+
+func TestBaseOnly(t *testing.T) {
+	const str = "CVSS:3.0/S:U/E:U/AV:N/C:H/AC:L/MAV:L/PR:N/UI:N/I:H/A:H/CR:H/RL:O"
+	v := NewOrderedVector()
+	if err := v.Parse(str); err != nil {
+		t.Fatal(err)
+	}
+	base := v.BaseOnly()
+	if s := base.OriginalString(); s != "CVSS:3.0/S:U/AV:N/C:H/AC:L/PR:N/UI:N/I:H/A:H" {
+		t.Errorf("unexpected base vector %q", s)
+	}
+	if score := base.Score(); score != v.BaseScore() || score != 9.8 {
+		t.Errorf("expected base vector to score the base score %.1f, got %.1f", v.BaseScore(), score)
+	}
+	if s := v.OriginalString(); s != str {
+		t.Errorf("original vector was modified: %q", s)
+	}
+}
+
+func TestBaseSensitivity(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U"); err != nil {
+		t.Fatal(err)
+	}
+	deltas, err := v.BaseSensitivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltas) != len(baseMetrics) {
+		t.Fatalf("expected deltas of %d base metrics, got %v", len(baseMetrics), deltas)
+	}
+	for i, d := range deltas {
+		if d.Metric != baseMetrics[i] {
+			t.Errorf("delta %d: expected metric %s, got %s", i, baseMetrics[i], d.Metric)
+		}
+	}
+	// the score is 9.8, 8.1 with AC:H
+	if ac := deltas[1]; ac.Value != "L" || math.Abs(ac.Delta-0.85) > 1e-9 {
+		t.Errorf("expected AC:L to contribute 0.85, got %+v", ac)
+	}
+	if s := v.String(); s != "CVSS:3.1/A:H/AC:L/AV:N/C:H/E:U/I:H/PR:N/S:U/UI:N" {
+		t.Errorf("vector was modified: %s", s)
+	}
+	if _, err := NewVector().BaseSensitivity(); err == nil {
+		t.Error("expected an error for incomplete vector")
+	}
+}
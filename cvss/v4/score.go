@@ -0,0 +1,299 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v4
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+// group is a set of optional metric groups affecting the score
+type group int
+
+const (
+	threat group = 1 << iota
+	environmental
+)
+
+// Score = combined score for the whole Vector (CVSS-BTE)
+func (v Vector) Score() float64 {
+	return v.score(threat | environmental)
+}
+
+// BaseScore returns the score of base metrics only (CVSS-B)
+func (v Vector) BaseScore() float64 {
+	return v.score(0)
+}
+
+// BaseScoreRaw returns the base score at full precision, before the final rounding, e.g. for distributions of scores
+// the one decimal steps would distort. It isn't the score of the specification, display BaseScore instead.
+func (v Vector) BaseScoreRaw() float64 {
+	return v.rawScore(0)
+}
+
+// ThreatScore returns the score adjusted by threat metrics (CVSS-BT)
+func (v Vector) ThreatScore() float64 {
+	return v.score(threat)
+}
+
+// EnvironmentalScore returns the score adjusted by environmental metrics (CVSS-BE)
+func (v Vector) EnvironmentalScore() float64 {
+	return v.score(environmental)
+}
+
+// MacroVector returns the equivalence classes EQ1 to EQ6 of the whole vector, e.g. "001200"
+func (v Vector) MacroVector() string {
+	return macroKey(v.macroVector(threat | environmental))
+}
+
+// value returns the value of the metric the score is computed with:
+// modified metrics override the base ones and not defined E, CR, IR and AR assume the worst case
+func (v Vector) value(metric string, groups group) string {
+	switch metric {
+	case "E":
+		if value := v.Metrics[metric]; groups&threat != 0 && value != "" && value != "X" {
+			return value
+		}
+		return "A"
+	case "CR", "IR", "AR":
+		if value := v.Metrics[metric]; groups&environmental != 0 && value != "" && value != "X" {
+			return value
+		}
+		return "H"
+	}
+	if value, ok := v.Metrics["M"+metric]; groups&environmental != 0 && ok && value != "X" {
+		return value
+	}
+	return v.Metrics[metric]
+}
+
+// level returns the severity level of metric's value, the lower the more severe
+func level(metric, value string) float64 {
+	if values, ok := weights["M"+metric]; ok {
+		// modified metrics have all values of base ones and then some
+		return values[value]
+	}
+	return weights[metric][value]
+}
+
+func (v Vector) macroVector(groups group) [6]int {
+	m := func(metric string) string {
+		return v.value(metric, groups)
+	}
+	var eq [6]int
+
+	// EQ1: AV, PR, UI
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+
+	// EQ2: AC, AT
+	if m("AC") != "L" || m("AT") != "N" {
+		eq[1] = 1
+	}
+
+	// EQ3: VC, VI, VA
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+
+	// EQ4: SC, SI, SA
+	switch {
+	case m("SI") == "S" || m("SA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+
+	// EQ5: E
+	switch m("E") {
+	case "A":
+		eq[4] = 0
+	case "P":
+		eq[4] = 1
+	default:
+		eq[4] = 2
+	}
+
+	// EQ6: CR, IR, AR in conjunction with VC, VI, VA
+	if !(m("CR") == "H" && m("VC") == "H" || m("IR") == "H" && m("VI") == "H" || m("AR") == "H" && m("VA") == "H") {
+		eq[5] = 1
+	}
+
+	return eq
+}
+
+func macroKey(eq [6]int) string {
+	var b strings.Builder
+	for _, e := range eq {
+		b.WriteString(strconv.Itoa(e))
+	}
+	return b.String()
+}
+
+// lowerScore returns the score of the macro vector one step less severe in i-th equivalence class, NaN if there's none
+func lowerScore(eq [6]int, i int) float64 {
+	eq[i]++
+	if score, ok := lookup[macroKey(eq)]; ok {
+		return score
+	}
+	return math.NaN()
+}
+
+// maxVector returns the highest severity vector of the macro vector which is at least as severe as v in every metric
+func (v Vector) maxVector(eq [6]int, groups group) common.Metrics {
+	var max common.Metrics
+	for _, eq1 := range maxVectorsEQ1[eq[0]] {
+		for _, eq2 := range maxVectorsEQ2[eq[1]] {
+			for _, eq3eq6 := range maxVectorsEQ3EQ6[eq[2]][eq[5]] {
+				for _, eq4 := range maxVectorsEQ4[eq[3]] {
+					for _, eq5 := range maxVectorsEQ5[eq[4]] {
+						max = make(common.Metrics)
+						for _, part := range strings.Split(strings.Join([]string{eq1, eq2, eq3eq6, eq4, eq5}, "/"), "/") {
+							tmp := strings.Split(part, ":")
+							max[tmp[0]] = tmp[1]
+						}
+						if v.dominatedBy(max, groups) {
+							return max
+						}
+					}
+				}
+			}
+		}
+	}
+	return max // should not happen for vectors of this macro vector
+}
+
+// severityMetrics are the metrics taking part in the severity distance
+var severityMetrics = []string{"AV", "PR", "UI", "AC", "AT", "VC", "VI", "VA", "SC", "SI", "SA", "CR", "IR", "AR"}
+
+func (v Vector) dominatedBy(max common.Metrics, groups group) bool {
+	for _, metric := range severityMetrics {
+		if v.distance(max, metric, groups) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// distance returns severity distance of the metric from the one of max vector
+func (v Vector) distance(max common.Metrics, metric string, groups group) float64 {
+	return level(metric, v.value(metric, groups)) - level(metric, max[metric])
+}
+
+// ScoreStrict is like Score, but the score out of the CVSS range is an error rather than clamped, see common.CheckScore
+func (v Vector) ScoreStrict() (float64, error) {
+	score := v.roundedScore(threat | environmental)
+	if err := common.CheckScore(score); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+func (v Vector) score(groups group) float64 {
+	return common.ClampScore(v.roundedScore(groups))
+}
+
+func (v Vector) roundedScore(groups group) float64 {
+	// nudge away from floating point errors, as the reference calculator does
+	return common.RoundHalfUp.Round(v.rawScore(groups) + 1e-6)
+}
+
+// rawScore returns the score before rounding
+func (v Vector) rawScore(groups group) float64 {
+	none := true
+	for _, metric := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		if v.value(metric, groups) != "N" {
+			none = false
+			break
+		}
+	}
+	if none {
+		return 0.0
+	}
+
+	eq := v.macroVector(groups)
+	value, ok := lookup[macroKey(eq)]
+	if !ok {
+		return 0.0 // should not happen
+	}
+
+	// scores of the next lower macro vectors
+	lowerEQ1 := lowerScore(eq, 0)
+	lowerEQ2 := lowerScore(eq, 1)
+	var lowerEQ3EQ6 float64
+	switch {
+	case eq[2] == 0 && eq[5] == 0:
+		// both eq3 and eq6 can go lower, take the more severe one
+		lowerEQ3EQ6 = math.Max(lowerScore(eq, 5), lowerScore(eq, 2))
+	case eq[2] == 1 && eq[5] == 0:
+		lowerEQ3EQ6 = lowerScore(eq, 5)
+	default:
+		lowerEQ3EQ6 = lowerScore(eq, 2)
+	}
+	lowerEQ4 := lowerScore(eq, 3)
+	lowerEQ5 := lowerScore(eq, 4)
+
+	// severity distances of the vector from the highest severity vector of the macro vector
+	max := v.maxVector(eq, groups)
+	d := func(metrics ...string) float64 {
+		var sum float64
+		for _, metric := range metrics {
+			sum += v.distance(max, metric, groups)
+		}
+		return sum
+	}
+	const step = 0.1
+	intervals := []struct {
+		available, current, maxSeverity float64
+	}{
+		{value - lowerEQ1, d("AV", "PR", "UI"), maxSeverityEQ1[eq[0]] * step},
+		{value - lowerEQ2, d("AC", "AT"), maxSeverityEQ2[eq[1]] * step},
+		{value - lowerEQ3EQ6, d("VC", "VI", "VA", "CR", "IR", "AR"), maxSeverityEQ3EQ6[eq[2]][eq[5]] * step},
+		{value - lowerEQ4, d("SC", "SI", "SA"), maxSeverityEQ4[eq[3]] * step},
+		{value - lowerEQ5, 0, 1}, // all vectors of EQ5 class have the same severity
+	}
+
+	// move the score towards the lower macro vectors proportionally to the distances, on average
+	var n int
+	var distance float64
+	for _, i := range intervals {
+		if math.IsNaN(i.available) {
+			continue
+		}
+		n++
+		distance += i.available * i.current / i.maxSeverity
+	}
+	if n != 0 {
+		value -= distance / float64(n)
+	}
+
+	return math.Min(math.Max(value, 0), 10)
+}
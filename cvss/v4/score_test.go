@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v4
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScores(t *testing.T) {
+	tests := []struct {
+		vector                                         string
+		macroVector                                    string
+		base, threatScore, environmentalScore, overall float64
+	}{
+		// examples validated at https://www.first.org/cvss/calculator/4.0
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "000200", 9.3, 9.3, 9.3, 9.3},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", "000100", 10, 10, 10, 10},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N", "001200", 8.7, 8.7, 8.7, 8.7},
+		{"CVSS:4.0/AV:L/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "100200", 8.5, 8.5, 8.5, 8.5},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:A/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N", "102201", 5.1, 5.1, 5.1, 5.1},
+		// threat
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:P", "000210", 9.3, 8.9, 9.3, 8.9},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:U", "000220", 9.3, 8.1, 9.3, 8.1},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:X", "000200", 9.3, 9.3, 9.3, 9.3},
+		// environmental
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/CR:L/IR:L/AR:L", "000201", 9.3, 9.3, 8.9, 8.9},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MSI:S", "000000", 9.3, 9.3, 10, 10},
+		{"CVSS:4.0/AV:P/AC:H/AT:P/PR:H/UI:A/VC:L/VI:L/VA:L/SC:L/SI:L/SA:L/E:U/CR:L/IR:L/AR:L/MAV:N/MPR:N", "112221", 1, 0.1, 2.1, 0.5},
+		// no impact
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N", "002201", 0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.vector, func(t *testing.T) {
+			v := NewVector()
+			if err := v.Parse(test.vector); err != nil {
+				t.Fatal(err)
+			}
+			if mv := v.MacroVector(); mv != test.macroVector {
+				t.Errorf("macro vector expected to be %s, got %s", test.macroVector, mv)
+			}
+			if s := v.BaseScore(); s != test.base {
+				t.Errorf("base score expected to be %.1f, got %.1f", test.base, s)
+			}
+			if s := v.ThreatScore(); s != test.threatScore {
+				t.Errorf("threat score expected to be %.1f, got %.1f", test.threatScore, s)
+			}
+			if s := v.EnvironmentalScore(); s != test.environmentalScore {
+				t.Errorf("environmental score expected to be %.1f, got %.1f", test.environmentalScore, s)
+			}
+			if s := v.Score(); s != test.overall {
+				t.Errorf("score expected to be %.1f, got %.1f", test.overall, s)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	// every macro vector has a score, eq3 = 2 implies eq6 = 1
+	n := 0
+	for eq1 := 0; eq1 < 3; eq1++ {
+		for eq2 := 0; eq2 < 2; eq2++ {
+			for eq3 := 0; eq3 < 3; eq3++ {
+				for eq4 := 0; eq4 < 3; eq4++ {
+					for eq5 := 0; eq5 < 3; eq5++ {
+						for eq6 := 0; eq6 < 2; eq6++ {
+							if eq3 == 2 && eq6 == 0 {
+								continue
+							}
+							key := macroKey([6]int{eq1, eq2, eq3, eq4, eq5, eq6})
+							if _, ok := lookup[key]; !ok {
+								t.Errorf("no score for macro vector %s", key)
+							}
+							n++
+						}
+					}
+				}
+			}
+		}
+	}
+	if n != len(lookup) {
+		t.Errorf("expected %d macro vectors, got %d", n, len(lookup))
+	}
+}
+
+func TestBaseScoreRaw(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:A/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N"); err != nil {
+		t.Fatal(err)
+	}
+	raw, base := v.BaseScoreRaw(), v.BaseScore()
+	if raw == base || math.Abs(raw-base) > 0.05 {
+		t.Errorf("expected raw score rounding to %.1f, got %v", base, raw)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v4
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+const (
+	version = "4.0"
+	prefix  = "CVSS:" + version + "/"
+)
+
+// CVSS v4 doesn't weight metrics, scores come from the macro vector lookup table, see score.go.
+// The weights below are severity levels of metric values, they measure the distance of the vector
+// from the highest severity vector of its macro vector; values outside of equivalence classes weigh 0.
+var (
+	weights      map[string]map[string]float64 // main weights, filled with the ones below
+	metricGroups map[string]common.MetricGroups
+
+	baseMetricsWeights = map[string]map[string]float64{
+		"AV": { // Attack Vector
+			"N": 0.0, // Network
+			"A": 0.1, // Adjacent
+			"L": 0.2, // Local
+			"P": 0.3, // Physical
+		},
+		"AC": { // Attack Complexity
+			"L": 0.0, // Low
+			"H": 0.1, // High
+		},
+		"AT": { // Attack Requirements
+			"N": 0.0, // None
+			"P": 0.1, // Present
+		},
+		"PR": { // Privileges Required
+			"N": 0.0, // None
+			"L": 0.1, // Low
+			"H": 0.2, // High
+		},
+		"UI": { // User Interaction
+			"N": 0.0, // None
+			"P": 0.1, // Passive
+			"A": 0.2, // Active
+		},
+		"VC": { // Vulnerable System Confidentiality
+			"H": 0.0, // High
+			"L": 0.1, // Low
+			"N": 0.2, // None
+		},
+		"VI": { // Vulnerable System Integrity
+			"H": 0.0, // High
+			"L": 0.1, // Low
+			"N": 0.2, // None
+		},
+		"VA": { // Vulnerable System Availability
+			"H": 0.0, // High
+			"L": 0.1, // Low
+			"N": 0.2, // None
+		},
+		"SC": { // Subsequent System Confidentiality
+			"H": 0.1, // High
+			"L": 0.2, // Low
+			"N": 0.3, // None
+		},
+		"SI": { // Subsequent System Integrity
+			"H": 0.1, // High
+			"L": 0.2, // Low
+			"N": 0.3, // None
+		},
+		"SA": { // Subsequent System Availability
+			"H": 0.1, // High
+			"L": 0.2, // Low
+			"N": 0.3, // None
+		},
+	}
+
+	threatMetricsWeights = map[string]map[string]float64{
+		"E": { // Exploit Maturity
+			"X": 0.0, // Not Defined, same as Attacked
+			"A": 0.0, // Attacked
+			"P": 0.1, // POC
+			"U": 0.2, // Unreported
+		},
+	}
+
+	environmentalMetricsWeights = map[string]map[string]float64{
+		"CR": { // Confidentiality Requirement
+			"X": 0.0, // Not Defined, same as High
+			"H": 0.0, // High
+			"M": 0.1, // Medium
+			"L": 0.2, // Low
+		},
+		"IR": { // Integrity Requirement
+			"X": 0.0, // Not Defined, same as High
+			"H": 0.0, // High
+			"M": 0.1, // Medium
+			"L": 0.2, // Low
+		},
+		"AR": { // Availability Requirement
+			"X": 0.0, // Not Defined, same as High
+			"H": 0.0, // High
+			"M": 0.1, // Medium
+			"L": 0.2, // Low
+		},
+		// + the modified ones from base vector, see init function below
+	}
+
+	// safety values of modified subsequent system integrity and availability
+	safetyWeights = map[string]float64{
+		"S": 0.0, // Safety
+	}
+
+	// supplemental metrics don't affect the score
+	supplementalMetrics = map[string][]string{
+		"S":  {"X", "N", "P"},                         // Safety
+		"AU": {"X", "N", "Y"},                         // Automatable
+		"R":  {"X", "A", "U", "I"},                    // Recovery
+		"V":  {"X", "D", "C"},                         // Value Density
+		"RE": {"X", "L", "M", "H"},                    // Vulnerability Response Effort
+		"U":  {"X", "Clear", "Green", "Amber", "Red"}, // Provider Urgency
+	}
+)
+
+func init() {
+	// create weights
+	weights = make(map[string]map[string]float64)
+	metricGroups = make(map[string]common.MetricGroups)
+	for metric, values := range baseMetricsWeights {
+		weights[metric] = values
+		metricGroups[metric] = common.BaseGroup
+		metricGroups["M"+metric] = common.EnvironmentalGroup
+		modified := map[string]float64{"X": 0.0} // Not Defined, same as base metric
+		for value, w := range values {
+			modified[value] = w
+		}
+		if metric == "SI" || metric == "SA" {
+			for value, w := range safetyWeights {
+				modified[value] = w
+			}
+		}
+		weights["M"+metric] = modified // environmental
+	}
+	for metric, values := range threatMetricsWeights {
+		weights[metric] = values
+		metricGroups[metric] = common.TemporalGroup
+	}
+	for metric, values := range environmentalMetricsWeights {
+		weights[metric] = values
+		metricGroups[metric] = common.EnvironmentalGroup
+	}
+	for metric, values := range supplementalMetrics {
+		metricGroups[metric] = common.SupplementalGroup
+		weights[metric] = make(map[string]float64, len(values))
+		for _, value := range values {
+			weights[metric][value] = 0.0
+		}
+	}
+}
+
+type Vector struct {
+	common.WeightsMetrics
+}
+
+func NewVector() Vector {
+	return Vector{WeightsMetrics: common.WeightsMetrics{Metrics: make(common.Metrics), Weights: weights}}
+}
+
+// baseMetrics lists base metrics in the order of the specification
+var baseMetrics = []string{"AV", "AC", "AT", "PR", "UI", "VC", "VI", "VA", "SC", "SI", "SA"}
+
+// canonicalOrder lists all metrics in the order of the specification
+var canonicalOrder = []string{
+	"AV", "AC", "AT", "PR", "UI", "VC", "VI", "VA", "SC", "SI", "SA", // base
+	"E",                                                                                           // threat
+	"CR", "IR", "AR", "MAV", "MAC", "MAT", "MPR", "MUI", "MVC", "MVI", "MVA", "MSC", "MSI", "MSA", // environmental
+	"S", "AU", "R", "V", "RE", "U", // supplemental
+}
+
+func (v Vector) Validate() error {
+	for _, metric := range baseMetrics {
+		if _, err := v.Get(metric); err != nil {
+			return fmt.Errorf("base vector: metric %q not defined", metric)
+		}
+	}
+	return nil
+}
+
+// MetricGroups returns the metrics of every metric group in the order of the specification;
+// the table is built on every call, so it can be modified freely
+func MetricGroups() map[common.MetricGroups][]string {
+	return common.GroupMetrics(metricGroups, canonicalOrder)
+}
+
+// Completeness returns the metric groups the vector has metrics of, metrics set to X (not defined) don't count;
+// threat metrics make common.TemporalGroup
+func (v Vector) Completeness() common.MetricGroups {
+	return v.Metrics.Completeness(metricGroups, "X")
+}
+
+// Freeze returns a read-only copy of the vector, safe for concurrent use: Set and Parse of the copy fail
+// with common.ErrFrozen, see common.WeightsMetrics.Freeze
+func (v Vector) Freeze() Vector {
+	v.WeightsMetrics = v.WeightsMetrics.Freeze()
+	return v
+}
+
+// BaseOnly returns a copy of the vector limited to the base metrics, without threat, environmental and supplemental ones
+func (v Vector) BaseOnly() Vector {
+	base := v
+	base.Metrics = v.Metrics.Only(baseMetrics...)
+	return base
+}
+
+// Normalize returns a copy of the vector without threat, environmental and supplemental metrics set to X
+// (not defined), which don't affect the scores, so equivalent vectors normalize to the same CanonicalString
+func (v Vector) Normalize() Vector {
+	n := v
+	n.Metrics = v.Metrics.Defined(metricGroups, "X")
+	return n
+}
+
+// CanonicalString returns the vector string with metrics in the order of the specification.
+func (v Vector) CanonicalString() string {
+	return prefix + v.Metrics.CanonicalString(canonicalOrder)
+}
+
+// Override Parse and String to remove/add prefix
+
+// prefixRe matches the version prefix of vector string
+var prefixRe = regexp.MustCompile(`^[Cc][Vv][Ss][Ss]:([0-9]+\.[0-9]+)/`)
+
+func (v Vector) Parse(str string) error {
+	// remove prefix if exists
+	if m := prefixRe.FindStringSubmatch(str); m != nil {
+		if m[1] != version {
+			return fmt.Errorf("unsupported CVSS version %q", m[1])
+		}
+		str = str[len(m[0]):]
+	}
+	return v.WeightsMetrics.Parse(str)
+}
+
+// canonicalIndex maps metrics to their position in canonicalOrder
+var canonicalIndex = make(map[string]int, len(canonicalOrder))
+
+func init() {
+	for i, metric := range canonicalOrder {
+		canonicalIndex[metric] = i
+	}
+}
+
+// ParseStrict is like Parse, but enforces the vector string requirements of the specification Parse is lenient about:
+// the vector must start with the CVSS:4.0/ prefix and list metrics in the order of the specification
+func (v Vector) ParseStrict(str string) error {
+	if !strings.HasPrefix(str, prefix) {
+		return fmt.Errorf("vector %q doesn't start with %q", str, prefix)
+	}
+	last := -1
+	for _, metric := range common.MetricNames(str[len(prefix):]) {
+		i, ok := canonicalIndex[metric]
+		if !ok {
+			continue // unknown metrics are reported by Parse
+		}
+		if i == last {
+			return fmt.Errorf("metric %q repeated", metric)
+		}
+		if i < last {
+			return fmt.Errorf("metric %q out of order, expected after %q", metric, canonicalOrder[last])
+		}
+		last = i
+	}
+	return v.Parse(str)
+}
+
+// ParseWithPolicy is like Parse, but metrics and values not defined for CVSS v4 are handled as per policy,
+// e.g. to accept vendor extensions; nil policy is as strict as Parse, see common.Policy
+func (v Vector) ParseWithPolicy(str string, policy common.Policy) error {
+	known, accepted, err := common.FilterUnknown(str, v.Weights, policy)
+	if err != nil {
+		return err
+	}
+	if err = v.Parse(known); err != nil {
+		return err
+	}
+	return v.SetVerbatim(accepted)
+}
+
+// String returns the vector string with metrics in the order of the specification,
+// unlike previous versions, CVSS v4 requires the order to be preserved.
+func (v Vector) String() string {
+	return v.CanonicalString()
+}
+
+// EnumerateBase calls fn for every valid base vector, in deterministic order (see common.WeightsMetrics.Enumerate).
+// The same vector is reused for every call, so fn must not retain it; enumeration stops if fn returns false.
+func EnumerateBase(fn func(Vector) bool) {
+	v := NewVector()
+	if err := v.Enumerate(baseMetrics, func() bool { return fn(v) }); err != nil {
+		panic(err) // base metrics are always defined
+	}
+}
+
+// BaseScoreRange returns the minimum and maximum base score over all completions of the base metrics the vector lacks,
+// bounding the score of incomplete vectors; threat, environmental and supplemental metrics are disregarded. The vector isn't modified.
+func (v Vector) BaseScoreRange() (min, max float64) {
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	min, max, err := base.ScoreRange(baseMetrics, base.BaseScore)
+	if err != nil {
+		panic(err) // base metrics are always defined
+	}
+	return min, max
+}
+
+// BaseSensitivity returns the contribution of each base metric to the base score, in the order of the specification,
+// to explain the score: the base score less the mean base score over the values of the metric, see
+// common.WeightsMetrics.Sensitivity. The vector isn't modified; vectors lacking base metrics are an error.
+func (v Vector) BaseSensitivity() ([]common.MetricDelta, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	base := v.BaseOnly() // a copy, so the original metrics aren't touched
+	return base.Sensitivity(baseMetrics, base.BaseScore)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v4
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const str = "CVSS:4.0/AV:N/AC:L/AT:P/PR:L/UI:A/VC:H/VI:L/VA:N/SC:L/SI:N/SA:H/E:P/CR:M/MAV:L/MSI:S/AU:Y/U:Amber"
+	v := NewVector()
+	if err := v.Parse(str); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"AV": "N", "AC": "L", "AT": "P", "PR": "L", "UI": "A", "VC": "H", "VI": "L", "VA": "N", "SC": "L", "SI": "N", "SA": "H",
+		"E":  "P",
+		"CR": "M", "MAV": "L", "MSI": "S",
+		"AU": "Y", "U": "Amber",
+	}
+	for metric, expected := range tests {
+		if actual, err := v.Get(metric); err != nil {
+			t.Errorf("%q: %v", metric, err)
+		} else if actual != expected {
+			t.Errorf("%q: expected %q, got %q", metric, expected, actual)
+		}
+	}
+	if s := v.String(); s != str {
+		t.Errorf("expected %q, got %q", str, s)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"CVSS:3.1/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", // wrong version
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:S/SA:N", // safety is only for MSI
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:F",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MSC:S",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:Blue",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/C:H",
+	}
+	for _, str := range tests {
+		if err := NewVector().Parse(str); err == nil {
+			t.Errorf("%q: expected an error", str)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := NewVector()
+	if err := v.Parse("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Validate(); err == nil {
+		t.Error("vector without SA expected to be invalid")
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	const str = "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:A/MSI:S/AU:Y"
+	if err := NewVector().ParseStrict(str); err != nil {
+		t.Fatal(err)
+	}
+	tests := []string{
+		"AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",          // no prefix
+		"cvss:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", // prefix in lower case
+		"CVSS:4.0/AC:L/AV:N/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", // base metrics out of order
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/AU:Y/E:A",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/SA:N",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:Blue",
+	}
+	for _, str := range tests {
+		if err := NewVector().ParseStrict(str); err == nil {
+			t.Errorf("%q: expected an error", str)
+		}
+	}
+	// lenient Parse accepts what ParseStrict rejects
+	if err := NewVector().Parse(tests[2]); err != nil {
+		t.Errorf("%q: %v", tests[2], err)
+	}
+}
@@ -15,11 +15,17 @@
 package cvss
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
 	"github.com/facebookincubator/nvdtools/cvss/v2"
 	"github.com/facebookincubator/nvdtools/cvss/v3"
+	"github.com/facebookincubator/nvdtools/cvss/v4"
 )
 
-// Vector provides an interface for dealing with CVSS v2 and v3 vectors
+// Vector provides an interface for dealing with CVSS v2, v3 and v4 vectors
 type Vector interface {
 	// Get returns a value associated with given metric or an error if it can't be resolved
 	Get(metric string) (string, error)
@@ -35,6 +41,27 @@ type Vector interface {
 	Score() float64
 }
 
+// NewVector returns an empty vector of the given CVSS version ("2", "2.0", "3", "3.0", "3.1", "4" or "4.0"),
+// ready to be filled in using Set or Parse; CVSS v3 vectors take the minor version of the parsed input, if declared
+func NewVector(version string) (Vector, error) {
+	switch version {
+	case "2", "2.0":
+		return NewVectorV2(), nil
+	case "3", "3.0":
+		return NewVectorV3(), nil
+	case "3.1":
+		v := v3.NewVector()
+		if err := v.SetVersion(version); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case "4", "4.0":
+		return NewVectorV4(), nil
+	default:
+		return nil, fmt.Errorf("unsupported CVSS version %q", version)
+	}
+}
+
 func NewVectorV2() Vector {
 	return v2.NewVector()
 }
@@ -43,6 +70,226 @@ func NewVectorV3() Vector {
 	return v3.NewVector()
 }
 
+func NewVectorV4() Vector {
+	return v4.NewVector()
+}
+
+// BaseOnly returns a copy of the vector limited to the base metrics, so the score of the result is the base score;
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func BaseOnly(v Vector) (Vector, error) {
+	switch v := v.(type) {
+	case v2.Vector:
+		return v.BaseOnly(), nil
+	case v3.Vector:
+		return v.BaseOnly(), nil
+	case v4.Vector:
+		return v.BaseOnly(), nil
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+}
+
+// Freeze returns a read-only copy of the vector, safe for concurrent reads: Set and Parse of the copy fail
+// with common.ErrFrozen; v must be a vector of one of the supported CVSS versions, see NewVector
+func Freeze(v Vector) (Vector, error) {
+	switch v := v.(type) {
+	case v2.Vector:
+		return v.Freeze(), nil
+	case v3.Vector:
+		return v.Freeze(), nil
+	case v4.Vector:
+		return v.Freeze(), nil
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", v)
+	}
+}
+
+// Completeness returns the metric groups the vector has metrics of (see e.g. v3.Vector.Completeness);
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func Completeness(v Vector) (common.MetricGroups, error) {
+	if c, ok := v.(interface{ Completeness() common.MetricGroups }); ok {
+		return c.Completeness(), nil
+	}
+	return 0, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// BaseScoreRange returns the minimum and maximum base score the vector can have once the base metrics it lacks
+// are known (see e.g. v3.Vector.BaseScoreRange); v must be a vector of one of the supported CVSS versions, see NewVector
+func BaseScoreRange(v Vector) (min, max float64, err error) {
+	if r, ok := v.(interface{ BaseScoreRange() (float64, float64) }); ok {
+		min, max = r.BaseScoreRange()
+		return min, max, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// BaseSensitivity returns the contribution of each base metric of the vector to its base score
+// (see e.g. v3.Vector.BaseSensitivity); v must be a vector of one of the supported CVSS versions, see NewVector
+func BaseSensitivity(v Vector) ([]common.MetricDelta, error) {
+	if s, ok := v.(interface {
+		BaseSensitivity() ([]common.MetricDelta, error)
+	}); ok {
+		return s.BaseSensitivity()
+	}
+	return nil, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// BaseScoreRaw returns the base score of the vector at full precision, before the final rounding (see e.g.
+// v3.Vector.BaseScoreRaw), for statistics only: it isn't the score of the specification.
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func BaseScoreRaw(v Vector) (float64, error) {
+	if r, ok := v.(interface{ BaseScoreRaw() float64 }); ok {
+		return r.BaseScoreRaw(), nil
+	}
+	return 0, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// ScoreStrict returns the score of the vector, like Score, but scores out of the CVSS range of 0.0 to 10.0 are
+// an error (see common.ErrScoreOutOfRange) rather than clamped, to detect broken custom weight tables.
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func ScoreStrict(v Vector) (float64, error) {
+	if s, ok := v.(interface{ ScoreStrict() (float64, error) }); ok {
+		return s.ScoreStrict()
+	}
+	return 0, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// WeightTable returns a copy of the weights the library assigns to metric values of the given CVSS version
+// (see NewVector), mapping metrics to the weights of their values; weights depending on other metrics
+// (e.g. CVSS v3 PR with changed scope) are adjusted when scoring and aren't in the table. CVSS v4 doesn't weight metrics,
+// its table holds severity levels of the values used to interpolate the scores of macro vectors, see package v4.
+func WeightTable(version string) (map[string]map[string]float64, error) {
+	v, err := NewVector(version)
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := v.(interface {
+		WeightTable() map[string]map[string]float64
+	}); ok {
+		return w.WeightTable(), nil
+	}
+	return nil, fmt.Errorf("unsupported vector type %T", v)
+}
+
+// MetricGroups returns the metrics of every metric group of the given CVSS version (see NewVector) in the order
+// of the specification, e.g. for CVSS v3.1 the temporal group is E, RL and RC; CVSS v4 threat metrics make
+// common.TemporalGroup. The table is a copy, modifying it doesn't affect the vectors.
+func MetricGroups(version string) (map[common.MetricGroups][]string, error) {
+	if _, err := NewVector(version); err != nil {
+		return nil, err
+	}
+	switch version[:1] {
+	case "2":
+		return v2.MetricGroups(), nil
+	case "3":
+		return v3.MetricGroups(), nil
+	default:
+		return v4.MetricGroups(), nil
+	}
+}
+
+// EnumerateBase calls fn for every valid base vector of the given CVSS version (see NewVector), in deterministic order.
+// The same vector is reused for every call, so fn must not retain it; enumeration stops if fn returns false.
+func EnumerateBase(version string, fn func(Vector) bool) error {
+	if _, err := NewVector(version); err != nil {
+		return err
+	}
+	switch version[:1] {
+	case "2":
+		v2.EnumerateBase(func(v v2.Vector) bool { return fn(v) })
+	case "3":
+		v3.EnumerateBase(func(v v3.Vector) bool { return fn(v) })
+	case "4":
+		v4.EnumerateBase(func(v v4.Vector) bool { return fn(v) })
+	}
+	return nil
+}
+
+// versionRe matches the version prefix of CVSS v3 and later vectors
+var versionRe = regexp.MustCompile(`^CVSS:([0-9]+)\.[0-9]+/`)
+
+// ScoreAndSeverity parses the vector of any supported CVSS version and returns its score and severity.
+// The version is detected by the prefix of the vector (e.g. CVSS:3.0/), vectors without one are CVSS v2;
+// CVSS v3.0 and v3.1 vectors are scored with the equations of their minor version, see v3.Vector.Score.
+// Severity of v2 scores follows NVD v2 qualitative rating, see SeverityFromV2Score.
+func ScoreAndSeverity(vector string) (float64, Severity, error) {
+	version := "2"
+	if m := versionRe.FindStringSubmatch(vector); m != nil {
+		version = m[1]
+	}
+	v, err := NewVector(version)
+	if err != nil {
+		return 0, SeverityUnknown, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	if err = v.Parse(vector); err != nil {
+		return 0, SeverityUnknown, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	if err = v.Validate(); err != nil {
+		return 0, SeverityUnknown, fmt.Errorf("vector %q: %v", vector, err)
+	}
+	score := v.Score()
+	if version == "2" {
+		return score, SeverityFromV2Score(score), nil
+	}
+	return score, SeverityFromScore(score), nil
+}
+
+// CompatibleVersions returns the CVSS versions ("2.0", "3.0" or "3.1", "4.0") whose parser accepts the vector,
+// for vectors of unknown provenance which may be ambiguous; unlike ScoreAndSeverity, the prefix isn't relied on.
+// Completeness isn't checked, so fragments like AV:N/AC:L are compatible with every version; nil if none accepts it.
+func CompatibleVersions(vector string) []string {
+	var versions []string
+	if err := v2.NewVector().Parse(vector); err == nil {
+		versions = append(versions, "2.0")
+	}
+	if v := v3.NewVector(); v.Parse(vector) == nil {
+		versions = append(versions, v.Version())
+	}
+	if err := v4.NewVector().Parse(vector); err == nil {
+		versions = append(versions, "4.0")
+	}
+	return versions
+}
+
+// vectorCandidateRe matches substrings shaped like CVSS vectors, of any version
+var vectorCandidateRe = regexp.MustCompile(`\b(?:CVSS:[0-9]+\.[0-9]+/)?[A-Za-z]+:[A-Za-z]+(?:/[A-Za-z]+:[A-Za-z]+)+`)
+
+// ExtractVectors returns CVSS v2, v3 and v4 vectors mentioned in the text, e.g. in the prose of advisories,
+// in the order of appearance, without duplicates. Only the substrings which parse as complete vectors
+// are returned (see ScoreAndSeverity), so colon separated text which merely looks like a vector is disregarded.
+func ExtractVectors(text string) []string {
+	var vectors []string
+	seen := map[string]bool{}
+	for _, candidate := range vectorCandidateRe.FindAllString(text, -1) {
+		if seen[candidate] {
+			continue
+		}
+		if _, _, err := ScoreAndSeverity(candidate); err == nil {
+			seen[candidate] = true
+			vectors = append(vectors, candidate)
+		}
+	}
+	return vectors
+}
+
+// ParseWithSource parses str into the vector and attributes the error (if any) to the source of str,
+// e.g. "line 42: unable to set metric ..."; the original error is available via errors.As or errors.Unwrap
+func ParseWithSource(v Vector, str, source string) error {
+	return common.WithSource(v.Parse(str), source)
+}
+
+// ParseWithPolicy parses str into the vector handling metrics and values it doesn't define as per policy,
+// e.g. to accept vendor extensions (see common.Policy); nil policy is as strict as Parse.
+// v must be a vector of one of the supported CVSS versions, see NewVector
+func ParseWithPolicy(v Vector, str string, policy common.Policy) error {
+	if p, ok := v.(interface {
+		ParseWithPolicy(string, common.Policy) error
+	}); ok {
+		return p.ParseWithPolicy(str, policy)
+	}
+	return fmt.Errorf("unsupported vector type %T", v)
+}
+
 // Severity represents scores severity
 type Severity int
 
@@ -54,6 +301,9 @@ const (
 	SeverityCritical
 )
 
+// SeverityUnknown is the severity of findings which weren't scored
+const SeverityUnknown Severity = -1
+
 func (s Severity) String() string {
 	switch s {
 	case SeverityNone:
@@ -66,11 +316,23 @@ func (s Severity) String() string {
 		return "High"
 	case SeverityCritical:
 		return "Critical"
+	case SeverityUnknown:
+		return "Unknown"
 	default:
 		panic("undefined severity")
 	}
 }
 
+// ParseSeverity returns the severity by its name, case-insensitive
+func ParseSeverity(name string) (Severity, error) {
+	for s := SeverityUnknown; s <= SeverityCritical; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity %q", name)
+}
+
 // SeverityFromScore will return the severity assigned to given score
 func SeverityFromScore(score float64) Severity {
 	if score <= 0 {
@@ -87,3 +349,32 @@ func SeverityFromScore(score float64) Severity {
 	}
 	return SeverityCritical
 }
+
+// MinScore returns the lowest score of the severity band, e.g. 7.0 for SeverityHigh; it's 0 for SeverityUnknown
+func (s Severity) MinScore() float64 {
+	switch s {
+	case SeverityLow:
+		return 0.1
+	case SeverityMedium:
+		return 4.0
+	case SeverityHigh:
+		return 7.0
+	case SeverityCritical:
+		return 9.0
+	default:
+		return 0.0
+	}
+}
+
+// SeverityFromV2Score will return the severity assigned to given CVSS v2 score as per NVD
+// qualitative rating for v2, which has neither none nor critical severity: 0.0 is low, 10.0 is high
+func SeverityFromV2Score(score float64) Severity {
+	switch s := SeverityFromScore(score); s {
+	case SeverityNone:
+		return SeverityLow
+	case SeverityCritical:
+		return SeverityHigh
+	default:
+		return s
+	}
+}
@@ -0,0 +1,485 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss/common"
+)
+
+func TestNewVector(t *testing.T) {
+	cases := map[string]string{
+		"2":   "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"2.0": "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"3":   "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"3.0": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"4":   "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		"4.0": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+	}
+	for version, str := range cases {
+		v, err := NewVector(version)
+		if err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		if err := v.Validate(); err == nil {
+			t.Errorf("version %s: new vector expected to be empty", version)
+		}
+		if err := v.Set("AV", "X"); err == nil {
+			t.Errorf("version %s: vector expected to be backed by weights", version)
+		}
+		if err := v.Parse(str); err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		if err := v.Validate(); err != nil {
+			t.Errorf("version %s: %v", version, err)
+		}
+	}
+	if _, err := NewVector("1.0"); err == nil {
+		t.Error("expected an error for unsupported version")
+	}
+}
+
+func TestScoreAndSeverity(t *testing.T) {
+	cases := []struct {
+		vector   string
+		score    float64
+		severity Severity
+	}{
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0, SeverityHigh}, // v2 has no critical severity
+		{"(AV:N/AC:L/Au:N/C:P/I:N/A:N)", 5.0, SeverityMedium},
+		{"AV:N/AC:L/Au:N/C:N/I:N/A:N", 0.0, SeverityLow}, // nor none
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, SeverityCritical},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", 7.5, SeverityHigh},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0.0, SeverityNone},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N", 8.7, SeverityHigh},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:A/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N", 5.1, SeverityMedium},
+	}
+	for _, c := range cases {
+		score, severity, err := ScoreAndSeverity(c.vector)
+		if err != nil {
+			t.Errorf("%s: %v", c.vector, err)
+		} else if score != c.score || severity != c.severity {
+			t.Errorf("%s: expected %.1f %v, got %.1f %v", c.vector, c.score, c.severity, score, severity)
+		}
+	}
+	for _, vector := range []string{
+		"CVSS:5.0/AV:N",
+		"CVSS:3.0/AV:N/AC:L", // incomplete
+		"CVSS:4.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", // v3 metrics
+		"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",          // v3 without prefix
+	} {
+		if _, severity, err := ScoreAndSeverity(vector); err == nil || severity != SeverityUnknown {
+			t.Errorf("%s: expected an error", vector)
+		}
+	}
+}
+
+func TestCompatibleVersions(t *testing.T) {
+	cases := []struct {
+		vector   string
+		versions []string
+	}{
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", []string{"2.0"}},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", []string{"3.1"}},
+		{"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", []string{"3.0"}},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N", []string{"4.0"}},
+		{"AV:N/AC:L", []string{"2.0", "3.0", "4.0"}}, // ambiguous
+		{"AV:N/AC:L/PR:N/UI:N", []string{"3.0", "4.0"}},
+		{"CVSS:5.0/AV:N", nil},
+		{"AV:N/AT:N/S:U", nil},
+	}
+	for _, c := range cases {
+		if versions := CompatibleVersions(c.vector); !reflect.DeepEqual(versions, c.versions) {
+			t.Errorf("%s: expected %v, got %v", c.vector, c.versions, versions)
+		}
+	}
+}
+
+func TestExtractVectors(t *testing.T) {
+	cases := []struct {
+		text    string
+		vectors []string
+	}{
+		{
+			"Remote attackers can execute code (CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H), NVD v2: (AV:N/AC:L/Au:N/C:P/I:P/A:P).",
+			[]string{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		},
+		{
+			"Scored CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N and again CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N",
+			[]string{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N"},
+		},
+		{"See host:port/key:value and AV:N/AC:L for details", nil},   // not vectors, incomplete vectors
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:Q", nil},        // invalid value
+		{"prefixAV:N/AC:L/Au:N/C:P/I:P/A:P is glued to a word", nil}, // not a standalone vector
+		{"", nil},
+	}
+	for _, c := range cases {
+		if vectors := ExtractVectors(c.text); !reflect.DeepEqual(vectors, c.vectors) {
+			t.Errorf("%q: expected %v, got %v", c.text, c.vectors, vectors)
+		}
+	}
+}
+
+func TestEnumerateBase(t *testing.T) {
+	cases := map[string]struct {
+		n           int
+		first, last string
+	}{
+		"2": {729, "AV:A/AC:H/Au:M/C:C/I:C/A:C", "AV:N/AC:M/Au:S/C:P/I:P/A:P"},
+		"3": {2592, "CVSS:3.0/AV:A/AC:H/PR:H/UI:N/S:C/C:H/I:H/A:H", "CVSS:3.0/AV:P/AC:L/PR:N/UI:R/S:U/C:N/I:N/A:N"},
+		"4": {104976, "CVSS:4.0/AV:A/AC:H/AT:N/PR:H/UI:A/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", "CVSS:4.0/AV:P/AC:L/AT:P/PR:N/UI:P/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N"},
+	}
+	for version, c := range cases {
+		n := 0
+		var first, last string
+		err := EnumerateBase(version, func(v Vector) bool {
+			if err := v.Validate(); err != nil {
+				t.Fatalf("version %s: %v", version, err)
+			}
+			if n == 0 {
+				first = canonical(v)
+			}
+			last = canonical(v)
+			n++
+			return true
+		})
+		if err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		if n != c.n || first != c.first || last != c.last {
+			t.Errorf("version %s: expected %d vectors from %s to %s, got %d from %s to %s", version, c.n, c.first, c.last, n, first, last)
+		}
+	}
+	if err := EnumerateBase("1.0", func(Vector) bool { return true }); err == nil {
+		t.Error("expected an error for unsupported version")
+	}
+}
+
+func TestBaseOnly(t *testing.T) {
+	cases := map[string]string{
+		"2": "AV:N/AC:L/Au:N/C:P/I:P/A:P/E:U/RL:OF/CDP:H/TD:L",
+		"3": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U/RL:O/MAV:P/CR:L",
+		"4": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:U/MAV:P/CR:L/S:P/U:Red",
+	}
+	for version, str := range cases {
+		v, _ := NewVector(version)
+		if err := v.Parse(str); err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		base, err := BaseOnly(v)
+		if err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		expected := v.(interface{ BaseScore() float64 }).BaseScore()
+		if base.Score() != expected || base.Score() == v.Score() {
+			t.Errorf("version %s: expected base vector to score %.1f, got %.1f (full vector %.1f)", version, expected, base.Score(), v.Score())
+		}
+		if _, err := base.Get("E"); err == nil {
+			t.Errorf("version %s: base vector %s has optional metrics", version, base)
+		}
+		if _, err := v.Get("E"); err != nil {
+			t.Errorf("version %s: original vector was modified", version)
+		}
+	}
+	if _, err := BaseOnly(nil); err == nil {
+		t.Error("expected an error for unsupported vector")
+	}
+}
+
+func TestCompleteness(t *testing.T) {
+	cases := []struct {
+		version, vector string
+		expected        string
+	}{
+		{"2", "AV:N/AC:L/Au:N/C:P/I:P/A:P", "Base"},
+		{"2", "AV:N/AC:L/Au:N/C:P/I:P/A:P/E:U/RL:OF/RC:ND", "Base + Temporal"},
+		{"2", "AV:N/AC:L/Au:N/C:P/I:P/A:P/E:ND/CDP:H", "Base + Environmental"},
+		{"3", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U/MAV:P", "Base + Temporal + Environmental"},
+		{"3", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:L", "Base + Environmental"},
+		{"4", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:X/U:Red", "Base + Supplemental"},
+		{"4", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:A/MSI:S", "Base + Temporal + Environmental"},
+	}
+	for _, c := range cases {
+		v, _ := NewVector(c.version)
+		if err := v.Parse(c.vector); err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		groups, err := Completeness(v)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if groups.String() != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.vector, c.expected, groups)
+		}
+	}
+	if _, err := Completeness(nil); err == nil {
+		t.Error("expected an error for unsupported vector")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	for version, str := range map[string]string{
+		"2": "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"3": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"4": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+	} {
+		v, _ := NewVector(version)
+		if err := v.Parse(str); err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		frozen, err := Freeze(v)
+		if err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		if err := frozen.Set("AV", "L"); err == nil {
+			t.Errorf("version %s: expected frozen vector to reject Set", version)
+		} else if _, ok := err.(common.ErrFrozen); !ok {
+			t.Errorf("version %s: expected common.ErrFrozen, got %v", version, err)
+		}
+		if err := frozen.Parse(str); err == nil {
+			t.Errorf("version %s: expected frozen vector to reject Parse", version)
+		}
+		score := v.Score()
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if frozen.Score() != score || frozen.String() != v.String() {
+					t.Errorf("version %s: frozen vector differs from the original", version)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	if _, err := Freeze(nil); err == nil {
+		t.Error("expected an error for unsupported vector")
+	}
+}
+
+func TestParseWithPolicy(t *testing.T) {
+	accept := func(metric, value string) common.Action {
+		if metric == "VX" {
+			return common.Accept
+		}
+		return common.Reject
+	}
+	cases := map[string]string{
+		"2": "(AV:N/AC:L/Au:N/C:P/I:P/A:P/VX:1)",
+		"3": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/VX:1",
+		"4": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/VX:1",
+	}
+	for version, str := range cases {
+		v, _ := NewVector(version)
+		if err := v.Parse(str); err == nil {
+			t.Errorf("version %s: expected Parse to reject unknown metric", version)
+		}
+		v, _ = NewVector(version)
+		if err := ParseWithPolicy(v, str, accept); err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+		if value, err := v.Get("VX"); err != nil || value != "1" {
+			t.Errorf("version %s: expected accepted metric to be kept, got %q, %v", version, value, err)
+		}
+		if err := v.Validate(); err != nil {
+			t.Errorf("version %s: %v", version, err)
+		}
+		strict, _ := NewVector(version)
+		if err := ParseWithPolicy(strict, str, nil); err == nil {
+			t.Errorf("version %s: expected nil policy to reject unknown metric", version)
+		}
+	}
+	if err := ParseWithPolicy(nil, "", nil); err == nil {
+		t.Error("expected an error for unsupported vector")
+	}
+}
+
+func TestBaseScoreRange(t *testing.T) {
+	cases := []struct {
+		version, vector string
+		min, max        float64
+	}{
+		{"2", "AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0, 10.0},
+		{"2", "AV:N/AC:L/Au:N/C:N/I:N", 0.0, 7.8},
+		{"3", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U", 9.8, 9.8},
+		{"3", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", 9.1, 9.8},
+		{"3", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/C:H/I:N/A:N", 7.5, 8.6},
+		{"3", "", 0.0, 10.0},
+		{"4", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N", 8.7, 8.7},
+	}
+	for _, c := range cases {
+		v, _ := NewVector(c.version)
+		if c.vector != "" {
+			if err := v.Parse(c.vector); err != nil {
+				t.Fatalf("%s: %v", c.vector, err)
+			}
+		}
+		str := v.String()
+		min, max, err := BaseScoreRange(v)
+		if err != nil {
+			t.Fatalf("%s: %v", c.vector, err)
+		}
+		if min != c.min || max != c.max {
+			t.Errorf("%s: expected range %.1f-%.1f, got %.1f-%.1f", c.vector, c.min, c.max, min, max)
+		}
+		if v.String() != str {
+			t.Errorf("%s: vector was modified: %s", c.vector, v)
+		}
+	}
+	if _, _, err := BaseScoreRange(nil); err == nil {
+		t.Error("expected an error for unsupported vector")
+	}
+}
+
+func canonical(v Vector) string {
+	return v.(interface{ CanonicalString() string }).CanonicalString()
+}
+
+func TestWeightTable(t *testing.T) {
+	table, err := WeightTable("3.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := table["AV"]["N"]; w != 0.85 {
+		t.Errorf("expected weight 0.85 of AV:N, got %v", w)
+	}
+	if w := table["PR"]["L"]; w != 0.62 {
+		t.Errorf("expected weight 0.62 of PR:L, got %v", w)
+	}
+	table["AV"]["N"] = 0
+	if table, _ = WeightTable("3.0"); table["AV"]["N"] != 0.85 {
+		t.Error("the table returned isn't a copy")
+	}
+	if table, err = WeightTable("2"); err != nil || table["Au"]["N"] != 0.704 {
+		t.Errorf("unexpected table of CVSS v2 (%v): %v", err, table["Au"])
+	}
+	if _, err = WeightTable("4"); err != nil {
+		t.Error(err)
+	}
+	if _, err = WeightTable("5"); err == nil {
+		t.Error("unsupported version is expected to be an error")
+	}
+}
+
+func TestMetricGroups(t *testing.T) {
+	for _, version := range []string{"2", "3.0", "3.1", "4"} {
+		groups, err := MetricGroups(version)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		table, err := WeightTable(version)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		// every metric belongs to exactly one group
+		seen := map[string]bool{}
+		for group, metrics := range groups {
+			for _, metric := range metrics {
+				if seen[metric] {
+					t.Errorf("%s: metric %s is in several groups", version, metric)
+				}
+				seen[metric] = true
+				if _, ok := table[metric]; !ok {
+					t.Errorf("%s: %s metric %s has no weights", version, group, metric)
+				}
+			}
+		}
+		if len(seen) != len(table) {
+			t.Errorf("%s: expected %d metrics in groups, got %d", version, len(table), len(seen))
+		}
+	}
+	groups, _ := MetricGroups("3.1")
+	if temporal := groups[common.TemporalGroup]; len(temporal) != 3 || temporal[0] != "E" || temporal[1] != "RL" || temporal[2] != "RC" {
+		t.Errorf("unexpected temporal metrics of CVSS v3.1: %v", temporal)
+	}
+	groups[common.BaseGroup] = nil
+	if groups, _ = MetricGroups("3.1"); len(groups[common.BaseGroup]) != 8 {
+		t.Error("the table returned isn't a copy")
+	}
+	if groups, _ = MetricGroups("4"); len(groups[common.SupplementalGroup]) != 6 {
+		t.Errorf("unexpected supplemental metrics of CVSS v4: %v", groups[common.SupplementalGroup])
+	}
+	if _, err := MetricGroups("5"); err == nil {
+		t.Error("unsupported version is expected to be an error")
+	}
+}
+
+func TestBaseScoreRaw(t *testing.T) {
+	for _, vector := range []string{
+		"AV:N/AC:M/Au:S/C:P/I:N/A:N",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:A/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N",
+	} {
+		versions := CompatibleVersions(vector)
+		v, err := NewVector(versions[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = v.Parse(vector); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := BaseScoreRaw(v)
+		if err != nil {
+			t.Errorf("%s: %v", vector, err)
+		} else if score := v.Score(); raw == score || math.Abs(raw-score) >= 0.1 {
+			t.Errorf("%s: expected full precision score close to %.1f, got %v", vector, score, raw)
+		}
+	}
+}
+
+func TestScoreStrict(t *testing.T) {
+	for _, vector := range []string{
+		"AV:N/AC:M/Au:S/C:P/I:N/A:N",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:A/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N",
+	} {
+		v, err := NewVector(CompatibleVersions(vector)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = v.Parse(vector); err != nil {
+			t.Fatal(err)
+		}
+		if score, err := ScoreStrict(v); err != nil || score != v.Score() {
+			t.Errorf("%s: expected %.1f, got %v, %v", vector, v.Score(), score, err)
+		}
+	}
+}
+
+func TestBaseSensitivity(t *testing.T) {
+	for _, vector := range []string{
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N",
+	} {
+		v, err := NewVector(CompatibleVersions(vector)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = v.Parse(vector); err != nil {
+			t.Fatal(err)
+		}
+		deltas, err := BaseSensitivity(v)
+		if err != nil {
+			t.Errorf("%s: %v", vector, err)
+		} else if av := deltas[0]; av.Metric != "AV" || av.Value != "N" || av.Delta <= 0 {
+			t.Errorf("%s: expected AV:N to raise the score, got %+v", vector, av)
+		}
+	}
+}
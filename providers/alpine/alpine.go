@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alpine provides a parser of Alpine Linux security database (secdb) to the fixes of the packages
+// Alpine ships (see cvefeed.DistroFixes). NVD CPE configurations hardly ever name Alpine packages, so the matches
+// of apk packages of Alpine based images are amended with the fixes Alpine tracks.
+package alpine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// SecDBURL is the URL of the secdb of the repository of the release, e.g. SecDBURL("v3.18", "main")
+func SecDBURL(release, repo string) string {
+	return fmt.Sprintf("https://secdb.alpinelinux.org/%s/%s.json", release, repo)
+}
+
+// secDB is the security database of a repository of Alpine release, e.g. https://secdb.alpinelinux.org/v3.18/main.json
+type secDB struct {
+	DistroVersion string `json:"distroversion"`
+	RepoName      string `json:"reponame"`
+	Packages      []struct {
+		Pkg struct {
+			Name string `json:"name"`
+			// SecFixes lists the CVEs fixed in the versions of the package, version 0 lists the ones
+			// the package isn't affected by
+			SecFixes map[string][]string `json:"secfixes"`
+		} `json:"pkg"`
+	} `json:"packages"`
+}
+
+// Parse parses the fixes of the packages of Alpine secdb JSON. The release of the fixes is alpine and the version
+// of the database, e.g. alpine3.18. Entries of secfixes other than CVE IDs (e.g. XSA-123) are skipped, CVEs fixed
+// in several versions are fixed in the earliest one.
+func Parse(in io.Reader) (cvefeed.DistroFixes, error) {
+	var db secDB
+	if err := json.NewDecoder(in).Decode(&db); err != nil {
+		return nil, fmt.Errorf("alpine: failed to decode secdb: %v", err)
+	}
+	release := "alpine" + strings.TrimPrefix(db.DistroVersion, "v")
+	fixes := make(cvefeed.DistroFixes)
+	for _, p := range db.Packages {
+		name := strings.ToLower(p.Pkg.Name)
+		if name == "" {
+			continue
+		}
+		versions := make([]string, 0, len(p.Pkg.SecFixes))
+		for version := range p.Pkg.SecFixes {
+			versions = append(versions, version)
+		}
+		// the earliest fix is added first, and kept
+		sort.Slice(versions, func(i, j int) bool { return nvdcommon.CompareAPK(versions[i], versions[j]) < 0 })
+		seen := make(map[string]bool)
+		for _, version := range versions {
+			for _, entry := range p.Pkg.SecFixes[version] {
+				for _, cve := range strings.Fields(entry) {
+					if !strings.HasPrefix(cve, "CVE-") || seen[cve] {
+						continue
+					}
+					seen[cve] = true
+					f := &cvefeed.DistroFix{CVE: cve, Package: name, Release: release}
+					if version == "0" {
+						f.NotAffected = true
+					} else {
+						f.FixedIn = version
+					}
+					fixes.Add(f)
+				}
+			}
+		}
+	}
+	return fixes, nil
+}
+
+// Load parses Alpine secdb JSON from file, see Parse
+func Load(path string) (cvefeed.DistroFixes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("alpine: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	fixes, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v in %q", err, path)
+	}
+	return fixes, nil
+}
+
+// Fetch downloads Alpine secdb JSON from url with the client and parses it, see Parse
+func Fetch(ctx context.Context, c *client.Client, url string) (cvefeed.DistroFixes, error) {
+	resp, err := c.Get(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("alpine: %v", err)
+	}
+	defer resp.Body.Close()
+	fixes, err := Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%v in %q", err, url)
+	}
+	return fixes, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alpine
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSecDB = `{
+  "apkurl": "{{urlprefix}}/{{distroversion}}/{{reponame}}/{{arch}}/{{pkg.name}}-{{pkg.ver}}.apk",
+  "archs": ["aarch64", "x86_64"],
+  "reponame": "main",
+  "urlprefix": "https://dl-cdn.alpinelinux.org/alpine",
+  "distroversion": "v3.18",
+  "packages": [
+    {"pkg": {"name": "openssl", "secfixes": {
+      "3.1.1-r0": ["CVE-2023-2650"],
+      "3.1.0-r4": ["CVE-2023-1255", "CVE-2023-2650"],
+      "0": ["CVE-2022-3996"]
+    }}},
+    {"pkg": {"name": "xen", "secfixes": {
+      "4.17.1-r1": ["CVE-2022-42332 XSA-427", "XSA-428"]
+    }}},
+    {"pkg": {"name": "busybox"}}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	fixes, err := Parse(strings.NewReader(testSecDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"CVE-2023-2650 openssl": "3.1.0-r4",
+		"CVE-2023-1255 openssl": "3.1.0-r4",
+		"CVE-2022-3996 openssl": "not_affected",
+		"CVE-2022-42332 xen":    "4.17.1-r1",
+	}
+	got := make(map[string]string)
+	for cve, fs := range fixes {
+		for _, f := range fs {
+			if f.Release != "alpine3.18" {
+				t.Errorf("%s: unexpected release %q", cve, f.Release)
+			}
+			got[cve+" "+f.Package] = f.String()
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+	if _, err := Parse(strings.NewReader("<xml/>")); err == nil {
+		t.Error("expected malformed secdb to fail")
+	}
+}
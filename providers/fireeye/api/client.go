@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/pkg/errors"
 )
 
@@ -43,6 +44,7 @@ type Client struct {
 	baseURL   string
 	userAgent string
 	m         sync.Mutex
+	HTTP      *client.Client
 }
 
 // NewClient creates an object which is used to query the iDefense API
@@ -55,6 +57,7 @@ func NewClient(baseURL, userAgent, publicKey, privateKey string) (*Client, error
 		publicKey: publicKey,
 		baseURL:   baseURL,
 		userAgent: userAgent,
+		HTTP:      client.Default(),
 	}, nil
 }
 
@@ -79,7 +82,7 @@ func (c *Client) Request(endpoint string) (io.Reader, error) {
 	req.Header.Set("User-Agent", c.userAgent)
 
 	// execute the request
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot get url")
 	}
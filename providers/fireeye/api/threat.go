@@ -17,10 +17,10 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 )
 
 // FetchAllThreatReportsSince will fetch all vulnerabilities with specified parameters
@@ -40,13 +40,14 @@ func (c *Client) FetchAllThreatReportsSince(since int64) (<-chan *schema.Fireeye
 		params := params
 		go func() {
 			defer wgReportIDs.Done()
-			log.Printf("Fetching: %s\n", params)
+			logger.Infof("Fetching: %s", params)
 			if rIDs, err := c.fetchReportIDs(params); err == nil {
 				for _, rID := range rIDs {
 					reportIDs <- rID
 				}
 			} else {
-				log.Println(err)
+				logger.Errorf("%v", err)
+				metrics.ProviderErrors.Inc("fireeye", "fetch")
 			}
 		}()
 	}
@@ -69,7 +70,8 @@ func (c *Client) FetchAllThreatReportsSince(since int64) (<-chan *schema.Fireeye
 			if report, err := c.fetchReport(rID); err == nil {
 				reports <- report
 			} else {
-				log.Println(err)
+				logger.With("id", rID).Errorf("%v", err)
+				metrics.ProviderErrors.Inc("fireeye", "fetch")
 			}
 		}()
 	}
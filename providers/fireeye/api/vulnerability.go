@@ -17,11 +17,13 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
 )
 
+var logger = logging.With("provider", "fireeye")
+
 // FetchAllVulnerabilitiesSince will fetch all vulnerabilities with specified parameters
 func (c *Client) FetchAllVulnerabilitiesSince(since int64) ([]*schema.FireeyeVulnerability, error) {
 	parameters := newParametersSince(since)
@@ -30,12 +32,12 @@ func (c *Client) FetchAllVulnerabilitiesSince(since int64) ([]*schema.FireeyeVul
 	}
 	var vulns []*schema.FireeyeVulnerability
 	for _, params := range parameters.batchBy(ninetyDays) {
-		log.Printf("Fetching: %s\n", params)
+		logger.Infof("Fetching: %s", params)
 		vs, err := c.fetchVulnerabilities(params)
 		if err != nil {
 			return nil, err
 		}
-		log.Printf("Adding %d vulns\n", len(vs))
+		logger.Infof("Adding %d vulns", len(vs))
 		vulns = append(vulns, vs...)
 	}
 	return vulns, nil
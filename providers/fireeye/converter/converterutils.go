@@ -15,15 +15,17 @@
 package converter
 
 import (
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
 )
 
+var logger = logging.With("provider", "fireeye")
+
 func extractCVSSBaseScore(item *schema.FireeyeVulnerability) float64 {
 	return strToFloat(item.CvssBaseScore)
 }
@@ -47,7 +49,7 @@ func convertTime(fireeyeTime int64) string {
 func strToFloat(str string) float64 {
 	f, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		log.Println(err)
+		logger.Warnf("%v", err)
 		f = float64(0)
 	}
 	return f
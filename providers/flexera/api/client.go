@@ -15,27 +15,31 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/facebookincubator/nvdtools/providers/flexera/rate"
 	"github.com/facebookincubator/nvdtools/providers/flexera/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/pkg/errors"
 )
 
+var logger = logging.With("provider", "flexera")
+
 // Client stores information needed to access Flexera API
 // API key will be sent in the Authorization field
-// rate limiter is used to enforce their api limits (so we don't go over them)
+// HTTP client enforces their api limits (so we don't go over them), see HTTPConfig
 type Client struct {
 	apiKey  string
 	baseURL string
-	limiter rate.Limiter
+	HTTP    *client.Client
 }
 
 const (
@@ -48,12 +52,23 @@ const (
 	retryDelay         = 1 * time.Second
 )
 
+// HTTPConfig returns the default config of the HTTP client, within the limits of Flexera API
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.RateLimit = requestsPerMinute
+	cfg.RatePeriod = time.Minute
+	cfg.Retries = numRequestRetries
+	cfg.Backoff = retryDelay
+	cfg.UserAgent = userAgent
+	return cfg
+}
+
 // NewClient creates a new Client object with given properties
 func NewClient(baseURL, apiKey string) Client {
 	return Client{
 		apiKey:  apiKey,
 		baseURL: baseURL,
-		limiter: rate.BurstyLimiter(time.Minute, requestsPerMinute),
+		HTTP:    client.MustNew(HTTPConfig()),
 	}
 }
 
@@ -68,7 +83,7 @@ func (c Client) FetchAll(from, to int64) (<-chan *schema.FlexeraAdvisory, error)
 	}
 
 	numPages := (totalAdvisories-1)/pageSize + 1
-	log.Printf("starting sync for %d advisories over %d pages\n", totalAdvisories, numPages)
+	logger.Infof("starting sync for %d advisories over %d pages", totalAdvisories, numPages)
 
 	identifiers := make(chan string, totalAdvisories)
 	advisories := make(chan *schema.FlexeraAdvisory, totalAdvisories)
@@ -84,7 +99,8 @@ func (c Client) FetchAll(from, to int64) (<-chan *schema.FlexeraAdvisory, error)
 					identifiers <- element.AdvisoryIdentifier
 				}
 			} else {
-				log.Println(errors.Wrapf(err, "failed to fetch page %d advisory list", p))
+				logger.Errorf("%v", errors.Wrapf(err, "failed to fetch page %d advisory list", p))
+				metrics.ProviderErrors.Inc("flexera", "fetch")
 			}
 		}(page + 1)
 	}
@@ -104,7 +120,8 @@ func (c Client) FetchAll(from, to int64) (<-chan *schema.FlexeraAdvisory, error)
 				if err == nil {
 					advisories <- advisory
 				} else {
-					log.Println(errors.Wrapf(err, "failed to fetch advisory %s", identifier))
+					logger.With("id", identifier).Errorf("%v", errors.Wrap(err, "failed to fetch advisory"))
+					metrics.ProviderErrors.Inc("flexera", "fetch")
 				}
 			}
 		}()
@@ -167,24 +184,11 @@ func (c Client) query(endpoint string, params map[string]string, v interface{})
 	}
 	u.RawQuery = query.Encode()
 
-	var resp *http.Response
-	for i := 0; i < numRequestRetries; i++ {
-		c.limiter.Allow() // block until we can make another request
-		resp, err = queryURL(u.String(), http.Header{
-			"Authorization": {c.apiKey},
-			"User-Agent":    {userAgent},
-		})
-		if err == nil {
-			break
-		}
-
-		if he, ok := err.(httpError); !ok || !he.isRateLimit() {
-			return err
-		}
-		// it is rate limit, just retry after 1 second
-		time.Sleep(retryDelay)
-	}
-	if resp == nil {
+	// rate limited requests are retried by the client
+	resp, err := c.HTTP.Get(context.Background(), u.String(), http.Header{
+		"Authorization": {c.apiKey},
+	})
+	if err != nil {
 		return err
 	}
 
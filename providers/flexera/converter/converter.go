@@ -15,14 +15,16 @@
 package converter
 
 import (
-	"log"
-
 	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
 	srcSchema "github.com/facebookincubator/nvdtools/providers/flexera/schema"
 
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/pkg/errors"
 )
 
+var logger = logging.With("provider", "flexera")
+
 const (
 	cveDataVersion = "4.0"
 )
@@ -33,9 +35,11 @@ func Convert(input <-chan *srcSchema.FlexeraAdvisory) *dstSchema.NVDCVEFeedJSON1
 	for adv := range input {
 		converted, err := convert(adv)
 		if err != nil {
-			log.Println(err)
+			logger.With("id", adv.AdvisoryIdentifier).Warnf("skipped: %v", err)
+			metrics.ProviderErrors.Inc("flexera", "convert")
 			continue
 		}
+		metrics.ProviderConverted.Inc("flexera")
 		feed.CVEItems = append(feed.CVEItems, converted)
 	}
 	return &feed
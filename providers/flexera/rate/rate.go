@@ -12,10 +12,17 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package rate limits the rate of requests.
+//
+// Deprecated: providers rate limit their requests with the HTTP client of providers/lib/client
+// (see client.Config.RateLimit), or with client.Limiter; this package wraps it and will be removed.
 package rate
 
 import (
+	"context"
 	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 )
 
 // Limiter provides only one function: Allow. it blocks until routine can proceed
@@ -23,29 +30,18 @@ type Limiter interface {
 	Allow()
 }
 
-type token struct{}
-
 type limiter struct {
-	tokens chan token
+	l *client.Limiter
 }
 
-// BurstyLimiter will create a limiter which allows bursts of maximum requestsPerPeriod
-// and otherwise allows requests with period/requestsPerPeriod gap in between
+// BurstyLimiter will create a limiter which allows requests with period/requestsPerPeriod gap in between;
+// unlike it used to, it doesn't allow bursts of maximum requestsPerPeriod after idle periods
+//
+// Deprecated: use client.NewLimiter.
 func BurstyLimiter(period time.Duration, requestsPerPeriod int) Limiter {
-	l := &limiter{
-		tokens: make(chan token, requestsPerPeriod),
-	}
-
-	// start filling indefinitely
-	go func() {
-		for range time.Tick(period / time.Duration(requestsPerPeriod)) {
-			l.tokens <- token{}
-		}
-	}()
-
-	return l
+	return limiter{client.NewLimiter(requestsPerPeriod, period)}
 }
 
-func (l *limiter) Allow() {
-	<-l.tokens
+func (l limiter) Allow() {
+	l.l.Wait(context.Background()) // never canceled, so it never fails
 }
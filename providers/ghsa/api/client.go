@@ -0,0 +1,242 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package api pulls GitHub security advisories with GitHub GraphQL API
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.With("provider", "ghsa")
+
+// URL is the endpoint of GitHub GraphQL API
+const URL = "https://api.github.com/graphql"
+
+const (
+	userAgent         = "nvdtools-ghsa"
+	pageSize          = 100
+	numRequestRetries = 5
+)
+
+// retryDelay is how long to wait before retrying rate limited request which doesn't tell when to retry
+var retryDelay = time.Minute
+
+// maxRetryWait is the longest wait for the rate limit to reset, the primary limit resets every hour
+const maxRetryWait = time.Hour
+
+const query = `query($first: Int!, $after: String, $updatedSince: DateTime) {
+  rateLimit { cost remaining resetAt }
+  securityAdvisories(first: $first, after: $after, updatedSince: $updatedSince, orderBy: {field: UPDATED_AT, direction: ASC}) {
+    totalCount
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ghsaId summary description severity permalink publishedAt updatedAt withdrawnAt
+      identifiers { type value }
+      references { url }
+      cvss { score vectorString }
+      cwes(first: 20) { nodes { cweId } }
+      vulnerabilities(first: 100) {
+        nodes {
+          package { ecosystem name }
+          vulnerableVersionRange
+          firstPatchedVersion { identifier }
+        }
+      }
+    }
+  }
+}`
+
+// Client pulls advisories from GitHub GraphQL API
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *client.Client
+}
+
+// HTTPConfig returns the default config of the HTTP client: rate limited requests, GraphQL queries which
+// change nothing, are retried once the limit resets (up to maxRetryWait), or after retryDelay doubled on
+// every retry if the response doesn't tell when
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.Retries = numRequestRetries
+	cfg.Backoff = retryDelay
+	cfg.MaxBackoff = maxRetryWait
+	cfg.Retryable = isRateLimit
+	cfg.RetryAllMethods = true
+	cfg.UserAgent = userAgent
+	return cfg
+}
+
+// NewClient creates a client of GitHub GraphQL API authenticated with the token
+func NewClient(url, token string) *Client {
+	return &Client{URL: url, Token: token, HTTP: client.MustNew(HTTPConfig())}
+}
+
+// Cursor is the position of an incremental pull: advisories updated since UpdatedSince, from the page after
+// the one EndCursor ends (empty EndCursor - from the first page); see Fetch
+type Cursor struct {
+	UpdatedSince time.Time `json:"updated_since"`
+	EndCursor    string    `json:"end_cursor,omitempty"`
+}
+
+// Fetch pulls advisories updated since the cursor, in the order of updates, and calls fn for every page
+// along with the cursor after the page, so an interrupted pull can be resumed from it. Once all pages are
+// pulled, the returned cursor is the one of the next incremental pull: advisories updated since the last
+// update pulled. Rate limited requests are retried once the limit resets.
+func (c *Client) Fetch(ctx context.Context, from Cursor, fn func([]*schema.SecurityAdvisory, Cursor) error) (Cursor, error) {
+	cursor := from
+	latest := from.UpdatedSince
+	for {
+		variables := map[string]interface{}{"first": pageSize}
+		if !cursor.UpdatedSince.IsZero() {
+			variables["updatedSince"] = cursor.UpdatedSince.UTC().Format(time.RFC3339)
+		}
+		if cursor.EndCursor != "" {
+			variables["after"] = cursor.EndCursor
+		}
+		data, err := c.query(ctx, variables)
+		if err != nil {
+			return cursor, err
+		}
+		page := data.SecurityAdvisories
+		if page == nil {
+			return cursor, errors.New("no securityAdvisories in response")
+		}
+		for _, adv := range page.Nodes {
+			if t, err := time.Parse(time.RFC3339, adv.UpdatedAt); err == nil && t.After(latest) {
+				latest = t
+			}
+		}
+		if page.PageInfo != nil && page.PageInfo.EndCursor != "" {
+			cursor.EndCursor = page.PageInfo.EndCursor
+		}
+		if err := fn(page.Nodes, cursor); err != nil {
+			return cursor, err
+		}
+		if page.PageInfo == nil || !page.PageInfo.HasNextPage {
+			return Cursor{UpdatedSince: latest}, nil
+		}
+		if err := waitRateLimit(ctx, data.RateLimit); err != nil {
+			return cursor, err
+		}
+	}
+}
+
+// FetchAll pulls all advisories updated since the time and the cursor of the next incremental pull, see Fetch
+func (c *Client) FetchAll(ctx context.Context, since time.Time) ([]*schema.SecurityAdvisory, Cursor, error) {
+	var all []*schema.SecurityAdvisory
+	next, err := c.Fetch(ctx, Cursor{UpdatedSince: since}, func(page []*schema.SecurityAdvisory, _ Cursor) error {
+		all = append(all, page...)
+		return nil
+	})
+	return all, next, err
+}
+
+// waitRateLimit blocks until the rate limit resets, if the next request would exceed it
+func waitRateLimit(ctx context.Context, limit *schema.RateLimit) error {
+	if limit == nil || limit.Remaining > limit.Cost {
+		return nil
+	}
+	reset, err := time.Parse(time.RFC3339, limit.ResetAt)
+	if err != nil {
+		return nil
+	}
+	logger.Warnf("rate limit exceeded, waiting until %s", limit.ResetAt)
+	return sleep(ctx, time.Until(reset))
+}
+
+func (c *Client) query(ctx context.Context, variables map[string]interface{}) (*schema.Data, error) {
+	body, err := json.Marshal(schema.Request{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result schema.Response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response")
+	}
+	if len(result.Errors) != 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = e.Message
+		}
+		return nil, errors.Errorf("graphql error: %s", strings.Join(msgs, "; "))
+	}
+	if result.Data == nil {
+		return nil, errors.New("no data in response")
+	}
+	return result.Data, nil
+}
+
+// isRateLimit tells whether the request exceeded the primary or the secondary rate limit
+func isRateLimit(resp *http.Response, body []byte) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && strings.Contains(strings.ToLower(string(body)), "rate limit")
+}
+
+// post executes GraphQL request and returns the response if its status is HTTP OK
+func (c *Client) post(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest("POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create http request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = client.MustNew(HTTPConfig())
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot post request")
+	}
+	if err := client.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+)
+
+func TestFetch(t *testing.T) {
+	pages := map[string]*schema.SecurityAdvisories{
+		"": {
+			PageInfo: &schema.PageInfo{HasNextPage: true, EndCursor: "c1"},
+			Nodes: []*schema.SecurityAdvisory{
+				{GHSAID: "GHSA-0001", UpdatedAt: "2023-01-01T00:00:00Z"},
+				{GHSAID: "GHSA-0002", UpdatedAt: "2023-01-03T00:00:00Z"},
+			},
+		},
+		"c1": {
+			PageInfo: &schema.PageInfo{EndCursor: "c2"},
+			Nodes:    []*schema.SecurityAdvisory{{GHSAID: "GHSA-0003", UpdatedAt: "2023-01-02T00:00:00Z"}},
+		},
+	}
+	limited := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token" {
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+		var req schema.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Variables["updatedSince"] != "2022-12-01T00:00:00Z" {
+			http.Error(w, "unexpected updatedSince", http.StatusBadRequest)
+			return
+		}
+		after, _ := req.Variables["after"].(string)
+		if after == "c1" && !limited { // secondary rate limit, once
+			limited = true
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "You have exceeded a secondary rate limit", http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(schema.Response{Data: &schema.Data{
+			RateLimit:          &schema.RateLimit{Cost: 1, Remaining: 4999, ResetAt: "2023-01-01T00:00:00Z"},
+			SecurityAdvisories: pages[after],
+		}})
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token")
+	since := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	var cursors []string
+	var ids []string
+	next, err := client.Fetch(context.Background(), Cursor{UpdatedSince: since}, func(page []*schema.SecurityAdvisory, c Cursor) error {
+		for _, adv := range page {
+			ids = append(ids, adv.GHSAID)
+		}
+		cursors = append(cursors, c.EndCursor)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[2] != "GHSA-0003" {
+		t.Errorf("unexpected advisories %v", ids)
+	}
+	if len(cursors) != 2 || cursors[0] != "c1" || cursors[1] != "c2" {
+		t.Errorf("unexpected cursors %v", cursors)
+	}
+	if !limited {
+		t.Error("expected rate limited request to be retried")
+	}
+	want := Cursor{UpdatedSince: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)}
+	if !next.UpdatedSince.Equal(want.UpdatedSince) || next.EndCursor != "" {
+		t.Errorf("want next cursor %+v, got %+v", want, next)
+	}
+
+	client.Token = "wrong"
+	if _, _, err := client.FetchAll(context.Background(), since); err == nil {
+		t.Error("expected an error with bad credentials")
+	}
+}
+
+func TestGraphQLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schema.Response{Errors: []*schema.Error{{Message: "Field 'foo' doesn't exist"}}})
+	}))
+	defer srv.Close()
+	if _, _, err := NewClient(srv.URL, "token").FetchAll(context.Background(), time.Time{}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package converter converts GitHub security advisories to NVD CVE JSON 1.0 format
+package converter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.With("provider", "ghsa")
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// Convert converts GitHub security advisories to NVD format; withdrawn advisories and the ones which
+// can't be converted (e.g. with no vulnerable packages of known ecosystems) are logged and skipped
+func Convert(advisories []*srcSchema.SecurityAdvisory) *dstSchema.NVDCVEFeedJSON10 {
+	var feed dstSchema.NVDCVEFeedJSON10
+	for _, adv := range advisories {
+		if adv == nil || adv.WithdrawnAt != "" {
+			continue
+		}
+		converted, err := ConvertAdvisory(adv)
+		if err != nil {
+			logger.With("id", adv.GHSAID).Warnf("skipped: %v", err)
+			metrics.ProviderErrors.Inc("ghsa", "convert")
+			continue
+		}
+		metrics.ProviderConverted.Inc("ghsa")
+		feed.CVEItems = append(feed.CVEItems, converted)
+	}
+	sort.Slice(feed.CVEItems, func(i, j int) bool {
+		return feed.CVEItems[i].CVE.CVEDataMeta.ID < feed.CVEItems[j].CVE.CVEDataMeta.ID
+	})
+	return &feed
+}
+
+// ConvertAdvisory converts GitHub security advisory to NVD format: the vulnerable packages become CPE names
+// of applications named after the packages, of any vendor, and the vulnerable version ranges become version
+// ranges of the CPE matches; GHSA ID is kept, the other identifiers (e.g. CVE IDs) become references.
+func ConvertAdvisory(adv *srcSchema.SecurityAdvisory) (*dstSchema.NVDCVEFeedJSON10DefCVEItem, error) {
+	modified, err := convertTime(adv.UpdatedAt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can't convert updated date", adv.GHSAID)
+	}
+	published, err := convertTime(adv.PublishedAt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can't convert published date", adv.GHSAID)
+	}
+
+	configurations, err := makeConfigurations(adv)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can't create configurations", adv.GHSAID)
+	}
+
+	description := adv.Description
+	if description == "" {
+		description = adv.Summary
+	}
+
+	return &dstSchema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &dstSchema.CVEJSON40{
+			CVEDataMeta: &dstSchema.CVEJSON40CVEDataMeta{
+				ID:       adv.GHSAID,
+				ASSIGNER: "ghsa",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &dstSchema.CVEJSON40Description{
+				DescriptionData: []*dstSchema.CVEJSON40LangString{
+					{Lang: "en", Value: description},
+				},
+			},
+			Problemtype: makeProblemtype(adv),
+			References:  makeReferences(adv),
+		},
+		Configurations:   configurations,
+		Impact:           makeImpact(adv),
+		LastModifiedDate: modified,
+		PublishedDate:    published,
+	}, nil
+}
+
+func convertTime(githubTime string) (string, error) {
+	t, err := time.Parse(time.RFC3339, githubTime)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(nvdcommon.TimeLayout), nil
+}
+
+func makeReferences(adv *srcSchema.SecurityAdvisory) *dstSchema.CVEJSON40References {
+	var refsData []*dstSchema.CVEJSON40Reference
+	for _, id := range adv.Identifiers {
+		if id.Value != adv.GHSAID {
+			refsData = append(refsData, &dstSchema.CVEJSON40Reference{Name: id.Value})
+		}
+	}
+	if adv.Permalink != "" {
+		refsData = append(refsData, &dstSchema.CVEJSON40Reference{Name: adv.GHSAID, URL: adv.Permalink})
+	}
+	for _, ref := range adv.References {
+		refsData = append(refsData, &dstSchema.CVEJSON40Reference{Name: ref.URL, URL: ref.URL})
+	}
+	if len(refsData) == 0 {
+		return nil
+	}
+	return &dstSchema.CVEJSON40References{ReferenceData: refsData}
+}
+
+func makeProblemtype(adv *srcSchema.SecurityAdvisory) *dstSchema.CVEJSON40Problemtype {
+	if adv.CWEs == nil || len(adv.CWEs.Nodes) == 0 {
+		return nil
+	}
+	var descriptions []*dstSchema.CVEJSON40LangString
+	for _, cwe := range adv.CWEs.Nodes {
+		descriptions = append(descriptions, &dstSchema.CVEJSON40LangString{Lang: "en", Value: cwe.CWEID})
+	}
+	return &dstSchema.CVEJSON40Problemtype{
+		ProblemtypeData: []*dstSchema.CVEJSON40ProblemtypeProblemtypeData{
+			{Description: descriptions},
+		},
+	}
+}
+
+// makeImpact returns CVSS v3 score of the vector of the advisory, nil if there's none or it doesn't parse
+func makeImpact(adv *srcSchema.SecurityAdvisory) *dstSchema.NVDCVEFeedJSON10DefImpact {
+	if adv.CVSS == nil || adv.CVSS.VectorString == "" {
+		return nil
+	}
+	vector := adv.CVSS.VectorString
+	score, severity, err := cvss.ScoreAndSeverity(vector)
+	if err != nil {
+		logger.With("id", adv.GHSAID).Warnf("%v", err)
+		return nil
+	}
+	return &dstSchema.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &dstSchema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &dstSchema.CVSSV30{
+				BaseScore:    score,
+				BaseSeverity: strings.ToUpper(severity.String()),
+				VectorString: vector,
+				Version:      strings.TrimPrefix(strings.SplitN(vector, "/", 2)[0], "CVSS:"),
+			},
+		},
+	}
+}
+
+func makeConfigurations(adv *srcSchema.SecurityAdvisory) (*dstSchema.NVDCVEFeedJSON10DefConfigurations, error) {
+	var matches []*dstSchema.NVDCVEFeedJSON10DefCPEMatch
+	if adv.Vulnerabilities != nil {
+		for _, vuln := range adv.Vulnerabilities.Nodes {
+			if vuln.Package == nil {
+				continue
+			}
+			match, err := makeMatch(vuln)
+			if err != nil {
+				logger.With("id", adv.GHSAID).Warnf("%v", err)
+				continue
+			}
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, errors.New("unable to find any vulnerable packages in data")
+	}
+
+	return &dstSchema.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes: []*dstSchema.NVDCVEFeedJSON10DefNode{
+			{
+				CPEMatch: matches,
+				Operator: "OR",
+			},
+		},
+	}, nil
+}
+
+// makeMatch returns CPE match of the vulnerable version range of the package, e.g. ">= 1.0.0, < 1.2.3" or "= 1.0.0"
+func makeMatch(vuln *srcSchema.SecurityVulnerability) (*dstSchema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	attrs, err := packageAttributes(vuln.Package)
+	if err != nil {
+		return nil, err
+	}
+	match := &dstSchema.NVDCVEFeedJSON10DefCPEMatch{Vulnerable: true}
+	for _, constraint := range strings.Split(vuln.VulnerableVersionRange, ",") {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" {
+			continue
+		}
+		version := strings.TrimLeft(constraint, "<>= ")
+		op := strings.TrimSpace(constraint[:len(constraint)-len(version)])
+		if version == "" {
+			return nil, errors.Errorf("malformed version range %q", vuln.VulnerableVersionRange)
+		}
+		switch op {
+		case ">=":
+			match.VersionStartIncluding = version
+		case ">":
+			match.VersionStartExcluding = version
+		case "<":
+			match.VersionEndExcluding = version
+		case "<=":
+			match.VersionEndIncluding = version
+		case "=":
+			if attrs.Version, err = wfn.WFNize(version); err != nil {
+				return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", version)
+			}
+		default:
+			return nil, errors.Errorf("malformed version range %q", vuln.VulnerableVersionRange)
+		}
+	}
+	match.Cpe23Uri = attrs.BindToFmtString()
+	if vuln.FirstPatchedVersion != nil {
+		match.FixedVersion = vuln.FirstPatchedVersion.Identifier
+	}
+	return match, nil
+}
+
+// ecosystemTypes maps GitHub ecosystems to the types of package URLs
+var ecosystemTypes = map[string]string{
+	"ACTIONS":  "github",
+	"COMPOSER": "composer",
+	"ERLANG":   "hex",
+	"GO":       "golang",
+	"MAVEN":    "maven",
+	"NPM":      "npm",
+	"NUGET":    "nuget",
+	"PIP":      "pypi",
+	"PUB":      "pub",
+	"RUBYGEMS": "gem",
+	"RUST":     "cargo",
+	"SWIFT":    "swift",
+}
+
+// PackageURL returns the package URL of the package, derived from the ecosystem and the name
+func PackageURL(pkg *srcSchema.Package) (*purl.PURL, error) {
+	typ, ok := ecosystemTypes[pkg.Ecosystem]
+	if !ok {
+		return nil, errors.Errorf("unknown ecosystem %q of package %q", pkg.Ecosystem, pkg.Name)
+	}
+	p := &purl.PURL{Type: typ, Name: pkg.Name}
+	sep := "/"
+	if typ == "maven" {
+		sep = ":" // group:artifact
+	}
+	if i := strings.LastIndex(pkg.Name, sep); i >= 0 {
+		p.Namespace, p.Name = pkg.Name[:i], pkg.Name[i+1:]
+	}
+	return purl.Parse(p.String()) // normalized
+}
+
+func packageAttributes(pkg *srcSchema.Package) (*wfn.Attributes, error) {
+	p, err := PackageURL(pkg)
+	if err != nil {
+		return nil, err
+	}
+	product, err := wfn.WFNize(strings.ToLower(p.Name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot wfn-ize: %q", p.Name)
+	}
+	return &wfn.Attributes{Part: "a", Vendor: wfn.Any, Product: product, Version: wfn.Any}, nil
+}
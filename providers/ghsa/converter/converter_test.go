@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"reflect"
+	"testing"
+
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+)
+
+func testAdvisory() *srcSchema.SecurityAdvisory {
+	return &srcSchema.SecurityAdvisory{
+		GHSAID:      "GHSA-jfh8-c2jp-5v3q",
+		Summary:     "Remote code injection in Log4j",
+		Permalink:   "https://github.com/advisories/GHSA-jfh8-c2jp-5v3q",
+		PublishedAt: "2021-12-10T00:40:56Z",
+		UpdatedAt:   "2023-11-07T05:04:32Z",
+		Identifiers: []*srcSchema.Identifier{
+			{Type: "GHSA", Value: "GHSA-jfh8-c2jp-5v3q"},
+			{Type: "CVE", Value: "CVE-2021-44228"},
+		},
+		CVSS: &srcSchema.CVSS{Score: 10, VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"},
+		CWEs: &srcSchema.CWEConnection{Nodes: []*srcSchema.CWE{{CWEID: "CWE-502"}}},
+		Vulnerabilities: &srcSchema.SecurityVulnerabilityConnection{Nodes: []*srcSchema.SecurityVulnerability{
+			{
+				Package:                &srcSchema.Package{Ecosystem: "MAVEN", Name: "org.apache.logging.log4j:log4j-core"},
+				VulnerableVersionRange: ">= 2.13.0, < 2.15.0",
+				FirstPatchedVersion:    &srcSchema.FirstPatchedVersion{Identifier: "2.15.0"},
+			},
+			{
+				Package:                &srcSchema.Package{Ecosystem: "MAVEN", Name: "org.apache.logging.log4j:log4j-core"},
+				VulnerableVersionRange: "< 2.3.1",
+			},
+			{
+				Package:                &srcSchema.Package{Ecosystem: "PIP", Name: "Log4_Py"},
+				VulnerableVersionRange: "= 1.0",
+			},
+			{
+				Package:                &srcSchema.Package{Ecosystem: "UNKNOWN", Name: "log4j"},
+				VulnerableVersionRange: "< 1.0",
+			},
+			{
+				Package:                &srcSchema.Package{Ecosystem: "NPM", Name: "log4js"},
+				VulnerableVersionRange: "~> 1.0",
+			},
+		}},
+	}
+}
+
+func TestConvertAdvisory(t *testing.T) {
+	item, err := ConvertAdvisory(testAdvisory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := item.CVE.CVEDataMeta.ID; id != "GHSA-jfh8-c2jp-5v3q" {
+		t.Errorf("unexpected ID %q", id)
+	}
+	if item.PublishedDate != "2021-12-10T00:40Z" || item.LastModifiedDate != "2023-11-07T05:04Z" {
+		t.Errorf("unexpected dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if v3 := item.Impact.BaseMetricV3.CVSSV3; v3.BaseScore != 10 || v3.BaseSeverity != "CRITICAL" {
+		t.Errorf("unexpected CVSS v3 %+v", v3)
+	}
+	if refs := item.CVE.References.ReferenceData; len(refs) != 2 || refs[0].Name != "CVE-2021-44228" {
+		t.Errorf("unexpected references %+v", refs)
+	}
+	if cwes := item.CVE.Problemtype.ProblemtypeData[0].Description; len(cwes) != 1 || cwes[0].Value != "CWE-502" {
+		t.Errorf("unexpected problem types %+v", cwes)
+	}
+
+	want := []*dstSchema.NVDCVEFeedJSON10DefCPEMatch{
+		{Cpe23Uri: "cpe:2.3:a:*:log4j-core:*:*:*:*:*:*:*:*", Vulnerable: true, VersionStartIncluding: "2.13.0", VersionEndExcluding: "2.15.0", FixedVersion: "2.15.0"},
+		{Cpe23Uri: "cpe:2.3:a:*:log4j-core:*:*:*:*:*:*:*:*", Vulnerable: true, VersionEndExcluding: "2.3.1"},
+		{Cpe23Uri: "cpe:2.3:a:*:log4-py:1.0:*:*:*:*:*:*:*", Vulnerable: true},
+	}
+	if got := item.Configurations.Nodes[0].CPEMatch; !reflect.DeepEqual(got, want) {
+		for _, m := range got {
+			t.Logf("%+v", m)
+		}
+		t.Error("unexpected CPE matches")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	withdrawn := testAdvisory()
+	withdrawn.GHSAID, withdrawn.WithdrawnAt = "GHSA-0000-0000-0000", "2022-01-01T00:00:00Z"
+	unaffected := &srcSchema.SecurityAdvisory{GHSAID: "GHSA-1111-1111-1111", PublishedAt: "2022-01-01T00:00:00Z", UpdatedAt: "2022-01-01T00:00:00Z"}
+	feed := Convert([]*srcSchema.SecurityAdvisory{testAdvisory(), withdrawn, unaffected, nil})
+	if len(feed.CVEItems) != 1 || feed.CVEItems[0].CVE.CVEDataMeta.ID != "GHSA-jfh8-c2jp-5v3q" {
+		t.Fatalf("expected only one advisory to be converted, got %+v", feed.CVEItems)
+	}
+}
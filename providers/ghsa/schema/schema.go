@@ -0,0 +1,128 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema defines the types of GitHub GraphQL API securityAdvisories query,
+// see https://docs.github.com/en/graphql/reference/objects#securityadvisory
+package schema
+
+// Request is GraphQL request
+type Request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the response of securityAdvisories query
+type Response struct {
+	Data   *Data    `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is GraphQL error
+type Error struct {
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// Data is the data of the response
+type Data struct {
+	RateLimit          *RateLimit          `json:"rateLimit"`
+	SecurityAdvisories *SecurityAdvisories `json:"securityAdvisories"`
+}
+
+// RateLimit is the status of the rate limit of the token
+type RateLimit struct {
+	Cost      int    `json:"cost"`
+	Remaining int    `json:"remaining"`
+	ResetAt   string `json:"resetAt"`
+}
+
+// SecurityAdvisories is a page of advisories
+type SecurityAdvisories struct {
+	PageInfo   *PageInfo           `json:"pageInfo"`
+	TotalCount int                 `json:"totalCount"`
+	Nodes      []*SecurityAdvisory `json:"nodes"`
+}
+
+// PageInfo tells whether there are more pages and the cursor of the next one
+type PageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// SecurityAdvisory is GitHub security advisory
+type SecurityAdvisory struct {
+	GHSAID          string                           `json:"ghsaId"`
+	Summary         string                           `json:"summary"`
+	Description     string                           `json:"description"`
+	Severity        string                           `json:"severity"` // LOW, MODERATE, HIGH, CRITICAL
+	Permalink       string                           `json:"permalink"`
+	PublishedAt     string                           `json:"publishedAt"`
+	UpdatedAt       string                           `json:"updatedAt"`
+	WithdrawnAt     string                           `json:"withdrawnAt,omitempty"`
+	Identifiers     []*Identifier                    `json:"identifiers"`
+	References      []*Reference                     `json:"references"`
+	CVSS            *CVSS                            `json:"cvss"`
+	CWEs            *CWEConnection                   `json:"cwes"`
+	Vulnerabilities *SecurityVulnerabilityConnection `json:"vulnerabilities"`
+}
+
+// Identifier is an identifier of the advisory, e.g. GHSA or CVE ID
+type Identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Reference is a link to more information about the advisory
+type Reference struct {
+	URL string `json:"url"`
+}
+
+// CVSS is CVSS v3 assessment of the advisory
+type CVSS struct {
+	Score        float64 `json:"score"`
+	VectorString string  `json:"vectorString"`
+}
+
+// CWEConnection lists the weaknesses of the advisory
+type CWEConnection struct {
+	Nodes []*CWE `json:"nodes"`
+}
+
+// CWE is a weakness, e.g. CWE-79
+type CWE struct {
+	CWEID string `json:"cweId"`
+}
+
+// SecurityVulnerabilityConnection lists the vulnerable packages of the advisory
+type SecurityVulnerabilityConnection struct {
+	Nodes []*SecurityVulnerability `json:"nodes"`
+}
+
+// SecurityVulnerability is the range of vulnerable versions of a package
+type SecurityVulnerability struct {
+	Package                *Package             `json:"package"`
+	VulnerableVersionRange string               `json:"vulnerableVersionRange"` // e.g. >= 1.0.0, < 1.2.3
+	FirstPatchedVersion    *FirstPatchedVersion `json:"firstPatchedVersion"`
+}
+
+// Package is a package of an ecosystem
+type Package struct {
+	Ecosystem string `json:"ecosystem"` // e.g. NPM, PIP, GO, RUST
+	Name      string `json:"name"`
+}
+
+// FirstPatchedVersion is the version the vulnerability was fixed in
+type FirstPatchedVersion struct {
+	Identifier string `json:"identifier"`
+}
@@ -0,0 +1,272 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package api downloads OSV records of the Go vulnerability database, https://vuln.go.dev, or reads them from
+// a local copy of it
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/govulndb/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	osvSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.With("provider", "govulndb")
+
+const (
+	// BaseURL is where the Go vulnerability database is published
+	BaseURL = "https://vuln.go.dev"
+
+	userAgent = "nvdtools-govulndb"
+	// defaultWorkers is the number of records downloaded concurrently, unless Client.Workers says otherwise
+	defaultWorkers = 8
+)
+
+// Client downloads records of the Go vulnerability database
+type Client struct {
+	BaseURL string
+	HTTP    *client.Client
+	Workers int
+}
+
+// HTTPConfig returns the default config of the HTTP client
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.UserAgent = userAgent
+	return cfg
+}
+
+// NewClient creates a client of the Go vulnerability database
+func NewClient() *Client {
+	return &Client{BaseURL: BaseURL, HTTP: client.MustNew(HTTPConfig()), Workers: defaultWorkers}
+}
+
+// Meta returns the metadata of the database, the time it was modified at
+func (c *Client) Meta(ctx context.Context) (*schema.DBMeta, error) {
+	var meta schema.DBMeta
+	if err := c.get(ctx, "/index/db.json", &meta); err != nil {
+		return nil, errors.Wrap(err, "failed to get database metadata")
+	}
+	return &meta, nil
+}
+
+// Modules returns the index of the modules of the database and their vulnerabilities
+func (c *Client) Modules(ctx context.Context) ([]*schema.ModuleEntry, error) {
+	var modules []*schema.ModuleEntry
+	if err := c.get(ctx, "/index/modules.json", &modules); err != nil {
+		return nil, errors.Wrap(err, "failed to get modules index")
+	}
+	return modules, nil
+}
+
+// Vulns returns the index of the vulnerabilities of the database
+func (c *Client) Vulns(ctx context.Context) ([]*schema.VulnEntry, error) {
+	var vulns []*schema.VulnEntry
+	if err := c.get(ctx, "/index/vulns.json", &vulns); err != nil {
+		return nil, errors.Wrap(err, "failed to get vulnerabilities index")
+	}
+	return vulns, nil
+}
+
+// Vulnerability returns the record of the vulnerability, e.g. GO-2022-0969
+func (c *Client) Vulnerability(ctx context.Context, id string) (*osvSchema.Vulnerability, error) {
+	var vuln osvSchema.Vulnerability
+	if err := c.get(ctx, "/ID/"+url.PathEscape(id)+".json", &vuln); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", id)
+	}
+	return &vuln, nil
+}
+
+// IDs returns sorted IDs of the vulnerabilities modified since the time (zero time means all of them)
+// of the modules (none means all the modules), as per the indexes of the database
+func (c *Client) IDs(ctx context.Context, since time.Time, modules []string) ([]string, error) {
+	modified := func(ts string) bool {
+		if since.IsZero() {
+			return true
+		}
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		return err != nil || !t.Before(since) // records of unknown modification time are fetched to be safe
+	}
+	seen := make(map[string]bool)
+	if len(modules) == 0 {
+		vulns, err := c.Vulns(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, vuln := range vulns {
+			if modified(vuln.Modified) {
+				seen[vuln.ID] = true
+			}
+		}
+	} else {
+		entries, err := c.Modules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wanted := make(map[string]bool, len(modules))
+		for _, module := range modules {
+			wanted[module] = true
+		}
+		for _, entry := range entries {
+			if !wanted[entry.Path] {
+				continue
+			}
+			for _, vuln := range entry.Vulns {
+				if modified(vuln.Modified) {
+					seen[vuln.ID] = true
+				}
+			}
+		}
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// FetchAll downloads the records of the vulnerabilities modified since the time of the modules, see IDs;
+// the records are sent to the channel as they are downloaded, the errors downloading them are logged
+func (c *Client) FetchAll(ctx context.Context, since time.Time, modules []string) (<-chan *osvSchema.Vulnerability, error) {
+	ids, err := c.IDs(ctx, since, modules)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("downloading %d records", len(ids))
+
+	workers := c.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	input := make(chan string)
+	output := make(chan *osvSchema.Vulnerability)
+	go func() {
+		defer close(input)
+		for _, id := range ids {
+			select {
+			case input <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for id := range input {
+				vuln, err := c.Vulnerability(ctx, id)
+				if err != nil {
+					logger.With("id", id).Warnf("skipped: %v", err)
+					metrics.ProviderErrors.Inc("govulndb", "fetch")
+					continue
+				}
+				output <- vuln
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+	return output, nil
+}
+
+// ReadDir reads the records of a local copy of the database: the JSON files of its ID directory, or of the
+// directory itself if there's no ID directory in it (e.g. the files of a checkout of golang.org/x/vulndb data/osv)
+func ReadDir(dir string) (<-chan *osvSchema.Vulnerability, error) {
+	if info, err := os.Stat(filepath.Join(dir, "ID")); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, "ID")
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	output := make(chan *osvSchema.Vulnerability)
+	go func() {
+		defer close(output)
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+				continue
+			}
+			vuln, err := readRecord(filepath.Join(dir, file.Name()))
+			if err != nil {
+				logger.With("file", file.Name()).Warnf("skipped: %v", err)
+				metrics.ProviderErrors.Inc("govulndb", "fetch")
+				continue
+			}
+			output <- vuln
+		}
+	}()
+	return output, nil
+}
+
+func readRecord(filename string) (*osvSchema.Vulnerability, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var vuln osvSchema.Vulnerability
+	if err := json.NewDecoder(f).Decode(&vuln); err != nil {
+		return nil, errors.Wrap(err, "failed to decode record")
+	}
+	if vuln.ID == "" {
+		return nil, errors.New("not a record, no ID")
+	}
+	return &vuln, nil
+}
+
+// get decodes the JSON document at the path of the database into v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(c.BaseURL, "/")+path, nil)
+	if err != nil {
+		return errors.Wrap(err, "cannot create http request")
+	}
+	req = req.WithContext(ctx)
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = client.MustNew(HTTPConfig())
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "cannot get url")
+	}
+	defer resp.Body.Close()
+	if err := client.CheckResponse(resp); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/govulndb/schema"
+	osvSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+func testServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index/db.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schema.DBMeta{Modified: "2023-03-01T00:00:00Z"})
+	})
+	mux.HandleFunc("/index/vulns.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*schema.VulnEntry{
+			{ID: "GO-2022-0001", Modified: "2022-01-01T00:00:00Z"},
+			{ID: "GO-2023-0002", Modified: "2023-02-01T00:00:00Z", Aliases: []string{"CVE-2023-0002"}},
+			{ID: "GO-2023-0003", Modified: "2023-03-01T00:00:00Z"},
+		})
+	})
+	mux.HandleFunc("/index/modules.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*schema.ModuleEntry{
+			{Path: "golang.org/x/net", Vulns: []*schema.ModuleVuln{
+				{ID: "GO-2022-0001", Modified: "2022-01-01T00:00:00Z", Fixed: "0.1.0"},
+				{ID: "GO-2023-0003", Modified: "2023-03-01T00:00:00Z"},
+			}},
+			{Path: "stdlib", Vulns: []*schema.ModuleVuln{{ID: "GO-2023-0002", Modified: "2023-02-01T00:00:00Z"}}},
+		})
+	})
+	mux.HandleFunc("/ID/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/ID/") : len(r.URL.Path)-len(".json")]
+		if id == "GO-2023-0003" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(osvSchema.Vulnerability{ID: id, Summary: "summary of " + id})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+	c := NewClient()
+	c.BaseURL = srv.URL
+	ctx := context.Background()
+
+	meta, err := c.Meta(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Modified != "2023-03-01T00:00:00Z" {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, q := range []struct {
+		since   time.Time
+		modules []string
+		ids     []string
+	}{
+		{time.Time{}, nil, []string{"GO-2022-0001", "GO-2023-0002", "GO-2023-0003"}},
+		{since, nil, []string{"GO-2023-0002", "GO-2023-0003"}},
+		{time.Time{}, []string{"golang.org/x/net"}, []string{"GO-2022-0001", "GO-2023-0003"}},
+		{since, []string{"golang.org/x/net", "github.com/unknown/module"}, []string{"GO-2023-0003"}},
+	} {
+		ids, err := c.IDs(ctx, q.since, q.modules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, q.ids) {
+			t.Errorf("%v %v: expected %v, got %v", q.since, q.modules, q.ids, ids)
+		}
+	}
+
+	vulns, err := c.FetchAll(ctx, time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for vuln := range vulns {
+		if vuln.Summary != "summary of "+vuln.ID {
+			t.Errorf("unexpected record %+v", vuln)
+		}
+		ids = append(ids, vuln.ID)
+	}
+	sort.Strings(ids)
+	if want := []string{"GO-2022-0001", "GO-2023-0002"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v, the record which can't be downloaded skipped, got %v", want, ids)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "govulndb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "ID"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"ID/GO-2022-0001.json": `{"id": "GO-2022-0001", "modified": "2022-01-01T00:00:00Z"}`,
+		"ID/GO-2022-0002.json": `{"id": "GO-2022-0002", "modified": "2022-01-02T00:00:00Z"}`,
+		"ID/broken.json":       `{"id": `,
+		"ID/README":            "not a record",
+		"index/db.json":        `{"modified": "2022-01-02T00:00:00Z"}`,
+	} {
+		name = filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vulns, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for vuln := range vulns {
+		ids = append(ids, vuln.ID)
+	}
+	if want := []string{"GO-2022-0001", "GO-2022-0002"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+}
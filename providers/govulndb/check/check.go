@@ -0,0 +1,268 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package check finds the vulnerabilities of the Go vulnerability database affecting Go binaries, the way
+// govulncheck does for binaries: the modules the binary is built of are matched against the affected versions
+// of the records and, if the binary has a symbol table, the vulnerable functions of the records against the
+// functions of the binary, so modules the binary only uses the safe parts of aren't reported
+package check
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	"github.com/facebookincubator/nvdtools/providers/govulndb/schema"
+	osvSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/facebookincubator/nvdtools/purl"
+)
+
+// Finding is a vulnerability of a module of the binary
+type Finding struct {
+	ID      string
+	Aliases []string
+	Module  string // the path of the module, stdlib for the standard library
+	Version string // the version of the module the binary is built with, as the database records versions
+	Fixed   string // the version fixing the vulnerability, if any
+	// Symbols are the vulnerable functions found in the binary, e.g. net/http.Server.Serve, or the vulnerable
+	// packages if the record lists no functions of them; none if the symbols weren't checked
+	Symbols []string
+}
+
+// Options tells how binaries are checked
+type Options struct {
+	// ModuleOnly reports the vulnerabilities of the modules without checking the symbols of the binary
+	ModuleOnly bool
+}
+
+// Binary returns the vulnerabilities of the records affecting the binary, sorted by module and ID. The symbols
+// are checked unless opts.ModuleOnly is set, the binary has no functions (e.g. stripped PE binary) or the affected
+// module lists no vulnerable packages, in which cases all the vulnerabilities of the module versions are reported.
+func Binary(bin *cpeparse.GoBinary, vulns []*osvSchema.Vulnerability, opts Options) []*Finding {
+	versions := make(map[string]string)
+	for _, mod := range bin.Modules() {
+		if v := cpeparse.GoModuleVersion(mod.Version); v != "" {
+			versions[mod.Path] = v
+		}
+	}
+	funcs := binaryFuncs(bin.Symbols)
+	checkSymbols := !opts.ModuleOnly && len(funcs) != 0
+
+	var findings []*Finding
+	for _, vuln := range vulns {
+		if vuln == nil || vuln.Withdrawn != "" {
+			continue
+		}
+		found := make(map[string]*Finding) // by module
+		for _, affected := range vuln.Affected {
+			if affected == nil || affected.Package == nil || affected.Package.Ecosystem != "Go" {
+				continue
+			}
+			module := affected.Package.Name
+			version, ok := versions[module]
+			if !ok {
+				continue
+			}
+			affects, fixed := affectedVersion(affected, version)
+			if !affects {
+				continue
+			}
+			var symbols []string
+			if imports, err := schema.Imports(affected); checkSymbols && err == nil && len(imports) != 0 {
+				if symbols = vulnerableSymbols(bin, funcs, imports); len(symbols) == 0 {
+					continue
+				}
+			}
+			f := found[module]
+			if f == nil {
+				f = &Finding{ID: vuln.ID, Aliases: vuln.Aliases, Module: module, Version: version}
+				found[module] = f
+				findings = append(findings, f)
+			}
+			if f.Fixed == "" {
+				f.Fixed = fixed
+			}
+			f.Symbols = append(f.Symbols, symbols...)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Module != findings[j].Module {
+			return findings[i].Module < findings[j].Module
+		}
+		return findings[i].ID < findings[j].ID
+	})
+	return findings
+}
+
+// affectedVersion tells whether the version of the module is affected, and the version of the range of it fixing
+// the vulnerability; SEMVER and ECOSYSTEM ranges are checked, or the listed versions if there are none
+func affectedVersion(affected *osvSchema.Affected, version string) (bool, string) {
+	ranged := false
+	for _, r := range affected.Ranges {
+		if r == nil || (r.Type != "SEMVER" && r.Type != "ECOSYSTEM") {
+			continue
+		}
+		ranged = true
+		var open *purl.Range // the range open at the moment
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				open = &purl.Range{Introduced: event.Introduced}
+			case event.Fixed != "" && open != nil:
+				open.Fixed = event.Fixed
+				if open.Contains("golang", version) {
+					return true, open.Fixed
+				}
+				open = nil
+			case event.LastAffected != "" && open != nil:
+				open.LastAffected = event.LastAffected
+				if open.Contains("golang", version) {
+					return true, ""
+				}
+				open = nil
+			}
+		}
+		if open != nil && open.Contains("golang", version) { // no fix yet
+			return true, ""
+		}
+	}
+	if ranged {
+		return false, ""
+	}
+	for _, v := range affected.Versions {
+		if purl.CompareSemver(v, version) == 0 {
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+// vulnerableSymbols returns the vulnerable functions of the packages the binary has, for the packages listing
+// no functions the packages themselves if the binary has any function of them; packages of other platforms are skipped
+func vulnerableSymbols(bin *cpeparse.GoBinary, funcs map[string]map[string]bool, imports []*schema.Package) []string {
+	var symbols []string
+	for _, pkg := range imports {
+		if !platform(bin.GOOS, pkg.GOOS) || !platform(bin.GOARCH, pkg.GOARCH) {
+			continue
+		}
+		pkgFuncs := funcs[pkg.Path]
+		if len(pkgFuncs) == 0 {
+			continue
+		}
+		if len(pkg.Symbols) == 0 {
+			symbols = append(symbols, pkg.Path)
+			continue
+		}
+		for _, symbol := range pkg.Symbols {
+			if pkgFuncs[symbol] {
+				symbols = append(symbols, pkg.Path+"."+symbol)
+			}
+		}
+	}
+	return symbols
+}
+
+// platform tells whether the platform of the binary is one of the listed, the binaries of unknown platform and
+// the packages listing none are of all the platforms
+func platform(binary string, listed []string) bool {
+	if binary == "" || len(listed) == 0 {
+		return true
+	}
+	for _, p := range listed {
+		if p == binary {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryFuncs maps the packages of the functions of the binary to the functions, named as the Go vulnerability
+// database names them, see SymbolName
+func binaryFuncs(symbols []string) map[string]map[string]bool {
+	funcs := make(map[string]map[string]bool)
+	for _, sym := range symbols {
+		pkg, name := SymbolName(sym)
+		if pkg == "" {
+			continue
+		}
+		if funcs[pkg] == nil {
+			funcs[pkg] = make(map[string]bool)
+		}
+		funcs[pkg][name] = true
+	}
+	return funcs
+}
+
+// SymbolName splits the name of a function of Go binary into the package path and the name of the function
+// as the Go vulnerability database names them: golang.org/x/net/http2.(*Server).ServeConn is Server.ServeConn
+// of golang.org/x/net/http2; type parameters are dropped and closures are named as the functions they're in,
+// e.g. pkg.List[...].Push.func1 is List.Push. Empty package is returned for the symbols which aren't functions
+// of Go packages.
+func SymbolName(sym string) (pkg, name string) {
+	base := sym // type parameters may have slashes of their own
+	if i := strings.IndexByte(sym, '['); i >= 0 {
+		base = sym[:i]
+	}
+	slash := strings.LastIndexByte(base, '/')
+	dot := strings.IndexByte(base[slash+1:], '.')
+	if dot <= 0 {
+		return "", ""
+	}
+	dot += slash + 1
+	pkg, rest := sym[:dot], sym[dot+1:]
+	if strings.IndexByte(pkg, ':') >= 0 { // type:.eq and the other symbols generated by the compiler
+		return "", ""
+	}
+	// the linker escapes dots of the last element of the path, gopkg.in/yaml%2ev3
+	pkg = strings.Replace(pkg, "%2e", ".", -1)
+
+	// drop type parameters
+	var b strings.Builder
+	depth := 0
+	for _, c := range rest {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case depth == 0 && c != '(' && c != ')' && c != '*':
+			b.WriteRune(c)
+		}
+	}
+	var parts []string
+	for _, part := range strings.Split(b.String(), ".") {
+		if part == "" || isClosure(part) {
+			break
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "", ""
+	}
+	return pkg, strings.Join(parts, ".")
+}
+
+// isClosure tells whether the part of function name is the name of a closure, func1, or a number of nested ones
+func isClosure(part string) bool {
+	part = strings.TrimPrefix(part, "func")
+	if part == "" {
+		return false
+	}
+	for _, c := range part {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
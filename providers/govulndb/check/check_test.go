@@ -0,0 +1,145 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	osvSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+const testRecords = `[
+  {
+    "id": "GO-2022-0969",
+    "modified": "2023-06-12T18:45:41Z",
+    "aliases": ["CVE-2022-27664"],
+    "affected": [
+      {
+        "package": {"name": "stdlib", "ecosystem": "Go"},
+        "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.18.6"}, {"introduced": "1.19.0"}, {"fixed": "1.19.1"}]}],
+        "ecosystem_specific": {"imports": [{"path": "net/http", "symbols": ["ListenAndServe", "Server.Serve"]}]}
+      },
+      {
+        "package": {"name": "golang.org/x/net", "ecosystem": "Go"},
+        "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.0.0-20220906165146-f3363e06e74c"}]}],
+        "ecosystem_specific": {"imports": [{"path": "golang.org/x/net/http2", "symbols": ["Server.ServeConn"]}]}
+      }
+    ]
+  },
+  {
+    "id": "GO-2023-0001",
+    "modified": "2023-01-01T00:00:00Z",
+    "affected": [
+      {
+        "package": {"name": "golang.org/x/net", "ecosystem": "Go"},
+        "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.7.0"}]}],
+        "ecosystem_specific": {"imports": [{"path": "golang.org/x/net/html", "symbols": ["Parse"]}]}
+      }
+    ]
+  },
+  {
+    "id": "GO-2023-0002",
+    "modified": "2023-01-01T00:00:00Z",
+    "affected": [
+      {
+        "package": {"name": "github.com/example/lib/v2", "ecosystem": "Go"},
+        "ranges": [{"type": "SEMVER", "events": [{"introduced": "2.1.0"}]}],
+        "ecosystem_specific": {"imports": [{"path": "github.com/example/lib/v2/unix", "goos": ["linux"]}]}
+      }
+    ]
+  },
+  {
+    "id": "GO-2023-0003",
+    "modified": "2023-01-01T00:00:00Z",
+    "withdrawn": "2023-02-01T00:00:00Z",
+    "affected": [{"package": {"name": "stdlib", "ecosystem": "Go"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]
+  }
+]`
+
+func TestBinary(t *testing.T) {
+	var vulns []*osvSchema.Vulnerability
+	if err := json.Unmarshal([]byte(testRecords), &vulns); err != nil {
+		t.Fatal(err)
+	}
+	bin := &cpeparse.GoBinary{
+		GoVersion: "go1.19",
+		Main:      cpeparse.GoModule{Path: "example.com/app", Version: "(devel)"},
+		Deps: []cpeparse.GoModule{
+			{Path: "golang.org/x/net", Version: "v0.0.0-20220127200216-cd36cc0744dd"},
+			{Path: "github.com/example/lib/v2", Version: "v2.3.0+incompatible"},
+		},
+		GOOS: "darwin",
+		Symbols: []string{
+			"golang.org/x/net/http2.(*Server).ServeConn",
+			"golang.org/x/net/http2.(*Server).ServeConn.func1",
+			"github.com/example/lib/v2/unix.Open",
+			"main.main",
+			"net/http.(*Server).Serve",
+			"runtime.main",
+		},
+	}
+
+	want := []*Finding{
+		{ID: "GO-2022-0969", Module: "golang.org/x/net", Version: "0.0.0-20220127200216-cd36cc0744dd",
+			Aliases: []string{"CVE-2022-27664"}, Fixed: "0.0.0-20220906165146-f3363e06e74c", Symbols: []string{"golang.org/x/net/http2.Server.ServeConn"}},
+		{ID: "GO-2022-0969", Module: "stdlib", Version: "1.19.0",
+			Aliases: []string{"CVE-2022-27664"}, Fixed: "1.19.1", Symbols: []string{"net/http.Server.Serve"}},
+	}
+	if got := Binary(bin, vulns, Options{}); !reflect.DeepEqual(got, want) {
+		for _, f := range got {
+			t.Logf("%+v", f)
+		}
+		t.Error("unexpected findings")
+	}
+
+	want = []*Finding{
+		{ID: "GO-2023-0002", Module: "github.com/example/lib/v2", Version: "2.3.0"},
+		{ID: "GO-2022-0969", Module: "golang.org/x/net", Version: "0.0.0-20220127200216-cd36cc0744dd",
+			Aliases: []string{"CVE-2022-27664"}, Fixed: "0.0.0-20220906165146-f3363e06e74c"},
+		{ID: "GO-2023-0001", Module: "golang.org/x/net", Version: "0.0.0-20220127200216-cd36cc0744dd", Fixed: "0.7.0"},
+		{ID: "GO-2022-0969", Module: "stdlib", Version: "1.19.0", Aliases: []string{"CVE-2022-27664"}, Fixed: "1.19.1"},
+	}
+	if got := Binary(bin, vulns, Options{ModuleOnly: true}); !reflect.DeepEqual(got, want) {
+		for _, f := range got {
+			t.Logf("%+v", f)
+		}
+		t.Error("unexpected findings of modules only")
+	}
+
+	bin.GOOS = "linux"
+	if got := Binary(bin, vulns, Options{}); len(got) != 3 || got[0].ID != "GO-2023-0002" || !reflect.DeepEqual(got[0].Symbols, []string{"github.com/example/lib/v2/unix"}) {
+		t.Errorf("expected the package of the platform to be found, got %+v", got)
+	}
+}
+
+func TestSymbolName(t *testing.T) {
+	for sym, want := range map[string][2]string{
+		"golang.org/x/net/http2.(*Server).ServeConn":               {"golang.org/x/net/http2", "Server.ServeConn"},
+		"net/http.ListenAndServe.func1":                            {"net/http", "ListenAndServe"},
+		"golang.org/x/exp/slices.Sort[go.shape.[]string,string]":   {"golang.org/x/exp/slices", "Sort"},
+		"example.com/list.(*List[go.shape.*example.com/x.T]).Push": {"example.com/list", "List.Push"},
+		"main.init.0":                  {"main", "init"},
+		"gopkg.in/yaml%2ev3.Unmarshal": {"gopkg.in/yaml.v3", "Unmarshal"},
+		"_cgo_init":                    {"", ""},
+		"type:.eq.[2]string":           {"", ""},
+	} {
+		if pkg, name := SymbolName(sym); pkg != want[0] || name != want[1] {
+			t.Errorf("%q: expected %q %q, got %q %q", sym, want[0], want[1], pkg, name)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package converter converts OSV records of the Go vulnerability database to NVD CVE JSON 1.0 format
+package converter
+
+import (
+	"sort"
+
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	osvConverter "github.com/facebookincubator/nvdtools/providers/osv/converter"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+var logger = logging.With("provider", "govulndb")
+
+// Convert converts the records to NVD format, see ConvertVulnerability; withdrawn records, the unreviewed ones
+// if reviewedOnly is set, and the ones which can't be converted are skipped
+func Convert(input <-chan *srcSchema.Vulnerability, reviewedOnly bool) *dstSchema.NVDCVEFeedJSON10 {
+	var feed dstSchema.NVDCVEFeedJSON10
+	for vuln := range input {
+		if vuln.Withdrawn != "" || reviewedOnly && !Reviewed(vuln) {
+			continue
+		}
+		converted, err := ConvertVulnerability(vuln)
+		if err != nil {
+			logger.With("id", vuln.ID).Warnf("skipped: %v", err)
+			metrics.ProviderErrors.Inc("govulndb", "convert")
+			continue
+		}
+		metrics.ProviderConverted.Inc("govulndb")
+		feed.CVEItems = append(feed.CVEItems, converted)
+	}
+	sort.Slice(feed.CVEItems, func(i, j int) bool {
+		return feed.CVEItems[i].CVE.CVEDataMeta.ID < feed.CVEItems[j].CVE.CVEDataMeta.ID
+	})
+	return &feed
+}
+
+// ConvertVulnerability converts the record as OSV records are converted (see osv converter), the affected
+// modules become CPE names of Go modules (see cpeparse.FromGoModule), of the standard library and the toolchain
+// golang:go, so the feed matches the CPE names of the modules Go binaries are built of
+func ConvertVulnerability(vuln *srcSchema.Vulnerability) (*dstSchema.NVDCVEFeedJSON10DefCVEItem, error) {
+	item, err := osvConverter.ConvertVulnerability(vuln)
+	if err != nil {
+		return nil, err
+	}
+	item.CVE.CVEDataMeta.ASSIGNER = "govulndb"
+	return item, nil
+}
+
+// Reviewed tells whether the record is reviewed by the Go security team, as opposed to the records imported from
+// other databases unreviewed, database_specific.review_status UNREVIEWED; the older records don't tell, they're reviewed
+func Reviewed(vuln *srcSchema.Vulnerability) bool {
+	status, _ := vuln.DatabaseSpecific["review_status"].(string)
+	return status != "UNREVIEWED"
+}
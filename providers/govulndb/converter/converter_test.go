@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+const testRecord = `{
+  "schema_version": "1.3.1",
+  "id": "GO-2022-0969",
+  "modified": "2023-06-12T18:45:41Z",
+  "published": "2022-09-12T20:23:06Z",
+  "aliases": ["CVE-2022-27664", "GHSA-69cg-p879-7622"],
+  "summary": "Denial of service in net/http and golang.org/x/net/http2",
+  "details": "HTTP/2 server connections can hang forever waiting for a clean shutdown.",
+  "affected": [
+    {
+      "package": {"name": "stdlib", "ecosystem": "Go"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.18.6"}, {"introduced": "1.19.0"}, {"fixed": "1.19.1"}]}],
+      "ecosystem_specific": {"imports": [{"path": "net/http", "symbols": ["ListenAndServe", "Server.Serve"]}]}
+    },
+    {
+      "package": {"name": "golang.org/x/net", "ecosystem": "Go"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.0.0-20220906165146-f3363e06e74c"}]}],
+      "ecosystem_specific": {"imports": [{"path": "golang.org/x/net/http2", "symbols": ["Server.ServeConn"]}]}
+    }
+  ],
+  "references": [{"type": "FIX", "url": "https://go.dev/issue/54658"}],
+  "database_specific": {"url": "https://pkg.go.dev/vuln/GO-2022-0969"}
+}`
+
+func TestConvertVulnerability(t *testing.T) {
+	var vuln srcSchema.Vulnerability
+	if err := json.Unmarshal([]byte(testRecord), &vuln); err != nil {
+		t.Fatal(err)
+	}
+	item, err := ConvertVulnerability(&vuln)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta := item.CVE.CVEDataMeta; meta.ID != "GO-2022-0969" || meta.ASSIGNER != "govulndb" {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+	want := []*dstSchema.NVDCVEFeedJSON10DefCPEMatch{
+		{Cpe23Uri: "cpe:2.3:a:golang:go:*:*:*:*:*:*:*:*", Vulnerable: true, VersionEndExcluding: "1.18.6", FixedVersion: "1.18.6"},
+		{Cpe23Uri: "cpe:2.3:a:golang:go:*:*:*:*:*:*:*:*", Vulnerable: true, VersionStartIncluding: "1.19.0", VersionEndExcluding: "1.19.1", FixedVersion: "1.19.1"},
+		{
+			Cpe23Uri:            "cpe:2.3:a:golang:net:*:*:*:*:*:go:*:*",
+			Vulnerable:          true,
+			VersionEndExcluding: "0.0.0-20220906165146-f3363e06e74c",
+			FixedVersion:        "0.0.0-20220906165146-f3363e06e74c",
+		},
+	}
+	if got := item.Configurations.Nodes[0].CPEMatch; !reflect.DeepEqual(got, want) {
+		for _, m := range got {
+			t.Logf("%+v", m)
+		}
+		t.Error("unexpected CPE matches")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var vuln srcSchema.Vulnerability
+	if err := json.Unmarshal([]byte(testRecord), &vuln); err != nil {
+		t.Fatal(err)
+	}
+	unreviewed := vuln
+	unreviewed.ID = "GO-2024-0001"
+	unreviewed.DatabaseSpecific = map[string]interface{}{"review_status": "UNREVIEWED"}
+	withdrawn := vuln
+	withdrawn.ID, withdrawn.Withdrawn = "GO-2024-0002", "2024-01-01T00:00:00Z"
+
+	for reviewedOnly, want := range map[bool][]string{
+		false: {"GO-2022-0969", "GO-2024-0001"},
+		true:  {"GO-2022-0969"},
+	} {
+		input := make(chan *srcSchema.Vulnerability, 3)
+		input <- &unreviewed
+		input <- &withdrawn
+		input <- &vuln
+		close(input)
+		var ids []string
+		for _, item := range Convert(input, reviewedOnly).CVEItems {
+			ids = append(ids, item.CVE.CVEDataMeta.ID)
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("reviewed only %t: expected %v, got %v", reviewedOnly, want, ids)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema defines the types of the indexes of the Go vulnerability database and the Go specific data
+// of its OSV records, as per https://go.dev/doc/security/vuln/database
+package schema
+
+import (
+	"encoding/json"
+
+	osvSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+// DBMeta is the metadata of the database, index/db.json
+type DBMeta struct {
+	Modified string `json:"modified"`
+}
+
+// ModuleEntry lists the vulnerabilities of a module, an entry of index/modules.json
+type ModuleEntry struct {
+	Path  string        `json:"path"`
+	Vulns []*ModuleVuln `json:"vulns"`
+}
+
+// ModuleVuln is a vulnerability of a module and the latest version of the module which fixes it, if any
+type ModuleVuln struct {
+	ID       string `json:"id"`
+	Modified string `json:"modified"`
+	Fixed    string `json:"fixed,omitempty"`
+}
+
+// VulnEntry is a vulnerability of the database, an entry of index/vulns.json
+type VulnEntry struct {
+	ID       string   `json:"id"`
+	Modified string   `json:"modified"`
+	Aliases  []string `json:"aliases,omitempty"`
+}
+
+// EcosystemSpecific is the Go specific data of an affected module of OSV record
+type EcosystemSpecific struct {
+	Imports []*Package `json:"imports,omitempty"`
+}
+
+// Package is a vulnerable package of the affected module, optionally only on some platforms;
+// Symbols are the vulnerable functions and methods, e.g. Parse, (*Tokenizer).Next as Tokenizer.Next,
+// none means the whole package is
+type Package struct {
+	Path    string   `json:"path"`
+	GOOS    []string `json:"goos,omitempty"`
+	GOARCH  []string `json:"goarch,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// Imports returns the vulnerable packages of the affected module, as recorded in its ecosystem specific data
+func Imports(affected *osvSchema.Affected) ([]*Package, error) {
+	if len(affected.EcosystemSpecific) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(affected.EcosystemSpecific)
+	if err != nil {
+		return nil, err
+	}
+	var specific EcosystemSpecific
+	if err := json.Unmarshal(data, &specific); err != nil {
+		return nil, err
+	}
+	return specific.Imports, nil
+}
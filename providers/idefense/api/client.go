@@ -15,21 +15,27 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
 
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/pkg/errors"
 )
 
+var logger = logging.With("provider", "idefense")
+
 // Client struct
 type Client struct {
 	APIKey string
 	URL    url.URL
+	HTTP   *client.Client
 }
 
 const (
@@ -37,24 +43,32 @@ const (
 	userAgent = "fb-idefense"
 )
 
+// HTTPConfig returns the default config of the HTTP client
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.UserAgent = userAgent
+	return cfg
+}
+
 // NewClient creates an object which is used to query the iDefense API
 func NewClient(u, apiKey string, parameters map[string]interface{}) Client {
 	apiURL, err := url.Parse(u)
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	apiURL.RawQuery = createQuery(parameters).Encode()
 
 	return Client{
 		APIKey: apiKey,
 		URL:    *apiURL,
+		HTTP:   client.MustNew(HTTPConfig()),
 	}
 }
 
 func createQuery(parameters map[string]interface{}) *url.Values {
 	query := url.Values{}
 	for key, value := range parameters {
-		log.Printf("querying with %s = %v\n", key, value)
+		logger.Debugf("querying with %s = %v", key, value)
 		switch v := value.(type) {
 		case string:
 			query.Add(key, v)
@@ -87,13 +101,14 @@ func (client Client) FetchAll() (<-chan *schema.IDefenseVulnerability, error) {
 	numPages := (totalVulns-1)/pageSize + 1
 
 	// fetch pages concurrently
-	log.Printf("starting sync for %d vulnerabilities over %d pages\n", totalVulns, numPages)
+	logger.Infof("starting sync for %d vulnerabilities over %d pages", totalVulns, numPages)
 	wg := sync.WaitGroup{}
 	for page := 1; page <= numPages; page++ {
 		wg.Add(1)
 		go func(p int) {
 			if err := client.fetchPage(p, output); err != nil {
-				log.Println(err)
+				logger.Errorf("%v", err)
+				metrics.ProviderErrors.Inc("idefense", "fetch")
 			}
 			wg.Done()
 		}(page)
@@ -135,9 +150,8 @@ func (client Client) query(params map[string]string) (*schema.IDefenseVulnerabil
 	}
 	u.RawQuery = query.Encode()
 
-	resp, err := queryURL(u.String(), http.Header{
+	resp, err := client.HTTP.Get(context.Background(), u.String(), http.Header{
 		"Auth-Token": {client.APIKey},
-		"User-Agent": {userAgent},
 	})
 	if err != nil {
 		return nil, err
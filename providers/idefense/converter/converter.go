@@ -15,14 +15,16 @@
 package converter
 
 import (
-	"log"
-
 	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
 	srcSchema "github.com/facebookincubator/nvdtools/providers/idefense/schema"
 
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
 	"github.com/pkg/errors"
 )
 
+var logger = logging.With("provider", "idefense")
+
 const (
 	cveDataVersion = "4.0"
 )
@@ -33,9 +35,11 @@ func Convert(input <-chan *srcSchema.IDefenseVulnerability) *dstSchema.NVDCVEFee
 	for vuln := range input {
 		converted, err := convert(vuln)
 		if err != nil {
-			log.Println(err)
+			logger.With("id", vuln.Key).Warnf("skipped: %v", err)
+			metrics.ProviderErrors.Inc("idefense", "convert")
 			continue
 		}
+		metrics.ProviderConverted.Inc("idefense")
 		feed.CVEItems = append(feed.CVEItems, converted)
 	}
 	return &feed
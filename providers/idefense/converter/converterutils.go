@@ -15,7 +15,6 @@
 package converter
 
 import (
-	"log"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
@@ -58,7 +57,7 @@ func findConfigurations(item *schema.IDefenseVulnerability) []configuration {
 	for _, vulnTech := range item.Affects.VulnTechs {
 		attrs, err := createAttributes(vulnTech.Part, vulnTech.Vendor, vulnTech.Product)
 		if err != nil {
-			log.Println(err)
+			logger.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -78,7 +77,7 @@ func findConfigurations(item *schema.IDefenseVulnerability) []configuration {
 	for _, pkg := range item.Affects.Packages {
 		attrs, err := createAttributes("a", "", pkg.PackageName)
 		if err != nil {
-			log.Println(err)
+			logger.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -102,7 +101,7 @@ func findConfigurations(item *schema.IDefenseVulnerability) []configuration {
 	for _, vulnTech := range item.FixedBy.VulnTechs {
 		attrs, err := createAttributes(vulnTech.Part, vulnTech.Vendor, vulnTech.Product)
 		if err != nil {
-			log.Println(err)
+			logger.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -118,7 +117,7 @@ func findConfigurations(item *schema.IDefenseVulnerability) []configuration {
 	for _, pkg := range item.FixedBy.Packages {
 		attrs, err := createAttributes("a", "", pkg.PackageName)
 		if err != nil {
-			log.Println(err)
+			logger.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -0,0 +1,162 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kev provides a converter for CISA Known Exploited Vulnerabilities (KEV) catalog to nvd.
+package kev
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// catalogURL is the human readable catalog entries are referenced to
+const catalogURL = "https://www.cisa.gov/known-exploited-vulnerabilities-catalog"
+
+// Fetch downloads KEV catalog from url (cvefeed.KEVURL if empty) and converts it to NVD CVE JSON 1.0 format.
+func Fetch(ctx context.Context, url string) (*jsonschema.NVDCVEFeedJSON10, error) {
+	return FetchWith(ctx, client.Default(), url)
+}
+
+// FetchWith is like Fetch, but the catalog is downloaded with the client, e.g. through a proxy.
+func FetchWith(ctx context.Context, c *client.Client, url string) (*jsonschema.NVDCVEFeedJSON10, error) {
+	if url == "" {
+		url = cvefeed.KEVURL
+	}
+	resp, err := c.Get(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kev: %v", err)
+	}
+	defer resp.Body.Close()
+	kev, err := cvefeed.ParseKEV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return Convert(kev)
+}
+
+// Convert converts KEV catalog to NVD CVE JSON 1.0 format, sorted by CVE ID.
+// The catalog names vendors and products in prose rather than CPE, so the converted CVEs affect all versions
+// of the products named after them (e.g. cpe:2.3:*:microsoft:windows:*:...) and are meant to flag the inventory
+// for review rather than to replace NVD configurations.
+func Convert(kev cvefeed.KEV) (*jsonschema.NVDCVEFeedJSON10, error) {
+	ids := make([]string, 0, len(kev))
+	for id := range kev {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	feed := &jsonschema.NVDCVEFeedJSON10{
+		CVEDataFormat:       "MITRE",
+		CVEDataType:         "CVE",
+		CVEDataVersion:      "4.0",
+		CVEDataNumberOfCVEs: fmt.Sprint(len(ids)),
+		CVEItems:            make([]*jsonschema.NVDCVEFeedJSON10DefCVEItem, 0, len(ids)),
+	}
+	for _, id := range ids {
+		item, err := ConvertEntry(kev[id])
+		if err != nil {
+			return nil, err
+		}
+		feed.CVEItems = append(feed.CVEItems, item)
+	}
+	return feed, nil
+}
+
+// ConvertEntry converts a KEV catalog entry to NVD CVE JSON 1.0 format, see Convert.
+func ConvertEntry(e *cvefeed.KEVEntry) (*jsonschema.NVDCVEFeedJSON10DefCVEItem, error) {
+	added, err := time.Parse("2006-01-02", e.DateAdded)
+	if err != nil {
+		return nil, fmt.Errorf("kev: %s: malformed dateAdded %q", e.CVEID, e.DateAdded)
+	}
+	conf, err := newConfigurations(e)
+	if err != nil {
+		return nil, err
+	}
+	description := e.Name
+	if e.ShortDescription != "" {
+		description += ": " + e.ShortDescription
+	}
+	return &jsonschema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &jsonschema.CVEJSON40{
+			CVEDataMeta: &jsonschema.CVEJSON40CVEDataMeta{
+				ID:       e.CVEID,
+				ASSIGNER: "CISA",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &jsonschema.CVEJSON40Description{
+				DescriptionData: []*jsonschema.CVEJSON40LangString{
+					{Lang: "en", Value: description},
+				},
+			},
+			Problemtype: newProblemtype(e.CWEs),
+			References: &jsonschema.CVEJSON40References{
+				ReferenceData: []*jsonschema.CVEJSON40Reference{
+					{Name: "CISA Known Exploited Vulnerabilities Catalog", URL: catalogURL, Tags: []string{"US Government Resource"}},
+				},
+			},
+		},
+		Configurations:   conf,
+		LastModifiedDate: added.Format(nvdcommon.TimeLayout),
+		PublishedDate:    added.Format(nvdcommon.TimeLayout),
+	}, nil
+}
+
+func newProblemtype(cwes []string) *jsonschema.CVEJSON40Problemtype {
+	data := &jsonschema.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range cwes {
+		data.Description = append(data.Description, &jsonschema.CVEJSON40LangString{Lang: "en", Value: cwe})
+	}
+	return &jsonschema.CVEJSON40Problemtype{
+		ProblemtypeData: []*jsonschema.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func newConfigurations(e *cvefeed.KEVEntry) (*jsonschema.NVDCVEFeedJSON10DefConfigurations, error) {
+	vendor, err := wfn.WFNize(strings.ToLower(strings.TrimSpace(e.VendorProject)))
+	if err != nil {
+		return nil, fmt.Errorf("kev: %s: cannot wfn-ize vendor %q: %v", e.CVEID, e.VendorProject, err)
+	}
+	product, err := wfn.WFNize(strings.ToLower(strings.TrimSpace(e.Product)))
+	if err != nil {
+		return nil, fmt.Errorf("kev: %s: cannot wfn-ize product %q: %v", e.CVEID, e.Product, err)
+	}
+	if vendor == "" || product == "" {
+		return nil, fmt.Errorf("kev: %s: vendor or product is empty", e.CVEID)
+	}
+	cpe := wfn.Attributes{Part: wfn.Any, Vendor: vendor, Product: product}
+	return &jsonschema.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: "4.0",
+		Nodes: []*jsonschema.NVDCVEFeedJSON10DefNode{
+			{
+				Operator: "OR",
+				CPEMatch: []*jsonschema.NVDCVEFeedJSON10DefCPEMatch{
+					{
+						Cpe23Uri:   cpe.BindToFmtString(),
+						Vulnerable: true,
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testCatalog = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "catalogVersion": "2023.01.01",
+  "count": 2,
+  "vulnerabilities": [
+    {
+      "cveID": "CVE-2020-0002",
+      "vendorProject": "Example Corp",
+      "product": "Widget Server",
+      "vulnerabilityName": "Example Widget Server Remote Code Execution",
+      "dateAdded": "2022-01-10",
+      "shortDescription": "Widget Server allows remote code execution.",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2022-07-10",
+      "cwes": ["CWE-94"]
+    },
+    {
+      "cveID": "CVE-2020-0001",
+      "vendorProject": "Example Corp",
+      "product": "Gadget",
+      "vulnerabilityName": "Example Gadget Privilege Escalation",
+      "dateAdded": "2022-01-11",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2022-07-11"
+    }
+  ]
+}`
+
+func TestConvert(t *testing.T) {
+	kev, err := cvefeed.ParseKEV(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := Convert(kev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 2 || feed.CVEItems[0].CVE.CVEDataMeta.ID != "CVE-2020-0001" {
+		t.Fatalf("expected 2 CVEs sorted by ID, got %d", len(feed.CVEItems))
+	}
+
+	// the converted feed is loadable and matches the products named in the catalog
+	var b bytes.Buffer
+	if err = json.NewEncoder(&b).Encode(feed); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.CVEItem, error) {
+		return cvefeed.ParseJSON(&b)
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpe, err := wfn.Parse("cpe:/a:example_corp:widget_server:2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := cvefeed.NewCache(dict).Get([]*wfn.Attributes{cpe})
+	if len(results) != 1 || results[0].CVE.CVEID() != "CVE-2020-0002" {
+		t.Fatalf("expected a match of CVE-2020-0002, got %v", results)
+	}
+	if cwes := results[0].CVE.ProblemTypes(); len(cwes) != 1 || cwes[0] != "CWE-94" {
+		t.Errorf("unexpected problem types %v", cwes)
+	}
+}
+
+func TestConvertEntryErrors(t *testing.T) {
+	tests := []*cvefeed.KEVEntry{
+		{CVEID: "CVE-2020-0001", VendorProject: "Example", Product: "Widget", DateAdded: "01/10/2022"},
+		{CVEID: "CVE-2020-0001", VendorProject: "Example", DateAdded: "2022-01-10"},
+	}
+	for _, e := range tests {
+		if _, err := ConvertEntry(e); err == nil {
+			t.Errorf("%+v: expected an error", e)
+		}
+	}
+}
+
+func TestFetchWith(t *testing.T) {
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n == 1 { // transient failure, once
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, testCatalog)
+	}))
+	defer srv.Close()
+
+	cfg := client.DefaultConfig()
+	cfg.Backoff = 0
+	feed, err := FetchWith(context.Background(), client.MustNew(cfg), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("want 2 CVEs, have %d", len(feed.CVEItems))
+	}
+	if n != 2 {
+		t.Fatalf("want 2 requests, have %d", n)
+	}
+}
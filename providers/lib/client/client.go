@@ -0,0 +1,297 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client provides the HTTP client shared by providers: requests are
+// rate limited, transient failures are retried with exponential backoff and
+// jitter, and the proxy and the CAs trusted are configurable, e.g. by flags
+// (see Config.AddFlags). Conditional requests are supported by Validators.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/pkg/errors"
+)
+
+var (
+	requestsTotal = metrics.NewCounter("nvdtools_http_requests_total",
+		"HTTP requests of providers and feeds by host and status code, error for requests failed without response.",
+		"host", "code")
+	retriesTotal = metrics.NewCounter("nvdtools_http_retries_total",
+		"Retries of HTTP requests failed transiently, by host.", "host")
+	requestDuration = metrics.NewHistogram("nvdtools_http_request_duration_seconds",
+		"Latency of HTTP requests until response headers, by host; retries are separate requests.", nil, "host")
+)
+
+// maxErrorBody is how much of the body of failed responses is read, e.g. for Error
+const maxErrorBody = 1024 * 1024
+
+// Config configures Client
+type Config struct {
+	// RateLimit is the number of requests allowed per RatePeriod, 0 - unlimited;
+	// requests are spread evenly over the period
+	RateLimit  int
+	RatePeriod time.Duration
+
+	// Retries is the number of retries of requests failed transiently, see Retryable
+	Retries int
+	// Backoff is the delay before the first retry, doubled on every retry up to MaxBackoff;
+	// the delays are jittered, unless the server tells when to retry (Retry-After header), which is
+	// waited for up to MaxBackoff too, so a server asking to retry tomorrow doesn't stall the client
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retryable tells whether the failed response, along with its body (up to 1 MB), can be retried;
+	// nil - 429 Too Many Requests and 5xx gateway and server errors, see DefaultRetryable
+	Retryable func(resp *http.Response, body []byte) bool
+	// RetryAllMethods retries requests of any method, e.g. POST queries which don't change anything.
+	// Otherwise requests of methods other than GET, HEAD, OPTIONS, PUT and DELETE, which may not be
+	// safe to repeat, are retried only if rejected with 429 Too Many Requests, unless they have
+	// Idempotency-Key header (as net/http does)
+	RetryAllMethods bool
+
+	// Timeout limits the time of every request, including reading the body, 0 - no limit
+	Timeout time.Duration
+	// Proxy is the URL of the proxy requests go through, empty - as per the environment (HTTPS_PROXY etc.)
+	Proxy string
+	// CAFile is the path to PEM encoded CA certificates trusted in addition to the ones of the system
+	CAFile string
+
+	// UserAgent is set as User-Agent header of requests which don't set it
+	UserAgent string
+}
+
+// DefaultConfig returns the default configuration: unlimited rate, 3 retries after 1s, 2s and 4s
+// (up to a minute for retries configured above that)
+func DefaultConfig() Config {
+	return Config{
+		RatePeriod: time.Second,
+		Retries:    3,
+		Backoff:    time.Second,
+		MaxBackoff: time.Minute,
+	}
+}
+
+// DefaultRetryable tells whether the failed response can be retried: rate limited (429 Too Many Requests)
+// ones and 500, 502, 503 and 504 server errors
+func DefaultRetryable(resp *http.Response, body []byte) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Client executes HTTP requests as per Config; it's safe for concurrent use,
+// the rate limit is shared by all requests of the client
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	limiter *Limiter
+}
+
+// New creates a client configured as per cfg; it fails only if the proxy URL or the CA file is bad
+func New(cfg Config) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad proxy url")
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read CA file")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates in CA file %q", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = DefaultRetryable
+	}
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		limiter: NewLimiter(cfg.RateLimit, cfg.RatePeriod),
+	}, nil
+}
+
+// MustNew is like New, but panics if the client can't be created; configs without proxy and CA file never fail
+func MustNew(cfg Config) *Client {
+	c, err := New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Default returns a new client configured as per DefaultConfig
+func Default() *Client {
+	return MustNew(DefaultConfig())
+}
+
+// Do executes the request as per the config: it waits for the rate limit and retries transient failures
+// of the requests safe to repeat (see Config.RetryAllMethods), rewinding the body of the request (see http.Request.GetBody, requests with bodies not rewindable aren't
+// retried). Like http.Client.Do, responses of any status are returned without error; failed responses
+// not retried have their body limited to 1 MB. Waits are cancelled with the context of the request.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if c.cfg.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		r, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		last := attempt >= c.cfg.Retries || (req.Body != nil && req.GetBody == nil)
+		repeatable := c.cfg.RetryAllMethods || idempotent(req)
+
+		start := time.Now()
+		resp, err := c.http.Do(r)
+		requestDuration.Since(start, req.URL.Host)
+		if err != nil {
+			requestsTotal.Inc(req.URL.Host, "error")
+			if last || !repeatable || ctx.Err() != nil {
+				return nil, err
+			}
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			retriesTotal.Inc(req.URL.Host)
+			continue
+		}
+		requestsTotal.Inc(req.URL.Host, strconv.Itoa(resp.StatusCode))
+		if resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read http response")
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if last || !c.cfg.Retryable(resp, body) || !repeatable && resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+		delay, ok := retryAfter(resp.Header, time.Now())
+		if !ok {
+			delay = c.backoff(attempt)
+		} else if c.cfg.MaxBackoff > 0 && delay > c.cfg.MaxBackoff {
+			delay = c.cfg.MaxBackoff
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		retriesTotal.Inc(req.URL.Host)
+	}
+}
+
+// Get executes GET request of the url with the header, see Do. Unlike Do, responses of status other than
+// 200 OK (and 304 Not Modified to conditional requests, see Validators) are returned as Error.
+func (c *Client) Get(ctx context.Context, u string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create http get request")
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get url")
+	}
+	if err := CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Error is a response of unexpected status
+type Error struct {
+	Code   int
+	Status string
+	Body   string
+	Header http.Header
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("http error: %s %q", e.Status, e.Body)
+}
+
+// CheckResponse returns nil if the status of the response is 200 OK, or 304 Not Modified to a conditional
+// request; otherwise, the body of the response is read (up to 1 MB) and closed, and returned as *Error
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK || NotModified(resp) {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return errors.Wrap(err, "cannot read http response")
+	}
+	return &Error{Code: resp.StatusCode, Status: resp.Status, Body: string(body), Header: resp.Header}
+}
+
+// idempotent tells whether the request is safe to repeat: of an idempotent method, or with idempotency key
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, key := req.Header["Idempotency-Key"]
+	_, xkey := req.Header["X-Idempotency-Key"]
+	return key || xkey
+}
+
+// rewind returns the request to execute on the attempt, with the body rewound for retries
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot rewind request body")
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
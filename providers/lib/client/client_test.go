@@ -0,0 +1,284 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+)
+
+func TestRetry(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		default:
+			if r.Header.Get("User-Agent") != "test" {
+				http.Error(w, "unexpected user agent", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Backoff = time.Millisecond
+	cfg.UserAgent = "test"
+	c := MustNew(cfg)
+
+	req, err := http.NewRequest("PUT", srv.URL, bytes.NewReader([]byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %q", resp.Status)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("want 3 requests, have %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != "hello" {
+			t.Errorf("request %d: body not rewound: %q", i, body)
+		}
+	}
+
+	// out of retries, the last failed response is returned as error
+	cfg.Retries = 1
+	bodies = nil
+	_, err = MustNew(cfg).Get(context.Background(), srv.URL, nil)
+	if he, ok := err.(*Error); !ok || he.Code != http.StatusTooManyRequests || he.Body != "slow down\n" {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("want 2 requests, have %d", len(bodies))
+	}
+}
+
+func TestRetryMethods(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n == 1 {
+			http.Error(w, "failed", status)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.Backoff = time.Millisecond
+	post := func(cfg Config, header string) int {
+		n = 0
+		req, err := http.NewRequest("POST", srv.URL, bytes.NewReader([]byte("hello")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set(header, "key")
+		}
+		resp, err := MustNew(cfg).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return n
+	}
+	if requests := post(cfg, ""); requests != 1 {
+		t.Errorf("server error of POST: want 1 request, have %d", requests)
+	}
+	if requests := post(cfg, "Idempotency-Key"); requests != 2 {
+		t.Errorf("server error of POST with idempotency key: want 2 requests, have %d", requests)
+	}
+	all := cfg
+	all.RetryAllMethods = true
+	if requests := post(all, ""); requests != 2 {
+		t.Errorf("server error of POST retrying all methods: want 2 requests, have %d", requests)
+	}
+	status = http.StatusTooManyRequests
+	if requests := post(cfg, ""); requests != 2 {
+		t.Errorf("rate limited POST: want 2 requests, have %d", requests)
+	}
+}
+
+func TestRetryAfterLimit(t *testing.T) {
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n == 1 {
+			w.Header().Set("Retry-After", "86400")
+			http.Error(w, "come back tomorrow", http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.MaxBackoff = 10 * time.Millisecond
+	start := time.Now()
+	if _, err := MustNew(cfg).Get(context.Background(), srv.URL, nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || n != 2 {
+		t.Errorf("expected the retry after MaxBackoff, had %d requests in %v", n, elapsed)
+	}
+}
+
+func TestNotRetryable(t *testing.T) {
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	if _, err := Default().Get(context.Background(), srv.URL, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n != 1 {
+		t.Fatalf("want 1 request, have %d", n)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n++; n == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := MustNew(Config{Retries: 1, Backoff: time.Millisecond})
+	if _, err := c.Get(context.Background(), srv.URL, nil); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(srv.URL)
+	var b strings.Builder
+	metrics.Default.Write(&b)
+	for _, line := range []string{
+		`nvdtools_http_requests_total{host="` + u.Host + `",code="503"} 1`,
+		`nvdtools_http_requests_total{host="` + u.Host + `",code="200"} 1`,
+		`nvdtools_http_retries_total{host="` + u.Host + `"} 1`,
+		`nvdtools_http_request_duration_seconds_count{host="` + u.Host + `"} 2`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("metrics expected to have %s", line)
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+
+	c := Default()
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if NotModified(resp) {
+		t.Fatal("unconditional request not modified")
+	}
+	v := ValidatorsOf(resp)
+	if v.ETag != `"v1"` {
+		t.Fatalf("unexpected validators %#v", v)
+	}
+
+	resp, err = c.Get(context.Background(), srv.URL, v.Header())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !NotModified(resp) {
+		t.Fatalf("unexpected status %q", resp.Status)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	l := NewLimiter(10, 100*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first request is immediate, the next ones 10ms apart
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("5 requests took %v, want at least 40ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewLimiter(1, time.Hour).Wait(ctx); err == nil {
+		t.Fatal("expected the wait to be cancelled")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{http.Header{"Retry-After": {"5"}}, 5 * time.Second, true},
+		{http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute, true},
+		{http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1577836810"}}, 10 * time.Second, true},
+		{http.Header{"X-Ratelimit-Remaining": {"1"}, "X-Ratelimit-Reset": {"1577836810"}}, 0, false},
+		{http.Header{}, 0, false},
+	}
+	for i, c := range cases {
+		d, ok := retryAfter(c.header, now)
+		if d != c.want || ok != c.ok {
+			t.Errorf("case %d: want %v/%t, have %v/%t", i, c.want, c.ok, d, ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := MustNew(Config{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond})
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		max *= time.Millisecond
+		if d := c.backoff(attempt); d < max/2 || d > max {
+			t.Errorf("attempt %d: backoff %v out of [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"net/http"
+)
+
+// Validators are the validators of a response (ETag and Last-Modified headers), which conditional
+// requests send back to get the resource only if it changed since, see Set and NotModified
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// ValidatorsOf returns the validators of the response
+func ValidatorsOf(resp *http.Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// IsZero tells whether there are no validators
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Set makes the request conditional on the validators: If-None-Match and If-Modified-Since headers are set
+func (v Validators) Set(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// Header returns the header of a request conditional on the validators, see Set
+func (v Validators) Header() http.Header {
+	req := http.Request{Header: make(http.Header)}
+	v.Set(&req)
+	return req.Header
+}
+
+// NotModified tells whether the response is 304 Not Modified to a conditional request
+func NotModified(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusNotModified
+}
@@ -0,0 +1,31 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"flag"
+)
+
+// AddFlags adds flags configuring the client to the flag set, the values of cfg are the defaults
+func (cfg *Config) AddFlags(fs *flag.FlagSet) {
+	fs.IntVar(&cfg.RateLimit, "rate_limit", cfg.RateLimit, "maximum number of HTTP requests per -rate_period, 0 - unlimited")
+	fs.DurationVar(&cfg.RatePeriod, "rate_period", cfg.RatePeriod, "period of -rate_limit")
+	fs.IntVar(&cfg.Retries, "retries", cfg.Retries, "number of retries of HTTP requests failed transiently, e.g. rate limited")
+	fs.DurationVar(&cfg.Backoff, "backoff", cfg.Backoff, "delay before the first retry, doubled on every retry (with jitter)")
+	fs.DurationVar(&cfg.MaxBackoff, "max_backoff", cfg.MaxBackoff, "maximum delay between retries")
+	fs.DurationVar(&cfg.Timeout, "http_timeout", cfg.Timeout, "timeout of every HTTP request, 0 - no timeout")
+	fs.StringVar(&cfg.Proxy, "proxy", cfg.Proxy, "URL of the proxy of HTTP requests, by default as per HTTPS_PROXY and HTTP_PROXY environment variables")
+	fs.StringVar(&cfg.CAFile, "ca_file", cfg.CAFile, "path to PEM encoded CA certificates to trust in addition to the system ones")
+}
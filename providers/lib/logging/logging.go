@@ -0,0 +1,305 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging provides the leveled logger of the commands and the providers. Messages below the level of
+// the logger are dropped, the others are written one per line, as text or, for log collectors, as JSON objects
+// with the fields attached by With. The level and the format of the default logger are set by flags, see AddFlags.
+package logging
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of messages
+type Level int
+
+// Levels of messages, in the order of severity
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String returns the name of the level, see ParseLevel
+func (l Level) String() string {
+	if l >= Debug && l <= Error {
+		return levelNames[l]
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel returns the level of the name: debug, info, warn (warning) or error
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(s)
+	if s == "warning" {
+		return Warn, nil
+	}
+	for l, name := range levelNames {
+		if s == name {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected one of debug, info, warn or error", s)
+}
+
+// output is the destination of messages, shared by the loggers derived by With
+type output struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	json  bool
+	now   func() time.Time
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes messages of its level or higher; it's safe for concurrent use
+type Logger struct {
+	out    *output
+	fields []field
+}
+
+// New creates new logger writing messages of the level or higher to w, as JSON if json is true or as text otherwise
+func New(w io.Writer, level Level, json bool) *Logger {
+	return &Logger{out: &output{w: w, level: level, json: json, now: time.Now}}
+}
+
+// SetLevel sets the level of the logger and of the loggers derived from it by With
+func (l *Logger) SetLevel(level Level) {
+	l.out.mu.Lock()
+	l.out.level = level
+	l.out.mu.Unlock()
+}
+
+// SetJSON sets the format of the logger and of the loggers derived from it by With: JSON if json is true, text otherwise
+func (l *Logger) SetJSON(json bool) {
+	l.out.mu.Lock()
+	l.out.json = json
+	l.out.mu.Unlock()
+}
+
+// Enabled tells whether messages of the level are written
+func (l *Logger) Enabled(level Level) bool {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	return level >= l.out.level
+}
+
+// With returns a logger writing the field along with the messages, and the fields of l; errors are written as
+// their messages, other values as encoded by encoding/json in JSON format and formatted by %v in text format
+func (l *Logger) With(key string, value interface{}) *Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	return &Logger{out: l.out, fields: append(fields, field{key, value})}
+}
+
+// Debugf writes the message at Debug level
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.write(Debug, fmt.Sprintf(format, args...))
+}
+
+// Infof writes the message at Info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.write(Info, fmt.Sprintf(format, args...))
+}
+
+// Warnf writes the message at Warn level
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.write(Warn, fmt.Sprintf(format, args...))
+}
+
+// Errorf writes the message at Error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.write(Error, fmt.Sprintf(format, args...))
+}
+
+// exit terminates the command after fatal errors, replaced by tests
+var exit = os.Exit
+
+// Fatal writes the message, formatted by fmt.Sprint, at Error level and exits with status 1
+func (l *Logger) Fatal(args ...interface{}) {
+	l.write(Error, fmt.Sprint(args...))
+	exit(1)
+}
+
+// Fatalf writes the message at Error level and exits with status 1
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.write(Error, fmt.Sprintf(format, args...))
+	exit(1)
+}
+
+func (l *Logger) write(level Level, msg string) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	if level < l.out.level {
+		return
+	}
+	now := l.out.now().UTC().Format(time.RFC3339)
+	msg = strings.TrimSuffix(msg, "\n")
+	var b strings.Builder
+	if l.out.json {
+		b.WriteString(`{"time":` + strconv.Quote(now) + `,"level":"` + level.String() + `","msg":` + jsonString(msg))
+		for _, f := range l.fields {
+			b.WriteString("," + jsonString(f.key) + ":" + jsonValue(f.value))
+		}
+		b.WriteString("}\n")
+	} else {
+		b.WriteString(now + " " + strings.ToUpper(level.String()) + " " + msg)
+		for _, f := range l.fields {
+			b.WriteString(" " + f.key + "=" + textValue(f.value))
+		}
+		b.WriteString("\n")
+	}
+	io.WriteString(l.out.w, b.String())
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s) // strings always encode
+	return string(b)
+}
+
+func jsonValue(v interface{}) string {
+	if err, ok := v.(error); ok {
+		return jsonString(err.Error())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return jsonString(fmt.Sprint(v))
+	}
+	return string(b)
+}
+
+// textValue formats the value of the field, quoted if it has spaces, quotes or '='
+func textValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var std = New(os.Stderr, Info, false)
+
+// Default returns the default logger: text messages of Info level or higher written to the standard error,
+// unless configured otherwise by the flags of AddFlags
+func Default() *Logger {
+	return std
+}
+
+// AddFlags adds -log_level and -log_format flags, configuring the default logger, to the flag set
+func AddFlags(fs *flag.FlagSet) {
+	fs.Var(levelFlag{std}, "log_level", "log messages of this level or higher: debug, info, warn or error")
+	fs.Var(formatFlag{std}, "log_format", "format of log messages: text or json (one object per line, for log collectors)")
+}
+
+// levelFlag is the flag.Value setting the level of the logger
+type levelFlag struct {
+	l *Logger
+}
+
+// part of flag.Value interface implementation
+func (f levelFlag) String() string {
+	if f.l == nil {
+		return Info.String()
+	}
+	f.l.out.mu.Lock()
+	defer f.l.out.mu.Unlock()
+	return f.l.out.level.String()
+}
+
+// part of flag.Value interface implementation
+func (f levelFlag) Set(val string) error {
+	level, err := ParseLevel(val)
+	if err != nil {
+		return err
+	}
+	f.l.SetLevel(level)
+	return nil
+}
+
+// formatFlag is the flag.Value setting the format of the logger
+type formatFlag struct {
+	l *Logger
+}
+
+// part of flag.Value interface implementation
+func (f formatFlag) String() string {
+	if f.l != nil && f.l.out.json {
+		return "json"
+	}
+	return "text"
+}
+
+// part of flag.Value interface implementation
+func (f formatFlag) Set(val string) error {
+	switch strings.ToLower(val) {
+	case "text":
+		f.l.SetJSON(false)
+	case "json":
+		f.l.SetJSON(true)
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", val)
+	}
+	return nil
+}
+
+// With returns the default logger writing the field along with the messages, see Logger.With; e.g. the providers
+// log with the logger of With("provider", name), so their messages tell the provider they're of
+func With(key string, value interface{}) *Logger {
+	return std.With(key, value)
+}
+
+// Debugf writes the message at Debug level with the default logger
+func Debugf(format string, args ...interface{}) {
+	std.write(Debug, fmt.Sprintf(format, args...))
+}
+
+// Infof writes the message at Info level with the default logger
+func Infof(format string, args ...interface{}) {
+	std.write(Info, fmt.Sprintf(format, args...))
+}
+
+// Warnf writes the message at Warn level with the default logger
+func Warnf(format string, args ...interface{}) {
+	std.write(Warn, fmt.Sprintf(format, args...))
+}
+
+// Errorf writes the message at Error level with the default logger
+func Errorf(format string, args ...interface{}) {
+	std.write(Error, fmt.Sprintf(format, args...))
+}
+
+// Fatal writes the message at Error level with the default logger and exits with status 1, see Logger.Fatal
+func Fatal(args ...interface{}) {
+	std.Fatal(args...)
+}
+
+// Fatalf writes the message at Error level with the default logger and exits with status 1
+func Fatalf(format string, args ...interface{}) {
+	std.Fatalf(format, args...)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var b strings.Builder
+	l := New(&b, Info, false)
+	l.out.now = func() time.Time { return time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC) }
+
+	l.Debugf("dropped")
+	l.Infof("downloaded %d advisories\n", 3)
+	l.With("id", "GHSA-1").With("reason", "no fixed version").Warnf("skipped")
+	expected := "2023-06-01T10:00:00Z INFO downloaded 3 advisories\n" +
+		"2023-06-01T10:00:00Z WARN skipped id=GHSA-1 reason=\"no fixed version\"\n"
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	l.SetJSON(true)
+	l.SetLevel(Debug)
+	l.With("id", "GHSA-1").With("err", errors.New("bad \"range\"")).With("pages", 2).Debugf("page fetched")
+	expected = `{"time":"2023-06-01T10:00:00Z","level":"debug","msg":"page fetched","id":"GHSA-1","err":"bad \"range\"","pages":2}` + "\n"
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+
+	var status int
+	saved := exit
+	exit = func(code int) { status = code }
+	defer func() { exit = saved }()
+	b.Reset()
+	l.Fatal("no token")
+	if status != 1 || !strings.Contains(b.String(), `"level":"error","msg":"no token"`) {
+		t.Errorf("expected logged error and exit status 1, got %d, %q", status, b.String())
+	}
+}
+
+func TestFlags(t *testing.T) {
+	level, json := std.out.level, std.out.json
+	defer func() {
+		std.SetLevel(level)
+		std.SetJSON(json)
+	}()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs)
+	if err := fs.Parse([]string{"-log_level", "warning", "-log_format", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if !std.Enabled(Warn) || std.Enabled(Info) || !std.out.json {
+		t.Error("flags expected to configure the default logger")
+	}
+	for _, args := range [][]string{{"-log_level", "trace"}, {"-log_format", "xml"}} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(&strings.Builder{})
+		AddFlags(fs)
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("%v expected to be an error", args)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"flag"
+	"net"
+	"net/http"
+)
+
+// Config configures the exposure of the metrics of the default registry
+type Config struct {
+	// Addr is the address (host:port) the metrics are served on at /metrics, empty - not served
+	Addr string
+	// File is the path to the file the metrics are written to when the command is done (see Start),
+	// e.g. in the directory of the textfile collector of node_exporter for commands run by cron; empty - not written
+	File string
+}
+
+// AddFlags adds flags configuring the metrics to the flag set, the values of cfg are the defaults
+func (cfg *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cfg.Addr, "metrics_addr", cfg.Addr, "serve Prometheus metrics on this address (host:port) at /metrics while running; empty disables serving")
+	fs.StringVar(&cfg.File, "metrics_file", cfg.File, "write Prometheus metrics to this file when done, e.g. for the textfile collector of node_exporter; empty disables writing")
+}
+
+// Enabled tells whether the metrics are exposed at all
+func (cfg Config) Enabled() bool {
+	return cfg.Addr != "" || cfg.File != ""
+}
+
+// Start starts serving the metrics of the default registry as per the config. The returned stop function
+// writes the metrics to the file and stops serving, the command must call it when it's done;
+// Start fails only if the address can't be listened on.
+func (cfg Config) Start() (stop func() error, err error) {
+	var srv *http.Server
+	if cfg.Addr != "" {
+		l, err := net.Listen("tcp", cfg.Addr)
+		if err != nil {
+			return nil, err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", Default.Handler())
+		srv = &http.Server{Handler: mux}
+		go srv.Serve(l)
+	}
+	return func() error {
+		if srv != nil {
+			srv.Close()
+		}
+		if cfg.File != "" {
+			return Default.WriteFile(cfg.File)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,345 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides counters, gauges and histograms of the commands and the providers, exposed in
+// Prometheus text format over HTTP (see Handler) or written to a file for the textfile collector of
+// node_exporter (see WriteFile), e.g. as configured by flags (see Config). It's a minimal replacement of the
+// Prometheus client: metrics are registered once and never unregistered, label values are given in the order
+// of the label names.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets are the default buckets of histograms, suited for latencies in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var nameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// metric kinds, as named by TYPE lines of the text format
+const (
+	counterKind   = "counter"
+	gaugeKind     = "gauge"
+	histogramKind = "histogram"
+)
+
+// family is a metric along with its series, one per distinct combination of label values
+type family struct {
+	name, help, kind string
+	labels           []string
+	buckets          []float64 // upper bounds of histogram buckets, sorted, +Inf excluded
+
+	mu     sync.Mutex
+	series map[string]*series // by label values joined by 0xff
+}
+
+type series struct {
+	labels []string
+	value  float64  // the value of counters and gauges, the sum of histograms
+	counts []uint64 // observations of histograms per bucket, not cumulative; the last one is +Inf bucket
+	count  uint64
+}
+
+// get returns the series of the label values, creating it if needed; the family must be locked
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: append([]string(nil), labelValues...)}
+		if f.kind == histogramKind {
+			s.counts = make([]uint64, len(f.buckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is a metric which only goes up, e.g. the number of requests
+type Counter struct {
+	f *family
+}
+
+// Add adds v to the series of the label values; it panics if v is negative
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can't decrease", c.f.name))
+	}
+	c.f.mu.Lock()
+	c.f.get(labelValues).value += v
+	c.f.mu.Unlock()
+}
+
+// Inc adds 1 to the series of the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Gauge is a metric which goes up and down, e.g. the number of CVEs loaded
+type Gauge struct {
+	f *family
+}
+
+// Set sets the series of the label values to v
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	g.f.get(labelValues).value = v
+	g.f.mu.Unlock()
+}
+
+// Add adds v, which might be negative, to the series of the label values
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	g.f.get(labelValues).value += v
+	g.f.mu.Unlock()
+}
+
+// SetToCurrentTime sets the series of the label values to the current Unix time in seconds
+func (g *Gauge) SetToCurrentTime(labelValues ...string) {
+	g.Set(float64(time.Now().UnixNano())/1e9, labelValues...)
+}
+
+// Histogram is a metric which counts observations in buckets, e.g. latencies
+type Histogram struct {
+	f *family
+}
+
+// Observe adds the observation v to the series of the label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	i := sort.SearchFloat64s(h.f.buckets, v) // the first bucket v is less or equal to
+	h.f.mu.Lock()
+	s := h.f.get(labelValues)
+	s.counts[i]++
+	s.count++
+	s.value += v
+	h.f.mu.Unlock()
+}
+
+// Since observes the time elapsed since start, in seconds
+func (h *Histogram) Since(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Registry is a set of metrics, written by Write in Prometheus text format; it's safe for concurrent use
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// register registers the family; it panics if the name or the labels aren't valid, or the name is taken
+func (r *Registry) register(f *family) *family {
+	if !nameRE.MatchString(f.name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", f.name))
+	}
+	for _, l := range f.labels {
+		if !nameRE.MatchString(l) || strings.Contains(l, ":") || strings.HasPrefix(l, "__") || l == "le" {
+			panic(fmt.Sprintf("metrics: %s: invalid label name %q", f.name, l))
+		}
+	}
+	f.series = map[string]*series{}
+	if len(f.labels) == 0 {
+		f.get(nil) // metrics without labels are exposed from the start
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[f.name]; ok {
+		panic(fmt.Sprintf("metrics: metric %s is already registered", f.name))
+	}
+	r.families[f.name] = f
+	return f
+}
+
+// NewCounter registers new counter with the label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(&family{name: name, help: help, kind: counterKind, labels: labels})}
+}
+
+// NewGauge registers new gauge with the label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(&family{name: name, help: help, kind: gaugeKind, labels: labels})}
+}
+
+// NewHistogram registers new histogram with the buckets (their upper bounds, DefBuckets if empty) and the label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	if math.IsInf(buckets[len(buckets)-1], +1) {
+		buckets = buckets[:len(buckets)-1] // +Inf bucket is always there
+	}
+	return &Histogram{r.register(&family{name: name, help: help, kind: histogramKind, labels: labels, buckets: buckets})}
+}
+
+// Write writes the metrics in Prometheus text exposition format, sorted by name and label values
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]*family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.Unlock()
+	var b strings.Builder
+	for _, f := range families {
+		f.write(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *family) write(b *strings.Builder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.name, f.kind)
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != histogramKind {
+			fmt.Fprintf(b, "%s%s %s\n", f.name, labelPairs(f.labels, s.labels, ""), formatFloat(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, n := range s.counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(f.buckets) {
+				le = formatFloat(f.buckets[i])
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, labelPairs(f.labels, s.labels, le), cumulative)
+		}
+		fmt.Fprintf(b, "%s_sum%s %s\n", f.name, labelPairs(f.labels, s.labels, ""), formatFloat(s.value))
+		fmt.Fprintf(b, "%s_count%s %d\n", f.name, labelPairs(f.labels, s.labels, ""), s.count)
+	}
+}
+
+// labelPairs formats the labels of a series, along with le label of histogram buckets if it's not empty
+func labelPairs(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler returns HTTP handler serving the metrics, e.g. to be scraped by Prometheus
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// WriteFile writes the metrics to the file, replacing it atomically, so the textfile collector never reads
+// partially written metrics
+func (r *Registry) WriteFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err = r.Write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Chmod(0644); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Default is the registry of the metrics of the commands and the providers
+var Default = NewRegistry()
+
+// NewCounter registers new counter in the default registry, see Registry.NewCounter
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewGauge registers new gauge in the default registry, see Registry.NewGauge
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewHistogram registers new histogram in the default registry, see Registry.NewHistogram
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests by code.", "host", "code")
+	loaded := r.NewGauge("cves_loaded", "CVEs loaded,\nby all feeds.")
+	latency := r.NewHistogram("match_duration_seconds", "Match latency.", []float64{0.5, 0.1})
+
+	requests.Inc("example.com", "200")
+	requests.Add(2, "example.com", "200")
+	requests.Inc(`a"b`, "error")
+	loaded.Set(42)
+	loaded.Add(-2)
+	latency.Observe(0.1)
+	latency.Observe(0.3)
+	latency.Observe(2)
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP cves_loaded CVEs loaded,\nby all feeds.
+# TYPE cves_loaded gauge
+cves_loaded 40
+# HELP match_duration_seconds Match latency.
+# TYPE match_duration_seconds histogram
+match_duration_seconds_bucket{le="0.1"} 1
+match_duration_seconds_bucket{le="0.5"} 2
+match_duration_seconds_bucket{le="+Inf"} 3
+match_duration_seconds_sum 2.4
+match_duration_seconds_count 3
+# HELP requests_total Requests by code.
+# TYPE requests_total counter
+requests_total{host="a\"b",code="error"} 1
+requests_total{host="example.com",code="200"} 3
+`
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if rec.Body.String() != expected {
+		t.Errorf("handler expected to serve the metrics, got\n%s", rec.Body.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "nvdtools.prom")
+	if err := r.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != expected {
+		t.Errorf("file expected to have the metrics, got %q (%v)", data, err)
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "", "code")
+	for name, fn := range map[string]func(){
+		"duplicate":     func() { r.NewGauge("requests_total", "") },
+		"invalid name":  func() { r.NewGauge("requests-total", "") },
+		"invalid label": func() { r.NewGauge("gauge", "", "le") },
+		"label values":  func() { c.Inc() },
+		"decrease":      func() { c.Add(-1, "200") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s expected to panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
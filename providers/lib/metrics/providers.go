@@ -0,0 +1,24 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+// Metrics of the providers, by the name of the provider; HTTP requests of their APIs are counted by the client,
+// see providers/lib/client
+var (
+	ProviderErrors = NewCounter("nvdtools_provider_errors_total",
+		"Vulnerabilities providers failed to fetch or convert (stage), skipped; by provider and stage.", "provider", "stage")
+	ProviderConverted = NewCounter("nvdtools_provider_converted_total",
+		"Vulnerabilities converted to NVD format, by provider.", "provider")
+)
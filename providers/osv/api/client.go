@@ -0,0 +1,254 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package api downloads OSV records from OSV.dev, either the exports of whole ecosystems or the records
+// of the packages queried with the batch API
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.With("provider", "osv")
+
+const (
+	// DataURL is where OSV.dev publishes the exports of ecosystems, {DataURL}/{ecosystem}/all.zip
+	DataURL = "https://osv-vulnerabilities.storage.googleapis.com"
+	// APIURL is the endpoint of OSV.dev API
+	APIURL = "https://api.osv.dev/v1"
+
+	userAgent = "nvdtools-osv"
+	// batchSize is the maximum number of queries of a request to the querybatch endpoint
+	batchSize = 1000
+)
+
+// Client downloads OSV records
+type Client struct {
+	DataURL string
+	APIURL  string
+	HTTP    *client.Client
+}
+
+// HTTPConfig returns the default config of the HTTP client; the queries posted change nothing, so they're
+// retried like the other requests
+func HTTPConfig() client.Config {
+	cfg := client.DefaultConfig()
+	cfg.RetryAllMethods = true
+	cfg.UserAgent = userAgent
+	return cfg
+}
+
+// NewClient creates a client of OSV.dev
+func NewClient() *Client {
+	return &Client{DataURL: DataURL, APIURL: APIURL, HTTP: client.MustNew(HTTPConfig())}
+}
+
+// FetchEcosystem downloads the export of all records of the ecosystem (e.g. PyPI, Go, npm, crates.io);
+// the records are sent to the channel as they are decoded, the errors decoding them are logged
+func (c *Client) FetchEcosystem(ctx context.Context, ecosystem string) (<-chan *schema.Vulnerability, error) {
+	u := strings.TrimSuffix(c.DataURL, "/") + "/" + url.PathEscape(ecosystem) + "/all.zip"
+	resp, err := c.do(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download %s export", ecosystem)
+	}
+	defer resp.Body.Close()
+
+	// zip archives are read from the end, so the export is spooled to a file rather than kept in memory
+	f, err := ioutil.TempFile("", "osv-"+path.Base(ecosystem)+"-*.zip")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary file")
+	}
+	defer os.Remove(f.Name())
+	size, err := io.Copy(f, resp.Body)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "failed to download %s export", ecosystem)
+	}
+	return readArchive(f, size)
+}
+
+// ReadArchive reads the records of an export of OSV.dev downloaded before, e.g. PyPI/all.zip
+func ReadArchive(filename string) (<-chan *schema.Vulnerability, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return readArchive(f, info.Size())
+}
+
+// readArchive sends the records of zip archive to the channel, f is closed when they are all sent
+func readArchive(f *os.File, size int64) (<-chan *schema.Vulnerability, error) {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "failed to read the export")
+	}
+	output := make(chan *schema.Vulnerability)
+	go func() {
+		defer close(output)
+		defer f.Close()
+		for _, file := range zr.File {
+			if !strings.HasSuffix(file.Name, ".json") {
+				continue
+			}
+			vuln, err := readRecord(file)
+			if err != nil {
+				logger.With("file", file.Name).Warnf("skipped: %v", err)
+				metrics.ProviderErrors.Inc("osv", "fetch")
+				continue
+			}
+			output <- vuln
+		}
+	}()
+	return output, nil
+}
+
+func readRecord(file *zip.File) (*schema.Vulnerability, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var vuln schema.Vulnerability
+	if err := json.NewDecoder(r).Decode(&vuln); err != nil {
+		return nil, errors.Wrap(err, "failed to decode record")
+	}
+	return &vuln, nil
+}
+
+// QueryBatch returns the records of vulnerabilities matching any of the queries, each record once;
+// the batch API only returns the IDs, so the records are fetched one by one afterwards
+func (c *Client) QueryBatch(ctx context.Context, queries []*schema.Query) ([]*schema.Vulnerability, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for len(queries) != 0 {
+		n := len(queries)
+		if n > batchSize {
+			n = batchSize
+		}
+		batch := queries[:n]
+		queries = queries[n:]
+
+		var resp schema.BatchResponse
+		if err := c.post(ctx, "/querybatch", &schema.BatchQuery{Queries: batch}, &resp); err != nil {
+			return nil, errors.Wrap(err, "failed to query batch")
+		}
+		for i, result := range resp.Results {
+			if result == nil {
+				continue
+			}
+			for _, vuln := range result.Vulns {
+				if vuln != nil && !seen[vuln.ID] {
+					seen[vuln.ID] = true
+					ids = append(ids, vuln.ID)
+				}
+			}
+			if result.NextPageToken != "" && i < len(batch) {
+				next := *batch[i]
+				next.PageToken = result.NextPageToken
+				queries = append(queries, &next)
+			}
+		}
+	}
+
+	vulns := make([]*schema.Vulnerability, 0, len(ids))
+	for _, id := range ids {
+		vuln, err := c.Vulnerability(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		vulns = append(vulns, vuln)
+	}
+	return vulns, nil
+}
+
+// Vulnerability returns the record of the vulnerability, e.g. GHSA-xxxx-xxxx-xxxx or PYSEC-2021-1
+func (c *Client) Vulnerability(ctx context.Context, id string) (*schema.Vulnerability, error) {
+	resp, err := c.do(ctx, "GET", strings.TrimSuffix(c.APIURL, "/")+"/vulns/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", id)
+	}
+	defer resp.Body.Close()
+	var vuln schema.Vulnerability
+	if err := json.NewDecoder(resp.Body).Decode(&vuln); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", id)
+	}
+	return &vuln, nil
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "POST", strings.TrimSuffix(c.APIURL, "/")+endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
+
+// do executes the request and returns the response if its status is HTTP OK
+func (c *Client) do(ctx context.Context, method, u string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create http request")
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = client.MustNew(HTTPConfig())
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get url")
+	}
+	if err := client.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
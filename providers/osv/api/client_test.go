@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"PYSEC-2021-1.json": `{"id": "PYSEC-2021-1", "modified": "2021-01-01T00:00:00Z"}`,
+		"PYSEC-2021-2.json": `{"id": "PYSEC-2021-2", "modified": "2021-01-02T00:00:00Z"}`,
+		"broken.json":       `{"id": `,
+		"README":            "not a record",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/PyPI/all.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	})
+	mux.HandleFunc("/v1/querybatch", func(w http.ResponseWriter, r *http.Request) {
+		var query schema.BatchQuery
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var resp schema.BatchResponse
+		for _, q := range query.Queries {
+			switch {
+			case q.Package.Purl == "pkg:pypi/django@3.2.0" && q.PageToken == "":
+				resp.Results = append(resp.Results, &schema.BatchResult{
+					Vulns:         []*schema.Vulnerability{{ID: "PYSEC-2021-1"}},
+					NextPageToken: "next",
+				})
+			case q.Package.Purl == "pkg:pypi/django@3.2.0":
+				resp.Results = append(resp.Results, &schema.BatchResult{Vulns: []*schema.Vulnerability{{ID: "PYSEC-2021-2"}}})
+			default:
+				resp.Results = append(resp.Results, &schema.BatchResult{Vulns: []*schema.Vulnerability{{ID: "PYSEC-2021-1"}}})
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/v1/vulns/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/v1/vulns/"):]
+		json.NewEncoder(w).Encode(schema.Vulnerability{ID: id, Summary: "summary of " + id})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetchEcosystem(t *testing.T) {
+	srv := testServer(t)
+	defer srv.Close()
+	client := NewClient()
+	client.DataURL = srv.URL
+
+	vulns, err := client.FetchEcosystem(context.Background(), "PyPI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for vuln := range vulns {
+		ids = append(ids, vuln.ID)
+	}
+	sort.Strings(ids)
+	if len(ids) != 2 || ids[0] != "PYSEC-2021-1" || ids[1] != "PYSEC-2021-2" {
+		t.Errorf("unexpected records %v", ids)
+	}
+
+	if _, err := client.FetchEcosystem(context.Background(), "Unknown"); err == nil {
+		t.Error("expected an error for unknown ecosystem")
+	}
+}
+
+func TestQueryBatch(t *testing.T) {
+	srv := testServer(t)
+	defer srv.Close()
+	client := NewClient()
+	client.APIURL = srv.URL + "/v1"
+
+	vulns, err := client.QueryBatch(context.Background(), []*schema.Query{
+		{Package: &schema.Package{Purl: "pkg:pypi/django@3.2.0"}},
+		{Package: &schema.Package{Purl: "pkg:pypi/flask@1.0"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 2 || vulns[0].ID != "PYSEC-2021-1" || vulns[1].ID != "PYSEC-2021-2" {
+		t.Fatalf("unexpected records %+v", vulns)
+	}
+	if vulns[0].Summary != "summary of PYSEC-2021-1" {
+		t.Errorf("expected full record, got %+v", vulns[0])
+	}
+}
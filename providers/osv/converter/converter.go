@@ -0,0 +1,346 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package converter converts OSV records to NVD CVE JSON 1.0 format
+package converter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/cvss"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/metrics"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.With("provider", "osv")
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// Convert converts OSV records to NVD format; withdrawn records and the ones which can't be converted
+// (e.g. with no affected packages of known ecosystems) are logged and skipped
+func Convert(input <-chan *srcSchema.Vulnerability) *dstSchema.NVDCVEFeedJSON10 {
+	var feed dstSchema.NVDCVEFeedJSON10
+	for vuln := range input {
+		if vuln.Withdrawn != "" {
+			continue
+		}
+		converted, err := ConvertVulnerability(vuln)
+		if err != nil {
+			logger.With("id", vuln.ID).Warnf("skipped: %v", err)
+			metrics.ProviderErrors.Inc("osv", "convert")
+			continue
+		}
+		metrics.ProviderConverted.Inc("osv")
+		feed.CVEItems = append(feed.CVEItems, converted)
+	}
+	sort.Slice(feed.CVEItems, func(i, j int) bool {
+		return feed.CVEItems[i].CVE.CVEDataMeta.ID < feed.CVEItems[j].CVE.CVEDataMeta.ID
+	})
+	return &feed
+}
+
+// ConvertVulnerability converts OSV record to NVD format: the affected packages become CPE names
+// of applications named after the packages, of any vendor, and the ranges of affected versions become
+// version ranges of the CPE matches; the ID of the record is kept, the aliases (e.g. CVE IDs) become references.
+func ConvertVulnerability(vuln *srcSchema.Vulnerability) (*dstSchema.NVDCVEFeedJSON10DefCVEItem, error) {
+	modified, err := convertTime(vuln.Modified)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can't convert modified date", vuln.ID)
+	}
+	published := modified
+	if vuln.Published != "" {
+		if published, err = convertTime(vuln.Published); err != nil {
+			return nil, errors.Wrapf(err, "%s: can't convert published date", vuln.ID)
+		}
+	}
+
+	configurations, err := makeConfigurations(vuln)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can't create configurations", vuln.ID)
+	}
+
+	description := vuln.Details
+	if description == "" {
+		description = vuln.Summary
+	}
+
+	return &dstSchema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &dstSchema.CVEJSON40{
+			CVEDataMeta: &dstSchema.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID,
+				ASSIGNER: "osv",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &dstSchema.CVEJSON40Description{
+				DescriptionData: []*dstSchema.CVEJSON40LangString{
+					{Lang: "en", Value: description},
+				},
+			},
+			Problemtype: makeProblemtype(vuln),
+			References:  makeReferences(vuln),
+		},
+		Configurations:   configurations,
+		Impact:           makeImpact(vuln),
+		LastModifiedDate: modified,
+		PublishedDate:    published,
+	}, nil
+}
+
+func convertTime(osvTime string) (string, error) {
+	t, err := time.Parse(time.RFC3339Nano, osvTime)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(nvdcommon.TimeLayout), nil
+}
+
+func makeReferences(vuln *srcSchema.Vulnerability) *dstSchema.CVEJSON40References {
+	var refsData []*dstSchema.CVEJSON40Reference
+	for _, alias := range vuln.Aliases {
+		refsData = append(refsData, &dstSchema.CVEJSON40Reference{Name: alias})
+	}
+	for _, ref := range vuln.References {
+		refsData = append(refsData, &dstSchema.CVEJSON40Reference{
+			Name: ref.URL,
+			URL:  ref.URL,
+			Tags: []string{ref.Type},
+		})
+	}
+	if len(refsData) == 0 {
+		return nil
+	}
+	return &dstSchema.CVEJSON40References{ReferenceData: refsData}
+}
+
+// makeProblemtype returns CWEs of the vulnerability, as reported by GitHub advisories in database_specific.cwe_ids
+func makeProblemtype(vuln *srcSchema.Vulnerability) *dstSchema.CVEJSON40Problemtype {
+	ids, _ := vuln.DatabaseSpecific["cwe_ids"].([]interface{})
+	var descriptions []*dstSchema.CVEJSON40LangString
+	for _, id := range ids {
+		if cwe, ok := id.(string); ok && cwe != "" {
+			descriptions = append(descriptions, &dstSchema.CVEJSON40LangString{Lang: "en", Value: cwe})
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	return &dstSchema.CVEJSON40Problemtype{
+		ProblemtypeData: []*dstSchema.CVEJSON40ProblemtypeProblemtypeData{
+			{Description: descriptions},
+		},
+	}
+}
+
+// makeImpact returns CVSS v2 and v3 scores of the vectors of the vulnerability, vectors which don't parse are skipped
+func makeImpact(vuln *srcSchema.Vulnerability) *dstSchema.NVDCVEFeedJSON10DefImpact {
+	severities := vuln.Severity
+	for _, affected := range vuln.Affected {
+		severities = append(severities, affected.Severity...)
+	}
+	var impact dstSchema.NVDCVEFeedJSON10DefImpact
+	for _, severity := range severities {
+		if severity == nil {
+			continue
+		}
+		switch severity.Type {
+		case "CVSS_V2":
+			if impact.BaseMetricV2 != nil {
+				continue
+			}
+			score, _, err := cvss.ScoreAndSeverity(severity.Score)
+			if err != nil {
+				logger.With("id", vuln.ID).Warnf("%v", err)
+				continue
+			}
+			impact.BaseMetricV2 = &dstSchema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+				CVSSV2: &dstSchema.CVSSV20{
+					BaseScore:    score,
+					VectorString: severity.Score,
+					Version:      "2.0",
+				},
+			}
+		case "CVSS_V3":
+			if impact.BaseMetricV3 != nil {
+				continue
+			}
+			score, sev, err := cvss.ScoreAndSeverity(severity.Score)
+			if err != nil {
+				logger.With("id", vuln.ID).Warnf("%v", err)
+				continue
+			}
+			impact.BaseMetricV3 = &dstSchema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3: &dstSchema.CVSSV30{
+					BaseScore:    score,
+					BaseSeverity: strings.ToUpper(sev.String()),
+					VectorString: severity.Score,
+					Version:      strings.TrimPrefix(strings.SplitN(severity.Score, "/", 2)[0], "CVSS:"),
+				},
+			}
+		}
+	}
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil
+	}
+	return &impact
+}
+
+func makeConfigurations(vuln *srcSchema.Vulnerability) (*dstSchema.NVDCVEFeedJSON10DefConfigurations, error) {
+	var matches []*dstSchema.NVDCVEFeedJSON10DefCPEMatch
+	for _, affected := range vuln.Affected {
+		if affected.Package == nil {
+			continue
+		}
+		attrs, err := packageAttributes(affected.Package)
+		if err != nil {
+			logger.With("id", vuln.ID).Warnf("%v", err)
+			continue
+		}
+		matches = append(matches, makeMatches(attrs, affected)...)
+	}
+	if len(matches) == 0 {
+		return nil, errors.New("unable to find any affected packages in data")
+	}
+
+	return &dstSchema.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes: []*dstSchema.NVDCVEFeedJSON10DefNode{
+			{
+				CPEMatch: matches,
+				Operator: "OR",
+			},
+		},
+	}, nil
+}
+
+// makeMatches returns CPE matches of the affected versions of the package: one per range of SEMVER and ECOSYSTEM
+// ranges, or one per listed version if there are none; GIT ranges of commits can't be matched against CPE names
+func makeMatches(attrs *wfn.Attributes, affected *srcSchema.Affected) []*dstSchema.NVDCVEFeedJSON10DefCPEMatch {
+	cpe23uri := attrs.BindToFmtString()
+	newMatch := func() *dstSchema.NVDCVEFeedJSON10DefCPEMatch {
+		return &dstSchema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: cpe23uri, Vulnerable: true}
+	}
+
+	var matches []*dstSchema.NVDCVEFeedJSON10DefCPEMatch
+	for _, r := range affected.Ranges {
+		if r == nil || (r.Type != "SEMVER" && r.Type != "ECOSYSTEM") {
+			continue
+		}
+		var match *dstSchema.NVDCVEFeedJSON10DefCPEMatch // the range open at the moment
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				match = newMatch()
+				if event.Introduced != "0" {
+					match.VersionStartIncluding = event.Introduced
+				}
+			case event.Fixed != "" && match != nil:
+				match.VersionEndExcluding = event.Fixed
+				match.FixedVersion = event.Fixed
+				matches = append(matches, match)
+				match = nil
+			case event.LastAffected != "" && match != nil:
+				match.VersionEndIncluding = event.LastAffected
+				matches = append(matches, match)
+				match = nil
+			}
+		}
+		if match != nil { // no fix yet
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) != 0 {
+		return matches
+	}
+
+	for _, version := range affected.Versions {
+		v, err := wfn.WFNize(version)
+		if err != nil {
+			continue
+		}
+		cpe := *attrs
+		cpe.Version = v
+		matches = append(matches, &dstSchema.NVDCVEFeedJSON10DefCPEMatch{
+			Cpe23Uri:   cpe.BindToFmtString(),
+			Vulnerable: true,
+		})
+	}
+	return matches
+}
+
+// ecosystemTypes maps OSV ecosystems to the types of package URLs
+var ecosystemTypes = map[string]string{
+	"crates.io": "cargo",
+	"Go":        "golang",
+	"Hex":       "hex",
+	"Maven":     "maven",
+	"npm":       "npm",
+	"NuGet":     "nuget",
+	"Packagist": "composer",
+	"Pub":       "pub",
+	"PyPI":      "pypi",
+	"RubyGems":  "gem",
+}
+
+// PackageURL returns the package URL of the package: the one of the record or, failing that,
+// the one derived from the ecosystem and the name
+func PackageURL(pkg *srcSchema.Package) (*purl.PURL, error) {
+	if pkg.Purl != "" {
+		return purl.Parse(pkg.Purl)
+	}
+	typ, ok := ecosystemTypes[strings.SplitN(pkg.Ecosystem, ":", 2)[0]]
+	if !ok {
+		return nil, errors.Errorf("unknown ecosystem %q of package %q", pkg.Ecosystem, pkg.Name)
+	}
+	p := &purl.PURL{Type: typ, Name: pkg.Name}
+	sep := "/"
+	if typ == "maven" {
+		sep = ":" // group:artifact
+	}
+	if i := strings.LastIndex(pkg.Name, sep); i >= 0 {
+		p.Namespace, p.Name = pkg.Name[:i], pkg.Name[i+1:]
+	}
+	return purl.Parse(p.String()) // normalized
+}
+
+// packageAttributes returns CPE name of the package of any version: Go modules are named as cpeparse.FromGoModule
+// names them, so they match the CPE names of the modules of Go binaries; the other packages are applications
+// of any vendor named after the packages
+func packageAttributes(pkg *srcSchema.Package) (*wfn.Attributes, error) {
+	if pkg.Ecosystem == "Go" {
+		return cpeparse.FromGoModule(pkg.Name, "")
+	}
+	p, err := PackageURL(pkg)
+	if err != nil {
+		return nil, err
+	}
+	product, err := wfn.WFNize(strings.ToLower(p.Name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot wfn-ize: %q", p.Name)
+	}
+	return &wfn.Attributes{Part: "a", Vendor: wfn.Any, Product: product, Version: wfn.Any}, nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	dstSchema "github.com/facebookincubator/nvdtools/cvefeed/jsonschema"
+	srcSchema "github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+const testRecord = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "modified": "2023-11-01T12:00:00.123Z",
+  "published": "2021-02-15T21:00:00Z",
+  "aliases": ["CVE-2021-23337"],
+  "summary": "Command Injection in lodash",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [
+    {
+      "package": {"ecosystem": "npm", "name": "lodash", "purl": "pkg:npm/lodash"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+    },
+    {
+      "package": {"ecosystem": "npm", "name": "lodash-es"},
+      "ranges": [
+        {"type": "GIT", "repo": "https://github.com/lodash/lodash", "events": [{"introduced": "0"}, {"fixed": "c4847eb"}]},
+        {"type": "ECOSYSTEM", "events": [{"introduced": "4.0.0"}, {"last_affected": "4.17.20"}, {"introduced": "5.0.0"}]}
+      ]
+    },
+    {
+      "package": {"ecosystem": "PyPI", "name": "Not_Lodash"},
+      "versions": ["1.0", "1.1"]
+    },
+    {
+      "package": {"ecosystem": "Go", "name": "github.com/lodash/lodash/v4"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+    },
+    {
+      "package": {"ecosystem": "Unknown", "name": "lodash"},
+      "versions": ["1.0"]
+    }
+  ],
+  "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}],
+  "database_specific": {"cwe_ids": ["CWE-77", "CWE-94"]}
+}`
+
+func TestConvertVulnerability(t *testing.T) {
+	var vuln srcSchema.Vulnerability
+	if err := json.Unmarshal([]byte(testRecord), &vuln); err != nil {
+		t.Fatal(err)
+	}
+	item, err := ConvertVulnerability(&vuln)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := item.CVE.CVEDataMeta.ID; id != "GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("unexpected ID %q", id)
+	}
+	if item.PublishedDate != "2021-02-15T21:00Z" || item.LastModifiedDate != "2023-11-01T12:00Z" {
+		t.Errorf("unexpected dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if desc := item.CVE.Description.DescriptionData[0].Value; desc != "Command Injection in lodash" {
+		t.Errorf("unexpected description %q", desc)
+	}
+	if v3 := item.Impact.BaseMetricV3.CVSSV3; v3.BaseScore != 7.2 || v3.BaseSeverity != "HIGH" || v3.Version != "3.1" {
+		t.Errorf("unexpected CVSS v3 %+v", v3)
+	}
+	if item.Impact.BaseMetricV2 != nil {
+		t.Errorf("unexpected CVSS v2 %+v", item.Impact.BaseMetricV2)
+	}
+	if cwes := item.CVE.Problemtype.ProblemtypeData[0].Description; len(cwes) != 2 || cwes[1].Value != "CWE-94" {
+		t.Errorf("unexpected problem types %+v", cwes)
+	}
+	if refs := item.CVE.References.ReferenceData; len(refs) != 2 || refs[0].Name != "CVE-2021-23337" {
+		t.Errorf("unexpected references %+v", refs)
+	}
+
+	want := []*dstSchema.NVDCVEFeedJSON10DefCPEMatch{
+		{Cpe23Uri: "cpe:2.3:a:*:lodash:*:*:*:*:*:*:*:*", Vulnerable: true, VersionEndExcluding: "4.17.21", FixedVersion: "4.17.21"},
+		{Cpe23Uri: "cpe:2.3:a:*:lodash-es:*:*:*:*:*:*:*:*", Vulnerable: true, VersionStartIncluding: "4.0.0", VersionEndIncluding: "4.17.20"},
+		{Cpe23Uri: "cpe:2.3:a:*:lodash-es:*:*:*:*:*:*:*:*", Vulnerable: true, VersionStartIncluding: "5.0.0"},
+		{Cpe23Uri: "cpe:2.3:a:*:not-lodash:1.0:*:*:*:*:*:*:*", Vulnerable: true},
+		{Cpe23Uri: "cpe:2.3:a:*:not-lodash:1.1:*:*:*:*:*:*:*", Vulnerable: true},
+		{Cpe23Uri: "cpe:2.3:a:lodash:lodash:*:*:*:*:*:go:*:*", Vulnerable: true, VersionEndExcluding: "4.17.21", FixedVersion: "4.17.21"},
+	}
+	nodes := item.Configurations.Nodes
+	if len(nodes) != 1 || nodes[0].Operator != "OR" {
+		t.Fatalf("unexpected nodes %+v", nodes)
+	}
+	if !reflect.DeepEqual(nodes[0].CPEMatch, want) {
+		for _, m := range nodes[0].CPEMatch {
+			t.Logf("%+v", m)
+		}
+		t.Error("unexpected CPE matches")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	input := make(chan *srcSchema.Vulnerability, 3)
+	var vuln srcSchema.Vulnerability
+	if err := json.Unmarshal([]byte(testRecord), &vuln); err != nil {
+		t.Fatal(err)
+	}
+	withdrawn := vuln
+	withdrawn.ID, withdrawn.Withdrawn = "GHSA-0000-0000-0000", "2023-01-01T00:00:00Z"
+	unaffected := srcSchema.Vulnerability{ID: "PYSEC-2023-1", Modified: "2023-01-01T00:00:00Z"}
+	input <- &vuln
+	input <- &withdrawn
+	input <- &unaffected
+	close(input)
+
+	feed := Convert(input)
+	if len(feed.CVEItems) != 1 || feed.CVEItems[0].CVE.CVEDataMeta.ID != vuln.ID {
+		t.Fatalf("expected only %s to be converted, got %+v", vuln.ID, feed.CVEItems)
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	cases := map[srcSchema.Package]string{
+		{Ecosystem: "Go", Name: "github.com/gorilla/websocket"}:           "pkg:golang/github.com/gorilla/websocket",
+		{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core"}: "pkg:maven/org.apache.logging.log4j/log4j-core",
+		{Ecosystem: "npm", Name: "@angular/core"}:                         "pkg:npm/%40angular/core",
+		{Ecosystem: "Debian:11", Name: "openssl"}:                         "",
+		{Ecosystem: "crates.io", Name: "hyper", Purl: "pkg:cargo/hyper"}:  "pkg:cargo/hyper",
+	}
+	for pkg, want := range cases {
+		pkg := pkg
+		p, err := PackageURL(&pkg)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%+v: expected an error, got %v", pkg, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%+v: %v", pkg, err)
+		}
+		if got := p.Package(); got != want {
+			t.Errorf("%+v: want %q, got %q", pkg, want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema defines the types of OSV (Open Source Vulnerability) format records,
+// as per https://ossf.github.io/osv-schema/
+package schema
+
+// Vulnerability is OSV record of a vulnerability
+type Vulnerability struct {
+	SchemaVersion    string                 `json:"schema_version,omitempty"`
+	ID               string                 `json:"id"`
+	Modified         string                 `json:"modified"`
+	Published        string                 `json:"published,omitempty"`
+	Withdrawn        string                 `json:"withdrawn,omitempty"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Related          []string               `json:"related,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Details          string                 `json:"details,omitempty"`
+	Severity         []*Severity            `json:"severity,omitempty"`
+	Affected         []*Affected            `json:"affected,omitempty"`
+	References       []*Reference           `json:"references,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+// Severity is a severity assessment of the vulnerability, e.g. CVSS vector
+type Severity struct {
+	Type  string `json:"type"` // CVSS_V2, CVSS_V3, CVSS_V4
+	Score string `json:"score"`
+}
+
+// Affected lists the affected versions of a package
+type Affected struct {
+	Package           *Package               `json:"package,omitempty"`
+	Severity          []*Severity            `json:"severity,omitempty"`
+	Ranges            []*Range               `json:"ranges,omitempty"`
+	Versions          []string               `json:"versions,omitempty"`
+	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific,omitempty"`
+	DatabaseSpecific  map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+// Package identifies the affected package within its ecosystem
+type Package struct {
+	Ecosystem string `json:"ecosystem"` // e.g. PyPI, npm, Go, crates.io
+	Name      string `json:"name"`
+	Purl      string `json:"purl,omitempty"`
+}
+
+// Range is a range of affected versions described by events
+type Range struct {
+	Type   string   `json:"type"` // SEMVER, ECOSYSTEM or GIT
+	Repo   string   `json:"repo,omitempty"`
+	Events []*Event `json:"events"`
+}
+
+// Event is a version of the package the range starts or ends at; only one of the fields is set
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// Reference is a link to more information about the vulnerability
+type Reference struct {
+	Type string `json:"type"` // e.g. ADVISORY, FIX, WEB
+	URL  string `json:"url"`
+}
+
+// Query is a query of the vulnerabilities of a package, optionally of a version of it, for OSV API;
+// the package is identified either by Package or by the package URL of the version
+type Query struct {
+	Package   *Package `json:"package,omitempty"`
+	Version   string   `json:"version,omitempty"`
+	PageToken string   `json:"page_token,omitempty"`
+}
+
+// BatchQuery is the request of OSV API querybatch endpoint
+type BatchQuery struct {
+	Queries []*Query `json:"queries"`
+}
+
+// BatchResponse is the response of OSV API querybatch endpoint, the results are aligned with the queries
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+}
+
+// BatchResult lists the vulnerabilities matching a query, only IDs and modification times of them
+type BatchResult struct {
+	Vulns         []*Vulnerability `json:"vulns"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// ovalDefinitions is a subset of OVAL v2 definitions as published by Red Hat, one file per product stream, see
+// https://security.access.redhat.com/data/oval/v2/
+type ovalDefinitions struct {
+	Definitions []ovalDefinition `xml:"definitions>definition"`
+	Tests       []ovalTest       `xml:"tests>rpminfo_test"`
+	Objects     []struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	} `xml:"objects>rpminfo_object"`
+	States []struct {
+		ID  string `xml:"id,attr"`
+		EVR struct {
+			Operation string `xml:"operation,attr"`
+			Value     string `xml:",chardata"`
+		} `xml:"evr"`
+	} `xml:"states>rpminfo_state"`
+}
+
+type ovalDefinition struct {
+	// Class is patch for advisories fixing CVEs and vulnerability for CVEs not fixed yet
+	Class    string `xml:"class,attr"`
+	Metadata struct {
+		References []struct {
+			ID     string `xml:"ref_id,attr"`
+			Source string `xml:"source,attr"`
+		} `xml:"reference"`
+		Advisory struct {
+			CVEs []string `xml:"cve"`
+			CPEs []string `xml:"affected_cpe_list>cpe"`
+		} `xml:"advisory"`
+	} `xml:"metadata"`
+	Criteria ovalCriteria `xml:"criteria"`
+}
+
+type ovalCriteria struct {
+	Criteria  []ovalCriteria `xml:"criteria"`
+	Criterion []struct {
+		TestRef string `xml:"test_ref,attr"`
+	} `xml:"criterion"`
+}
+
+type ovalTest struct {
+	ID     string `xml:"id,attr"`
+	Object struct {
+		Ref string `xml:"object_ref,attr"`
+	} `xml:"object"`
+	State struct {
+		Ref string `xml:"state_ref,attr"`
+	} `xml:"state"`
+}
+
+// ParseOVAL parses the fixes of rpm packages from Red Hat OVAL v2 definitions. Patch definitions fix their CVEs
+// in the packages the tests check to be "less than" the fixed [epoch:]version-release; vulnerability definitions
+// (of the files including unpatched CVEs) tell the packages their tests check the presence of are affected.
+// The release is told by the dist tag of the fix or the affected CPEs of the definition.
+func ParseOVAL(in io.Reader) (cvefeed.DistroFixes, error) {
+	var doc ovalDefinitions
+	if err := xml.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("redhat: failed to decode OVAL: %v", err)
+	}
+	objects := make(map[string]string, len(doc.Objects))
+	for _, o := range doc.Objects {
+		objects[o.ID] = strings.ToLower(strings.TrimSpace(o.Name))
+	}
+	fixedIn := make(map[string]string, len(doc.States))
+	for _, s := range doc.States {
+		if s.EVR.Operation == "less than" {
+			fixedIn[s.ID] = strings.TrimSpace(s.EVR.Value)
+		}
+	}
+	tests := make(map[string]ovalTest, len(doc.Tests))
+	for _, t := range doc.Tests {
+		tests[t.ID] = t
+	}
+
+	fixes := make(cvefeed.DistroFixes)
+	for _, d := range doc.Definitions {
+		if d.Class != "patch" && d.Class != "vulnerability" {
+			continue
+		}
+		cves := d.cves()
+		if len(cves) == 0 {
+			continue
+		}
+		var release string
+		for _, cpe := range d.Metadata.Advisory.CPEs {
+			if release = releaseOfCPE(cpe); release != "" {
+				break
+			}
+		}
+		for _, ref := range d.Criteria.testRefs(nil) {
+			t, ok := tests[ref]
+			if !ok || objects[t.Object.Ref] == "" {
+				continue
+			}
+			f := cvefeed.DistroFix{Package: objects[t.Object.Ref], Release: release}
+			switch {
+			case d.Class == "patch" && fixedIn[t.State.Ref] != "":
+				f.FixedIn = fixedIn[t.State.Ref]
+				if r := releaseOfEVR(f.FixedIn); r != "" {
+					f.Release = r
+				}
+			case d.Class == "vulnerability" && t.State.Ref == "":
+			default:
+				continue // checks of the release installed, signing keys etc.
+			}
+			for _, cve := range cves {
+				fix := f
+				fix.CVE = cve
+				add(fixes, &fix)
+			}
+		}
+	}
+	return fixes, nil
+}
+
+// cves returns the CVEs of the definition, from the advisory or the references
+func (d *ovalDefinition) cves() []string {
+	var cves []string
+	for _, cve := range d.Metadata.Advisory.CVEs {
+		if cve = strings.TrimSpace(cve); cve != "" {
+			cves = append(cves, cve)
+		}
+	}
+	if len(cves) != 0 {
+		return cves
+	}
+	for _, ref := range d.Metadata.References {
+		if ref.Source == "CVE" && ref.ID != "" {
+			cves = append(cves, ref.ID)
+		}
+	}
+	return cves
+}
+
+// testRefs appends the tests referenced by the criteria tree to refs
+func (c *ovalCriteria) testRefs(refs []string) []string {
+	for _, criterion := range c.Criterion {
+		refs = append(refs, criterion.TestRef)
+	}
+	for i := range c.Criteria {
+		refs = c.Criteria[i].testRefs(refs)
+	}
+	return refs
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"strings"
+	"testing"
+)
+
+const testOVAL = `<?xml version="1.0" encoding="utf-8"?>
+<oval_definitions xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5" xmlns:red-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#linux">
+ <definitions>
+  <definition class="patch" id="oval:com.redhat.rhsa:def:20230946" version="637">
+   <metadata>
+    <title>RHSA-2023:0946: openssl security update (Important)</title>
+    <reference ref_id="RHSA-2023:0946" source="RHSA"/>
+    <reference ref_id="CVE-2023-0286" source="CVE"/>
+    <advisory from="secalert@redhat.com">
+     <cve cvss3="7.4/CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H" impact="high">CVE-2023-0286</cve>
+     <cve impact="moderate">CVE-2022-4304</cve>
+     <affected_cpe_list><cpe>cpe:/a:redhat:enterprise_linux:8</cpe></affected_cpe_list>
+    </advisory>
+   </metadata>
+   <criteria operator="OR">
+    <criterion comment="Red Hat Enterprise Linux must be installed" test_ref="oval:com.redhat.rhba:tst:20191992005"/>
+    <criteria operator="AND">
+     <criterion comment="openssl is earlier than 1:1.1.1k-9.el8_7" test_ref="oval:com.redhat.rhsa:tst:20230946001"/>
+     <criterion comment="openssl is signed with Red Hat redhatrelease2 key" test_ref="oval:com.redhat.rhsa:tst:20230946002"/>
+    </criteria>
+   </criteria>
+  </definition>
+  <definition class="vulnerability" id="oval:com.redhat.unaffected:def:20235678" version="1">
+   <metadata>
+    <reference ref_id="CVE-2023-5678" source="CVE"/>
+    <advisory from="secalert@redhat.com">
+     <affected_cpe_list><cpe>cpe:/o:redhat:enterprise_linux:8</cpe></affected_cpe_list>
+    </advisory>
+   </metadata>
+   <criteria operator="AND">
+    <criterion comment="openssl is installed" test_ref="oval:com.redhat.unaffected:tst:20235678001"/>
+   </criteria>
+  </definition>
+ </definitions>
+ <tests>
+  <red-def:rpminfo_test check="at least one" comment="Red Hat Enterprise Linux must be installed" id="oval:com.redhat.rhba:tst:20191992005">
+   <red-def:object object_ref="oval:com.redhat.rhba:obj:20191992003"/>
+   <red-def:state state_ref="oval:com.redhat.rhba:ste:20191992003"/>
+  </red-def:rpminfo_test>
+  <red-def:rpminfo_test check="at least one" comment="openssl is earlier than 1:1.1.1k-9.el8_7" id="oval:com.redhat.rhsa:tst:20230946001">
+   <red-def:object object_ref="oval:com.redhat.rhsa:obj:20230946001"/>
+   <red-def:state state_ref="oval:com.redhat.rhsa:ste:20230946001"/>
+  </red-def:rpminfo_test>
+  <red-def:rpminfo_test check="at least one" comment="openssl is signed with Red Hat redhatrelease2 key" id="oval:com.redhat.rhsa:tst:20230946002">
+   <red-def:object object_ref="oval:com.redhat.rhsa:obj:20230946001"/>
+   <red-def:state state_ref="oval:com.redhat.rhba:ste:20191992002"/>
+  </red-def:rpminfo_test>
+  <red-def:rpminfo_test check="at least one" comment="openssl is installed" id="oval:com.redhat.unaffected:tst:20235678001">
+   <red-def:object object_ref="oval:com.redhat.rhsa:obj:20230946001"/>
+  </red-def:rpminfo_test>
+ </tests>
+ <objects>
+  <red-def:rpminfo_object id="oval:com.redhat.rhba:obj:20191992003"><red-def:name>redhat-release</red-def:name></red-def:rpminfo_object>
+  <red-def:rpminfo_object id="oval:com.redhat.rhsa:obj:20230946001"><red-def:name>openssl</red-def:name></red-def:rpminfo_object>
+ </objects>
+ <states>
+  <red-def:rpminfo_state id="oval:com.redhat.rhba:ste:20191992002"><red-def:signature_keyid operation="equals">199e2f91fd431d51</red-def:signature_keyid></red-def:rpminfo_state>
+  <red-def:rpminfo_state id="oval:com.redhat.rhba:ste:20191992003"><red-def:version operation="pattern match">^8[^\d]</red-def:version></red-def:rpminfo_state>
+  <red-def:rpminfo_state id="oval:com.redhat.rhsa:ste:20230946001"><red-def:arch datatype="string" operation="pattern match">aarch64|ppc64le|s390x|x86_64</red-def:arch><red-def:evr datatype="evr_string" operation="less than">1:1.1.1k-9.el8_7</red-def:evr></red-def:rpminfo_state>
+ </states>
+</oval_definitions>`
+
+func TestParseOVAL(t *testing.T) {
+	fixes, err := ParseOVAL(strings.NewReader(testOVAL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixes) != 3 {
+		t.Fatalf("expected fixes of 3 CVEs, got %d: %v", len(fixes), fixes)
+	}
+	for _, cve := range []string{"CVE-2023-0286", "CVE-2022-4304"} {
+		fs := fixes[cve]
+		if len(fs) != 1 || fs[0].Package != "openssl" || fs[0].Release != "el8" || fs[0].FixedIn != "1:1.1.1k-9.el8_7" {
+			t.Errorf("%s: unexpected fixes %+v", cve, fs)
+		}
+	}
+	if fs := fixes["CVE-2023-5678"]; len(fs) != 1 || fs[0].Package != "openssl" || fs[0].Release != "el8" || fs[0].String() != "affected" {
+		t.Errorf("CVE-2023-5678: unexpected fixes %+v", fs)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redhat provides a parser of Red Hat security data, CSAF VEX documents and OVAL v2 definitions,
+// to the fixes of the packages Red Hat Enterprise Linux ships (see cvefeed.DistroFixes). Red Hat backports
+// fixes without bumping upstream versions, so NVD matches of rpm packages are amended with them.
+package redhat
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvdcommon"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Parse parses CSAF VEX document or OVAL v2 definitions, bzip2'ed or not, telling them by the content
+func Parse(in io.Reader) (cvefeed.DistroFixes, error) {
+	r := bufio.NewReader(in)
+	if magic, _ := r.Peek(3); bytes.Equal(magic, []byte("BZh")) {
+		r = bufio.NewReader(bzip2.NewReader(r))
+	}
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("redhat: unknown format: %v", err)
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			r.UnreadByte()
+			return ParseVEX(r)
+		case '<':
+			r.UnreadByte()
+			return ParseOVAL(r)
+		default:
+			return nil, fmt.Errorf("redhat: unknown format: neither CSAF VEX JSON nor OVAL XML")
+		}
+	}
+}
+
+// Load parses CSAF VEX document or OVAL v2 definitions from file, see Parse
+func Load(path string) (cvefeed.DistroFixes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("redhat: failed to load %q: %v", path, err)
+	}
+	defer f.Close()
+	fixes, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v in %q", err, path)
+	}
+	return fixes, nil
+}
+
+// Fetch downloads CSAF VEX document or OVAL v2 definitions from url with the client and parses them, see Parse
+func Fetch(ctx context.Context, c *client.Client, url string) (cvefeed.DistroFixes, error) {
+	resp, err := c.Get(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("redhat: %v", err)
+	}
+	defer resp.Body.Close()
+	fixes, err := Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%v in %q", err, url)
+	}
+	return fixes, nil
+}
+
+// Merge adds the fixes of src to dst. Red Hat data may have several verdicts on a package of the same release,
+// one per product stream, e.g. the main one and extended update support (EUS) of a minor release; these are
+// merged by the precedence: known affected (no fix for some stream yet), the earliest fix, not affected.
+// Builds of minor release streams get the fixes in earlier releases, so the earliest fix avoids false
+// positives on them at the cost of missing main stream builds between the fixes.
+func Merge(dst, src cvefeed.DistroFixes) {
+	for _, fixes := range src {
+		for _, f := range fixes {
+			add(dst, f)
+		}
+	}
+}
+
+// add adds the fix to fixes merging it with the one of the same package and release, see Merge
+func add(fixes cvefeed.DistroFixes, fix *cvefeed.DistroFix) {
+	for _, f := range fixes[fix.CVE] {
+		if f.Package != fix.Package || f.Release != fix.Release {
+			continue
+		}
+		switch {
+		case f.NotAffected:
+			*f = *fix
+		case fix.NotAffected, f.FixedIn == "":
+		case fix.FixedIn == "" || nvdcommon.CompareRPM(fix.FixedIn, f.FixedIn) < 0:
+			f.FixedIn = fix.FixedIn
+		}
+		return
+	}
+	f := *fix
+	fixes.Add(&f)
+}
+
+// distTag matches the dist tag of rpm releases, e.g. el8 in 7.el8_6 or 1.module+el8.6.0+14880+4e8a8bb6
+var distTag = regexp.MustCompile(`(?:^|[.+])(el\d+)`)
+
+// releaseName matches the dist tag of a release alone
+var releaseName = regexp.MustCompile(`^el\d+$`)
+
+// releaseOfEVR returns the dist tag of the [epoch:]version-release, empty if there's none
+func releaseOfEVR(evr string) string {
+	if i := strings.LastIndexByte(evr, '-'); i != -1 {
+		if m := distTag.FindStringSubmatch(evr[i+1:]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// releaseOfCPE returns the dist tag of Red Hat Enterprise Linux release the product CPE name is of, e.g. el8 for
+// cpe:/a:redhat:enterprise_linux:8::appstream, cpe:/a:redhat:rhel_eus:8.6::baseos or cpe:/a:redhat:openshift:4.12::el8;
+// empty if the CPE doesn't tell
+func releaseOfCPE(cpe string) string {
+	attr, err := wfn.Parse(cpe)
+	if err != nil {
+		return ""
+	}
+	for _, v := range []string{attr.Update, attr.Edition} {
+		if v = wfn.StripSlashes(v); releaseName.MatchString(v) {
+			return v
+		}
+	}
+	product := wfn.StripSlashes(attr.Product)
+	if product != "enterprise_linux" && !strings.HasPrefix(product, "rhel") {
+		return ""
+	}
+	version := wfn.StripSlashes(attr.Version)
+	if i := strings.IndexByte(version, '.'); i != -1 {
+		version = version[:i]
+	}
+	if version == "" || strings.Trim(version, "0123456789") != "" {
+		return ""
+	}
+	return "el" + version
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestParse(t *testing.T) {
+	for name, in := range map[string]string{"vex": "\n" + testVEX, "oval": testOVAL} {
+		fixes, err := Parse(strings.NewReader(in))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(fixes["CVE-2023-0286"]) == 0 {
+			t.Errorf("%s: no fixes parsed: %v", name, fixes)
+		}
+	}
+	if _, err := Parse(strings.NewReader("CVE-2023-0286,openssl,el8,affected")); err == nil {
+		t.Error("expected unknown format to fail")
+	}
+	if _, err := Parse(bzip2.NewReader(bytes.NewReader(nil))); err == nil {
+		t.Error("expected empty input to fail")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	fixes := make(cvefeed.DistroFixes)
+	for _, f := range []cvefeed.DistroFix{
+		{CVE: "CVE-1", Package: "openssl", Release: "el8", NotAffected: true},
+		{CVE: "CVE-1", Package: "openssl", Release: "el8", FixedIn: "1:1.1.1k-9.el8_7"},
+		{CVE: "CVE-1", Package: "openssl", Release: "el8", FixedIn: "1:1.1.1k-8.el8_6"},
+		{CVE: "CVE-1", Package: "openssl", Release: "el8", NotAffected: true},
+		{CVE: "CVE-2", Package: "openssl", Release: "el8", FixedIn: "1:1.1.1k-8.el8_6"},
+		{CVE: "CVE-2", Package: "openssl", Release: "el8"},
+		{CVE: "CVE-2", Package: "openssl", Release: "el8", FixedIn: "1:1.1.1k-9.el8_7"},
+	} {
+		f := f
+		Merge(fixes, cvefeed.DistroFixes{f.CVE: {&f}})
+	}
+	if fs := fixes["CVE-1"]; len(fs) != 1 || fs[0].String() != "1:1.1.1k-8.el8_6" {
+		t.Errorf("expected the earliest fix, got %+v", fs)
+	}
+	if fs := fixes["CVE-2"]; len(fs) != 1 || fs[0].String() != "affected" {
+		t.Errorf("expected known affected, got %+v", fs)
+	}
+}
+
+func TestReleaseOfCPE(t *testing.T) {
+	for cpe, release := range map[string]string{
+		"cpe:/o:redhat:enterprise_linux:9":            "el9",
+		"cpe:/a:redhat:enterprise_linux:8::appstream": "el8",
+		"cpe:/a:redhat:rhel_eus:8.6::baseos":          "el8",
+		"cpe:/a:redhat:openshift:4.12::el8":           "el8",
+		"cpe:/a:redhat:openshift:4.12":                "",
+		"cpe:/a:redhat:enterprise_linux:*":            "",
+		"not a cpe":                                   "",
+	} {
+		if got := releaseOfCPE(cpe); got != release {
+			t.Errorf("%s: expected %q, got %q", cpe, release, got)
+		}
+	}
+}
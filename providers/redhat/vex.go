@@ -0,0 +1,199 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// vexDocument is a subset of CSAF 2.0 VEX document as published by Red Hat, one per CVE, see
+// https://security.access.redhat.com/data/csaf/v2/vex/
+type vexDocument struct {
+	ProductTree struct {
+		Branches      []vexBranch       `json:"branches"`
+		Relationships []vexRelationship `json:"relationships"`
+	} `json:"product_tree"`
+	Vulnerabilities []struct {
+		CVE           string `json:"cve"`
+		ProductStatus struct {
+			Fixed            []string `json:"fixed"`
+			KnownAffected    []string `json:"known_affected"`
+			KnownNotAffected []string `json:"known_not_affected"`
+		} `json:"product_status"`
+	} `json:"vulnerabilities"`
+}
+
+type vexBranch struct {
+	Category string      `json:"category"`
+	Name     string      `json:"name"`
+	Branches []vexBranch `json:"branches"`
+	Product  *vexProduct `json:"product"`
+}
+
+type vexProduct struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Helper    struct {
+		CPE  string `json:"cpe"`
+		PURL string `json:"purl"`
+	} `json:"product_identification_helper"`
+}
+
+// vexRelationship tells the product (full_product_name) is the component (product_reference) of the platform
+// (relates_to_product_reference), e.g. openssl-1:1.1.1k-7.el8_6.x86_64 of Red Hat Enterprise Linux AppStream 8
+type vexRelationship struct {
+	Category         string     `json:"category"`
+	FullProductName  vexProduct `json:"full_product_name"`
+	ProductReference string     `json:"product_reference"`
+	RelatesTo        string     `json:"relates_to_product_reference"`
+}
+
+// ParseVEX parses the fixes of rpm packages from Red Hat CSAF VEX document. Products the statuses of the CVEs
+// are given for are components of the platforms (relationships of the product tree): fixed components name
+// the build with the fix, the others name the package alone. Components other than rpm packages
+// (e.g. container images) are skipped, the release is told by the dist tag of the fix or the platform CPE.
+func ParseVEX(in io.Reader) (cvefeed.DistroFixes, error) {
+	var doc vexDocument
+	if err := json.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("redhat: failed to decode CSAF VEX: %v", err)
+	}
+	products := make(map[string]*vexProduct)
+	var walk func([]vexBranch)
+	walk = func(branches []vexBranch) {
+		for _, b := range branches {
+			if b.Product != nil {
+				products[b.Product.ProductID] = b.Product
+			}
+			walk(b.Branches)
+		}
+	}
+	walk(doc.ProductTree.Branches)
+	relationships := make(map[string]vexRelationship)
+	for _, r := range doc.ProductTree.Relationships {
+		if r.Category == "default_component_of" {
+			relationships[r.FullProductName.ProductID] = r
+		}
+	}
+
+	fixes := make(cvefeed.DistroFixes)
+	for _, v := range doc.Vulnerabilities {
+		if v.CVE == "" {
+			continue
+		}
+		status := func(ids []string, fixed, notAffected bool) {
+			for _, id := range ids {
+				r, ok := relationships[id]
+				if !ok {
+					continue
+				}
+				name, evr, ok := vexComponent(r.ProductReference, products[r.ProductReference])
+				if !ok || fixed && evr == "" {
+					continue
+				}
+				f := &cvefeed.DistroFix{CVE: v.CVE, Package: name, NotAffected: notAffected}
+				if fixed {
+					f.FixedIn = evr
+					f.Release = releaseOfEVR(evr)
+				}
+				if f.Release == "" {
+					if platform := products[r.RelatesTo]; platform != nil {
+						f.Release = releaseOfCPE(platform.Helper.CPE)
+					}
+				}
+				add(fixes, f)
+			}
+		}
+		status(v.ProductStatus.Fixed, true, false)
+		status(v.ProductStatus.KnownAffected, false, false)
+		status(v.ProductStatus.KnownNotAffected, false, true)
+	}
+	return fixes, nil
+}
+
+// vexComponent returns the name and [epoch:]version-release, if any, of rpm package the component is;
+// false if it isn't an rpm package
+func vexComponent(id string, p *vexProduct) (name, evr string, ok bool) {
+	if p != nil && p.Helper.PURL != "" {
+		return rpmPURL(p.Helper.PURL)
+	}
+	if i := strings.LastIndexByte(id, '/'); i != -1 { // module streams, e.g. nodejs:18/nodejs
+		id = id[i+1:]
+	}
+	name, evr = splitNEVRA(id)
+	return strings.ToLower(name), evr, name != ""
+}
+
+// rpmPURL returns the name and [epoch:]version-release of rpm package URL, e.g.
+// pkg:rpm/redhat/openssl@1.1.1k-7.el8_6?arch=x86_64&epoch=1; false if it isn't one
+func rpmPURL(purl string) (name, evr string, ok bool) {
+	if !strings.HasPrefix(purl, "pkg:rpm/") {
+		return "", "", false
+	}
+	purl = strings.TrimPrefix(purl, "pkg:rpm/")
+	if i := strings.IndexByte(purl, '#'); i != -1 {
+		purl = purl[:i]
+	}
+	var query url.Values
+	if i := strings.IndexByte(purl, '?'); i != -1 {
+		query, _ = url.ParseQuery(purl[i+1:])
+		purl = purl[:i]
+	}
+	if i := strings.IndexByte(purl, '@'); i != -1 {
+		evr, _ = url.PathUnescape(purl[i+1:])
+		purl = purl[:i]
+	}
+	if i := strings.LastIndexByte(purl, '/'); i != -1 {
+		purl = purl[i+1:]
+	}
+	if name, _ = url.PathUnescape(purl); name == "" {
+		return "", "", false
+	}
+	if epoch := query.Get("epoch"); epoch != "" && epoch != "0" && evr != "" {
+		evr = epoch + ":" + evr
+	}
+	return strings.ToLower(name), evr, true
+}
+
+// rpmArches are the architectures Red Hat builds rpm packages for
+var rpmArches = map[string]bool{
+	"src": true, "noarch": true, "x86_64": true, "i686": true, "aarch64": true, "ppc64le": true, "ppc64": true, "s390x": true,
+}
+
+// splitNEVRA splits name-[epoch:]version-release[.arch] into the name and [epoch:]version-release;
+// the name alone is returned whole
+func splitNEVRA(s string) (name, evr string) {
+	if i := strings.LastIndexByte(s, '.'); i != -1 && rpmArches[s[i+1:]] {
+		s = s[:i]
+	}
+	rel := strings.LastIndexByte(s, '-')
+	if rel <= 0 {
+		return s, ""
+	}
+	ver := strings.LastIndexByte(s[:rel], '-')
+	if ver <= 0 {
+		return s, ""
+	}
+	evr = s[ver+1:]
+	if c := evr[0]; c < '0' || c > '9' {
+		return s, ""
+	}
+	return s[:ver], evr
+}
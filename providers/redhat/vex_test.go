@@ -0,0 +1,116 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"strings"
+	"testing"
+)
+
+const testVEX = `{
+  "document": {"tracking": {"id": "CVE-2023-0286"}},
+  "product_tree": {
+    "branches": [{
+      "category": "vendor", "name": "Red Hat",
+      "branches": [
+        {"category": "product_family", "name": "Red Hat Enterprise Linux", "branches": [
+          {"category": "product_name", "name": "Red Hat Enterprise Linux AppStream (v. 8)",
+           "product": {"product_id": "AppStream-8.7.0.Z.MAIN", "name": "Red Hat Enterprise Linux AppStream (v. 8)",
+             "product_identification_helper": {"cpe": "cpe:/a:redhat:enterprise_linux:8::appstream"}}},
+          {"category": "product_name", "name": "Red Hat Enterprise Linux 8.6 Extended Update Support",
+           "product": {"product_id": "AppStream-8.6.0.Z.EUS", "name": "Red Hat Enterprise Linux 8.6 Extended Update Support",
+             "product_identification_helper": {"cpe": "cpe:/a:redhat:rhel_eus:8.6::appstream"}}},
+          {"category": "product_name", "name": "Red Hat Enterprise Linux 9",
+           "product": {"product_id": "red_hat_enterprise_linux_9", "name": "Red Hat Enterprise Linux 9",
+             "product_identification_helper": {"cpe": "cpe:/o:redhat:enterprise_linux:9"}}}
+        ]},
+        {"category": "product_version", "name": "openssl-1:1.1.1k-9.el8_7.x86_64",
+         "product": {"product_id": "openssl-1:1.1.1k-9.el8_7.x86_64", "name": "openssl-1:1.1.1k-9.el8_7.x86_64",
+           "product_identification_helper": {"purl": "pkg:rpm/redhat/openssl@1.1.1k-9.el8_7?arch=x86_64&epoch=1"}}},
+        {"category": "product_version", "name": "openssl-1:1.1.1k-8.el8_6.x86_64",
+         "product": {"product_id": "openssl-1:1.1.1k-8.el8_6.x86_64", "name": "openssl-1:1.1.1k-8.el8_6.x86_64",
+           "product_identification_helper": {"purl": "pkg:rpm/redhat/openssl@1.1.1k-8.el8_6?arch=x86_64&epoch=1"}}},
+        {"category": "product_version", "name": "openssl-libs-1:1.1.1k-9.el8_7.x86_64",
+         "product": {"product_id": "openssl-libs-1:1.1.1k-9.el8_7.x86_64", "name": "openssl-libs-1:1.1.1k-9.el8_7.x86_64"}},
+        {"category": "product_version", "name": "openssl",
+         "product": {"product_id": "openssl", "name": "openssl"}},
+        {"category": "product_version", "name": "ubi8/openssl",
+         "product": {"product_id": "ubi8/openssl", "name": "ubi8/openssl",
+           "product_identification_helper": {"purl": "pkg:oci/openssl?repository_url=registry.access.redhat.com/ubi8"}}}
+      ]
+    }],
+    "relationships": [
+      {"category": "default_component_of", "product_reference": "openssl-1:1.1.1k-9.el8_7.x86_64", "relates_to_product_reference": "AppStream-8.7.0.Z.MAIN",
+       "full_product_name": {"product_id": "AppStream-8.7.0.Z.MAIN:openssl-1:1.1.1k-9.el8_7.x86_64", "name": "openssl as a component of Red Hat Enterprise Linux AppStream (v. 8)"}},
+      {"category": "default_component_of", "product_reference": "openssl-1:1.1.1k-8.el8_6.x86_64", "relates_to_product_reference": "AppStream-8.6.0.Z.EUS",
+       "full_product_name": {"product_id": "AppStream-8.6.0.Z.EUS:openssl-1:1.1.1k-8.el8_6.x86_64", "name": "openssl as a component of Red Hat Enterprise Linux 8.6 Extended Update Support"}},
+      {"category": "default_component_of", "product_reference": "openssl-libs-1:1.1.1k-9.el8_7.x86_64", "relates_to_product_reference": "AppStream-8.7.0.Z.MAIN",
+       "full_product_name": {"product_id": "AppStream-8.7.0.Z.MAIN:openssl-libs-1:1.1.1k-9.el8_7.x86_64", "name": "openssl-libs as a component of Red Hat Enterprise Linux AppStream (v. 8)"}},
+      {"category": "default_component_of", "product_reference": "openssl", "relates_to_product_reference": "red_hat_enterprise_linux_9",
+       "full_product_name": {"product_id": "red_hat_enterprise_linux_9:openssl", "name": "openssl as a component of Red Hat Enterprise Linux 9"}},
+      {"category": "default_component_of", "product_reference": "ubi8/openssl", "relates_to_product_reference": "AppStream-8.7.0.Z.MAIN",
+       "full_product_name": {"product_id": "AppStream-8.7.0.Z.MAIN:ubi8/openssl", "name": "ubi8/openssl as a component of Red Hat Enterprise Linux AppStream (v. 8)"}}
+    ]
+  },
+  "vulnerabilities": [{
+    "cve": "CVE-2023-0286",
+    "product_status": {
+      "fixed": [
+        "AppStream-8.7.0.Z.MAIN:openssl-1:1.1.1k-9.el8_7.x86_64",
+        "AppStream-8.6.0.Z.EUS:openssl-1:1.1.1k-8.el8_6.x86_64",
+        "AppStream-8.7.0.Z.MAIN:openssl-libs-1:1.1.1k-9.el8_7.x86_64",
+        "AppStream-8.7.0.Z.MAIN:ubi8/openssl"
+      ],
+      "known_not_affected": ["red_hat_enterprise_linux_9:openssl"]
+    }
+  }]
+}`
+
+func TestParseVEX(t *testing.T) {
+	fixes, err := ParseVEX(strings.NewReader(testVEX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, f := range fixes["CVE-2023-0286"] {
+		got[f.Package+" "+f.Release] = f.String()
+	}
+	want := map[string]string{
+		"openssl el8":      "1:1.1.1k-8.el8_6",
+		"openssl-libs el8": "1:1.1.1k-9.el8_7",
+		"openssl el9":      "not_affected",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+}
+
+func TestSplitNEVRA(t *testing.T) {
+	for _, c := range []struct{ in, name, evr string }{
+		{"openssl-libs-1:1.1.1k-9.el8_7.x86_64", "openssl-libs", "1:1.1.1k-9.el8_7"},
+		{"kernel-4.18.0-425.3.1.el8.src", "kernel", "4.18.0-425.3.1.el8"},
+		{"openssl", "openssl", ""},
+		{"python-setuptools-wheel", "python-setuptools-wheel", ""},
+	} {
+		if name, evr := splitNEVRA(c.in); name != c.name || evr != c.evr {
+			t.Errorf("%s: expected %q %q, got %q %q", c.in, c.name, c.evr, name, evr)
+		}
+	}
+}
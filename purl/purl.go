@@ -0,0 +1,168 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purl parses package URLs and compares the versions of packages they identify.
+// See https://github.com/package-url/purl-spec
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PURL is a package URL: pkg:type/namespace/name@version?qualifiers#subpath
+type PURL struct {
+	Type       string
+	Namespace  string // slash separated segments, if any
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// Parse parses package URL; type is lowercased, as well as namespace and name of the types the specification
+// defines case insensitive (e.g. github, pypi), and names of pypi packages have underscores replaced by dashes.
+func Parse(s string) (*PURL, error) {
+	if !strings.HasPrefix(s, "pkg:") {
+		return nil, fmt.Errorf("purl %q: no pkg: scheme", s)
+	}
+	rest := strings.TrimLeft(s[len("pkg:"):], "/")
+	p := &PURL{}
+	var err error
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		if p.Subpath, err = unescape(strings.Trim(rest[i+1:], "/")); err != nil {
+			return nil, fmt.Errorf("purl %q: subpath: %v", s, err)
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		if p.Qualifiers, err = parseQualifiers(rest[i+1:]); err != nil {
+			return nil, fmt.Errorf("purl %q: qualifiers: %v", s, err)
+		}
+		rest = rest[:i]
+	}
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 {
+		if p.Version, err = unescape(rest[i+1:]); err != nil {
+			return nil, fmt.Errorf("purl %q: version: %v", s, err)
+		}
+		rest = rest[:i]
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" || segments[len(segments)-1] == "" {
+		return nil, fmt.Errorf("purl %q: expected pkg:type/name", s)
+	}
+	p.Type = strings.ToLower(segments[0])
+	if p.Name, err = unescape(segments[len(segments)-1]); err != nil {
+		return nil, fmt.Errorf("purl %q: name: %v", s, err)
+	}
+	namespace := make([]string, 0, len(segments)-2)
+	for _, seg := range segments[1 : len(segments)-1] {
+		if seg == "" {
+			continue
+		}
+		seg, err = unescape(seg)
+		if err != nil {
+			return nil, fmt.Errorf("purl %q: namespace: %v", s, err)
+		}
+		namespace = append(namespace, seg)
+	}
+	p.Namespace = strings.Join(namespace, "/")
+	p.normalize()
+	return p, nil
+}
+
+// caseInsensitive lists types whose namespaces and names are case insensitive
+var caseInsensitive = map[string]bool{
+	"bitbucket": true,
+	"composer":  true,
+	"github":    true,
+	"pypi":      true,
+}
+
+func (p *PURL) normalize() {
+	if caseInsensitive[p.Type] {
+		p.Namespace = strings.ToLower(p.Namespace)
+		p.Name = strings.ToLower(p.Name)
+	}
+	if p.Type == "pypi" {
+		p.Name = strings.Replace(p.Name, "_", "-", -1)
+	}
+}
+
+func unescape(s string) (string, error) {
+	return url.PathUnescape(s)
+}
+
+func escape(s string) string {
+	return strings.Replace(url.PathEscape(s), "@", "%40", -1)
+}
+
+func parseQualifiers(s string) (map[string]string, error) {
+	qualifiers := make(map[string]string)
+	for _, pair := range strings.Split(s, "&") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		v, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		if v != "" {
+			qualifiers[strings.ToLower(kv[0])] = v
+		}
+	}
+	return qualifiers, nil
+}
+
+// Package returns the package URL of the package without version, qualifiers and subpath, e.g. pkg:npm/lodash;
+// package URLs of different versions of the same package have the same Package.
+func (p PURL) Package() string {
+	s := "pkg:" + p.Type + "/"
+	if p.Namespace != "" {
+		for _, seg := range strings.Split(p.Namespace, "/") {
+			s += escape(seg) + "/"
+		}
+	}
+	return s + escape(p.Name)
+}
+
+// String returns the canonical form of package URL, with qualifiers sorted
+func (p PURL) String() string {
+	s := p.Package()
+	if p.Version != "" {
+		s += "@" + escape(p.Version)
+	}
+	if len(p.Qualifiers) != 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for k := range p.Qualifiers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = k + "=" + url.QueryEscape(p.Qualifiers[k])
+		}
+		s += "?" + strings.Join(pairs, "&")
+	}
+	if p.Subpath != "" {
+		s += "#" + p.Subpath
+	}
+	return s
+}
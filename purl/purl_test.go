@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in       string
+		want     PURL
+		pkg, str string
+	}{
+		{
+			in:   "pkg:npm/%40angular/core@12.0.0",
+			want: PURL{Type: "npm", Namespace: "@angular", Name: "core", Version: "12.0.0"},
+			pkg:  "pkg:npm/%40angular/core",
+			str:  "pkg:npm/%40angular/core@12.0.0",
+		},
+		{
+			in:   "pkg:PyPI/Django_Rest@3.1?os=linux&arch=x86#src/",
+			want: PURL{Type: "pypi", Name: "django-rest", Version: "3.1", Qualifiers: map[string]string{"arch": "x86", "os": "linux"}, Subpath: "src"},
+			pkg:  "pkg:pypi/django-rest",
+			str:  "pkg:pypi/django-rest@3.1?arch=x86&os=linux#src",
+		},
+		{
+			in:   "pkg:golang/github.com/gorilla/websocket",
+			want: PURL{Type: "golang", Namespace: "github.com/gorilla", Name: "websocket"},
+			pkg:  "pkg:golang/github.com/gorilla/websocket",
+			str:  "pkg:golang/github.com/gorilla/websocket",
+		},
+	}
+	for _, c := range cases {
+		p, err := Parse(c.in)
+		if err != nil {
+			t.Fatalf("%s: %v", c.in, err)
+		}
+		if !reflect.DeepEqual(*p, c.want) {
+			t.Errorf("%s: want %+v, got %+v", c.in, c.want, *p)
+		}
+		if pkg := p.Package(); pkg != c.pkg {
+			t.Errorf("%s: want package %q, got %q", c.in, c.pkg, pkg)
+		}
+		if str := p.String(); str != c.str {
+			t.Errorf("%s: want string %q, got %q", c.in, c.str, str)
+		}
+	}
+	for _, s := range []string{"npm/lodash", "pkg:npm", "pkg:npm/", "pkg:/lodash", "pkg:npm/lodash?os"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		typ, a, b string
+		want      int
+	}{
+		{"npm", "1.2.3", "1.2.3", 0},
+		{"npm", "1.10.0", "1.9.0", 1},
+		{"npm", "1.0.0-alpha", "1.0.0", -1},
+		{"npm", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"npm", "1.0.0-beta.11", "1.0.0-beta.2", 1},
+		{"npm", "1.0.0+build", "1.0.0", 0},
+		{"golang", "v0.4.1", "v0.4.0", 1},
+		{"pypi", "1.0", "1.0.0", -1},
+		{"pypi", "1.0rc1", "1.0", -1},
+		{"maven", "1.10", "1.9", 1},
+		{"deb", "1.2-3", "1.2-10", -1},
+		{"gem", "005", "5", 0},
+	}
+	for _, c := range cases {
+		if got := CompareVersions(c.typ, c.a, c.b); got != c.want {
+			t.Errorf("%s %s vs %s: want %d, got %d", c.typ, c.a, c.b, c.want, got)
+		}
+		if got := CompareVersions(c.typ, c.b, c.a); got != -c.want {
+			t.Errorf("%s %s vs %s: want %d, got %d", c.typ, c.b, c.a, -c.want, got)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	cases := []struct {
+		r       Range
+		version string
+		want    bool
+	}{
+		{Range{Introduced: "0", Fixed: "4.17.21"}, "4.17.20", true},
+		{Range{Introduced: "0", Fixed: "4.17.21"}, "4.17.21", false},
+		{Range{Introduced: "1.0.0", Fixed: "2.0.0"}, "0.9.0", false},
+		{Range{Introduced: "1.0.0", Fixed: "2.0.0"}, "1.0.0", true},
+		{Range{Introduced: "1.0.0", Fixed: "2.0.0"}, "2.0.0-rc.1", true},
+		{Range{Introduced: "1.0.0", LastAffected: "1.5.0"}, "1.5.0", true},
+		{Range{Introduced: "1.0.0", LastAffected: "1.5.0"}, "1.5.1", false},
+		{Range{Introduced: "3.0.0"}, "10.0.0", true},
+	}
+	for _, c := range cases {
+		if got := c.r.Contains("npm", c.version); got != c.want {
+			t.Errorf("%+v contains %s: want %t, got %t", c.r, c.version, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semverTypes lists package types versioned by semantic versioning, https://semver.org
+var semverTypes = map[string]bool{
+	"cargo":  true,
+	"golang": true,
+	"hex":    true,
+	"npm":    true,
+	"nuget":  true,
+}
+
+// CompareVersions compares versions of packages of the type: semantically for the types versioned by semantic
+// versioning (e.g. npm, cargo, golang), segment by segment otherwise, numeric segments numerically and the others
+// lexically, with alphabetic suffixes (1.0rc1, 1.0-beta) making pre-releases of the version they follow.
+// Returns -1 if a < b, 1 if a > b and 0 if a == b.
+func CompareVersions(typ, a, b string) int {
+	if semverTypes[typ] {
+		return CompareSemver(a, b)
+	}
+	return compareSegments(segments(a), segments(b))
+}
+
+// CompareSemver compares semantic versions, https://semver.org; leading v is accepted and build metadata is ignored.
+// Versions which aren't semantic are compared segment by segment, as CompareVersions does for the other types.
+// Returns -1 if a < b, 1 if a > b and 0 if a == b.
+func CompareSemver(a, b string) int {
+	if x, ok := parseSemver(a); ok {
+		if y, ok := parseSemver(b); ok {
+			return compareSemver(x, y)
+		}
+	}
+	return compareSegments(segments(a), segments(b))
+}
+
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseSemver parses semantic version; leading v as used by Go modules is accepted, build metadata is ignored
+func parseSemver(v string) (semver, bool) {
+	var s semver
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		s.prerelease = strings.Split(v[i+1:], ".")
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return s, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return s, false
+		}
+		s.core[i] = n
+	}
+	return s, true
+}
+
+func compareSemver(a, b semver) int {
+	for i := range a.core {
+		if c := compareInts(a.core[i], b.core[i]); c != 0 {
+			return c
+		}
+	}
+	// a version without pre-release takes precedence over the one with
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		x, errx := strconv.Atoi(a.prerelease[i])
+		y, erry := strconv.Atoi(b.prerelease[i])
+		var c int
+		switch {
+		case errx == nil && erry == nil:
+			c = compareInts(x, y)
+		case errx == nil: // numeric identifiers have lower precedence
+			c = -1
+		case erry == nil:
+			c = 1
+		default:
+			c = strings.Compare(a.prerelease[i], b.prerelease[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a.prerelease), len(b.prerelease))
+}
+
+// segments splits version into runs of digits and runs of letters, dropping the separators
+func segments(v string) []string {
+	var segs []string
+	start := -1
+	digits := false
+	for i := 0; i <= len(v); i++ {
+		var c byte
+		if i < len(v) {
+			c = v[i]
+		}
+		isDigit, isLetter := c >= '0' && c <= '9', c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if start >= 0 && (i == len(v) || isDigit != digits || !isDigit && !isLetter) {
+			segs = append(segs, strings.ToLower(v[start:i]))
+			start = -1
+		}
+		if start < 0 && (isDigit || isLetter) {
+			start, digits = i, isDigit
+		}
+	}
+	return segs
+}
+
+func isNumeric(seg string) bool {
+	return seg != "" && seg[0] >= '0' && seg[0] <= '9'
+}
+
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		var c int
+		switch {
+		case isNumeric(x) && isNumeric(y):
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if c = compareInts(len(x), len(y)); c == 0 {
+				c = strings.Compare(x, y)
+			}
+		case isNumeric(x): // 1.0.1 > 1.0rc
+			c = 1
+		case isNumeric(y):
+			c = -1
+		default:
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) == len(b):
+		return 0
+	case len(a) > len(b):
+		if isNumeric(a[len(b)]) {
+			return 1 // 1.0.1 > 1.0
+		}
+		return -1 // 1.0rc1 < 1.0
+	default:
+		if isNumeric(b[len(a)]) {
+			return -1
+		}
+		return 1
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Range is a range of affected versions as reported by OSV advisories: versions from Introduced (inclusive)
+// until Fixed (exclusive) or LastAffected (inclusive). Empty or "0" Introduced means all the versions before,
+// empty Fixed and LastAffected mean all the versions after.
+type Range struct {
+	Introduced   string
+	Fixed        string
+	LastAffected string
+}
+
+// Contains tells whether version of package of the type is in the range, see CompareVersions
+func (r Range) Contains(typ, version string) bool {
+	if r.Introduced != "" && r.Introduced != "0" && CompareVersions(typ, version, r.Introduced) < 0 {
+		return false
+	}
+	if r.Fixed != "" && CompareVersions(typ, version, r.Fixed) >= 0 {
+		return false
+	}
+	if r.LastAffected != "" && CompareVersions(typ, version, r.LastAffected) > 0 {
+		return false
+	}
+	return true
+}
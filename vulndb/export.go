@@ -29,9 +29,12 @@ import (
 	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
 )
 
-// DataExporter is a helper for exporting vulnerability records from the db.
+// DataExporter is a helper for exporting vulnerability records from the db:
+// the latest versions of vendor data, the vendor data kept in sync with the
+// sources but withdrawn CVEs (see VendorDataSyncer), and overrides.
 type DataExporter struct {
 	DB              *sql.DB
+	Dialect         sqlutil.Dialect
 	FilterProviders []string
 	FilterCVEs      []string
 }
@@ -76,6 +79,33 @@ func (exp DataExporter) selectVendorData(fields ...string) *sqlutil.SelectStmt {
 	return q
 }
 
+// fields must be vendor_cve.field_name
+func (exp DataExporter) selectSyncedData(fields ...string) *sqlutil.SelectStmt {
+	q := sqlutil.Select(
+		fields...,
+	).From(
+		"vendor_cve",
+	).Literal(
+		"LEFT JOIN custom_data ON custom_data.cve_id = vendor_cve.cve_id",
+	)
+
+	cond := sqlutil.Cond().
+		IsNull("vendor_cve.withdrawn").
+		And().
+		IsNull("custom_data.cve_id")
+
+	if len(exp.FilterProviders) > 0 {
+		cond = cond.And().In("vendor_cve.provider", exp.FilterProviders)
+	}
+
+	if len(exp.FilterCVEs) > 0 {
+		cond = cond.And().In("vendor_cve.cve_id", exp.FilterCVEs)
+	}
+
+	q = q.Where(cond)
+	return q
+}
+
 func (exp DataExporter) selectOverrides(fields ...string) *sqlutil.SelectStmt {
 	q := sqlutil.Select(
 		fields...,
@@ -126,6 +156,16 @@ func (exp DataExporter) CSV(ctx context.Context, w io.Writer, header bool) error
 			"vendor_data.base_score",
 			"vendor_data.summary",
 		).Literal("UNION ALL").
+			Select(exp.selectSyncedData(
+				"vendor_cve.owner",
+				"vendor_cve.provider",
+				"vendor_cve.cve_id",
+				"vendor_cve.published",
+				"vendor_cve.modified",
+				"vendor_cve.base_score",
+				"vendor_cve.summary",
+			)).
+			Literal("UNION ALL").
 			Select(exp.selectOverrides(
 				"custom_data.owner",
 				"custom_data.provider",
@@ -137,7 +177,7 @@ func (exp DataExporter) CSV(ctx context.Context, w io.Writer, header bool) error
 			)),
 	)
 
-	query, args := q.String(), q.QueryArgs()
+	query, args := exp.Dialect.Rebind(q.String()), q.QueryArgs()
 
 	if debug.V(1) {
 		log.Printf("running: %q / %#v", query, args)
@@ -200,6 +240,11 @@ func (exp DataExporter) JSON(ctx context.Context, w io.Writer, indent string) er
 			"vendor_data.cve_id AS cve_id",
 			"vendor_data.cve_json AS cve_json",
 		).
+			Literal("UNION ALL").
+			Select(exp.selectSyncedData(
+				"vendor_cve.cve_id",
+				"vendor_cve.cve_json",
+			)).
 			Literal("UNION ALL").
 			Select(exp.selectOverrides(
 				"custom_data.cve_id",
@@ -207,7 +252,7 @@ func (exp DataExporter) JSON(ctx context.Context, w io.Writer, indent string) er
 			)),
 	)
 
-	query, args := q.String(), q.QueryArgs()
+	query, args := exp.Dialect.Rebind(q.String()), q.QueryArgs()
 
 	if debug.V(1) {
 		log.Printf("running: %q / %#v", query, args)
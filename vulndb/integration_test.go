@@ -25,6 +25,7 @@ import (
 
 	"github.com/facebookincubator/nvdtools/vulndb/debug"
 	"github.com/facebookincubator/nvdtools/vulndb/mysql"
+	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
 )
 
 func init() {
@@ -233,6 +234,90 @@ func TestIntegration(t *testing.T) {
 		})
 	})
 
+	t.Run("migrate", func(t *testing.T) {
+		if _, err := Migrate(ctx, db, sqlutil.MySQL); err != nil {
+			t.Fatal(err)
+		}
+
+		// migrating twice is a no-op
+		applied, err := Migrate(ctx, db, sqlutil.MySQL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied != 0 {
+			t.Fatalf("want no migrations applied, have %d", applied)
+		}
+	})
+
+	t.Run("vendor/sync", func(t *testing.T) {
+		syn := VendorDataSyncer{
+			DB:       db,
+			Dialect:  sqlutil.MySQL,
+			Owner:    "test",
+			Provider: "test-sync",
+		}
+
+		stats, err := syn.SyncFiles(ctx, f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Synced != 2 || stats.Withdrawn != 0 {
+			t.Fatalf("unexpected stats: %#v", stats)
+		}
+
+		// syncing again upserts the same records
+		stats, err = syn.SyncFiles(ctx, f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Synced != 2 || stats.Withdrawn != 0 {
+			t.Fatalf("unexpected stats: %#v", stats)
+		}
+
+		exp := DataExporter{
+			DB:              db,
+			Dialect:         sqlutil.MySQL,
+			FilterProviders: []string{"test-sync"},
+		}
+
+		var b bytes.Buffer
+		if err = exp.CSV(ctx, &b, false); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), "CVE-0000-0000") {
+			t.Fatal("missing synced CVE")
+		}
+
+		if _, err = syn.SyncFiles(ctx); err == nil {
+			t.Fatal("empty sync must fail unless allowed")
+		}
+
+		syn.AllowEmpty = true
+		stats, err = syn.SyncFiles(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Withdrawn != 2 {
+			t.Fatalf("want 2 withdrawn, have %d", stats.Withdrawn)
+		}
+
+		b.Reset()
+		if err = exp.CSV(ctx, &b, false); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(b.String(), "CVE-0000-0000") {
+			t.Fatal("withdrawn CVE exported")
+		}
+
+		n, err := syn.PurgeWithdrawn(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Fatalf("want 2 purged, have %d", n)
+		}
+	})
+
 	t.Run("vendor/trim", func(t *testing.T) {
 		del := VendorDataTrimmer{
 			DB:                  db,
@@ -0,0 +1,242 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulndb
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/facebookincubator/nvdtools/vulndb/debug"
+	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
+)
+
+// Migration is a change of the database schema, in the SQL of every supported dialect.
+type Migration struct {
+	Version  int
+	Name     string
+	MySQL    []string
+	Postgres []string
+}
+
+// Statements returns the SQL statements of the migration in the dialect.
+func (m Migration) Statements(d sqlutil.Dialect) []string {
+	if d == sqlutil.Postgres {
+		return m.Postgres
+	}
+	return m.MySQL
+}
+
+// Migrations are the changes of the database schema, in the order they're applied.
+// New migrations are appended, the migrations applied to databases must not change.
+//
+// The first migration creates the schema of schema.sql unless it exists, so databases
+// initialized by InitSchemaSQL are migrated safely.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		MySQL: []string{
+			"CREATE TABLE IF NOT EXISTS `vendor` (" +
+				"`version` INT NOT NULL AUTO_INCREMENT COMMENT 'ID of the dataset', " +
+				"`ts` TIMESTAMP NOT NULL COMMENT 'Time of the dataset import', " +
+				"`ready` BOOL NOT NULL COMMENT 'Indicates the dataset is ready to use', " +
+				"`owner` VARCHAR(64) NOT NULL COMMENT 'Point of contact for dataset', " +
+				"`provider` VARCHAR(64) NOT NULL COMMENT 'Short name of dataset provider', " +
+				"PRIMARY KEY (`version`), KEY (`provider`)" +
+				") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Vendors providing vulnerability datasets'",
+			"CREATE TABLE IF NOT EXISTS `vendor_data` (" +
+				"`version` INT NOT NULL COMMENT 'ID of the vendor dataset', " +
+				"`cve_id` VARCHAR(128) NOT NULL COMMENT 'Common Vulnerability and Exposure (CVE) ID', " +
+				"`published` TIMESTAMP NOT NULL COMMENT 'Timestamp of vulnerability publication' DEFAULT CURRENT_TIMESTAMP, " +
+				"`modified` TIMESTAMP NOT NULL COMMENT 'Timestamp of vulnerability last modification' DEFAULT CURRENT_TIMESTAMP, " +
+				"`base_score` FLOAT(3,1) NOT NULL COMMENT 'Base score from CVSS 3.0 or 2.0 fallback', " +
+				"`summary` TEXT NOT NULL COMMENT 'Description of the vulnerability', " +
+				"`cve_json` MEDIUMBLOB NOT NULL COMMENT 'JSON record containing raw CVE data', " +
+				"PRIMARY KEY (`version`, `cve_id`)" +
+				") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Vulnerability data from vendors'",
+			"CREATE TABLE IF NOT EXISTS `custom_data` (" +
+				"`owner` VARCHAR(64) NOT NULL COMMENT 'Point of contact for dataset', " +
+				"`provider` VARCHAR(64) NOT NULL COMMENT 'Short name of data provider', " +
+				"`cve_id` VARCHAR(128) NOT NULL COMMENT 'Common Vulnerability and Exposure ID', " +
+				"`published` TIMESTAMP NOT NULL COMMENT 'Timestamp of vulnerability publication' DEFAULT CURRENT_TIMESTAMP, " +
+				"`modified` TIMESTAMP NOT NULL COMMENT 'Timestamp of customized last modification' DEFAULT CURRENT_TIMESTAMP, " +
+				"`base_score` FLOAT(3,1) NOT NULL COMMENT 'Base score from CVSS 3.0 or 2.0 fallback', " +
+				"`summary` TEXT NOT NULL COMMENT 'Description of the vulnerability', " +
+				"`cve_json` MEDIUMBLOB NOT NULL COMMENT 'JSON record containing raw CVE data', " +
+				"PRIMARY KEY (`cve_id`)" +
+				") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Custom vulnerability data including overrides'",
+			"CREATE TABLE IF NOT EXISTS `snooze` (" +
+				"`owner` VARCHAR(64) NOT NULL COMMENT 'Point of contact for snooze', " +
+				"`collector` VARCHAR(64) NOT NULL COMMENT 'Unique name of the data collector', " +
+				"`provider` VARCHAR(32) NOT NULL COMMENT 'Short name of data provider', " +
+				"`cve_id` VARCHAR(128) NOT NULL COMMENT 'Common Vulnerability and Exposure ID', " +
+				"`deadline` TIMESTAMP NULL COMMENT 'Timestamp of snooze expiration' DEFAULT CURRENT_TIMESTAMP, " +
+				"`metadata` BLOB NULL COMMENT 'Opaque metadata for snooze management', " +
+				"PRIMARY KEY (`provider`, `cve_id`)" +
+				") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Vulnerability records to ignore for a period of time'",
+		},
+		Postgres: []string{
+			"CREATE TABLE IF NOT EXISTS vendor (" +
+				"version SERIAL PRIMARY KEY, " +
+				"ts TIMESTAMP NOT NULL, " +
+				"ready BOOLEAN NOT NULL, " +
+				"owner VARCHAR(64) NOT NULL, " +
+				"provider VARCHAR(64) NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS vendor_provider ON vendor (provider)",
+			"CREATE TABLE IF NOT EXISTS vendor_data (" +
+				"version INTEGER NOT NULL, " +
+				"cve_id VARCHAR(128) NOT NULL, " +
+				"published TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"base_score NUMERIC(3,1) NOT NULL, " +
+				"summary TEXT NOT NULL, " +
+				"cve_json BYTEA NOT NULL, " +
+				"PRIMARY KEY (version, cve_id))",
+			"CREATE TABLE IF NOT EXISTS custom_data (" +
+				"owner VARCHAR(64) NOT NULL, " +
+				"provider VARCHAR(64) NOT NULL, " +
+				"cve_id VARCHAR(128) NOT NULL PRIMARY KEY, " +
+				"published TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"base_score NUMERIC(3,1) NOT NULL, " +
+				"summary TEXT NOT NULL, " +
+				"cve_json BYTEA NOT NULL)",
+			"CREATE TABLE IF NOT EXISTS snooze (" +
+				"owner VARCHAR(64) NOT NULL, " +
+				"collector VARCHAR(64) NOT NULL, " +
+				"provider VARCHAR(32) NOT NULL, " +
+				"cve_id VARCHAR(128) NOT NULL, " +
+				"deadline TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"metadata BYTEA NULL, " +
+				"PRIMARY KEY (provider, cve_id))",
+		},
+	},
+	{
+		Version: 2,
+		Name:    "synced vendor data",
+		MySQL: []string{
+			"CREATE TABLE IF NOT EXISTS `vendor_cve` (" +
+				"`provider` VARCHAR(64) NOT NULL COMMENT 'Short name of dataset provider', " +
+				"`cve_id` VARCHAR(128) NOT NULL COMMENT 'Common Vulnerability and Exposure (CVE) ID', " +
+				"`owner` VARCHAR(64) NOT NULL COMMENT 'Point of contact for dataset', " +
+				"`published` TIMESTAMP NOT NULL COMMENT 'Timestamp of vulnerability publication' DEFAULT CURRENT_TIMESTAMP, " +
+				"`modified` TIMESTAMP NOT NULL COMMENT 'Timestamp of vulnerability last modification' DEFAULT CURRENT_TIMESTAMP, " +
+				"`base_score` FLOAT(3,1) NOT NULL COMMENT 'Base score from CVSS 3.0 or 2.0 fallback', " +
+				"`summary` TEXT NOT NULL COMMENT 'Description of the vulnerability', " +
+				"`cve_json` MEDIUMBLOB NOT NULL COMMENT 'JSON record containing raw CVE data', " +
+				"`synced` TIMESTAMP NOT NULL COMMENT 'Timestamp of the last sync the vulnerability was in' DEFAULT CURRENT_TIMESTAMP, " +
+				"`withdrawn` TIMESTAMP NULL COMMENT 'Timestamp of the sync the vulnerability was withdrawn in, NULL unless withdrawn' DEFAULT NULL, " +
+				"PRIMARY KEY (`provider`, `cve_id`)" +
+				") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Vulnerability data from vendors kept in sync with their sources'",
+		},
+		Postgres: []string{
+			"CREATE TABLE IF NOT EXISTS vendor_cve (" +
+				"provider VARCHAR(64) NOT NULL, " +
+				"cve_id VARCHAR(128) NOT NULL, " +
+				"owner VARCHAR(64) NOT NULL, " +
+				"published TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"base_score NUMERIC(3,1) NOT NULL, " +
+				"summary TEXT NOT NULL, " +
+				"cve_json BYTEA NOT NULL, " +
+				"synced TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+				"withdrawn TIMESTAMP NULL DEFAULT NULL, " +
+				"PRIMARY KEY (provider, cve_id))",
+		},
+	},
+}
+
+// migrationTable records the migrations applied to the database.
+var migrationTable = map[sqlutil.Dialect]string{
+	sqlutil.MySQL: "CREATE TABLE IF NOT EXISTS `schema_migration` (" +
+		"`version` INT NOT NULL COMMENT 'Version of the migration', " +
+		"`name` VARCHAR(128) NOT NULL COMMENT 'Name of the migration', " +
+		"`applied` TIMESTAMP NOT NULL COMMENT 'Timestamp of the migration' DEFAULT CURRENT_TIMESTAMP, " +
+		"PRIMARY KEY (`version`)" +
+		") ENGINE InnoDB DEFAULT CHARACTER SET utf8mb4 COMMENT 'Migrations applied to the schema'",
+	sqlutil.Postgres: "CREATE TABLE IF NOT EXISTS schema_migration (" +
+		"version INTEGER NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(128) NOT NULL, " +
+		"applied TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+}
+
+// SchemaVersion returns the version of the last migration applied to the database, 0 if none.
+func SchemaVersion(ctx context.Context, db *sql.DB, d sqlutil.Dialect) (int, error) {
+	if _, err := db.ExecContext(ctx, migrationTable[d]); err != nil {
+		return 0, errors.Wrap(err, "cannot create migration table")
+	}
+	var version sql.NullInt64
+	q := sqlutil.Select("MAX(version)").From("schema_migration")
+	if err := db.QueryRowContext(ctx, q.String()).Scan(&version); err != nil {
+		return 0, errors.Wrap(err, "cannot query schema version")
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate applies the migrations the database lacks, in order, and returns the number of the migrations
+// applied. Every migration is applied in a transaction along with its record; MySQL commits schema changes
+// implicitly though, so a migration of MySQL interrupted midway is retried on the next run, which the
+// migrations are safe to.
+func Migrate(ctx context.Context, db *sql.DB, d sqlutil.Dialect) (int, error) {
+	version, err := SchemaVersion(ctx, db, d)
+	if err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, m := range Migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := migrate(ctx, db, d, m); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+func migrate(ctx context.Context, db *sql.DB, d sqlutil.Dialect, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "cannot begin migration %d", m.Version)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.Statements(d) {
+		if debug.V(1) {
+			log.Printf("running: %q", stmt)
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "cannot apply migration %d (%s)", m.Version, m.Name)
+		}
+	}
+
+	record := struct {
+		Version int       `sql:"version"`
+		Name    string    `sql:"name"`
+		Applied time.Time `sql:"applied"`
+	}{m.Version, m.Name, time.Now().UTC()}
+	r := sqlutil.NewRecordType(record)
+	q := sqlutil.Insert().Into("schema_migration").Fields(r.Fields()...).Values(r)
+	if _, err := tx.ExecContext(ctx, d.Rebind(q.String()), q.QueryArgs()...); err != nil {
+		return errors.Wrapf(err, "cannot record migration %d", m.Version)
+	}
+
+	return errors.Wrapf(tx.Commit(), "cannot commit migration %d", m.Version)
+}
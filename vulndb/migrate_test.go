@@ -0,0 +1,37 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
+)
+
+func TestMigrations(t *testing.T) {
+	version := 0
+	for _, m := range Migrations {
+		if m.Version != version+1 {
+			t.Fatalf("migration %q: want version %d, have %d", m.Name, version+1, m.Version)
+		}
+		version = m.Version
+
+		for _, d := range []sqlutil.Dialect{sqlutil.MySQL, sqlutil.Postgres} {
+			if len(m.Statements(d)) == 0 {
+				t.Fatalf("migration %d: no statements for %s", m.Version, d)
+			}
+		}
+	}
+}
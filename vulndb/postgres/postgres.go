@@ -0,0 +1,58 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postgres provides a connector to vulndb via PostgreSQL.
+//
+// The package doesn't register a driver: programs import one registered
+// as "postgres", e.g. github.com/lib/pq.
+package postgres
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/vulndb/debug"
+)
+
+const postgresDriver = "postgres"
+
+// OpenRead opens a connection to PostgreSQL for reading.
+//
+// See https://pkg.go.dev/github.com/lib/pq#hdr-Connection_String_Parameters for dsn details.
+func OpenRead(dsn string) (*sql.DB, error) {
+	return openDB(dsn)
+}
+
+// OpenWrite opens a connection to PostgreSQL for writing.
+//
+// See https://pkg.go.dev/github.com/lib/pq#hdr-Connection_String_Parameters for dsn details.
+func OpenWrite(dsn string) (*sql.DB, error) {
+	return openDB(dsn)
+}
+
+func openDB(dsn string) (*sql.DB, error) {
+	if debug.V(1) {
+		log.Printf("connecting to %q", dsn)
+	}
+	db, err := sql.Open(postgresDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxIdleConns(0)
+	db.SetMaxOpenConns(10)
+	return db, nil
+}
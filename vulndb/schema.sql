@@ -15,6 +15,7 @@
 DROP TABLE IF EXISTS
 	`snooze`,
 	`custom_data`,
+	`vendor_cve`,
 	`vendor_data`,
 	`vendor`
 ;
@@ -48,6 +49,24 @@ DEFAULT CHARACTER SET utf8mb4
 COMMENT 'Vulnerability data from vendors'
 ;
 
+CREATE TABLE `vendor_cve` (
+	`provider`   VARCHAR(64)  NOT NULL COMMENT 'Short name of dataset provider',
+	`cve_id`     VARCHAR(128) NOT NULL COMMENT 'Common Vulnerability and Exposure (CVE) ID',
+	`owner`      VARCHAR(64)  NOT NULL COMMENT 'Point of contact for dataset',
+	`published`  TIMESTAMP    NOT NULL COMMENT 'Timestamp of vulnerability publication' DEFAULT CURRENT_TIMESTAMP,
+	`modified`   TIMESTAMP    NOT NULL COMMENT 'Timestamp of vulnerability last modification' DEFAULT CURRENT_TIMESTAMP,
+	`base_score` FLOAT(3,1)   NOT NULL COMMENT 'Base score from CVSS 3.0 or 2.0 fallback',
+	`summary`    TEXT         NOT NULL COMMENT 'Description of the vulnerability',
+	`cve_json`   MEDIUMBLOB   NOT NULL COMMENT 'JSON record containing raw CVE data',
+	`synced`     TIMESTAMP    NOT NULL COMMENT 'Timestamp of the last sync the vulnerability was in' DEFAULT CURRENT_TIMESTAMP,
+	`withdrawn`  TIMESTAMP        NULL COMMENT 'Timestamp of the sync the vulnerability was withdrawn in, NULL unless withdrawn' DEFAULT NULL,
+	PRIMARY KEY (`provider`, `cve_id`)
+)
+ENGINE InnoDB
+DEFAULT CHARACTER SET utf8mb4
+COMMENT 'Vulnerability data from vendors kept in sync with their sources'
+;
+
 CREATE TABLE `custom_data` (
 	`owner`       VARCHAR(64)  NOT NULL COMMENT 'Point of contact for dataset',
 	`provider`    VARCHAR(64)  NOT NULL COMMENT 'Short name of data provider',
@@ -38,6 +38,13 @@ func (c *QueryConditionSet) Equal(k string, v interface{}) *QueryConditionSet {
 	return c
 }
 
+// Less adds k<v to the set.
+func (c *QueryConditionSet) Less(k string, v interface{}) *QueryConditionSet {
+	c.qs = append(c.qs, fmt.Sprintf("%s<?", k))
+	c.qv = append(c.qv, v)
+	return c
+}
+
 // In adds k IN (v) to the condition set.
 // v must be a slice of any type or a *SelectStmt.
 func (c *QueryConditionSet) In(k string, v interface{}) *QueryConditionSet {
@@ -27,3 +27,16 @@ func TestQueryConditionSet(t *testing.T) {
 		t.Fatalf("unexpected query condition:\nwant: %q\nhave: %q\n", want, have)
 	}
 }
+
+func TestQueryConditionLess(t *testing.T) {
+	c := Cond().Equal("foo", "bar").And().Less("ts", 42)
+
+	want := "foo=? AND ts<?"
+	have := c.String()
+	if want != have {
+		t.Fatalf("unexpected query condition:\nwant: %q\nhave: %q\n", want, have)
+	}
+	if v := c.Values(); len(v) != 2 || v[1] != 42 {
+		t.Fatalf("unexpected query values: %v", v)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL dialect of the database. Statements are built with ? bindings and the MySQL
+// flavor of SQL, the dialect adapts them to the database, see Rebind and InsertStmt.Upsert.
+type Dialect int
+
+// Supported dialects.
+const (
+	MySQL Dialect = iota
+	Postgres
+)
+
+// ParseDialect returns the dialect of its name: mysql or postgres.
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "mysql":
+		return MySQL, nil
+	case "postgres", "postgresql":
+		return Postgres, nil
+	}
+	return MySQL, fmt.Errorf("unsupported sql dialect: %q", name)
+}
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case MySQL:
+		return "mysql"
+	case Postgres:
+		return "postgres"
+	}
+	return "Dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// Rebind returns the query with ? bindings replaced by the ones of the dialect,
+// e.g. $1, $2 for Postgres. Question marks quoted in the query are kept.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// upsert returns the clause updating the fields of the rows which already exist, identified by the keys.
+func (d Dialect) upsert(keys, fields []string) string {
+	set := make([]string, len(fields))
+	if d == Postgres {
+		for i, f := range fields {
+			set[i] = f + " = EXCLUDED." + f
+		}
+		return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+	}
+	for i, f := range fields {
+		set[i] = f + " = VALUES(" + f + ")"
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+}
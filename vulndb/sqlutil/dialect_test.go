@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlutil
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	cases := []struct {
+		Dialect Dialect
+		Have    string
+		Want    string
+	}{
+		{MySQL, "SELECT k FROM t WHERE k=? AND v=?", "SELECT k FROM t WHERE k=? AND v=?"},
+		{Postgres, "SELECT k FROM t WHERE k=? AND v=?", "SELECT k FROM t WHERE k=$1 AND v=$2"},
+		{Postgres, "SELECT '?' FROM t WHERE k IN (?, ?)", "SELECT '?' FROM t WHERE k IN ($1, $2)"},
+		{Postgres, "SELECT k FROM t", "SELECT k FROM t"},
+	}
+
+	for _, tc := range cases {
+		have := tc.Dialect.Rebind(tc.Have)
+		if have != tc.Want {
+			t.Fatalf("unexpected %s query:\nhave: %s\nwant: %s\n", tc.Dialect, have, tc.Want)
+		}
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	for name, want := range map[string]Dialect{"mysql": MySQL, "postgres": Postgres, "PostgreSQL": Postgres} {
+		have, err := ParseDialect(name)
+		if err != nil || have != want {
+			t.Fatalf("unexpected dialect of %q: %v, %v", name, have, err)
+		}
+	}
+	if _, err := ParseDialect("oracle"); err == nil {
+		t.Fatal("expected unsupported dialect to fail")
+	}
+}
@@ -127,6 +127,14 @@ func (s *InsertStmt) Values(records ...Record) *InsertStmt {
 	return s
 }
 
+// Upsert adds the clause of the dialect updating the fields of the rows which already exist
+// rather than failing the INSERT; keys are the fields of the unique key identifying the rows,
+// only Postgres needs them.
+func (s *InsertStmt) Upsert(d Dialect, keys []string, fields ...string) *InsertStmt {
+	s.add(d.upsert(keys, fields))
+	return s
+}
+
 // Select adds a Select to the statement.
 func (s *InsertStmt) Select(stmt *SelectStmt) *InsertStmt {
 	s.add(stmt.q...)
@@ -132,3 +132,28 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("unexpected statement:\nhave: %v\nwant: %v\n", haveval, wantval)
 	}
 }
+
+func TestUpsert(t *testing.T) {
+	r := NewRecordType(sampleRecord{K: "hello", V: 42}).Subset("foo", "bar")
+	cases := []struct {
+		Dialect Dialect
+		Want    string
+	}{
+		{
+			Dialect: MySQL,
+			Want:    "INSERT INTO table (foo, bar) VALUES (?, ?) ON DUPLICATE KEY UPDATE bar = VALUES(bar)",
+		},
+		{
+			Dialect: Postgres,
+			Want:    "INSERT INTO table (foo, bar) VALUES ($1, $2) ON CONFLICT (foo) DO UPDATE SET bar = EXCLUDED.bar",
+		},
+	}
+
+	for _, tc := range cases {
+		stmt := Insert().Into("table").Fields(r.Fields()...).Values(r).Upsert(tc.Dialect, []string{"foo"}, "bar")
+		have := tc.Dialect.Rebind(stmt.String())
+		if have != tc.Want {
+			t.Fatalf("unexpected %s statement:\nhave: %s\nwant: %s\n", tc.Dialect, have, tc.Want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulndb
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/facebookincubator/nvdtools/vulndb/debug"
+	"github.com/facebookincubator/nvdtools/vulndb/sqlutil"
+)
+
+// VendorCVERecord represents a db record of the `vendor_cve` table.
+type VendorCVERecord struct {
+	Provider  string     `sql:"provider"`
+	CVE       string     `sql:"cve_id"`
+	Owner     string     `sql:"owner"`
+	Published time.Time  `sql:"published"`
+	Modified  time.Time  `sql:"modified"`
+	BaseScore float64    `sql:"base_score"`
+	Summary   string     `sql:"summary"`
+	JSON      []byte     `sql:"cve_json"`
+	Synced    time.Time  `sql:"synced"`
+	Withdrawn *time.Time `sql:"withdrawn"`
+}
+
+// vendorCVEUpdates are the fields of vendor_cve records updated by every sync.
+var vendorCVEUpdates = []string{
+	"owner",
+	"published",
+	"modified",
+	"base_score",
+	"summary",
+	"cve_json",
+	"synced",
+	"withdrawn",
+}
+
+// VendorDataSyncer is a helper for keeping the vulnerability data of a provider
+// in sync with its source, in the `vendor_cve` table.
+//
+// Unlike VendorDataImporter, which imports a new version of the dataset every
+// time, the syncer updates the CVEs of the provider in place, keyed on provider
+// and CVE ID: CVEs of the files are inserted or updated, and the CVEs synced
+// before which are no longer in the files are marked withdrawn rather than
+// deleted. CVEs reappearing in the files are restored. Every sync runs in a
+// single transaction, so the data is never left half synced.
+//
+// The database must be migrated, see Migrate.
+type VendorDataSyncer struct {
+	DB       *sql.DB
+	Dialect  sqlutil.Dialect
+	Owner    string
+	Provider string
+	OnFile   func(filename string)
+
+	// AllowEmpty allows syncing files without CVEs, which withdraws all
+	// the CVEs of the provider; it's an error otherwise, so a broken source
+	// doesn't wipe the data of the provider.
+	AllowEmpty bool
+}
+
+// SyncStats are the results of a sync.
+type SyncStats struct {
+	Synced    int   // CVEs of the files, inserted or updated
+	Withdrawn int64 // CVEs withdrawn from the source since the previous sync
+}
+
+// SyncFiles syncs the CVEs of the provider with the files, formatted as NVD CVE
+// JSON 1.0 optionally gzipped; CVEs in several files are synced as per the last one.
+func (v VendorDataSyncer) SyncFiles(ctx context.Context, files ...string) (*SyncStats, error) {
+	now := time.Now().UTC().Truncate(time.Second) // TIMESTAMP precision
+	records, err := v.recordsFromFiles(now, files...)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 && !v.AllowEmpty {
+		return nil, errors.Errorf("no CVEs to sync for provider %q, refusing to withdraw them all", v.Provider)
+	}
+
+	tx, err := v.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot begin sync")
+	}
+	defer tx.Rollback()
+
+	// records not synced now are withdrawn, so now must follow the last sync
+	// of the provider, even a sync within the same second
+	last, err := v.lastSynced(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !now.After(last) {
+		now = last.Add(time.Second)
+		for i := range records {
+			records[i].Synced = now
+		}
+	}
+
+	if err = v.upsert(ctx, tx, records); err != nil {
+		return nil, err
+	}
+
+	withdrawn, err := v.withdraw(ctx, tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "cannot commit sync")
+	}
+
+	return &SyncStats{Synced: len(records), Withdrawn: withdrawn}, nil
+}
+
+// lastSynced returns the time of the last sync of the provider, zero time if none.
+func (v VendorDataSyncer) lastSynced(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	q := sqlutil.Select("MAX(synced)").From("vendor_cve").Where(
+		sqlutil.Cond().Equal("provider", v.Provider),
+	)
+
+	query, args := v.Dialect.Rebind(q.String()), q.QueryArgs()
+
+	if debug.V(1) {
+		log.Printf("running: %q / %#v", query, args)
+	}
+
+	var last sqlutil.NullTime
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&last); err != nil {
+		return time.Time{}, errors.Wrap(err, "cannot query last sync")
+	}
+	return last.Time, nil
+}
+
+// recordsFromFiles loads the records of the files, one per CVE, sorted by CVE ID.
+func (v VendorDataSyncer) recordsFromFiles(synced time.Time, files ...string) ([]VendorCVERecord, error) {
+	byID := make(map[string]VendorCVERecord)
+	for _, file := range files {
+		if v.OnFile != nil {
+			v.OnFile(file)
+		}
+		feed, err := readNVDCVEJSON(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot load vendor file")
+		}
+		for _, item := range feed.CVEItems {
+			cve := cveItem{item}
+			if cve.ID() == "" {
+				continue
+			}
+			byID[cve.ID()] = VendorCVERecord{
+				Provider:  v.Provider,
+				CVE:       cve.ID(),
+				Owner:     v.Owner,
+				Published: cve.Published(),
+				Modified:  cve.Modified(),
+				BaseScore: cve.BaseScore(),
+				Summary:   cve.Summary(),
+				JSON:      cve.JSON(),
+				Synced:    synced,
+			}
+		}
+	}
+
+	records := make([]VendorCVERecord, 0, len(byID))
+	for _, r := range byID {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CVE < records[j].CVE })
+	return records, nil
+}
+
+func (v VendorDataSyncer) upsert(ctx context.Context, tx *sql.Tx, data []VendorCVERecord) error {
+	r := sqlutil.NewRecords(data)
+
+	const batchSize = 10
+	for i := 0; i < len(data); i += batchSize {
+		lim := i + batchSize
+		if lim > len(data) {
+			lim = len(data)
+		}
+
+		s := r[i:lim]
+		q := sqlutil.Insert().
+			Into("vendor_cve").
+			Fields(s.Fields()...).
+			Values(s...).
+			Upsert(v.Dialect, []string{"provider", "cve_id"}, vendorCVEUpdates...)
+
+		query, args := v.Dialect.Rebind(q.String()), q.QueryArgs()
+
+		if debug.V(2) {
+			log.Printf("running: %q", query)
+		}
+
+		_, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.Wrap(err, "cannot upsert vendor cve records")
+		}
+	}
+
+	return nil
+}
+
+// withdraw marks the CVEs of the provider not synced by the sync withdrawn.
+func (v VendorDataSyncer) withdraw(ctx context.Context, tx *sql.Tx, synced time.Time) (int64, error) {
+	q := sqlutil.Update("vendor_cve").Set(
+		sqlutil.Assign().Equal("withdrawn", synced),
+	).Where(
+		sqlutil.Cond().
+			Equal("provider", v.Provider).
+			And().
+			Less("synced", synced).
+			And().
+			IsNull("withdrawn"),
+	)
+
+	query, args := v.Dialect.Rebind(q.String()), q.QueryArgs()
+
+	if debug.V(1) {
+		log.Printf("running: %q / %#v", query, args)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot withdraw vendor cve records")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot count withdrawn vendor cve records")
+	}
+
+	return n, nil
+}
+
+// PurgeWithdrawn deletes the CVEs of the provider withdrawn before the time
+// for good, and returns the number of CVEs deleted.
+func (v VendorDataSyncer) PurgeWithdrawn(ctx context.Context, before time.Time) (int64, error) {
+	q := sqlutil.Delete().From("vendor_cve").Where(
+		sqlutil.Cond().
+			Equal("provider", v.Provider).
+			And().
+			Less("withdrawn", before.UTC()),
+	)
+
+	query, args := v.Dialect.Rebind(q.String()), q.QueryArgs()
+
+	if debug.V(1) {
+		log.Printf("running: %q / %#v", query, args)
+	}
+
+	res, err := v.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot purge withdrawn vendor cve records")
+	}
+
+	return res.RowsAffected()
+}
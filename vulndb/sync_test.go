@@ -0,0 +1,63 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulndb
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSyncRecordsFromFiles(t *testing.T) {
+	f, err := createSampleCVE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	v := VendorDataSyncer{
+		Owner:    "test",
+		Provider: "test",
+	}
+
+	synced := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// CVEs in more than one file are synced once
+	records, err := v.recordsFromFiles(synced, f.Name(), f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("want 2 records, have %d", len(records))
+	}
+
+	for i, want := range []string{"CVE-0000-0000", "CVE-0000-0001"} {
+		r := records[i]
+		if r.CVE != want {
+			t.Fatalf("record %d: want %s, have %s", i, want, r.CVE)
+		}
+		if r.Provider != "test" || r.Owner != "test" {
+			t.Fatalf("record %d: unexpected provider or owner: %#v", i, r)
+		}
+		if !r.Synced.Equal(synced) || r.Withdrawn != nil {
+			t.Fatalf("record %d: unexpected sync time: %#v", i, r)
+		}
+		if r.Summary != "hello world" || len(r.JSON) == 0 {
+			t.Fatalf("record %d: unexpected data: %#v", i, r)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+)
+
+// Field identifies one of the WFN attributes
+type Field int
+
+// Possible values of Field type, in the order of formatted string binding
+const (
+	FieldPart Field = iota
+	FieldVendor
+	FieldProduct
+	FieldVersion
+	FieldUpdate
+	FieldEdition
+	FieldLanguage
+	FieldSWEdition
+	FieldTargetSW
+	FieldTargetHW
+	FieldOther
+)
+
+// Fields lists all WFN attributes in the order of formatted string binding
+var Fields = []Field{
+	FieldPart,
+	FieldVendor,
+	FieldProduct,
+	FieldVersion,
+	FieldUpdate,
+	FieldEdition,
+	FieldLanguage,
+	FieldSWEdition,
+	FieldTargetSW,
+	FieldTargetHW,
+	FieldOther,
+}
+
+// String returns the name of the attribute as per [CPE23-N:5.3]
+func (f Field) String() string {
+	switch f {
+	case FieldPart:
+		return "part"
+	case FieldVendor:
+		return "vendor"
+	case FieldProduct:
+		return "product"
+	case FieldVersion:
+		return "version"
+	case FieldUpdate:
+		return "update"
+	case FieldEdition:
+		return "edition"
+	case FieldLanguage:
+		return "language"
+	case FieldSWEdition:
+		return "sw_edition"
+	case FieldTargetSW:
+		return "target_sw"
+	case FieldTargetHW:
+		return "target_hw"
+	case FieldOther:
+		return "other"
+	default:
+		return fmt.Sprintf("Undefined field %d", f)
+	}
+}
+
+// Get returns the value of the attribute identified by field; unknown fields are reported as ANY
+func (a *Attributes) Get(field Field) string {
+	if a == nil {
+		return Any
+	}
+	switch field {
+	case FieldPart:
+		return a.Part
+	case FieldVendor:
+		return a.Vendor
+	case FieldProduct:
+		return a.Product
+	case FieldVersion:
+		return a.Version
+	case FieldUpdate:
+		return a.Update
+	case FieldEdition:
+		return a.Edition
+	case FieldLanguage:
+		return a.Language
+	case FieldSWEdition:
+		return a.SWEdition
+	case FieldTargetSW:
+		return a.TargetSW
+	case FieldTargetHW:
+		return a.TargetHW
+	case FieldOther:
+		return a.Other
+	default:
+		return Any
+	}
+}
+
+// IsAny returns true if the attribute identified by field has logical value ANY
+func (a *Attributes) IsAny(field Field) bool {
+	return a.Get(field) == Any
+}
+
+// IsNA returns true if the attribute identified by field has logical value NA
+func (a *Attributes) IsNA(field Field) bool {
+	return a.Get(field) == NA
+}
+
+// EqualIgnoring returns true if a and other are the same CPE name once normalized (see Equal),
+// disregarding the attributes identified by fields, e.g. FieldUpdate and FieldLanguage; nil equals nil only
+func (a *Attributes) EqualIgnoring(other *Attributes, fields ...Field) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+	ignored := make(map[Field]bool, len(fields))
+	for _, f := range fields {
+		ignored[f] = true
+	}
+	for _, f := range Fields {
+		if !ignored[f] && normalizeValue(a.Get(f)) != normalizeValue(other.Get(f)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"testing"
+)
+
+func TestIsAnyIsNA(t *testing.T) {
+	attr, err := Parse("cpe:2.3:a:microsoft:ie:6\\.0:-:*:*:*:*:*:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		field Field
+		any   bool
+		na    bool
+	}{
+		{FieldPart, false, false},
+		{FieldVendor, false, false},
+		{FieldVersion, false, false},
+		{FieldUpdate, false, true},
+		{FieldEdition, true, false},
+		{FieldOther, true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.field.String(), func(t *testing.T) {
+			if attr.IsAny(c.field) != c.any {
+				t.Errorf("IsAny(%v) expected to be %t", c.field, c.any)
+			}
+			if attr.IsNA(c.field) != c.na {
+				t.Errorf("IsNA(%v) expected to be %t", c.field, c.na)
+			}
+		})
+	}
+}
+
+func TestFieldString(t *testing.T) {
+	if len(Fields) != 11 {
+		t.Fatalf("expected 11 fields, got %d", len(Fields))
+	}
+	if s := FieldSWEdition.String(); s != "sw_edition" {
+		t.Errorf("unexpected name of sw_edition field: %q", s)
+	}
+	if s := Field(42).String(); s != "Undefined field 42" {
+		t.Errorf("unexpected name of undefined field: %q", s)
+	}
+}
+
+func TestEqualIgnoring(t *testing.T) {
+	a := &Attributes{Part: "a", Vendor: "Microsoft", Product: "ie", Version: `6\.0`, Update: "sp1", Language: "en"}
+	b := &Attributes{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6.0", Update: "sp2", Language: Any}
+	if a.EqualIgnoring(b) {
+		t.Errorf("%v and %v are expected to differ", a, b)
+	}
+	if a.EqualIgnoring(b, FieldUpdate) {
+		t.Errorf("%v and %v are expected to differ in language", a, b)
+	}
+	if !a.EqualIgnoring(b, FieldUpdate, FieldLanguage) {
+		t.Errorf("%v and %v are expected to be equal ignoring update and language", a, b)
+	}
+	if b.Product = "edge"; a.EqualIgnoring(b, FieldUpdate, FieldLanguage) {
+		t.Errorf("%v and %v are expected to differ in product", a, b)
+	}
+	var none *Attributes
+	if a.EqualIgnoring(none) || none.EqualIgnoring(a, Fields...) || !none.EqualIgnoring(nil) {
+		t.Error("nil is expected to equal nil only")
+	}
+}
@@ -41,13 +41,23 @@ func (a Attributes) BindToFmtString() string {
 	return fsbPrefix + strings.Join(parts, ":")
 }
 
-// UnbindFmtString loads WFN from formatted string
+// MaxFmtStringLength is the length of the longest formatted string UnbindFmtString accepts,
+// well above the longest CPE names of NVD CPE dictionary; it bounds the memory untrusted input can take
+const MaxFmtStringLength = 4096
+
+// UnbindFmtString loads WFN from formatted string.
+// Malformed strings (e.g. with a trailing backslash, the number of components other than 11 or a part other than
+// a, o, h, * and -) are an error.
 func UnbindFmtString(s string) (*Attributes, error) {
+	if len(s) > MaxFmtStringLength {
+		return nil, fmt.Errorf("FSB is too long: %d bytes, at most %d allowed", len(s), MaxFmtStringLength)
+	}
 	if !strings.HasPrefix(s, fsbPrefix) {
 		return nil, fmt.Errorf("bad prefix in FSB %q", s)
 	}
 	attr := &Attributes{}
-	for i, partN := len(fsbPrefix), 0; i < len(s); i, partN = i+1, partN+1 {
+	partN := 0
+	for i := len(fsbPrefix); i < len(s); i, partN = i+1, partN+1 {
 		var err error
 		switch partN {
 		case 0:
@@ -72,11 +82,22 @@ func UnbindFmtString(s string) (*Attributes, error) {
 			attr.TargetHW, i, err = unbindValueFSAt(s, i)
 		case 10:
 			attr.Other, i, err = unbindValueFSAt(s, i)
+		default:
+			err = fmt.Errorf("too many components in %q", s)
+		}
+		if err == nil && i == len(s)-1 {
+			err = fmt.Errorf("empty last component in %q", s)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("unbind formatted string: %v", err)
 		}
 	}
+	if partN != 11 {
+		return nil, fmt.Errorf("unbind formatted string: expected 11 components, got %d in %q", partN, s)
+	}
+	if err := checkPart(attr.Part); err != nil {
+		return nil, fmt.Errorf("unbind formatted string: %v in %q", err, s)
+	}
 	return attr, nil
 }
 
@@ -95,29 +116,49 @@ func StripSlashes(s string) string {
 	return string(out)
 }
 
+// bindValueFS binds attribute value to formatted string: quoted '.', '_' and '-' pass unquoted, except for
+// the lone hyphen, which would read as NA; punctuation left unquoted in the value is quoted, so it can't break
+// the string apart
 func bindValueFS(s string) string {
 	switch s {
 	case Any:
 		return "*"
 	case NA:
 		return "-"
-	default:
-		return StripSlashes(s)
+	case "\\-":
+		return s
 	}
+	out := make([]byte, 0, len(s)+len(s)/2)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i < len(s)-1:
+			i++
+			switch s[i] {
+			case '.', '_', '-': // these pass unquoted
+			default:
+				out = append(out, c)
+			}
+			c = s[i]
+		case isAlnum(c), c == '_', c == '.', c == '-', c == '*', c == '?', c >= 0x80:
+		default:
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return string(out)
 }
 
 func unbindValueFSAt(s string, at int) (string, int, error) {
 	if len(s)-at < 1 || s[at] == ':' {
 		return Any, at, fmt.Errorf("could not unbind attribute at pos %d", at)
 	}
-	if len(s)-at == 1 || s[at+1] == ':' {
+	if (len(s)-at == 1 || s[at+1] == ':') && s[at] != '\\' {
 		switch s[at] {
 		case '*':
 			return Any, at + 1, nil
 		case '-':
 			return NA, at + 1, nil
-		default:
-			return s[at : at+1], at + 1, nil
 		}
 	}
 	return addSlashesAt(s, at)
@@ -136,6 +177,9 @@ func addSlashesAt(s string, at int) (string, int, error) {
 		}
 		switch c {
 		case '\\':
+			if i == len(s)-1 {
+				return Any, i, fmt.Errorf("trailing '\\' in the FSB fragment %q", s)
+			}
 			i++
 			b = append(b, c, s[i])
 			embedded = true
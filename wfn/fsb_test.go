@@ -16,6 +16,7 @@ package wfn
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +54,19 @@ func TestUnbindFmtString(t *testing.T) {
 			FSB:  "cpe:2.3:a:hp:insight_diagnostics:7.4.*.1570:*:*:*:*:*:*",
 			Fail: true,
 		},
+		{
+			FSB:    `cpe:2.3:a:foo\:bar:baz:1.0:*:*:*:*:*:*:*`, // escaped colon is a part of the value
+			Expect: `wfn:[part="a",vendor="foo\:bar",product="baz",version="1\.0",update=ANY,edition=ANY,language=ANY]`,
+		},
+		{FSB: `cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:\`, Fail: true},                               // lone trailing backslash
+		{FSB: `cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:x\`, Fail: true},                              // trailing backslash
+		{FSB: `cpe:2.3:a:foo:bar\:*:*:*:*:*:*:*:*`, Fail: true},                                // escaped separator leaves too few components
+		{FSB: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*", Fail: true},     // too few components
+		{FSB: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*:*", Fail: true}, // too many components
+		{FSB: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*:", Fail: true},  // empty last component
+		{FSB: "cpe:2.3:a:foo", Fail: true},
+		{FSB: "cpe:2.3:", Fail: true},
+		{FSB: "cpe:2.3:a:" + strings.Repeat("x", MaxFmtStringLength) + ":bar:*:*:*:*:*:*:*:*", Fail: true}, // oversized
 	}
 	for _, tc := range cases {
 		tc := tc
@@ -74,6 +88,34 @@ func TestUnbindFmtString(t *testing.T) {
 	}
 }
 
+func FuzzUnbindFS(f *testing.F) {
+	for _, s := range []string{
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		"cpe:2.3:a:microsoft:internet_exp?????:8.*:sp?:*:*:*:*:*:*",
+		`cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`,
+		`cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:\`,
+		"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:",
+		"cpe:2.3:a",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		attr, err := UnbindFmtString(s)
+		if err != nil {
+			return
+		}
+		// a successfully parsed name must survive the round trip
+		fsb := attr.BindToFmtString()
+		again, err := UnbindFmtString(fsb)
+		if err != nil {
+			t.Fatalf("%q parsed as %v, but its binding %q doesn't parse: %v", s, attr, fsb, err)
+		}
+		if *again != *attr {
+			t.Fatalf("%q parsed as %v, but its binding %q parsed as %v", s, attr, fsb, again)
+		}
+	})
+}
+
 func BenchmarkUnbindFmtString(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		UnbindFmtString("cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*")
@@ -0,0 +1,172 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Guess is a candidate CPE name guessed from a free-form product string, see GuessCPE
+type Guess struct {
+	Attributes Attributes
+	Confidence float64 // in (0, 1], the higher the more likely the guess is right
+}
+
+// ProductVendors returns the vendors a CPE dictionary knows the product of, none if the product is unknown;
+// products are passed WFNized in lower case, as they appear in CPE names
+type ProductVendors func(product string) []string
+
+var (
+	// packageArch matches the architecture and the extension of package file names
+	packageArch = regexp.MustCompile(`[._](noarch|x86_64|i[3-6]86|aarch64|ppc64le|s390x|src|amd64|arm64|armhf|all)(\.(rpm|deb))?$|\.(rpm|deb)$`)
+	// rpmName matches name-version-release of RPM packages
+	rpmName = regexp.MustCompile(`^([a-z0-9][a-z0-9+._-]*?)-([0-9][a-z0-9+._~^]*)(-[a-z0-9+._~^]+)?$`)
+	// debName matches name_[epoch:]version[-revision] of Debian packages
+	debName = regexp.MustCompile(`^([a-z0-9][a-z0-9+.-]*)_([0-9]+:)?([0-9][a-z0-9+.~]*)(-[a-z0-9+.~]+)?$`)
+	// versionToken matches the words of free-form strings which are versions, e.g. 9.0.31, v2 or 2019
+	versionToken = regexp.MustCompile(`^v?([0-9][a-z0-9+._-]*)$`)
+)
+
+// noiseWords don't name the product in free-form strings
+var noiseWords = map[string]bool{
+	"version": true, "ver": true, "release": true, "edition": true,
+	"x64": true, "x86": true, "64-bit": true, "32-bit": true, "(x64)": true, "(x86)": true,
+}
+
+// GuessCPE guesses the CPE names of an application from a free-form product string, e.g. "Apache Tomcat 9.0.31"
+// or a package file name, e.g. "openssl-1.1.1k-5.el8": the words before the version are taken for the vendor and
+// the product, the words after it for the update; the release of packages is dropped, as it's specific to the
+// distribution. Candidates are ranked by confidence, the most likely first.
+// This function isn't a part of standard, it's a heuristic: the guesses are only as good as vendors, if it isn't nil,
+// the CPE dictionary rates and extends them with the vendors it knows the products of.
+func GuessCPE(s string, vendors ProductVendors) []Guess {
+	words, version, update := splitProductString(strings.ToLower(strings.TrimSpace(s)))
+	if len(words) == 0 {
+		return nil
+	}
+	type candidate struct{ vendor, product string }
+	confidence := make(map[candidate]float64)
+	add := func(vendor, product string, c float64) {
+		if c > confidence[candidate{vendor, product}] {
+			confidence[candidate{vendor, product}] = c
+		}
+	}
+	all := strings.Join(words, "_")
+	if len(words) == 1 {
+		add(all, all, 0.6)
+	} else {
+		rest := strings.Join(words[1:], "_")
+		add(words[0], rest, 0.6)
+		add(all, all, 0.4)
+		add(words[0], all, 0.3)
+	}
+
+	if vendors != nil {
+		guessed := make(map[candidate]float64, len(confidence))
+		for c, conf := range confidence {
+			guessed[c] = conf
+		}
+		for c, conf := range guessed {
+			known := vendors(wfnizeGuess(c.product))
+			if len(known) == 0 {
+				confidence[c] = conf / 2
+				continue
+			}
+			found := false
+			for _, v := range known {
+				if v == wfnizeGuess(c.vendor) {
+					found = true
+				}
+			}
+			if found {
+				confidence[c] = conf + (1-conf)*0.9
+				continue
+			}
+			confidence[c] = conf / 2
+			for _, v := range known {
+				add(v, c.product, conf+(1-conf)*0.5)
+			}
+		}
+	}
+
+	guesses := make([]Guess, 0, len(confidence))
+	for c, conf := range confidence {
+		attrs := Attributes{
+			Part:    "a",
+			Vendor:  wfnizeGuess(c.vendor),
+			Product: wfnizeGuess(c.product),
+			Version: wfnizeGuess(version),
+			Update:  wfnizeGuess(update),
+		}
+		if attrs.Vendor == Any || attrs.Product == Any {
+			continue
+		}
+		guesses = append(guesses, Guess{Attributes: attrs, Confidence: conf})
+	}
+	sort.Slice(guesses, func(i, j int) bool {
+		if guesses[i].Confidence != guesses[j].Confidence {
+			return guesses[i].Confidence > guesses[j].Confidence
+		}
+		if guesses[i].Attributes.Vendor != guesses[j].Attributes.Vendor {
+			return guesses[i].Attributes.Vendor < guesses[j].Attributes.Vendor
+		}
+		return guesses[i].Attributes.Product < guesses[j].Attributes.Product
+	})
+	return guesses
+}
+
+// splitProductString splits package file name or free-form product string into the words naming the product,
+// the version and the update
+func splitProductString(s string) (words []string, version, update string) {
+	if !strings.ContainsAny(s, " \t") {
+		pkg := packageArch.ReplaceAllString(s, "")
+		if m := debName.FindStringSubmatch(pkg); m != nil {
+			return strings.Split(m[1], "-"), m[3], ""
+		}
+		if m := rpmName.FindStringSubmatch(pkg); m != nil {
+			return strings.Split(m[1], "-"), m[2], ""
+		}
+	}
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		f = strings.Trim(f, ",;")
+		if m := versionToken.FindStringSubmatch(f); m != nil && i > 0 {
+			version = m[1]
+			var updates []string
+			for _, u := range fields[i+1:] {
+				if !noiseWords[u] {
+					updates = append(updates, u)
+				}
+			}
+			update = strings.Join(updates, "_")
+			break
+		}
+		if !noiseWords[f] && f != "" {
+			words = append(words, f)
+		}
+	}
+	return words, version, update
+}
+
+// wfnizeGuess WFNizes the value, dropping invalid ones
+func wfnizeGuess(s string) string {
+	v, err := WFNize(s)
+	if err != nil {
+		return Any
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"testing"
+)
+
+func TestGuessCPE(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"Apache Tomcat 9.0.31", "cpe:2.3:a:apache:tomcat:9.0.31:*:*:*:*:*:*:*"},
+		{"Microsoft Internet Explorer 8 SP1", "cpe:2.3:a:microsoft:internet_explorer:8:sp1:*:*:*:*:*:*"},
+		{"Google Chrome v96.0.4664.110 (x64)", "cpe:2.3:a:google:chrome:96.0.4664.110:*:*:*:*:*:*:*"},
+		{"openssl-1.1.1k-5.el8", "cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*"},
+		{"openssl-1.1.1k-5.el8.x86_64.rpm", "cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*"},
+		{"curl_7.68.0-1ubuntu2.7_amd64.deb", "cpe:2.3:a:curl:curl:7.68.0:*:*:*:*:*:*:*"},
+		{"nginx", "cpe:2.3:a:nginx:nginx:*:*:*:*:*:*:*:*"},
+	}
+	for _, c := range cases {
+		guesses := GuessCPE(c.in, nil)
+		if len(guesses) == 0 {
+			t.Errorf("%q: no guesses", c.in)
+			continue
+		}
+		if got := guesses[0].Attributes.BindToFmtString(); got != c.want {
+			t.Errorf("%q: want %s, got %s", c.in, c.want, got)
+		}
+		for i := 1; i < len(guesses); i++ {
+			if guesses[i].Confidence > guesses[i-1].Confidence {
+				t.Errorf("%q: guesses aren't ranked: %+v", c.in, guesses)
+			}
+		}
+	}
+	if guesses := GuessCPE("  ", nil); len(guesses) != 0 {
+		t.Errorf("expected no guesses for empty string, got %+v", guesses)
+	}
+}
+
+func TestGuessCPEWithDictionary(t *testing.T) {
+	vendors := func(product string) []string {
+		if product == "tomcat" {
+			return []string{"apache"}
+		}
+		return nil
+	}
+	guesses := GuessCPE("Tomcat 9.0.31", vendors)
+	if len(guesses) == 0 {
+		t.Fatal("no guesses")
+	}
+	best := guesses[0]
+	if best.Attributes.Vendor != "apache" || best.Attributes.Product != "tomcat" || best.Attributes.Version != "9\\.0\\.31" {
+		t.Errorf("expected the vendor known to the dictionary, got %+v", best.Attributes)
+	}
+	if plain := GuessCPE("Tomcat 9.0.31", nil); best.Confidence <= plain[0].Confidence {
+		t.Errorf("expected the dictionary to raise confidence: %v <= %v", best.Confidence, plain[0].Confidence)
+	}
+
+	known := GuessCPE("Apache Tomcat 9.0.31", vendors)
+	if known[0].Attributes.Vendor != "apache" || known[0].Confidence <= 0.6 {
+		t.Errorf("expected confirmed guess to be rated higher, got %+v", known[0])
+	}
+}
@@ -16,6 +16,7 @@ package wfn
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Possible values of Relation type
@@ -280,6 +281,28 @@ func Match(src, tgt *Attributes) bool {
 		matchAttr(src.Other, tgt.Other)
 }
 
+// WildcardMatch returns true if attributes of a, which might contain wildcards, match wildcard-free attributes of tgt.
+// Unquoted asterisk matches zero or more characters, unquoted question mark matches exactly one character,
+// e.g. product "http_*" matches "http_server"; quoted \* and \? match the literal symbols.
+// Unlike Match, it compares values insensitive to lexical case, which makes it suitable for fuzzy CPE lookups.
+func (a *Attributes) WildcardMatch(tgt *Attributes) bool {
+	if a == nil || tgt == nil {
+		return false
+	}
+	for _, f := range Fields {
+		if !WildcardMatchValue(a.Get(f), tgt.Get(f)) {
+			return false
+		}
+	}
+	return true
+}
+
+// WildcardMatchValue returns true if attribute value src, which might contain wildcards, matches value tgt;
+// see Attributes.WildcardMatch
+func WildcardMatchValue(src, tgt string) bool {
+	return matchAttr(strings.ToLower(src), strings.ToLower(tgt))
+}
+
 // CompareAttr calculates a relation between a pair of wfn attribute values.
 // Accordingly to standard, string matching must be insensitive to lexical case,
 // target A-V must not have wildcards.
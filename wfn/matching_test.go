@@ -72,6 +72,58 @@ func TestMatchStr(t *testing.T) {
 	}
 }
 
+func TestWildcardMatch(t *testing.T) {
+	cases := []struct {
+		Src    string
+		Tgt    string
+		Expect bool
+	}{
+		// trailing
+		{"http_*", "http_server", true},
+		{"http_*", "http_", true},
+		{"http_*", "http", false},
+		{"http_serve?", "http_server", true},
+		{"http_serve?", "http_serve", false},
+		// leading
+		{"*_server", "http_server", true},
+		{"*_server", "http_server_tools", false},
+		{"?ttp_server", "http_server", true},
+		// embedded
+		{"ht*_ser?er", "http_server", true},
+		{"h*p*r", "http_server", true},
+		{"h*x*r", "http_server", false},
+		{"ht?p_server", "htp_server", false},
+		// quoted literals
+		{"foo\\*", "foo\\*", true},
+		{"foo\\*", "foobar", false},
+		{"foo\\?", "foo\\?", true},
+		{"foo\\?", "foox", false},
+		{"foo\\\\*", "foo\\\\bar", true},
+		// case insensitivity
+		{"HTTP_*", "http_server", true},
+		// logical values
+		{Any, "http_server", true},
+		{NA, "http_server", false},
+		{NA, NA, true},
+		{"http_*", NA, false},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
+			if r := WildcardMatchValue(c.Src, c.Tgt); r != c.Expect {
+				t.Fatalf("WildcardMatchValue returned %t, %t was expected", r, c.Expect)
+			}
+		})
+	}
+
+	src := &Attributes{Part: "a", Vendor: "apache", Product: "http_*"}
+	if !src.WildcardMatch(&Attributes{Part: "a", Vendor: "apache", Product: "http_server", Version: "2\\.4\\.1"}) {
+		t.Error("expected http_* to match http_server")
+	}
+	if src.WildcardMatch(&Attributes{Part: "a", Vendor: "nginx", Product: "http_server"}) {
+		t.Error("expected vendor mismatch to fail the match")
+	}
+}
+
 func TestCompare(t *testing.T) {
 	cases := []struct {
 		Src    string
@@ -173,3 +225,40 @@ func BenchmarkHasWildcard(b *testing.B) {
 		HasWildcard(srcAttr.Product)
 	}
 }
+
+func TestMatchOther(t *testing.T) {
+	cases := []struct {
+		Src    string
+		Tgt    string
+		Expect bool
+	}{
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:enterprise", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", false},
+		{"cpe:/a:v:p:1.0::~~~~~enterprise", "cpe:/a:v:p:1.0::~~~~~community", false},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:enterprise", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:enterprise", true},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:*", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", true},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:-", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", false},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:-", false},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:comm*", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", true},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:ent*", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", false},
+		{"cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:communit?", "cpe:2.3:a:v:p:1.0:*:*:*:*:*:*:community", true},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
+			src, err := Parse(c.Src)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", c.Src, err)
+			}
+			tgt, err := Parse(c.Tgt)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", c.Tgt, err)
+			}
+			if r := Match(src, tgt); r != c.Expect {
+				t.Fatalf("Match returned %v, %v was expected", r, c.Expect)
+			}
+			if r, err := Compare(src, tgt); err != nil || (r.Relation() != Disjoint) != c.Expect {
+				t.Fatalf("Compare returned %v (%v), disjoint %v was expected", r.Relation(), err, !c.Expect)
+			}
+		})
+	}
+}
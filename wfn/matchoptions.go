@@ -0,0 +1,58 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+// MatchOptions tune the semantics of matching CPE names, see MatchOptions.Match; different scanners need different
+// strictness. The zero value matches as Match does.
+type MatchOptions struct {
+	// IgnoreTargetSW and IgnoreTargetHW disregard target_sw and target_hw attributes,
+	// e.g. the CVE of product on android matches the product on ios
+	IgnoreTargetSW bool
+	IgnoreTargetHW bool
+	// AnyNotNA disallows ANY attributes of the target (the input, e.g. inventory) to match NA attributes of the source
+	// (e.g. CVE): the product of unknown update doesn't match the CVE of the product without updates
+	AnyNotNA bool
+	// ExactVersion only matches equal versions: ANY and wildcards of either side don't match specific versions;
+	// CVE matchers don't match the version ranges either
+	ExactVersion bool
+}
+
+// Match is like Match function, but the attributes are compared as per the options
+func (o MatchOptions) Match(src, tgt *Attributes) bool {
+	if o == (MatchOptions{}) {
+		return Match(src, tgt)
+	}
+	if src == nil || tgt == nil {
+		return false
+	}
+	for _, f := range Fields {
+		s, t := src.Get(f), tgt.Get(f)
+		switch {
+		case f == FieldTargetSW && o.IgnoreTargetSW, f == FieldTargetHW && o.IgnoreTargetHW:
+			continue
+		case f == FieldVersion && o.ExactVersion:
+			if s != t || s == Any || HasWildcard(s) {
+				return false
+			}
+			continue
+		case o.AnyNotNA && t == Any && s == NA:
+			return false
+		}
+		if !matchAttr(s, t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"testing"
+)
+
+func TestMatchOptions(t *testing.T) {
+	cve := &Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.0`, Update: NA, TargetSW: "android", TargetHW: "x64"}
+	cases := []struct {
+		opts     MatchOptions
+		tgt      Attributes
+		expected bool
+	}{
+		{MatchOptions{}, Attributes{Part: "a", Vendor: "foo", Product: "bar"}, true},
+		{MatchOptions{}, Attributes{Part: "a", Vendor: "foo", Product: "bar", TargetSW: "ios"}, false},
+		{MatchOptions{IgnoreTargetSW: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", TargetSW: "ios"}, true},
+		{MatchOptions{IgnoreTargetSW: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", TargetHW: "arm64"}, false},
+		{MatchOptions{IgnoreTargetHW: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", TargetHW: "arm64"}, true},
+		{MatchOptions{AnyNotNA: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar"}, false},
+		{MatchOptions{AnyNotNA: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", Update: NA}, true},
+		{MatchOptions{ExactVersion: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar"}, false},
+		{MatchOptions{ExactVersion: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.*`}, false},
+		{MatchOptions{ExactVersion: true}, Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.0`}, true},
+		{MatchOptions{ExactVersion: true}, Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: `1\.0`}, false},
+	}
+	for _, c := range cases {
+		if got := c.opts.Match(cve, &c.tgt); got != c.expected {
+			t.Errorf("%+v: %s: expected %t, got %t", c.opts, c.tgt, c.expected, got)
+		}
+		if c.opts == (MatchOptions{}) && Match(cve, &c.tgt) != c.expected {
+			t.Errorf("%s: Match disagrees with zero MatchOptions", c.tgt)
+		}
+	}
+	if (MatchOptions{ExactVersion: true}).Match(cve, nil) {
+		t.Error("nil target matched")
+	}
+}
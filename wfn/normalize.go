@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+// Normalize returns a copy of a in canonical form, so CPE names of the same software written slightly differently
+// compare equal: values are lowercased, punctuation is quoted and needlessly quoted letters, digits and underscores
+// aren't (e.g. 1.0 and 1\.0, Foo and foo are the same), runs of unquoted asterisks are collapsed
+// and the value consisting of an asterisk alone becomes ANY. Logical values ANY and NA are kept.
+func (a Attributes) Normalize() *Attributes {
+	for _, v := range []*string{&a.Part, &a.Vendor, &a.Product, &a.Version, &a.Update, &a.Edition,
+		&a.SWEdition, &a.TargetSW, &a.TargetHW, &a.Other, &a.Language} {
+		*v = normalizeValue(*v)
+	}
+	return &a
+}
+
+// Equal returns true if a and b are the same CPE name once normalized, see Normalize
+func (a Attributes) Equal(b Attributes) bool {
+	return *a.Normalize() == *b.Normalize()
+}
+
+// Dedup returns normalized copies of cpes without duplicates, in the order they're first seen; nil elements are dropped
+func Dedup(cpes []*Attributes) []*Attributes {
+	seen := make(map[Attributes]bool, len(cpes))
+	out := make([]*Attributes, 0, len(cpes))
+	for _, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		norm := cpe.Normalize()
+		if seen[*norm] {
+			continue
+		}
+		seen[*norm] = true
+		out = append(out, norm)
+	}
+	return out
+}
+
+func normalizeValue(s string) string {
+	if s == Any || s == NA {
+		return s
+	}
+	b := make([]byte, 0, len(s)+len(s)/2)
+	star := false // the last byte is an unquoted asterisk
+	for i := 0; i < len(s); i++ {
+		c := lower(s[i])
+		switch {
+		case c == '*':
+			if !star {
+				b = append(b, c)
+			}
+			star = true
+			continue
+		case c == '\\' && i < len(s)-1:
+			i++
+			c = lower(s[i])
+			if !plain(c) {
+				b = append(b, '\\')
+			}
+			b = append(b, c)
+		case c == '?' || plain(c):
+			b = append(b, c)
+		default: // unquoted punctuation, including a trailing backslash
+			b = append(b, '\\', c)
+		}
+		star = false
+	}
+	if string(b) == "*" {
+		return Any
+	}
+	return string(b)
+}
+
+// plain returns true for the characters which aren't quoted in WFN: ASCII letters, digits and underscore;
+// non-ASCII bytes are passed as they are
+func plain(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c >= 0x80
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"", ""},
+		{"-", "-"},
+		{"Foo", "foo"},
+		{"1.0", `1\.0`},
+		{`1\.0`, `1\.0`},
+		{`\f\o\o`, "foo"},
+		{`\Foo\_Bar`, "foo_bar"},
+		{"*", ""},
+		{"**", ""},
+		{`1\.**`, `1\.*`},
+		{`\*`, `\*`},
+		{`\**`, `\**`},
+		{"??1", "??1"},
+		{`bar\`, `bar\\`},
+	}
+	for _, c := range cases {
+		if got := normalizeValue(c.in); got != c.expected {
+			t.Errorf("normalizeValue(%q): expected %q, got %q", c.in, c.expected, got)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := Attributes{Part: "a", Vendor: "Microsoft", Product: "Internet_Explorer", Version: "11.0", Update: "*"}
+	b := Attributes{Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `11\.0`}
+	if !a.Equal(b) {
+		t.Errorf("%v and %v are expected to be equal", a, b)
+	}
+	if b.Version = "11.1"; a.Equal(b) {
+		t.Errorf("%v and %v are expected to differ", a, b)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	in := []*Attributes{
+		{Part: "a", Vendor: "foo", Product: "Bar", Version: "1.0"},
+		nil,
+		{Part: "a", Vendor: "foo", Product: "baz"},
+		{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.0`},
+		{Part: "a", Vendor: "foo", Product: "baz", Update: "**"},
+	}
+	orig := *in[0]
+	expected := []*Attributes{
+		{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.0`},
+		{Part: "a", Vendor: "foo", Product: "baz"},
+	}
+	if got := Dedup(in); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if *in[0] != orig {
+		t.Errorf("input was modified: %v", in[0])
+	}
+}
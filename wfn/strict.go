@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseStrict is like Parse, but the CPE name must comply with CPE 2.3 specification in full: formatted strings
+// are checked as per Validate, URIs must have at most 7 components of unreserved characters (letters, digits,
+// '-', '.', '_') and percent-encoded ones and the packed edition must have all 5 of its fields;
+// the unbound attributes are checked with ValidateAttributes, its *AttributeError can be retrieved with errors.As.
+// Names ParseStrict accepts bind back to the same attributes, see ValidateAttributes.
+func ParseStrict(s string) (*Attributes, error) {
+	var attr *Attributes
+	var err error
+	switch {
+	case strings.HasPrefix(s, fsbPrefix):
+		if err = Validate(s); err != nil {
+			return nil, fmt.Errorf("wfn: strict: %v", err)
+		}
+		attr, err = UnbindFmtString(s)
+	case strings.HasPrefix(s, uriPrefix):
+		if err = validateURI(s); err != nil {
+			return nil, fmt.Errorf("wfn: strict: invalid URI %q: %v", s, err)
+		}
+		attr, err = UnbindURI(s)
+	default:
+		return nil, fmt.Errorf("wfn: strict: unsupported format %q", s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wfn: strict: %v", err)
+	}
+	if err = ValidateAttributes(attr); err != nil {
+		return nil, fmt.Errorf("wfn: strict: %q: %w", s, err)
+	}
+	return attr, nil
+}
+
+// uriComponents is the number of components of URI binding, the packed edition counts as one
+const uriComponents = 7
+
+// validateURI checks the syntax of URI binding, the values are checked once unbound
+func validateURI(s string) error {
+	components := strings.Split(s[len(uriPrefix):], ":")
+	if len(components) > uriComponents {
+		return fmt.Errorf("too many components: %d, at most %d allowed", len(components), uriComponents)
+	}
+	for n, c := range components {
+		packed := n == 5 && strings.HasPrefix(c, "~")
+		if fields := strings.Count(c, "~"); packed && fields != 5 {
+			return fmt.Errorf("packed edition %q: expected 5 fields, got %d", c, fields)
+		}
+		for i := 0; i < len(c); i++ {
+			switch b := c[i]; {
+			case isAlnum(b) || b == '_' || b == '.' || b == '-':
+			case b == '~' && packed:
+			case b == '%':
+				if i+2 >= len(c) || !isHex(c[i+1]) || !isHex(c[i+2]) {
+					return fmt.Errorf("component %d: malformed percent-encoding at pos %d", n+1, i)
+				}
+				i += 2
+			default:
+				return fmt.Errorf("component %d: illegal character %q at pos %d", n+1, b, i)
+			}
+		}
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseStrict(t *testing.T) {
+	valid := map[string]Attributes{
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*": {
+			Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `8\.0\.6001`, Update: "beta",
+		},
+		`cpe:2.3:a:foo:\-:-:*:*:en-us:*:*:*:*`: {Part: "a", Vendor: "foo", Product: `\-`, Version: NA, Language: `en\-us`},
+		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01": {
+			Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `8\.*`, Update: "sp?",
+		},
+		"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~": {
+			Part: "a", Vendor: "hp", Product: "insight_diagnostics", Version: `7\.4\.0\.1570`,
+			SWEdition: "online", TargetSW: "win2003", TargetHW: "x64",
+		},
+		"cpe:/a:foo%7ebar:-bar:%2d:-": {Part: "a", Vendor: `foo\~bar`, Product: `\-bar`, Version: `\-`, Update: NA},
+	}
+	for s, want := range valid {
+		attr, err := ParseStrict(s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", s, err)
+			continue
+		}
+		if *attr != want {
+			t.Errorf("%s: expected %s, got %s", s, want, attr)
+		}
+	}
+	invalid := map[string]Field{
+		"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*":           -1, // too few components
+		`cpe:2.3:a:foo:bar\_baz:1.0:*:*:*:*:*:*:*`:    -1, // quoted underscore
+		"cpe:2.3:a:foo:bar:1.0:*:*:english:*:*:*:*":   FieldLanguage,
+		"cpe:/a:foo:bar:1.0:beta:-:en:x":              -1, // too many components
+		"cpe:/a:foo!:bar":                             -1, // unencoded punctuation
+		"cpe:/a:foo:bar:1.%2":                         -1, // truncated percent-encoding
+		"cpe:/a:foo:bar:1.%zz":                        -1,
+		"cpe:/a:foo:bar:1.0::~~online~win2003~x64":    -1, // packed edition lacks a field
+		"cpe:/a:foo:bar:1.0:on~line":                  -1,
+		"cpe:/a:foo:bar:1.%021":                       -1, // embedded wildcard
+		"cpe:/a:foo:bar:%02":                          FieldVersion,
+		"cpe:/x:foo:bar":                              -1,
+		"cpe:/a:foo:bar:1.0:beta:-:english":           FieldLanguage,
+		"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*:*":       -1,
+		"cpe:2.3:a:foo:caf\xc3\xa9:1.0:*:*:*:*:*:*:*": -1,
+		"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*\\":       -1,
+		"cpe:2.4:a:foo:bar:1.0:*:*:*:*:*:*:*":         -1,
+		"cpe:/a:foo:bar:1.0:beta:-:en%2du%2":          -1,
+		"cpe:/a:foo:bar:1.0:beta:-:en%2dus:":          -1, // too many components
+		"cpe:/a:foo:b%41r":                            -1, // only unreserved characters are encoded
+		"cpe:/a:foo:bar:1.0:~~online~win2003~x~y~z":   -1,
+	}
+	for s, field := range invalid {
+		_, err := ParseStrict(s)
+		if err == nil {
+			t.Errorf("%q: expected an error", s)
+			continue
+		}
+		var attrErr *AttributeError
+		if errors.As(err, &attrErr) != (field >= 0) || field >= 0 && attrErr.Field != field {
+			t.Errorf("%q: expected the error of %v, got %v", s, field, err)
+		}
+	}
+}
+
+func TestBindRoundTrip(t *testing.T) {
+	cases := []Attributes{
+		{Part: "a", Vendor: `\-`, Product: `\-foo`, Version: `foo\-`, Update: `\-\-`},
+		{Part: "a", Vendor: `\.`, Product: `\!`, Version: `\~`, Update: `\:`, Edition: `\\`},
+		{Part: "o", Vendor: `\*`, Product: `\?`, Version: `\*foo\?`, Update: `*\?`, Edition: `\**`},
+		{Part: "h", Vendor: "??foo", Product: "foo??", Version: "*foo*", Update: "??*", Edition: `\%01`},
+		{Part: "a", Edition: NA, SWEdition: `\-`, TargetSW: NA, TargetHW: `\~x`, Other: `a\~`},
+		{Part: "a", Vendor: `foo\\\\`, Product: `foo\\\*`, Language: `en\-us`},
+		{Part: "a", Vendor: "foo", Product: "bar", Version: `1\.0`, Language: NA},
+	}
+	for _, a := range cases {
+		if err := ValidateAttributes(&a); err != nil {
+			t.Errorf("%s: unexpected error: %v", a, err)
+			continue
+		}
+		checkRoundTrip(t, &a)
+	}
+}
+
+// checkRoundTrip checks that valid attributes bind to formatted string and URI, which strictly unbind back to a
+func checkRoundTrip(t *testing.T, a *Attributes) {
+	t.Helper()
+	fs := a.BindToFmtString()
+	if b, err := ParseStrict(fs); err != nil {
+		t.Errorf("%s: bound to %q, which failed to parse: %v", a, fs, err)
+	} else if *b != *a {
+		t.Errorf("%s: round trip through %q is lossy, got %s", a, fs, b)
+	}
+	uri := a.BindToURI()
+	if b, err := ParseStrict(uri); err != nil {
+		t.Errorf("%s: bound to %q, which failed to parse: %v", a, uri, err)
+	} else if !b.Equal(*a) {
+		t.Errorf("%s: round trip through %q is lossy, got %s", a, uri, b)
+	}
+}
+
+func FuzzParseStrict(f *testing.F) {
+	for _, s := range []string{
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		`cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`,
+		`cpe:2.3:a:disney:where\'s_my_perry\?_free:1.5.1:*:*:*:*:android:*:*`,
+		"cpe:2.3:h:cisco:rv320:??1:1??:*:*:*:*:*:en-us",
+		`cpe:2.3:a:foo:\-:-:*:*:*:*:*:*:*`,
+		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
+		"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~",
+		"cpe:/a:foo%7ebar:-bar:%2d:-:-",
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		Parse(s) // the lenient parsers mustn't panic either
+		a, err := ParseStrict(s)
+		if err != nil {
+			return
+		}
+		checkRoundTrip(t, a)
+	})
+}
+
+func FuzzBindRoundTrip(f *testing.F) {
+	f.Add("a", "microsoft", "internet_explorer", `8\.0\.6001`, "beta", `en\-us`)
+	f.Add("o", `foo\\bar`, `big\$money`, `8\.*`, "sp?", "*")
+	f.Add("h", `\-`, `\-foo`, "??1", "??*", "en")
+	f.Add("-", `\~`, `\*foo\?`, "*foo*", `\%01`, "-")
+	f.Fuzz(func(t *testing.T, part, vendor, product, version, update, language string) {
+		a := &Attributes{Part: part, Vendor: vendor, Product: product, Version: version, Update: update,
+			Edition: update, SWEdition: version, TargetSW: product, TargetHW: vendor, Other: language, Language: language}
+		if ValidateAttributes(a) != nil {
+			a.Edition, a.SWEdition, a.TargetSW, a.TargetHW, a.Other = "", "", "", "", ""
+			if ValidateAttributes(a) != nil {
+				return
+			}
+		}
+		if len(a.BindToFmtString()) > MaxFmtStringLength {
+			return
+		}
+		checkRoundTrip(t, a)
+	})
+}
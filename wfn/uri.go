@@ -20,7 +20,8 @@ import (
 	"strings"
 )
 
-// BindToURI binds WFN to URI
+// BindToURI binds WFN to CPE 2.2 URI, e.g. cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~;
+// attributes added in CPE 2.3 are packed into the edition component when any of them is set.
 func (a Attributes) BindToURI() string {
 	var parts []string
 	for i, v := range []string{
@@ -52,7 +53,7 @@ func (a Attributes) BindToURI() string {
 	return uriPrefix + strings.Join(parts, ":")
 }
 
-// UnbindURI loads WFN from URI
+// UnbindURI loads WFN from URI; parts other than a, o, h, ANY and - are an error
 func UnbindURI(s string) (*Attributes, error) {
 	if !strings.HasPrefix(s, uriPrefix) {
 		return nil, fmt.Errorf("unbind uri: bad prefix in URI %q", s)
@@ -91,7 +92,9 @@ func UnbindURI(s string) (*Attributes, error) {
 					attr.TargetHW, i, err = unbindValueURIAtTill(s, i, '~')
 				case 4:
 					attr.Other, i, err = unbindValueURIAtTill(s, i, ':')
-				default:
+				}
+				// stop at the end of edition, so the language isn't skipped
+				if err != nil || subpartN == 4 || i == len(s) || s[i] == ':' {
 					break edition23
 				}
 			}
@@ -102,6 +105,9 @@ func UnbindURI(s string) (*Attributes, error) {
 			return nil, fmt.Errorf("unbind uri: %v", err)
 		}
 	}
+	if err := checkPart(attr.Part); err != nil {
+		return nil, fmt.Errorf("unbind uri: %v in %q", err, uriPrefix+s)
+	}
 	return &attr, nil
 }
 
@@ -122,8 +128,13 @@ func pack(ss []string) string {
 // Scans an input string s and applies the following transformations:
 // - pass alphanumeric characters thru untouched
 // - percent-encode quoted non-alphanumerics as needed
-// - unquoted special characters are mapped to their special forms.
+// - unquoted special characters are mapped to their special forms
+// - unquoted punctuation, illegal in WFN, is bound as if it was quoted.
+// The leading hyphen is percent-encoded, as "-" and a leading "-" would read as NA.
 func bindValueURI(s string) string {
+	if s == NA {
+		return "-"
+	}
 	var out []byte
 	for i := 0; i < len(s); i++ {
 		b := byte(s[i])
@@ -132,27 +143,35 @@ func bindValueURI(s string) string {
 			out = append(out, b)
 		} else if b == '\\' {
 			// percent-encode escaped characters
-			// sanity check should be done during unbinding, so here we silently skip all
-			// illegal characters
 			i++
 			if i == len(s) {
+				out = append(out, pctEncode(b)...) // trailing backslash is taken literally
 				break
 			}
+			if s[i] == '-' && len(out) == 0 {
+				out = append(out, "%2d"...)
+				continue
+			}
 			out = append(out, pctEncode(s[i])...)
 		} else if b == '?' { // unquoted '?' -> "%01"
 			out = append(out, '%', '0', '1')
 		} else if b == '*' { // unquoted '*' -> "%02"
 			out = append(out, '%', '0', '2')
+		} else if b == '-' && len(out) == 0 {
+			out = append(out, "%2d"...)
+		} else {
+			out = append(out, pctEncode(b)...)
 		}
 	}
 	return string(out)
 }
 
 func unbindValueURIAtTill(s string, at int, till byte) (string, int, error) {
-	if at >= len(s) || s[at] == till {
+	// the fields of packed edition end at '~', but the edition with fewer fields ends at ':'
+	if at >= len(s) || s[at] == till || s[at] == ':' {
 		return Any, at, nil
 	}
-	if s[at] == '-' {
+	if s[at] == '-' && (at+1 == len(s) || s[at+1] == till || s[at+1] == ':') { // a leading hyphen of a longer value is literal
 		return NA, at + 1, nil
 	}
 	out := make([]byte, 0, len(s)*2) // assume the worst
@@ -161,7 +180,7 @@ func unbindValueURIAtTill(s string, at int, till byte) (string, int, error) {
 loop:
 	for ; i < len(s); i++ {
 		switch s[i] {
-		case till:
+		case till, ':':
 			break loop
 		case '%':
 			if i+3 > len(s) {
@@ -175,7 +194,7 @@ loop:
 			if code == 0x1 || code == 0x2 {
 				if !(i == at || i == len(s)-3 || s[i+3] == till || // at the beginning or at the end of the string
 					(!embedded && i > 2 && s[i-3:i] == codeStr || // not embedded and preceded by the same symbol
-						(embedded && i+6 < len(s) && s[i+3:i+6] == codeStr))) { // embedded and followed by the same symbol
+						(embedded && (i+6 < len(s) || code == 0x1 && i+6 == len(s)) && s[i+3:i+6] == codeStr))) { // embedded and followed by the same symbol
 					return "", i, fmt.Errorf("unbind URI attribute: %%%02d is embedded into string %q", code, s)
 				}
 				switch code {
@@ -212,6 +231,10 @@ loop:
 				out = append(out, '\\', '+')
 			case 0x2c:
 				out = append(out, '\\', ',')
+			case 0x2d:
+				out = append(out, '\\', '-')
+			case 0x2e:
+				out = append(out, '\\', '.')
 			case 0x2f:
 				out = append(out, '\\', '/')
 			case 0x3a:
@@ -236,6 +259,8 @@ loop:
 				out = append(out, '\\', ']')
 			case 0x5e:
 				out = append(out, '\\', '^')
+			case 0x5f:
+				out = append(out, '_')
 			case 0x60:
 				out = append(out, '\\', '`')
 			case 0x7b:
@@ -251,10 +276,14 @@ loop:
 			}
 			i += 2
 			embedded = true
-		case '.', '-', '~':
-			out = append(out, '\\', s[i])
+		case '*', '?':
+			out = append(out, s[i])
 			embedded = true
 		default:
+			// punctuation other than '.', '-' and '~' is illegal in URI, but it's quoted as it must be in WFN
+			if c := s[i]; !isAlnum(c) && c != '_' && c < 0x80 {
+				out = append(out, '\\')
+			}
 			out = append(out, s[i])
 			embedded = true
 		}
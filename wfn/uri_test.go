@@ -53,6 +53,14 @@ func TestUnbindURI(t *testing.T) {
 			URI:    "cpe:/o:microsoft:windows_10:-::~~~~x64~",
 			Expect: `wfn:[part="o",vendor="microsoft",product="windows_10",version=NA,update=ANY,edition=ANY,sw_edition=ANY,target_sw=ANY,target_hw="x64",other=ANY,language=ANY]`,
 		},
+		{
+			URI:    "cpe:/a:foo:-bar:%2d:1.0:~~online~-:en-us",
+			Expect: `wfn:[part="a",vendor="foo",product="\-bar",version="\-",update="1\.0",edition=ANY,sw_edition="online",target_sw=NA,target_hw=ANY,other=ANY,language="en\-us"]`,
+		},
+		{
+			URI:    "cpe:/a:foo:bar!:1.0+1",
+			Expect: `wfn:[part="a",vendor="foo",product="bar\!",version="1\.0\+1",update=ANY,edition=ANY,language=ANY]`,
+		},
 		{
 			URI:  `cpe:/a:foo:boo%02%02`,
 			Fail: true,
@@ -94,6 +102,9 @@ func TestBindToURI(t *testing.T) {
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01:limited",
 		"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~",
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~",
+		"cpe:/a:foo%7ebar:b%21z:1.0:-:-",
+		"cpe:/a:foo:bar:1.0:beta%01%01",
 	}
 	for n, c := range cases {
 		c := c
@@ -108,3 +119,29 @@ func TestBindToURI(t *testing.T) {
 		})
 	}
 }
+
+func TestBindToURIRoundTrip(t *testing.T) {
+	cases := []string{
+		`cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*`,
+		`cpe:2.3:o:microsoft:windows_10:-:*:*:*:*:*:x64:*`,
+		`cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*`,
+		`cpe:2.3:a:foo\~bar:b\!z\%:1.0:sp?:*:*:*:*:*:*`,
+		`cpe:2.3:a:foo:bar:1.*:*:*:*:*:*:*:en-us`,
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			attr, err := UnbindFmtString(c)
+			if err != nil {
+				t.Fatalf("failed to parse input %q: %v", c, err)
+			}
+			uri := attr.BindToURI()
+			attr2, err := UnbindURI(uri)
+			if err != nil {
+				t.Fatalf("failed to parse bound URI %q: %v", uri, err)
+			}
+			if *attr2 != *attr {
+				t.Fatalf("round trip through %s is lossy\nexpected %s\ngot %s", uri, attr, attr2)
+			}
+		})
+	}
+}
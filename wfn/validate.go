@@ -0,0 +1,233 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fsbComponents is the number of attributes in formatted string binding
+const fsbComponents = 11
+
+// IsValid reports whether s is a well-formed CPE 2.3 formatted string, see Validate
+func IsValid(s string) bool {
+	return Validate(s) == nil
+}
+
+// Validate checks the syntax of CPE 2.3 formatted string (e.g. cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*)
+// without unbinding it into Attributes: the prefix, the number of components, the part value, quoting of
+// punctuation and placement of unquoted wildcards. It is stricter than UnbindFmtString, which quotes
+// punctuation as needed and tolerates missing trailing components.
+func Validate(s string) error {
+	if !strings.HasPrefix(s, fsbPrefix) {
+		return fmt.Errorf("bad prefix in FSB %q", s)
+	}
+	n := 0
+	for at := len(fsbPrefix); ; n++ {
+		if n == fsbComponents {
+			return fmt.Errorf("too many components in FSB %q", s)
+		}
+		end, err := validateValueFSAt(s, at)
+		if err != nil {
+			return fmt.Errorf("invalid FSB %q: %v", s, err)
+		}
+		if n == 0 {
+			switch s[at:end] {
+			case "a", "o", "h", "*", "-":
+			default:
+				return fmt.Errorf("invalid part %q in FSB %q", s[at:end], s)
+			}
+		}
+		if end == len(s) {
+			break
+		}
+		at = end + 1
+	}
+	if n+1 != fsbComponents {
+		return fmt.Errorf("expected %d components in FSB %q, got %d", fsbComponents, s, n+1)
+	}
+	return nil
+}
+
+// validateValueFSAt checks the attribute value starting at position at in formatted string s
+// and returns the position right after it
+func validateValueFSAt(s string, at int) (int, error) {
+	end := at
+	for ; end < len(s) && s[end] != ':'; end++ {
+		if s[end] == '\\' {
+			end++
+		}
+	}
+	if end > len(s) {
+		return end, fmt.Errorf("quoting '\\' at the end of the string")
+	}
+	if end == at {
+		return end, fmt.Errorf("empty attribute at pos %d", at)
+	}
+	if end-at == 1 && (s[at] == '*' || s[at] == '-') {
+		return end, nil // logical value
+	}
+	for i := at; i < end; i++ {
+		c := s[i]
+		switch {
+		case c < 0x21 || c > 0x7e:
+			return end, fmt.Errorf("illegal character %q at pos %d", c, i)
+		case c == '\\':
+			i++
+			if isAlnum(s[i]) || s[i] == '_' {
+				return end, fmt.Errorf("quoted alphanumeric character %q at pos %d", s[i], i)
+			}
+		case isAlnum(c) || c == '_' || c == '-' || c == '.':
+		case c == '*':
+			if i != at && i != end-1 {
+				return end, fmt.Errorf("unquoted '*' inside the attribute at pos %d", i)
+			}
+		case c == '?':
+			if strings.Trim(s[at:i], "?") != "" && strings.Trim(s[i:end], "?") != "" {
+				return end, fmt.Errorf("unquoted '?' inside the attribute at pos %d", i)
+			}
+		default:
+			return end, fmt.Errorf("unquoted %q at pos %d", c, i)
+		}
+	}
+	return end, nil
+}
+
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// AttributeError reports the attribute of WFN which violates CPE 2.3 specification, see ValidateAttributes
+type AttributeError struct {
+	Field  Field
+	Value  string
+	Pos    int // position of the offending character in the value, -1 if the value is wrong as a whole
+	Reason string
+}
+
+// Error implements error interface
+func (e *AttributeError) Error() string {
+	if e.Pos < 0 {
+		return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s at pos %d", e.Field, e.Value, e.Reason, e.Pos)
+}
+
+// ValidateAttributes checks the attribute values of WFN against CPE 2.3 specification (NISTIR 7695, 5.3.2):
+// values other than ANY and NA are printable ASCII, only letters, digits and underscore pass unquoted,
+// unquoted '*' (one) and '?' (a run of them) only appear at the beginning or end, the part is one of a, o, h
+// and the language is a language tag, e.g. en or en\-us. The first violation is reported as *AttributeError.
+// Attributes which pass bind to formatted string and URI which ParseStrict unbinds back unchanged, as long as
+// the formatted string is within MaxFmtStringLength (URI binding lowercases letters, so that round trip holds
+// up to Equal).
+func ValidateAttributes(a *Attributes) error {
+	for _, f := range Fields {
+		v := a.Get(f)
+		if pos, reason := validateValue(v); reason != "" {
+			return &AttributeError{Field: f, Value: v, Pos: pos, Reason: reason}
+		}
+		switch f {
+		case FieldPart:
+			if err := checkPart(v); err != nil {
+				return &AttributeError{Field: f, Value: v, Pos: -1, Reason: "expected one of a, o, h, ANY or NA"}
+			}
+		case FieldLanguage:
+			if v != Any && v != NA && !languageTag.MatchString(v) {
+				return &AttributeError{Field: f, Value: v, Pos: -1, Reason: "not a language tag"}
+			}
+		}
+	}
+	return nil
+}
+
+// languageTag is the language attribute as per CPE 2.3 ABNF: a language subtag with an optional region subtag,
+// possibly with wildcards around
+var languageTag = regexp.MustCompile(`^(\*|\?{1,3})?([a-zA-Z]{2,3}(\\-([a-zA-Z]{2}|[0-9]{3}))?)?(\*|\?{1,3})?$`)
+
+// validateValue checks WFN attribute value and returns the position and the reason of the violation,
+// the reason is empty for valid values
+func validateValue(v string) (int, string) {
+	if v == Any || v == NA {
+		return -1, ""
+	}
+	if v == "*" {
+		return -1, "lone '*' is ANY"
+	}
+	head, tail := wildcards(v)
+	if !wildcard(head) {
+		return 0, "mixed unquoted '*' and '?'"
+	}
+	if !wildcard(tail) {
+		return len(v) - len(tail), "mixed unquoted '*' and '?'"
+	}
+	for i := len(head); i < len(v)-len(tail); i++ {
+		c := v[i]
+		switch {
+		case c < 0x21 || c > 0x7e:
+			return i, fmt.Sprintf("illegal character %q", c)
+		case c == '\\':
+			if i == len(v)-1 {
+				return i, "trailing '\\'"
+			}
+			i++
+			if c = v[i]; isAlnum(c) || c == '_' || c < 0x21 || c > 0x7e {
+				return i, fmt.Sprintf("quoted %q", c)
+			}
+		case isAlnum(c) || c == '_':
+		case c == '*' || c == '?':
+			return i, fmt.Sprintf("unquoted %q inside the value", c)
+		default:
+			return i, fmt.Sprintf("unquoted %q", c)
+		}
+	}
+	return -1, ""
+}
+
+// wildcards returns leading and trailing runs of unquoted wildcards of v, the whole value is the leading run
+// if it's all wildcards
+func wildcards(v string) (head, tail string) {
+	i := 0
+	for i < len(v) && (v[i] == '*' || v[i] == '?') {
+		i++
+	}
+	if i == len(v) {
+		// up to two runs, e.g. ??*
+		j := strings.IndexAny(v, "*")
+		if j <= 0 || v[:j] != strings.Repeat("?", j) {
+			j = 1
+		}
+		return v[:j], v[j:]
+	}
+	j := len(v)
+	for j > i && (v[j-1] == '*' || v[j-1] == '?') {
+		j--
+	}
+	if n := 0; j < len(v) { // count backslashes before the tail, an odd number quotes its first wildcard
+		for k := j - 1; k >= 0 && v[k] == '\\'; k-- {
+			n++
+		}
+		if n%2 == 1 {
+			j++
+		}
+	}
+	return v[:i], v[j:]
+}
+
+// wildcard returns true if s is valid as leading or trailing unquoted wildcards: empty, one '*' or '?' run
+func wildcard(s string) bool {
+	return s == "" || s == "*" || strings.Trim(s, "?") == ""
+}
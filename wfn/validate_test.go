@@ -0,0 +1,123 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		"cpe:2.3:a:microsoft:internet_exp?????:8.*:sp?:*:*:*:*:*:*",
+		"cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*",
+		`cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`,
+		`cpe:2.3:a:disney:where\'s_my_perry\?_free:1.5.1:*:*:*:*:android:*:*`,
+		"cpe:2.3:o:microsoft:windows_10:-:*:*:*:*:*:x64:*",
+		"cpe:2.3:*:*:*:*:*:*:*:*:*:*:*",
+		"cpe:2.3:h:cisco:rv320:*foo*:*:*:*:*:*:*:*",
+		"cpe:2.3:h:cisco:rv320:??1:1??:*:*:*:*:*:*",
+	}
+	for _, s := range valid {
+		if err := Validate(s); err != nil {
+			t.Errorf("%s: unexpected error: %v", s, err)
+		}
+		if !IsValid(s) {
+			t.Errorf("%s: expected to be valid", s)
+		}
+		if _, err := UnbindFmtString(s); err != nil {
+			t.Errorf("%s: valid string failed to unbind: %v", s, err)
+		}
+	}
+	invalid := []string{
+		"",
+		"cpe:/a:microsoft:internet_explorer:8.0.6001:beta",
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*",      // too few components
+		"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*:*",  // too many components
+		"cpe:2.3:x:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",    // bad part
+		"cpe:2.3:a::internet_explorer:8.0.6001:beta:*:*:*:*:*:*",             // empty component
+		"cpe:2.3:a:hp:insight_diagnostics:7.4.*.1570:*:*:*:*:*:*:*",          // embedded *
+		`cpe:2.3:a:disney:where\'s_my_perry?_free:1.5.1:*:*:*:*:android:*:*`, // embedded ?
+		"cpe:2.3:a:foo:big$money:2010:*:*:*:*:*:*:*",                         // unquoted punctuation
+		`cpe:2.3:a:foo:\bar:2010:*:*:*:*:*:*:*`,                              // quoted letter
+		`cpe:2.3:a:foo:bar\_baz:2010:*:*:*:*:*:*:*`,                          // quoted underscore
+		"cpe:2.3:a:foo:bar baz:2010:*:*:*:*:*:*:*",                           // whitespace
+		`cpe:2.3:a:foo:bar:2010:*:*:*:*:*:*:baz\`,                            // dangling quote
+	}
+	for _, s := range invalid {
+		if err := Validate(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+		if IsValid(s) {
+			t.Errorf("%q: expected to be invalid", s)
+		}
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	valid := []Attributes{
+		{Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `8\.0\.6001`, Update: "beta"},
+		{Part: "a", Vendor: `foo\\bar`, Product: `big\$money`, Version: `8\.*`, Update: "sp?", Edition: NA},
+		{Part: "h", Product: "??1", Version: "*foo*", Update: "??*", Language: `en\-us`},
+		{Part: "o", Vendor: `\-`, Product: `\*\?`, Version: `1\.?`, Language: "fr*"},
+		{Part: NA},
+		{},
+	}
+	for _, a := range valid {
+		if err := ValidateAttributes(&a); err != nil {
+			t.Errorf("%s: unexpected error: %v", a, err)
+		}
+	}
+	cases := []struct {
+		attr  Attributes
+		field Field
+		pos   int
+	}{
+		{Attributes{Part: "x"}, FieldPart, -1},
+		{Attributes{Part: "a", Vendor: "*"}, FieldVendor, -1},
+		{Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1.0"}, FieldVersion, 1},
+		{Attributes{Part: "a", Version: "1*0"}, FieldVersion, 1},
+		{Attributes{Part: "a", Version: "1?0"}, FieldVersion, 1},
+		{Attributes{Part: "a", Update: "*?beta"}, FieldUpdate, 0},
+		{Attributes{Part: "a", Update: "beta?*"}, FieldUpdate, 4},
+		{Attributes{Part: "a", Edition: `\a`}, FieldEdition, 1},
+		{Attributes{Part: "a", Edition: `x\_y`}, FieldEdition, 2},
+		{Attributes{Part: "a", TargetSW: "foo bar"}, FieldTargetSW, 3},
+		{Attributes{Part: "a", TargetHW: "x64\\\\\\"}, FieldTargetHW, 5},
+		{Attributes{Part: "a", Other: "caf\xc3\xa9"}, FieldOther, 3},
+		{Attributes{Part: "a", Language: "english"}, FieldLanguage, -1},
+		{Attributes{Part: "a", Language: `en\-u`}, FieldLanguage, -1},
+	}
+	for _, c := range cases {
+		err := ValidateAttributes(&c.attr)
+		var attrErr *AttributeError
+		if !errors.As(err, &attrErr) {
+			t.Errorf("%s: expected *AttributeError, got %v", c.attr, err)
+			continue
+		}
+		if attrErr.Field != c.field || attrErr.Pos != c.pos || attrErr.Value != c.attr.Get(c.field) {
+			t.Errorf("%s: expected %s at %d, got %v", c.attr, c.field, c.pos, err)
+		}
+	}
+}
+
+func BenchmarkIsValid(b *testing.B) {
+	const s = `cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsValid(s)
+	}
+}
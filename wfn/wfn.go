@@ -26,6 +26,15 @@ var KnownParts = map[string]string{
 	"h": "hardware",
 }
 
+// checkPart returns an error if part isn't one of KnownParts, ANY or NA;
+// CPEs of the other parts are malformed and would never match anything
+func checkPart(part string) error {
+	if _, ok := KnownParts[part]; ok || part == Any || part == NA {
+		return nil
+	}
+	return fmt.Errorf("invalid part %q, expected one of a, o, h, * or -", part)
+}
+
 // Possible logical value of Attributes
 // empty string considered ANY when parsing and unquoted "-" is illegal in WFN attribute-value
 const (
@@ -58,6 +67,19 @@ var parsers = map[string]func(s string) (*Attributes, error){
 	fsbPrefix: UnbindFmtString,
 }
 
+// Soften returns a copy of a with NA attributes other than part, vendor and product replaced by ANY.
+// Per specification NA means the attribute doesn't apply, so it doesn't match any concrete value;
+// scanners however often report NA for the attributes they don't know (e.g. update of the OS),
+// which makes such CPE names miss the entries specifying them.
+func (a Attributes) Soften() *Attributes {
+	for _, v := range []*string{&a.Version, &a.Update, &a.Edition, &a.SWEdition, &a.TargetSW, &a.TargetHW, &a.Other, &a.Language} {
+		if *v == NA {
+			*v = Any
+		}
+	}
+	return &a
+}
+
 // Parse parses Attributes from URI or formatted string binding.
 func Parse(s string) (*Attributes, error) {
 	for prefix, parserFunc := range parsers {
@@ -68,6 +90,59 @@ func Parse(s string) (*Attributes, error) {
 	return nil, fmt.Errorf("wfn: unsupported format %q", s)
 }
 
+// CollapseDoubleEscaping undoes surplus escaping of CPE names which passed through JSON or shell layers,
+// e.g. cpe:2.3:a:acme\\:inc:... for cpe:2.3:a:acme\:inc:..., which Parse would unbind wrong or not at all:
+// runs of backslashes collapse into one. It's heuristic, as escaped backslashes (literal backslashes in values)
+// collapse too, so only apply it to the input known to suffer from this; returns true if s was changed.
+func CollapseDoubleEscaping(s string) (string, bool) {
+	if !strings.Contains(s, `\\`) {
+		return s, false
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i > 0 && s[i-1] == '\\' {
+			continue
+		}
+		b = append(b, s[i])
+	}
+	return string(b), true
+}
+
+// ParseShorthand parses the non-standard shorthand of CPE names some inventory tools report, vendor:product:version
+// or vendor:product, e.g. apache:http_server:2.4.1; it isn't a CPE binding, so use Parse for those.
+// Values are lowercased and quoted with WFNize, * stands for ANY and - for NA; part and the other attributes are ANY.
+func ParseShorthand(s string) (*Attributes, error) {
+	if strings.HasPrefix(strings.ToLower(s), "cpe:") {
+		return nil, fmt.Errorf("wfn: %q is a CPE binding, not a shorthand", s)
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("wfn: shorthand %q: expected vendor:product[:version]", s)
+	}
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		switch part = strings.ToLower(strings.TrimSpace(part)); part {
+		case "":
+			return nil, fmt.Errorf("wfn: shorthand %q: empty attribute %d", s, i+1)
+		case "*":
+			values[i] = Any
+		case NA:
+			values[i] = NA
+		default:
+			v, err := WFNize(part)
+			if err != nil {
+				return nil, fmt.Errorf("wfn: shorthand %q: %v", s, err)
+			}
+			values[i] = v
+		}
+	}
+	attr := &Attributes{Vendor: values[0], Product: values[1]}
+	if len(values) == 3 {
+		attr.Version = values[2]
+	}
+	return attr, nil
+}
+
 // WFNize transforms a string into CPE23-NAME compliant avstring value.
 // This function isn't a part of standard. Quoted wildcards (*?) become unquoted ones (i.e. act as wildcards,
 // not a literal '*' and '?')
@@ -14,7 +14,10 @@
 
 package wfn
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestWFNize(t *testing.T) {
 	cases := []struct {
@@ -48,3 +51,130 @@ func BenchmarkWFNize(t *testing.B) {
 		WFNize("1.8.14.6001")
 	}
 }
+
+func TestSoften(t *testing.T) {
+	a := &Attributes{Part: "o", Vendor: "microsoft", Product: NA, Version: `10\.0`, Update: NA, TargetHW: NA}
+	expected := Attributes{Part: "o", Vendor: "microsoft", Product: NA, Version: `10\.0`}
+	if soft := a.Soften(); *soft != expected {
+		t.Errorf("expected %v, got %v", expected, soft)
+	}
+	if a.Update != NA {
+		t.Error("original attributes expected to stay intact")
+	}
+}
+
+func TestParseShorthand(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected *Attributes
+	}{
+		{"apache:http_server:2.4.1", &Attributes{Vendor: "apache", Product: "http_server", Version: `2\.4\.1`}},
+		{"Apache:HTTP Server", &Attributes{Vendor: "apache", Product: "http_server"}},
+		{"foo:bar:*", &Attributes{Vendor: "foo", Product: "bar"}},
+		{"foo:bar:-", &Attributes{Vendor: "foo", Product: "bar", Version: NA}},
+		{"foo", nil},
+		{"foo::1.0", nil},
+		{"foo:bar:1.0:sp1", nil},
+		{"cpe:/a:foo:bar", nil},
+	}
+	for _, c := range cases {
+		attr, err := ParseShorthand(c.in)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", c.in, attr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.in, err)
+			continue
+		}
+		if *attr != *c.expected {
+			t.Errorf("%q: expected %v, got %v", c.in, c.expected, attr)
+		}
+	}
+}
+
+func TestParsePart(t *testing.T) {
+	cases := []struct {
+		s    string
+		part string
+	}{
+		{"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*", "a"},
+		{"cpe:2.3:o:foo:bar:1.0:*:*:*:*:*:*:*", "o"},
+		{"cpe:2.3:h:foo:bar:1.0:*:*:*:*:*:*:*", "h"},
+		{"cpe:2.3:*:foo:bar:1.0:*:*:*:*:*:*:*", Any},
+		{"cpe:2.3:-:foo:bar:1.0:*:*:*:*:*:*:*", NA},
+		{"cpe:/a:foo:bar:1.0", "a"},
+		{"cpe:/o:foo:bar:1.0", "o"},
+		{"cpe:/h:foo:bar:1.0", "h"},
+		{"cpe:/:foo:bar:1.0", Any},
+		{"cpe:/-:foo:bar:1.0", NA},
+	}
+	for _, c := range cases {
+		attr, err := Parse(c.s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.s, err)
+			continue
+		}
+		if attr.Part != c.part {
+			t.Errorf("%q: expected part %q, got %q", c.s, c.part, attr.Part)
+		}
+	}
+	for _, s := range []string{
+		"cpe:2.3:p:foo:bar:1.0:*:*:*:*:*:*:*",
+		"cpe:2.3:app:foo:bar:1.0:*:*:*:*:*:*:*",
+		"cpe:2.3:a?:foo:bar:1.0:*:*:*:*:*:*:*",
+		"cpe:/p:foo:bar:1.0",
+		"cpe:/application:foo:bar",
+	} {
+		if attr, err := Parse(s); err == nil || !strings.Contains(err.Error(), "invalid part") {
+			t.Errorf("%q: expected invalid part error, got %v, %v", s, attr, err)
+		}
+	}
+}
+
+func TestCollapseDoubleEscaping(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected *Attributes
+		changed  bool
+	}{
+		{
+			`cpe:2.3:a:acme\\:inc:widget\\+\\+:1\\.0:*:*:*:*:*:*:*`,
+			&Attributes{Part: "a", Vendor: `acme\:inc`, Product: `widget\+\+`, Version: `1\.0`,
+				Update: Any, Edition: Any, Language: Any, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			true,
+		},
+		{ // escaped twice
+			`cpe:2.3:a:at\\\\&t:connect:*:*:*:*:*:*:*:*`,
+			&Attributes{Part: "a", Vendor: `at\&t`, Product: "connect",
+				Version: Any, Update: Any, Edition: Any, Language: Any, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			true,
+		},
+		{
+			`cpe:2.3:a:acme\:inc:widget:1.0:*:*:*:*:*:*:*`,
+			&Attributes{Part: "a", Vendor: `acme\:inc`, Product: "widget", Version: `1\.0`,
+				Update: Any, Edition: Any, Language: Any, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			false,
+		},
+	}
+	for _, c := range cases {
+		s, changed := CollapseDoubleEscaping(c.in)
+		if changed != c.changed {
+			t.Errorf("%s: expected changed to be %t", c.in, c.changed)
+		}
+		attr, err := Parse(s)
+		if err != nil {
+			t.Errorf("%s: %v", c.in, err)
+			continue
+		}
+		if *attr != *c.expected {
+			t.Errorf("%s: expected %v, got %v", c.in, c.expected, attr)
+		}
+	}
+	// without collapsing, the double escaped name doesn't unbind
+	if attr, err := Parse(cases[0].in); err == nil {
+		t.Errorf("%s: expected an error, got %v", cases[0].in, attr)
+	}
+}